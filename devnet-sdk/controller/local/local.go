@@ -0,0 +1,68 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/surface"
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/descriptors"
+)
+
+// Service is a service that runs in the same process as the devnet user, and can be stopped and started again.
+type Service interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// LocalControllerSurface controls the services of a devnet that runs in-process.
+type LocalControllerSurface struct {
+	env      *descriptors.DevnetEnvironment
+	services map[string]Service
+
+	// control operations are disruptive, let's make sure we don't run them
+	// concurrently so that test logic has a fighting chance of being correct.
+	mtx sync.Mutex
+}
+
+func NewLocalControllerSurface(env *descriptors.DevnetEnvironment, services map[string]Service) *LocalControllerSurface {
+	return &LocalControllerSurface{
+		env:      env,
+		services: services,
+	}
+}
+
+// Services returns the names of the controllable services, in sorted order.
+func (s *LocalControllerSurface) Services() []string {
+	names := make([]string, 0, len(s.services))
+	for name := range s.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *LocalControllerSurface) StartService(ctx context.Context, serviceName string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	svc, ok := s.services[serviceName]
+	if !ok {
+		return fmt.Errorf("unknown service %q in devnet %q", serviceName, s.env.Name)
+	}
+	return svc.Start(ctx)
+}
+
+func (s *LocalControllerSurface) StopService(ctx context.Context, serviceName string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	svc, ok := s.services[serviceName]
+	if !ok {
+		return fmt.Errorf("unknown service %q in devnet %q", serviceName, s.env.Name)
+	}
+	return svc.Stop(ctx)
+}
+
+var _ surface.ServiceLifecycleSurface = (*LocalControllerSurface)(nil)
@@ -0,0 +1,75 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/descriptors"
+)
+
+type testService struct {
+	running bool
+	err     error
+}
+
+func (s *testService) Start(ctx context.Context) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.running = true
+	return nil
+}
+
+func (s *testService) Stop(ctx context.Context) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.running = false
+	return nil
+}
+
+func TestLocalControllerSurface(t *testing.T) {
+	batcher := &testService{running: true}
+	broken := &testService{err: errors.New("boom")}
+	env := &descriptors.DevnetEnvironment{Name: "test"}
+	ctrl := NewLocalControllerSurface(env, map[string]Service{
+		"op-batcher": batcher,
+		"broken":     broken,
+	})
+	require.Equal(t, []string{"broken", "op-batcher"}, ctrl.Services())
+
+	ctx := context.Background()
+	require.NoError(t, ctrl.StopService(ctx, "op-batcher"))
+	require.False(t, batcher.running)
+	require.NoError(t, ctrl.StartService(ctx, "op-batcher"))
+	require.True(t, batcher.running)
+
+	require.ErrorIs(t, ctrl.StartService(ctx, "broken"), broken.err)
+	require.ErrorContains(t, ctrl.StopService(ctx, "unknown"), "unknown service")
+}
+
+func TestRegistry(t *testing.T) {
+	env := &descriptors.DevnetEnvironment{Name: "registry-test"}
+	ctrl := NewLocalControllerSurface(env, nil)
+
+	_, _, err := Lookup(env.Name)
+	require.Error(t, err)
+
+	unregister, err := Register(env, ctrl)
+	require.NoError(t, err)
+	_, err = Register(env, ctrl)
+	require.ErrorContains(t, err, "already registered")
+
+	gotEnv, gotCtrl, err := Lookup(env.Name)
+	require.NoError(t, err)
+	require.Same(t, env, gotEnv)
+	require.Same(t, ctrl, gotCtrl)
+
+	unregister()
+	_, _, err = Lookup(env.Name)
+	require.Error(t, err)
+	require.Equal(t, "local://registry-test", URL(env.Name))
+}
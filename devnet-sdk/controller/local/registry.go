@@ -0,0 +1,54 @@
+package local
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/descriptors"
+)
+
+// Scheme is the URL scheme of devnets that run in-process, e.g. "local://my-devnet".
+// These URLs can only be resolved within the process that registered the devnet.
+const Scheme = "local"
+
+// URL returns the devnet URL of the in-process devnet with the given name.
+func URL(name string) string {
+	return Scheme + "://" + name
+}
+
+type entry struct {
+	env  *descriptors.DevnetEnvironment
+	ctrl *LocalControllerSurface
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]entry)
+)
+
+// Register makes the in-process devnet available by name, to resolve its URL.
+// The returned function unregisters the devnet.
+func Register(env *descriptors.DevnetEnvironment, ctrl *LocalControllerSurface) (func(), error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[env.Name]; ok {
+		return nil, fmt.Errorf("devnet %q is already registered", env.Name)
+	}
+	registry[env.Name] = entry{env: env, ctrl: ctrl}
+	return func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(registry, env.Name)
+	}, nil
+}
+
+// Lookup returns the registered in-process devnet and its controller.
+func Lookup(name string) (*descriptors.DevnetEnvironment, *LocalControllerSurface, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	e, ok := registry[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("no in-process devnet %q, it only exists in the process that started it", name)
+	}
+	return e.env, e.ctrl, nil
+}
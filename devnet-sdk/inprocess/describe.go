@@ -0,0 +1,206 @@
+package inprocess
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/local"
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/descriptors"
+	op_e2e "github.com/tokamak-network/tokamak-thanos/op-e2e"
+	"github.com/tokamak-network/tokamak-thanos/op-e2e/e2eutils"
+)
+
+// describe creates the descriptor of the running op-e2e system.
+func describe(name string, sys *op_e2e.System, services map[string]local.Service) (*descriptors.DevnetEnvironment, error) {
+	l1, err := describeL1(sys)
+	if err != nil {
+		return nil, err
+	}
+	l2, err := describeL2(sys, services)
+	if err != nil {
+		return nil, err
+	}
+	return &descriptors.DevnetEnvironment{
+		Name: name,
+		L1:   l1,
+		L2:   []*descriptors.L2Chain{l2},
+	}, nil
+}
+
+func describeL1(sys *op_e2e.System) (*descriptors.Chain, error) {
+	el, err := describeEL("l1", sys.EthInstances["l1"], false)
+	if err != nil {
+		return nil, err
+	}
+	beacon, err := portInfo(sys.L1BeaconEndpoint())
+	if err != nil {
+		return nil, fmt.Errorf("invalid L1 beacon endpoint: %w", err)
+	}
+	secrets := sys.Cfg.Secrets
+	return &descriptors.Chain{
+		Name: "l1",
+		ID:   strconv.FormatUint(sys.Cfg.DeployConfig.L1ChainID, 10),
+		Services: descriptors.RedundantServiceMap{
+			"cl": {{
+				Name:      "l1-beacon",
+				Endpoints: descriptors.EndpointMap{"http": beacon},
+			}},
+		},
+		Nodes: []descriptors.Node{{
+			Name:     "l1",
+			Services: descriptors.ServiceMap{"el": el},
+		}},
+		Wallets: userWallets(secrets),
+	}, nil
+}
+
+func describeL2(sys *op_e2e.System, services map[string]local.Service) (*descriptors.L2Chain, error) {
+	// The sequencer must be the first node.
+	names := []string{"sequencer"}
+	for name := range sys.RollupNodes {
+		if name != "sequencer" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+
+	nodes := make([]descriptors.Node, 0, len(names))
+	for _, name := range names {
+		if _, ok := sys.RollupNodes[name]; !ok {
+			return nil, fmt.Errorf("missing rollup node %q", name)
+		}
+		el, err := describeEL(name, sys.EthInstances[name], true)
+		if err != nil {
+			return nil, err
+		}
+		cl, err := portInfo(sys.RollupEndpoint(name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s op-node endpoint: %w", name, err)
+		}
+		nodes = append(nodes, descriptors.Node{
+			Name: name,
+			Services: descriptors.ServiceMap{
+				"el": el,
+				"cl": {
+					Name:      name + "-op-node",
+					Endpoints: descriptors.EndpointMap{"rpc": cl},
+				},
+			},
+		})
+	}
+
+	chainServices := make(descriptors.RedundantServiceMap)
+	for name := range services {
+		chainServices[name] = []*descriptors.Service{{Name: name, Endpoints: descriptors.EndpointMap{}}}
+	}
+
+	addresses, err := deploymentAddresses(sys)
+	if err != nil {
+		return nil, err
+	}
+	secrets := sys.Cfg.Secrets
+	l1Wallets := userWallets(secrets)
+	l1Wallets["deployer"] = wallet(secrets.Deployer)
+	l1Wallets["systemConfigOwner"] = wallet(secrets.SysCfgOwner)
+	l1Wallets["batcher"] = wallet(secrets.Batcher)
+	l1Wallets["proposer"] = wallet(secrets.Proposer)
+
+	return &descriptors.L2Chain{
+		Chain: &descriptors.Chain{
+			Name:      "l2",
+			ID:        sys.RollupConfig.L2ChainID.String(),
+			Services:  chainServices,
+			Nodes:     nodes,
+			Wallets:   userWallets(secrets),
+			JWT:       hexutil.Encode(sys.Cfg.JWTSecret[:]),
+			Config:    sys.L2GenesisCfg.Config,
+			Addresses: addresses,
+		},
+		L1Wallets:    l1Wallets,
+		RollupConfig: sys.RollupConfig,
+	}, nil
+}
+
+func describeEL(name string, node op_e2e.EthInstance, engine bool) (*descriptors.Service, error) {
+	if node == nil {
+		return nil, fmt.Errorf("missing execution node %q", name)
+	}
+	endpoints := make(descriptors.EndpointMap)
+	for key, endpoint := range map[string]string{"rpc": node.HTTPEndpoint(), "ws": node.WSEndpoint()} {
+		info, err := portInfo(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s endpoint: %w", name, key, err)
+		}
+		endpoints[key] = info
+	}
+	if engine {
+		info, err := portInfo(node.HTTPAuthEndpoint())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s engine endpoint: %w", name, err)
+		}
+		endpoints["engine-rpc"] = info
+	}
+	return &descriptors.Service{
+		Name:      name + "-geth",
+		Endpoints: endpoints,
+	}, nil
+}
+
+func portInfo(endpoint string) (*descriptors.PortInfo, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in %q: %w", endpoint, err)
+	}
+	return &descriptors.PortInfo{
+		Host:   u.Hostname(),
+		Scheme: u.Scheme,
+		Port:   port,
+	}, nil
+}
+
+// deploymentAddresses returns the L1 contracts of the L2 chain, by their deployment name.
+func deploymentAddresses(sys *op_e2e.System) (descriptors.AddressMap, error) {
+	data, err := json.Marshal(sys.Cfg.L1Deployments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode L1 deployments: %w", err)
+	}
+	var deployments map[string]common.Address
+	if err := json.Unmarshal(data, &deployments); err != nil {
+		return nil, fmt.Errorf("failed to decode L1 deployments: %w", err)
+	}
+	out := make(descriptors.AddressMap)
+	for name, addr := range deployments {
+		if addr != (common.Address{}) {
+			out[name] = addr
+		}
+	}
+	return out, nil
+}
+
+// userWallets returns the test user wallets, which are funded on both L1 and L2.
+func userWallets(secrets *e2eutils.Secrets) descriptors.WalletMap {
+	return descriptors.WalletMap{
+		"alice":   wallet(secrets.Alice),
+		"bob":     wallet(secrets.Bob),
+		"mallory": wallet(secrets.Mallory),
+	}
+}
+
+func wallet(key *ecdsa.PrivateKey) *descriptors.Wallet {
+	return &descriptors.Wallet{
+		Address:    crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: e2eutils.EncodePrivKeyToString(key),
+	}
+}
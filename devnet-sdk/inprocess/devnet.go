@@ -0,0 +1,155 @@
+package inprocess
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/local"
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/surface"
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/descriptors"
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/system"
+	op_e2e "github.com/tokamak-network/tokamak-thanos/op-e2e"
+	"github.com/tokamak-network/tokamak-thanos/op-e2e/e2eutils/challenger"
+)
+
+const (
+	BatcherService    = "op-batcher"
+	ProposerService   = "op-proposer"
+	ChallengerService = "op-challenger"
+)
+
+type config struct {
+	name       string
+	sysConfig  []func(cfg *op_e2e.SystemConfig)
+	sysOpts    []op_e2e.SystemConfigOption
+	challenger []challenger.Option
+	// withChallenger is separate from the challenger options, since it may be enabled without options.
+	withChallenger bool
+}
+
+type Option func(cfg *config)
+
+// WithName sets the name of the devnet, which is also the host part of its URL.
+// The name defaults to one derived from the test name.
+func WithName(name string) Option {
+	return func(cfg *config) {
+		cfg.name = name
+	}
+}
+
+// WithSystemConfig modifies the op-e2e system config before the system is started.
+func WithSystemConfig(fn func(cfg *op_e2e.SystemConfig)) Option {
+	return func(cfg *config) {
+		cfg.sysConfig = append(cfg.sysConfig, fn)
+	}
+}
+
+// WithSystemConfigOptions passes the options to the start of the op-e2e system.
+func WithSystemConfigOptions(opts ...op_e2e.SystemConfigOption) Option {
+	return func(cfg *config) {
+		cfg.sysOpts = append(cfg.sysOpts, opts...)
+	}
+}
+
+// WithChallenger runs an op-challenger against the dispute game factory of the devnet.
+// The challenger plays alphabet games, unless other trace types are configured with the options.
+func WithChallenger(opts ...challenger.Option) Option {
+	return func(cfg *config) {
+		cfg.withChallenger = true
+		cfg.challenger = append(cfg.challenger, opts...)
+	}
+}
+
+// Devnet is a devnet that runs within the test process: an L1 and a Thanos L2,
+// with op-node, op-batcher, op-proposer and optionally op-challenger.
+// Multiple L2 chains are not supported: the L1 genesis of the op-e2e system, from the devnet allocs,
+// contains the L1 contracts of a single L2 chain, and an L1 cannot be shared between op-e2e systems.
+// Tests that need several L2 chains must run against a Kurtosis devnet.
+type Devnet struct {
+	Env *descriptors.DevnetEnvironment
+	Sys *op_e2e.System
+
+	ctrl *local.LocalControllerSurface
+}
+
+// NewDevnet starts an in-process devnet, which is stopped when the test completes.
+// The devnet is registered under its URL, so it can be used like any other devnet-sdk devnet,
+// e.g. with system.NewSystemFromURL, by the tests that run in the same process.
+func NewDevnet(t *testing.T, opts ...Option) *Devnet {
+	cfg := &config{
+		name: devnetName(t.Name()),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	sysCfg := op_e2e.DefaultSystemConfig(t)
+	for _, fn := range cfg.sysConfig {
+		fn(&sysCfg)
+	}
+	sys, err := sysCfg.Start(t, cfg.sysOpts...)
+	require.NoError(t, err, "failed to start op-e2e system")
+	t.Cleanup(sys.Close)
+
+	services := make(map[string]local.Service)
+	if sys.BatchSubmitter != nil {
+		services[BatcherService] = &batcherService{sys: sys}
+	}
+	if sys.L2OutputSubmitter != nil {
+		services[ProposerService] = &proposerService{sys: sys}
+	}
+	if cfg.withChallenger {
+		chl := &challengerService{t: t, sys: sys, opts: append([]challenger.Option{
+			challenger.WithAlphabet(),
+			challenger.WithFactoryAddress(sys.Cfg.L1Deployments.DisputeGameFactoryProxy),
+			challenger.WithPrivKey(sys.Cfg.Secrets.Alice),
+		}, cfg.challenger...)}
+		require.NoError(t, chl.Start(context.Background()), "failed to start op-challenger")
+		t.Cleanup(func() {
+			_ = chl.Stop(context.Background())
+		})
+		services[ChallengerService] = chl
+	}
+
+	env, err := describe(cfg.name, sys, services)
+	require.NoError(t, err, "failed to describe devnet")
+	ctrl := local.NewLocalControllerSurface(env, services)
+	unregister, err := local.Register(env, ctrl)
+	require.NoError(t, err)
+	t.Cleanup(unregister)
+
+	return &Devnet{Env: env, Sys: sys, ctrl: ctrl}
+}
+
+// URL returns the devnet URL, to load the devnet with, e.g. as DEVNET_ENV_URL.
+// The URL can only be resolved within this process.
+func (d *Devnet) URL() string {
+	return local.URL(d.Env.Name)
+}
+
+// System returns the devnet-sdk system of the devnet.
+func (d *Devnet) System() (system.System, error) {
+	return system.NewSystemFromURL(d.URL())
+}
+
+// Control returns the control surface, to stop and start the services of the devnet.
+func (d *Devnet) Control() surface.ControlSurface {
+	return d.ctrl
+}
+
+// devnetName returns a devnet name that is safe to use as URL host.
+func devnetName(testName string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(testName) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	return fmt.Sprintf("inprocess-%s", strings.Trim(b.String(), "-"))
+}
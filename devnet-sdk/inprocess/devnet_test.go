@@ -0,0 +1,61 @@
+package inprocess
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/surface"
+	"github.com/tokamak-network/tokamak-thanos/op-e2e/config"
+)
+
+func TestDevnetName(t *testing.T) {
+	require.Equal(t, "inprocess-testfoo-sub-case-1", devnetName("TestFoo/sub_case#1"))
+}
+
+func TestPortInfo(t *testing.T) {
+	info, err := portInfo("ws://127.0.0.1:8546")
+	require.NoError(t, err)
+	require.Equal(t, "ws", info.Scheme)
+	require.Equal(t, "127.0.0.1", info.Host)
+	require.Equal(t, 8546, info.Port)
+
+	_, err = portInfo("http://127.0.0.1")
+	require.Error(t, err)
+}
+
+func TestInProcessDevnet(t *testing.T) {
+	if config.L1Allocs == nil {
+		// CI generates the allocs, the test must not be skipped there.
+		if os.Getenv("CI") != "" {
+			t.Fatal("op-e2e allocs are not available, run make devnet-allocs to generate them")
+		}
+		t.Skip("op-e2e allocs are not available, run make devnet-allocs to generate them")
+	}
+	dn := NewDevnet(t)
+
+	sys, err := dn.System()
+	require.NoError(t, err)
+	require.Equal(t, dn.Env.Name, sys.Identifier())
+	require.Len(t, sys.L2s(), 1)
+
+	l2 := sys.L2s()[0]
+	require.Zero(t, dn.Sys.RollupConfig.L2ChainID.Cmp(l2.ID()))
+	require.NotEmpty(t, l2.Wallets())
+	require.NotEmpty(t, l2.L1Wallets())
+
+	ctx := context.Background()
+	l2Client, err := l2.Nodes()[0].GethClient()
+	require.NoError(t, err)
+	chainID, err := l2Client.ChainID(ctx)
+	require.NoError(t, err)
+	require.Zero(t, dn.Sys.RollupConfig.L2ChainID.Cmp(chainID), "node must be an L2 node")
+
+	ctrl, ok := dn.Control().(surface.ServiceLifecycleSurface)
+	require.True(t, ok)
+	require.NoError(t, ctrl.StopService(ctx, BatcherService))
+	require.NoError(t, ctrl.StartService(ctx, BatcherService))
+	require.Error(t, ctrl.StartService(ctx, ChallengerService), "challenger is not enabled")
+}
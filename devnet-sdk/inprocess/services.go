@@ -0,0 +1,69 @@
+package inprocess
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/local"
+	op_e2e "github.com/tokamak-network/tokamak-thanos/op-e2e"
+	"github.com/tokamak-network/tokamak-thanos/op-e2e/e2eutils/challenger"
+)
+
+// batcherService pauses and resumes batch submission, the batcher service itself keeps running.
+type batcherService struct {
+	sys *op_e2e.System
+}
+
+func (s *batcherService) Start(ctx context.Context) error {
+	return s.sys.BatchSubmitter.Driver().StartBatchSubmitting()
+}
+
+func (s *batcherService) Stop(ctx context.Context) error {
+	return s.sys.BatchSubmitter.Driver().StopBatchSubmitting(ctx)
+}
+
+// proposerService pauses and resumes output submission, the proposer service itself keeps running.
+type proposerService struct {
+	sys *op_e2e.System
+}
+
+func (s *proposerService) Start(ctx context.Context) error {
+	return s.sys.L2OutputSubmitter.Driver().StartL2OutputSubmitting()
+}
+
+func (s *proposerService) Stop(ctx context.Context) error {
+	return s.sys.L2OutputSubmitter.Driver().StopL2OutputSubmitting()
+}
+
+// challengerService runs a new op-challenger instance on every start.
+type challengerService struct {
+	t    *testing.T
+	sys  *op_e2e.System
+	opts []challenger.Option
+
+	helper *challenger.Helper
+}
+
+func (s *challengerService) Start(ctx context.Context) error {
+	if s.helper != nil {
+		return errors.New("op-challenger is already running")
+	}
+	s.helper = challenger.NewChallenger(s.t, ctx, s.sys, "challenger", s.opts...)
+	return nil
+}
+
+func (s *challengerService) Stop(ctx context.Context) error {
+	if s.helper == nil {
+		return nil
+	}
+	err := s.helper.Close()
+	s.helper = nil
+	return err
+}
+
+var (
+	_ local.Service = (*batcherService)(nil)
+	_ local.Service = (*proposerService)(nil)
+	_ local.Service = (*challengerService)(nil)
+)
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/kt"
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/local"
 	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/surface"
 	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/descriptors"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
@@ -50,6 +51,7 @@ var (
 		"file":     {fetchFileData, nil},
 		"kt":       {ktFetcher.fetchKurtosisData, getKurtosisController},
 		"ktnative": {fetchKurtosisNativeData, getKurtosisController},
+		local.Scheme: {fetchLocalData, getLocalController},
 	}
 )
 
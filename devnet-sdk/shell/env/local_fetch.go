@@ -0,0 +1,39 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/local"
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/surface"
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/descriptors"
+)
+
+// fetchLocalData resolves a devnet that runs in-process, registered under the host part of the URL.
+func fetchLocalData(u *url.URL) (*descriptors.DevnetEnvironment, error) {
+	env, _, err := local.Lookup(u.Host)
+	if err != nil {
+		return nil, err
+	}
+	// Copy the descriptor, so the fixups do not modify the registered devnet.
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding devnet descriptor: %w", err)
+	}
+	var config descriptors.DevnetEnvironment
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error decoding devnet descriptor: %w", err)
+	}
+	return &config, nil
+}
+
+func getLocalController(env *descriptors.DevnetEnvironment) surfaceGetter {
+	return func() (surface.ControlSurface, error) {
+		_, ctrl, err := local.Lookup(env.Name)
+		if err != nil {
+			return nil, err
+		}
+		return ctrl, nil
+	}
+}
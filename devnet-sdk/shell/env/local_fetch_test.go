@@ -0,0 +1,44 @@
+package env
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/controller/local"
+	"github.com/tokamak-network/tokamak-thanos/devnet-sdk/descriptors"
+)
+
+type nopService struct{}
+
+func (nopService) Start(ctx context.Context) error { return nil }
+func (nopService) Stop(ctx context.Context) error  { return nil }
+
+func TestLoadLocalDevnet(t *testing.T) {
+	env := &descriptors.DevnetEnvironment{
+		Name: "local-fetch-test",
+		L1:   &descriptors.Chain{Name: "l1", ID: "900"},
+		L2: []*descriptors.L2Chain{{
+			Chain: &descriptors.Chain{Name: "l2", ID: "0x385"},
+		}},
+	}
+	ctrl := local.NewLocalControllerSurface(env, map[string]local.Service{"op-batcher": nopService{}})
+
+	_, err := LoadDevnetFromURL(local.URL(env.Name))
+	require.ErrorContains(t, err, "no in-process devnet")
+
+	unregister, err := local.Register(env, ctrl)
+	require.NoError(t, err)
+	t.Cleanup(unregister)
+
+	devnet, err := LoadDevnetFromURL(local.URL(env.Name))
+	require.NoError(t, err)
+	require.Equal(t, env.Name, devnet.Env.Name)
+	require.Equal(t, "901", devnet.Env.L2[0].ID, "chain ID is normalized")
+	require.Equal(t, "0x385", env.L2[0].ID, "registered devnet must not be modified")
+
+	control, err := devnet.Control()
+	require.NoError(t, err)
+	require.Same(t, ctrl, control)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/urfave/cli/v2"
@@ -27,18 +28,38 @@ func StartDAServer(cliCtx *cli.Context) error {
 
 	l.Info("Initializing AltDA server...")
 
-	var store altda.KVStore
+	var backends []Backend
 
-	if cfg.FileStoreEnabled() {
-		l.Info("Using file storage", "path", cfg.FileStoreDirPath)
-		store = NewFileStore(cfg.FileStoreDirPath)
-	} else if cfg.S3Enabled() {
+	for _, dir := range cfg.FileStoreDirPaths {
+		l.Info("Using file storage", "path", dir)
+		backends = append(backends, Backend{Name: "file:" + dir, Store: NewFileStore(dir)})
+	}
+	if cfg.S3Enabled() {
 		l.Info("Using S3 storage", "bucket", cfg.S3Config().Bucket)
 		s3, err := NewS3Store(cfg.S3Config())
 		if err != nil {
 			return fmt.Errorf("failed to create S3 store: %w", err)
 		}
-		store = s3
+		backends = append(backends, Backend{Name: "s3:" + cfg.S3Config().Bucket, Store: s3})
+	}
+
+	var store altda.KVStore
+	if len(backends) == 1 && cfg.ScrubInterval == 0 {
+		store = backends[0].Store
+	} else {
+		replicated, err := NewReplicatedStore(l, backends, int(cfg.WriteQuorum), cfg.ReadRepair)
+		if err != nil {
+			return fmt.Errorf("failed to create replicated store: %w", err)
+		}
+		l.Info("Replicating storage", "backends", len(backends), "write_quorum", cfg.WriteQuorum, "read_repair", cfg.ReadRepair)
+		store = replicated
+
+		if cfg.ScrubInterval > 0 {
+			l.Info("Scrubbing storage", "interval", cfg.ScrubInterval, "repair", cfg.ScrubRepair)
+			scrubCtx, cancel := context.WithCancel(cliCtx.Context)
+			defer cancel()
+			go NewScrubber(l, replicated, cfg.ScrubInterval, cfg.ScrubRepair).Run(scrubCtx)
+		}
 	}
 
 	server := altda.NewDAServer(cliCtx.String(ListenAddrFlagName), cliCtx.Int(PortFlagName), store, l, cfg.UseGenericComm)
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a local stand-in for an S3-compatible service, implementing just enough of the API
+// (object get and put, bucket listing and location) for the S3Store. Signatures are not checked.
+type fakeS3 struct {
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeS3(t *testing.T, bucket string) (*fakeS3, S3Config) {
+	f := &fakeS3{bucket: bucket, objects: make(map[string][]byte)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, S3Config{
+		Bucket:          bucket,
+		Endpoint:        strings.TrimPrefix(srv.URL, "http://"),
+		AccessKeyID:     "test",
+		AccessKeySecret: "test-secret",
+		Insecure:        true,
+	}
+}
+
+func (f *fakeS3) object(key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.objects[key]
+	return v, ok
+}
+
+func (f *fakeS3) setObject(key string, value []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[key] = value
+}
+
+func (f *fakeS3) deleteObject(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, key)
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket != f.bucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch {
+	case key == "" && r.URL.Query().Has("location"):
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LocationConstraint"`
+		}{})
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w)
+	case r.Method == http.MethodPut:
+		body, err := readS3Body(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		f.setObject(key, body)
+		w.Header().Set("ETag", `"fake"`)
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		value, ok := f.object(key)
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(value)))
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		w.Header().Set("ETag", `"fake"`)
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(value)
+		}
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter) {
+	type content struct {
+		Key  string `xml:"Key"`
+		Size int    `xml:"Size"`
+	}
+	f.mu.Lock()
+	contents := make([]content, 0, len(f.objects))
+	for k, v := range f.objects {
+		contents = append(contents, content{Key: k, Size: len(v)})
+	}
+	f.mu.Unlock()
+	sort.Slice(contents, func(i, j int) bool { return contents[i].Key < contents[j].Key })
+
+	writeXML(w, struct {
+		XMLName     xml.Name  `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+		Name        string    `xml:"Name"`
+		KeyCount    int       `xml:"KeyCount"`
+		MaxKeys     int       `xml:"MaxKeys"`
+		IsTruncated bool      `xml:"IsTruncated"`
+		Contents    []content `xml:"Contents"`
+	}{Name: f.bucket, KeyCount: len(contents), MaxKeys: 1000, Contents: contents})
+}
+
+// readS3Body reads the object from a put request, decoding the aws-chunked encoding
+// that is used for streaming signatures over plain HTTP.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	var out []byte
+	br := bufio.NewReader(r.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseUint(sizeHex, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chunk size %q: %w", sizeHex, err)
+		}
+		if size == 0 {
+			return out, nil
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+		if _, err := br.ReadString('\n'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: code})
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func TestFakeS3Store(t *testing.T) {
+	_, cfg := newFakeS3(t, "altda")
+	store, err := NewS3Store(cfg)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, []byte{0x00, 0x01}, []byte("hello")))
+	value, err := store.Get(ctx, []byte{0x00, 0x01})
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), value)
+
+	keys, err := store.ListKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0x00, 0x01}}, keys)
+}
@@ -34,6 +34,27 @@ func (s *FileStore) Put(ctx context.Context, key []byte, value []byte) error {
 	return os.WriteFile(s.fileName(key), value, 0600)
 }
 
+// ListKeys returns the keys of all values in the store directory.
+func (s *FileStore) ListKeys(ctx context.Context) ([][]byte, error) {
+	entries, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		key, err := hex.DecodeString(entry.Name())
+		if err != nil {
+			// not a stored value
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
 func (s *FileStore) fileName(key []byte) string {
 	return path.Join(s.directory, hex.EncodeToString(key))
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/urfave/cli/v2"
 
@@ -17,8 +18,13 @@ const (
 	S3EndpointFlagName        = "s3.endpoint"
 	S3AccessKeyIDFlagName     = "s3.access-key-id"
 	S3AccessKeySecretFlagName = "s3.access-key-secret"
+	S3InsecureFlagName        = "s3.insecure"
 	FileStorePathFlagName     = "file.path"
 	GenericCommFlagName       = "generic-commitment"
+	WriteQuorumFlagName       = "replication.write-quorum"
+	ReadRepairFlagName        = "replication.read-repair"
+	ScrubIntervalFlagName     = "scrub.interval"
+	ScrubRepairFlagName       = "scrub.repair"
 )
 
 const EnvVarPrefix = "OP_ALTDA_SERVER"
@@ -40,9 +46,9 @@ var (
 		Value:   3100,
 		EnvVars: prefixEnvVars("PORT"),
 	}
-	FileStorePathFlag = &cli.StringFlag{
+	FileStorePathFlag = &cli.StringSliceFlag{
 		Name:    FileStorePathFlagName,
-		Usage:   "path to directory for file storage",
+		Usage:   "path to directory for file storage. Can be repeated to replicate to multiple directories",
 		EnvVars: prefixEnvVars("FILESTORE_PATH"),
 	}
 	GenericCommFlag = &cli.BoolFlag{
@@ -74,6 +80,36 @@ var (
 		Value:   "",
 		EnvVars: prefixEnvVars("S3_ACCESS_KEY_SECRET"),
 	}
+	S3InsecureFlag = &cli.BoolFlag{
+		Name:    S3InsecureFlagName,
+		Usage:   "connect to the S3 endpoint over plain HTTP, e.g. for a local S3-compatible service",
+		Value:   false,
+		EnvVars: prefixEnvVars("S3_INSECURE"),
+	}
+	WriteQuorumFlag = &cli.UintFlag{
+		Name:    WriteQuorumFlagName,
+		Usage:   "number of storage backends that must store an input for a put to succeed. 0 means all backends",
+		Value:   0,
+		EnvVars: prefixEnvVars("WRITE_QUORUM"),
+	}
+	ReadRepairFlag = &cli.BoolFlag{
+		Name:    ReadRepairFlagName,
+		Usage:   "write inputs back to storage backends that are missing them or hold a corrupt copy when read",
+		Value:   true,
+		EnvVars: prefixEnvVars("READ_REPAIR"),
+	}
+	ScrubIntervalFlag = &cli.DurationFlag{
+		Name:    ScrubIntervalFlagName,
+		Usage:   "interval to check all storage backends for missing or corrupt inputs. 0 disables scrubbing",
+		Value:   0,
+		EnvVars: prefixEnvVars("SCRUB_INTERVAL"),
+	}
+	ScrubRepairFlag = &cli.BoolFlag{
+		Name:    ScrubRepairFlagName,
+		Usage:   "repair missing or corrupt inputs found while scrubbing",
+		Value:   false,
+		EnvVars: prefixEnvVars("SCRUB_REPAIR"),
+	}
 )
 
 var requiredFlags = []cli.Flag{
@@ -87,7 +123,12 @@ var optionalFlags = []cli.Flag{
 	S3EndpointFlag,
 	S3AccessKeyIDFlag,
 	S3AccessKeySecretFlag,
+	S3InsecureFlag,
 	GenericCommFlag,
+	WriteQuorumFlag,
+	ReadRepairFlag,
+	ScrubIntervalFlag,
+	ScrubRepairFlag,
 }
 
 func init() {
@@ -99,22 +140,32 @@ func init() {
 var Flags []cli.Flag
 
 type CLIConfig struct {
-	FileStoreDirPath  string
+	FileStoreDirPaths []string
 	S3Bucket          string
 	S3Endpoint        string
 	S3AccessKeyID     string
 	S3AccessKeySecret string
+	S3Insecure        bool
 	UseGenericComm    bool
+	WriteQuorum       uint
+	ReadRepair        bool
+	ScrubInterval     time.Duration
+	ScrubRepair       bool
 }
 
 func ReadCLIConfig(ctx *cli.Context) CLIConfig {
 	return CLIConfig{
-		FileStoreDirPath:  ctx.String(FileStorePathFlagName),
+		FileStoreDirPaths: ctx.StringSlice(FileStorePathFlagName),
 		S3Bucket:          ctx.String(S3BucketFlagName),
 		S3Endpoint:        ctx.String(S3EndpointFlagName),
 		S3AccessKeyID:     ctx.String(S3AccessKeyIDFlagName),
 		S3AccessKeySecret: ctx.String(S3AccessKeySecretFlagName),
+		S3Insecure:        ctx.Bool(S3InsecureFlagName),
 		UseGenericComm:    ctx.Bool(GenericCommFlagName),
+		WriteQuorum:       ctx.Uint(WriteQuorumFlagName),
+		ReadRepair:        ctx.Bool(ReadRepairFlagName),
+		ScrubInterval:     ctx.Duration(ScrubIntervalFlagName),
+		ScrubRepair:       ctx.Bool(ScrubRepairFlagName),
 	}
 }
 
@@ -122,15 +173,27 @@ func (c CLIConfig) Check() error {
 	if !c.S3Enabled() && !c.FileStoreEnabled() {
 		return errors.New("at least one storage backend must be enabled")
 	}
-	if c.S3Enabled() && c.FileStoreEnabled() {
-		return errors.New("only one storage backend can be enabled")
-	}
 	if c.S3Enabled() && (c.S3Bucket == "" || c.S3Endpoint == "" || c.S3AccessKeyID == "" || c.S3AccessKeySecret == "") {
 		return errors.New("all S3 flags must be set")
 	}
+	if c.WriteQuorum > uint(c.NumBackends()) {
+		return fmt.Errorf("write quorum %d exceeds the number of storage backends %d", c.WriteQuorum, c.NumBackends())
+	}
+	if c.ScrubInterval < 0 {
+		return errors.New("scrub interval must not be negative")
+	}
 	return nil
 }
 
+// NumBackends returns the number of configured storage backends.
+func (c CLIConfig) NumBackends() int {
+	n := len(c.FileStoreDirPaths)
+	if c.S3Enabled() {
+		n++
+	}
+	return n
+}
+
 func (c CLIConfig) S3Enabled() bool {
 	return !(c.S3Bucket == "" && c.S3Endpoint == "" && c.S3AccessKeyID == "" && c.S3AccessKeySecret == "")
 }
@@ -141,11 +204,12 @@ func (c CLIConfig) S3Config() S3Config {
 		Endpoint:        c.S3Endpoint,
 		AccessKeyID:     c.S3AccessKeyID,
 		AccessKeySecret: c.S3AccessKeySecret,
+		Insecure:        c.S3Insecure,
 	}
 }
 
 func (c CLIConfig) FileStoreEnabled() bool {
-	return c.FileStoreDirPath != ""
+	return len(c.FileStoreDirPaths) > 0
 }
 
 func CheckRequired(ctx *cli.Context) error {
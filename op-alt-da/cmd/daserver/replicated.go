@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+)
+
+// KeyLister is implemented by storage backends that can enumerate their stored keys,
+// which is required for a backend to take part in scrubbing.
+type KeyLister interface {
+	ListKeys(ctx context.Context) ([][]byte, error)
+}
+
+// Backend is a named storage backend of a ReplicatedStore.
+type Backend struct {
+	Name  string
+	Store altda.KVStore
+}
+
+// ReplicatedStore is a KVStore that replicates every value to all of its backends.
+// Writes succeed once the write quorum of backends stored the value.
+// Reads try the backends in order, verify the value against the commitment key,
+// and write the value back to backends that were missing it or held a corrupt copy.
+type ReplicatedStore struct {
+	log         log.Logger
+	backends    []Backend
+	writeQuorum int
+	readRepair  bool
+}
+
+var _ altda.KVStore = (*ReplicatedStore)(nil)
+
+// NewReplicatedStore creates a ReplicatedStore over the given backends.
+// A writeQuorum of 0 requires all backends to store a value for a write to succeed.
+func NewReplicatedStore(log log.Logger, backends []Backend, writeQuorum int, readRepair bool) (*ReplicatedStore, error) {
+	if len(backends) == 0 {
+		return nil, errors.New("at least one backend is required")
+	}
+	if writeQuorum == 0 {
+		writeQuorum = len(backends)
+	}
+	if writeQuorum < 0 || writeQuorum > len(backends) {
+		return nil, fmt.Errorf("write quorum %d must be between 1 and the number of backends %d", writeQuorum, len(backends))
+	}
+	return &ReplicatedStore{
+		log:         log,
+		backends:    backends,
+		writeQuorum: writeQuorum,
+		readRepair:  readRepair,
+	}, nil
+}
+
+// Put stores the value in all backends concurrently and returns an error if fewer than
+// the write quorum of backends succeeded.
+func (s *ReplicatedStore) Put(ctx context.Context, key []byte, value []byte) error {
+	errs := make([]error, len(s.backends))
+	var wg sync.WaitGroup
+	for i, b := range s.backends {
+		wg.Add(1)
+		go func(i int, b Backend) {
+			defer wg.Done()
+			if err := b.Store.Put(ctx, key, value); err != nil {
+				errs[i] = fmt.Errorf("backend %s: %w", b.Name, err)
+			}
+		}(i, b)
+	}
+	wg.Wait()
+
+	stored := 0
+	for i, err := range errs {
+		if err != nil {
+			s.log.Warn("Failed to store value in backend", "backend", s.backends[i].Name, "key", keyString(key), "err", err)
+		} else {
+			stored++
+		}
+	}
+	if stored < s.writeQuorum {
+		return fmt.Errorf("write quorum not reached, stored in %d of %d backends (quorum %d): %w",
+			stored, len(s.backends), s.writeQuorum, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Get returns the first value found in the backends that matches the commitment key.
+// It returns altda.ErrNotFound only if every backend reported the key as missing.
+func (s *ReplicatedStore) Get(ctx context.Context, key []byte) ([]byte, error) {
+	var repair []Backend
+	var errs []error
+	notFound := 0
+	for _, b := range s.backends {
+		value, err := b.Store.Get(ctx, key)
+		if errors.Is(err, altda.ErrNotFound) {
+			notFound++
+			repair = append(repair, b)
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("backend %s: %w", b.Name, err))
+			continue
+		}
+		if err := verifyValue(key, value); err != nil {
+			s.log.Warn("Backend returned corrupt value", "backend", b.Name, "key", keyString(key), "err", err)
+			errs = append(errs, fmt.Errorf("backend %s: %w", b.Name, err))
+			repair = append(repair, b)
+			continue
+		}
+		if s.readRepair {
+			s.repair(ctx, key, value, repair)
+		}
+		return value, nil
+	}
+	if notFound == len(s.backends) {
+		return nil, altda.ErrNotFound
+	}
+	return nil, errors.Join(errs...)
+}
+
+// repair writes the value to the given backends, which were found to be missing it
+// or to hold a corrupt copy. Failures are only logged, the read itself succeeded.
+func (s *ReplicatedStore) repair(ctx context.Context, key []byte, value []byte, backends []Backend) {
+	for _, b := range backends {
+		if err := b.Store.Put(ctx, key, value); err != nil {
+			s.log.Warn("Failed to repair value in backend", "backend", b.Name, "key", keyString(key), "err", err)
+			continue
+		}
+		s.log.Info("Repaired value in backend", "backend", b.Name, "key", keyString(key))
+	}
+}
+
+// Backends returns the backends of the store, in read order.
+func (s *ReplicatedStore) Backends() []Backend {
+	return s.backends
+}
+
+// verifyValue checks the value against the commitment it is stored under.
+// Keys that are not valid commitments, and generic commitments, cannot be verified and are accepted.
+func verifyValue(key []byte, value []byte) error {
+	comm, err := altda.DecodeCommitmentData(key)
+	if err != nil {
+		return nil
+	}
+	return comm.Verify(value)
+}
+
+func keyString(key []byte) string {
+	return fmt.Sprintf("0x%x", key)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+// failingStore is a backend that is unreachable.
+type failingStore struct{}
+
+func (failingStore) Get(ctx context.Context, key []byte) ([]byte, error) {
+	return nil, errors.New("backend unreachable")
+}
+
+func (failingStore) Put(ctx context.Context, key []byte, value []byte) error {
+	return errors.New("backend unreachable")
+}
+
+type testBackends struct {
+	dirA, dirB string
+	s3         *fakeS3
+	backends   []Backend
+}
+
+func newTestBackends(t *testing.T) *testBackends {
+	dirA, dirB := t.TempDir(), t.TempDir()
+	s3, s3Cfg := newFakeS3(t, "altda")
+	s3Store, err := NewS3Store(s3Cfg)
+	require.NoError(t, err)
+	return &testBackends{
+		dirA: dirA,
+		dirB: dirB,
+		s3:   s3,
+		backends: []Backend{
+			{Name: "file-a", Store: NewFileStore(dirA)},
+			{Name: "file-b", Store: NewFileStore(dirB)},
+			{Name: "s3", Store: s3Store},
+		},
+	}
+}
+
+func TestReplicatedStorePutGet(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	ctx := context.Background()
+	tb := newTestBackends(t)
+
+	store, err := NewReplicatedStore(logger, tb.backends, 0, true)
+	require.NoError(t, err)
+
+	input := []byte("some altDA input")
+	key := altda.NewKeccak256Commitment(input).Encode()
+	require.NoError(t, store.Put(ctx, key, input))
+
+	for _, b := range tb.backends {
+		value, err := b.Store.Get(ctx, key)
+		require.NoError(t, err, b.Name)
+		require.Equal(t, input, value, b.Name)
+	}
+
+	value, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, input, value)
+
+	_, err = store.Get(ctx, altda.NewKeccak256Commitment([]byte("unknown")).Encode())
+	require.ErrorIs(t, err, altda.ErrNotFound)
+}
+
+func TestReplicatedStoreWriteQuorum(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	ctx := context.Background()
+	dir := t.TempDir()
+	backends := []Backend{
+		{Name: "file", Store: NewFileStore(dir)},
+		{Name: "down", Store: failingStore{}},
+	}
+	input := []byte("some altDA input")
+	key := altda.NewKeccak256Commitment(input).Encode()
+
+	all, err := NewReplicatedStore(logger, backends, 0, true)
+	require.NoError(t, err)
+	require.ErrorContains(t, all.Put(ctx, key, input), "write quorum not reached")
+
+	one, err := NewReplicatedStore(logger, backends, 1, true)
+	require.NoError(t, err)
+	require.NoError(t, one.Put(ctx, key, input))
+
+	// the unreachable backend is skipped on read
+	value, err := one.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, input, value)
+
+	_, err = NewReplicatedStore(logger, backends, 3, true)
+	require.ErrorContains(t, err, "write quorum 3")
+}
+
+func TestReplicatedStoreReadRepair(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	ctx := context.Background()
+	tb := newTestBackends(t)
+
+	store, err := NewReplicatedStore(logger, tb.backends, 0, true)
+	require.NoError(t, err)
+
+	input := []byte("some altDA input")
+	key := altda.NewKeccak256Commitment(input).Encode()
+	require.NoError(t, store.Put(ctx, key, input))
+
+	// corrupt the first copy, and lose the S3 copy
+	require.NoError(t, os.WriteFile(filepath.Join(tb.dirA, hex.EncodeToString(key)), []byte("corrupt"), 0600))
+	tb.s3.deleteObject(hex.EncodeToString(key))
+
+	value, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, input, value)
+
+	// both copies have been repaired from the second file backend
+	repaired, err := os.ReadFile(filepath.Join(tb.dirA, hex.EncodeToString(key)))
+	require.NoError(t, err)
+	require.Equal(t, input, repaired)
+	s3Value, ok := tb.s3.object(hex.EncodeToString(key))
+	require.True(t, ok)
+	require.Equal(t, input, s3Value)
+}
+
+func TestReplicatedStoreAllCorrupt(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewReplicatedStore(logger, []Backend{{Name: "file", Store: NewFileStore(dir)}}, 0, true)
+	require.NoError(t, err)
+
+	key := altda.NewKeccak256Commitment([]byte("input")).Encode()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hex.EncodeToString(key)), []byte("corrupt"), 0600))
+
+	_, err = store.Get(ctx, key)
+	require.ErrorIs(t, err, altda.ErrCommitmentMismatch)
+}
+
+func TestReplicatedStoreGenericCommitment(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	ctx := context.Background()
+	tb := newTestBackends(t)
+
+	store, err := NewReplicatedStore(logger, tb.backends, 2, true)
+	require.NoError(t, err)
+
+	// generic commitments cannot be verified, so any value is accepted
+	key := altda.NewGenericCommitment([]byte{0xff, 0x01, 0x02}).Encode()
+	require.NoError(t, store.Put(ctx, key, []byte("opaque")))
+	value, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, []byte("opaque"), value)
+}
@@ -17,6 +17,8 @@ type S3Config struct {
 	Endpoint        string
 	AccessKeyID     string
 	AccessKeySecret string
+	// Insecure connects to the endpoint over plain HTTP, e.g. for a local S3-compatible service.
+	Insecure bool
 }
 
 type S3Store struct {
@@ -27,7 +29,7 @@ type S3Store struct {
 func NewS3Store(cfg S3Config) (*S3Store, error) {
 	client, err := minio.New(cfg.Endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.AccessKeySecret, ""),
-		Secure: true,
+		Secure: !cfg.Insecure,
 	})
 	if err != nil {
 		return nil, err
@@ -50,6 +52,10 @@ func (s *S3Store) Get(ctx context.Context, key []byte) ([]byte, error) {
 	defer result.Close()
 	data, err := io.ReadAll(result)
 	if err != nil {
+		// the object is fetched lazily, so a missing key may only be reported on read
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, altda.ErrNotFound
+		}
 		return nil, err
 	}
 
@@ -61,3 +67,20 @@ func (s *S3Store) Put(ctx context.Context, key []byte, value []byte) error {
 
 	return err
 }
+
+// ListKeys returns the keys of all objects in the bucket.
+func (s *S3Store) ListKeys(ctx context.Context) ([][]byte, error) {
+	var keys [][]byte
+	for obj := range s.client.ListObjects(ctx, s.cfg.Bucket, minio.ListObjectsOptions{}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		key, err := hex.DecodeString(obj.Key)
+		if err != nil {
+			// not a stored value
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+)
+
+// IssueKind describes why a backend's copy of a value needs attention.
+type IssueKind string
+
+const (
+	IssueMissing IssueKind = "missing"
+	IssueCorrupt IssueKind = "corrupt"
+	// IssueUnreadable is reported when a backend failed to return a value, e.g. because it is unreachable.
+	IssueUnreadable IssueKind = "unreadable"
+	// IssueLost is reported when no backend holds a valid copy of a value.
+	IssueLost IssueKind = "lost"
+)
+
+// ScrubIssue is a single problem found by the Scrubber.
+type ScrubIssue struct {
+	Key      []byte
+	Backend  string
+	Kind     IssueKind
+	Repaired bool
+}
+
+// ScrubReport summarizes a single scrubbing pass.
+type ScrubReport struct {
+	Checked int
+	Issues  []ScrubIssue
+}
+
+// Count returns the number of issues of the given kind.
+func (r ScrubReport) Count(kind IssueKind) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Scrubber checks that every key known to any backend of a ReplicatedStore is present
+// and valid in all backends, and optionally repairs missing or corrupt copies.
+type Scrubber struct {
+	log      log.Logger
+	store    *ReplicatedStore
+	interval time.Duration
+	repair   bool
+}
+
+func NewScrubber(log log.Logger, store *ReplicatedStore, interval time.Duration, repair bool) *Scrubber {
+	return &Scrubber{
+		log:      log,
+		store:    store,
+		interval: interval,
+		repair:   repair,
+	}
+}
+
+// Run scrubs the store every interval, until the context is cancelled.
+func (s *Scrubber) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.Scrub(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				s.log.Error("Failed to scrub storage backends", "err", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Scrub runs a single pass over all keys listed by the backends and reports every
+// missing, corrupt or unreadable copy.
+func (s *Scrubber) Scrub(ctx context.Context) (ScrubReport, error) {
+	keys, err := s.listKeys(ctx)
+	if err != nil {
+		return ScrubReport{}, err
+	}
+	s.log.Info("Scrubbing storage backends", "keys", len(keys), "backends", len(s.store.Backends()))
+
+	var report ScrubReport
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		report.Issues = append(report.Issues, s.scrubKey(ctx, key)...)
+		report.Checked++
+	}
+
+	for _, issue := range report.Issues {
+		s.log.Warn("Storage backend issue", "key", keyString(issue.Key), "backend", issue.Backend, "kind", issue.Kind, "repaired", issue.Repaired)
+	}
+	s.log.Info("Scrubbed storage backends", "checked", report.Checked,
+		"missing", report.Count(IssueMissing), "corrupt", report.Count(IssueCorrupt),
+		"unreadable", report.Count(IssueUnreadable), "lost", report.Count(IssueLost))
+	return report, nil
+}
+
+func (s *Scrubber) scrubKey(ctx context.Context, key []byte) []ScrubIssue {
+	var issues []ScrubIssue
+	var good []byte
+	// indices into issues, with the backend to repair for each
+	needRepair := make(map[int]Backend)
+	for _, b := range s.store.Backends() {
+		value, err := b.Store.Get(ctx, key)
+		switch {
+		case errors.Is(err, altda.ErrNotFound):
+			issues = append(issues, ScrubIssue{Key: key, Backend: b.Name, Kind: IssueMissing})
+			needRepair[len(issues)-1] = b
+		case err != nil:
+			issues = append(issues, ScrubIssue{Key: key, Backend: b.Name, Kind: IssueUnreadable})
+		case verifyValue(key, value) != nil:
+			issues = append(issues, ScrubIssue{Key: key, Backend: b.Name, Kind: IssueCorrupt})
+			needRepair[len(issues)-1] = b
+		case good == nil:
+			good = value
+		}
+	}
+	if good == nil {
+		return append(issues, ScrubIssue{Key: key, Kind: IssueLost})
+	}
+	if !s.repair {
+		return issues
+	}
+	for i, b := range needRepair {
+		if err := b.Store.Put(ctx, key, good); err != nil {
+			s.log.Warn("Failed to repair value in backend", "backend", b.Name, "key", keyString(key), "err", err)
+			continue
+		}
+		issues[i].Repaired = true
+	}
+	return issues
+}
+
+// listKeys returns the union of the keys of all backends that can list their keys.
+func (s *Scrubber) listKeys(ctx context.Context) ([][]byte, error) {
+	seen := make(map[string]struct{})
+	var keys [][]byte
+	listers := 0
+	for _, b := range s.store.Backends() {
+		lister, ok := b.Store.(KeyLister)
+		if !ok {
+			continue
+		}
+		listers++
+		bkeys, err := lister.ListKeys(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list keys of backend %s: %w", b.Name, err)
+		}
+		for _, k := range bkeys {
+			if _, ok := seen[string(k)]; ok {
+				continue
+			}
+			seen[string(k)] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+	if listers == 0 {
+		return nil, errors.New("no backend supports listing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+func TestScrubber(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	ctx := context.Background()
+	tb := newTestBackends(t)
+
+	// read-repair is disabled, so only the scrubber fixes the backends
+	store, err := NewReplicatedStore(logger, tb.backends, 0, false)
+	require.NoError(t, err)
+
+	inputs := [][]byte{[]byte("first"), []byte("second"), []byte("third")}
+	keys := make([][]byte, len(inputs))
+	for i, input := range inputs {
+		keys[i] = altda.NewKeccak256Commitment(input).Encode()
+		require.NoError(t, store.Put(ctx, keys[i], input))
+	}
+
+	// first: missing in file-b, second: corrupt in s3, third: only present in s3
+	require.NoError(t, os.Remove(filepath.Join(tb.dirB, hex.EncodeToString(keys[0]))))
+	tb.s3.setObject(hex.EncodeToString(keys[1]), []byte("corrupt"))
+	require.NoError(t, os.Remove(filepath.Join(tb.dirA, hex.EncodeToString(keys[2]))))
+	require.NoError(t, os.Remove(filepath.Join(tb.dirB, hex.EncodeToString(keys[2]))))
+
+	report, err := NewScrubber(logger, store, 0, false).Scrub(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Checked)
+	require.Equal(t, 3, report.Count(IssueMissing))
+	require.Equal(t, 1, report.Count(IssueCorrupt))
+	require.Zero(t, report.Count(IssueLost))
+	for _, issue := range report.Issues {
+		require.False(t, issue.Repaired)
+	}
+
+	report, err = NewScrubber(logger, store, 0, true).Scrub(ctx)
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 4)
+	for _, issue := range report.Issues {
+		require.True(t, issue.Repaired, "%s in %s", issue.Kind, issue.Backend)
+	}
+
+	report, err = NewScrubber(logger, store, 0, false).Scrub(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Checked)
+	require.Empty(t, report.Issues)
+}
+
+func TestScrubberLostValue(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewReplicatedStore(logger, []Backend{{Name: "file", Store: NewFileStore(dir)}}, 0, false)
+	require.NoError(t, err)
+
+	key := altda.NewKeccak256Commitment([]byte("input")).Encode()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hex.EncodeToString(key)), []byte("corrupt"), 0600))
+
+	report, err := NewScrubber(logger, store, 0, true).Scrub(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Count(IssueCorrupt))
+	require.Equal(t, 1, report.Count(IssueLost))
+}
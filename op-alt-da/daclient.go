@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 )
@@ -16,6 +17,10 @@ var ErrNotFound = errors.New("not found")
 // ErrInvalidInput is returned when the input is not valid for posting to the DA storage.
 var ErrInvalidInput = errors.New("invalid input")
 
+// ErrAltDADown is returned when the DA server is unavailable, either because it could not be
+// reached or because it signaled so with a 503 status. Callers may fall back to posting on L1.
+var ErrAltDADown = errors.New("alt DA is down: failover to eth DA")
+
 // DAClient is an HTTP client to communicate with a DA storage service.
 // It creates commitments and retrieves input data + verifies if needed.
 type DAClient struct {
@@ -102,9 +107,12 @@ func (c *DAClient) setInputWithCommit(ctx context.Context, comm CommitmentData,
 	client := &http.Client{Timeout: c.putTimeout}
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return wrapUnavailable(err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return ErrAltDADown
+	}
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("failed to store preimage: %v", resp.StatusCode)
 	}
@@ -128,9 +136,12 @@ func (c *DAClient) setInput(ctx context.Context, img []byte) (CommitmentData, er
 	client := &http.Client{Timeout: c.putTimeout}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, wrapUnavailable(err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return nil, ErrAltDADown
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("failed to store data: %v", resp.StatusCode)
 	}
@@ -147,3 +158,13 @@ func (c *DAClient) setInput(ctx context.Context, img []byte) (CommitmentData, er
 
 	return comm, nil
 }
+
+// wrapUnavailable marks errors from failing to connect to the DA server with ErrAltDADown.
+// Other transport errors, such as timeouts or context cancellation, are returned as is.
+func wrapUnavailable(err error) error {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return fmt.Errorf("%w: %w", ErrAltDADown, err)
+	}
+	return err
+}
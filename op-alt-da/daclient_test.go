@@ -61,7 +61,7 @@ func TestDAClientPrecomputed(t *testing.T) {
 	// server not responsive
 	require.NoError(t, server.Stop())
 	_, err = client.SetInput(ctx, input)
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrAltDADown)
 
 	_, err = client.GetInput(ctx, NewKeccak256Commitment(input))
 	require.Error(t, err)
@@ -120,8 +120,44 @@ func TestDAClientService(t *testing.T) {
 	// server not responsive
 	require.NoError(t, server.Stop())
 	_, err = client.SetInput(ctx, input)
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrAltDADown)
 
 	_, err = client.GetInput(ctx, NewKeccak256Commitment(input))
 	require.Error(t, err)
 }
+
+func TestDAClientOutOfService(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+
+	ctx := context.Background()
+
+	server := NewFakeDAServer("127.0.0.1", 0, logger)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		_ = server.Stop()
+	})
+
+	cfg := CLIConfig{
+		Enabled:     true,
+		DAServerURL: server.HttpEndpoint(),
+		GenericDA:   true,
+	}
+	require.NoError(t, cfg.Check())
+
+	client := cfg.NewDAClient()
+
+	rng := rand.New(rand.NewSource(1234))
+	input := RandomData(rng, 2000)
+
+	server.SetOutOfService(true)
+	_, err := client.SetInput(ctx, input)
+	require.ErrorIs(t, err, ErrAltDADown)
+
+	server.SetOutOfService(false)
+	comm, err := client.SetInput(ctx, input)
+	require.NoError(t, err)
+
+	stored, err := client.GetInput(ctx, comm)
+	require.NoError(t, err)
+	require.Equal(t, input, stored)
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
@@ -111,6 +112,8 @@ type FakeDAServer struct {
 	*DAServer
 	putRequestLatency time.Duration
 	getRequestLatency time.Duration
+	// outOfService makes put requests fail with a 503, to test the batcher's fallback to L1.
+	outOfService atomic.Bool
 }
 
 func NewFakeDAServer(host string, port int, log log.Logger) *FakeDAServer {
@@ -130,6 +133,10 @@ func (s *FakeDAServer) HandleGet(w http.ResponseWriter, r *http.Request) {
 
 func (s *FakeDAServer) HandlePut(w http.ResponseWriter, r *http.Request) {
 	time.Sleep(s.putRequestLatency)
+	if s.outOfService.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
 	s.DAServer.HandlePut(w, r)
 }
 
@@ -154,6 +161,11 @@ func (s *FakeDAServer) SetGetRequestLatency(latency time.Duration) {
 	s.getRequestLatency = latency
 }
 
+// SetOutOfService makes the server respond to put requests with a 503 while enabled.
+func (s *FakeDAServer) SetOutOfService(outOfService bool) {
+	s.outOfService.Store(outOfService)
+}
+
 type MemStore struct {
 	db   map[string][]byte
 	lock sync.RWMutex
@@ -0,0 +1,94 @@
+package responder
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/cliiface"
+)
+
+var (
+	EnabledFlagName       = responderFlags("enabled")
+	PollIntervalFlagName  = responderFlags("poll-interval")
+	MaxBlockRangeFlagName = responderFlags("max-block-range")
+)
+
+// responderFlags returns the flag names for the DA challenge responder
+func responderFlags(v string) string {
+	return "altda.responder." + v
+}
+
+func responderEnvs(envprefix, v string) []string {
+	return []string{envprefix + "_ALTDA_RESPONDER_" + v}
+}
+
+func CLIFlags(envPrefix string, category string) []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:     EnabledFlagName,
+			Usage:    "Resolve DA challenges against commitments posted by this batcher, using the input data from the DA server",
+			Value:    false,
+			EnvVars:  responderEnvs(envPrefix, "ENABLED"),
+			Category: category,
+		},
+		&cli.DurationFlag{
+			Name:     PollIntervalFlagName,
+			Usage:    "Interval to check L1 for new DA challenges",
+			Value:    12 * time.Second,
+			EnvVars:  responderEnvs(envPrefix, "POLL_INTERVAL"),
+			Category: category,
+		},
+		&cli.Uint64Flag{
+			Name:     MaxBlockRangeFlagName,
+			Usage:    "Maximum number of L1 blocks to fetch challenge events for in a single request",
+			Value:    1000,
+			EnvVars:  responderEnvs(envPrefix, "MAX_BLOCK_RANGE"),
+			Category: category,
+		},
+	}
+}
+
+type CLIConfig struct {
+	Enabled       bool
+	PollInterval  time.Duration
+	MaxBlockRange uint64
+}
+
+func (c CLIConfig) Check() error {
+	if c.Enabled {
+		if c.PollInterval <= 0 {
+			return errors.New("DA challenge responder poll interval must be positive")
+		}
+		if c.MaxBlockRange == 0 {
+			return errors.New("DA challenge responder max block range must be at least 1")
+		}
+	}
+	return nil
+}
+
+// Config returns the responder config for the given chain parameters.
+func (c CLIConfig) Config(challengeAddr common.Address, resolveWindow uint64, inbox common.Address, batcher common.Address) (Config, error) {
+	if challengeAddr == (common.Address{}) {
+		return Config{}, fmt.Errorf("DA challenge contract address is not configured")
+	}
+	return Config{
+		DAChallengeAddress: challengeAddr,
+		ResolveWindow:      resolveWindow,
+		BatchInboxAddress:  inbox,
+		BatcherAddress:     batcher,
+		PollInterval:       c.PollInterval,
+		MaxBlockRange:      c.MaxBlockRange,
+	}, nil
+}
+
+func ReadCLIConfig(c cliiface.Context) CLIConfig {
+	return CLIConfig{
+		Enabled:       c.Bool(EnabledFlagName),
+		PollInterval:  c.Duration(PollIntervalFlagName),
+		MaxBlockRange: c.Uint64(MaxBlockRangeFlagName),
+	}
+}
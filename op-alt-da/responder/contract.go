@@ -0,0 +1,40 @@
+package responder
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+	"github.com/tokamak-network/tokamak-thanos/op-alt-da/bindings"
+)
+
+// ChallengeContract is the subset of the DataAvailabilityChallenge contract state the responder reads.
+type ChallengeContract interface {
+	GetChallenge(ctx context.Context, challengedBlockNumber uint64, comm altda.CommitmentData) (bindings.Challenge, error)
+	Balance(ctx context.Context, addr common.Address) (*big.Int, error)
+}
+
+type challengeContract struct {
+	caller *bindings.DataAvailabilityChallengeCaller
+}
+
+// NewChallengeContract binds the DataAvailabilityChallenge contract at the given address.
+func NewChallengeContract(addr common.Address, caller bind.ContractCaller) (ChallengeContract, error) {
+	c, err := bindings.NewDataAvailabilityChallengeCaller(addr, caller)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind DA challenge contract: %w", err)
+	}
+	return &challengeContract{caller: c}, nil
+}
+
+func (c *challengeContract) GetChallenge(ctx context.Context, challengedBlockNumber uint64, comm altda.CommitmentData) (bindings.Challenge, error) {
+	return c.caller.GetChallenge(&bind.CallOpts{Context: ctx}, new(big.Int).SetUint64(challengedBlockNumber), comm.Encode())
+}
+
+func (c *challengeContract) Balance(ctx context.Context, addr common.Address) (*big.Int, error) {
+	return c.caller.Balances(&bind.CallOpts{Context: ctx}, addr)
+}
@@ -0,0 +1,102 @@
+package responder
+
+import (
+	"math/big"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+)
+
+// Outcome is the result of handling a challenge against one of our commitments.
+type Outcome string
+
+const (
+	// OutcomeResolved is recorded when our resolve transaction was included.
+	OutcomeResolved Outcome = "resolved"
+	// OutcomeResolvedByOther is recorded when the challenge was resolved by someone else first.
+	OutcomeResolvedByOther Outcome = "resolved_by_other"
+	// OutcomeExpired is recorded when the resolve window closed before the challenge was resolved.
+	OutcomeExpired Outcome = "expired"
+	// OutcomeFailed is recorded for every failed resolve attempt. Attempts are retried until the window closes.
+	OutcomeFailed Outcome = "failed"
+	// OutcomeIgnored is recorded for challenges against commitments that were not posted by our batcher.
+	OutcomeIgnored Outcome = "ignored"
+)
+
+type Metricer interface {
+	RecordChallengeDetected(lockedBond *big.Int)
+	RecordChallengeOutcome(outcome Outcome)
+	RecordActiveChallenges(count int, lockedBonds *big.Int)
+	RecordResolverBalance(balance *big.Int)
+}
+
+type Metrics struct {
+	detected         *metrics.Event
+	detectedBonds    prometheus.Counter
+	outcomes         metrics.EventVec
+	activeChallenges prometheus.Gauge
+	lockedBonds      prometheus.Gauge
+	resolverBalance  prometheus.Gauge
+}
+
+var _ Metricer = (*Metrics)(nil)
+
+func MakeMetrics(ns string, factory metrics.Factory) *Metrics {
+	return &Metrics{
+		detected: metrics.NewEvent(factory, ns, "altda_responder", "challenges_detected", "challenges detected against our commitments"),
+		detectedBonds: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "altda_responder",
+			Name:      "challenged_bonds_total",
+			Help:      "Total bonds in ETH locked by challengers of our commitments",
+		}),
+		outcomes: metrics.NewEventVec(factory, ns, "altda_responder", "challenge_outcome", "outcomes of challenges against our commitments", []string{"outcome"}),
+		activeChallenges: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: "altda_responder",
+			Name:      "active_challenges",
+			Help:      "Number of unresolved challenges against our commitments",
+		}),
+		lockedBonds: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: "altda_responder",
+			Name:      "locked_bonds",
+			Help:      "Bonds in ETH locked by active challenges against our commitments",
+		}),
+		resolverBalance: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Subsystem: "altda_responder",
+			Name:      "resolver_balance",
+			Help:      "Balance in ETH of the resolver in the DA challenge contract, accumulated from resolver refunds",
+		}),
+	}
+}
+
+func (m *Metrics) RecordChallengeDetected(lockedBond *big.Int) {
+	m.detected.Record()
+	if lockedBond != nil {
+		m.detectedBonds.Add(eth.WeiToEther(lockedBond))
+	}
+}
+
+func (m *Metrics) RecordChallengeOutcome(outcome Outcome) {
+	m.outcomes.Record(string(outcome))
+}
+
+func (m *Metrics) RecordActiveChallenges(count int, lockedBonds *big.Int) {
+	m.activeChallenges.Set(float64(count))
+	m.lockedBonds.Set(eth.WeiToEther(lockedBonds))
+}
+
+func (m *Metrics) RecordResolverBalance(balance *big.Int) {
+	m.resolverBalance.Set(eth.WeiToEther(balance))
+}
+
+type NoopMetrics struct{}
+
+func (NoopMetrics) RecordChallengeDetected(lockedBond *big.Int)            {}
+func (NoopMetrics) RecordChallengeOutcome(outcome Outcome)                 {}
+func (NoopMetrics) RecordActiveChallenges(count int, lockedBonds *big.Int) {}
+func (NoopMetrics) RecordResolverBalance(balance *big.Int)                 {}
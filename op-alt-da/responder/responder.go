@@ -0,0 +1,468 @@
+package responder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+	"github.com/tokamak-network/tokamak-thanos/op-alt-da/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
+)
+
+var ErrAlreadyRunning = errors.New("responder is already running")
+
+// journalLimit is the number of fetched L1 block ranges that are journaled, and can be reverted on a reorg.
+// It covers reorgs far deeper than L1 finality.
+const journalLimit = 256
+
+// L1Client is the L1 interface the responder needs to follow challenges.
+type L1Client interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// DAStorage is the DA server the challenged input data is fetched from.
+type DAStorage interface {
+	GetInput(ctx context.Context, comm altda.CommitmentData) ([]byte, error)
+}
+
+// TxSender sends the resolve transactions, e.g. a txmgr.TxManager.
+type TxSender interface {
+	From() common.Address
+	Send(ctx context.Context, candidate txmgr.TxCandidate) (*types.Receipt, error)
+}
+
+type Config struct {
+	// DAChallengeAddress is the address of the DataAvailabilityChallenge contract.
+	DAChallengeAddress common.Address
+	// ResolveWindow is the number of L1 blocks after a challenge during which it can be resolved.
+	ResolveWindow uint64
+	// BatchInboxAddress is the address commitments are posted to.
+	BatchInboxAddress common.Address
+	// BatcherAddress restricts the responder to challenges against commitments posted by this address.
+	// If empty, every challenge for which the DA server has the input is resolved.
+	BatcherAddress common.Address
+	// PollInterval is the interval to check L1 for new challenge events.
+	PollInterval time.Duration
+	// MaxBlockRange is the maximum number of L1 blocks to fetch logs for in a single request.
+	MaxBlockRange uint64
+}
+
+// challenge is an active challenge against one of our commitments.
+type challenge struct {
+	comm        altda.CommitmentData
+	blockNumber uint64 // L1 block the challenged commitment was included in
+	startBlock  uint64 // L1 block the challenge was started in
+	startHash   common.Hash
+	lockedBond  *big.Int
+	resolving   bool
+}
+
+func (c *challenge) key() string {
+	return challengeKey(c.comm, c.blockNumber)
+}
+
+func challengeKey(comm altda.CommitmentData, blockNumber uint64) string {
+	return fmt.Sprintf("%d-%x", blockNumber, comm.Encode())
+}
+
+// challengeUpdate is a change of the tracked challenges by a challenge event.
+type challengeUpdate struct {
+	key  string
+	prev *challenge // nil if the challenge was not tracked before the update
+}
+
+// journalEntry is the last L1 block of a fetched range, with the updates of the challenge events in the range,
+// to revert them if the block is reorged out.
+type journalEntry struct {
+	block   eth.BlockID
+	updates []challengeUpdate
+}
+
+// Responder watches the DataAvailabilityChallenge contract for challenges against our commitments,
+// and resolves them by posting the input data fetched from the DA server before the resolve window closes.
+type Responder struct {
+	log      log.Logger
+	cfg      Config
+	metrics  Metricer
+	l1       L1Client
+	contract ChallengeContract
+	storage  DAStorage
+	txSender TxSender
+
+	mu         sync.Mutex
+	challenges map[string]*challenge
+	// journal are the fetched L1 block ranges, oldest first. The last entry is the last L1 block
+	// challenge events were fetched for.
+	journal []journalEntry
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewResponder(log log.Logger, cfg Config, m Metricer, l1 L1Client, contract ChallengeContract, storage DAStorage, txSender TxSender) *Responder {
+	if cfg.MaxBlockRange == 0 {
+		cfg.MaxBlockRange = 1000
+	}
+	return &Responder{
+		log:        log,
+		cfg:        cfg,
+		metrics:    m,
+		l1:         l1,
+		contract:   contract,
+		storage:    storage,
+		txSender:   txSender,
+		challenges: make(map[string]*challenge),
+	}
+}
+
+// Start starts following challenges in the background, until Stop is called.
+func (r *Responder) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return ErrAlreadyRunning
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go r.loop(ctx)
+	r.log.Info("Started DA challenge responder", "contract", r.cfg.DAChallengeAddress, "batcher", r.cfg.BatcherAddress)
+	return nil
+}
+
+// Stop stops the responder and waits for in-flight resolve transactions to return.
+func (r *Responder) Stop() {
+	r.mu.Lock()
+	cancel := r.cancel
+	r.cancel = nil
+	r.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	r.wg.Wait()
+	r.log.Info("Stopped DA challenge responder")
+}
+
+func (r *Responder) loop(ctx context.Context) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.Poll(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			r.log.Warn("Failed to process DA challenges", "err", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Poll fetches new challenge events up to the L1 head, and starts resolving, or expires, the known challenges.
+// The challenge events of L1 blocks that were reorged out are reverted first.
+func (r *Responder) Poll(ctx context.Context) error {
+	head, err := r.l1.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch L1 head: %w", err)
+	}
+	headNum := head.Number.Uint64()
+
+	if err := r.rewind(ctx); err != nil {
+		return fmt.Errorf("failed to rewind to common ancestor: %w", err)
+	}
+	var from uint64
+	if len(r.journal) > 0 {
+		from = r.journal[len(r.journal)-1].block.Number + 1
+	} else {
+		// on startup, only challenges that can still be resolved are of interest
+		from = headNum - min(headNum, r.cfg.ResolveWindow)
+	}
+	for from <= headNum {
+		to := min(from+r.cfg.MaxBlockRange-1, headNum)
+		last, err := r.l1.HeaderByNumber(ctx, new(big.Int).SetUint64(to))
+		if err != nil {
+			return fmt.Errorf("failed to fetch L1 block %d: %w", to, err)
+		}
+		entry := journalEntry{block: eth.BlockID{Hash: last.Hash(), Number: to}}
+		logs, err := r.l1.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{r.cfg.DAChallengeAddress},
+			Topics:    [][]common.Hash{{altda.ChallengeStatusEventABIHash}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch challenge events from %d to %d: %w", from, to, err)
+		}
+		for _, l := range logs {
+			if l.BlockNumber == to && l.BlockHash != entry.block.Hash {
+				r.revert(entry.updates)
+				return fmt.Errorf("L1 block %d was reorged while fetching challenge events", to)
+			}
+			if err := r.handleLog(ctx, l, &entry); err != nil {
+				r.revert(entry.updates)
+				return err
+			}
+		}
+		r.journal = append(r.journal, entry)
+		if len(r.journal) > journalLimit {
+			r.journal = slices.Delete(r.journal, 0, len(r.journal)-journalLimit)
+		}
+		from = to + 1
+	}
+
+	r.processChallenges(ctx, headNum)
+	r.recordState(ctx)
+	return nil
+}
+
+// rewind reverts the challenge events of the journaled L1 blocks that are no longer canonical, newest first,
+// until the last journaled block is canonical again.
+// If all journaled blocks were reorged out, the challenges are fetched again from the start of the resolve window.
+func (r *Responder) rewind(ctx context.Context) error {
+	for len(r.journal) > 0 {
+		last := r.journal[len(r.journal)-1]
+		header, err := r.l1.HeaderByNumber(ctx, new(big.Int).SetUint64(last.block.Number))
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("failed to fetch L1 block %d: %w", last.block.Number, err)
+		}
+		if err == nil && header.Hash() == last.block.Hash {
+			return nil
+		}
+		r.log.Warn("Reverting challenge events of reorged L1 blocks", "block", last.block, "updates", len(last.updates))
+		r.revert(last.updates)
+		r.journal = r.journal[:len(r.journal)-1]
+	}
+	return nil
+}
+
+// revert undoes the updates of the tracked challenges, newest first.
+func (r *Responder) revert(updates []challengeUpdate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(updates) - 1; i >= 0; i-- {
+		u := updates[i]
+		if u.prev == nil {
+			delete(r.challenges, u.key)
+		} else {
+			r.challenges[u.key] = u.prev
+		}
+	}
+}
+
+// handleLog updates the tracked challenges with a challenge event, and journals the update in entry.
+func (r *Responder) handleLog(ctx context.Context, l types.Log, entry *journalEntry) error {
+	event, err := altda.DecodeChallengeStatusEvent(&l)
+	if err != nil {
+		r.log.Warn("Failed to decode challenge event", "tx", l.TxHash, "log", l.Index, "err", err)
+		return nil
+	}
+	comm, err := altda.DecodeCommitmentData(event.ChallengedCommitment)
+	if err != nil {
+		r.log.Warn("Invalid challenged commitment", "tx", l.TxHash, "log", l.Index, "err", err)
+		return nil
+	}
+	blockNumber := event.ChallengedBlockNumber.Uint64()
+	key := challengeKey(comm, blockNumber)
+
+	if l.Removed {
+		// The challenge of a reorged out block is dropped, unless it is being resolved already.
+		// Other removed events are reverted when the reorg is detected.
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if ch, known := r.challenges[key]; known && ch.startHash == l.BlockHash && !ch.resolving {
+			r.log.Warn("Challenge was reorged out", "comm", comm, "block", blockNumber, "challenge_block", l.BlockNumber)
+			entry.updates = append(entry.updates, challengeUpdate{key: key, prev: ch})
+			delete(r.challenges, key)
+		}
+		return nil
+	}
+
+	switch altda.ChallengeStatus(event.Status) {
+	case altda.ChallengeActive:
+		r.mu.Lock()
+		_, known := r.challenges[key]
+		r.mu.Unlock()
+		if known {
+			return nil
+		}
+		own, err := r.isOwnCommitment(ctx, comm, blockNumber)
+		if err != nil {
+			return err
+		}
+		if !own {
+			r.log.Debug("Ignoring challenge of foreign commitment", "comm", comm, "block", blockNumber)
+			r.metrics.RecordChallengeOutcome(OutcomeIgnored)
+			return nil
+		}
+		ch := &challenge{comm: comm, blockNumber: blockNumber, startBlock: l.BlockNumber, startHash: l.BlockHash}
+		if onchain, err := r.contract.GetChallenge(ctx, blockNumber, comm); err != nil {
+			r.log.Warn("Failed to fetch challenge", "comm", comm, "block", blockNumber, "err", err)
+		} else {
+			ch.lockedBond = onchain.LockedBond
+			if onchain.StartBlock != nil && onchain.StartBlock.Sign() > 0 {
+				ch.startBlock = onchain.StartBlock.Uint64()
+			}
+		}
+		r.log.Warn("Detected challenge against our commitment", "comm", comm, "block", blockNumber,
+			"challenge_block", ch.startBlock, "bond", ch.lockedBond, "resolve_by", ch.startBlock+r.cfg.ResolveWindow)
+		r.metrics.RecordChallengeDetected(ch.lockedBond)
+		r.mu.Lock()
+		r.challenges[key] = ch
+		r.mu.Unlock()
+		entry.updates = append(entry.updates, challengeUpdate{key: key})
+	case altda.ChallengeResolved:
+		// a challenge we are resolving is accounted for when our resolve tx returns
+		r.mu.Lock()
+		ch, known := r.challenges[key]
+		byOther := known && !ch.resolving
+		if byOther {
+			delete(r.challenges, key)
+			entry.updates = append(entry.updates, challengeUpdate{key: key, prev: ch})
+		}
+		r.mu.Unlock()
+		if byOther {
+			r.log.Info("Challenge was resolved by another account", "comm", comm, "block", blockNumber, "tx", l.TxHash)
+			r.metrics.RecordChallengeOutcome(OutcomeResolvedByOther)
+		}
+	}
+	return nil
+}
+
+// isOwnCommitment checks that the commitment was posted to the batch inbox by our batcher in the given L1 block.
+func (r *Responder) isOwnCommitment(ctx context.Context, comm altda.CommitmentData, blockNumber uint64) (bool, error) {
+	if r.cfg.BatcherAddress == (common.Address{}) {
+		return true, nil
+	}
+	block, err := r.l1.BlockByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch L1 block %d of challenged commitment: %w", blockNumber, err)
+	}
+	txData := comm.TxData()
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || *tx.To() != r.cfg.BatchInboxAddress || !bytes.Equal(tx.Data(), txData) {
+			continue
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			continue
+		}
+		if sender == r.cfg.BatcherAddress {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// processChallenges expires challenges past their resolve window and starts resolving the others.
+func (r *Responder) processChallenges(ctx context.Context, head uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, ch := range r.challenges {
+		if ch.resolving {
+			continue
+		}
+		// the resolve tx can be included in the next block at the earliest
+		if head+1 > ch.startBlock+r.cfg.ResolveWindow {
+			r.log.Error("Challenge against our commitment expired", "comm", ch.comm, "block", ch.blockNumber, "bond", ch.lockedBond)
+			r.metrics.RecordChallengeOutcome(OutcomeExpired)
+			delete(r.challenges, key)
+			continue
+		}
+		ch.resolving = true
+		r.wg.Add(1)
+		go r.resolve(ctx, ch)
+	}
+}
+
+func (r *Responder) resolve(ctx context.Context, ch *challenge) {
+	defer r.wg.Done()
+	err := r.sendResolve(ctx, ch)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		ch.resolving = false
+		if !errors.Is(err, context.Canceled) {
+			r.log.Error("Failed to resolve challenge, will retry", "comm", ch.comm, "block", ch.blockNumber, "err", err)
+			r.metrics.RecordChallengeOutcome(OutcomeFailed)
+		}
+		return
+	}
+	// the challenge may have been reorged out, and tracked again, while it was being resolved
+	if r.challenges[ch.key()] == ch {
+		delete(r.challenges, ch.key())
+	}
+	r.metrics.RecordChallengeOutcome(OutcomeResolved)
+}
+
+func (r *Responder) sendResolve(ctx context.Context, ch *challenge) error {
+	input, err := r.storage.GetInput(ctx, ch.comm)
+	if err != nil {
+		return fmt.Errorf("failed to fetch input from DA server: %w", err)
+	}
+	if err := ch.comm.Verify(input); err != nil {
+		return fmt.Errorf("DA server returned invalid input: %w", err)
+	}
+	data, err := ResolveCallData(ch.comm, ch.blockNumber, input)
+	if err != nil {
+		return err
+	}
+	r.log.Info("Resolving challenge", "comm", ch.comm, "block", ch.blockNumber, "input_len", len(input))
+	receipt, err := r.txSender.Send(ctx, txmgr.TxCandidate{
+		To:     &r.cfg.DAChallengeAddress,
+		TxData: data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send resolve tx: %w", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("resolve tx %s reverted", receipt.TxHash)
+	}
+	r.log.Info("Resolved challenge", "comm", ch.comm, "block", ch.blockNumber, "tx", receipt.TxHash, "l1_block", receipt.BlockNumber)
+	return nil
+}
+
+func (r *Responder) recordState(ctx context.Context) {
+	r.mu.Lock()
+	bonds := new(big.Int)
+	for _, ch := range r.challenges {
+		if ch.lockedBond != nil {
+			bonds.Add(bonds, ch.lockedBond)
+		}
+	}
+	count := len(r.challenges)
+	r.mu.Unlock()
+	r.metrics.RecordActiveChallenges(count, bonds)
+
+	balance, err := r.contract.Balance(ctx, r.txSender.From())
+	if err != nil {
+		r.log.Warn("Failed to fetch resolver balance", "err", err)
+		return
+	}
+	r.metrics.RecordResolverBalance(balance)
+}
+
+// ResolveCallData encodes the call to resolve the challenge of the commitment included in the given L1 block.
+func ResolveCallData(comm altda.CommitmentData, blockNumber uint64, input []byte) ([]byte, error) {
+	dacAbi, err := bindings.DataAvailabilityChallengeMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return dacAbi.Pack("resolve", new(big.Int).SetUint64(blockNumber), comm.Encode(), input)
+}
@@ -0,0 +1,371 @@
+package responder
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+	"github.com/tokamak-network/tokamak-thanos/op-alt-da/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
+)
+
+var (
+	challengeAddr = common.HexToAddress("0xda")
+	inboxAddr     = common.HexToAddress("0xff00")
+	chainID       = big.NewInt(900)
+)
+
+type fakeL1 struct {
+	head uint64
+	// forks change the hashes of the L1 blocks, to reorg them.
+	forks  map[uint64]byte
+	blocks map[uint64]*types.Block
+	logs   []types.Log
+}
+
+func (f *fakeL1) header(number uint64) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(number), Extra: []byte{f.forks[number]}}
+}
+
+// reorg replaces the L1 blocks from the given number up to the head.
+func (f *fakeL1) reorg(from uint64) {
+	for n := from; n <= f.head; n++ {
+		f.forks[n]++
+	}
+}
+
+func (f *fakeL1) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return f.header(f.head), nil
+	}
+	if number.Uint64() > f.head {
+		return nil, ethereum.NotFound
+	}
+	return f.header(number.Uint64()), nil
+}
+
+func (f *fakeL1) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	b, ok := f.blocks[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return b, nil
+}
+
+func (f *fakeL1) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, l := range f.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() && l.BlockNumber <= q.ToBlock.Uint64() {
+			l.BlockHash = f.header(l.BlockNumber).Hash()
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+type fakeContract struct {
+	challenges map[string]bindings.Challenge
+}
+
+func (f *fakeContract) GetChallenge(ctx context.Context, challengedBlockNumber uint64, comm altda.CommitmentData) (bindings.Challenge, error) {
+	c, ok := f.challenges[challengeKey(comm, challengedBlockNumber)]
+	if !ok {
+		return bindings.Challenge{}, errors.New("unknown challenge")
+	}
+	return c, nil
+}
+
+func (f *fakeContract) Balance(ctx context.Context, addr common.Address) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+type fakeTxSender struct {
+	mu   sync.Mutex
+	err  error
+	sent []txmgr.TxCandidate
+}
+
+func (f *fakeTxSender) From() common.Address {
+	return common.HexToAddress("0x5e")
+}
+
+func (f *fakeTxSender) Send(ctx context.Context, candidate txmgr.TxCandidate) (*types.Receipt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.sent = append(f.sent, candidate)
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+}
+
+type recordingMetrics struct {
+	NoopMetrics
+	mu       sync.Mutex
+	outcomes map[Outcome]int
+}
+
+func (m *recordingMetrics) RecordChallengeOutcome(outcome Outcome) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes[outcome]++
+}
+
+func (m *recordingMetrics) count(outcome Outcome) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.outcomes[outcome]
+}
+
+func challengeLog(t *testing.T, blockNumber uint64, comm altda.CommitmentData, challengedBlock uint64, status altda.ChallengeStatus) types.Log {
+	dacAbi, err := bindings.DataAvailabilityChallengeMetaData.GetAbi()
+	require.NoError(t, err)
+	data, err := dacAbi.Events[altda.ChallengeStatusEventName].Inputs.NonIndexed().Pack(comm.Encode(), uint8(status))
+	require.NoError(t, err)
+	return types.Log{
+		Address:     challengeAddr,
+		BlockNumber: blockNumber,
+		Topics: []common.Hash{
+			altda.ChallengeStatusEventABIHash,
+			common.BigToHash(new(big.Int).SetUint64(challengedBlock)),
+		},
+		Data: data,
+	}
+}
+
+type testSetup struct {
+	responder *Responder
+	l1        *fakeL1
+	storage   *altda.MockDAClient
+	contract  *fakeContract
+	sender    *fakeTxSender
+	metrics   *recordingMetrics
+	batcher   *ecdsa.PrivateKey
+}
+
+func newTestSetup(t *testing.T) *testSetup {
+	logger := testlog.Logger(t, log.LevelDebug)
+	batcherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	ts := &testSetup{
+		l1:       &fakeL1{forks: make(map[uint64]byte), blocks: make(map[uint64]*types.Block)},
+		storage:  altda.NewMockDAClient(logger),
+		contract: &fakeContract{challenges: make(map[string]bindings.Challenge)},
+		sender:   &fakeTxSender{},
+		metrics:  &recordingMetrics{outcomes: make(map[Outcome]int)},
+		batcher:  batcherKey,
+	}
+	cfg := Config{
+		DAChallengeAddress: challengeAddr,
+		ResolveWindow:      10,
+		BatchInboxAddress:  inboxAddr,
+		BatcherAddress:     crypto.PubkeyToAddress(batcherKey.PublicKey),
+		MaxBlockRange:      4,
+	}
+	ts.responder = NewResponder(logger, cfg, ts.metrics, ts.l1, ts.contract, ts.storage, ts.sender)
+	return ts
+}
+
+// postCommitments stores the inputs and includes the commitment txs, signed by key, in the given L1 block.
+func (ts *testSetup) postCommitments(t *testing.T, key *ecdsa.PrivateKey, blockNumber uint64, inputs ...[]byte) []altda.CommitmentData {
+	signer := types.LatestSignerForChainID(chainID)
+	comms := make([]altda.CommitmentData, len(inputs))
+	txs := make([]*types.Transaction, len(inputs))
+	for i, input := range inputs {
+		comm, err := ts.storage.SetInput(context.Background(), input)
+		require.NoError(t, err)
+		comms[i] = comm
+		txs[i] = types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID: chainID,
+			Nonce:   uint64(i),
+			To:      &inboxAddr,
+			Data:    comm.TxData(),
+		})
+	}
+	ts.l1.blocks[blockNumber] = types.NewBlock(&types.Header{Number: new(big.Int).SetUint64(blockNumber)}, txs, nil, nil, trie.NewStackTrie(nil))
+	return comms
+}
+
+func (ts *testSetup) challenge(t *testing.T, comm altda.CommitmentData, challengedBlock uint64, startBlock uint64) {
+	ts.l1.logs = append(ts.l1.logs, challengeLog(t, startBlock, comm, challengedBlock, altda.ChallengeActive))
+	ts.contract.challenges[challengeKey(comm, challengedBlock)] = bindings.Challenge{
+		Challenger: common.HexToAddress("0xc0"),
+		LockedBond: big.NewInt(1e18),
+		StartBlock: new(big.Int).SetUint64(startBlock),
+	}
+}
+
+func TestResponderResolvesOwnCommitment(t *testing.T) {
+	ts := newTestSetup(t)
+	ctx := context.Background()
+
+	input := []byte("batcher input")
+	comm := ts.postCommitments(t, ts.batcher, 5, input)[0]
+	ts.challenge(t, comm, 5, 8)
+	ts.l1.head = 9
+
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+
+	require.Len(t, ts.sender.sent, 1)
+	require.Equal(t, challengeAddr, *ts.sender.sent[0].To)
+	expected, err := ResolveCallData(comm, 5, input)
+	require.NoError(t, err)
+	require.Equal(t, expected, ts.sender.sent[0].TxData)
+	require.Equal(t, 1, ts.metrics.count(OutcomeResolved))
+	require.Empty(t, ts.responder.challenges)
+
+	// the resolved event of our own resolve tx is not counted again
+	ts.l1.logs = append(ts.l1.logs, challengeLog(t, 10, comm, 5, altda.ChallengeResolved))
+	ts.l1.head = 10
+	require.NoError(t, ts.responder.Poll(ctx))
+	require.Zero(t, ts.metrics.count(OutcomeResolvedByOther))
+}
+
+func TestResponderIgnoresForeignCommitment(t *testing.T) {
+	ts := newTestSetup(t)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	comm := ts.postCommitments(t, otherKey, 5, []byte("foreign input"))[0]
+	ts.challenge(t, comm, 5, 6)
+	ts.l1.head = 7
+
+	require.NoError(t, ts.responder.Poll(context.Background()))
+	ts.responder.wg.Wait()
+	require.Empty(t, ts.sender.sent)
+	require.Equal(t, 1, ts.metrics.count(OutcomeIgnored))
+}
+
+func TestResponderRetriesAndExpires(t *testing.T) {
+	ts := newTestSetup(t)
+	ctx := context.Background()
+
+	comm := ts.postCommitments(t, ts.batcher, 5, []byte("batcher input"))[0]
+	ts.challenge(t, comm, 5, 6)
+	ts.sender.err = errors.New("tx failed")
+
+	ts.l1.head = 7
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Equal(t, 1, ts.metrics.count(OutcomeFailed))
+	require.Len(t, ts.responder.challenges, 1)
+
+	ts.l1.head = 8
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Equal(t, 2, ts.metrics.count(OutcomeFailed))
+
+	// the resolve window of 10 blocks closes after block 16
+	ts.l1.head = 16
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Equal(t, 1, ts.metrics.count(OutcomeExpired))
+	require.Empty(t, ts.responder.challenges)
+}
+
+func TestResponderResolvedByOther(t *testing.T) {
+	ts := newTestSetup(t)
+	ctx := context.Background()
+	ts.responder.cfg.BatcherAddress = common.Address{} // resolve any commitment the DA server has
+
+	comm, err := ts.storage.SetInput(ctx, []byte("input"))
+	require.NoError(t, err)
+	ts.challenge(t, comm, 5, 6)
+	ts.l1.logs = append(ts.l1.logs, challengeLog(t, 7, comm, 5, altda.ChallengeResolved))
+	ts.l1.head = 7
+
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Empty(t, ts.sender.sent)
+	require.Equal(t, 1, ts.metrics.count(OutcomeResolvedByOther))
+}
+
+func TestResponderRevertsReorgedChallenge(t *testing.T) {
+	ts := newTestSetup(t)
+	ctx := context.Background()
+	ts.sender.err = errors.New("tx failed") // keep the challenge tracked
+
+	comm := ts.postCommitments(t, ts.batcher, 5, []byte("batcher input"))[0]
+	ts.challenge(t, comm, 5, 8)
+	ts.l1.head = 9
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Len(t, ts.responder.challenges, 1)
+
+	// the challenge is reorged out
+	ts.l1.reorg(8)
+	ts.l1.logs = nil
+	ts.l1.head = 10
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Empty(t, ts.responder.challenges)
+
+	// and included again in a later block
+	ts.challenge(t, comm, 5, 11)
+	ts.l1.head = 11
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Len(t, ts.responder.challenges, 1)
+	require.Equal(t, uint64(11), ts.responder.challenges[challengeKey(comm, 5)].startBlock)
+}
+
+func TestResponderRevertsReorgedResolve(t *testing.T) {
+	ts := newTestSetup(t)
+	ctx := context.Background()
+	ts.responder.cfg.BatcherAddress = common.Address{} // resolve any commitment the DA server has
+	ts.sender.err = errors.New("tx failed")            // keep the challenge tracked
+
+	comm, err := ts.storage.SetInput(ctx, []byte("input"))
+	require.NoError(t, err)
+	ts.challenge(t, comm, 5, 6)
+	ts.l1.logs = append(ts.l1.logs, challengeLog(t, 7, comm, 5, altda.ChallengeResolved))
+	ts.l1.head = 7
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Empty(t, ts.responder.challenges)
+
+	// the resolve by the other account is reorged out, the challenge is active again
+	ts.l1.reorg(7)
+	ts.l1.logs = ts.l1.logs[:1]
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Len(t, ts.responder.challenges, 1)
+	require.Equal(t, 1, ts.metrics.count(OutcomeFailed), "resolves the challenge again")
+}
+
+func TestResponderDropsRemovedChallenge(t *testing.T) {
+	ts := newTestSetup(t)
+	ctx := context.Background()
+	ts.sender.err = errors.New("tx failed") // keep the challenge tracked
+
+	comm := ts.postCommitments(t, ts.batcher, 5, []byte("batcher input"))[0]
+	ts.challenge(t, comm, 5, 6)
+	ts.l1.head = 7
+	require.NoError(t, ts.responder.Poll(ctx))
+	ts.responder.wg.Wait()
+	require.Len(t, ts.responder.challenges, 1)
+
+	removed := ts.l1.logs[0]
+	removed.BlockHash = ts.l1.header(6).Hash()
+	removed.Removed = true
+	var entry journalEntry
+	require.NoError(t, ts.responder.handleLog(ctx, removed, &entry))
+	require.Empty(t, ts.responder.challenges)
+
+	ts.responder.revert(entry.updates)
+	require.Len(t, ts.responder.challenges, 1)
+}
@@ -8,14 +8,16 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+	"github.com/tokamak-network/tokamak-thanos/op-alt-da/responder"
 	"github.com/tokamak-network/tokamak-thanos/op-batcher/compressor"
 	"github.com/tokamak-network/tokamak-thanos/op-batcher/flags"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
-	plasma "github.com/tokamak-network/tokamak-thanos/op-plasma"
 	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
 	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
 )
 
@@ -99,8 +101,11 @@ type CLIConfig struct {
 	LogConfig     oplog.CLIConfig
 	MetricsConfig opmetrics.CLIConfig
 	PprofConfig   oppprof.CLIConfig
+	TracingConfig tracing.CLIConfig
 	RPC           oprpc.CLIConfig
-	PlasmaDA      plasma.CLIConfig
+	AltDA         altda.CLIConfig
+	// AltDAResponder configures resolving DA challenges against the commitments this batcher posts.
+	AltDAResponder responder.CLIConfig
 }
 
 func (c *CLIConfig) Check() error {
@@ -149,12 +154,32 @@ func (c *CLIConfig) Check() error {
 	if err := c.PprofConfig.Check(); err != nil {
 		return err
 	}
+	if err := c.TracingConfig.Check(); err != nil {
+		return err
+	}
 	if err := c.TxMgrConfig.Check(); err != nil {
 		return err
 	}
 	if err := c.RPC.Check(); err != nil {
 		return err
 	}
+	if err := c.AltDA.Check(); err != nil {
+		return err
+	}
+	if c.AltDA.Enabled {
+		if c.DataAvailabilityType != flags.CalldataType {
+			return fmt.Errorf("alt-DA requires the %q data availability type, got %q", flags.CalldataType, c.DataAvailabilityType)
+		}
+		if c.AltDA.MaxConcurrentRequests == 0 {
+			return errors.New("alt-DA max concurrent requests must be at least 1")
+		}
+	}
+	if err := c.AltDAResponder.Check(); err != nil {
+		return err
+	}
+	if c.AltDAResponder.Enabled && !c.AltDA.Enabled {
+		return errors.New("the DA challenge responder requires alt-DA to be enabled")
+	}
 	return nil
 }
 
@@ -186,7 +211,9 @@ func NewConfig(ctx *cli.Context) *CLIConfig {
 		LogConfig:                    oplog.ReadCLIConfig(ctx),
 		MetricsConfig:                opmetrics.ReadCLIConfig(ctx),
 		PprofConfig:                  oppprof.ReadCLIConfig(ctx),
+		TracingConfig:                tracing.ReadCLIConfig(ctx),
 		RPC:                          oprpc.ReadCLIConfig(ctx),
-		PlasmaDA:                     plasma.ReadCLIConfig(ctx),
+		AltDA:                        altda.ReadCLIConfig(ctx),
+		AltDAResponder:               responder.ReadCLIConfig(ctx),
 	}
 }
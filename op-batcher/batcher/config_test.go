@@ -113,6 +113,49 @@ func TestBatcherConfig(t *testing.T) {
 			},
 			errString: "invalid ApproxComprRatio 4.2 for ratio compressor",
 		},
+		{
+			name: "alt-DA without DA server",
+			override: func(c *batcher.CLIConfig) {
+				c.AltDA.Enabled = true
+				c.AltDA.MaxConcurrentRequests = 1
+			},
+			errString: "DA server URL is required when altDA is enabled",
+		},
+		{
+			name: "alt-DA with blobs",
+			override: func(c *batcher.CLIConfig) {
+				c.AltDA.Enabled = true
+				c.AltDA.DAServerURL = "http://localhost:3100"
+				c.AltDA.MaxConcurrentRequests = 1
+				c.DataAvailabilityType = flags.BlobsType
+			},
+			errString: "alt-DA requires the \"calldata\" data availability type, got \"blobs\"",
+		},
+		{
+			name: "alt-DA without concurrent requests",
+			override: func(c *batcher.CLIConfig) {
+				c.AltDA.Enabled = true
+				c.AltDA.DAServerURL = "http://localhost:3100"
+			},
+			errString: "alt-DA max concurrent requests must be at least 1",
+		},
+		{
+			name: "DA challenge responder without alt-DA",
+			override: func(c *batcher.CLIConfig) {
+				c.AltDAResponder.Enabled = true
+				c.AltDAResponder.PollInterval = time.Second
+				c.AltDAResponder.MaxBlockRange = 100
+			},
+			errString: "the DA challenge responder requires alt-DA to be enabled",
+		},
+		{
+			name: "DA challenge responder without poll interval",
+			override: func(c *batcher.CLIConfig) {
+				c.AltDAResponder.Enabled = true
+				c.AltDAResponder.MaxBlockRange = 100
+			},
+			errString: "DA challenge responder poll interval must be positive",
+		},
 	}
 
 	for _, test := range tests {
@@ -10,13 +10,15 @@ import (
 	"sync"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
 	"github.com/tokamak-network/tokamak-thanos/op-batcher/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
-	plasma "github.com/tokamak-network/tokamak-thanos/op-plasma"
 	"github.com/tokamak-network/tokamak-thanos/op-service/dial"
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
@@ -37,6 +39,10 @@ type RollupClient interface {
 	SyncStatus(ctx context.Context) (*eth.SyncStatus, error)
 }
 
+type AltDAClient interface {
+	SetInput(ctx context.Context, img []byte) (altda.CommitmentData, error)
+}
+
 // DriverSetup is the collection of input/output interfaces and configuration that the driver operates on.
 type DriverSetup struct {
 	Log              log.Logger
@@ -47,7 +53,7 @@ type DriverSetup struct {
 	L1Client         L1Client
 	EndpointProvider dial.L2EndpointProvider
 	ChannelConfig    ChannelConfig
-	PlasmaDA         *plasma.DAClient
+	AltDA            AltDAClient
 }
 
 // BatchSubmitter encapsulates a service responsible for submitting L2 tx
@@ -267,6 +273,12 @@ func (l *BatchSubmitter) loop() {
 
 	receiptsCh := make(chan txmgr.TxReceipt[txID])
 	queue := txmgr.NewQueue[txID](l.killCtx, l.Txmgr, l.Config.MaxPendingTransactions)
+	daGroup := &errgroup.Group{}
+	// errgroup with limit of 0 means no goroutine is able to run concurrently,
+	// so we only set the limit if it is greater than 0.
+	if l.Config.MaxConcurrentDARequests > 0 {
+		daGroup.SetLimit(int(l.Config.MaxConcurrentDARequests))
+	}
 
 	// start the receipt/result processing loop
 	receiptLoopDone := make(chan struct{})
@@ -288,7 +300,11 @@ func (l *BatchSubmitter) loop() {
 	defer ticker.Stop()
 
 	publishAndWait := func() {
-		l.publishStateToL1(queue, receiptsCh)
+		l.publishStateToL1(queue, receiptsCh, daGroup)
+		// DA requests queue their commitment txs when done, so they need to finish before waiting on the queue.
+		if err := daGroup.Wait(); err != nil {
+			l.Log.Error("Error waiting for DA requests to complete", "err", err)
+		}
 		if !l.Txmgr.IsClosed() {
 			queue.Wait()
 		} else {
@@ -314,7 +330,7 @@ func (l *BatchSubmitter) loop() {
 				l.clearState(l.shutdownCtx)
 				continue
 			}
-			l.publishStateToL1(queue, receiptsCh)
+			l.publishStateToL1(queue, receiptsCh, daGroup)
 		case <-l.shutdownCtx.Done():
 			if l.Txmgr.IsClosed() {
 				l.Log.Info("Txmgr is closed, remaining channel data won't be sent")
@@ -371,14 +387,14 @@ func (l *BatchSubmitter) waitNodeSync() error {
 
 // publishStateToL1 queues up all pending TxData to be published to the L1, returning when there is
 // no more data to queue for publishing or if there was an error queing the data.
-func (l *BatchSubmitter) publishStateToL1(queue *txmgr.Queue[txID], receiptsCh chan txmgr.TxReceipt[txID]) {
+func (l *BatchSubmitter) publishStateToL1(queue *txmgr.Queue[txID], receiptsCh chan txmgr.TxReceipt[txID], daGroup *errgroup.Group) {
 	for {
 		// if the txmgr is closed, we stop the transaction sending
 		if l.Txmgr.IsClosed() {
 			l.Log.Info("Txmgr is closed, aborting state publishing")
 			return
 		}
-		err := l.publishTxToL1(l.killCtx, queue, receiptsCh)
+		err := l.publishTxToL1(l.killCtx, queue, receiptsCh, daGroup)
 		if err != nil {
 			if err != io.EOF {
 				l.Log.Error("error publishing tx to l1", "err", err)
@@ -428,7 +444,7 @@ func (l *BatchSubmitter) clearState(ctx context.Context) {
 }
 
 // publishTxToL1 submits a single state tx to the L1
-func (l *BatchSubmitter) publishTxToL1(ctx context.Context, queue *txmgr.Queue[txID], receiptsCh chan txmgr.TxReceipt[txID]) error {
+func (l *BatchSubmitter) publishTxToL1(ctx context.Context, queue *txmgr.Queue[txID], receiptsCh chan txmgr.TxReceipt[txID], daGroup *errgroup.Group) error {
 	// send all available transactions
 	l1tip, err := l.l1Tip(ctx)
 	if err != nil {
@@ -448,7 +464,7 @@ func (l *BatchSubmitter) publishTxToL1(ctx context.Context, queue *txmgr.Queue[t
 		return err
 	}
 
-	if err = l.sendTransaction(ctx, txdata, queue, receiptsCh); err != nil {
+	if err = l.sendTransaction(ctx, txdata, queue, receiptsCh, daGroup); err != nil {
 		return fmt.Errorf("BatchSubmitter.sendTransaction failed: %w", err)
 	}
 	return nil
@@ -479,8 +495,9 @@ func (l *BatchSubmitter) safeL1Origin(ctx context.Context) (eth.BlockID, error)
 }
 
 // sendTransaction creates & queues for sending a transaction to the batch inbox address with the given `txData`.
-// The method will block if the queue's MaxPendingTransactions is exceeded.
-func (l *BatchSubmitter) sendTransaction(ctx context.Context, txdata txData, queue *txmgr.Queue[txID], receiptsCh chan txmgr.TxReceipt[txID]) error {
+// The method will block if the queue's MaxPendingTransactions is exceeded, or, with Alt-DA enabled,
+// if the number of in-flight DA requests reaches MaxConcurrentDARequests.
+func (l *BatchSubmitter) sendTransaction(ctx context.Context, txdata txData, queue *txmgr.Queue[txID], receiptsCh chan txmgr.TxReceipt[txID], daGroup *errgroup.Group) error {
 	var err error
 	// Do the gas estimation offline. A value of 0 will cause the [txmgr] to estimate the gas limit.
 
@@ -498,20 +515,12 @@ func (l *BatchSubmitter) sendTransaction(ctx context.Context, txdata txData, que
 		if nf := len(txdata.frames); nf != 1 {
 			l.Log.Crit("unexpected number of frames in calldata tx", "num_frames", nf)
 		}
-		data := txdata.CallData()
-		// if plasma DA is enabled we post the txdata to the DA Provider and replace it with the commitment.
-		if l.Config.UsePlasma {
-			comm, err := l.PlasmaDA.SetInput(ctx, data)
-			if err != nil {
-				l.Log.Error("Failed to post input to Plasma DA", "error", err)
-				// requeue frame if we fail to post to the DA Provider so it can be retried
-				l.recordFailedTx(txdata.ID(), err)
-				return nil
-			}
-			// signal plasma commitment tx with TxDataVersion1
-			data = comm.TxData()
+		// if Alt-DA is enabled we post the txdata to the DA Provider and replace it with the commitment.
+		if l.Config.UseAltDA {
+			l.publishToAltDAAndL1(ctx, txdata, queue, receiptsCh, daGroup)
+			return nil
 		}
-		candidate = l.calldataTxCandidate(data)
+		candidate = l.calldataTxCandidate(txdata.CallData())
 	}
 
 	// GasLimit intentionally left as 0 so that txmgr calls eth_estimateGas on
@@ -528,6 +537,36 @@ func (l *BatchSubmitter) sendTransaction(ctx context.Context, txdata txData, que
 	return nil
 }
 
+// publishToAltDAAndL1 posts the txdata to the Alt-DA server in a goroutine of the daGroup,
+// and queues a calldata tx with the returned commitment once the DA request succeeded.
+// If the DA server is unavailable, the txdata is posted to L1 directly instead, which the
+// derivation pipeline accepts as regular frame data.
+// The method blocks if the daGroup is already running MaxConcurrentDARequests requests.
+func (l *BatchSubmitter) publishToAltDAAndL1(ctx context.Context, txdata txData, queue *txmgr.Queue[txID], receiptsCh chan txmgr.TxReceipt[txID], daGroup *errgroup.Group) {
+	daGroup.Go(func() error {
+		start := time.Now()
+		l.Metr.RecordAltDARequestSubmitted()
+		comm, err := l.AltDA.SetInput(ctx, txdata.CallData())
+		if errors.Is(err, altda.ErrAltDADown) {
+			l.Metr.RecordAltDARequestFailed(time.Since(start))
+			l.Metr.RecordAltDARequestFallback()
+			l.Log.Warn("Alt-DA server is unavailable, posting input to L1", logFields(txdata.ID(), err)...)
+			queue.Send(txdata.ID(), *l.calldataTxCandidate(txdata.CallData()), receiptsCh)
+			return nil
+		} else if err != nil {
+			l.Metr.RecordAltDARequestFailed(time.Since(start))
+			// requeue frame if we fail to post to the DA Provider so it can be retried
+			l.recordFailedDARequest(txdata.ID(), err)
+			return nil
+		}
+		l.Metr.RecordAltDARequestSuccess(time.Since(start))
+		l.Log.Info("Set Alt-DA input", "commitment", comm, "tx_id", txdata.ID(), "duration", time.Since(start))
+		// signal Alt-DA commitment tx with TxDataVersion1
+		queue.Send(txdata.ID(), *l.calldataTxCandidate(comm.TxData()), receiptsCh)
+		return nil
+	})
+}
+
 func (l *BatchSubmitter) blobTxCandidate(data txData) (*txmgr.TxCandidate, error) {
 	blobs, err := data.Blobs()
 	if err != nil {
@@ -577,6 +616,16 @@ func (l *BatchSubmitter) recordFailedTx(id txID, err error) {
 	l.state.TxFailed(id)
 }
 
+func (l *BatchSubmitter) recordFailedDARequest(id txID, err error) {
+	if errors.Is(err, context.Canceled) {
+		// expected on shutdown, the frame is requeued without logging an error.
+		l.Log.Info("Alt-DA request cancelled", logFields(id)...)
+	} else {
+		l.Log.Warn("Failed to post input to Alt-DA server", logFields(id, err)...)
+	}
+	l.state.TxFailed(id)
+}
+
 func (l *BatchSubmitter) recordConfirmedTx(id txID, receipt *types.Receipt) {
 	l.Log.Info("Transaction confirmed", logFields(id, receipt)...)
 	l1block := eth.ReceiptBlockID(receipt)
@@ -5,13 +5,20 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
 	"github.com/tokamak-network/tokamak-thanos/op-batcher/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-service/dial"
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
 	"github.com/tokamak-network/tokamak-thanos/op-service/testutils"
+	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
+	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr/mocks"
 )
 
 type mockL2EndpointProvider struct {
@@ -117,3 +124,63 @@ func TestBatchSubmitter_SafeL1Origin_FailsToResolveRollupClient(t *testing.T) {
 	_, err := bs.safeL1Origin(context.Background())
 	require.Error(t, err)
 }
+
+type fakeAltDAClient struct {
+	comm altda.CommitmentData
+	err  error
+}
+
+func (f *fakeAltDAClient) SetInput(ctx context.Context, img []byte) (altda.CommitmentData, error) {
+	return f.comm, f.err
+}
+
+func TestBatchSubmitter_PublishToAltDA(t *testing.T) {
+	txdata := singleFrameTxData(frameData{data: []byte{1, 2, 3}, id: frameID{frameNumber: 1}})
+	comm := altda.NewGenericCommitment([]byte{0xaa, 0xbb})
+
+	tests := []struct {
+		name         string
+		daErr        error
+		expectTxData []byte
+	}{
+		{
+			name:         "PostsCommitment",
+			expectTxData: comm.TxData(),
+		},
+		{
+			name:         "FallsBackToL1WhenDown",
+			daErr:        altda.ErrAltDADown,
+			expectTxData: txdata.CallData(),
+		},
+		{
+			name:  "RequeuesOnError",
+			daErr: errors.New("internal server error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs, _ := setup(t)
+			bs.AltDA = &fakeAltDAClient{comm: comm, err: tt.daErr}
+
+			txMgr := new(mocks.TxManager)
+			txMgr.On("Send", mock.Anything, mock.Anything).Return(&types.Receipt{}, nil)
+			queue := txmgr.NewQueue[txID](context.Background(), txMgr, 0)
+			receiptsCh := make(chan txmgr.TxReceipt[txID], 1)
+			daGroup := &errgroup.Group{}
+
+			bs.publishToAltDAAndL1(context.Background(), txdata, queue, receiptsCh, daGroup)
+			require.NoError(t, daGroup.Wait())
+			queue.Wait()
+
+			if tt.expectTxData == nil {
+				txMgr.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+				return
+			}
+			txMgr.AssertNumberOfCalls(t, "Send", 1)
+			candidate := txMgr.Calls[0].Arguments.Get(1).(txmgr.TxCandidate)
+			require.Equal(t, tt.expectTxData, candidate.TxData)
+			require.Equal(t, txdata.ID().String(), (<-receiptsCh).ID.String())
+		})
+	}
+}
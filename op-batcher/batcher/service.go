@@ -12,13 +12,15 @@ import (
 
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+	"github.com/tokamak-network/tokamak-thanos/op-alt-da/responder"
 	"github.com/tokamak-network/tokamak-thanos/op-batcher/flags"
 	"github.com/tokamak-network/tokamak-thanos/op-batcher/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-batcher/rpc"
 	"github.com/tokamak-network/tokamak-thanos/op-node/chaincfg"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
-	plasma "github.com/tokamak-network/tokamak-thanos/op-plasma"
 	"github.com/tokamak-network/tokamak-thanos/op-service/cliapp"
 	"github.com/tokamak-network/tokamak-thanos/op-service/dial"
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
@@ -26,6 +28,7 @@ import (
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
 	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
 )
 
@@ -39,9 +42,11 @@ type BatcherConfig struct {
 	// UseBlobs is true if the batcher should use blobs instead of calldata for posting blobs
 	UseBlobs bool
 
-	// UsePlasma is true if the rollup config has a DA challenge address so the batcher
-	// will post inputs to the Plasma DA server and post commitments to blobs or calldata.
-	UsePlasma bool
+	// UseAltDA is true if the rollup config has a DA challenge address so the batcher
+	// will post inputs to the Alt-DA server and post commitments to calldata.
+	UseAltDA bool
+	// MaxConcurrentDARequests is the maximum number of concurrent requests to the Alt-DA server.
+	MaxConcurrentDARequests uint64
 
 	WaitNodeSync        bool
 	CheckRecentTxsDepth int
@@ -55,7 +60,7 @@ type BatcherService struct {
 	L1Client         *ethclient.Client
 	EndpointProvider dial.L2EndpointProvider
 	TxManager        txmgr.TxManager
-	PlasmaDA         *plasma.DAClient
+	AltDA            *altda.DAClient
 
 	BatcherConfig
 
@@ -66,11 +71,14 @@ type BatcherService struct {
 
 	driver *BatchSubmitter
 
+	altDAResponder *responder.Responder
+
 	Version string
 
-	pprofService *oppprof.Service
-	metricsSrv   *httputil.HTTPServer
-	rpcServer    *oprpc.Server
+	pprofService   *oppprof.Service
+	metricsSrv     *httputil.HTTPServer
+	rpcServer      *oprpc.Server
+	tracerProvider *sdktrace.TracerProvider
 
 	balanceMetricer io.Closer
 	stopped         atomic.Bool
@@ -95,6 +103,9 @@ func (bs *BatcherService) initFromCLIConfig(ctx context.Context, version string,
 	bs.NotSubmittingOnStart = cfg.Stopped
 
 	bs.initMetrics(cfg)
+	if err := bs.initTracing(ctx, version, cfg); err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
 
 	bs.PollInterval = cfg.PollInterval
 	bs.MaxPendingTransactions = cfg.MaxPendingTransactions
@@ -121,8 +132,11 @@ func (bs *BatcherService) initFromCLIConfig(ctx context.Context, version string,
 		return fmt.Errorf("failed to init profiling: %w", err)
 	}
 	// init before driver
-	if err := bs.initPlasmaDA(cfg); err != nil {
-		return fmt.Errorf("failed to init plasma DA: %w", err)
+	if err := bs.initAltDA(cfg); err != nil {
+		return fmt.Errorf("failed to init AltDA: %w", err)
+	}
+	if err := bs.initAltDAResponder(cfg); err != nil {
+		return fmt.Errorf("failed to init DA challenge responder: %w", err)
 	}
 	bs.initDriver()
 	if err := bs.initRPCServer(cfg); err != nil {
@@ -215,8 +229,12 @@ func (bs *BatcherService) initChannelConfig(cfg *CLIConfig) error {
 		return fmt.Errorf("unknown data availability type: %v", cfg.DataAvailabilityType)
 	}
 
-	if bs.UsePlasma && cc.MaxFrameSize > plasma.MaxInputSize {
-		return fmt.Errorf("max frame size %d exceeds plasma max input size %d", cc.MaxFrameSize, plasma.MaxInputSize)
+	if cfg.AltDA.Enabled && cfg.DataAvailabilityType != flags.CalldataType {
+		return fmt.Errorf("cannot use data availability type %q with Alt-DA", cfg.DataAvailabilityType)
+	}
+
+	if cfg.AltDA.Enabled && cc.MaxFrameSize > altda.MaxInputSize {
+		return fmt.Errorf("max frame size %d exceeds altDA max input size %d", cc.MaxFrameSize, altda.MaxInputSize)
 	}
 
 	cc.InitCompressorConfig(cfg.ApproxComprRatio, cfg.Compressor, cfg.CompressionAlgo)
@@ -238,7 +256,7 @@ func (bs *BatcherService) initChannelConfig(cfg *CLIConfig) error {
 	}
 	bs.Log.Info("Initialized channel-config",
 		"use_blobs", bs.UseBlobs,
-		"use_plasma", bs.UsePlasma,
+		"use_alt_da", cfg.AltDA.Enabled,
 		"max_frame_size", cc.MaxFrameSize,
 		"target_num_frames", cc.TargetNumFrames,
 		"compressor", cc.CompressorConfig.Kind,
@@ -260,6 +278,19 @@ func (bs *BatcherService) initTxManager(cfg *CLIConfig) error {
 	return nil
 }
 
+func (bs *BatcherService) initTracing(ctx context.Context, version string, cfg *CLIConfig) error {
+	if !cfg.TracingConfig.Enabled {
+		return nil
+	}
+	tp, err := tracing.Start(ctx, cfg.TracingConfig, "op-batcher", version)
+	if err != nil {
+		return err
+	}
+	bs.tracerProvider = tp
+	bs.Log.Info("Started tracing", "endpoint", cfg.TracingConfig.Endpoint)
+	return nil
+}
+
 func (bs *BatcherService) initPProf(cfg *CLIConfig) error {
 	bs.pprofService = oppprof.New(
 		cfg.PprofConfig.ListenEnabled,
@@ -306,7 +337,7 @@ func (bs *BatcherService) initDriver() {
 		L1Client:         bs.L1Client,
 		EndpointProvider: bs.EndpointProvider,
 		ChannelConfig:    bs.ChannelConfig,
-		PlasmaDA:         bs.PlasmaDA,
+		AltDA:            bs.AltDA,
 	})
 }
 
@@ -330,13 +361,37 @@ func (bs *BatcherService) initRPCServer(cfg *CLIConfig) error {
 	return nil
 }
 
-func (bs *BatcherService) initPlasmaDA(cfg *CLIConfig) error {
-	config := cfg.PlasmaDA
+func (bs *BatcherService) initAltDA(cfg *CLIConfig) error {
+	config := cfg.AltDA
 	if err := config.Check(); err != nil {
 		return err
 	}
-	bs.PlasmaDA = config.NewDAClient()
-	bs.UsePlasma = config.Enabled
+	bs.AltDA = config.NewDAClient()
+	bs.UseAltDA = config.Enabled
+	bs.MaxConcurrentDARequests = config.MaxConcurrentRequests
+	return nil
+}
+
+func (bs *BatcherService) initAltDAResponder(cfg *CLIConfig) error {
+	if !cfg.AltDAResponder.Enabled {
+		return nil
+	}
+	daCfg, err := bs.RollupConfig.GetOPAltDAConfig()
+	if err != nil {
+		return fmt.Errorf("invalid alt-DA rollup config: %w", err)
+	}
+	if daCfg.CommitmentType != altda.Keccak256CommitmentType {
+		return fmt.Errorf("DA challenges are only supported for %s commitments", altda.KeccakCommitmentString)
+	}
+	rCfg, err := cfg.AltDAResponder.Config(daCfg.DAChallengeContractAddress, daCfg.ResolveWindow, bs.RollupConfig.BatchInboxAddress, bs.TxManager.From())
+	if err != nil {
+		return err
+	}
+	contract, err := responder.NewChallengeContract(rCfg.DAChallengeAddress, bs.L1Client)
+	if err != nil {
+		return err
+	}
+	bs.altDAResponder = responder.NewResponder(bs.Log.New("service", "altda-responder"), rCfg, bs.Metrics, bs.L1Client, contract, bs.AltDA, bs.TxManager)
 	return nil
 }
 
@@ -345,6 +400,12 @@ func (bs *BatcherService) initPlasmaDA(cfg *CLIConfig) error {
 func (bs *BatcherService) Start(_ context.Context) error {
 	bs.driver.Log.Info("Starting batcher", "notSubmittingOnStart", bs.NotSubmittingOnStart)
 
+	if bs.altDAResponder != nil {
+		if err := bs.altDAResponder.Start(); err != nil {
+			return fmt.Errorf("failed to start DA challenge responder: %w", err)
+		}
+	}
+
 	if !bs.NotSubmittingOnStart {
 		return bs.driver.StartBatchSubmitting()
 	}
@@ -384,6 +445,9 @@ func (bs *BatcherService) Stop(ctx context.Context) error {
 			result = errors.Join(result, fmt.Errorf("failed to stop batch submitting: %w", err))
 		}
 	}
+	if bs.altDAResponder != nil {
+		bs.altDAResponder.Stop()
+	}
 
 	if bs.rpcServer != nil {
 		// TODO(7685): the op-service RPC server is not built on top of op-service httputil Server, and has poor shutdown
@@ -414,6 +478,11 @@ func (bs *BatcherService) Stop(ctx context.Context) error {
 	if bs.EndpointProvider != nil {
 		bs.EndpointProvider.Close()
 	}
+	if bs.tracerProvider != nil {
+		if err := bs.tracerProvider.Shutdown(ctx); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to shut down tracing: %w", err))
+		}
+	}
 
 	if result == nil {
 		bs.stopped.Store(true)
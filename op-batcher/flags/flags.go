@@ -8,15 +8,17 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
+	"github.com/tokamak-network/tokamak-thanos/op-alt-da/responder"
 	"github.com/tokamak-network/tokamak-thanos/op-batcher/compressor"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
-	plasma "github.com/tokamak-network/tokamak-thanos/op-plasma"
 	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
 	openum "github.com/tokamak-network/tokamak-thanos/op-service/enum"
 	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
 	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
 )
 
@@ -30,7 +32,7 @@ var (
 	// Required flags
 	L1EthRpcFlag = &cli.StringFlag{
 		Name:    "l1-eth-rpc",
-		Usage:   "HTTP provider URL for L1",
+		Usage:   "HTTP provider URL for L1. Multiple endpoints can be given, separated by '|' and optionally followed by quorum=N, to fail over between them and to require N endpoints to agree on consensus-critical calls.",
 		EnvVars: prefixEnvVars("L1_ETH_RPC"),
 	}
 	L2EthRpcFlag = &cli.StringFlag{
@@ -185,8 +187,10 @@ func init() {
 	optionalFlags = append(optionalFlags, oplog.CLIFlags(EnvVarPrefix)...)
 	optionalFlags = append(optionalFlags, opmetrics.CLIFlags(EnvVarPrefix)...)
 	optionalFlags = append(optionalFlags, oppprof.CLIFlags(EnvVarPrefix)...)
+	optionalFlags = append(optionalFlags, tracing.CLIFlags(EnvVarPrefix)...)
 	optionalFlags = append(optionalFlags, txmgr.CLIFlags(EnvVarPrefix)...)
-	optionalFlags = append(optionalFlags, plasma.CLIFlags(EnvVarPrefix, "")...)
+	optionalFlags = append(optionalFlags, altda.CLIFlags(EnvVarPrefix, "")...)
+	optionalFlags = append(optionalFlags, responder.CLIFlags(EnvVarPrefix, "")...)
 
 	Flags = append(requiredFlags, optionalFlags...)
 }
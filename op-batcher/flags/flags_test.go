@@ -98,3 +98,7 @@ func TestEnvVarFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestL1RPCUsageNamesSeparator(t *testing.T) {
+	require.Contains(t, L1EthRpcFlag.Usage, "separated by '|'")
+}
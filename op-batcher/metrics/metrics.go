@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"io"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/tokamak-network/tokamak-thanos/op-alt-da/responder"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
@@ -30,6 +32,9 @@ type Metricer interface {
 
 	opmetrics.RPCMetricer
 
+	// Record DA challenge responder metrics
+	responder.Metricer
+
 	StartBalanceMetrics(l log.Logger, client *ethclient.Client, account common.Address) io.Closer
 
 	RecordLatestL1Block(l1ref eth.L1BlockRef)
@@ -48,6 +53,11 @@ type Metricer interface {
 
 	RecordBlobUsedBytes(num int)
 
+	RecordAltDARequestSubmitted()
+	RecordAltDARequestSuccess(duration time.Duration)
+	RecordAltDARequestFailed(duration time.Duration)
+	RecordAltDARequestFallback()
+
 	Document() []opmetrics.DocumentedMetric
 }
 
@@ -59,6 +69,7 @@ type Metrics struct {
 	opmetrics.RefMetrics
 	txmetrics.TxMetrics
 	opmetrics.RPCMetrics
+	*responder.Metrics
 
 	info prometheus.GaugeVec
 	up   prometheus.Gauge
@@ -83,6 +94,11 @@ type Metrics struct {
 	batcherTxEvs opmetrics.EventVec
 
 	blobUsedBytes prometheus.Histogram
+
+	// label by submitted, success, failed, fallback
+	altDARequestEvs      opmetrics.EventVec
+	altDARequestsPending prometheus.Gauge
+	altDARequestDuration prometheus.Histogram
 }
 
 var _ Metricer = (*Metrics)(nil)
@@ -107,6 +123,7 @@ func NewMetrics(procName string) *Metrics {
 		RefMetrics: opmetrics.MakeRefMetrics(ns, factory),
 		TxMetrics:  txmetrics.MakeTxMetrics(ns, factory),
 		RPCMetrics: opmetrics.MakeRPCMetrics(ns, factory),
+		Metrics:    responder.MakeMetrics(ns, factory),
 
 		info: *factory.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: ns,
@@ -193,6 +210,19 @@ func NewMetrics(procName string) *Metrics {
 		}),
 
 		batcherTxEvs: opmetrics.NewEventVec(factory, ns, "", "batcher_tx", "BatcherTx", []string{"stage"}),
+
+		altDARequestEvs: opmetrics.NewEventVec(factory, ns, "", "altda_request", "AltDA request", []string{"stage"}),
+		altDARequestsPending: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "altda_requests_pending",
+			Help:      "Number of requests to the Alt-DA server currently in flight.",
+		}),
+		altDARequestDuration: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: ns,
+			Name:      "altda_request_duration_seconds",
+			Help:      "Duration of requests posting input data to the Alt-DA server.",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300, 600},
+		}),
 	}
 }
 
@@ -231,6 +261,11 @@ const (
 	TxStageSubmitted = "submitted"
 	TxStageSuccess   = "success"
 	TxStageFailed    = "failed"
+
+	DAStageSubmitted = "submitted"
+	DAStageSuccess   = "success"
+	DAStageFailed    = "failed"
+	DAStageFallback  = "fallback"
 )
 
 func (m *Metrics) RecordLatestL1Block(l1ref eth.L1BlockRef) {
@@ -318,6 +353,30 @@ func (m *Metrics) RecordBlobUsedBytes(num int) {
 	m.blobUsedBytes.Observe(float64(num))
 }
 
+// RecordAltDARequestSubmitted should be called when input data is sent to the Alt-DA server.
+func (m *Metrics) RecordAltDARequestSubmitted() {
+	m.altDARequestEvs.Record(DAStageSubmitted)
+	m.altDARequestsPending.Inc()
+}
+
+func (m *Metrics) RecordAltDARequestSuccess(duration time.Duration) {
+	m.altDARequestEvs.Record(DAStageSuccess)
+	m.altDARequestsPending.Dec()
+	m.altDARequestDuration.Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordAltDARequestFailed(duration time.Duration) {
+	m.altDARequestEvs.Record(DAStageFailed)
+	m.altDARequestsPending.Dec()
+	m.altDARequestDuration.Observe(duration.Seconds())
+}
+
+// RecordAltDARequestFallback should be called when input data is posted to L1 directly,
+// because the Alt-DA server was unavailable.
+func (m *Metrics) RecordAltDARequestFallback() {
+	m.altDARequestEvs.Record(DAStageFallback)
+}
+
 // estimateBatchSize estimates the size of the batch
 func estimateBatchSize(block *types.Block) uint64 {
 	size := uint64(70) // estimated overhead of batch metadata
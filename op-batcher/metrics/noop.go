@@ -2,12 +2,14 @@ package metrics
 
 import (
 	"io"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/tokamak-network/tokamak-thanos/op-alt-da/responder"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
@@ -18,6 +20,7 @@ type noopMetrics struct {
 	opmetrics.NoopRefMetrics
 	txmetrics.NoopTxMetrics
 	opmetrics.NoopRPCMetrics
+	responder.NoopMetrics
 }
 
 var NoopMetrics Metricer = new(noopMetrics)
@@ -43,6 +46,11 @@ func (*noopMetrics) RecordBatchTxSubmitted() {}
 func (*noopMetrics) RecordBatchTxSuccess()   {}
 func (*noopMetrics) RecordBatchTxFailed()    {}
 func (*noopMetrics) RecordBlobUsedBytes(int) {}
+
+func (*noopMetrics) RecordAltDARequestSubmitted()            {}
+func (*noopMetrics) RecordAltDARequestSuccess(time.Duration) {}
+func (*noopMetrics) RecordAltDARequestFailed(time.Duration)  {}
+func (*noopMetrics) RecordAltDARequestFallback()             {}
 func (*noopMetrics) StartBalanceMetrics(log.Logger, *ethclient.Client, common.Address) io.Closer {
 	return nil
 }
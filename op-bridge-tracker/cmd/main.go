@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/config"
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/flags"
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/tracker"
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/version"
+	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
+	"github.com/tokamak-network/tokamak-thanos/op-service/cliapp"
+	"github.com/tokamak-network/tokamak-thanos/op-service/ctxinterrupt"
+	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
+)
+
+var (
+	GitCommit = ""
+	GitDate   = ""
+)
+
+// VersionWithMeta holds the textual version string including the metadata.
+var VersionWithMeta = opservice.FormatVersion(version.Version, GitCommit, GitDate, version.Meta)
+
+func main() {
+	args := os.Args
+	ctx := ctxinterrupt.WithSignalWaiterMain(context.Background())
+	if err := run(ctx, args, tracker.Main); err != nil {
+		log.Crit("Application failed", "err", err)
+	}
+}
+
+type ConfiguredLifecycle func(ctx context.Context, log log.Logger, config *config.Config) (cliapp.Lifecycle, error)
+
+func run(ctx context.Context, args []string, action ConfiguredLifecycle) error {
+	oplog.SetupDefaults()
+
+	app := cli.NewApp()
+	app.Version = VersionWithMeta
+	app.Flags = cliapp.ProtectFlags(flags.Flags)
+	app.Name = "op-bridge-tracker"
+	app.Usage = "Track deposits and withdrawals"
+	app.Description = "Indexes the deposits and withdrawals of the L2 chain, and serves their status over JSON-RPC."
+	app.Action = cliapp.LifecycleCmd(func(ctx *cli.Context, close context.CancelCauseFunc) (cliapp.Lifecycle, error) {
+		logger, err := setupLogging(ctx)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Starting op-bridge-tracker", "version", VersionWithMeta)
+
+		cfg, err := flags.NewConfigFromCLI(ctx, VersionWithMeta)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("RPC endpoints",
+			"l1", cfg.L1EthRpc,
+			"l2", cfg.L2EthRpc,
+		)
+		return action(ctx.Context, logger, cfg)
+	})
+	return app.RunContext(ctx, args)
+}
+
+func setupLogging(ctx *cli.Context) (log.Logger, error) {
+	logCfg := oplog.ReadCLIConfig(ctx)
+	logger := oplog.NewLogger(oplog.AppOut(ctx), logCfg)
+	oplog.SetGlobalLogHandler(logger.Handler())
+	return logger, nil
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
+	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+)
+
+var (
+	ErrMissingL1EthRPC      = errors.New("missing l1 eth rpc url")
+	ErrMissingL2EthRPC      = errors.New("missing l2 eth rpc url")
+	ErrMissingPortalAddress = errors.New("missing portal address")
+	ErrMissingDatadir       = errors.New("missing datadir")
+	ErrInvalidPollInterval  = errors.New("poll interval must be greater than 0")
+	ErrInvalidMaxBlocks     = errors.New("max blocks must be greater than 0")
+)
+
+const (
+	// DefaultL1Confirmations is the default number of L1 blocks to stay behind the L1 head,
+	// the indexer does not handle reorgs deeper than that.
+	DefaultL1Confirmations = uint64(10)
+	// DefaultL2Confirmations is the default number of L2 blocks to stay behind the L2 head.
+	DefaultL2Confirmations = uint64(60)
+	// DefaultPollInterval is the default interval to poll for new blocks at, once the indexer caught up.
+	DefaultPollInterval = 12 * time.Second
+	// DefaultMaxBlocks is the default maximum number of blocks of each chain to index per poll.
+	DefaultMaxBlocks = uint64(100)
+)
+
+// Config is a well typed config that is parsed from the CLI params.
+// It also contains config options for auxiliary services.
+type Config struct {
+	L1EthRpc      string         // L1 RPC Url
+	L2EthRpc      string         // L2 RPC Url
+	PortalAddress common.Address // Address of the OptimismPortal proxy
+	Datadir       string         // Directory of the database
+
+	L1StartBlock    uint64        // First L1 block to index, if the database is empty
+	L2StartBlock    uint64        // First L2 block to index, if the database is empty
+	L1Confirmations uint64        // Number of L1 blocks to stay behind the L1 head
+	L2Confirmations uint64        // Number of L2 blocks to stay behind the L2 head
+	PollInterval    time.Duration // Interval to poll for new blocks at
+	MaxBlocks       uint64        // Maximum number of blocks of each chain to index per poll
+
+	Version string
+
+	MetricsConfig opmetrics.CLIConfig
+	PprofConfig   oppprof.CLIConfig
+	RPC           oprpc.CLIConfig
+}
+
+func NewConfig(l1EthRpc string, l2EthRpc string, portalAddress common.Address, datadir string) Config {
+	return Config{
+		L1EthRpc:      l1EthRpc,
+		L2EthRpc:      l2EthRpc,
+		PortalAddress: portalAddress,
+		Datadir:       datadir,
+
+		L1Confirmations: DefaultL1Confirmations,
+		L2Confirmations: DefaultL2Confirmations,
+		PollInterval:    DefaultPollInterval,
+		MaxBlocks:       DefaultMaxBlocks,
+
+		MetricsConfig: opmetrics.DefaultCLIConfig(),
+		PprofConfig:   oppprof.DefaultCLIConfig(),
+		RPC:           oprpc.DefaultCLIConfig(),
+	}
+}
+
+func (c Config) Check() error {
+	if c.L1EthRpc == "" {
+		return ErrMissingL1EthRPC
+	}
+	if c.L2EthRpc == "" {
+		return ErrMissingL2EthRPC
+	}
+	if c.PortalAddress == (common.Address{}) {
+		return ErrMissingPortalAddress
+	}
+	if c.Datadir == "" {
+		return ErrMissingDatadir
+	}
+	if c.PollInterval <= 0 {
+		return ErrInvalidPollInterval
+	}
+	if c.MaxBlocks == 0 {
+		return ErrInvalidMaxBlocks
+	}
+	if err := c.MetricsConfig.Check(); err != nil {
+		return fmt.Errorf("metrics config: %w", err)
+	}
+	if err := c.PprofConfig.Check(); err != nil {
+		return fmt.Errorf("pprof config: %w", err)
+	}
+	if err := c.RPC.Check(); err != nil {
+		return fmt.Errorf("rpc config: %w", err)
+	}
+	return nil
+}
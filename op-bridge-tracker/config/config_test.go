@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	validL1EthRpc      = "http://localhost:8545"
+	validL2EthRpc      = "http://localhost:9545"
+	validPortalAddress = common.Address{0x23}
+	validDatadir       = "/tmp/bridge-tracker"
+)
+
+func validConfig() Config {
+	return NewConfig(validL1EthRpc, validL2EthRpc, validPortalAddress, validDatadir)
+}
+
+func TestValidConfigIsValid(t *testing.T) {
+	require.NoError(t, validConfig().Check())
+}
+
+func TestL1EthRpcRequired(t *testing.T) {
+	config := validConfig()
+	config.L1EthRpc = ""
+	require.ErrorIs(t, config.Check(), ErrMissingL1EthRPC)
+}
+
+func TestL2EthRpcRequired(t *testing.T) {
+	config := validConfig()
+	config.L2EthRpc = ""
+	require.ErrorIs(t, config.Check(), ErrMissingL2EthRPC)
+}
+
+func TestPortalAddressRequired(t *testing.T) {
+	config := validConfig()
+	config.PortalAddress = common.Address{}
+	require.ErrorIs(t, config.Check(), ErrMissingPortalAddress)
+}
+
+func TestDatadirRequired(t *testing.T) {
+	config := validConfig()
+	config.Datadir = ""
+	require.ErrorIs(t, config.Check(), ErrMissingDatadir)
+}
+
+func TestPollIntervalMustBePositive(t *testing.T) {
+	config := validConfig()
+	config.PollInterval = 0
+	require.ErrorIs(t, config.Check(), ErrInvalidPollInterval)
+}
+
+func TestMaxBlocksMustBePositive(t *testing.T) {
+	config := validConfig()
+	config.MaxBlocks = 0
+	require.ErrorIs(t, config.Check(), ErrInvalidMaxBlocks)
+}
@@ -0,0 +1,151 @@
+package flags
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/config"
+	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
+	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
+	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+)
+
+const (
+	envVarPrefix = "OP_BRIDGE_TRACKER"
+)
+
+func prefixEnvVars(name string) []string {
+	return opservice.PrefixEnvVar(envVarPrefix, name)
+}
+
+var (
+	// Required Flags
+	L1EthRpcFlag = &cli.StringFlag{
+		Name:    "l1-eth-rpc",
+		Usage:   "HTTP provider URL for L1.",
+		EnvVars: prefixEnvVars("L1_ETH_RPC"),
+	}
+	L2EthRpcFlag = &cli.StringFlag{
+		Name:    "l2-eth-rpc",
+		Usage:   "HTTP provider URL for L2.",
+		EnvVars: prefixEnvVars("L2_ETH_RPC"),
+	}
+	PortalAddressFlag = &cli.StringFlag{
+		Name:    "portal-address",
+		Usage:   "Address of the OptimismPortal proxy contract.",
+		EnvVars: prefixEnvVars("PORTAL_ADDRESS"),
+	}
+	DatadirFlag = &cli.PathFlag{
+		Name:    "datadir",
+		Usage:   "Directory to store the indexed deposits and withdrawals in.",
+		EnvVars: prefixEnvVars("DATADIR"),
+	}
+	// Optional Flags
+	L1StartBlockFlag = &cli.Uint64Flag{
+		Name:    "l1-start-block",
+		Usage:   "First L1 block to index, when the datadir is empty. Should be the L1 origin of the L2 start block.",
+		EnvVars: prefixEnvVars("L1_START_BLOCK"),
+	}
+	L2StartBlockFlag = &cli.Uint64Flag{
+		Name:    "l2-start-block",
+		Usage:   "First L2 block to index, when the datadir is empty.",
+		EnvVars: prefixEnvVars("L2_START_BLOCK"),
+	}
+	L1ConfirmationsFlag = &cli.Uint64Flag{
+		Name:    "l1-confirmations",
+		Usage:   "Number of L1 blocks to stay behind the L1 head. Reorgs deeper than this stop the indexer.",
+		EnvVars: prefixEnvVars("L1_CONFIRMATIONS"),
+		Value:   config.DefaultL1Confirmations,
+	}
+	L2ConfirmationsFlag = &cli.Uint64Flag{
+		Name:    "l2-confirmations",
+		Usage:   "Number of L2 blocks to stay behind the L2 head. Reorgs deeper than this stop the indexer.",
+		EnvVars: prefixEnvVars("L2_CONFIRMATIONS"),
+		Value:   config.DefaultL2Confirmations,
+	}
+	PollIntervalFlag = &cli.DurationFlag{
+		Name:    "poll-interval",
+		Usage:   "The interval to poll for new blocks at, once the indexer caught up with the chains.",
+		EnvVars: prefixEnvVars("POLL_INTERVAL"),
+		Value:   config.DefaultPollInterval,
+	}
+	MaxBlocksFlag = &cli.Uint64Flag{
+		Name:    "max-blocks",
+		Usage:   "Maximum number of blocks of each chain to index per poll.",
+		EnvVars: prefixEnvVars("MAX_BLOCKS"),
+		Value:   config.DefaultMaxBlocks,
+	}
+)
+
+// requiredFlags are checked by [CheckRequired]
+var requiredFlags = []cli.Flag{
+	L1EthRpcFlag,
+	L2EthRpcFlag,
+	PortalAddressFlag,
+	DatadirFlag,
+}
+
+// optionalFlags is a list of unchecked cli flags
+var optionalFlags = []cli.Flag{
+	L1StartBlockFlag,
+	L2StartBlockFlag,
+	L1ConfirmationsFlag,
+	L2ConfirmationsFlag,
+	PollIntervalFlag,
+	MaxBlocksFlag,
+}
+
+func init() {
+	optionalFlags = append(optionalFlags, oprpc.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, oplog.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, opmetrics.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, oppprof.CLIFlags(envVarPrefix)...)
+
+	Flags = append(requiredFlags, optionalFlags...)
+}
+
+// Flags contains the list of configuration options available to the binary.
+var Flags []cli.Flag
+
+func CheckRequired(ctx *cli.Context) error {
+	for _, f := range requiredFlags {
+		if !ctx.IsSet(f.Names()[0]) {
+			return fmt.Errorf("flag %s is required", f.Names()[0])
+		}
+	}
+	return nil
+}
+
+// NewConfigFromCLI parses the Config from the provided flags or environment variables.
+func NewConfigFromCLI(ctx *cli.Context, version string) (*config.Config, error) {
+	if err := CheckRequired(ctx); err != nil {
+		return nil, err
+	}
+	portalAddress, err := opservice.ParseAddress(ctx.String(PortalAddressFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid portal address: %w", err)
+	}
+
+	return &config.Config{
+		L1EthRpc:      ctx.String(L1EthRpcFlag.Name),
+		L2EthRpc:      ctx.String(L2EthRpcFlag.Name),
+		PortalAddress: portalAddress,
+		Datadir:       ctx.Path(DatadirFlag.Name),
+
+		L1StartBlock:    ctx.Uint64(L1StartBlockFlag.Name),
+		L2StartBlock:    ctx.Uint64(L2StartBlockFlag.Name),
+		L1Confirmations: ctx.Uint64(L1ConfirmationsFlag.Name),
+		L2Confirmations: ctx.Uint64(L2ConfirmationsFlag.Name),
+		PollInterval:    ctx.Duration(PollIntervalFlag.Name),
+		MaxBlocks:       ctx.Uint64(MaxBlocksFlag.Name),
+
+		Version: version,
+
+		MetricsConfig: opmetrics.ReadCLIConfig(ctx),
+		PprofConfig:   oppprof.ReadCLIConfig(ctx),
+		RPC:           oprpc.ReadCLIConfig(ctx),
+	}, nil
+}
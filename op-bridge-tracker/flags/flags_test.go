@@ -0,0 +1,41 @@
+package flags
+
+import (
+	"testing"
+
+	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUniqueFlags asserts that all flag names are unique, to avoid accidental conflicts between the many flags.
+func TestUniqueFlags(t *testing.T) {
+	seenCLI := make(map[string]struct{})
+	for _, flag := range Flags {
+		for _, name := range flag.Names() {
+			if _, ok := seenCLI[name]; ok {
+				t.Errorf("duplicate flag %s", name)
+				continue
+			}
+			seenCLI[name] = struct{}{}
+		}
+	}
+}
+
+func TestEnvVarFormat(t *testing.T) {
+	for _, flag := range Flags {
+		flag := flag
+		flagName := flag.Names()[0]
+
+		t.Run(flagName, func(t *testing.T) {
+			envFlagGetter, ok := flag.(interface {
+				GetEnvVars() []string
+			})
+			require.True(t, ok, "must be able to cast the flag to an EnvVar interface")
+			envFlags := envFlagGetter.GetEnvVars()
+			require.Equal(t, 1, len(envFlags), "flags should have exactly one env var")
+			expectedEnvVar := opservice.FlagNameToEnvVarName(flagName, envVarPrefix)
+			require.Equal(t, expectedEnvVar, envFlags[0])
+		})
+	}
+}
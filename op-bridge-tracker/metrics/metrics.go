@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+)
+
+const Namespace = "op_bridge_tracker"
+
+type Metricer interface {
+	RecordInfo(version string)
+	RecordUp()
+
+	opmetrics.RPCMetricer
+
+	CacheAdd(label string, cacheSize int, evicted bool)
+	CacheGet(label string, hit bool)
+
+	RecordIndexedBlock(chain string, ref eth.BlockRef)
+	RecordDeposits(count int)
+	RecordWithdrawalEvents(event string, count int)
+	RecordReorg(chain string)
+
+	Document() []opmetrics.DocumentedMetric
+}
+
+type Metrics struct {
+	ns       string
+	registry *prometheus.Registry
+	factory  opmetrics.Factory
+
+	opmetrics.RPCMetrics
+	*opmetrics.CacheMetrics
+	RefMetrics opmetrics.RefMetrics
+
+	deposits         prometheus.Counter
+	withdrawalEvents *prometheus.CounterVec
+	reorgs           *prometheus.CounterVec
+
+	info prometheus.GaugeVec
+	up   prometheus.Gauge
+}
+
+var _ Metricer = (*Metrics)(nil)
+
+// implements the Registry getter, for metrics HTTP server to hook into
+var _ opmetrics.RegistryMetricer = (*Metrics)(nil)
+
+func NewMetrics() *Metrics {
+	registry := opmetrics.NewRegistry()
+	factory := opmetrics.With(registry)
+	ns := Namespace
+
+	return &Metrics{
+		ns:       ns,
+		registry: registry,
+		factory:  factory,
+
+		RPCMetrics:   opmetrics.MakeRPCMetrics(ns, factory),
+		CacheMetrics: opmetrics.NewCacheMetrics(factory, ns, "source_rpc_cache", "Source RPC cache"),
+		RefMetrics:   opmetrics.MakeRefMetrics(ns, factory),
+
+		deposits: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "deposits_total",
+			Help:      "Number of TransactionDeposited events indexed",
+		}),
+		withdrawalEvents: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "withdrawal_events_total",
+			Help:      "Number of MessagePassed, WithdrawalProven and WithdrawalFinalized events indexed",
+		}, []string{
+			"event",
+		}),
+		reorgs: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "reorgs_total",
+			Help:      "Number of reorgs of indexed blocks",
+		}, []string{
+			"chain",
+		}),
+
+		info: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "info",
+			Help:      "Pseudo-metric tracking version and config info",
+		}, []string{
+			"version",
+		}),
+		up: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "up",
+			Help:      "1 if the op-bridge-tracker has finished starting up",
+		}),
+	}
+}
+
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) Document() []opmetrics.DocumentedMetric {
+	return m.factory.Document()
+}
+
+// RecordInfo sets a pseudo-metric that contains versioning and config info for the op-bridge-tracker.
+func (m *Metrics) RecordInfo(version string) {
+	m.info.WithLabelValues(version).Set(1)
+}
+
+// RecordUp sets the up metric to 1.
+func (m *Metrics) RecordUp() {
+	m.up.Set(1)
+}
+
+// RecordIndexedBlock records the last block of the chain that was indexed.
+func (m *Metrics) RecordIndexedBlock(chain string, ref eth.BlockRef) {
+	m.RefMetrics.RecordRef(chain, "indexed", ref.Number, ref.Time, ref.Hash)
+}
+
+func (m *Metrics) RecordDeposits(count int) {
+	m.deposits.Add(float64(count))
+}
+
+func (m *Metrics) RecordWithdrawalEvents(event string, count int) {
+	m.withdrawalEvents.WithLabelValues(event).Add(float64(count))
+}
+
+func (m *Metrics) RecordReorg(chain string) {
+	m.reorgs.WithLabelValues(chain).Inc()
+}
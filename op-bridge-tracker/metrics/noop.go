@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+)
+
+type noopMetrics struct {
+	opmetrics.NoopRPCMetrics
+}
+
+var NoopMetrics Metricer = new(noopMetrics)
+
+func (*noopMetrics) Document() []opmetrics.DocumentedMetric { return nil }
+
+func (*noopMetrics) RecordInfo(version string) {}
+func (*noopMetrics) RecordUp()                 {}
+
+func (*noopMetrics) CacheAdd(_ string, _ int, _ bool) {}
+func (*noopMetrics) CacheGet(_ string, _ bool)        {}
+
+func (*noopMetrics) RecordIndexedBlock(_ string, _ eth.BlockRef) {}
+func (*noopMetrics) RecordDeposits(_ int)                        {}
+func (*noopMetrics) RecordWithdrawalEvents(_ string, _ int)      {}
+func (*noopMetrics) RecordReorg(_ string)                        {}
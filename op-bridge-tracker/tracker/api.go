@@ -0,0 +1,212 @@
+package tracker
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/clock"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+// DepositView is a deposit with its status.
+type DepositView struct {
+	*Deposit
+	Status DepositStatus `json:"status"`
+}
+
+// WithdrawalView is a withdrawal with its status at the time of the query.
+type WithdrawalView struct {
+	*Withdrawal
+	Status WithdrawalStatus `json:"status"`
+	// FinalizableAt is the time the finalization period of the earliest proof of a proven withdrawal ends.
+	// With fault proofs, the withdrawal is only finalizable once the dispute game of a proof is resolved too.
+	FinalizableAt *hexutil.Uint64 `json:"finalizableAt,omitempty"`
+	// FinalizableIn is the number of seconds until the finalization period of the earliest proof of a proven
+	// withdrawal ends, or 0 if it ended already.
+	FinalizableIn *hexutil.Uint64 `json:"finalizableIn,omitempty"`
+}
+
+// Records are the deposits and withdrawals that match a query.
+type Records struct {
+	Deposits    []*DepositView    `json:"deposits"`
+	Withdrawals []*WithdrawalView `json:"withdrawals"`
+}
+
+// SyncStatus is the progress of the indexer.
+type SyncStatus struct {
+	L1 *eth.BlockRef `json:"l1"`
+	L2 *eth.BlockRef `json:"l2"`
+	// FinalizationPeriod is the number of seconds a withdrawal proof must mature before finalization.
+	FinalizationPeriod hexutil.Uint64 `json:"finalizationPeriod"`
+}
+
+// WithdrawalChecker checks whether a withdrawal can be finalized with the proof of the submitter.
+// On an OptimismPortal2, the dispute game of the proof must be resolved in favor of the proposal.
+type WithdrawalChecker interface {
+	CheckWithdrawal(ctx context.Context, hash common.Hash, submitter common.Address) (bool, error)
+}
+
+// API is the JSON-RPC API of the tracker, served in the "tracker" namespace.
+type API struct {
+	db                 *DB
+	clock              clock.Clock
+	finalizationPeriod uint64
+	// checker checks the matured proofs of an OptimismPortal2, it is nil for an OptimismPortal.
+	checker WithdrawalChecker
+}
+
+func NewAPI(db *DB, cl clock.Clock, finalizationPeriod uint64, checker WithdrawalChecker) *API {
+	return &API{db: db, clock: cl, finalizationPeriod: finalizationPeriod, checker: checker}
+}
+
+// GetTransactionStatus returns the deposits and withdrawals that the transaction initiated, executed, proved or
+// finalized. The transaction is any L1 or L2 transaction of a deposit or withdrawal.
+func (a *API) GetTransactionStatus(ctx context.Context, txHash common.Hash) (*Records, error) {
+	deposits, withdrawals, err := a.db.ByTxHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(deposits) == 0 && len(withdrawals) == 0 {
+		return nil, fmt.Errorf("no deposit or withdrawal of transaction %s: %w", txHash, ErrNotFound)
+	}
+	return a.records(ctx, deposits, withdrawals, func(DepositStatus) bool { return true }, func(WithdrawalStatus) bool { return true })
+}
+
+// GetPending returns the deposits and withdrawals of the address that are not completed yet:
+// deposits that are not executed on L2, and withdrawals that are not finalized on L1.
+func (a *API) GetPending(ctx context.Context, addr common.Address) (*Records, error) {
+	deposits, withdrawals, err := a.db.ByAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	return a.records(ctx, deposits, withdrawals,
+		func(s DepositStatus) bool { return s == DepositPending },
+		func(s WithdrawalStatus) bool { return s != WithdrawalFinalized && s != WithdrawalFailed },
+	)
+}
+
+// GetDeposit returns the deposit with the given L2 transaction hash.
+func (a *API) GetDeposit(_ context.Context, l2TxHash common.Hash) (*DepositView, error) {
+	dep, err := a.db.Deposit(l2TxHash)
+	if err != nil {
+		return nil, a.lookupErr("deposit", l2TxHash, err)
+	}
+	return &DepositView{Deposit: dep, Status: dep.Status()}, nil
+}
+
+// GetWithdrawal returns the withdrawal with the given withdrawal hash.
+func (a *API) GetWithdrawal(ctx context.Context, hash common.Hash) (*WithdrawalView, error) {
+	w, err := a.db.Withdrawal(hash)
+	if err != nil {
+		return nil, a.lookupErr("withdrawal", hash, err)
+	}
+	return a.withdrawalView(ctx, w, uint64(a.clock.Now().Unix()))
+}
+
+// SyncStatus returns the last indexed blocks, which are nil before the first block is indexed.
+func (a *API) SyncStatus(_ context.Context) (*SyncStatus, error) {
+	status := &SyncStatus{FinalizationPeriod: hexutil.Uint64(a.finalizationPeriod)}
+	for chain, dst := range map[Chain]**eth.BlockRef{ChainL1: &status.L1, ChainL2: &status.L2} {
+		ref, err := a.db.Cursor(chain)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		*dst = &ref
+	}
+	return status, nil
+}
+
+func (a *API) lookupErr(kind string, hash common.Hash, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%s %s: %w", kind, hash, ErrNotFound)
+	}
+	return err
+}
+
+func (a *API) records(ctx context.Context, deposits []*Deposit, withdrawals []*Withdrawal, depositFilter func(DepositStatus) bool, withdrawalFilter func(WithdrawalStatus) bool) (*Records, error) {
+	now := uint64(a.clock.Now().Unix())
+	out := &Records{Deposits: []*DepositView{}, Withdrawals: []*WithdrawalView{}}
+	for _, dep := range deposits {
+		if status := dep.Status(); depositFilter(status) {
+			out.Deposits = append(out.Deposits, &DepositView{Deposit: dep, Status: status})
+		}
+	}
+	for _, w := range withdrawals {
+		view, err := a.withdrawalView(ctx, w, now)
+		if err != nil {
+			return nil, err
+		}
+		if withdrawalFilter(view.Status) {
+			out.Withdrawals = append(out.Withdrawals, view)
+		}
+	}
+	slices.SortFunc(out.Deposits, func(x, y *DepositView) int {
+		return cmp.Or(cmp.Compare(x.Initiated.BlockNumber, y.Initiated.BlockNumber), cmp.Compare(x.LogIndex, y.LogIndex))
+	})
+	slices.SortFunc(out.Withdrawals, func(x, y *WithdrawalView) int {
+		return cmp.Compare(withdrawalOrder(x.Withdrawal), withdrawalOrder(y.Withdrawal))
+	})
+	return out, nil
+}
+
+func (a *API) withdrawalView(ctx context.Context, w *Withdrawal, now uint64) (*WithdrawalView, error) {
+	view := &WithdrawalView{Withdrawal: w, Status: w.Status(now, a.finalizationPeriod)}
+	if view.Status == WithdrawalFinalizable && a.checker != nil {
+		ok, err := a.checkProofs(ctx, w, now)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			view.Status = WithdrawalProven
+		}
+	}
+	if len(w.Proofs) > 0 && w.Finalized == nil {
+		at := w.FinalizableAt(a.finalizationPeriod)
+		var in uint64
+		if at > now {
+			in = at - now
+		}
+		view.FinalizableAt = (*hexutil.Uint64)(&at)
+		view.FinalizableIn = (*hexutil.Uint64)(&in)
+	}
+	return view, nil
+}
+
+// checkProofs returns whether the withdrawal can be finalized with any of its matured proofs.
+func (a *API) checkProofs(ctx context.Context, w *Withdrawal, now uint64) (bool, error) {
+	for _, p := range w.maturedProofs(now, a.finalizationPeriod) {
+		if p.Submitter == nil {
+			continue
+		}
+		ok, err := a.checker.CheckWithdrawal(ctx, w.Hash, *p.Submitter)
+		if err != nil {
+			return false, fmt.Errorf("failed to check proof of withdrawal %s by %s: %w", w.Hash, *p.Submitter, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// withdrawalOrder orders withdrawals by the time they are initiated, or else proven or finalized.
+func withdrawalOrder(w *Withdrawal) hexutil.Uint64 {
+	switch {
+	case w.Initiated != nil:
+		return w.Initiated.Timestamp
+	case len(w.Proofs) > 0:
+		return w.firstProof().Timestamp
+	case w.Finalized != nil:
+		return w.Finalized.Timestamp
+	default:
+		return 0
+	}
+}
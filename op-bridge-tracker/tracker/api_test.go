@@ -0,0 +1,169 @@
+package tracker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/clock"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+// stubChecker accepts the proofs of the submitters that are set to true.
+type stubChecker map[common.Address]bool
+
+func (s stubChecker) CheckWithdrawal(_ context.Context, _ common.Hash, submitter common.Address) (bool, error) {
+	return s[submitter], nil
+}
+
+func TestAPI(t *testing.T) {
+	db := newTestDB(t)
+	cl := clock.NewDeterministicClock(time.Unix(1500, 0))
+	api := NewAPI(db, cl, 1000, nil)
+	ctx := context.Background()
+
+	pending := &Deposit{
+		L2TxHash:  common.Hash{0xd2},
+		Initiated: Inclusion{TxHash: common.Hash{0xd1}, BlockNumber: 6, Timestamp: 1012},
+		From:      alice,
+		To:        &bob,
+		Value:     (*hexutil.Big)(big.NewInt(1)),
+		Data:      hexutil.Bytes{},
+	}
+	executed := &Deposit{
+		L2TxHash:  common.Hash{0xd4},
+		Initiated: Inclusion{TxHash: common.Hash{0xd3}, BlockNumber: 5, Timestamp: 1000},
+		From:      alice,
+		To:        &bob,
+		Value:     (*hexutil.Big)(big.NewInt(1)),
+		Data:      hexutil.Bytes{},
+		Executed:  &Execution{Inclusion: Inclusion{TxHash: common.Hash{0xd4}, BlockNumber: 40, Timestamp: 1002}, Success: true},
+	}
+	proven := &Withdrawal{
+		Hash:      common.Hash{0x77},
+		Initiated: &Inclusion{TxHash: common.Hash{0xe1}, BlockNumber: 50, Timestamp: 900},
+		Sender:    alice,
+		Target:    bob,
+		Proofs:    []Proof{{Inclusion: Inclusion{TxHash: common.Hash{0xe2}, BlockNumber: 7, Timestamp: 1024}}},
+	}
+	finalized := &Withdrawal{
+		Hash:      common.Hash{0x78},
+		Sender:    alice,
+		Target:    bob,
+		Finalized: &Finalization{Inclusion: Inclusion{TxHash: common.Hash{0xe3}, BlockNumber: 7, Timestamp: 1024}, Success: true},
+	}
+	require.NoError(t, db.Update(ChainL1, eth.BlockRef{Number: 7, Time: 1024}, []*Deposit{pending, executed}, []*Withdrawal{proven, finalized}))
+
+	t.Run("GetPending", func(t *testing.T) {
+		records, err := api.GetPending(ctx, alice)
+		require.NoError(t, err)
+		require.Equal(t, []*DepositView{{Deposit: pending, Status: DepositPending}}, records.Deposits)
+		require.Len(t, records.Withdrawals, 1)
+		view := records.Withdrawals[0]
+		require.Equal(t, proven, view.Withdrawal)
+		require.Equal(t, WithdrawalProven, view.Status)
+		require.Equal(t, hexutil.Uint64(2024), *view.FinalizableAt)
+		require.Equal(t, hexutil.Uint64(524), *view.FinalizableIn)
+
+		// Once the finalization period ended the withdrawal is finalizable, and still pending.
+		cl.AdvanceTime(time.Hour)
+		records, err = api.GetPending(ctx, alice)
+		require.NoError(t, err)
+		require.Len(t, records.Withdrawals, 1)
+		require.Equal(t, WithdrawalFinalizable, records.Withdrawals[0].Status)
+		require.Equal(t, hexutil.Uint64(0), *records.Withdrawals[0].FinalizableIn)
+
+		records, err = api.GetPending(ctx, common.Address{0x99})
+		require.NoError(t, err)
+		require.NotNil(t, records.Deposits)
+		require.Empty(t, records.Deposits)
+		require.NotNil(t, records.Withdrawals)
+		require.Empty(t, records.Withdrawals)
+	})
+
+	t.Run("GetTransactionStatus", func(t *testing.T) {
+		records, err := api.GetTransactionStatus(ctx, common.Hash{0xe3})
+		require.NoError(t, err)
+		require.Empty(t, records.Deposits)
+		require.Len(t, records.Withdrawals, 1)
+		require.Equal(t, WithdrawalFinalized, records.Withdrawals[0].Status)
+		require.Nil(t, records.Withdrawals[0].FinalizableAt)
+
+		records, err = api.GetTransactionStatus(ctx, common.Hash{0xd4})
+		require.NoError(t, err)
+		require.Equal(t, []*DepositView{{Deposit: executed, Status: DepositExecuted}}, records.Deposits)
+
+		_, err = api.GetTransactionStatus(ctx, common.Hash{0x99})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("GetDepositAndWithdrawal", func(t *testing.T) {
+		dep, err := api.GetDeposit(ctx, pending.L2TxHash)
+		require.NoError(t, err)
+		require.Equal(t, DepositPending, dep.Status)
+		_, err = api.GetDeposit(ctx, common.Hash{0x99})
+		require.ErrorIs(t, err, ErrNotFound)
+
+		w, err := api.GetWithdrawal(ctx, finalized.Hash)
+		require.NoError(t, err)
+		require.Equal(t, WithdrawalFinalized, w.Status)
+		_, err = api.GetWithdrawal(ctx, common.Hash{0x99})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("SyncStatus", func(t *testing.T) {
+		status, err := api.SyncStatus(ctx)
+		require.NoError(t, err)
+		require.Equal(t, &eth.BlockRef{Number: 7, Time: 1024}, status.L1)
+		require.Nil(t, status.L2)
+		require.Equal(t, hexutil.Uint64(1000), status.FinalizationPeriod)
+	})
+}
+
+func TestAPIFaultProofs(t *testing.T) {
+	db := newTestDB(t)
+	cl := clock.NewDeterministicClock(time.Unix(2500, 0))
+	checker := stubChecker{}
+	api := NewAPI(db, cl, 1000, checker)
+	ctx := context.Background()
+
+	// Proven by alice first, and by bob later.
+	w := &Withdrawal{
+		Hash:   common.Hash{0x77},
+		Sender: alice,
+		Target: bob,
+		Proofs: []Proof{
+			{Inclusion: Inclusion{TxHash: common.Hash{0xe2}, BlockNumber: 7, Timestamp: 1024}, Submitter: &alice},
+			{Inclusion: Inclusion{TxHash: common.Hash{0xe3}, BlockNumber: 9, Timestamp: 2000}, Submitter: &bob},
+		},
+	}
+	require.NoError(t, db.Update(ChainL1, eth.BlockRef{Number: 9, Time: 2000}, nil, []*Withdrawal{w}))
+
+	// The proof of alice matured, but its dispute game is not resolved.
+	view, err := api.GetWithdrawal(ctx, w.Hash)
+	require.NoError(t, err)
+	require.Equal(t, WithdrawalProven, view.Status)
+	require.Equal(t, hexutil.Uint64(2024), *view.FinalizableAt)
+	require.Equal(t, hexutil.Uint64(0), *view.FinalizableIn)
+
+	// The proof of bob is only checked once it matured.
+	checker[bob] = true
+	view, err = api.GetWithdrawal(ctx, w.Hash)
+	require.NoError(t, err)
+	require.Equal(t, WithdrawalProven, view.Status)
+	cl.AdvanceTime(time.Hour)
+	view, err = api.GetWithdrawal(ctx, w.Hash)
+	require.NoError(t, err)
+	require.Equal(t, WithdrawalFinalizable, view.Status)
+
+	records, err := api.GetPending(ctx, alice)
+	require.NoError(t, err)
+	require.Len(t, records.Withdrawals, 1)
+	require.Equal(t, WithdrawalFinalizable, records.Withdrawals[0].Status)
+}
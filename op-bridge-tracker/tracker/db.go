@@ -0,0 +1,439 @@
+package tracker
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidEntry = errors.New("invalid db entry")
+)
+
+const (
+	// Keys are prefixed with a constant byte to allow us to differentiate different "columns" within the data
+	keyPrefixCursor     byte = 0
+	keyPrefixDeposit    byte = 1
+	keyPrefixWithdrawal byte = 2
+	keyPrefixTxHash     byte = 3
+	keyPrefixAddress    byte = 4
+	keyPrefixJournal    byte = 5
+)
+
+// journalLimit is the number of updates that are kept in the journal, and can be reverted on a reorg.
+// Updates are per block of either chain, so this covers hours of L2 blocks.
+const journalLimit = 10_000
+
+// Chain identifies the chain of an indexer cursor.
+type Chain byte
+
+const (
+	ChainL1 Chain = 1
+	ChainL2 Chain = 2
+)
+
+func (c Chain) String() string {
+	switch c {
+	case ChainL1:
+		return "l1"
+	case ChainL2:
+		return "l2"
+	default:
+		return fmt.Sprintf("chain(%d)", byte(c))
+	}
+}
+
+// recordKind identifies the record an index entry points to.
+type recordKind byte
+
+const (
+	kindDeposit    recordKind = 1
+	kindWithdrawal recordKind = 2
+)
+
+func cursorKey(chain Chain) []byte {
+	return []byte{keyPrefixCursor, byte(chain)}
+}
+
+func depositKey(l2TxHash common.Hash) []byte {
+	return append([]byte{keyPrefixDeposit}, l2TxHash[:]...)
+}
+
+func withdrawalKey(hash common.Hash) []byte {
+	return append([]byte{keyPrefixWithdrawal}, hash[:]...)
+}
+
+// indexKey is the key of an index entry: prefix ++ indexed value ++ kind ++ record id.
+// Index entries have no value, all entries of an indexed value are found by iterating over the key prefix.
+func indexKey(prefix byte, indexed []byte, kind recordKind, id common.Hash) []byte {
+	key := make([]byte, 0, 1+len(indexed)+1+common.HashLength)
+	key = append(key, prefix)
+	key = append(key, indexed...)
+	key = append(key, byte(kind))
+	return append(key, id[:]...)
+}
+
+func journalKey(seq uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{keyPrefixJournal}, seq)
+}
+
+func journalRange() *pebble.IterOptions {
+	return &pebble.IterOptions{LowerBound: []byte{keyPrefixJournal}, UpperBound: []byte{keyPrefixJournal + 1}}
+}
+
+func indexRange(prefix byte, indexed []byte) *pebble.IterOptions {
+	lower := append([]byte{prefix}, indexed...)
+	upper := append([]byte{prefix}, indexed...)
+	upper = append(upper, 0xff)
+	return &pebble.IterOptions{LowerBound: lower, UpperBound: upper}
+}
+
+// DB stores the deposits and withdrawals, and the progress of the indexers.
+type DB struct {
+	// m ensures all read iterators are closed before closing the database by preventing concurrent read and write
+	// operations (with close considered a write operation).
+	m   sync.RWMutex
+	log log.Logger
+	db  *pebble.DB
+
+	writeOpts *pebble.WriteOptions
+
+	closed bool
+}
+
+func OpenDB(logger log.Logger, path string) (*DB, error) {
+	db, err := pebble.Open(path, &pebble.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return &DB{
+		log:       logger,
+		db:        db,
+		writeOpts: &pebble.WriteOptions{Sync: true},
+	}, nil
+}
+
+// Cursor returns the last block the indexer of the chain processed, or ErrNotFound if it did not process any block.
+func (d *DB) Cursor(chain Chain) (eth.BlockRef, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	var ref eth.BlockRef
+	if err := d.get(cursorKey(chain), &ref); err != nil {
+		return eth.BlockRef{}, err
+	}
+	return ref, nil
+}
+
+// Deposit returns the deposit with the given L2 transaction hash.
+func (d *DB) Deposit(l2TxHash common.Hash) (*Deposit, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	return d.deposit(l2TxHash)
+}
+
+func (d *DB) deposit(l2TxHash common.Hash) (*Deposit, error) {
+	var dep Deposit
+	if err := d.get(depositKey(l2TxHash), &dep); err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
+
+// Withdrawal returns the withdrawal with the given withdrawal hash.
+func (d *DB) Withdrawal(hash common.Hash) (*Withdrawal, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	return d.withdrawal(hash)
+}
+
+func (d *DB) withdrawal(hash common.Hash) (*Withdrawal, error) {
+	var w Withdrawal
+	if err := d.get(withdrawalKey(hash), &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ByTxHash returns the deposits and withdrawals that one of the steps of was included in the transaction.
+func (d *DB) ByTxHash(txHash common.Hash) ([]*Deposit, []*Withdrawal, error) {
+	return d.lookup(keyPrefixTxHash, txHash[:])
+}
+
+// ByAddress returns the deposits and withdrawals that involve the address.
+func (d *DB) ByAddress(addr common.Address) ([]*Deposit, []*Withdrawal, error) {
+	return d.lookup(keyPrefixAddress, addr[:])
+}
+
+func (d *DB) lookup(prefix byte, indexed []byte) ([]*Deposit, []*Withdrawal, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	iter, err := d.db.NewIter(indexRange(prefix, indexed))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create iterator: %w", err)
+	}
+	defer iter.Close()
+	var deposits []*Deposit
+	var withdrawals []*Withdrawal
+	for valid := iter.First(); valid; valid = iter.Next() {
+		key := iter.Key()
+		if len(key) != 1+len(indexed)+1+common.HashLength {
+			return nil, nil, fmt.Errorf("%w: index key %x", ErrInvalidEntry, key)
+		}
+		id := common.BytesToHash(key[len(key)-common.HashLength:])
+		switch recordKind(key[1+len(indexed)]) {
+		case kindDeposit:
+			dep, err := d.deposit(id)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to load indexed deposit %s: %w", id, err)
+			}
+			deposits = append(deposits, dep)
+		case kindWithdrawal:
+			w, err := d.withdrawal(id)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to load indexed withdrawal %s: %w", id, err)
+			}
+			withdrawals = append(withdrawals, w)
+		default:
+			return nil, nil, fmt.Errorf("%w: index key %x", ErrInvalidEntry, key)
+		}
+	}
+	return deposits, withdrawals, iter.Error()
+}
+
+// journalEntry is the state an update replaced, to revert the update on a reorg.
+type journalEntry struct {
+	Chain Chain        `json:"chain"`
+	Block eth.BlockRef `json:"block"`
+	// PrevCursor is nil if the update indexed the first block of the chain.
+	PrevCursor *eth.BlockRef `json:"prevCursor,omitempty"`
+	// PrevDeposits and PrevWithdrawals are the previous versions of the updated records, nil for new records.
+	PrevDeposits    map[common.Hash]*Deposit    `json:"prevDeposits,omitempty"`
+	PrevWithdrawals map[common.Hash]*Withdrawal `json:"prevWithdrawals,omitempty"`
+	// IndexKeys are the index entries the update added.
+	IndexKeys [][]byte `json:"indexKeys,omitempty"`
+}
+
+// Update writes the deposits and withdrawals that changed in the block, and moves the cursor of the chain to the
+// block, atomically. Records replace the previous version of the record, index entries are only added.
+// The update is journaled, so that it can be reverted if the block is reorged out.
+func (d *DB) Update(chain Chain, block eth.BlockRef, deposits []*Deposit, withdrawals []*Withdrawal) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	batch := d.db.NewBatch()
+	defer batch.Close()
+	entry := &journalEntry{
+		Chain:           chain,
+		Block:           block,
+		PrevDeposits:    make(map[common.Hash]*Deposit),
+		PrevWithdrawals: make(map[common.Hash]*Withdrawal),
+	}
+	var prevCursor eth.BlockRef
+	if err := d.get(cursorKey(chain), &prevCursor); err == nil {
+		entry.PrevCursor = &prevCursor
+	} else if !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to read %v cursor: %w", chain, err)
+	}
+	for _, dep := range deposits {
+		prev, err := d.deposit(dep.L2TxHash)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to read deposit %s: %w", dep.L2TxHash, err)
+		}
+		entry.PrevDeposits[dep.L2TxHash] = prev
+		if err := d.set(batch, depositKey(dep.L2TxHash), dep); err != nil {
+			return fmt.Errorf("failed to write deposit %s: %w", dep.L2TxHash, err)
+		}
+		if err := d.index(batch, entry, kindDeposit, dep.L2TxHash, dep.TxHashes(), dep.Addresses()); err != nil {
+			return fmt.Errorf("failed to index deposit %s: %w", dep.L2TxHash, err)
+		}
+	}
+	for _, w := range withdrawals {
+		prev, err := d.withdrawal(w.Hash)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to read withdrawal %s: %w", w.Hash, err)
+		}
+		entry.PrevWithdrawals[w.Hash] = prev
+		if err := d.set(batch, withdrawalKey(w.Hash), w); err != nil {
+			return fmt.Errorf("failed to write withdrawal %s: %w", w.Hash, err)
+		}
+		if err := d.index(batch, entry, kindWithdrawal, w.Hash, w.TxHashes(), w.Addresses()); err != nil {
+			return fmt.Errorf("failed to index withdrawal %s: %w", w.Hash, err)
+		}
+	}
+	if err := d.set(batch, cursorKey(chain), block); err != nil {
+		return fmt.Errorf("failed to write %v cursor: %w", chain, err)
+	}
+	if err := d.journal(batch, entry); err != nil {
+		return fmt.Errorf("failed to journal %v block %v: %w", chain, block, err)
+	}
+	if err := batch.Commit(d.writeOpts); err != nil {
+		return fmt.Errorf("failed to commit %v block %v: %w", chain, block, err)
+	}
+	return nil
+}
+
+// index adds the index entries of a record, and journals the entries that did not exist yet.
+func (d *DB) index(batch *pebble.Batch, entry *journalEntry, kind recordKind, id common.Hash, txHashes []common.Hash, addrs []common.Address) error {
+	add := func(key []byte) error {
+		_, closer, err := d.db.Get(key)
+		if err == nil {
+			return closer.Close()
+		} else if !errors.Is(err, pebble.ErrNotFound) {
+			return err
+		}
+		entry.IndexKeys = append(entry.IndexKeys, key)
+		return batch.Set(key, nil, d.writeOpts)
+	}
+	for _, h := range txHashes {
+		if err := add(indexKey(keyPrefixTxHash, h[:], kind, id)); err != nil {
+			return err
+		}
+	}
+	for _, addr := range addrs {
+		if addr == (common.Address{}) {
+			// Not known yet, e.g. the sender of a withdrawal that was only seen finalized so far.
+			continue
+		}
+		if err := add(indexKey(keyPrefixAddress, addr[:], kind, id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// journal appends the entry to the journal, and prunes the entries beyond the journal limit.
+func (d *DB) journal(batch *pebble.Batch, entry *journalEntry) error {
+	seq, ok, err := d.lastJournalSeq()
+	if err != nil {
+		return err
+	}
+	if ok {
+		seq++
+	}
+	if err := d.set(batch, journalKey(seq), entry); err != nil {
+		return err
+	}
+	if seq >= journalLimit {
+		return batch.DeleteRange(journalKey(0), journalKey(seq-journalLimit+1), d.writeOpts)
+	}
+	return nil
+}
+
+func (d *DB) lastJournalSeq() (uint64, bool, error) {
+	iter, err := d.db.NewIter(journalRange())
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create iterator: %w", err)
+	}
+	defer iter.Close()
+	if !iter.Last() {
+		return 0, false, iter.Error()
+	}
+	key := iter.Key()
+	if len(key) != 1+8 {
+		return 0, false, fmt.Errorf("%w: journal key %x", ErrInvalidEntry, key)
+	}
+	return binary.BigEndian.Uint64(key[1:]), true, nil
+}
+
+// Revert reverts the updates back to and including the last update of the chain, newest first, atomically.
+// Updates of the other chain after that update are reverted too, so that the records are consistent.
+// It returns ErrNotFound if the journal has no update of the chain.
+func (d *DB) Revert(chain Chain) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	iter, err := d.db.NewIter(journalRange())
+	if err != nil {
+		return fmt.Errorf("failed to create iterator: %w", err)
+	}
+	defer iter.Close()
+	batch := d.db.NewBatch()
+	defer batch.Close()
+	for valid := iter.Last(); valid; valid = iter.Prev() {
+		var entry journalEntry
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEntry, err)
+		}
+		if err := d.revert(batch, &entry); err != nil {
+			return fmt.Errorf("failed to revert %v block %v: %w", entry.Chain, entry.Block, err)
+		}
+		if err := batch.Delete(slices.Clone(iter.Key()), d.writeOpts); err != nil {
+			return err
+		}
+		if entry.Chain == chain {
+			return batch.Commit(d.writeOpts)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return fmt.Errorf("no journaled update of %v: %w", chain, ErrNotFound)
+}
+
+func (d *DB) revert(batch *pebble.Batch, entry *journalEntry) error {
+	for hash, prev := range entry.PrevDeposits {
+		if err := d.restore(batch, depositKey(hash), prev, prev == nil); err != nil {
+			return err
+		}
+	}
+	for hash, prev := range entry.PrevWithdrawals {
+		if err := d.restore(batch, withdrawalKey(hash), prev, prev == nil); err != nil {
+			return err
+		}
+	}
+	for _, key := range entry.IndexKeys {
+		if err := batch.Delete(key, d.writeOpts); err != nil {
+			return err
+		}
+	}
+	return d.restore(batch, cursorKey(entry.Chain), entry.PrevCursor, entry.PrevCursor == nil)
+}
+
+// restore sets the key to the previous value, or deletes it if there was no previous value.
+func (d *DB) restore(batch *pebble.Batch, key []byte, prev any, absent bool) error {
+	if absent {
+		return batch.Delete(key, d.writeOpts)
+	}
+	return d.set(batch, key, prev)
+}
+
+func (d *DB) set(batch *pebble.Batch, key []byte, v any) error {
+	val, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return batch.Set(key, val, d.writeOpts)
+}
+
+func (d *DB) get(key []byte, v any) error {
+	val, closer, err := d.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	defer closer.Close()
+	if err := json.Unmarshal(val, v); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEntry, err)
+	}
+	return nil
+}
+
+func (d *DB) Close() error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if d.closed {
+		// Already closed
+		return nil
+	}
+	d.closed = true
+	return d.db.Close()
+}
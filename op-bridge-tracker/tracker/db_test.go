@@ -0,0 +1,156 @@
+package tracker
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+func newTestDB(t *testing.T) *DB {
+	db, err := OpenDB(testlog.Logger(t, log.LvlInfo), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+	return db
+}
+
+func TestDBCursor(t *testing.T) {
+	db := newTestDB(t)
+	_, err := db.Cursor(ChainL1)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	ref := eth.BlockRef{Hash: common.Hash{0x01}, Number: 5, ParentHash: common.Hash{0x02}, Time: 1000}
+	require.NoError(t, db.Update(ChainL1, ref, nil, nil))
+	got, err := db.Cursor(ChainL1)
+	require.NoError(t, err)
+	require.Equal(t, ref, got)
+
+	_, err = db.Cursor(ChainL2)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDBRevert(t *testing.T) {
+	db := newTestDB(t)
+	dep := &Deposit{
+		L2TxHash:  common.Hash{0xd2},
+		Initiated: Inclusion{TxHash: common.Hash{0xd1}, BlockNumber: 5, Timestamp: 1000},
+		From:      alice,
+		To:        &bob,
+		Data:      hexutil.Bytes{},
+	}
+	l1Block5 := eth.BlockRef{Hash: common.Hash{0x05}, Number: 5}
+	l1Block6 := eth.BlockRef{Hash: common.Hash{0x06}, Number: 6, ParentHash: l1Block5.Hash}
+	l2Block50 := eth.BlockRef{Hash: common.Hash{0x50}, Number: 50}
+	require.NoError(t, db.Update(ChainL1, l1Block5, []*Deposit{dep}, nil))
+	executed := *dep
+	executed.Executed = &Execution{Inclusion: Inclusion{TxHash: dep.L2TxHash, BlockNumber: 50, Timestamp: 1002}, Success: true}
+	require.NoError(t, db.Update(ChainL2, l2Block50, []*Deposit{&executed}, nil))
+	other := &Deposit{L2TxHash: common.Hash{0xe2}, Initiated: Inclusion{TxHash: common.Hash{0xe1}, BlockNumber: 6, Timestamp: 1012}, From: bob}
+	require.NoError(t, db.Update(ChainL1, l1Block6, []*Deposit{other}, nil))
+
+	// Reverting L1 block 6 keeps the L2 block.
+	require.NoError(t, db.Revert(ChainL1))
+	cursor, err := db.Cursor(ChainL1)
+	require.NoError(t, err)
+	require.Equal(t, l1Block5, cursor)
+	_, err = db.Deposit(other.L2TxHash)
+	require.ErrorIs(t, err, ErrNotFound)
+	deposits, _, err := db.ByTxHash(common.Hash{0xe1})
+	require.NoError(t, err)
+	require.Empty(t, deposits)
+	got, err := db.Deposit(dep.L2TxHash)
+	require.NoError(t, err)
+	require.Equal(t, &executed, got)
+
+	// Reverting L1 block 5 reverts the later L2 block too.
+	require.NoError(t, db.Revert(ChainL1))
+	_, err = db.Cursor(ChainL1)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = db.Cursor(ChainL2)
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = db.Deposit(dep.L2TxHash)
+	require.ErrorIs(t, err, ErrNotFound)
+	deposits, _, err = db.ByAddress(alice)
+	require.NoError(t, err)
+	require.Empty(t, deposits)
+
+	require.ErrorIs(t, db.Revert(ChainL1), ErrNotFound)
+}
+
+func TestDBRecords(t *testing.T) {
+	db := newTestDB(t)
+	dep := &Deposit{
+		L2TxHash:  common.Hash{0xd2},
+		Initiated: Inclusion{TxHash: common.Hash{0xd1}, BlockNumber: 5, Timestamp: 1000},
+		From:      alice,
+		To:        &bob,
+		Mint:      (*hexutil.Big)(big.NewInt(10)),
+		Value:     (*hexutil.Big)(big.NewInt(10)),
+		Data:      hexutil.Bytes{},
+		Transfers: []Transfer{{Asset: AssetNativeToken, From: alice, To: bob, Amount: (*hexutil.Big)(big.NewInt(10))}},
+	}
+	// A withdrawal of alice to bob that is only seen finalized so far, so has no addresses yet.
+	w := &Withdrawal{
+		Hash:      common.Hash{0x77},
+		Finalized: &Finalization{Inclusion: Inclusion{TxHash: common.Hash{0xf1}, BlockNumber: 6, Timestamp: 1012}, Success: true},
+	}
+	require.NoError(t, db.Update(ChainL1, eth.BlockRef{Number: 6}, []*Deposit{dep}, []*Withdrawal{w}))
+
+	got, err := db.Deposit(dep.L2TxHash)
+	require.NoError(t, err)
+	require.Equal(t, dep, got)
+	_, err = db.Deposit(common.Hash{0x99})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	for _, h := range []common.Hash{dep.Initiated.TxHash, dep.L2TxHash} {
+		deposits, withdrawals, err := db.ByTxHash(h)
+		require.NoError(t, err)
+		require.Equal(t, []*Deposit{dep}, deposits)
+		require.Empty(t, withdrawals)
+	}
+	deposits, withdrawals, err := db.ByTxHash(common.Hash{0xf1})
+	require.NoError(t, err)
+	require.Empty(t, deposits)
+	require.Equal(t, []*Withdrawal{w}, withdrawals)
+
+	deposits, withdrawals, err = db.ByAddress(alice)
+	require.NoError(t, err)
+	require.Equal(t, []*Deposit{dep}, deposits)
+	require.Empty(t, withdrawals)
+
+	// The withdrawal is initiated on L2 later, the updated record is found by its addresses too.
+	w = &Withdrawal{
+		Hash:      w.Hash,
+		Initiated: &Inclusion{TxHash: common.Hash{0xe1}, BlockNumber: 50, Timestamp: 900},
+		Nonce:     (*hexutil.Big)(big.NewInt(1)),
+		Sender:    alice,
+		Target:    bob,
+		Value:     (*hexutil.Big)(big.NewInt(1)),
+		GasLimit:  (*hexutil.Big)(big.NewInt(100_000)),
+		Finalized: w.Finalized,
+	}
+	require.NoError(t, db.Update(ChainL2, eth.BlockRef{Number: 50}, nil, []*Withdrawal{w}))
+	deposits, withdrawals, err = db.ByAddress(bob)
+	require.NoError(t, err)
+	require.Equal(t, []*Deposit{dep}, deposits)
+	require.Equal(t, []*Withdrawal{w}, withdrawals)
+	for _, h := range []common.Hash{{0xe1}, {0xf1}} {
+		_, withdrawals, err := db.ByTxHash(h)
+		require.NoError(t, err)
+		require.Equal(t, []*Withdrawal{w}, withdrawals)
+	}
+
+	deposits, withdrawals, err = db.ByAddress(common.Address{0x99})
+	require.NoError(t, err)
+	require.Empty(t, deposits)
+	require.Empty(t, withdrawals)
+}
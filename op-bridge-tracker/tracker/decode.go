@@ -0,0 +1,168 @@
+package tracker
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bindings/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/crossdomain"
+	nodebindings "github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+)
+
+var standardBridgeABI = func() *abi.ABI {
+	parsed, err := bindings.StandardBridgeMetaData.GetAbi()
+	if err != nil {
+		panic(fmt.Errorf("failed to parse StandardBridge ABI: %w", err))
+	}
+	return parsed
+}()
+
+// NewDeposit creates a Deposit from its TransactionDeposited event, emitted in an L1 block with the given timestamp.
+func NewDeposit(ev *types.Log, l1Time uint64) (*Deposit, error) {
+	dep, err := derive.UnmarshalDepositLogEvent(ev)
+	if err != nil {
+		return nil, err
+	}
+	mint := dep.Mint
+	if mint == nil {
+		mint = new(big.Int)
+	}
+	d := &Deposit{
+		L2TxHash: types.NewTx(dep).Hash(),
+		Initiated: Inclusion{
+			TxHash:      ev.TxHash,
+			BlockNumber: hexutil.Uint64(ev.BlockNumber),
+			Timestamp:   hexutil.Uint64(l1Time),
+		},
+		LogIndex:   hexutil.Uint(ev.Index),
+		From:       dep.From,
+		To:         dep.To,
+		IsCreation: dep.To == nil,
+		Mint:       (*hexutil.Big)(mint),
+		Value:      (*hexutil.Big)(dep.Value),
+		GasLimit:   hexutil.Uint64(dep.Gas),
+		Data:       dep.Data,
+	}
+	if dep.To != nil && *dep.To == predeploys.L2CrossDomainMessengerAddr {
+		// Deposits that cannot be decoded as a message are treated like any other deposit.
+		if msg, err := crossdomain.DecodeCrossDomainMessage(dep.Data); err == nil {
+			d.MessageSender = &msg.Sender
+			d.MessageTarget = &msg.Target
+			d.Transfers = messageTransfers(msg, true)
+			return d, nil
+		}
+	}
+	if mint.Sign() > 0 {
+		// The minted native token is credited to the sender on L2, the value is transferred from there.
+		to := dep.From
+		if dep.To != nil && dep.Value.Cmp(mint) == 0 {
+			to = *dep.To
+		}
+		d.Transfers = []Transfer{{Asset: AssetNativeToken, From: dep.From, To: to, Amount: (*hexutil.Big)(mint)}}
+	}
+	return d, nil
+}
+
+// NewWithdrawal creates a Withdrawal from its MessagePassed event, emitted in an L2 block with the given timestamp.
+func NewWithdrawal(ev *nodebindings.L2ToL1MessagePasserMessagePassed, l2Time uint64) *Withdrawal {
+	w := &Withdrawal{
+		Hash: ev.WithdrawalHash,
+		Initiated: &Inclusion{
+			TxHash:      ev.Raw.TxHash,
+			BlockNumber: hexutil.Uint64(ev.Raw.BlockNumber),
+			Timestamp:   hexutil.Uint64(l2Time),
+		},
+		Nonce:    (*hexutil.Big)(ev.Nonce),
+		Sender:   ev.Sender,
+		Target:   ev.Target,
+		Value:    (*hexutil.Big)(ev.Value),
+		GasLimit: (*hexutil.Big)(ev.GasLimit),
+		Data:     ev.Data,
+	}
+	if ev.Sender == predeploys.L2CrossDomainMessengerAddr {
+		if msg, err := crossdomain.DecodeCrossDomainMessage(ev.Data); err == nil {
+			w.MessageSender = &msg.Sender
+			w.MessageTarget = &msg.Target
+			w.Transfers = messageTransfers(msg, false)
+			return w
+		}
+	}
+	if ev.Value != nil && ev.Value.Sign() > 0 {
+		w.Transfers = []Transfer{{Asset: AssetNativeToken, From: ev.Sender, To: ev.Target, Amount: (*hexutil.Big)(ev.Value)}}
+	}
+	return w
+}
+
+// messageTransfers returns the transfers of a cross domain message. Messages between the standard bridges are
+// decoded as bridge transfers, the value of other messages is a transfer of the native token.
+func messageTransfers(msg *crossdomain.CrossDomainMessage, deposit bool) []Transfer {
+	// Deposits are relayed to the L2StandardBridge, withdrawals are sent by it.
+	l2Bridge := msg.Sender
+	if deposit {
+		l2Bridge = msg.Target
+	}
+	if l2Bridge == predeploys.L2StandardBridgeAddr {
+		if t, ok := bridgeTransfer(msg.Data, deposit); ok {
+			return []Transfer{t}
+		}
+	}
+	if msg.Value != nil && msg.Value.Sign() > 0 {
+		return []Transfer{{Asset: AssetNativeToken, From: msg.Sender, To: msg.Target, Amount: (*hexutil.Big)(msg.Value)}}
+	}
+	return nil
+}
+
+// bridgeTransfer decodes the transfer of a finalizeBridge call of the StandardBridge on the destination chain.
+func bridgeTransfer(data []byte, deposit bool) (Transfer, bool) {
+	if len(data) < 4 {
+		return Transfer{}, false
+	}
+	method, err := standardBridgeABI.MethodById(data[:4])
+	if err != nil {
+		return Transfer{}, false
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return Transfer{}, false
+	}
+	switch method.Name {
+	case "finalizeBridgeETH":
+		return Transfer{
+			Asset:  AssetETH,
+			From:   args[0].(common.Address),
+			To:     args[1].(common.Address),
+			Amount: (*hexutil.Big)(args[2].(*big.Int)),
+		}, true
+	case "finalizeBridgeNativeToken":
+		return Transfer{
+			Asset:  AssetNativeToken,
+			From:   args[0].(common.Address),
+			To:     args[1].(common.Address),
+			Amount: (*hexutil.Big)(args[2].(*big.Int)),
+		}, true
+	case "finalizeBridgeERC20":
+		// The local token is the token of the destination chain.
+		local, remote := args[0].(common.Address), args[1].(common.Address)
+		l1Token, l2Token := local, remote
+		if deposit {
+			l1Token, l2Token = remote, local
+		}
+		return Transfer{
+			Asset:   AssetERC20,
+			L1Token: &l1Token,
+			L2Token: &l2Token,
+			From:    args[2].(common.Address),
+			To:      args[3].(common.Address),
+			Amount:  (*hexutil.Big)(args[4].(*big.Int)),
+		}, true
+	default:
+		return Transfer{}, false
+	}
+}
@@ -0,0 +1,227 @@
+package tracker
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/crossdomain"
+	nodebindings "github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+)
+
+var (
+	testPortal = common.Address{0xaa}
+	alice      = common.Address{0x01}
+	bob        = common.Address{0x02}
+	l1Bridge   = common.Address{0xb1}
+	l1Token    = common.Address{0xc1}
+	l2Token    = common.Address{0xc2}
+)
+
+func depositLog(t *testing.T, dep *types.DepositTx, txHash common.Hash, block uint64, index uint) *types.Log {
+	l, err := derive.MarshalDepositLogEvent(testPortal, dep)
+	require.NoError(t, err)
+	l.TxHash = txHash
+	l.BlockNumber = block
+	l.BlockHash = common.Hash{byte(block)}
+	l.Index = index
+	return l
+}
+
+func relayMessage(t *testing.T, sender, target common.Address, value *big.Int, data []byte) []byte {
+	msg, err := crossdomain.EncodeCrossDomainMessageV1(crossdomain.EncodeVersionedNonce(big.NewInt(1), big.NewInt(1)),
+		sender, target, value, big.NewInt(200_000), data)
+	require.NoError(t, err)
+	return msg
+}
+
+func bridgeCall(t *testing.T, method string, args ...any) []byte {
+	data, err := standardBridgeABI.Pack(method, args...)
+	require.NoError(t, err)
+	return data
+}
+
+func TestNewDeposit(t *testing.T) {
+	t.Run("NativeToken", func(t *testing.T) {
+		dep := &types.DepositTx{From: alice, To: &bob, Mint: big.NewInt(100), Value: big.NewInt(100), Gas: 21_000}
+		d, err := NewDeposit(depositLog(t, dep, common.Hash{0x11}, 5, 3), 1000)
+		require.NoError(t, err)
+		require.Equal(t, common.Hash{0x11}, d.Initiated.TxHash)
+		require.Equal(t, hexutil.Uint64(5), d.Initiated.BlockNumber)
+		require.Equal(t, hexutil.Uint64(1000), d.Initiated.Timestamp)
+		require.Equal(t, hexutil.Uint(3), d.LogIndex)
+		require.Equal(t, &bob, d.To)
+		require.Equal(t, DepositPending, d.Status())
+		require.Equal(t, []Transfer{{Asset: AssetNativeToken, From: alice, To: bob, Amount: (*hexutil.Big)(big.NewInt(100))}}, d.Transfers)
+
+		// The L2 transaction hash is the hash of the deposit transaction as derived from the log.
+		derived, err := derive.UnmarshalDepositLogEvent(depositLog(t, dep, common.Hash{0x11}, 5, 3))
+		require.NoError(t, err)
+		require.Equal(t, types.NewTx(derived).Hash(), d.L2TxHash)
+	})
+
+	t.Run("MintKeptBySender", func(t *testing.T) {
+		dep := &types.DepositTx{From: alice, To: &bob, Mint: big.NewInt(100), Value: big.NewInt(0), Gas: 21_000}
+		d, err := NewDeposit(depositLog(t, dep, common.Hash{0x11}, 5, 0), 1000)
+		require.NoError(t, err)
+		require.Equal(t, []Transfer{{Asset: AssetNativeToken, From: alice, To: alice, Amount: (*hexutil.Big)(big.NewInt(100))}}, d.Transfers)
+	})
+
+	t.Run("NoTransfer", func(t *testing.T) {
+		dep := &types.DepositTx{From: alice, Value: big.NewInt(0), Gas: 100_000, Data: []byte{0x60, 0x00}}
+		d, err := NewDeposit(depositLog(t, dep, common.Hash{0x11}, 5, 0), 1000)
+		require.NoError(t, err)
+		require.True(t, d.IsCreation)
+		require.Nil(t, d.To)
+		require.Empty(t, d.Transfers)
+	})
+
+	t.Run("BridgeETH", func(t *testing.T) {
+		data := relayMessage(t, l1Bridge, predeploys.L2StandardBridgeAddr, big.NewInt(0),
+			bridgeCall(t, "finalizeBridgeETH", alice, bob, big.NewInt(7), []byte{}))
+		to := predeploys.L2CrossDomainMessengerAddr
+		dep := &types.DepositTx{From: common.Address{0xaa}, To: &to, Value: big.NewInt(0), Gas: 300_000, Data: data}
+		d, err := NewDeposit(depositLog(t, dep, common.Hash{0x11}, 5, 0), 1000)
+		require.NoError(t, err)
+		require.Equal(t, &l1Bridge, d.MessageSender)
+		require.Equal(t, &predeploys.L2StandardBridgeAddr, d.MessageTarget)
+		require.Equal(t, []Transfer{{Asset: AssetETH, From: alice, To: bob, Amount: (*hexutil.Big)(big.NewInt(7))}}, d.Transfers)
+		require.Contains(t, d.Addresses(), alice)
+		require.Contains(t, d.Addresses(), bob)
+	})
+
+	t.Run("BridgeERC20", func(t *testing.T) {
+		data := relayMessage(t, l1Bridge, predeploys.L2StandardBridgeAddr, big.NewInt(0),
+			bridgeCall(t, "finalizeBridgeERC20", l2Token, l1Token, alice, bob, big.NewInt(9), []byte{}))
+		to := predeploys.L2CrossDomainMessengerAddr
+		dep := &types.DepositTx{From: common.Address{0xaa}, To: &to, Value: big.NewInt(0), Gas: 300_000, Data: data}
+		d, err := NewDeposit(depositLog(t, dep, common.Hash{0x11}, 5, 0), 1000)
+		require.NoError(t, err)
+		require.Equal(t, []Transfer{{
+			Asset:   AssetERC20,
+			L1Token: &l1Token,
+			L2Token: &l2Token,
+			From:    alice,
+			To:      bob,
+			Amount:  (*hexutil.Big)(big.NewInt(9)),
+		}}, d.Transfers)
+	})
+
+	t.Run("MessageValue", func(t *testing.T) {
+		data := relayMessage(t, alice, bob, big.NewInt(3), nil)
+		to := predeploys.L2CrossDomainMessengerAddr
+		dep := &types.DepositTx{From: common.Address{0xaa}, To: &to, Mint: big.NewInt(3), Value: big.NewInt(3), Gas: 300_000, Data: data}
+		d, err := NewDeposit(depositLog(t, dep, common.Hash{0x11}, 5, 0), 1000)
+		require.NoError(t, err)
+		require.Equal(t, []Transfer{{Asset: AssetNativeToken, From: alice, To: bob, Amount: (*hexutil.Big)(big.NewInt(3))}}, d.Transfers)
+	})
+}
+
+func TestNewWithdrawal(t *testing.T) {
+	newEvent := func(sender, target common.Address, value *big.Int, data []byte) *nodebindings.L2ToL1MessagePasserMessagePassed {
+		return &nodebindings.L2ToL1MessagePasserMessagePassed{
+			Nonce:          big.NewInt(1),
+			Sender:         sender,
+			Target:         target,
+			Value:          value,
+			GasLimit:       big.NewInt(100_000),
+			Data:           data,
+			WithdrawalHash: common.Hash{0x77},
+			Raw:            types.Log{TxHash: common.Hash{0x22}, BlockNumber: 9},
+		}
+	}
+
+	t.Run("NativeToken", func(t *testing.T) {
+		w := NewWithdrawal(newEvent(alice, bob, big.NewInt(5), nil), 2000)
+		require.Equal(t, common.Hash{0x77}, w.Hash)
+		require.Equal(t, &Inclusion{TxHash: common.Hash{0x22}, BlockNumber: 9, Timestamp: 2000}, w.Initiated)
+		require.Equal(t, []Transfer{{Asset: AssetNativeToken, From: alice, To: bob, Amount: (*hexutil.Big)(big.NewInt(5))}}, w.Transfers)
+		require.Equal(t, WithdrawalInitiated, w.Status(3000, 100))
+	})
+
+	t.Run("BridgeNativeToken", func(t *testing.T) {
+		data := relayMessage(t, predeploys.L2StandardBridgeAddr, l1Bridge, big.NewInt(4),
+			bridgeCall(t, "finalizeBridgeNativeToken", alice, bob, big.NewInt(4), []byte{}))
+		w := NewWithdrawal(newEvent(predeploys.L2CrossDomainMessengerAddr, common.Address{0xac}, big.NewInt(4), data), 2000)
+		require.Equal(t, &predeploys.L2StandardBridgeAddr, w.MessageSender)
+		require.Equal(t, &l1Bridge, w.MessageTarget)
+		require.Equal(t, []Transfer{{Asset: AssetNativeToken, From: alice, To: bob, Amount: (*hexutil.Big)(big.NewInt(4))}}, w.Transfers)
+	})
+
+	t.Run("BridgeERC20", func(t *testing.T) {
+		data := relayMessage(t, predeploys.L2StandardBridgeAddr, l1Bridge, big.NewInt(0),
+			bridgeCall(t, "finalizeBridgeERC20", l1Token, l2Token, alice, bob, big.NewInt(6), []byte{}))
+		w := NewWithdrawal(newEvent(predeploys.L2CrossDomainMessengerAddr, common.Address{0xac}, big.NewInt(0), data), 2000)
+		require.Equal(t, []Transfer{{
+			Asset:   AssetERC20,
+			L1Token: &l1Token,
+			L2Token: &l2Token,
+			From:    alice,
+			To:      bob,
+			Amount:  (*hexutil.Big)(big.NewInt(6)),
+		}}, w.Transfers)
+	})
+
+	t.Run("NotFromBridge", func(t *testing.T) {
+		// Only messages of the L2StandardBridge are decoded as bridge transfers.
+		data := relayMessage(t, alice, l1Bridge, big.NewInt(0),
+			bridgeCall(t, "finalizeBridgeETH", alice, bob, big.NewInt(4), []byte{}))
+		w := NewWithdrawal(newEvent(predeploys.L2CrossDomainMessengerAddr, common.Address{0xac}, big.NewInt(0), data), 2000)
+		require.Empty(t, w.Transfers)
+	})
+}
+
+func TestWithdrawalStatus(t *testing.T) {
+	w := &Withdrawal{Hash: common.Hash{0x01}}
+	require.Equal(t, WithdrawalInitiated, w.Status(1000, 100))
+
+	w.addProof(Inclusion{TxHash: common.Hash{0xa1}, Timestamp: 1000})
+	require.Equal(t, WithdrawalProven, w.Status(1099, 100))
+	require.Equal(t, WithdrawalFinalizable, w.Status(1100, 100))
+	require.Equal(t, uint64(1100), w.FinalizableAt(100))
+
+	// Proving again on an OptimismPortal replaces the proof, and restarts the finalization period.
+	w.addProof(Inclusion{TxHash: common.Hash{0xa2}, Timestamp: 1050})
+	require.Len(t, w.Proofs, 1)
+	require.Equal(t, WithdrawalProven, w.Status(1100, 100))
+	require.Equal(t, uint64(1150), w.FinalizableAt(100))
+
+	w.Finalized = &Finalization{Success: true}
+	require.Equal(t, WithdrawalFinalized, w.Status(1100, 100))
+	w.Finalized.Success = false
+	require.Equal(t, WithdrawalFailed, w.Status(1100, 100))
+}
+
+func TestWithdrawalProofSubmitters(t *testing.T) {
+	w := &Withdrawal{Hash: common.Hash{0x01}}
+	w.addProof(Inclusion{TxHash: common.Hash{0xa1}, Timestamp: 1000})
+	require.True(t, w.setProofSubmitter(common.Hash{0xa1}, alice))
+	w.addProof(Inclusion{TxHash: common.Hash{0xa2}, Timestamp: 1050})
+	require.True(t, w.setProofSubmitter(common.Hash{0xa2}, bob))
+	require.False(t, w.setProofSubmitter(common.Hash{0xa3}, bob))
+
+	// An OptimismPortal2 keeps the proof of every submitter.
+	require.Equal(t, []Proof{
+		{Inclusion: Inclusion{TxHash: common.Hash{0xa1}, Timestamp: 1000}, Submitter: &alice},
+		{Inclusion: Inclusion{TxHash: common.Hash{0xa2}, Timestamp: 1050}, Submitter: &bob},
+	}, w.Proofs)
+	require.Equal(t, uint64(1100), w.FinalizableAt(100))
+	require.Equal(t, w.Proofs[:1], w.maturedProofs(1100, 100))
+
+	// Proving again replaces the proof of the submitter only.
+	w.addProof(Inclusion{TxHash: common.Hash{0xa3}, Timestamp: 1200})
+	require.True(t, w.setProofSubmitter(common.Hash{0xa3}, alice))
+	require.Equal(t, []Proof{
+		{Inclusion: Inclusion{TxHash: common.Hash{0xa2}, Timestamp: 1050}, Submitter: &bob},
+		{Inclusion: Inclusion{TxHash: common.Hash{0xa3}, Timestamp: 1200}, Submitter: &alice},
+	}, w.Proofs)
+	require.Equal(t, uint64(1150), w.FinalizableAt(100))
+	require.Equal(t, []common.Hash{{0xa2}, {0xa3}}, w.TxHashes())
+}
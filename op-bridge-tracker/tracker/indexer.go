@@ -0,0 +1,385 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/metrics"
+	nodebindings "github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-node/withdrawals"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+)
+
+var ErrReorg = errors.New("reorg deeper than the journal")
+
+// withdrawalProvenExtension1Topic is the topic of the event of an OptimismPortal2 that follows a WithdrawalProven
+// event, with the submitter of the proof.
+var withdrawalProvenExtension1Topic = crypto.Keccak256Hash([]byte("WithdrawalProvenExtension1(bytes32,address)"))
+
+// BlockSource provides the blocks and receipts of a chain.
+type BlockSource interface {
+	InfoByLabel(ctx context.Context, label eth.BlockLabel) (eth.BlockInfo, error)
+	InfoByNumber(ctx context.Context, number uint64) (eth.BlockInfo, error)
+	FetchReceipts(ctx context.Context, blockHash common.Hash) (eth.BlockInfo, types.Receipts, error)
+}
+
+type IndexerConfig struct {
+	// Portal is the address of the OptimismPortal proxy contract.
+	Portal common.Address
+	// L1StartBlock and L2StartBlock are the first blocks to index, when the database has no progress yet.
+	L1StartBlock uint64
+	L2StartBlock uint64
+	// L1Confirmations and L2Confirmations are the number of blocks to stay behind the head of the chain.
+	L1Confirmations uint64
+	L2Confirmations uint64
+	// MaxBlocks is the maximum number of blocks of each chain to index per step.
+	MaxBlocks uint64
+}
+
+// Indexer indexes the deposits and withdrawals of the L1 and L2 blocks into the database.
+//
+// The L2 blocks are only indexed up to the timestamp of the last indexed L1 block. As the L1 origin of an L2 block
+// is never newer than the L2 block, deposits are always indexed on L1 before they are executed on L2.
+// Withdrawals can be proven before the indexer sees them initiated on L2, the records are merged when it does.
+//
+// When a block does not build on the last indexed block of its chain, the indexed blocks are reverted back to the
+// common ancestor with the canonical chain, and the blocks after it are indexed again.
+type Indexer struct {
+	log     log.Logger
+	metrics metrics.Metricer
+	db      *DB
+	l1      BlockSource
+	l2      BlockSource
+	cfg     IndexerConfig
+
+	portal *nodebindings.OptimismPortalFilterer
+	passer *nodebindings.L2ToL1MessagePasserFilterer
+
+	withdrawalProvenTopic    common.Hash
+	withdrawalFinalizedTopic common.Hash
+}
+
+func NewIndexer(logger log.Logger, m metrics.Metricer, db *DB, l1 BlockSource, l2 BlockSource, cfg IndexerConfig) (*Indexer, error) {
+	if cfg.MaxBlocks == 0 {
+		return nil, errors.New("max blocks must be greater than 0")
+	}
+	portal, err := nodebindings.NewOptimismPortalFilterer(cfg.Portal, nil)
+	if err != nil {
+		return nil, err
+	}
+	passer, err := nodebindings.NewL2ToL1MessagePasserFilterer(predeploys.L2ToL1MessagePasserAddr, nil)
+	if err != nil {
+		return nil, err
+	}
+	portalABI, err := nodebindings.OptimismPortalMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return &Indexer{
+		log:                      logger,
+		metrics:                  m,
+		db:                       db,
+		l1:                       l1,
+		l2:                       l2,
+		cfg:                      cfg,
+		portal:                   portal,
+		passer:                   passer,
+		withdrawalProvenTopic:    portalABI.Events["WithdrawalProven"].ID,
+		withdrawalFinalizedTopic: portalABI.Events["WithdrawalFinalized"].ID,
+	}, nil
+}
+
+// Step indexes up to MaxBlocks blocks of each chain.
+// It returns true if there are more blocks to index already, without waiting for new blocks.
+func (ix *Indexer) Step(ctx context.Context) (bool, error) {
+	l1More, err := ix.indexChain(ctx, ChainL1, ix.l1, ix.cfg.L1StartBlock, ix.cfg.L1Confirmations, math.MaxUint64, ix.indexL1Block)
+	if err != nil {
+		return false, fmt.Errorf("failed to index L1: %w", err)
+	}
+	l1Cursor, err := ix.db.Cursor(ChainL1)
+	if errors.Is(err, ErrNotFound) {
+		// No L1 block is indexed yet, so no L2 block can be indexed either.
+		return l1More, nil
+	} else if err != nil {
+		return false, err
+	}
+	l2More, err := ix.indexChain(ctx, ChainL2, ix.l2, ix.cfg.L2StartBlock, ix.cfg.L2Confirmations, l1Cursor.Time, ix.indexL2Block)
+	if err != nil {
+		return false, fmt.Errorf("failed to index L2: %w", err)
+	}
+	return l1More || l2More, nil
+}
+
+// indexChain indexes the blocks after the cursor of the chain, up to the confirmation depth and the maximum time.
+func (ix *Indexer) indexChain(ctx context.Context, chain Chain, src BlockSource, start uint64, confirmations uint64, maxTime uint64,
+	process func(ref eth.BlockRef, receipts types.Receipts) error) (bool, error) {
+	cursor, err := ix.db.Cursor(chain)
+	hasCursor := err == nil
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+	next := start
+	if hasCursor {
+		next = cursor.Number + 1
+	}
+	head, err := src.InfoByLabel(ctx, eth.Unsafe)
+	if err != nil {
+		return false, fmt.Errorf("failed to get head: %w", err)
+	}
+	if head.NumberU64() < confirmations || head.NumberU64()-confirmations < next {
+		return false, nil
+	}
+	target := head.NumberU64() - confirmations
+	end := min(target, next+ix.cfg.MaxBlocks-1)
+	for num := next; num <= end; num++ {
+		info, err := src.InfoByNumber(ctx, num)
+		if err != nil {
+			return false, fmt.Errorf("failed to get block %d: %w", num, err)
+		}
+		if hasCursor && info.ParentHash() != cursor.Hash {
+			ix.log.Warn("Detected reorg", "chain", chain, "block", eth.InfoToL1BlockRef(info), "indexed", cursor)
+			ix.metrics.RecordReorg(chain.String())
+			if err := ix.rewind(ctx, chain, src); err != nil {
+				return false, fmt.Errorf("failed to rewind to common ancestor: %w", err)
+			}
+			return true, nil
+		}
+		if info.Time() > maxTime {
+			return false, nil
+		}
+		_, receipts, err := src.FetchReceipts(ctx, info.Hash())
+		if err != nil {
+			return false, fmt.Errorf("failed to get receipts of block %d: %w", num, err)
+		}
+		cursor = eth.InfoToL1BlockRef(info)
+		hasCursor = true
+		if err := process(cursor, receipts); err != nil {
+			return false, fmt.Errorf("failed to index block %s: %w", cursor, err)
+		}
+		ix.metrics.RecordIndexedBlock(chain.String(), cursor)
+	}
+	return end < target, nil
+}
+
+// rewind reverts the indexed blocks of the chain until the cursor is a block of the canonical chain again.
+// Blocks of the other chain that were indexed after a reverted block are reverted too, and are indexed again.
+func (ix *Indexer) rewind(ctx context.Context, chain Chain, src BlockSource) error {
+	for {
+		cursor, err := ix.db.Cursor(chain)
+		if errors.Is(err, ErrNotFound) {
+			// Reverted to before the start block.
+			return nil
+		} else if err != nil {
+			return err
+		}
+		info, err := src.InfoByNumber(ctx, cursor.Number)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("failed to get block %d: %w", cursor.Number, err)
+		}
+		if err == nil && info.Hash() == cursor.Hash {
+			ix.log.Info("Rewound to common ancestor", "chain", chain, "block", cursor)
+			return nil
+		}
+		if err := ix.db.Revert(chain); errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: indexed block %s", ErrReorg, cursor)
+		} else if err != nil {
+			return fmt.Errorf("failed to revert block %s: %w", cursor, err)
+		}
+	}
+}
+
+func (ix *Indexer) indexL1Block(ref eth.BlockRef, receipts types.Receipts) error {
+	u := newBlockUpdate(ix.db)
+	var deposits, proven, finalized int
+	for _, rec := range receipts {
+		for _, l := range rec.Logs {
+			if l.Address != ix.cfg.Portal || len(l.Topics) == 0 {
+				continue
+			}
+			inclusion := Inclusion{TxHash: l.TxHash, BlockNumber: hexutil.Uint64(ref.Number), Timestamp: hexutil.Uint64(ref.Time)}
+			switch l.Topics[0] {
+			case derive.DepositEventABIHash:
+				dep, err := NewDeposit(l, ref.Time)
+				if err != nil {
+					return fmt.Errorf("failed to decode deposit in tx %s: %w", l.TxHash, err)
+				}
+				existing, err := u.deposit(dep.L2TxHash)
+				if err != nil && !errors.Is(err, ErrNotFound) {
+					return err
+				}
+				if existing != nil {
+					// Only when the L1 blocks are indexed again.
+					dep.Executed = existing.Executed
+				}
+				u.putDeposit(dep)
+				deposits++
+			case ix.withdrawalProvenTopic:
+				ev, err := ix.portal.ParseWithdrawalProven(*l)
+				if err != nil {
+					return fmt.Errorf("failed to decode WithdrawalProven in tx %s: %w", l.TxHash, err)
+				}
+				w, err := u.withdrawalOrNew(ev.WithdrawalHash)
+				if err != nil {
+					return err
+				}
+				w.Sender = ev.From
+				w.Target = ev.To
+				w.addProof(inclusion)
+				u.putWithdrawal(w)
+				proven++
+			case withdrawalProvenExtension1Topic:
+				if len(l.Topics) != 3 {
+					return fmt.Errorf("invalid WithdrawalProvenExtension1 in tx %s", l.TxHash)
+				}
+				w, err := u.withdrawal(l.Topics[1])
+				if err != nil {
+					return fmt.Errorf("failed to get withdrawal %s proven in tx %s: %w", l.Topics[1], l.TxHash, err)
+				}
+				if !w.setProofSubmitter(l.TxHash, common.BytesToAddress(l.Topics[2][:])) {
+					return fmt.Errorf("no proof of withdrawal %s in tx %s", w.Hash, l.TxHash)
+				}
+				u.putWithdrawal(w)
+			case ix.withdrawalFinalizedTopic:
+				ev, err := ix.portal.ParseWithdrawalFinalized(*l)
+				if err != nil {
+					return fmt.Errorf("failed to decode WithdrawalFinalized in tx %s: %w", l.TxHash, err)
+				}
+				w, err := u.withdrawalOrNew(ev.WithdrawalHash)
+				if err != nil {
+					return err
+				}
+				w.Finalized = &Finalization{Inclusion: inclusion, Success: ev.Success}
+				u.putWithdrawal(w)
+				finalized++
+			}
+		}
+	}
+	if err := u.commit(ChainL1, ref); err != nil {
+		return err
+	}
+	ix.metrics.RecordDeposits(deposits)
+	ix.metrics.RecordWithdrawalEvents("proven", proven)
+	ix.metrics.RecordWithdrawalEvents("finalized", finalized)
+	if deposits+proven+finalized > 0 {
+		ix.log.Info("Indexed L1 block", "block", ref, "deposits", deposits, "proven", proven, "finalized", finalized)
+	}
+	return nil
+}
+
+func (ix *Indexer) indexL2Block(ref eth.BlockRef, receipts types.Receipts) error {
+	u := newBlockUpdate(ix.db)
+	var executed, initiated int
+	for _, rec := range receipts {
+		if rec.Type == types.DepositTxType {
+			// Deposits that are not found are the system deposits, and deposits from before the L1 start block.
+			dep, err := u.deposit(rec.TxHash)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			dep.Executed = &Execution{
+				Inclusion: Inclusion{TxHash: rec.TxHash, BlockNumber: hexutil.Uint64(ref.Number), Timestamp: hexutil.Uint64(ref.Time)},
+				Success:   rec.Status == types.ReceiptStatusSuccessful,
+			}
+			u.putDeposit(dep)
+			executed++
+		}
+		for _, l := range rec.Logs {
+			if l.Address != predeploys.L2ToL1MessagePasserAddr || len(l.Topics) == 0 || l.Topics[0] != withdrawals.MessagePassedTopic {
+				continue
+			}
+			ev, err := ix.passer.ParseMessagePassed(*l)
+			if err != nil {
+				return fmt.Errorf("failed to decode MessagePassed in tx %s: %w", l.TxHash, err)
+			}
+			w := NewWithdrawal(ev, ref.Time)
+			existing, err := u.withdrawal(w.Hash)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+			if existing != nil {
+				w.Proofs = existing.Proofs
+				w.Finalized = existing.Finalized
+			}
+			u.putWithdrawal(w)
+			initiated++
+		}
+	}
+	if err := u.commit(ChainL2, ref); err != nil {
+		return err
+	}
+	ix.metrics.RecordWithdrawalEvents("initiated", initiated)
+	if executed+initiated > 0 {
+		ix.log.Info("Indexed L2 block", "block", ref, "deposits", executed, "withdrawals", initiated)
+	}
+	return nil
+}
+
+// blockUpdate collects the records that change in a block, on top of the records in the database.
+type blockUpdate struct {
+	db          *DB
+	deposits    map[common.Hash]*Deposit
+	withdrawals map[common.Hash]*Withdrawal
+}
+
+func newBlockUpdate(db *DB) *blockUpdate {
+	return &blockUpdate{
+		db:          db,
+		deposits:    make(map[common.Hash]*Deposit),
+		withdrawals: make(map[common.Hash]*Withdrawal),
+	}
+}
+
+func (u *blockUpdate) deposit(l2TxHash common.Hash) (*Deposit, error) {
+	if dep, ok := u.deposits[l2TxHash]; ok {
+		return dep, nil
+	}
+	return u.db.Deposit(l2TxHash)
+}
+
+func (u *blockUpdate) putDeposit(dep *Deposit) {
+	u.deposits[dep.L2TxHash] = dep
+}
+
+func (u *blockUpdate) withdrawal(hash common.Hash) (*Withdrawal, error) {
+	if w, ok := u.withdrawals[hash]; ok {
+		return w, nil
+	}
+	return u.db.Withdrawal(hash)
+}
+
+func (u *blockUpdate) withdrawalOrNew(hash common.Hash) (*Withdrawal, error) {
+	w, err := u.withdrawal(hash)
+	if errors.Is(err, ErrNotFound) {
+		return &Withdrawal{Hash: hash}, nil
+	}
+	return w, err
+}
+
+func (u *blockUpdate) putWithdrawal(w *Withdrawal) {
+	u.withdrawals[w.Hash] = w
+}
+
+func (u *blockUpdate) commit(chain Chain, ref eth.BlockRef) error {
+	deposits := make([]*Deposit, 0, len(u.deposits))
+	for _, dep := range u.deposits {
+		deposits = append(deposits, dep)
+	}
+	withdrawals := make([]*Withdrawal, 0, len(u.withdrawals))
+	for _, w := range u.withdrawals {
+		withdrawals = append(withdrawals, w)
+	}
+	return u.db.Update(chain, ref, deposits, withdrawals)
+}
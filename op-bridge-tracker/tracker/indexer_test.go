@@ -0,0 +1,360 @@
+package tracker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/metrics"
+	nodebindings "github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testutils"
+)
+
+type fakeChain struct {
+	id        byte
+	startTime uint64
+	blockTime uint64
+	blocks    []*testutils.MockBlockInfo
+	receipts  map[common.Hash]types.Receipts
+}
+
+func newFakeChain(id byte, startTime uint64, blockTime uint64) *fakeChain {
+	return &fakeChain{id: id, startTime: startTime, blockTime: blockTime, receipts: make(map[common.Hash]types.Receipts)}
+}
+
+// addBlock adds a block with the receipts, and sets the block number of their logs.
+func (c *fakeChain) addBlock(receipts ...*types.Receipt) *testutils.MockBlockInfo {
+	num := uint64(len(c.blocks))
+	info := &testutils.MockBlockInfo{
+		InfoHash: common.Hash{c.id, byte(num)},
+		InfoNum:  num,
+		InfoTime: c.startTime + num*c.blockTime,
+	}
+	if num > 0 {
+		info.InfoParentHash = c.blocks[num-1].InfoHash
+	}
+	for _, rec := range receipts {
+		for _, l := range rec.Logs {
+			l.BlockNumber = num
+			l.BlockHash = info.InfoHash
+		}
+	}
+	c.blocks = append(c.blocks, info)
+	c.receipts[info.InfoHash] = receipts
+	return info
+}
+
+// reorg replaces the blocks from the given number on with the blocks that are added next.
+func (c *fakeChain) reorg(from uint64) {
+	c.blocks = c.blocks[:from]
+	c.id += 0x10
+}
+
+func (c *fakeChain) InfoByLabel(_ context.Context, _ eth.BlockLabel) (eth.BlockInfo, error) {
+	return c.blocks[len(c.blocks)-1], nil
+}
+
+func (c *fakeChain) InfoByNumber(_ context.Context, number uint64) (eth.BlockInfo, error) {
+	if number >= uint64(len(c.blocks)) {
+		return nil, ethereum.NotFound
+	}
+	return c.blocks[number], nil
+}
+
+func (c *fakeChain) FetchReceipts(_ context.Context, blockHash common.Hash) (eth.BlockInfo, types.Receipts, error) {
+	for _, info := range c.blocks {
+		if info.InfoHash == blockHash {
+			return info, c.receipts[blockHash], nil
+		}
+	}
+	return nil, nil, ethereum.NotFound
+}
+
+func portalLog(t *testing.T, event string, txHash common.Hash, topics []common.Hash, args ...any) *types.Log {
+	portalABI, err := nodebindings.OptimismPortalMetaData.GetAbi()
+	require.NoError(t, err)
+	data, err := portalABI.Events[event].Inputs.NonIndexed().Pack(args...)
+	require.NoError(t, err)
+	return &types.Log{
+		Address: testPortal,
+		Topics:  append([]common.Hash{portalABI.Events[event].ID}, topics...),
+		Data:    data,
+		TxHash:  txHash,
+	}
+}
+
+func messagePassedLog(t *testing.T, txHash common.Hash, ev *nodebindings.L2ToL1MessagePasserMessagePassed) *types.Log {
+	passerABI, err := nodebindings.L2ToL1MessagePasserMetaData.GetAbi()
+	require.NoError(t, err)
+	data, err := passerABI.Events["MessagePassed"].Inputs.NonIndexed().Pack(ev.Value, ev.GasLimit, ev.Data, ev.WithdrawalHash)
+	require.NoError(t, err)
+	return &types.Log{
+		Address: predeploys.L2ToL1MessagePasserAddr,
+		Topics: []common.Hash{
+			passerABI.Events["MessagePassed"].ID,
+			common.BigToHash(ev.Nonce),
+			common.BytesToHash(ev.Sender[:]),
+			common.BytesToHash(ev.Target[:]),
+		},
+		Data:   data,
+		TxHash: txHash,
+	}
+}
+
+func provenExtension1Log(txHash common.Hash, withdrawalHash common.Hash, submitter common.Address) *types.Log {
+	return &types.Log{
+		Address: testPortal,
+		Topics:  []common.Hash{withdrawalProvenExtension1Topic, withdrawalHash, common.BytesToHash(submitter[:])},
+		TxHash:  txHash,
+	}
+}
+
+type indexerTest struct {
+	l1      *fakeChain
+	l2      *fakeChain
+	db      *DB
+	indexer *Indexer
+}
+
+func newIndexerTest(t *testing.T) *indexerTest {
+	db := newTestDB(t)
+	l1 := newFakeChain(1, 1000, 12)
+	l2 := newFakeChain(2, 1000, 2)
+	indexer, err := NewIndexer(testlog.Logger(t, log.LvlInfo), metrics.NoopMetrics, db, l1, l2, IndexerConfig{
+		Portal:    testPortal,
+		MaxBlocks: 10,
+	})
+	require.NoError(t, err)
+	return &indexerTest{l1: l1, l2: l2, db: db, indexer: indexer}
+}
+
+// sync steps the indexer until it caught up.
+func (it *indexerTest) sync(t *testing.T) {
+	for more := true; more; {
+		var err error
+		more, err = it.indexer.Step(context.Background())
+		require.NoError(t, err)
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	it := newIndexerTest(t)
+
+	// L1: a deposit of alice to bob, and the proof of a withdrawal of alice to bob.
+	depLog := depositLog(t, &types.DepositTx{From: alice, To: &bob, Mint: big.NewInt(100), Value: big.NewInt(100), Gas: 21_000},
+		common.Hash{0x11}, 1, 0)
+	dep, err := NewDeposit(depLog, 1012)
+	require.NoError(t, err)
+	withdrawalHash := common.Hash{0x77}
+	it.l1.addBlock()
+	it.l1.addBlock(&types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{depLog}})
+	it.l1.addBlock(&types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{
+		portalLog(t, "WithdrawalProven", common.Hash{0x33}, []common.Hash{withdrawalHash, common.BytesToHash(alice[:]), common.BytesToHash(bob[:])}),
+	}})
+	it.l1.addBlock()
+
+	// L2: the execution of the deposit, and the initiation of the withdrawal.
+	ev := &nodebindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:          big.NewInt(1),
+		Sender:         alice,
+		Target:         bob,
+		Value:          big.NewInt(5),
+		GasLimit:       big.NewInt(100_000),
+		Data:           []byte{},
+		WithdrawalHash: withdrawalHash,
+	}
+	it.l2.addBlock()
+	it.l2.addBlock(
+		&types.Receipt{Type: types.DepositTxType, Status: types.ReceiptStatusSuccessful, TxHash: common.Hash{0x01}},
+		&types.Receipt{Type: types.DepositTxType, Status: types.ReceiptStatusSuccessful, TxHash: dep.L2TxHash},
+	)
+	for i := 0; i < 5; i++ {
+		it.l2.addBlock()
+	}
+	it.l2.addBlock(&types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: common.Hash{0x22}, Logs: []*types.Log{
+		messagePassedLog(t, common.Hash{0x22}, ev),
+	}})
+	for i := 0; i < 30; i++ {
+		it.l2.addBlock()
+	}
+
+	it.sync(t)
+
+	l1Cursor, err := it.db.Cursor(ChainL1)
+	require.NoError(t, err)
+	require.Equal(t, it.l1.blocks[3].InfoHash, l1Cursor.Hash)
+	// L2 is only indexed up to the time of the last indexed L1 block.
+	l2Cursor, err := it.db.Cursor(ChainL2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(18), l2Cursor.Number)
+	require.LessOrEqual(t, l2Cursor.Time, l1Cursor.Time)
+
+	gotDep, err := it.db.Deposit(dep.L2TxHash)
+	require.NoError(t, err)
+	require.Equal(t, DepositExecuted, gotDep.Status())
+	require.EqualValues(t, 1, gotDep.Executed.BlockNumber)
+	require.EqualValues(t, 1002, gotDep.Executed.Timestamp)
+
+	// The system deposit is not tracked.
+	_, err = it.db.Deposit(common.Hash{0x01})
+	require.ErrorIs(t, err, ErrNotFound)
+
+	w, err := it.db.Withdrawal(withdrawalHash)
+	require.NoError(t, err)
+	require.Equal(t, &Inclusion{TxHash: common.Hash{0x22}, BlockNumber: 7, Timestamp: 1014}, w.Initiated)
+	require.Equal(t, []Proof{{Inclusion: Inclusion{TxHash: common.Hash{0x33}, BlockNumber: 2, Timestamp: 1024}}}, w.Proofs)
+	require.Equal(t, []Transfer{{Asset: AssetNativeToken, From: alice, To: bob, Amount: w.Value}}, w.Transfers)
+
+	// Finalize the withdrawal.
+	it.l1.addBlock(&types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{
+		portalLog(t, "WithdrawalFinalized", common.Hash{0x44}, []common.Hash{withdrawalHash}, true),
+	}})
+	it.sync(t)
+	w, err = it.db.Withdrawal(withdrawalHash)
+	require.NoError(t, err)
+	require.Equal(t, &Finalization{Inclusion: Inclusion{TxHash: common.Hash{0x44}, BlockNumber: 4, Timestamp: 1048}, Success: true}, w.Finalized)
+	require.NotNil(t, w.Initiated, "must keep the initiation")
+	require.NotEmpty(t, w.Proofs, "must keep the proof")
+}
+
+func TestIndexerProofSubmitters(t *testing.T) {
+	it := newIndexerTest(t)
+	withdrawalHash := common.Hash{0x77}
+	prove := func(txHash common.Hash, submitter common.Address) *types.Receipt {
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{
+			portalLog(t, "WithdrawalProven", txHash, []common.Hash{withdrawalHash, common.BytesToHash(alice[:]), common.BytesToHash(bob[:])}),
+			provenExtension1Log(txHash, withdrawalHash, submitter),
+		}}
+	}
+
+	// Proven by alice and bob, and by alice again.
+	it.l1.addBlock()
+	it.l1.addBlock(prove(common.Hash{0x33}, alice))
+	it.l1.addBlock(prove(common.Hash{0x34}, bob))
+	it.l1.addBlock(prove(common.Hash{0x35}, alice))
+	it.l2.addBlock()
+	it.sync(t)
+
+	w, err := it.db.Withdrawal(withdrawalHash)
+	require.NoError(t, err)
+	require.Equal(t, []Proof{
+		{Inclusion: Inclusion{TxHash: common.Hash{0x34}, BlockNumber: 2, Timestamp: 1024}, Submitter: &bob},
+		{Inclusion: Inclusion{TxHash: common.Hash{0x35}, BlockNumber: 3, Timestamp: 1036}, Submitter: &alice},
+	}, w.Proofs)
+
+	// An extension event without a proof in the transaction is invalid.
+	it.l1.addBlock(&types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{
+		provenExtension1Log(common.Hash{0x36}, withdrawalHash, bob),
+	}})
+	_, err = it.indexer.Step(context.Background())
+	require.ErrorContains(t, err, "no proof of withdrawal")
+}
+
+func TestIndexerConfirmations(t *testing.T) {
+	it := newIndexerTest(t)
+	it.indexer.cfg.L1Confirmations = 2
+	it.l1.addBlock()
+	it.l1.addBlock()
+	more, err := it.indexer.Step(context.Background())
+	require.NoError(t, err)
+	require.False(t, more)
+	_, err = it.db.Cursor(ChainL1)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	it.l1.addBlock()
+	it.l2.addBlock()
+	it.sync(t)
+	l1Cursor, err := it.db.Cursor(ChainL1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), l1Cursor.Number)
+}
+
+func TestIndexerReorg(t *testing.T) {
+	it := newIndexerTest(t)
+	depTx := &types.DepositTx{From: alice, To: &bob, Mint: big.NewInt(100), Value: big.NewInt(100), Gas: 21_000}
+
+	// L1: a deposit in block 1, that is executed in L2 block 1.
+	it.l1.addBlock()
+	oldLog := depositLog(t, depTx, common.Hash{0x11}, 0, 0)
+	oldBlock := it.l1.addBlock(&types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{oldLog}})
+	it.l1.addBlock()
+	oldDep, err := NewDeposit(oldLog, oldBlock.InfoTime)
+	require.NoError(t, err)
+	it.l2.addBlock()
+	it.l2.addBlock(&types.Receipt{Type: types.DepositTxType, Status: types.ReceiptStatusSuccessful, TxHash: oldDep.L2TxHash})
+	for i := 0; i < 20; i++ {
+		it.l2.addBlock()
+	}
+	it.sync(t)
+	dep, err := it.db.Deposit(oldDep.L2TxHash)
+	require.NoError(t, err)
+	require.Equal(t, DepositExecuted, dep.Status())
+
+	// Reorg L1 below the deposit: the deposit is included in another transaction of block 2 instead.
+	it.l1.reorg(1)
+	it.l1.addBlock()
+	newLog := depositLog(t, depTx, common.Hash{0x12}, 0, 0)
+	newBlock := it.l1.addBlock(&types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{newLog}})
+	it.l1.addBlock()
+	newDep, err := NewDeposit(newLog, newBlock.InfoTime)
+	require.NoError(t, err)
+	it.sync(t)
+
+	l1Cursor, err := it.db.Cursor(ChainL1)
+	require.NoError(t, err)
+	require.Equal(t, it.l1.blocks[3].InfoHash, l1Cursor.Hash)
+	l2Cursor, err := it.db.Cursor(ChainL2)
+	require.NoError(t, err)
+	require.Equal(t, it.l2.blocks[l2Cursor.Number].InfoHash, l2Cursor.Hash)
+
+	// The deposit of the reorged block and its index entries are gone, the new deposit is indexed.
+	_, err = it.db.Deposit(oldDep.L2TxHash)
+	require.ErrorIs(t, err, ErrNotFound)
+	deposits, _, err := it.db.ByTxHash(common.Hash{0x11})
+	require.NoError(t, err)
+	require.Empty(t, deposits)
+	dep, err = it.db.Deposit(newDep.L2TxHash)
+	require.NoError(t, err)
+	require.Equal(t, DepositPending, dep.Status())
+	deposits, _, err = it.db.ByAddress(alice)
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+
+	// Reorg L2 below the execution of the new deposit.
+	it.l2.reorg(2)
+	it.l2.addBlock(&types.Receipt{Type: types.DepositTxType, Status: types.ReceiptStatusSuccessful, TxHash: newDep.L2TxHash})
+	for i := 0; i < 30; i++ {
+		it.l2.addBlock()
+	}
+	it.sync(t)
+	dep, err = it.db.Deposit(newDep.L2TxHash)
+	require.NoError(t, err)
+	require.Equal(t, DepositExecuted, dep.Status())
+	require.EqualValues(t, 2, dep.Executed.BlockNumber)
+}
+
+func TestIndexerReorgBeyondJournal(t *testing.T) {
+	it := newIndexerTest(t)
+	it.l1.addBlock()
+	it.l1.addBlock()
+	it.l2.addBlock()
+	it.sync(t)
+
+	// Drop the journal, as if the indexed blocks were pruned from it.
+	require.NoError(t, it.db.db.DeleteRange([]byte{keyPrefixJournal}, []byte{keyPrefixJournal + 1}, nil))
+	it.l1.reorg(1)
+	it.l1.addBlock()
+	it.l1.addBlock()
+	_, err := it.indexer.Step(context.Background())
+	require.ErrorIs(t, err, ErrReorg)
+}
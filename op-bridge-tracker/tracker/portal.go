@@ -0,0 +1,71 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	nodebindings "github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	bindingspreview "github.com/tokamak-network/tokamak-thanos/op-node/bindings/preview"
+)
+
+// Portal is the withdrawal configuration of the portal.
+type Portal struct {
+	// FinalizationPeriod is the number of seconds a withdrawal proof must mature before the withdrawal can be
+	// finalized.
+	FinalizationPeriod uint64
+	// Checker checks the dispute games of the proofs of an OptimismPortal2, it is nil for an OptimismPortal.
+	Checker WithdrawalChecker
+}
+
+// LoadPortal returns the withdrawal configuration of the portal. The finalization period is the proof maturity
+// delay of an OptimismPortal2, or the finalization period of the L2OutputOracle of an OptimismPortal.
+func LoadPortal(ctx context.Context, caller bind.ContractCaller, portalAddr common.Address) (*Portal, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	portal2, err := bindingspreview.NewOptimismPortal2Caller(portalAddr, caller)
+	if err != nil {
+		return nil, err
+	}
+	if delay, err := portal2.ProofMaturityDelaySeconds(opts); err == nil {
+		return &Portal{FinalizationPeriod: delay.Uint64(), Checker: &portal2Checker{portal: portal2}}, nil
+	}
+	portal, err := nodebindings.NewOptimismPortalCaller(portalAddr, caller)
+	if err != nil {
+		return nil, err
+	}
+	oracleAddr, err := portal.L2Oracle(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L2OutputOracle of portal %s: %w", portalAddr, err)
+	}
+	oracle, err := nodebindings.NewL2OutputOracleCaller(oracleAddr, caller)
+	if err != nil {
+		return nil, err
+	}
+	period, err := oracle.FinalizationPeriodSeconds(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get finalization period of L2OutputOracle %s: %w", oracleAddr, err)
+	}
+	return &Portal{FinalizationPeriod: period.Uint64()}, nil
+}
+
+// portal2Checker checks withdrawals with the checkWithdrawal method of an OptimismPortal2, which reverts unless the
+// proof matured and its dispute game resolved in favor of the proposal.
+type portal2Checker struct {
+	portal *bindingspreview.OptimismPortal2Caller
+}
+
+func (c *portal2Checker) CheckWithdrawal(ctx context.Context, hash common.Hash, submitter common.Address) (bool, error) {
+	err := c.portal.CheckWithdrawal(&bind.CallOpts{Context: ctx}, hash, submitter)
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		// Reverted.
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,285 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/config"
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-bridge-tracker/version"
+	"github.com/tokamak-network/tokamak-thanos/op-service/cliapp"
+	rpcclient "github.com/tokamak-network/tokamak-thanos/op-service/client"
+	"github.com/tokamak-network/tokamak-thanos/op-service/clock"
+	"github.com/tokamak-network/tokamak-thanos/op-service/dial"
+	"github.com/tokamak-network/tokamak-thanos/op-service/httputil"
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
+	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
+)
+
+func Main(ctx context.Context, logger log.Logger, cfg *config.Config) (cliapp.Lifecycle, error) {
+	if err := cfg.Check(); err != nil {
+		return nil, err
+	}
+	return NewService(ctx, logger, cfg)
+}
+
+type Service struct {
+	logger  log.Logger
+	metrics metrics.Metricer
+
+	l1RPC    *rpc.Client
+	l1Client *sources.L1Client
+	l2Client *sources.L1Client
+
+	db      *DB
+	indexer *Indexer
+
+	pollInterval time.Duration
+	cancel       context.CancelFunc
+	done         chan struct{}
+
+	rpcServer    *oprpc.Server
+	pprofService *oppprof.Service
+	metricsSrv   *httputil.HTTPServer
+
+	stopped atomic.Bool
+}
+
+// NewService creates a new Service.
+func NewService(ctx context.Context, logger log.Logger, cfg *config.Config) (*Service, error) {
+	s := &Service{
+		logger:       logger,
+		metrics:      metrics.NewMetrics(),
+		pollInterval: cfg.PollInterval,
+		done:         make(chan struct{}),
+	}
+
+	if err := s.initFromConfig(ctx, cfg); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to init service: %w", err), s.Stop(ctx))
+	}
+
+	return s, nil
+}
+
+func (s *Service) initFromConfig(ctx context.Context, cfg *config.Config) error {
+	if err := s.initClients(ctx, cfg); err != nil {
+		return fmt.Errorf("failed to init clients: %w", err)
+	}
+	if err := s.initDB(cfg); err != nil {
+		return fmt.Errorf("failed to init database: %w", err)
+	}
+	if err := s.initIndexer(cfg); err != nil {
+		return fmt.Errorf("failed to init indexer: %w", err)
+	}
+	if err := s.initPProf(&cfg.PprofConfig); err != nil {
+		return fmt.Errorf("failed to init profiling: %w", err)
+	}
+	if err := s.initMetricsServer(&cfg.MetricsConfig); err != nil {
+		return fmt.Errorf("failed to init metrics server: %w", err)
+	}
+	if err := s.initRPCServer(ctx, cfg); err != nil {
+		return fmt.Errorf("failed to init rpc server: %w", err)
+	}
+
+	s.metrics.RecordInfo(version.SimpleWithMeta)
+	s.metrics.RecordUp()
+
+	return nil
+}
+
+func (s *Service) initClients(ctx context.Context, cfg *config.Config) error {
+	l1RPC, err := dial.DialRPCClientWithTimeout(ctx, 1*time.Minute, s.logger, cfg.L1EthRpc)
+	if err != nil {
+		return fmt.Errorf("failed to dial L1: %w", err)
+	}
+	s.l1RPC = l1RPC
+	// Receipts are fetched with the standard methods, so the indexer works with any RPC provider.
+	// The RPC is trusted to avoid needing to update op-bridge-tracker for L1 hard forks that change the header,
+	// the receipts are still verified against the receipts root of the block.
+	s.l1Client, err = sources.NewL1Client(rpcclient.NewBaseRPCClient(l1RPC), s.logger, s.metrics,
+		sources.L1ClientSimpleConfig(true, sources.RPCKindStandard, int(cfg.MaxBlocks)))
+	if err != nil {
+		return fmt.Errorf("failed to init L1 client: %w", err)
+	}
+	l2RPC, err := dial.DialRPCClientWithTimeout(ctx, 1*time.Minute, s.logger, cfg.L2EthRpc)
+	if err != nil {
+		return fmt.Errorf("failed to dial L2: %w", err)
+	}
+	s.l2Client, err = sources.NewL1Client(rpcclient.NewBaseRPCClient(l2RPC), s.logger, s.metrics,
+		sources.L1ClientSimpleConfig(false, sources.RPCKindStandard, int(cfg.MaxBlocks)))
+	if err != nil {
+		return fmt.Errorf("failed to init L2 client: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) initDB(cfg *config.Config) error {
+	db, err := OpenDB(s.logger, cfg.Datadir)
+	if err != nil {
+		return err
+	}
+	s.db = db
+	return nil
+}
+
+func (s *Service) initIndexer(cfg *config.Config) error {
+	indexer, err := NewIndexer(s.logger, s.metrics, s.db, s.l1Client, s.l2Client, IndexerConfig{
+		Portal:          cfg.PortalAddress,
+		L1StartBlock:    cfg.L1StartBlock,
+		L2StartBlock:    cfg.L2StartBlock,
+		L1Confirmations: cfg.L1Confirmations,
+		L2Confirmations: cfg.L2Confirmations,
+		MaxBlocks:       cfg.MaxBlocks,
+	})
+	if err != nil {
+		return err
+	}
+	s.indexer = indexer
+	return nil
+}
+
+func (s *Service) initPProf(cfg *oppprof.CLIConfig) error {
+	s.pprofService = oppprof.New(
+		cfg.ListenEnabled,
+		cfg.ListenAddr,
+		cfg.ListenPort,
+		cfg.ProfileType,
+		cfg.ProfileDir,
+		cfg.ProfileFilename,
+	)
+
+	if err := s.pprofService.Start(); err != nil {
+		return fmt.Errorf("failed to start pprof service: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) initMetricsServer(cfg *opmetrics.CLIConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	s.logger.Debug("starting metrics server", "addr", cfg.ListenAddr, "port", cfg.ListenPort)
+	m, ok := s.metrics.(opmetrics.RegistryMetricer)
+	if !ok {
+		return fmt.Errorf("metrics were enabled, but metricer %T does not expose registry for metrics-server", s.metrics)
+	}
+	metricsSrv, err := opmetrics.StartServer(m.Registry(), cfg.ListenAddr, cfg.ListenPort)
+	if err != nil {
+		return fmt.Errorf("failed to start metrics server: %w", err)
+	}
+	s.logger.Info("started metrics server", "addr", metricsSrv.Addr())
+	s.metricsSrv = metricsSrv
+	return nil
+}
+
+func (s *Service) initRPCServer(ctx context.Context, cfg *config.Config) error {
+	portal, err := LoadPortal(ctx, ethclient.NewClient(s.l1RPC), cfg.PortalAddress)
+	if err != nil {
+		return fmt.Errorf("failed to load portal: %w", err)
+	}
+	s.logger.Info("Loaded portal", "portal", cfg.PortalAddress, "finalizationPeriod", portal.FinalizationPeriod,
+		"faultProofs", portal.Checker != nil)
+	server := oprpc.NewServer(
+		cfg.RPC.ListenAddr,
+		cfg.RPC.ListenPort,
+		cfg.Version,
+		oprpc.WithLogger(s.logger),
+	)
+	server.AddAPI(rpc.API{
+		Namespace: "tracker",
+		Service:   NewAPI(s.db, clock.SystemClock, portal.FinalizationPeriod, portal.Checker),
+	})
+	s.rpcServer = server
+	return nil
+}
+
+func (s *Service) Start(_ context.Context) error {
+	s.logger.Info("Starting JSON-RPC server")
+	if err := s.rpcServer.Start(); err != nil {
+		return fmt.Errorf("unable to start RPC server: %w", err)
+	}
+	s.logger.Info("Started JSON-RPC server", "endpoint", s.rpcServer.Endpoint())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go s.run(ctx)
+	s.logger.Info("Bridge tracker started")
+	return nil
+}
+
+// run indexes new blocks until the context is canceled. It only waits for the poll interval once the
+// indexer caught up with the chains, or after an error.
+func (s *Service) run(ctx context.Context) {
+	defer close(s.done)
+	for {
+		more, err := s.indexer.Step(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to index blocks", "err", err)
+		}
+		if more && err == nil && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.pollInterval):
+		}
+	}
+}
+
+func (s *Service) Stopped() bool {
+	return s.stopped.Load()
+}
+
+func (s *Service) Stop(ctx context.Context) error {
+	s.logger.Info("Stopping bridge tracker")
+
+	var result error
+	if s.cancel != nil {
+		s.cancel()
+		select {
+		case <-s.done:
+		case <-ctx.Done():
+			result = errors.Join(result, fmt.Errorf("failed to wait for indexer to stop: %w", ctx.Err()))
+		}
+	}
+	if s.rpcServer != nil {
+		if err := s.rpcServer.Stop(); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to stop RPC server: %w", err))
+		}
+	}
+	if s.db != nil {
+		if err := s.db.Close(); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to close database: %w", err))
+		}
+	}
+	if s.l1Client != nil {
+		s.l1Client.Close()
+	}
+	if s.l2Client != nil {
+		s.l2Client.Close()
+	}
+	if s.pprofService != nil {
+		if err := s.pprofService.Stop(ctx); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to close pprof server: %w", err))
+		}
+	}
+	if s.metricsSrv != nil {
+		if err := s.metricsSrv.Stop(ctx); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to close metrics server: %w", err))
+		}
+	}
+	s.stopped.Store(true)
+	s.logger.Info("Stopped bridge tracker", "err", result)
+	return result
+}
@@ -0,0 +1,253 @@
+package tracker
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Asset is the kind of asset of a Transfer.
+type Asset string
+
+const (
+	// AssetNativeToken is the native ERC-20 gas token of the L2 chain, the L1 token that is minted on L2 by deposits.
+	AssetNativeToken Asset = "native-token"
+	// AssetETH is ETH, bridged by the standard bridges.
+	AssetETH Asset = "eth"
+	// AssetERC20 is any other ERC-20 token, bridged by the standard bridges.
+	AssetERC20 Asset = "erc20"
+)
+
+// Transfer is an asset transfer of a deposit or withdrawal.
+type Transfer struct {
+	Asset Asset `json:"asset"`
+	// L1Token and L2Token are only set for ERC-20 transfers.
+	L1Token *common.Address `json:"l1Token,omitempty"`
+	L2Token *common.Address `json:"l2Token,omitempty"`
+	From    common.Address  `json:"from"`
+	To      common.Address  `json:"to"`
+	Amount  *hexutil.Big    `json:"amount"`
+}
+
+// Inclusion is the transaction and block a step of a deposit or withdrawal was included in.
+type Inclusion struct {
+	TxHash      common.Hash    `json:"txHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Timestamp   hexutil.Uint64 `json:"timestamp"`
+}
+
+// Proof is a proof of a withdrawal on L1.
+type Proof struct {
+	Inclusion
+	// Submitter is the account that proved the withdrawal on an OptimismPortal2, which keeps a proof per submitter.
+	// It is not set for proofs on an OptimismPortal, which only keeps the latest proof.
+	Submitter *common.Address `json:"submitter,omitempty"`
+}
+
+// Execution is the inclusion of a deposit on L2.
+type Execution struct {
+	Inclusion
+	Success bool `json:"success"`
+}
+
+// Finalization is the inclusion of a withdrawal finalization on L1.
+type Finalization struct {
+	Inclusion
+	Success bool `json:"success"`
+}
+
+type DepositStatus string
+
+const (
+	// DepositPending deposits are initiated on L1, but not executed on L2 yet.
+	DepositPending DepositStatus = "pending"
+	// DepositExecuted deposits were executed successfully on L2.
+	DepositExecuted DepositStatus = "executed"
+	// DepositFailed deposits reverted on L2. The minted native token is still credited to the sender.
+	DepositFailed DepositStatus = "failed"
+)
+
+// Deposit is a deposit transaction, as emitted by the TransactionDeposited event of the OptimismPortal.
+type Deposit struct {
+	L2TxHash   common.Hash     `json:"l2TxHash"`
+	Initiated  Inclusion       `json:"initiated"`
+	LogIndex   hexutil.Uint    `json:"logIndex"`
+	From       common.Address  `json:"from"`
+	To         *common.Address `json:"to"` // nil for contract creations
+	IsCreation bool            `json:"isCreation"`
+	Mint       *hexutil.Big    `json:"mint"`
+	Value      *hexutil.Big    `json:"value"`
+	GasLimit   hexutil.Uint64  `json:"gasLimit"`
+	Data       hexutil.Bytes   `json:"data"`
+	// MessageSender and MessageTarget are set for deposits of the L1CrossDomainMessenger,
+	// they are the sender and target of the relayed message.
+	MessageSender *common.Address `json:"messageSender,omitempty"`
+	MessageTarget *common.Address `json:"messageTarget,omitempty"`
+	Transfers     []Transfer      `json:"transfers"`
+	Executed      *Execution      `json:"executed,omitempty"`
+}
+
+// Status returns the status of the deposit.
+func (d *Deposit) Status() DepositStatus {
+	switch {
+	case d.Executed == nil:
+		return DepositPending
+	case d.Executed.Success:
+		return DepositExecuted
+	default:
+		return DepositFailed
+	}
+}
+
+// Addresses returns the addresses the deposit is found by.
+func (d *Deposit) Addresses() []common.Address {
+	addrs := []common.Address{d.From}
+	if d.To != nil {
+		addrs = append(addrs, *d.To)
+	}
+	return append(addrs, messageAddresses(d.MessageSender, d.MessageTarget, d.Transfers)...)
+}
+
+// TxHashes returns the hashes of the transactions the deposit is found by.
+func (d *Deposit) TxHashes() []common.Hash {
+	return []common.Hash{d.Initiated.TxHash, d.L2TxHash}
+}
+
+type WithdrawalStatus string
+
+const (
+	// WithdrawalInitiated withdrawals are initiated on L2, but not proven on L1 yet.
+	WithdrawalInitiated WithdrawalStatus = "initiated"
+	// WithdrawalProven withdrawals are proven on L1, and wait for the end of the finalization period.
+	WithdrawalProven WithdrawalStatus = "proven"
+	// WithdrawalFinalizable withdrawals are proven, and the finalization period of a proof ended.
+	// With fault proofs, the dispute game of the proof is also resolved in favor of the proposal.
+	WithdrawalFinalizable WithdrawalStatus = "finalizable"
+	// WithdrawalFinalized withdrawals were finalized on L1, and the withdrawal call succeeded.
+	WithdrawalFinalized WithdrawalStatus = "finalized"
+	// WithdrawalFailed withdrawals were finalized on L1, but the withdrawal call failed. They cannot be replayed.
+	WithdrawalFailed WithdrawalStatus = "failed"
+)
+
+// Withdrawal is a withdrawal transaction, as emitted by the MessagePassed event of the L2ToL1MessagePasser.
+// Withdrawals that are proven or finalized before they are initiated on L2, from the point of view of the indexer,
+// only have the fields of the WithdrawalProven and WithdrawalFinalized events set until then.
+type Withdrawal struct {
+	Hash      common.Hash    `json:"withdrawalHash"`
+	Initiated *Inclusion     `json:"initiated,omitempty"`
+	Nonce     *hexutil.Big   `json:"nonce,omitempty"`
+	Sender    common.Address `json:"sender"`
+	Target    common.Address `json:"target"`
+	Value     *hexutil.Big   `json:"value,omitempty"`
+	GasLimit  *hexutil.Big   `json:"gasLimit,omitempty"`
+	Data      hexutil.Bytes  `json:"data,omitempty"`
+	// MessageSender and MessageTarget are set for withdrawals of the L2CrossDomainMessenger,
+	// they are the sender and target of the relayed message.
+	MessageSender *common.Address `json:"messageSender,omitempty"`
+	MessageTarget *common.Address `json:"messageTarget,omitempty"`
+	Transfers     []Transfer      `json:"transfers,omitempty"`
+	// Proofs are the current proofs of the withdrawal: the latest proof on an OptimismPortal, or the latest proof of
+	// every submitter on an OptimismPortal2. The withdrawal can be finalized with any of them.
+	Proofs    []Proof       `json:"proofs,omitempty"`
+	Finalized *Finalization `json:"finalized,omitempty"`
+}
+
+// Status returns the status of the withdrawal at the given L1 time,
+// with the given duration of the finalization period in seconds.
+// It does not check the dispute games of the proofs, a withdrawal of an OptimismPortal2 may not be finalizable yet.
+func (w *Withdrawal) Status(now uint64, finalizationPeriod uint64) WithdrawalStatus {
+	switch {
+	case w.Finalized != nil && w.Finalized.Success:
+		return WithdrawalFinalized
+	case w.Finalized != nil:
+		return WithdrawalFailed
+	case len(w.Proofs) == 0:
+		return WithdrawalInitiated
+	case now >= w.FinalizableAt(finalizationPeriod):
+		return WithdrawalFinalizable
+	default:
+		return WithdrawalProven
+	}
+}
+
+// FinalizableAt returns the time the finalization period of the earliest proof ends. The withdrawal must be proven.
+// With fault proofs, the dispute game of the proof must also be resolved in favor of the proposal, so the
+// withdrawal may only be finalizable later.
+func (w *Withdrawal) FinalizableAt(finalizationPeriod uint64) uint64 {
+	return uint64(w.firstProof().Timestamp) + finalizationPeriod
+}
+
+// maturedProofs returns the proofs whose finalization period ended at the given L1 time.
+func (w *Withdrawal) maturedProofs(now uint64, finalizationPeriod uint64) []Proof {
+	var proofs []Proof
+	for _, p := range w.Proofs {
+		if now >= uint64(p.Timestamp)+finalizationPeriod {
+			proofs = append(proofs, p)
+		}
+	}
+	return proofs
+}
+
+func (w *Withdrawal) firstProof() Proof {
+	return slices.MinFunc(w.Proofs, func(x, y Proof) int {
+		return cmp.Compare(x.Timestamp, y.Timestamp)
+	})
+}
+
+// addProof adds the proof of a WithdrawalProven event. It replaces the previous proof of an OptimismPortal.
+// On an OptimismPortal2, the submitter of the proof is set by the WithdrawalProvenExtension1 event that follows.
+func (w *Withdrawal) addProof(inclusion Inclusion) {
+	w.Proofs = slices.DeleteFunc(w.Proofs, func(p Proof) bool { return p.Submitter == nil })
+	w.Proofs = append(w.Proofs, Proof{Inclusion: inclusion})
+}
+
+// setProofSubmitter sets the submitter of the proof of the transaction, from a WithdrawalProvenExtension1 event.
+// The proof replaces the previous proof of the submitter. It returns false if the transaction has no proof.
+func (w *Withdrawal) setProofSubmitter(txHash common.Hash, submitter common.Address) bool {
+	i := slices.IndexFunc(w.Proofs, func(p Proof) bool { return p.TxHash == txHash && p.Submitter == nil })
+	if i < 0 {
+		return false
+	}
+	w.Proofs[i].Submitter = &submitter
+	w.Proofs = slices.DeleteFunc(w.Proofs, func(p Proof) bool {
+		return p.Submitter != nil && *p.Submitter == submitter && p.TxHash != txHash
+	})
+	return true
+}
+
+// Addresses returns the addresses the withdrawal is found by.
+func (w *Withdrawal) Addresses() []common.Address {
+	addrs := []common.Address{w.Sender, w.Target}
+	return append(addrs, messageAddresses(w.MessageSender, w.MessageTarget, w.Transfers)...)
+}
+
+// TxHashes returns the hashes of the transactions the withdrawal is found by.
+func (w *Withdrawal) TxHashes() []common.Hash {
+	var hashes []common.Hash
+	if w.Initiated != nil {
+		hashes = append(hashes, w.Initiated.TxHash)
+	}
+	for _, p := range w.Proofs {
+		hashes = append(hashes, p.TxHash)
+	}
+	if w.Finalized != nil {
+		hashes = append(hashes, w.Finalized.TxHash)
+	}
+	return hashes
+}
+
+func messageAddresses(sender, target *common.Address, transfers []Transfer) []common.Address {
+	var addrs []common.Address
+	if sender != nil {
+		addrs = append(addrs, *sender)
+	}
+	if target != nil {
+		addrs = append(addrs, *target)
+	}
+	for _, t := range transfers {
+		addrs = append(addrs, t.From, t.To)
+	}
+	return addrs
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/clients"
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/nativetoken"
+	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
+	"github.com/tokamak-network/tokamak-thanos/op-service/cliapp"
+	"github.com/tokamak-network/tokamak-thanos/op-service/jsonutil"
+	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
+	"github.com/tokamak-network/tokamak-thanos/op-service/opio"
+)
+
+const envPrefix = "CHECK_NATIVE_TOKEN_SUPPLY"
+
+var (
+	l1EthRpcFlag = &cli.StringFlag{
+		Name:     "l1-eth-rpc",
+		Usage:    "HTTP provider URL for L1.",
+		EnvVars:  opservice.PrefixEnvVar(envPrefix, "L1_ETH_RPC"),
+		Required: true,
+	}
+	l2EthRpcFlag = &cli.StringFlag{
+		Name:     "l2-eth-rpc",
+		Usage:    "HTTP provider URL for L2. It must serve the state of the audited L2 blocks.",
+		EnvVars:  opservice.PrefixEnvVar(envPrefix, "L2_ETH_RPC"),
+		Required: true,
+	}
+	portalAddressFlag = &cli.StringFlag{
+		Name:     "portal-address",
+		Usage:    "Address of the OptimismPortal proxy contract.",
+		EnvVars:  opservice.PrefixEnvVar(envPrefix, "PORTAL_ADDRESS"),
+		Required: true,
+	}
+	l1StartBlockFlag = &cli.Uint64Flag{
+		Name:    "l1-start-block",
+		Usage:   "First L1 block to audit. The portal must not hold any native tokens before it, e.g. the deployment block of the portal.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "L1_START_BLOCK"),
+	}
+	l1BlockFlag = &cli.Uint64Flag{
+		Name:        "l1-block",
+		Usage:       "L1 block to audit the supply at.",
+		EnvVars:     opservice.PrefixEnvVar(envPrefix, "L1_BLOCK"),
+		DefaultText: "finalized",
+	}
+	l2BlockFlag = &cli.Uint64Flag{
+		Name:        "l2-block",
+		Usage:       "L2 block to audit the supply at. It should be derived from about the L1 block, withdrawals finalized on L1 must be initiated up to this block.",
+		EnvVars:     opservice.PrefixEnvVar(envPrefix, "L2_BLOCK"),
+		DefaultText: "finalized",
+	}
+	blockRangeFlag = &cli.Uint64Flag{
+		Name:    "block-range",
+		Usage:   "Maximum number of blocks to request the events of at once.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "BLOCK_RANGE"),
+		Value:   10_000,
+	}
+	loopIntervalFlag = &cli.DurationFlag{
+		Name:    "loop-interval",
+		Usage:   "Keep auditing the finalized L1 and L2 blocks at this interval, instead of auditing once. Discrepancies are logged and do not stop the audit.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "LOOP_INTERVAL"),
+	}
+	outFlag = &cli.StringFlag{
+		Name:    "out",
+		Usage:   "Path to write the JSON report to, '-' for stdout. The report is overwritten on every audit.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "OUT"),
+		Value:   "-",
+	}
+)
+
+func main() {
+	oplog.SetupDefaults()
+
+	app := cli.NewApp()
+	app.Name = "check-native-token-supply"
+	app.Usage = "Check that the native tokens locked in the OptimismPortal back the native token supply of L2."
+	app.Description = "Accounts the deposits and withdrawals of the native token through the OptimismPortal, and " +
+		"compares the portal balance with the L2 issuance, the pending withdrawals and the deposits in flight at a " +
+		"pair of L1 and L2 blocks. Discrepancies are reported with the L1 transactions that caused them."
+	app.Flags = cliapp.ProtectFlags(append([]cli.Flag{
+		l1EthRpcFlag,
+		l2EthRpcFlag,
+		portalAddressFlag,
+		l1StartBlockFlag,
+		l1BlockFlag,
+		l2BlockFlag,
+		blockRangeFlag,
+		loopIntervalFlag,
+		outFlag,
+	}, oplog.CLIFlags(envPrefix)...))
+	app.Writer = os.Stdout
+	app.ErrWriter = os.Stderr
+	app.Action = checkAction
+
+	if err := app.Run(os.Args); err != nil {
+		log.Crit("Application failed", "err", err)
+	}
+}
+
+func checkAction(cliCtx *cli.Context) error {
+	ctx := opio.CancelOnInterrupt(cliCtx.Context)
+	logger := oplog.NewLogger(os.Stderr, oplog.ReadCLIConfig(cliCtx))
+	interval := cliCtx.Duration(loopIntervalFlag.Name)
+	if interval > 0 && (cliCtx.IsSet(l1BlockFlag.Name) || cliCtx.IsSet(l2BlockFlag.Name)) {
+		return fmt.Errorf("--%s audits the finalized blocks, it cannot be combined with --%s or --%s",
+			loopIntervalFlag.Name, l1BlockFlag.Name, l2BlockFlag.Name)
+	}
+	portalAddr, err := opservice.ParseAddress(cliCtx.String(portalAddressFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid portal address: %w", err)
+	}
+
+	cl, err := clients.NewClients(cliCtx.String(l1EthRpcFlag.Name), cliCtx.String(l2EthRpcFlag.Name))
+	if err != nil {
+		return err
+	}
+	auditor, err := nativetoken.NewAuditor(ctx, logger, cl.L1Client, cl.L2Client, portalAddr,
+		cliCtx.Uint64(l1StartBlockFlag.Name), cliCtx.Uint64(blockRangeFlag.Name))
+	if err != nil {
+		return err
+	}
+	logger.Info("Loaded native token", "portal", portalAddr, "token", auditor.NativeToken())
+
+	audit := func() (*nativetoken.Report, error) {
+		l1Block, err := blockNumber(ctx, cl.L1Client, cliCtx, l1BlockFlag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get L1 block: %w", err)
+		}
+		l2Block, err := blockNumber(ctx, cl.L2Client, cliCtx, l2BlockFlag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get L2 block: %w", err)
+		}
+		r, err := auditor.Update(ctx, l1Block, l2Block)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Audited native token supply", "l1Block", r.L1Block, "l2Block", r.L2Block,
+			"portalBalance", r.PortalBalance, "issuance", r.Issuance, "pending", r.Pending, "inFlight", r.InFlight,
+			"unaccounted", r.Unaccounted, "discrepancies", len(r.Discrepancies))
+		return r, jsonutil.WriteJSON(cliCtx.String(outFlag.Name), r, 0o644)
+	}
+
+	if interval == 0 {
+		r, err := audit()
+		if err != nil {
+			return err
+		}
+		if len(r.Discrepancies) > 0 {
+			return fmt.Errorf("found %d discrepancies, %v native tokens are unaccounted for", len(r.Discrepancies), r.Unaccounted)
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := audit(); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			logger.Error("Failed to audit native token supply", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// blockNumber returns the block number of the flag, or the finalized block if the flag is not set.
+func blockNumber(ctx context.Context, client *ethclient.Client, cliCtx *cli.Context, flag *cli.Uint64Flag) (uint64, error) {
+	if cliCtx.IsSet(flag.Name) {
+		return cliCtx.Uint64(flag.Name), nil
+	}
+	header, err := client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/genesis"
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/rollupcheck"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
+	"github.com/tokamak-network/tokamak-thanos/op-program/chainconfig"
+	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
+	"github.com/tokamak-network/tokamak-thanos/op-service/cliapp"
+	"github.com/tokamak-network/tokamak-thanos/op-service/dial"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/jsonutil"
+	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
+	"github.com/tokamak-network/tokamak-thanos/op-service/opio"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources/batching"
+)
+
+const envPrefix = "CHECK_ROLLUP_CONFIG"
+
+const (
+	sourceOpProgram = "op-program"
+	sourceOpNode    = "op-node"
+	sourceGenesis   = "L2 genesis"
+)
+
+var (
+	rollupConfigFlag = &cli.PathFlag{
+		Name:     "rollup-config",
+		Usage:    "Path to the rollup.json generated by tokamak-deployer. The other sources are checked against it.",
+		EnvVars:  opservice.PrefixEnvVar(envPrefix, "ROLLUP_CONFIG"),
+		Required: true,
+	}
+	l2GenesisFlag = &cli.PathFlag{
+		Name:    "l2-genesis",
+		Usage:   "Path to the L2 genesis.json generated by tokamak-deployer.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "L2_GENESIS"),
+	}
+	deployConfigFlag = &cli.PathFlag{
+		Name:    "deploy-config",
+		Usage:   "Path to the deploy config, to check the native token and the fault proof absolute prestate against L1.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "DEPLOY_CONFIG"),
+	}
+	prestateFlag = &cli.StringFlag{
+		Name:    "prestate",
+		Usage:   "Absolute prestate of op-program to check the respected dispute game against. Overrides the prestate of the deploy config.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "PRESTATE"),
+	}
+	skipOpProgramFlag = &cli.BoolFlag{
+		Name:    "skip-op-program",
+		Usage:   "Do not check the config embedded into op-program, e.g. for a chain that is not embedded yet.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "SKIP_OP_PROGRAM"),
+	}
+	opNodeRpcFlag = &cli.StringFlag{
+		Name:    "op-node-rpc",
+		Usage:   "RPC URL of an op-node, to check its loaded rollup config.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "OP_NODE_RPC"),
+	}
+	l1EthRpcFlag = &cli.StringFlag{
+		Name:    "l1-eth-rpc",
+		Usage:   "HTTP provider URL for L1, to check the L1 genesis block and the SystemConfig contract. It must serve the state of the L1 genesis block.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "L1_ETH_RPC"),
+	}
+	l2EthRpcFlag = &cli.StringFlag{
+		Name:    "l2-eth-rpc",
+		Usage:   "HTTP provider URL for L2, to check the L2 genesis block.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "L2_ETH_RPC"),
+	}
+	outFlag = &cli.StringFlag{
+		Name:    "out",
+		Usage:   "Path to write the JSON list of differences to, '-' for stdout.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "OUT"),
+		Value:   "-",
+	}
+)
+
+func main() {
+	oplog.SetupDefaults()
+
+	app := cli.NewApp()
+	app.Name = "check-rollup-config"
+	app.Usage = "Check that the rollup configs of op-node, op-program and tokamak-deployer match the L2 genesis and L1."
+	app.Description = "Compares the rollup.json generated by tokamak-deployer with the config embedded into op-program, " +
+		"the config loaded by op-node, the L2 genesis and the L1 SystemConfig contract. Every difference is reported " +
+		"with the expected value."
+	app.Flags = cliapp.ProtectFlags(append([]cli.Flag{
+		rollupConfigFlag,
+		l2GenesisFlag,
+		deployConfigFlag,
+		prestateFlag,
+		skipOpProgramFlag,
+		opNodeRpcFlag,
+		l1EthRpcFlag,
+		l2EthRpcFlag,
+		outFlag,
+	}, oplog.CLIFlags(envPrefix)...))
+	app.Writer = os.Stdout
+	app.ErrWriter = os.Stderr
+	app.Action = checkAction
+
+	if err := app.Run(os.Args); err != nil {
+		log.Crit("Application failed", "err", err)
+	}
+}
+
+func checkAction(cliCtx *cli.Context) error {
+	ctx := opio.CancelOnInterrupt(cliCtx.Context)
+	logger := oplog.NewLogger(os.Stderr, oplog.ReadCLIConfig(cliCtx))
+
+	cfg, err := loadRollupConfig(cliCtx.Path(rollupConfigFlag.Name))
+	if err != nil {
+		return err
+	}
+	exp, err := expectations(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	diffs := []rollupcheck.Difference{}
+	add := func(source string, d []rollupcheck.Difference) {
+		logger.Info("Checked rollup config", "source", source, "differences", len(d))
+		diffs = append(diffs, d...)
+	}
+
+	if !cliCtx.Bool(skipOpProgramFlag.Name) {
+		chainID := eth.ChainIDFromBig(cfg.L2ChainID)
+		programCfg, err := chainconfig.RollupConfigByChainID(chainID)
+		if err != nil {
+			return fmt.Errorf("failed to load op-program rollup config: %w", err)
+		}
+		d, err := rollupcheck.CompareConfigs(cfg, programCfg, sourceOpProgram)
+		if err != nil {
+			return err
+		}
+		add(sourceOpProgram, d)
+		chainCfg, err := chainconfig.L2ChainConfigByChainID(chainID)
+		if err != nil {
+			return fmt.Errorf("failed to load op-program L2 chain config: %w", err)
+		}
+		add(sourceOpProgram, rollupcheck.CompareChainConfig(cfg, chainCfg, sourceOpProgram))
+	}
+
+	if path := cliCtx.Path(l2GenesisFlag.Name); path != "" {
+		l2Genesis, err := jsonutil.LoadJSON[core.Genesis](path)
+		if err != nil {
+			return fmt.Errorf("failed to load L2 genesis: %w", err)
+		}
+		add(sourceGenesis, rollupcheck.CompareGenesis(cfg, l2Genesis, sourceGenesis))
+	}
+
+	if url := cliCtx.String(opNodeRpcFlag.Name); url != "" {
+		rollupClient, err := dial.DialRollupClientWithTimeout(ctx, dial.DefaultDialTimeout, logger, url)
+		if err != nil {
+			return fmt.Errorf("failed to dial op-node: %w", err)
+		}
+		defer rollupClient.Close()
+		nodeCfg, err := rollupClient.RollupConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get op-node rollup config: %w", err)
+		}
+		d, err := rollupcheck.CompareConfigs(cfg, nodeCfg, sourceOpNode)
+		if err != nil {
+			return err
+		}
+		add(sourceOpNode, d)
+	}
+
+	if url := cliCtx.String(l1EthRpcFlag.Name); url != "" {
+		l1Client, err := dial.DialEthClientWithTimeout(ctx, dial.DefaultDialTimeout, logger, url)
+		if err != nil {
+			return fmt.Errorf("failed to dial L1: %w", err)
+		}
+		defer l1Client.Close()
+		caller := batching.NewMultiCaller(l1Client.Client(), batching.DefaultBatchSize)
+		d, err := rollupcheck.CheckL1(ctx, l1Client, caller, cfg, exp)
+		if err != nil {
+			return err
+		}
+		add(rollupcheck.SourceL1, d)
+	} else if exp.NativeToken != nil || exp.Prestate != nil {
+		logger.Warn("Not checking the native token and prestate without an L1 RPC", "flag", l1EthRpcFlag.Name)
+	}
+
+	if url := cliCtx.String(l2EthRpcFlag.Name); url != "" {
+		l2Client, err := dial.DialEthClientWithTimeout(ctx, dial.DefaultDialTimeout, logger, url)
+		if err != nil {
+			return fmt.Errorf("failed to dial L2: %w", err)
+		}
+		defer l2Client.Close()
+		d, err := rollupcheck.CheckL2(ctx, l2Client, cfg)
+		if err != nil {
+			return err
+		}
+		add(rollupcheck.SourceL2, d)
+	}
+
+	for _, d := range diffs {
+		logger.Error("Rollup config mismatch", "source", d.Source, "field", d.Field, "expected", d.Expected, "actual", d.Actual)
+	}
+	if err := jsonutil.WriteJSON(cliCtx.String(outFlag.Name), diffs, 0o644); err != nil {
+		return err
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("found %d differences", len(diffs))
+	}
+	logger.Info("Rollup configs match")
+	return nil
+}
+
+func loadRollupConfig(path string) (*rollup.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rollup config: %w", err)
+	}
+	defer f.Close()
+	var cfg rollup.Config
+	if err := cfg.ParseRollupConfig(f); err != nil {
+		return nil, fmt.Errorf("failed to parse rollup config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// expectations returns the native token and prestate that L1 is checked against, from the deploy config and the
+// prestate flag.
+func expectations(cliCtx *cli.Context) (rollupcheck.Expectations, error) {
+	var exp rollupcheck.Expectations
+	if path := cliCtx.Path(deployConfigFlag.Name); path != "" {
+		deployConfig, err := genesis.NewDeployConfig(path)
+		if err != nil {
+			return exp, fmt.Errorf("failed to load deploy config: %w", err)
+		}
+		nativeToken := deployConfig.NativeTokenAddress
+		exp.NativeToken = &nativeToken
+		if deployConfig.UseFaultProofs {
+			prestate := deployConfig.FaultGameAbsolutePrestate
+			exp.Prestate = &prestate
+		}
+	}
+	if cliCtx.IsSet(prestateFlag.Name) {
+		prestate, err := parseHash(cliCtx.String(prestateFlag.Name))
+		if err != nil {
+			return exp, fmt.Errorf("invalid prestate: %w", err)
+		}
+		exp.Prestate = &prestate
+	}
+	return exp, nil
+}
+
+func parseHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(b) != common.HashLength {
+		return common.Hash{}, errors.New("must be 32 bytes")
+	}
+	return common.BytesToHash(b), nil
+}
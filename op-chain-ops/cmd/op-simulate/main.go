@@ -32,6 +32,7 @@ import (
 
 	op_service "github.com/tokamak-network/tokamak-thanos/op-service"
 	"github.com/tokamak-network/tokamak-thanos/op-service/cliapp"
+	"github.com/tokamak-network/tokamak-thanos/op-service/jsonutil"
 	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
 	"github.com/tokamak-network/tokamak-thanos/op-service/opio"
 )
@@ -46,10 +47,39 @@ var (
 		Required: true,
 	}
 	TxFlag = &cli.StringFlag{
-		Name:     "tx",
-		Usage:    "Transaction hash to trace and simulate",
-		EnvVars:  op_service.PrefixEnvVar(EnvPrefix, "TX"),
-		Required: true,
+		Name:    "tx",
+		Usage:   "Transaction hash to trace and simulate. Mutually exclusive with --from and --to",
+		EnvVars: op_service.PrefixEnvVar(EnvPrefix, "TX"),
+	}
+	FromFlag = &cli.Uint64Flag{
+		Name:    "from",
+		Usage:   "First block of the block range to replay",
+		EnvVars: op_service.PrefixEnvVar(EnvPrefix, "FROM"),
+	}
+	ToFlag = &cli.Uint64Flag{
+		Name:    "to",
+		Usage:   "Last block (inclusive) of the block range to replay. Defaults to the --from block",
+		EnvVars: op_service.PrefixEnvVar(EnvPrefix, "TO"),
+	}
+	CodeOverrideFlag = &cli.StringSliceFlag{
+		Name:    "code-override",
+		Usage:   "Replace the code of an account in the prestate, formatted as <address>=<0x-prefixed code, or path to a file with hex code>",
+		EnvVars: op_service.PrefixEnvVar(EnvPrefix, "CODE_OVERRIDE"),
+	}
+	ChainConfigFlag = &cli.PathFlag{
+		Name:    "chain-config",
+		Usage:   "Path to a chain config JSON file to simulate with, instead of the chain config of the RPC",
+		EnvVars: op_service.PrefixEnvVar(EnvPrefix, "CHAIN_CONFIG"),
+	}
+	ForkOverrideFlag = &cli.StringSliceFlag{
+		Name:    "fork-override",
+		Usage:   "Override the activation time of a fork, formatted as <fork>=<timestamp>, e.g. ecotone=0",
+		EnvVars: op_service.PrefixEnvVar(EnvPrefix, "FORK_OVERRIDE"),
+	}
+	ReportFlag = &cli.PathFlag{
+		Name:    "report",
+		Usage:   "Path to write the JSON report of a block range replay to",
+		EnvVars: op_service.PrefixEnvVar(EnvPrefix, "REPORT"),
 	}
 	ProfFlag = &cli.BoolFlag{
 		Name:     "profile",
@@ -61,14 +91,15 @@ var (
 
 func main() {
 	flags := []cli.Flag{
-		RPCFlag, TxFlag, ProfFlag,
+		RPCFlag, TxFlag, ProfFlag, FromFlag, ToFlag, CodeOverrideFlag, ChainConfigFlag, ForkOverrideFlag, ReportFlag,
 	}
 	flags = append(flags, oplog.CLIFlags(EnvPrefix)...)
 
 	app := cli.NewApp()
 	app.Name = "op-simulate"
-	app.Usage = "Simulate a tx locally."
-	app.Description = "Fetch a tx from an RPC and simulate it locally."
+	app.Usage = "Simulate a tx or a block range locally."
+	app.Description = "Fetch a tx from an RPC and simulate it locally, or replay a block range " +
+		"and compare every tx with the canonical receipt and state changes, optionally with code or chain config overrides."
 	app.Flags = cliapp.ProtectFlags(flags)
 	app.Action = mainAction
 	app.Writer = os.Stdout
@@ -90,19 +121,66 @@ func mainAction(c *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to dial RPC %q: %w", endpoint, err)
 	}
+	chainConfig, err := fetchChainConfig(ctx, cl)
+	if err != nil {
+		return fmt.Errorf("failed to get chain config: %w", err)
+	}
+	if path := c.Path(ChainConfigFlag.Name); path != "" {
+		chainConfig, err = loadChainConfig(path)
+		if err != nil {
+			return err
+		}
+	}
+	chainConfig, err = applyForkOverrides(chainConfig, c.StringSlice(ForkOverrideFlag.Name))
+	if err != nil {
+		return err
+	}
+	code, err := parseCodeOverrides(c.StringSlice(CodeOverrideFlag.Name))
+	if err != nil {
+		return err
+	}
+	prestatesDir := "."
+
+	if c.IsSet(FromFlag.Name) {
+		if c.IsSet(TxFlag.Name) {
+			return fmt.Errorf("--%s cannot be combined with a block range", TxFlag.Name)
+		}
+		from := c.Uint64(FromFlag.Name)
+		to := from
+		if c.IsSet(ToFlag.Name) {
+			to = c.Uint64(ToFlag.Name)
+		}
+		report, err := replayRange(ctx, logger, cl, &ReplayConfig{
+			From:         from,
+			To:           to,
+			ChainConfig:  chainConfig,
+			Code:         code,
+			PrestatesDir: prestatesDir,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to replay blocks: %w", err)
+		}
+		logger.Info("Replayed block range", "from", from, "to", to,
+			"txs", report.Transactions, "mismatches", report.Mismatches)
+		if path := c.Path(ReportFlag.Name); path != "" {
+			if err := jsonutil.WriteJSON(path, report, 0o644); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
+		}
+		return nil
+	}
+
 	txHashStr := c.String(TxFlag.Name)
+	if txHashStr == "" {
+		return fmt.Errorf("either --%s or --%s is required", TxFlag.Name, FromFlag.Name)
+	}
 	var txHash common.Hash
 	if err := txHash.UnmarshalText([]byte(txHashStr)); err != nil {
 		return fmt.Errorf("invalid tx hash: %q", txHashStr)
 	}
-	prestatesDir := "."
 	if err := fetchPrestate(ctx, cl, prestatesDir, txHash); err != nil {
 		return fmt.Errorf("failed to prepare prestate: %w", err)
 	}
-	chainConfig, err := fetchChainConfig(ctx, cl)
-	if err != nil {
-		return fmt.Errorf("failed to get chain config: %w", err)
-	}
 	tx, err := fetchTx(ctx, cl, txHash)
 	if err != nil {
 		return fmt.Errorf("failed to get TX: %w", err)
@@ -116,7 +194,7 @@ func mainAction(c *cli.Context) error {
 		return fmt.Errorf("failed to get block header: %w", err)
 	}
 	doProfile := c.Bool(ProfFlag.Name)
-	if err := simulate(ctx, logger, chainConfig, prestateTraceFile(prestatesDir, txHash), tx, header, doProfile); err != nil {
+	if err := simulate(ctx, logger, chainConfig, prestateTraceFile(prestatesDir, txHash), code, tx, header, doProfile); err != nil {
 		return fmt.Errorf("failed to simulate tx: %w", err)
 	}
 	return nil
@@ -244,17 +322,14 @@ func (d *simChainContext) GetHeader(h common.Hash, n uint64) *types.Header {
 	panic(fmt.Errorf("header retrieval not supported, cannot fetch %s %d", h, n))
 }
 
-func simulate(ctx context.Context, logger log.Logger, conf *params.ChainConfig,
-	prestatePath string, tx *types.Transaction, header *types.Header, doProfile bool) error {
+// newPrestateDB creates an in-memory state with the accounts of the prestate dump,
+// committed as state of the given block number.
+func newPrestateDB(dump map[common.Address]DumpAccount, blockNum uint64) (*gstate.StateDB, error) {
 	memDB := rawdb.NewMemoryDatabase()
 	stateDB := gstate.NewDatabase(memDB)
 	state, err := gstate.New(types.EmptyRootHash, stateDB, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create in-memory state: %w", err)
-	}
-	dump, err := readDump(prestatePath)
-	if err != nil {
-		return fmt.Errorf("failed to read prestate: %w", err)
+		return nil, fmt.Errorf("failed to create in-memory state: %w", err)
 	}
 	for addr, acc := range dump {
 		state.CreateAccount(addr)
@@ -265,10 +340,24 @@ func simulate(ctx context.Context, logger log.Logger, conf *params.ChainConfig,
 	}
 
 	// load prestate data into memory db state
-	_, err = state.Commit(header.Number.Uint64()-1, true)
+	_, err = state.Commit(blockNum, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write state data to underlying DB: %w", err)
+	}
+	return state, nil
+}
+
+func simulate(ctx context.Context, logger log.Logger, conf *params.ChainConfig,
+	prestatePath string, code codeOverrides, tx *types.Transaction, header *types.Header, doProfile bool) error {
+	dump, err := readDump(prestatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read prestate: %w", err)
+	}
+	state, err := newPrestateDB(dump, header.Number.Uint64()-1)
 	if err != nil {
-		return fmt.Errorf("failed to write state data to underlying DB: %w", err)
+		return err
 	}
+	code.apply(state)
 
 	rules := conf.Rules(header.Number, true, header.Time)
 	signer := types.MakeSigner(conf, header.Number, header.Time)
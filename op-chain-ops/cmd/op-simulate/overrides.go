@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// codeOverrides replaces the code of accounts in the prestate, e.g. to try out a patched predeploy.
+type codeOverrides map[common.Address][]byte
+
+// parseCodeOverrides parses overrides in the format <address>=<code>,
+// where the code is 0x-prefixed hex, or the path to a file that contains the hex code.
+func parseCodeOverrides(args []string) (codeOverrides, error) {
+	out := make(codeOverrides)
+	for _, arg := range args {
+		addrStr, codeStr, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid code override %q, expected <address>=<code or file>", arg)
+		}
+		if !common.IsHexAddress(addrStr) {
+			return nil, fmt.Errorf("invalid code override address %q", addrStr)
+		}
+		if !strings.HasPrefix(codeStr, "0x") {
+			data, err := os.ReadFile(codeStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read code override file: %w", err)
+			}
+			codeStr = strings.TrimSpace(string(data))
+		}
+		code, err := hexutil.Decode(codeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid code override of %s: %w", addrStr, err)
+		}
+		out[common.HexToAddress(addrStr)] = code
+	}
+	return out, nil
+}
+
+// apply sets the overridden code in the state.
+func (o codeOverrides) apply(state *gstate.StateDB) {
+	for addr, code := range o {
+		if !state.Exist(addr) {
+			state.CreateAccount(addr)
+		}
+		state.SetCode(addr, code)
+	}
+}
+
+// loadChainConfig reads a chain config from a JSON file, in the same format as the eth_chainConfig RPC.
+func loadChainConfig(path string) (*params.ChainConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chain config: %w", err)
+	}
+	var config params.ChainConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode chain config: %w", err)
+	}
+	return &config, nil
+}
+
+// forkTimeKey returns the chain config JSON key of the activation time of the fork.
+func forkTimeKey(fork string) (string, error) {
+	if fork == "" {
+		return "", errors.New("empty fork name")
+	}
+	return strings.ToLower(fork[:1]) + fork[1:] + "Time", nil
+}
+
+// applyForkOverrides changes the activation time of forks in the chain config.
+// Overrides are in the format <fork>=<timestamp>, with the fork name as in the chain config JSON,
+// without the "Time" suffix: e.g. "ecotone=0" sets the "ecotoneTime" to 0.
+func applyForkOverrides(conf *params.ChainConfig, args []string) (*params.ChainConfig, error) {
+	if len(args) == 0 {
+		return conf, nil
+	}
+	fields, err := chainConfigFields(conf)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(args))
+	for _, arg := range args {
+		fork, timeStr, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fork override %q, expected <fork>=<timestamp>", arg)
+		}
+		key, err := forkTimeKey(fork)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fork override %q: %w", arg, err)
+		}
+		t, err := strconv.ParseUint(timeStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fork override time %q: %w", timeStr, err)
+		}
+		fields[key] = t
+		keys = append(keys, key)
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chain config: %w", err)
+	}
+	var out params.ChainConfig
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode overridden chain config: %w", err)
+	}
+	// unknown keys are silently dropped when decoding, check that every override was applied
+	applied, err := chainConfigFields(&out)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if _, ok := applied[key]; !ok {
+			return nil, fmt.Errorf("unknown fork activation %q", key)
+		}
+	}
+	return &out, nil
+}
+
+// chainConfigFields returns the chain config as generic JSON object.
+func chainConfigFields(conf *params.ChainConfig) (map[string]any, error) {
+	data, err := json.Marshal(conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chain config: %w", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode chain config fields: %w", err)
+	}
+	return fields, nil
+}
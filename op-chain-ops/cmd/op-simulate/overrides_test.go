@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestParseCodeOverrides(t *testing.T) {
+	codeFile := filepath.Join(t.TempDir(), "code.hex")
+	require.NoError(t, os.WriteFile(codeFile, []byte("0x6001\n"), 0o644))
+
+	addrA := common.HexToAddress("0x4200000000000000000000000000000000000015")
+	addrB := common.HexToAddress("0x4200000000000000000000000000000000000016")
+	overrides, err := parseCodeOverrides([]string{
+		addrA.Hex() + "=0x60016002",
+		addrB.Hex() + "=" + codeFile,
+	})
+	require.NoError(t, err)
+	require.Equal(t, codeOverrides{
+		addrA: {0x60, 0x01, 0x60, 0x02},
+		addrB: {0x60, 0x01},
+	}, overrides)
+
+	for _, test := range []struct {
+		arg      string
+		expected string
+	}{
+		{arg: addrA.Hex(), expected: "expected <address>=<code or file>"},
+		{arg: "0x42=0x00", expected: "invalid code override address"},
+		{arg: addrA.Hex() + "=0xzz", expected: "invalid code override of"},
+		{arg: addrA.Hex() + "=" + filepath.Join(t.TempDir(), "missing"), expected: "failed to read code override file"},
+	} {
+		_, err := parseCodeOverrides([]string{test.arg})
+		require.ErrorContains(t, err, test.expected, test.arg)
+	}
+}
+
+func TestApplyForkOverrides(t *testing.T) {
+	shanghai := uint64(10)
+	conf := &params.ChainConfig{ChainID: big.NewInt(901), ShanghaiTime: &shanghai}
+
+	same, err := applyForkOverrides(conf, nil)
+	require.NoError(t, err)
+	require.Same(t, conf, same, "no overrides")
+
+	out, err := applyForkOverrides(conf, []string{"shanghai=0", "Cancun=20"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), *out.ShanghaiTime)
+	require.Equal(t, uint64(20), *out.CancunTime, "fork names are case-insensitive in the first letter")
+	require.Equal(t, big.NewInt(901), out.ChainID)
+	require.Equal(t, uint64(10), *conf.ShanghaiTime, "the original config is not modified")
+
+	for _, test := range []struct {
+		arg      string
+		expected string
+	}{
+		{arg: "shanghai", expected: "expected <fork>=<timestamp>"},
+		{arg: "=1", expected: "empty fork name"},
+		{arg: "shanghai=soon", expected: "invalid fork override time"},
+		{arg: "notafork=1", expected: `unknown fork activation "notaforkTime"`},
+	} {
+		_, err := applyForkOverrides(conf, []string{test.arg})
+		require.ErrorContains(t, err, test.expected, test.arg)
+	}
+}
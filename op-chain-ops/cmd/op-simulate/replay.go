@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/core"
+	gstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// diffModeConfig is the prestateTracer config to only trace the modified state, with pre and post values.
+var diffModeConfig = json.RawMessage(`{"diffMode":true}`)
+
+// blockTraceResult is a single transaction result of debug_traceBlockByNumber.
+type blockTraceResult[T any] struct {
+	TxHash common.Hash `json:"txHash"`
+	Result T           `json:"result"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// diffAccount is an account in the diffMode output of the prestateTracer.
+// Fields are only present if the account field was modified.
+type diffAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   *uint64                     `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// stateDiff is the diffMode output of the prestateTracer for a single transaction.
+type stateDiff struct {
+	Pre  map[common.Address]diffAccount `json:"pre"`
+	Post map[common.Address]diffAccount `json:"post"`
+}
+
+// changes normalizes the diff into the changed account fields, with their new values.
+// Cleared storage slots are only present in the pre-state of the diff, and are normalized to zero.
+func (d *stateDiff) changes() map[string]string {
+	out := make(map[string]string)
+	for addr, pre := range d.Pre {
+		post, ok := d.Post[addr]
+		if !ok {
+			out[addr.String()+".deleted"] = "true"
+			continue
+		}
+		for slot := range pre.Storage {
+			if _, ok := post.Storage[slot]; !ok {
+				out[addr.String()+".storage."+slot.String()] = common.Hash{}.String()
+			}
+		}
+	}
+	for addr, post := range d.Post {
+		if post.Balance != nil {
+			out[addr.String()+".balance"] = post.Balance.String()
+		}
+		if post.Nonce != nil {
+			out[addr.String()+".nonce"] = fmt.Sprint(*post.Nonce)
+		}
+		if post.Code != nil {
+			out[addr.String()+".code"] = crypto.Keccak256Hash(post.Code).String()
+		}
+		for slot, v := range post.Storage {
+			out[addr.String()+".storage."+slot.String()] = v.String()
+		}
+	}
+	return out
+}
+
+// changesHash commits to a set of state changes, such that two executions can be compared at a glance.
+func changesHash(changes map[string]string) common.Hash {
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for _, k := range keys {
+		buf.WriteString(k + "=" + changes[k] + "\n")
+	}
+	return crypto.Keccak256Hash(buf.Bytes())
+}
+
+// compareChanges lists the differences between the expected and the simulated state changes.
+func compareChanges(expected, got map[string]string) []string {
+	var out []string
+	for k, v := range expected {
+		if g, ok := got[k]; !ok {
+			out = append(out, fmt.Sprintf("state %s: expected %s, but not modified", k, v))
+		} else if g != v {
+			out = append(out, fmt.Sprintf("state %s: expected %s, got %s", k, v, g))
+		}
+	}
+	for k, v := range got {
+		if _, ok := expected[k]; !ok {
+			out = append(out, fmt.Sprintf("state %s: unexpectedly modified to %s", k, v))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// compareReceipts lists the differences between the canonical and the simulated receipt.
+func compareReceipts(expected, got *types.Receipt) []string {
+	var out []string
+	if expected.Status != got.Status {
+		out = append(out, fmt.Sprintf("status: expected %d, got %d", expected.Status, got.Status))
+	}
+	if expected.GasUsed != got.GasUsed {
+		out = append(out, fmt.Sprintf("gas used: expected %d, got %d", expected.GasUsed, got.GasUsed))
+	}
+	if expected.CumulativeGasUsed != got.CumulativeGasUsed {
+		out = append(out, fmt.Sprintf("cumulative gas used: expected %d, got %d", expected.CumulativeGasUsed, got.CumulativeGasUsed))
+	}
+	if expected.ContractAddress != got.ContractAddress {
+		out = append(out, fmt.Sprintf("contract address: expected %s, got %s", expected.ContractAddress, got.ContractAddress))
+	}
+	if len(expected.Logs) != len(got.Logs) {
+		out = append(out, fmt.Sprintf("logs: expected %d, got %d", len(expected.Logs), len(got.Logs)))
+	} else {
+		for i := range expected.Logs {
+			e, g := expected.Logs[i], got.Logs[i]
+			if e.Address != g.Address || !slices.Equal(e.Topics, g.Topics) || !bytes.Equal(e.Data, g.Data) {
+				out = append(out, fmt.Sprintf("log %d: expected %s %v %x, got %s %v %x", i, e.Address, e.Topics, e.Data, g.Address, g.Topics, g.Data))
+			}
+		}
+	}
+	return out
+}
+
+// TxResult is the comparison of the canonical and the simulated execution of a transaction.
+type TxResult struct {
+	Block uint64      `json:"block"`
+	Index int         `json:"index"`
+	Hash  common.Hash `json:"hash"`
+
+	ExpectedStatus  uint64 `json:"expectedStatus"`
+	Status          uint64 `json:"status"`
+	ExpectedGasUsed uint64 `json:"expectedGasUsed"`
+	GasUsed         uint64 `json:"gasUsed"`
+
+	// ExpectedChangesHash and ChangesHash commit to the state changes of the transaction, see changesHash.
+	// They are not state roots: the full state root cannot be compared, since only the state accessed by the
+	// block range is available.
+	ExpectedChangesHash common.Hash `json:"expectedChangesHash"`
+	ChangesHash         common.Hash `json:"changesHash"`
+
+	Differences []string `json:"differences,omitempty"`
+	// Error is set if the transaction could not be applied at all, e.g. because it became invalid.
+	Error string `json:"error,omitempty"`
+}
+
+// Matches returns whether the simulated execution matches the canonical execution.
+func (r *TxResult) Matches() bool {
+	return r.Error == "" && len(r.Differences) == 0
+}
+
+// ReplayReport is the result of replaying a block range.
+type ReplayReport struct {
+	From         uint64     `json:"from"`
+	To           uint64     `json:"to"`
+	Transactions int        `json:"transactions"`
+	Mismatches   int        `json:"mismatches"`
+	Results      []TxResult `json:"results"`
+}
+
+// ReplayConfig configures the replay of a block range.
+type ReplayConfig struct {
+	From, To     uint64
+	ChainConfig  *params.ChainConfig
+	Code         codeOverrides
+	PrestatesDir string
+}
+
+func rangePrestateFile(dir string, from, to uint64) string {
+	return path.Join(dir, fmt.Sprintf("prestate_blocks_%d_%d.json", from, to))
+}
+
+// fetchRangePrestate fetches the state that is accessed by the blocks in the range, as of the start of the range.
+// The prestate of each transaction is merged, while keeping the first-seen value of every account and storage slot:
+// state that was not accessed by any prior transaction in the range still has the value of the start of the range.
+func fetchRangePrestate(ctx context.Context, cl *rpc.Client, dir string, from, to uint64) (map[common.Address]DumpAccount, error) {
+	dest := rangePrestateFile(dir, from, to)
+	if _, err := os.Stat(dest); err == nil {
+		return readDump(dest)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to check prestate file %q: %w", dest, err)
+	}
+	out := make(map[common.Address]DumpAccount)
+	for num := from; num <= to; num++ {
+		var results []blockTraceResult[map[common.Address]DumpAccount]
+		if err := cl.CallContext(ctx, &results, "debug_traceBlockByNumber", hexutil.Uint64(num), TraceConfig{
+			Tracer: "prestateTracer",
+		}); err != nil {
+			return nil, fmt.Errorf("failed to retrieve prestate trace of block %d: %w", num, err)
+		}
+		for _, res := range results {
+			if res.Error != "" {
+				return nil, fmt.Errorf("failed to trace tx %s of block %d: %s", res.TxHash, num, res.Error)
+			}
+			mergePrestate(out, res.Result)
+		}
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode prestate: %w", err)
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write prestate trace: %w", err)
+	}
+	return out, nil
+}
+
+// mergePrestate adds the accounts and storage slots of src to dst, if not already present in dst.
+func mergePrestate(dst, src map[common.Address]DumpAccount) {
+	for addr, acc := range src {
+		existing, ok := dst[addr]
+		if !ok {
+			storage := make(map[common.Hash]common.Hash, len(acc.Storage))
+			for k, v := range acc.Storage {
+				storage[k] = v
+			}
+			acc.Storage = storage
+			dst[addr] = acc
+			continue
+		}
+		if existing.Storage == nil {
+			existing.Storage = make(map[common.Hash]common.Hash)
+		}
+		for k, v := range acc.Storage {
+			if _, ok := existing.Storage[k]; !ok {
+				existing.Storage[k] = v
+			}
+		}
+		dst[addr] = existing
+	}
+}
+
+// rpcChainContext serves block headers from the RPC, for BLOCKHASH lookups during the replay.
+type rpcChainContext struct {
+	ctx     context.Context
+	logger  log.Logger
+	cl      *ethclient.Client
+	eng     consensus.Engine
+	headers map[common.Hash]*types.Header
+}
+
+func (d *rpcChainContext) Engine() consensus.Engine {
+	return d.eng
+}
+
+func (d *rpcChainContext) GetHeader(h common.Hash, n uint64) *types.Header {
+	if header, ok := d.headers[h]; ok {
+		return header
+	}
+	header, err := d.cl.HeaderByHash(d.ctx, h)
+	if err != nil {
+		d.logger.Error("Failed to fetch header", "hash", h, "number", n, "err", err)
+		return nil
+	}
+	d.headers[h] = header
+	return header
+}
+
+// replayRange replays the blocks of the range on top of the prestate of the range,
+// with the given chain config and code overrides, and compares every transaction with the canonical chain.
+func replayRange(ctx context.Context, logger log.Logger, cl *rpc.Client, cfg *ReplayConfig) (*ReplayReport, error) {
+	if cfg.From == 0 || cfg.To < cfg.From {
+		return nil, fmt.Errorf("invalid block range %d-%d", cfg.From, cfg.To)
+	}
+	dump, err := fetchRangePrestate(ctx, cl, cfg.PrestatesDir, cfg.From, cfg.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare prestate: %w", err)
+	}
+	state, err := newPrestateDB(dump, cfg.From-1)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Code.apply(state)
+
+	client := ethclient.NewClient(cl)
+	cCtx := &rpcChainContext{ctx: ctx, logger: logger, cl: client, eng: beacon.NewFaker(), headers: make(map[common.Hash]*types.Header)}
+	report := &ReplayReport{From: cfg.From, To: cfg.To}
+	for num := cfg.From; num <= cfg.To; num++ {
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(num))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block %d: %w", num, err)
+		}
+		cCtx.headers[block.Hash()] = block.Header()
+		receipts, err := client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipts of block %d: %w", num, err)
+		}
+		var diffs []blockTraceResult[stateDiff]
+		if err := cl.CallContext(ctx, &diffs, "debug_traceBlockByNumber", hexutil.Uint64(num), TraceConfig{
+			Tracer:       "prestateTracer",
+			TracerConfig: diffModeConfig,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to retrieve state diff trace of block %d: %w", num, err)
+		}
+		txs := block.Transactions()
+		if len(receipts) != len(txs) || len(diffs) != len(txs) {
+			return nil, fmt.Errorf("block %d has %d txs, but got %d receipts and %d traces", num, len(txs), len(receipts), len(diffs))
+		}
+
+		header := block.Header()
+		gp := core.GasPool(header.GasLimit)
+		usedGas := uint64(0)
+		for i, tx := range txs {
+			res := TxResult{
+				Block:               num,
+				Index:               i,
+				Hash:                tx.Hash(),
+				ExpectedStatus:      receipts[i].Status,
+				ExpectedGasUsed:     receipts[i].GasUsed,
+				ExpectedChangesHash: changesHash(diffs[i].Result.changes()),
+			}
+			if err := replayTx(cCtx, cfg.ChainConfig, state, header, tx, i, &gp, &usedGas, receipts[i], &diffs[i].Result, dump, &res); err != nil {
+				res.Error = err.Error()
+			}
+			if !res.Matches() {
+				report.Mismatches++
+				logger.Warn("Transaction differs", "block", num, "index", i, "tx", tx.Hash(),
+					"status", res.Status, "expected_status", res.ExpectedStatus,
+					"gas", res.GasUsed, "expected_gas", res.ExpectedGasUsed,
+					"changes_hash", res.ChangesHash, "expected_changes_hash", res.ExpectedChangesHash,
+					"err", res.Error, "differences", strings.Join(res.Differences, "; "))
+			}
+			report.Results = append(report.Results, res)
+			report.Transactions++
+		}
+		logger.Info("Replayed block", "number", num, "txs", len(txs), "mismatches", report.Mismatches)
+	}
+	return report, nil
+}
+
+// replayTx applies a transaction on the replay state, and compares it with the canonical receipt and state diff.
+func replayTx(cCtx core.ChainContext, conf *params.ChainConfig, state *gstate.StateDB, header *types.Header,
+	tx *types.Transaction, index int, gp *core.GasPool, usedGas *uint64,
+	expectedReceipt *types.Receipt, expectedDiff *stateDiff, prestate map[common.Address]DumpAccount, res *TxResult) error {
+	tracer, err := tracers.DefaultDirectory.New("prestateTracer", &tracers.Context{
+		BlockHash:   header.Hash(),
+		BlockNumber: header.Number,
+		TxIndex:     index,
+		TxHash:      tx.Hash(),
+	}, diffModeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create state diff tracer: %w", err)
+	}
+	state.SetTxContext(tx.Hash(), index)
+	receipt, err := core.ApplyTransaction(conf, cCtx, nil, gp, state, header, tx, usedGas, vm.Config{Tracer: tracer})
+	if err != nil {
+		return fmt.Errorf("failed to apply tx: %w", err)
+	}
+	res.Status = receipt.Status
+	res.GasUsed = receipt.GasUsed
+	res.Differences = compareReceipts(expectedReceipt, receipt)
+
+	out, err := tracer.GetResult()
+	if err != nil {
+		return fmt.Errorf("failed to get state diff: %w", err)
+	}
+	var got stateDiff
+	if err := json.Unmarshal(out, &got); err != nil {
+		return fmt.Errorf("failed to decode state diff: %w", err)
+	}
+	changes := got.changes()
+	res.ChangesHash = changesHash(changes)
+	res.Differences = append(res.Differences, compareChanges(expectedDiff.changes(), changes)...)
+	for addr := range got.Post {
+		if _, ok := prestate[addr]; !ok {
+			if _, ok := expectedDiff.Post[addr]; !ok {
+				res.Differences = append(res.Differences, fmt.Sprintf("account %s is outside of the fetched prestate, its original state is unknown", addr))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestStateDiffChanges(t *testing.T) {
+	addrA := common.Address{0xaa}
+	addrB := common.Address{0xbb}
+	addrC := common.Address{0xcc}
+	slot1, slot2 := common.Hash{0x01}, common.Hash{0x02}
+	var diff stateDiff
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"pre": {
+			"`+addrA.Hex()+`": {"balance": "0x10", "nonce": 1, "storage": {"`+slot1.Hex()+`": "`+common.Hash{0x05}.Hex()+`", "`+slot2.Hex()+`": "`+common.Hash{0x06}.Hex()+`"}},
+			"`+addrC.Hex()+`": {"balance": "0x1"}
+		},
+		"post": {
+			"`+addrA.Hex()+`": {"balance": "0x20", "storage": {"`+slot1.Hex()+`": "`+common.Hash{0x07}.Hex()+`"}},
+			"`+addrB.Hex()+`": {"nonce": 1, "code": "0x6001"}
+		}
+	}`), &diff))
+
+	require.Equal(t, map[string]string{
+		addrA.String() + ".balance":                   "0x20",
+		addrA.String() + ".storage." + slot1.String(): common.Hash{0x07}.String(),
+		addrA.String() + ".storage." + slot2.String(): common.Hash{}.String(),
+		addrB.String() + ".nonce":                     "1",
+		addrB.String() + ".code":                      crypto.Keccak256Hash([]byte{0x60, 0x01}).String(),
+		addrC.String() + ".deleted":                   "true",
+	}, diff.changes())
+}
+
+func TestCompareChanges(t *testing.T) {
+	expected := map[string]string{"a.balance": "0x1", "a.nonce": "1", "b.deleted": "true"}
+	require.Empty(t, compareChanges(expected, map[string]string{"a.balance": "0x1", "a.nonce": "1", "b.deleted": "true"}))
+	require.Equal(t, changesHash(expected), changesHash(map[string]string{"b.deleted": "true", "a.nonce": "1", "a.balance": "0x1"}),
+		"the hash does not depend on the map order")
+
+	got := map[string]string{"a.balance": "0x2", "c.nonce": "1", "b.deleted": "true"}
+	require.Equal(t, []string{
+		"state a.balance: expected 0x1, got 0x2",
+		"state a.nonce: expected 1, but not modified",
+		"state c.nonce: unexpectedly modified to 1",
+	}, compareChanges(expected, got))
+	require.NotEqual(t, changesHash(expected), changesHash(got))
+}
+
+func TestCompareReceipts(t *testing.T) {
+	lg := &types.Log{Address: common.Address{0x01}, Topics: []common.Hash{{0x02}}, Data: []byte{0x03}}
+	expected := &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, CumulativeGasUsed: 42000, Logs: []*types.Log{lg}}
+	same := *expected
+	same.Logs = []*types.Log{{Address: lg.Address, Topics: lg.Topics, Data: lg.Data, BlockNumber: 5}}
+	require.Empty(t, compareReceipts(expected, &same), "only consensus log fields are compared")
+
+	got := &types.Receipt{
+		Status:            types.ReceiptStatusFailed,
+		GasUsed:           30000,
+		CumulativeGasUsed: 51000,
+		ContractAddress:   common.Address{0x04},
+		Logs:              []*types.Log{{Address: lg.Address, Topics: lg.Topics, Data: []byte{0x04}}},
+	}
+	diffs := compareReceipts(expected, got)
+	require.Len(t, diffs, 5)
+	require.Equal(t, "status: expected 1, got 0", diffs[0])
+	require.Equal(t, "gas used: expected 21000, got 30000", diffs[1])
+	require.Equal(t, "cumulative gas used: expected 42000, got 51000", diffs[2])
+	require.Contains(t, diffs[3], "contract address")
+	require.Contains(t, diffs[4], "log 0")
+
+	got.Logs = nil
+	require.Contains(t, compareReceipts(expected, got), "logs: expected 1, got 0")
+}
+
+func TestMergePrestate(t *testing.T) {
+	addr := common.Address{0xaa}
+	slot1, slot2 := common.Hash{0x01}, common.Hash{0x02}
+	dst := map[common.Address]DumpAccount{}
+	first := map[common.Address]DumpAccount{addr: {Nonce: 1, Storage: map[common.Hash]common.Hash{slot1: {0x10}}}}
+	mergePrestate(dst, first)
+	mergePrestate(dst, map[common.Address]DumpAccount{addr: {Nonce: 2, Storage: map[common.Hash]common.Hash{slot1: {0x11}, slot2: {0x20}}}})
+
+	require.Equal(t, uint64(1), dst[addr].Nonce, "keeps the first-seen account")
+	require.Equal(t, map[common.Hash]common.Hash{slot1: {0x10}, slot2: {0x20}}, dst[addr].Storage, "keeps the first-seen slot values")
+	require.Len(t, first[addr].Storage, 1, "the source prestate is not modified")
+}
@@ -87,6 +87,16 @@ func main() {
 				Usage:   "The file to write the output to. If not specified, output is written to stdout",
 				EnvVars: []string{"OUTFILE"},
 			},
+			&cli.BoolFlag{
+				Name:    "simulate",
+				Usage:   "Simulate the batch on a fork of L1, and fail if the upgrade breaks any of the post-upgrade invariants",
+				EnvVars: []string{"SIMULATE"},
+			},
+			&cli.PathFlag{
+				Name:    "simulation-report",
+				Usage:   "The file to write the simulation report to. If not specified, the report is written to stderr",
+				EnvVars: []string{"SIMULATION_REPORT"},
+			},
 		},
 		Action: entrypoint,
 	}
@@ -154,6 +164,8 @@ func entrypoint(ctx *cli.Context) error {
 	// Create a batch of transactions
 	batch := safe.Batch{}
 
+	var reports []*upgrades.Report
+
 	for _, chainConfig := range targets {
 		// Panic if this chain ID is not allowed. See comments in isAllowedChainID to learn more.
 		isAllowedChainID(chainConfig.ChainID)
@@ -252,9 +264,40 @@ func entrypoint(ctx *cli.Context) error {
 
 		// Build the batch
 		// op-upgrade assumes a superchain config for L1 contract-implementations set.
+		start := len(batch.Transactions)
 		if err := upgrades.L1(&batch, list, *addresses, config, chainConfig, sc, clients.L1Client); err != nil {
 			return err
 		}
+
+		if ctx.Bool("simulate") {
+			log.Info("Simulating upgrade on a fork of L1", "name", chainConfig.Name)
+			header, err := clients.L1Client.HeaderByNumber(ctx.Context, nil)
+			if err != nil {
+				return fmt.Errorf("cannot fetch L1 head: %w", err)
+			}
+			report, err := upgrades.SimulateBatch(log.Root(), &upgrades.SimulationConfig{
+				Client:    clients.L1RpcClient,
+				Header:    header,
+				L1ChainID: l1ChainID,
+			}, chainConfig.Name, batch.Transactions[start:], list, *addresses)
+			if err != nil {
+				return fmt.Errorf("cannot simulate upgrade of %s: %w", chainConfig.Name, err)
+			}
+			reports = append(reports, report)
+		}
+	}
+
+	if len(reports) > 0 {
+		if err := writeReports(ctx.Path("simulation-report"), reports); err != nil {
+			return fmt.Errorf("cannot write simulation report: %w", err)
+		}
+		var errs []error
+		for _, report := range reports {
+			errs = append(errs, report.Err())
+		}
+		if err := errors.Join(errs...); err != nil {
+			return fmt.Errorf("upgrade simulation failed: %w", err)
+		}
 	}
 
 	// Write the batch to disk or stdout
@@ -272,6 +315,25 @@ func entrypoint(ctx *cli.Context) error {
 	return nil
 }
 
+// writeReports writes the simulation reports to the given file, or stderr if no file is given.
+func writeReports(path string, reports []*upgrades.Report) error {
+	w := os.Stderr
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	for _, report := range reports {
+		if err := report.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // toDeployConfigName is a temporary function that maps the chain config names
 // to deploy config names. This should be able to be removed in the future
 // with a canonical naming scheme. If an empty string is returned, then
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/clients"
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/withdrawals"
+	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
+	"github.com/tokamak-network/tokamak-thanos/op-service/cliapp"
+	"github.com/tokamak-network/tokamak-thanos/op-service/jsonutil"
+	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
+	"github.com/tokamak-network/tokamak-thanos/op-service/opio"
+	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
+	txmetrics "github.com/tokamak-network/tokamak-thanos/op-service/txmgr/metrics"
+)
+
+const envPrefix = "WITHDRAWALS"
+
+var (
+	l1EthRpcFlag = &cli.StringFlag{
+		Name:     txmgr.L1RPCFlagName,
+		Usage:    "HTTP provider URL for L1.",
+		EnvVars:  opservice.PrefixEnvVar(envPrefix, "L1_ETH_RPC"),
+		Required: true,
+	}
+	l2EthRpcFlag = &cli.StringFlag{
+		Name:     "l2-eth-rpc",
+		Usage:    "HTTP provider URL for L2. It must serve eth_getProof for the blocks of the L2 outputs.",
+		EnvVars:  opservice.PrefixEnvVar(envPrefix, "L2_ETH_RPC"),
+		Required: true,
+	}
+	portalAddressFlag = &cli.StringFlag{
+		Name:     "portal-address",
+		Usage:    "Address of the OptimismPortal proxy contract.",
+		EnvVars:  opservice.PrefixEnvVar(envPrefix, "PORTAL_ADDRESS"),
+		Required: true,
+	}
+	addressesFlag = &cli.StringSliceFlag{
+		Name:    "address",
+		Usage:   "Address to find the withdrawals of, as sender or target of the withdrawal or of its cross domain message. May be repeated. All withdrawals are found if omitted.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "ADDRESSES"),
+	}
+	startBlockFlag = &cli.Uint64Flag{
+		Name:    "start-block",
+		Usage:   "First L2 block to search for withdrawals.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "START_BLOCK"),
+	}
+	endBlockFlag = &cli.Uint64Flag{
+		Name:        "end-block",
+		Usage:       "Last L2 block to search for withdrawals.",
+		EnvVars:     opservice.PrefixEnvVar(envPrefix, "END_BLOCK"),
+		DefaultText: "latest",
+	}
+	blockRangeFlag = &cli.Uint64Flag{
+		Name:    "block-range",
+		Usage:   "Maximum number of L2 blocks to request the withdrawal events of at once.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "BLOCK_RANGE"),
+		Value:   10_000,
+	}
+	skipProofsFlag = &cli.BoolFlag{
+		Name:    "skip-proofs",
+		Usage:   "Do not generate the proofs of the withdrawals that are ready to be proven.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "SKIP_PROOFS"),
+	}
+	outFlag = &cli.StringFlag{
+		Name:    "out",
+		Usage:   "Path to write the JSON report to, '-' for stdout.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "OUT"),
+		Value:   "-",
+	}
+	batchSizeFlag = &cli.IntFlag{
+		Name:    "batch-size",
+		Usage:   "Number of prove and finalize transactions to send concurrently.",
+		EnvVars: opservice.PrefixEnvVar(envPrefix, "BATCH_SIZE"),
+		Value:   10,
+	}
+)
+
+func findFlags() []cli.Flag {
+	flags := []cli.Flag{
+		l1EthRpcFlag,
+		l2EthRpcFlag,
+		portalAddressFlag,
+		addressesFlag,
+		startBlockFlag,
+		endBlockFlag,
+		blockRangeFlag,
+		skipProofsFlag,
+		outFlag,
+	}
+	return append(flags, oplog.CLIFlags(envPrefix)...)
+}
+
+func submitFlags() []cli.Flag {
+	flags := append(findFlags(), batchSizeFlag)
+	return append(flags, txmgr.CLIFlags(envPrefix)...)
+}
+
+func main() {
+	oplog.SetupDefaults()
+
+	app := cli.NewApp()
+	app.Name = "withdrawals"
+	app.Usage = "Find and complete the unproven or unfinalized withdrawals of a set of addresses."
+	app.Description = "Scans the MessagePassed events of the L2ToL1MessagePasser, and reports the withdrawals that " +
+		"are not finalized on the OptimismPortal yet, with the proofs of the ones that are ready to be proven. " +
+		"The withdrawals can be proven and finalized in batches of transactions."
+	app.Writer = os.Stdout
+	app.ErrWriter = os.Stderr
+	app.Commands = []*cli.Command{
+		{
+			Name:   "find",
+			Usage:  "Reports the withdrawals that are not finalized yet.",
+			Flags:  cliapp.ProtectFlags(findFlags()),
+			Action: findAction,
+		},
+		{
+			Name: "submit",
+			Usage: "Proves the withdrawals that are ready to be proven, and finalizes the ones that are ready to be finalized. " +
+				"Withdrawals that are proven by this command can be finalized by running it again after the proof matured.",
+			Flags:  cliapp.ProtectFlags(submitFlags()),
+			Action: submitAction,
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Crit("Application failed", "err", err)
+	}
+}
+
+type report struct {
+	Portal      common.Address       `json:"portal"`
+	FaultProofs bool                 `json:"faultProofs"`
+	StartBlock  uint64               `json:"startBlock"`
+	EndBlock    uint64               `json:"endBlock"`
+	L1Timestamp uint64               `json:"l1Timestamp"`
+	Withdrawals []*withdrawals.Entry `json:"withdrawals"`
+	Results     []withdrawals.Result `json:"results,omitempty"`
+}
+
+func findAction(cliCtx *cli.Context) error {
+	ctx := opio.CancelOnInterrupt(cliCtx.Context)
+	logger := oplog.NewLogger(os.Stderr, oplog.ReadCLIConfig(cliCtx))
+	r, _, err := find(ctx, cliCtx, logger, !cliCtx.Bool(skipProofsFlag.Name))
+	if err != nil {
+		return err
+	}
+	return jsonutil.WriteJSON(cliCtx.String(outFlag.Name), r, 0o644)
+}
+
+func submitAction(cliCtx *cli.Context) error {
+	ctx := opio.CancelOnInterrupt(cliCtx.Context)
+	logger := oplog.NewLogger(os.Stderr, oplog.ReadCLIConfig(cliCtx))
+	if cliCtx.Bool(skipProofsFlag.Name) {
+		return errors.New("proofs are required to submit withdrawals")
+	}
+
+	txMgr, err := txmgr.NewSimpleTxManager("withdrawals", logger, &txmetrics.NoopTxMetrics{}, txmgr.ReadCLIConfig(cliCtx))
+	if err != nil {
+		return fmt.Errorf("failed to create the transaction manager: %w", err)
+	}
+	defer txMgr.Close()
+	logger.Info("Configured transaction manager", "sender", txMgr.From())
+
+	r, portal, err := find(ctx, cliCtx, logger, true)
+	if err != nil {
+		return err
+	}
+	r.Results, err = withdrawals.Submit(ctx, logger, txMgr, portal.Address(), r.Withdrawals, cliCtx.Int(batchSizeFlag.Name))
+	// Write the report of the sent transactions, also when the submission is interrupted.
+	if writeErr := jsonutil.WriteJSON(cliCtx.String(outFlag.Name), r, 0o644); writeErr != nil {
+		return errors.Join(err, writeErr)
+	}
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, res := range r.Results {
+		if res.Error != "" {
+			failed++
+		}
+	}
+	logger.Info("Submitted withdrawal transactions", "total", len(r.Results), "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d withdrawal transactions failed", failed, len(r.Results))
+	}
+	return nil
+}
+
+func find(ctx context.Context, cliCtx *cli.Context, logger log.Logger, withProofs bool) (*report, withdrawals.Portal, error) {
+	portalAddr, err := opservice.ParseAddress(cliCtx.String(portalAddressFlag.Name))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid portal address: %w", err)
+	}
+	var addrs []common.Address
+	for _, s := range cliCtx.StringSlice(addressesFlag.Name) {
+		addr, err := opservice.ParseAddress(s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid address %q: %w", s, err)
+		}
+		addrs = append(addrs, addr)
+	}
+
+	cl, err := clients.NewClients(cliCtx.String(l1EthRpcFlag.Name), cliCtx.String(l2EthRpcFlag.Name))
+	if err != nil {
+		return nil, nil, err
+	}
+	portal, err := withdrawals.NewPortal(ctx, cl.L1Client, cl.L2GethClient, cl.L2Client, portalAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.Info("Loaded portal", "address", portalAddr, "faultProofs", portal.FaultProofs())
+
+	start := cliCtx.Uint64(startBlockFlag.Name)
+	end := cliCtx.Uint64(endBlockFlag.Name)
+	if !cliCtx.IsSet(endBlockFlag.Name) {
+		end, err = cl.L2Client.BlockNumber(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get L2 head: %w", err)
+		}
+	}
+	if start > end {
+		return nil, nil, fmt.Errorf("start block %d is after end block %d", start, end)
+	}
+
+	logger.Info("Scanning withdrawals", "start", start, "end", end, "addresses", len(addrs))
+	found, err := withdrawals.Scan(ctx, cl.L2Client, start, end, cliCtx.Uint64(blockRangeFlag.Name), withdrawals.NewAddressFilter(addrs...))
+	if err != nil {
+		return nil, nil, err
+	}
+	l1Head, err := cl.L1Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get L1 head: %w", err)
+	}
+	entries, err := withdrawals.Inspect(ctx, logger, portal, found, l1Head.Time, withProofs)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.Info("Found withdrawals", "total", len(found), "incomplete", len(entries))
+
+	return &report{
+		Portal:      portalAddr,
+		FaultProofs: portal.FaultProofs(),
+		StartBlock:  start,
+		EndBlock:    end,
+		L1Timestamp: l1Head.Time,
+		Withdrawals: entries,
+	}, portal, nil
+}
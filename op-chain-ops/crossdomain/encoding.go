@@ -1,6 +1,9 @@
 package crossdomain
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 
@@ -58,6 +61,44 @@ func EncodeCrossDomainMessageV1(
 	return relayMessage1.Pack("relayMessage", nonce, sender, target, value, gasLimit, data)
 }
 
+// DecodeCrossDomainMessage will decode the calldata of a call to
+// "relayMessage" of either version, i.e. the data of a withdrawal
+// sent by the L2CrossDomainMessenger.
+func DecodeCrossDomainMessage(data []byte) (*CrossDomainMessage, error) {
+	if len(data) < 4 {
+		return nil, errors.New("calldata too short")
+	}
+	if method := relayMessage1.Methods["relayMessage"]; bytes.Equal(data[:4], method.ID) {
+		args, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode v1 relayMessage: %w", err)
+		}
+		return NewCrossDomainMessage(
+			args[0].(*big.Int),
+			args[1].(common.Address),
+			args[2].(common.Address),
+			args[3].(*big.Int),
+			args[4].(*big.Int),
+			args[5].([]byte),
+		), nil
+	}
+	if method := relayMessage0.Methods["relayMessage"]; bytes.Equal(data[:4], method.ID) {
+		args, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode v0 relayMessage: %w", err)
+		}
+		return NewCrossDomainMessage(
+			args[3].(*big.Int),
+			args[1].(common.Address),
+			args[0].(common.Address),
+			new(big.Int),
+			new(big.Int),
+			args[2].([]byte),
+		), nil
+	}
+	return nil, errors.New("not a relayMessage call")
+}
+
 // DecodeVersionedNonce will decode the version that is encoded in the nonce
 func DecodeVersionedNonce(versioned *big.Int) (*big.Int, *big.Int) {
 	nonce := new(big.Int).And(versioned, NonceMask)
@@ -4,6 +4,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/crossdomain"
 )
@@ -28,3 +29,45 @@ func FuzzVersionedNonce(f *testing.F) {
 		require.Equal(t, decodedVersion.Uint64(), inputVersion.Uint64())
 	})
 }
+
+func TestDecodeCrossDomainMessage(t *testing.T) {
+	sender := common.Address{0x01}
+	target := common.Address{0x02}
+	data := []byte{0xaa, 0xbb}
+
+	t.Run("v0", func(t *testing.T) {
+		nonce := big.NewInt(5)
+		enc, err := crossdomain.EncodeCrossDomainMessageV0(target, sender, data, nonce)
+		require.NoError(t, err)
+
+		msg, err := crossdomain.DecodeCrossDomainMessage(enc)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), msg.Version())
+		require.Equal(t, sender, msg.Sender)
+		require.Equal(t, target, msg.Target)
+		require.Equal(t, data, msg.Data)
+		require.Zero(t, nonce.Cmp(msg.Nonce))
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		nonce := crossdomain.EncodeVersionedNonce(big.NewInt(7), common.Big1)
+		enc, err := crossdomain.EncodeCrossDomainMessageV1(nonce, sender, target, big.NewInt(100), big.NewInt(200_000), data)
+		require.NoError(t, err)
+
+		msg, err := crossdomain.DecodeCrossDomainMessage(enc)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), msg.Version())
+		require.Equal(t, sender, msg.Sender)
+		require.Equal(t, target, msg.Target)
+		require.Equal(t, data, msg.Data)
+		require.Zero(t, big.NewInt(100).Cmp(msg.Value))
+		require.Zero(t, big.NewInt(200_000).Cmp(msg.GasLimit))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := crossdomain.DecodeCrossDomainMessage([]byte{0x01})
+		require.Error(t, err)
+		_, err = crossdomain.DecodeCrossDomainMessage([]byte{0x01, 0x02, 0x03, 0x04})
+		require.Error(t, err)
+	})
+}
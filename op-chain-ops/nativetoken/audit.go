@@ -0,0 +1,542 @@
+package nativetoken
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bindings/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/withdrawals"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+)
+
+// Kind is the kind of a Discrepancy.
+type Kind string
+
+const (
+	// KindDepositMismatch is a deposit transaction that transferred a different amount of tokens to the portal
+	// than it minted.
+	KindDepositMismatch Kind = "deposit-mismatch"
+	// KindDonation is a transaction that transferred tokens to the portal without depositing them.
+	KindDonation Kind = "donation"
+	// KindStrandedWithdrawal is a finalized withdrawal that left (part of) its value in the portal,
+	// e.g. because the call to its target failed and the approved tokens were not pulled.
+	KindStrandedWithdrawal Kind = "stranded-withdrawal"
+	// KindOverpaidWithdrawal is a finalized withdrawal that transferred more tokens out of the portal than its value.
+	KindOverpaidWithdrawal Kind = "overpaid-withdrawal"
+	// KindUnexpectedTransfer is a transaction that transferred tokens out of the portal without finalizing a withdrawal.
+	KindUnexpectedTransfer Kind = "unexpected-transfer"
+	// KindUnknownWithdrawal is a finalized withdrawal that was not initiated on L2 up to the audited L2 block.
+	KindUnknownWithdrawal Kind = "unknown-withdrawal"
+	// KindMissingDeposit is a deposit that should be included on L2 at the audited L2 block, but is not.
+	KindMissingDeposit Kind = "missing-deposit"
+	// KindBalanceMismatch is a difference between the portal balance and the transfers to and from the portal.
+	KindBalanceMismatch Kind = "balance-mismatch"
+)
+
+// Discrepancy is a deviation of the portal balance from the supply that it backs.
+type Discrepancy struct {
+	Kind Kind `json:"kind"`
+	// TxHash and Block are the L1 transaction that caused the discrepancy, they are not set for balance mismatches.
+	TxHash common.Hash `json:"txHash"`
+	Block  uint64      `json:"blockNumber"`
+	// Amount is the number of tokens that the portal holds in excess of the supply that it backs, negative if it
+	// holds too few. Missing deposits are counted as in flight, their amount is the mint of the deposit instead.
+	Amount *big.Int `json:"amount"`
+	Detail string   `json:"detail"`
+}
+
+// Deposit is a deposit of native tokens to L2.
+type Deposit struct {
+	L1TxHash common.Hash `json:"l1TxHash"`
+	L1Block  uint64      `json:"l1BlockNumber"`
+	// L2TxHash is the hash of the deposit transaction on L2, which commits to the mint.
+	L2TxHash common.Hash `json:"l2TxHash"`
+	Mint     *big.Int    `json:"mint"`
+}
+
+// Report is the native token supply at a pair of L1 and L2 blocks.
+type Report struct {
+	Portal      common.Address `json:"portal"`
+	NativeToken common.Address `json:"nativeToken"`
+	L1Block     uint64         `json:"l1BlockNumber"`
+	L2Block     uint64         `json:"l2BlockNumber"`
+	// L1Origin is the L1 origin of L2Block, the deposits of later L1 blocks cannot be included on L2 yet.
+	L1Origin uint64 `json:"l1Origin"`
+
+	PortalBalance *big.Int `json:"portalBalance"`
+	// Deposited is the mint of the deposits on L1, of which Minted is included on L2 and InFlight is not yet.
+	Deposited *big.Int `json:"deposited"`
+	Minted    *big.Int `json:"minted"`
+	InFlight  *big.Int `json:"inFlight"`
+	// Withdrawn is the value of the withdrawals initiated on L2, of which Finalized is finalized on L1
+	// and Pending is not yet.
+	Withdrawn *big.Int `json:"withdrawn"`
+	Finalized *big.Int `json:"finalized"`
+	Pending   *big.Int `json:"pending"`
+	// Issuance is the supply of bridged tokens on L2: the minted deposits minus the initiated withdrawals.
+	Issuance *big.Int `json:"issuance"`
+	// Unaccounted is the portal balance minus the issuance, the pending withdrawals and the deposits in flight.
+	// It is the sum of the amounts of the discrepancies, apart from the missing deposits.
+	Unaccounted *big.Int `json:"unaccounted"`
+
+	InFlightDeposits []*Deposit     `json:"inFlightDeposits"`
+	Discrepancies    []*Discrepancy `json:"discrepancies"`
+}
+
+type L1Client interface {
+	bind.ContractCaller
+	bind.ContractFilterer
+}
+
+type L2Client interface {
+	bind.ContractCaller
+	bind.ContractFilterer
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// l1Tx holds the native token flows of an L1 transaction.
+type l1Tx struct {
+	hash      common.Hash
+	block     uint64
+	index     uint
+	deposits  []*Deposit
+	finalized []common.Hash
+	in, out   *big.Int
+}
+
+// finalization is an L1 transaction that finalized withdrawals. The tokens that it transferred out of the portal
+// are compared with the value of its withdrawals, once all of them are found on L2.
+type finalization struct {
+	hash    common.Hash
+	block   uint64
+	out     *big.Int
+	value   *big.Int
+	unknown []common.Hash
+}
+
+// Auditor keeps the native token accounts of a chain, and advances them over new L1 and L2 blocks.
+// The audited blocks must be final, reorgs are not handled.
+type Auditor struct {
+	log  log.Logger
+	l2   L2Client
+	step uint64
+
+	portalAddr  common.Address
+	tokenAddr   common.Address
+	portal      *bindings.OptimismPortalFilterer
+	token       *bindings.ERC20Caller
+	tokenEvents *bindings.ERC20Filterer
+	l1Block     *bindings.L1BlockCaller
+
+	// l1Next and l2Next are the first blocks that are not audited yet.
+	l1Next uint64
+	l2Next uint64
+
+	// transferred is the portal balance that follows from the transfers to and from the portal.
+	transferred *big.Int
+	deposited   *big.Int
+	minted      *big.Int
+	withdrawn   *big.Int
+	finalized   *big.Int
+
+	unminted []*Deposit
+	pending  map[common.Hash]*withdrawals.Withdrawal
+	// unresolved are the finalizations of withdrawals that are not found on L2 yet,
+	// unknown indexes them by the hashes of these withdrawals.
+	unresolved    []*finalization
+	unknown       map[common.Hash]*finalization
+	discrepancies []*Discrepancy
+}
+
+// NewAuditor creates an Auditor of the native token of the portal, that starts at the L1 block l1Start and
+// at L2 genesis. The portal must not hold any native tokens before l1Start. Events are requested in ranges
+// of at most step blocks, to stay within the limits of the RPC providers.
+func NewAuditor(ctx context.Context, logger log.Logger, l1 L1Client, l2 L2Client, portalAddr common.Address, l1Start uint64, step uint64) (*Auditor, error) {
+	if step == 0 {
+		return nil, fmt.Errorf("invalid block range step: %d", step)
+	}
+	portalCaller, err := bindings.NewOptimismPortalCaller(portalAddr, l1)
+	if err != nil {
+		return nil, err
+	}
+	tokenAddr, err := portalCaller.NativeTokenAddress(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get native token of portal %s: %w", portalAddr, err)
+	}
+	if tokenAddr == (common.Address{}) {
+		return nil, fmt.Errorf("portal %s has no native token", portalAddr)
+	}
+	portal, err := bindings.NewOptimismPortalFilterer(portalAddr, l1)
+	if err != nil {
+		return nil, err
+	}
+	token, err := bindings.NewERC20Caller(tokenAddr, l1)
+	if err != nil {
+		return nil, err
+	}
+	tokenEvents, err := bindings.NewERC20Filterer(tokenAddr, l1)
+	if err != nil {
+		return nil, err
+	}
+	l1Block, err := bindings.NewL1BlockCaller(predeploys.L1BlockAddr, l2)
+	if err != nil {
+		return nil, err
+	}
+	if l1Start > 0 {
+		opening, err := token.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(l1Start - 1)}, portalAddr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get portal balance at L1 block %d: %w", l1Start-1, err)
+		}
+		if opening.Sign() != 0 {
+			return nil, fmt.Errorf("portal holds %v native tokens before L1 block %d, the audit must start before the first deposit", opening, l1Start)
+		}
+	}
+	return &Auditor{
+		log:         logger,
+		l2:          l2,
+		step:        step,
+		portalAddr:  portalAddr,
+		tokenAddr:   tokenAddr,
+		portal:      portal,
+		token:       token,
+		tokenEvents: tokenEvents,
+		l1Block:     l1Block,
+		l1Next:      l1Start,
+		transferred: new(big.Int),
+		deposited:   new(big.Int),
+		minted:      new(big.Int),
+		withdrawn:   new(big.Int),
+		finalized:   new(big.Int),
+		pending:     make(map[common.Hash]*withdrawals.Withdrawal),
+		unknown:     make(map[common.Hash]*finalization),
+	}, nil
+}
+
+// NativeToken returns the address of the audited token on L1.
+func (a *Auditor) NativeToken() common.Address {
+	return a.tokenAddr
+}
+
+// Update advances the accounts to the L1 block l1End and the L2 block l2End, and reports the supply at these
+// blocks. The L2 block should be derived from about l1End: withdrawals that are finalized on L1 must be initiated
+// on L2 up to l2End, or they are reported as unknown withdrawals.
+func (a *Auditor) Update(ctx context.Context, l1End, l2End uint64) (*Report, error) {
+	if l1End+1 < a.l1Next || l2End+1 < a.l2Next {
+		return nil, fmt.Errorf("cannot audit L1 block %d and L2 block %d, the next blocks to audit are L1 block %d and L2 block %d",
+			l1End, l2End, a.l1Next, a.l2Next)
+	}
+	if l2End >= a.l2Next {
+		a.log.Info("Scanning withdrawals", "start", a.l2Next, "end", l2End)
+		found, err := withdrawals.Scan(ctx, a.l2, a.l2Next, l2End, a.step, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range found {
+			a.addWithdrawal(w)
+		}
+		a.l2Next = l2End + 1
+	}
+	if l1End >= a.l1Next {
+		a.log.Info("Scanning portal transactions", "start", a.l1Next, "end", l1End)
+	}
+	for from := a.l1Next; from <= l1End; from += a.step {
+		to := min(from+a.step-1, l1End)
+		if err := a.scanL1(ctx, from, to); err != nil {
+			return nil, err
+		}
+		a.l1Next = to + 1
+		if to == l1End {
+			break // avoid overflow of from at the end of the uint64 range
+		}
+	}
+
+	origin, err := a.l1Block.Number(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(l2End)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L1 origin of L2 block %d: %w", l2End, err)
+	}
+	if err := a.mint(ctx, origin, l2End); err != nil {
+		return nil, err
+	}
+	balance, err := a.token.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(l1End)}, a.portalAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portal balance at L1 block %d: %w", l1End, err)
+	}
+	return a.report(l1End, l2End, origin, balance), nil
+}
+
+// iterator is implemented by the event iterators of the bindings.
+type iterator interface {
+	Next() bool
+	Error() error
+	Close() error
+}
+
+func drain(iter iterator, fn func() error) error {
+	defer iter.Close()
+	for iter.Next() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+// scanL1 accounts the deposits, finalized withdrawals and native token transfers of the portal
+// in the L1 blocks from to to (inclusive).
+func (a *Auditor) scanL1(ctx context.Context, from, to uint64) error {
+	opts := &bind.FilterOpts{Start: from, End: &to, Context: ctx}
+	txs := make(map[common.Hash]*l1Tx)
+	txOf := func(raw types.Log) *l1Tx {
+		tx, ok := txs[raw.TxHash]
+		if !ok {
+			tx = &l1Tx{hash: raw.TxHash, block: raw.BlockNumber, index: raw.TxIndex, in: new(big.Int), out: new(big.Int)}
+			txs[raw.TxHash] = tx
+		}
+		return tx
+	}
+
+	deposits, err := a.portal.FilterTransactionDeposited(opts, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter TransactionDeposited events in L1 blocks %d-%d: %w", from, to, err)
+	}
+	err = drain(deposits, func() error {
+		raw := deposits.Event.Raw
+		dep, err := derive.UnmarshalDepositLogEvent(&raw)
+		if err != nil {
+			return fmt.Errorf("invalid deposit in L1 transaction %s: %w", raw.TxHash, err)
+		}
+		if dep.Mint == nil {
+			return nil // the deposit does not move native tokens
+		}
+		tx := txOf(raw)
+		tx.deposits = append(tx.deposits, &Deposit{
+			L1TxHash: raw.TxHash,
+			L1Block:  raw.BlockNumber,
+			L2TxHash: types.NewTx(dep).Hash(),
+			Mint:     dep.Mint,
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read TransactionDeposited events in L1 blocks %d-%d: %w", from, to, err)
+	}
+
+	finalized, err := a.portal.FilterWithdrawalFinalized(opts, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter WithdrawalFinalized events in L1 blocks %d-%d: %w", from, to, err)
+	}
+	err = drain(finalized, func() error {
+		tx := txOf(finalized.Event.Raw)
+		tx.finalized = append(tx.finalized, common.Hash(finalized.Event.WithdrawalHash))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read WithdrawalFinalized events in L1 blocks %d-%d: %w", from, to, err)
+	}
+
+	in, err := a.tokenEvents.FilterTransfer(opts, nil, []common.Address{a.portalAddr})
+	if err != nil {
+		return fmt.Errorf("failed to filter transfers to the portal in L1 blocks %d-%d: %w", from, to, err)
+	}
+	err = drain(in, func() error {
+		tx := txOf(in.Event.Raw)
+		tx.in.Add(tx.in, in.Event.Value)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read transfers to the portal in L1 blocks %d-%d: %w", from, to, err)
+	}
+
+	out, err := a.tokenEvents.FilterTransfer(opts, []common.Address{a.portalAddr}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter transfers from the portal in L1 blocks %d-%d: %w", from, to, err)
+	}
+	err = drain(out, func() error {
+		tx := txOf(out.Event.Raw)
+		tx.out.Add(tx.out, out.Event.Value)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read transfers from the portal in L1 blocks %d-%d: %w", from, to, err)
+	}
+
+	sorted := make([]*l1Tx, 0, len(txs))
+	for _, tx := range txs {
+		sorted = append(sorted, tx)
+	}
+	slices.SortFunc(sorted, func(x, y *l1Tx) int {
+		return cmp.Or(cmp.Compare(x.block, y.block), cmp.Compare(x.index, y.index), x.hash.Cmp(y.hash))
+	})
+	for _, tx := range sorted {
+		a.addL1Tx(tx)
+	}
+	return nil
+}
+
+func (a *Auditor) addL1Tx(tx *l1Tx) {
+	a.transferred.Add(a.transferred, tx.in)
+	a.transferred.Sub(a.transferred, tx.out)
+
+	mint := new(big.Int)
+	for _, d := range tx.deposits {
+		mint.Add(mint, d.Mint)
+	}
+	a.deposited.Add(a.deposited, mint)
+	a.unminted = append(a.unminted, tx.deposits...)
+	if len(tx.deposits) > 0 && tx.in.Cmp(mint) != 0 {
+		a.addDiscrepancy(KindDepositMismatch, tx.hash, tx.block, new(big.Int).Sub(tx.in, mint),
+			"transferred %v tokens to the portal for deposits that mint %v", tx.in, mint)
+	} else if len(tx.deposits) == 0 && tx.in.Sign() > 0 {
+		a.addDiscrepancy(KindDonation, tx.hash, tx.block, new(big.Int).Set(tx.in),
+			"transferred %v tokens to the portal without a deposit", tx.in)
+	}
+
+	if len(tx.finalized) == 0 {
+		if tx.out.Sign() > 0 {
+			a.addDiscrepancy(KindUnexpectedTransfer, tx.hash, tx.block, new(big.Int).Neg(tx.out),
+				"transferred %v tokens out of the portal without finalizing a withdrawal", tx.out)
+		}
+		return
+	}
+	f := &finalization{hash: tx.hash, block: tx.block, out: tx.out, value: new(big.Int)}
+	for _, hash := range tx.finalized {
+		w, ok := a.pending[hash]
+		if !ok {
+			f.unknown = append(f.unknown, hash)
+			a.unknown[hash] = f
+			continue
+		}
+		delete(a.pending, hash)
+		f.value.Add(f.value, w.Value)
+		a.finalized.Add(a.finalized, w.Value)
+	}
+	if len(f.unknown) > 0 {
+		a.unresolved = append(a.unresolved, f)
+		return
+	}
+	a.resolve(f)
+}
+
+func (a *Auditor) addWithdrawal(w *withdrawals.Withdrawal) {
+	a.withdrawn.Add(a.withdrawn, w.Value)
+	f, ok := a.unknown[w.Hash]
+	if !ok {
+		a.pending[w.Hash] = w
+		return
+	}
+	delete(a.unknown, w.Hash)
+	f.value.Add(f.value, w.Value)
+	a.finalized.Add(a.finalized, w.Value)
+	f.unknown = slices.DeleteFunc(f.unknown, func(hash common.Hash) bool { return hash == w.Hash })
+	if len(f.unknown) == 0 {
+		a.unresolved = slices.DeleteFunc(a.unresolved, func(u *finalization) bool { return u == f })
+		a.resolve(f)
+	}
+}
+
+// resolve compares the tokens that a finalization transferred out of the portal with the value of its withdrawals.
+func (a *Auditor) resolve(f *finalization) {
+	switch diff := new(big.Int).Sub(f.value, f.out); diff.Sign() {
+	case 1:
+		a.addDiscrepancy(KindStrandedWithdrawal, f.hash, f.block, diff,
+			"finalized withdrawals of %v tokens, but transferred %v tokens out of the portal", f.value, f.out)
+	case -1:
+		a.addDiscrepancy(KindOverpaidWithdrawal, f.hash, f.block, diff,
+			"finalized withdrawals of %v tokens, but transferred %v tokens out of the portal", f.value, f.out)
+	}
+}
+
+func (a *Auditor) addDiscrepancy(kind Kind, txHash common.Hash, block uint64, amount *big.Int, format string, args ...any) {
+	d := &Discrepancy{Kind: kind, TxHash: txHash, Block: block, Amount: amount, Detail: fmt.Sprintf(format, args...)}
+	a.log.Warn("Found native token discrepancy", "kind", kind, "tx", txHash, "block", block, "amount", amount)
+	a.discrepancies = append(a.discrepancies, d)
+}
+
+// mint finds the deposits of L1 blocks up to origin that are included on L2 up to l2End.
+// Deposits of later L1 blocks cannot be included yet, so they are not looked up.
+func (a *Auditor) mint(ctx context.Context, origin, l2End uint64) error {
+	var unminted []*Deposit
+	for i, d := range a.unminted {
+		if d.L1Block > origin {
+			unminted = append(unminted, d)
+			continue
+		}
+		receipt, err := a.l2.TransactionReceipt(ctx, d.L2TxHash)
+		if errors.Is(err, ethereum.NotFound) || (err == nil && receipt.BlockNumber.Uint64() > l2End) {
+			unminted = append(unminted, d)
+			continue
+		}
+		if err != nil {
+			a.unminted = append(unminted, a.unminted[i:]...)
+			return fmt.Errorf("failed to get receipt of deposit %s: %w", d.L2TxHash, err)
+		}
+		a.minted.Add(a.minted, d.Mint)
+	}
+	a.unminted = unminted
+	return nil
+}
+
+func (a *Auditor) report(l1End, l2End, origin uint64, balance *big.Int) *Report {
+	r := &Report{
+		Portal:        a.portalAddr,
+		NativeToken:   a.tokenAddr,
+		L1Block:       l1End,
+		L2Block:       l2End,
+		L1Origin:      origin,
+		PortalBalance: balance,
+		Deposited:     new(big.Int).Set(a.deposited),
+		Minted:        new(big.Int).Set(a.minted),
+		InFlight:      new(big.Int).Sub(a.deposited, a.minted),
+		Withdrawn:     new(big.Int).Set(a.withdrawn),
+		Finalized:     new(big.Int).Set(a.finalized),
+		Pending:       new(big.Int).Sub(a.withdrawn, a.finalized),
+		Issuance:      new(big.Int).Sub(a.minted, a.withdrawn),
+
+		InFlightDeposits: slices.Clone(a.unminted),
+		Discrepancies:    slices.Clone(a.discrepancies),
+	}
+	r.Unaccounted = new(big.Int).Sub(balance, r.Issuance)
+	r.Unaccounted.Sub(r.Unaccounted, r.Pending)
+	r.Unaccounted.Sub(r.Unaccounted, r.InFlight)
+
+	for _, f := range a.unresolved {
+		r.Discrepancies = append(r.Discrepancies, &Discrepancy{
+			Kind:   KindUnknownWithdrawal,
+			TxHash: f.hash,
+			Block:  f.block,
+			Amount: new(big.Int).Sub(f.value, f.out),
+			Detail: fmt.Sprintf("finalized withdrawals %v that are not initiated on L2 up to block %d", f.unknown, l2End),
+		})
+	}
+	for _, d := range a.unminted {
+		if d.L1Block > origin {
+			continue
+		}
+		r.Discrepancies = append(r.Discrepancies, &Discrepancy{
+			Kind:   KindMissingDeposit,
+			TxHash: d.L1TxHash,
+			Block:  d.L1Block,
+			Amount: new(big.Int).Set(d.Mint),
+			Detail: fmt.Sprintf("deposit %s is not included on L2 up to block %d, which has L1 origin %d", d.L2TxHash, l2End, origin),
+		})
+	}
+	if diff := new(big.Int).Sub(balance, a.transferred); diff.Sign() != 0 {
+		r.Discrepancies = append(r.Discrepancies, &Discrepancy{
+			Kind:   KindBalanceMismatch,
+			Amount: diff,
+			Detail: fmt.Sprintf("portal balance is %v, but %v tokens were transferred to and from it", balance, a.transferred),
+		})
+	}
+	return r
+}
@@ -0,0 +1,325 @@
+package nativetoken
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bindings/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+var (
+	portalAddr = common.Address{0xaa}
+	tokenAddr  = common.Address{0xbb}
+	userAddr   = common.Address{0x01}
+)
+
+// stubChain serves the contract calls, logs and receipts of a chain.
+type stubChain struct {
+	t        *testing.T
+	abis     map[common.Address]*abi.ABI
+	results  map[common.Address]map[string]any
+	logs     []types.Log
+	receipts map[common.Hash]*types.Receipt
+}
+
+func newStubChain(t *testing.T) *stubChain {
+	return &stubChain{
+		t:        t,
+		abis:     make(map[common.Address]*abi.ABI),
+		results:  make(map[common.Address]map[string]any),
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+}
+
+func (s *stubChain) setResult(addr common.Address, contract *abi.ABI, method string, result any) {
+	s.abis[addr] = contract
+	if s.results[addr] == nil {
+		s.results[addr] = make(map[string]any)
+	}
+	s.results[addr][method] = result
+}
+
+func (s *stubChain) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func (s *stubChain) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	contract, ok := s.abis[*call.To]
+	require.True(s.t, ok, "unexpected call to %s", call.To)
+	method, err := contract.MethodById(call.Data[:4])
+	require.NoError(s.t, err)
+	result, ok := s.results[*call.To][method.Name]
+	require.True(s.t, ok, "unexpected call of %s", method.Name)
+	return method.Outputs.Pack(result)
+}
+
+func (s *stubChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var out []types.Log
+	for _, l := range s.logs {
+		if l.BlockNumber < q.FromBlock.Uint64() || l.BlockNumber > q.ToBlock.Uint64() {
+			continue
+		}
+		if len(q.Addresses) > 0 && !slices.Contains(q.Addresses, l.Address) {
+			continue
+		}
+		if matchTopics(q.Topics, l.Topics) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func matchTopics(query [][]common.Hash, topics []common.Hash) bool {
+	for i, alternatives := range query {
+		if len(alternatives) == 0 {
+			continue
+		}
+		if i >= len(topics) || !slices.Contains(alternatives, topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *stubChain) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("subscriptions are not supported")
+}
+
+func (s *stubChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, ok := s.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func loadABI(t *testing.T, metadata interface{ GetAbi() (*abi.ABI, error) }) *abi.ABI {
+	contract, err := metadata.GetAbi()
+	require.NoError(t, err)
+	return contract
+}
+
+type auditTest struct {
+	t          *testing.T
+	l1         *stubChain
+	l2         *stubChain
+	portalABI  *abi.ABI
+	erc20ABI   *abi.ABI
+	l1BlockABI *abi.ABI
+	passerABI  *abi.ABI
+}
+
+func newAuditTest(t *testing.T) *auditTest {
+	test := &auditTest{
+		t:          t,
+		l1:         newStubChain(t),
+		l2:         newStubChain(t),
+		portalABI:  loadABI(t, bindings.OptimismPortalMetaData),
+		erc20ABI:   loadABI(t, bindings.ERC20MetaData),
+		l1BlockABI: loadABI(t, bindings.L1BlockMetaData),
+		passerABI:  loadABI(t, bindings.L2ToL1MessagePasserMetaData),
+	}
+	test.l1.setResult(portalAddr, test.portalABI, "nativeTokenAddress", tokenAddr)
+	return test
+}
+
+func (test *auditTest) setBalance(balance int64) {
+	test.l1.setResult(tokenAddr, test.erc20ABI, "balanceOf", big.NewInt(balance))
+}
+
+func (test *auditTest) setOrigin(origin uint64) {
+	test.l2.setResult(predeploys.L1BlockAddr, test.l1BlockABI, "number", origin)
+}
+
+func (test *auditTest) addL1Log(txHash common.Hash, block uint64, l *types.Log) {
+	l.TxHash = txHash
+	l.BlockNumber = block
+	l.BlockHash = common.BigToHash(new(big.Int).SetUint64(block))
+	l.Index = uint(len(test.l1.logs))
+	test.l1.logs = append(test.l1.logs, *l)
+}
+
+// deposit adds a deposit that mints the amount, and returns its L2 transaction hash.
+func (test *auditTest) deposit(txHash common.Hash, block uint64, mint int64) common.Hash {
+	l, err := derive.MarshalDepositLogEvent(portalAddr, &types.DepositTx{
+		From: userAddr,
+		To:   &userAddr,
+		Mint: big.NewInt(mint),
+		Gas:  100_000,
+	})
+	require.NoError(test.t, err)
+	test.addL1Log(txHash, block, l)
+	dep, err := derive.UnmarshalDepositLogEvent(&test.l1.logs[len(test.l1.logs)-1])
+	require.NoError(test.t, err)
+	return types.NewTx(dep).Hash()
+}
+
+func (test *auditTest) transfer(txHash common.Hash, block uint64, from, to common.Address, value int64) {
+	ev := test.erc20ABI.Events["Transfer"]
+	data, err := ev.Inputs.NonIndexed().Pack(big.NewInt(value))
+	require.NoError(test.t, err)
+	test.addL1Log(txHash, block, &types.Log{
+		Address: tokenAddr,
+		Topics:  []common.Hash{ev.ID, eth.AddressAsLeftPaddedHash(from), eth.AddressAsLeftPaddedHash(to)},
+		Data:    data,
+	})
+}
+
+func (test *auditTest) finalize(txHash common.Hash, block uint64, withdrawalHash common.Hash) {
+	ev := test.portalABI.Events["WithdrawalFinalized"]
+	data, err := ev.Inputs.NonIndexed().Pack(true)
+	require.NoError(test.t, err)
+	test.addL1Log(txHash, block, &types.Log{
+		Address: portalAddr,
+		Topics:  []common.Hash{ev.ID, withdrawalHash},
+		Data:    data,
+	})
+}
+
+// withdraw adds a withdrawal of the value on L2, and returns its withdrawal hash.
+func (test *auditTest) withdraw(block uint64, value int64) common.Hash {
+	nonce := big.NewInt(int64(len(test.l2.logs)))
+	withdrawalHash := crypto.Keccak256Hash(nonce.Bytes())
+	ev := test.passerABI.Events["MessagePassed"]
+	data, err := ev.Inputs.NonIndexed().Pack(big.NewInt(value), big.NewInt(100_000), []byte{}, withdrawalHash)
+	require.NoError(test.t, err)
+	test.l2.logs = append(test.l2.logs, types.Log{
+		Address:     predeploys.L2ToL1MessagePasserAddr,
+		Topics:      []common.Hash{ev.ID, common.BigToHash(nonce), eth.AddressAsLeftPaddedHash(userAddr), eth.AddressAsLeftPaddedHash(userAddr)},
+		Data:        data,
+		BlockNumber: block,
+		TxHash:      crypto.Keccak256Hash(withdrawalHash[:]),
+	})
+	return withdrawalHash
+}
+
+func (test *auditTest) include(l2TxHash common.Hash, block uint64) {
+	test.l2.receipts[l2TxHash] = &types.Receipt{TxHash: l2TxHash, BlockNumber: new(big.Int).SetUint64(block)}
+}
+
+func requireDiscrepancies(t *testing.T, r *Report, expected ...*Discrepancy) {
+	require.Len(t, r.Discrepancies, len(expected))
+	unaccounted := new(big.Int)
+	for i, d := range r.Discrepancies {
+		require.Equal(t, expected[i].Kind, d.Kind, "discrepancy %d", i)
+		require.Equal(t, expected[i].TxHash, d.TxHash, "discrepancy %d", i)
+		require.Zero(t, expected[i].Amount.Cmp(d.Amount), "discrepancy %d has amount %v", i, d.Amount)
+		if d.Kind != KindMissingDeposit {
+			unaccounted.Add(unaccounted, d.Amount)
+		}
+	}
+	require.Zero(t, unaccounted.Cmp(r.Unaccounted), "discrepancies must explain the unaccounted tokens")
+}
+
+func requireAmount(t *testing.T, expected int64, actual *big.Int) {
+	require.Zero(t, big.NewInt(expected).Cmp(actual), "expected %d, got %v", expected, actual)
+}
+
+func TestAuditor(t *testing.T) {
+	test := newAuditTest(t)
+	w1 := test.withdraw(5, 30)
+	w2 := test.withdraw(6, 20)
+	w3 := test.withdraw(20, 10)
+	test.withdraw(22, 8)
+
+	d1, d2, donation := common.Hash{0xd1}, common.Hash{0xd2}, common.Hash{0xd0}
+	f1, f2, f3 := common.Hash{0xf1}, common.Hash{0xf2}, common.Hash{0xf3}
+	d3, drain := common.Hash{0xd3}, common.Hash{0xee}
+	test.include(test.deposit(d1, 1, 100), 2)
+	test.transfer(d1, 1, userAddr, portalAddr, 100)
+	test.include(test.deposit(d2, 2, 50), 3)
+	test.transfer(d2, 2, userAddr, portalAddr, 49)
+	test.transfer(donation, 3, userAddr, portalAddr, 5)
+	test.finalize(f1, 10, w1)
+	test.transfer(f1, 10, portalAddr, userAddr, 30)
+	// The call of the withdrawal failed, its value stays in the portal.
+	test.finalize(f2, 11, w2)
+	test.finalize(f3, 12, w3)
+	test.transfer(f3, 12, portalAddr, userAddr, 10)
+	test.deposit(d3, 13, 7)
+	test.transfer(d3, 13, userAddr, portalAddr, 7)
+	test.transfer(drain, 14, portalAddr, userAddr, 3)
+
+	auditor, err := NewAuditor(context.Background(), testlog.Logger(t, log.LevelInfo), test.l1, test.l2, portalAddr, 0, 5)
+	require.NoError(t, err)
+	require.Equal(t, tokenAddr, auditor.NativeToken())
+
+	t.Run("withdrawal not found yet", func(t *testing.T) {
+		test.setOrigin(5)
+		test.setBalance(119)
+		r, err := auditor.Update(context.Background(), 15, 10)
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), r.L1Origin)
+		requireAmount(t, 119, r.PortalBalance)
+		requireAmount(t, 157, r.Deposited)
+		requireAmount(t, 150, r.Minted)
+		requireAmount(t, 7, r.InFlight)
+		requireAmount(t, 50, r.Withdrawn)
+		requireAmount(t, 50, r.Finalized)
+		requireAmount(t, 0, r.Pending)
+		requireAmount(t, 100, r.Issuance)
+		requireAmount(t, 12, r.Unaccounted)
+		require.Len(t, r.InFlightDeposits, 1)
+		require.Equal(t, d3, r.InFlightDeposits[0].L1TxHash)
+		requireDiscrepancies(t, r,
+			&Discrepancy{Kind: KindDepositMismatch, TxHash: d2, Amount: big.NewInt(-1)},
+			&Discrepancy{Kind: KindDonation, TxHash: donation, Amount: big.NewInt(5)},
+			&Discrepancy{Kind: KindStrandedWithdrawal, TxHash: f2, Amount: big.NewInt(20)},
+			&Discrepancy{Kind: KindUnexpectedTransfer, TxHash: drain, Amount: big.NewInt(-3)},
+			&Discrepancy{Kind: KindUnknownWithdrawal, TxHash: f3, Amount: big.NewInt(-10)},
+			&Discrepancy{Kind: KindBalanceMismatch, Amount: big.NewInt(1)},
+		)
+	})
+
+	t.Run("withdrawal found and deposit missing", func(t *testing.T) {
+		test.setOrigin(14)
+		test.setBalance(118)
+		r, err := auditor.Update(context.Background(), 15, 25)
+		require.NoError(t, err)
+		requireAmount(t, 150, r.Minted)
+		requireAmount(t, 7, r.InFlight)
+		requireAmount(t, 68, r.Withdrawn)
+		requireAmount(t, 60, r.Finalized)
+		requireAmount(t, 8, r.Pending)
+		requireAmount(t, 82, r.Issuance)
+		requireAmount(t, 21, r.Unaccounted)
+		requireDiscrepancies(t, r,
+			&Discrepancy{Kind: KindDepositMismatch, TxHash: d2, Amount: big.NewInt(-1)},
+			&Discrepancy{Kind: KindDonation, TxHash: donation, Amount: big.NewInt(5)},
+			&Discrepancy{Kind: KindStrandedWithdrawal, TxHash: f2, Amount: big.NewInt(20)},
+			&Discrepancy{Kind: KindUnexpectedTransfer, TxHash: drain, Amount: big.NewInt(-3)},
+			&Discrepancy{Kind: KindMissingDeposit, TxHash: d3, Amount: big.NewInt(7)},
+		)
+	})
+
+	t.Run("cannot go back", func(t *testing.T) {
+		_, err := auditor.Update(context.Background(), 14, 25)
+		require.ErrorContains(t, err, "next blocks to audit are L1 block 16 and L2 block 26")
+	})
+}
+
+func TestAuditorOpeningBalance(t *testing.T) {
+	test := newAuditTest(t)
+	test.setBalance(1)
+	_, err := NewAuditor(context.Background(), testlog.Logger(t, log.LevelInfo), test.l1, test.l2, portalAddr, 5, 100)
+	require.ErrorContains(t, err, "portal holds 1 native tokens before L1 block 5")
+
+	test.setBalance(0)
+	_, err = NewAuditor(context.Background(), testlog.Logger(t, log.LevelInfo), test.l1, test.l2, portalAddr, 5, 100)
+	require.NoError(t, err)
+}
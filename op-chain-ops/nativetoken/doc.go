@@ -0,0 +1,15 @@
+// Package nativetoken audits the supply of the native token of a Thanos chain,
+// the ERC-20 token that is locked in the OptimismPortal on L1 and minted as the
+// gas paying coin on L2.
+//
+// The portal balance is reconciled with the tokens that back the L2 supply:
+//
+//	portal balance = L2 issuance + pending withdrawals + deposits in flight
+//
+// Deposits are read from the TransactionDeposited events of the portal, and are
+// matched to their deposit transactions on L2. Withdrawals are read from the
+// MessagePassed events of the L2ToL1MessagePasser, and are matched to the
+// WithdrawalFinalized events of the portal. The Transfer events of the native
+// token in the same L1 transactions tell how many tokens actually moved, so that
+// each discrepancy is attributed to the transaction that caused it.
+package nativetoken
@@ -0,0 +1,179 @@
+// Package rollupcheck cross-checks the copies of the rollup config of a chain:
+// the rollup.json produced by tokamak-deployer, the config embedded into
+// op-program by its chainconfig package, and the config loaded by op-node.
+// The configs are also checked against the L2 genesis and the state of the
+// L1 contracts, as a mismatch otherwise only shows up as a derivation or
+// fault proof failure.
+package rollupcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
+)
+
+// unset is the value of fields that are not set.
+const unset = "<unset>"
+
+// Difference is a field of a source whose value differs from the expected value.
+type Difference struct {
+	// Field is the JSON path of the field in the rollup config, e.g. genesis.l2.hash,
+	// or the name of the deploy config value for values not in the rollup config.
+	Field    string `json:"field"`
+	Source   string `json:"source"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s is %s, expected %s", d.Source, d.Field, d.Actual, d.Expected)
+}
+
+// CompareConfigs reports the fields of the actual rollup config of the source
+// that differ from the expected rollup config.
+func CompareConfigs(expected, actual *rollup.Config, source string) ([]Difference, error) {
+	expectedFields, err := fields(expected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode expected rollup config: %w", err)
+	}
+	actualFields, err := fields(actual)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rollup config of %s: %w", source, err)
+	}
+	names := slices.Collect(maps.Keys(expectedFields))
+	for name := range actualFields {
+		if _, ok := expectedFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var diffs []Difference
+	for _, name := range names {
+		exp, ok := expectedFields[name]
+		if !ok {
+			exp = unset
+		}
+		act, ok := actualFields[name]
+		if !ok {
+			act = unset
+		}
+		if exp != act {
+			diffs = append(diffs, Difference{Field: name, Source: source, Expected: exp, Actual: act})
+		}
+	}
+	return diffs, nil
+}
+
+// fields flattens the JSON encoding of the rollup config into its leaf values, by JSON path.
+func fields(cfg *rollup.Config) (map[string]string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree map[string]any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	if err := flatten("", tree, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func flatten(path string, value any, out map[string]string) error {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			if err := flatten(childPath, child, out); err != nil {
+				return err
+			}
+		}
+	case nil:
+		// null values are the same as omitted ones
+	case string:
+		out[path] = v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		out[path] = string(data)
+	}
+	return nil
+}
+
+// CompareChainConfig reports the fields of the rollup config that differ from the L2 chain config of the source.
+// Only the chain ID, the OP Stack fork times and the OP Stack parameters are compared.
+func CompareChainConfig(cfg *rollup.Config, chainCfg *params.ChainConfig, source string) []Difference {
+	var diffs []Difference
+	check := func(field, expected, actual string) {
+		if expected != actual {
+			diffs = append(diffs, Difference{Field: field, Source: source, Expected: expected, Actual: actual})
+		}
+	}
+	check("l2_chain_id", cfg.L2ChainID.String(), chainCfg.ChainID.String())
+	check("regolith_time", timeString(cfg.RegolithTime), timeString(chainCfg.RegolithTime))
+	check("canyon_time", timeString(cfg.CanyonTime), timeString(chainCfg.CanyonTime))
+	check("ecotone_time", timeString(cfg.EcotoneTime), timeString(chainCfg.EcotoneTime))
+	check("fjord_time", timeString(cfg.FjordTime), timeString(chainCfg.FjordTime))
+	check("interop_time", timeString(cfg.InteropTime), timeString(chainCfg.InteropTime))
+	if cfg.ChainOpConfig != nil {
+		check("chain_op_config", jsonString(cfg.ChainOpConfig), jsonString(chainCfg.Optimism))
+	}
+	return diffs
+}
+
+// CompareGenesis reports the fields of the rollup config that differ from the L2 genesis of the source.
+func CompareGenesis(cfg *rollup.Config, genesis *core.Genesis, source string) []Difference {
+	var diffs []Difference
+	block := genesis.ToBlock()
+	if hash := block.Hash(); hash != cfg.Genesis.L2.Hash {
+		diffs = append(diffs, Difference{Field: "genesis.l2.hash", Source: source, Expected: cfg.Genesis.L2.Hash.Hex(), Actual: hash.Hex()})
+	}
+	if number := block.NumberU64(); number != cfg.Genesis.L2.Number {
+		diffs = append(diffs, Difference{Field: "genesis.l2.number", Source: source,
+			Expected: strconv.FormatUint(cfg.Genesis.L2.Number, 10), Actual: strconv.FormatUint(number, 10)})
+	}
+	if genesis.Timestamp != cfg.Genesis.L2Time {
+		diffs = append(diffs, Difference{Field: "genesis.l2_time", Source: source,
+			Expected: strconv.FormatUint(cfg.Genesis.L2Time, 10), Actual: strconv.FormatUint(genesis.Timestamp, 10)})
+	}
+	if genesis.Config != nil {
+		diffs = append(diffs, CompareChainConfig(cfg, genesis.Config, source)...)
+	}
+	return diffs
+}
+
+func timeString(t *uint64) string {
+	if t == nil {
+		return unset
+	}
+	return strconv.FormatUint(*t, 10)
+}
+
+func jsonString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	if string(data) == "null" {
+		return unset
+	}
+	return string(data)
+}
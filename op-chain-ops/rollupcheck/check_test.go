@@ -0,0 +1,120 @@
+package rollupcheck
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+func u64(v uint64) *uint64 {
+	return &v
+}
+
+func testConfig() *rollup.Config {
+	return &rollup.Config{
+		Genesis: rollup.Genesis{
+			L1:     eth.BlockID{Hash: common.Hash{0x01}, Number: 100},
+			L2:     eth.BlockID{Hash: common.Hash{0x02}, Number: 0},
+			L2Time: 1700000000,
+			SystemConfig: eth.SystemConfig{
+				BatcherAddr: common.Address{0x03},
+				Overhead:    eth.Bytes32{31: 0xbc},
+				Scalar:      eth.Bytes32{31: 0x0a},
+				GasLimit:    30_000_000,
+			},
+		},
+		BlockTime:              2,
+		MaxSequencerDrift:      600,
+		SeqWindowSize:          3600,
+		ChannelTimeoutBedrock:  300,
+		L1ChainID:              big.NewInt(11155111),
+		L2ChainID:              big.NewInt(111551119090),
+		RegolithTime:           u64(0),
+		CanyonTime:             u64(0),
+		EcotoneTime:            u64(10),
+		BatchInboxAddress:      common.Address{0x04},
+		DepositContractAddress: common.Address{0x05},
+		L1SystemConfigAddress:  common.Address{0x06},
+		ChainOpConfig: &params.OptimismConfig{
+			EIP1559Elasticity:        6,
+			EIP1559Denominator:       50,
+			EIP1559DenominatorCanyon: 250,
+		},
+	}
+}
+
+func TestCompareConfigs(t *testing.T) {
+	t.Run("Equal", func(t *testing.T) {
+		diffs, err := CompareConfigs(testConfig(), testConfig(), "op-node")
+		require.NoError(t, err)
+		require.Empty(t, diffs)
+	})
+
+	t.Run("Differences", func(t *testing.T) {
+		actual := testConfig()
+		actual.Genesis.L2.Hash = common.Hash{0xff}
+		actual.EcotoneTime = nil
+		actual.FjordTime = u64(20)
+		actual.Genesis.SystemConfig.GasLimit = 60_000_000
+		diffs, err := CompareConfigs(testConfig(), actual, "op-node")
+		require.NoError(t, err)
+		require.Equal(t, []Difference{
+			{Field: "ecotone_time", Source: "op-node", Expected: "10", Actual: unset},
+			{Field: "fjord_time", Source: "op-node", Expected: unset, Actual: "20"},
+			{Field: "genesis.l2.hash", Source: "op-node", Expected: common.Hash{0x02}.Hex(), Actual: common.Hash{0xff}.Hex()},
+			{Field: "genesis.system_config.gasLimit", Source: "op-node", Expected: "30000000", Actual: "60000000"},
+		}, diffs)
+	})
+}
+
+func testChainConfig(cfg *rollup.Config) *params.ChainConfig {
+	return &params.ChainConfig{
+		ChainID:      cfg.L2ChainID,
+		RegolithTime: cfg.RegolithTime,
+		CanyonTime:   cfg.CanyonTime,
+		EcotoneTime:  cfg.EcotoneTime,
+		Optimism:     cfg.ChainOpConfig,
+	}
+}
+
+func TestCompareChainConfig(t *testing.T) {
+	cfg := testConfig()
+	require.Empty(t, CompareChainConfig(cfg, testChainConfig(cfg), "op-program"))
+
+	chainCfg := testChainConfig(cfg)
+	chainCfg.ChainID = big.NewInt(901)
+	chainCfg.EcotoneTime = u64(11)
+	chainCfg.Optimism = &params.OptimismConfig{EIP1559Elasticity: 6, EIP1559Denominator: 50}
+	diffs := CompareChainConfig(cfg, chainCfg, "op-program")
+	require.Len(t, diffs, 3)
+	require.Equal(t, Difference{Field: "l2_chain_id", Source: "op-program", Expected: "111551119090", Actual: "901"}, diffs[0])
+	require.Equal(t, Difference{Field: "ecotone_time", Source: "op-program", Expected: "10", Actual: "11"}, diffs[1])
+	require.Equal(t, "chain_op_config", diffs[2].Field)
+}
+
+func TestCompareGenesis(t *testing.T) {
+	cfg := testConfig()
+	genesis := &core.Genesis{
+		Config:    testChainConfig(cfg),
+		Timestamp: cfg.Genesis.L2Time,
+		GasLimit:  cfg.Genesis.SystemConfig.GasLimit,
+		Alloc:     types.GenesisAlloc{},
+	}
+	cfg.Genesis.L2.Hash = genesis.ToBlock().Hash()
+	require.Empty(t, CompareGenesis(cfg, genesis, "L2 genesis"))
+
+	genesis.Timestamp++
+	diffs := CompareGenesis(cfg, genesis, "L2 genesis")
+	require.Len(t, diffs, 2)
+	require.Equal(t, "genesis.l2.hash", diffs[0].Field)
+	require.Equal(t, genesis.ToBlock().Hash().Hex(), diffs[0].Actual)
+	require.Equal(t, Difference{Field: "genesis.l2_time", Source: "L2 genesis", Expected: "1700000000", Actual: "1700000001"}, diffs[1])
+}
@@ -0,0 +1,161 @@
+package rollupcheck
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bindings/bindings"
+	bindingspreview "github.com/tokamak-network/tokamak-thanos/op-node/bindings/preview"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources/batching"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources/batching/rpcblock"
+	"github.com/tokamak-network/tokamak-thanos/packages/tokamak/contracts-bedrock/snapshots"
+)
+
+const (
+	methodBatchInbox         = "batchInbox"
+	methodOptimismPortal     = "optimismPortal"
+	methodStartBlock         = "startBlock"
+	methodNativeTokenAddress = "nativeTokenAddress"
+	methodDisputeGameFactory = "disputeGameFactory"
+	methodBatcherHash        = "batcherHash"
+	methodGasLimit           = "gasLimit"
+	methodOverhead           = "overhead"
+	methodScalar             = "scalar"
+	methodRespectedGameType  = "respectedGameType"
+	methodGameImpls          = "gameImpls"
+	methodAbsolutePrestate   = "absolutePrestate"
+)
+
+const (
+	SourceL1                 = "L1"
+	SourceL2                 = "L2"
+	SourceSystemConfig       = "SystemConfig"
+	SourceDisputeGameFactory = "DisputeGameFactory"
+)
+
+// HeaderSource is the subset of the ethclient used to read the genesis blocks.
+type HeaderSource interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Expectations are the values that the L1 contracts are checked against, that are not part of the rollup config.
+// Values that are not set are not checked.
+type Expectations struct {
+	NativeToken *common.Address
+	// Prestate is the absolute prestate of op-program, that the dispute games of the respected game type must use.
+	Prestate *common.Hash
+}
+
+// CheckL1 reports the fields of the rollup config that differ from the L1 chain and its SystemConfig contract,
+// and the native token and fault proof prestate that differ from the expectations. The genesis system config
+// is read at the L1 genesis block, which requires the L1 node to serve the state of that block.
+func CheckL1(ctx context.Context, headers HeaderSource, caller *batching.MultiCaller, cfg *rollup.Config, exp Expectations) ([]Difference, error) {
+	var diffs []Difference
+	check := func(field, source, expected, actual string) {
+		if expected != actual {
+			diffs = append(diffs, Difference{Field: field, Source: source, Expected: expected, Actual: actual})
+		}
+	}
+
+	header, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(cfg.Genesis.L1.Number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L1 genesis block %d: %w", cfg.Genesis.L1.Number, err)
+	}
+	check("genesis.l1.hash", SourceL1, cfg.Genesis.L1.Hash.Hex(), header.Hash().Hex())
+
+	systemConfigABI, err := bindings.SystemConfigMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SystemConfig ABI: %w", err)
+	}
+	systemConfig := batching.NewBoundContract(systemConfigABI, cfg.L1SystemConfigAddress)
+	results, err := caller.Call(ctx, rpcblock.Latest,
+		systemConfig.Call(methodBatchInbox),
+		systemConfig.Call(methodOptimismPortal),
+		systemConfig.Call(methodStartBlock),
+		systemConfig.Call(methodNativeTokenAddress),
+		systemConfig.Call(methodDisputeGameFactory))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SystemConfig %s: %w", cfg.L1SystemConfigAddress, err)
+	}
+	check("batch_inbox_address", SourceSystemConfig, cfg.BatchInboxAddress.Hex(), results[0].GetAddress(0).Hex())
+	check("deposit_contract_address", SourceSystemConfig, cfg.DepositContractAddress.Hex(), results[1].GetAddress(0).Hex())
+	check("genesis.l1.number", SourceSystemConfig, strconv.FormatUint(cfg.Genesis.L1.Number, 10), results[2].GetBigInt(0).String())
+	if exp.NativeToken != nil {
+		check("nativeTokenAddress", SourceSystemConfig, exp.NativeToken.Hex(), results[3].GetAddress(0).Hex())
+	}
+	factory := results[4].GetAddress(0)
+
+	results, err = caller.Call(ctx, rpcblock.ByNumber(cfg.Genesis.L1.Number),
+		systemConfig.Call(methodBatcherHash),
+		systemConfig.Call(methodGasLimit),
+		systemConfig.Call(methodOverhead),
+		systemConfig.Call(methodScalar))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SystemConfig at L1 genesis block %d: %w", cfg.Genesis.L1.Number, err)
+	}
+	sysCfg := cfg.Genesis.SystemConfig
+	batcherHash := results[0].GetHash(0)
+	check("genesis.system_config.batcherAddr", SourceSystemConfig, sysCfg.BatcherAddr.Hex(), common.BytesToAddress(batcherHash[:]).Hex())
+	check("genesis.system_config.gasLimit", SourceSystemConfig, strconv.FormatUint(sysCfg.GasLimit, 10), strconv.FormatUint(results[1].GetUint64(0), 10))
+	check("genesis.system_config.overhead", SourceSystemConfig, common.Hash(sysCfg.Overhead).Hex(), common.BigToHash(results[2].GetBigInt(0)).Hex())
+	check("genesis.system_config.scalar", SourceSystemConfig, common.Hash(sysCfg.Scalar).Hex(), common.BigToHash(results[3].GetBigInt(0)).Hex())
+
+	if exp.Prestate != nil {
+		if factory == (common.Address{}) {
+			check("faultGameAbsolutePrestate", SourceSystemConfig, exp.Prestate.Hex(), unset)
+		} else {
+			prestate, err := respectedPrestate(ctx, caller, cfg.DepositContractAddress, factory)
+			if err != nil {
+				return nil, err
+			}
+			check("faultGameAbsolutePrestate", SourceDisputeGameFactory, exp.Prestate.Hex(), prestate.Hex())
+		}
+	}
+	return diffs, nil
+}
+
+// respectedPrestate returns the absolute prestate of the dispute game type that the portal respects.
+func respectedPrestate(ctx context.Context, caller *batching.MultiCaller, portalAddr, factoryAddr common.Address) (common.Hash, error) {
+	portalABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to load OptimismPortal2 ABI: %w", err)
+	}
+	result, err := caller.SingleCall(ctx, rpcblock.Latest, batching.NewBoundContract(portalABI, portalAddr).Call(methodRespectedGameType))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get respected game type: %w", err)
+	}
+	gameType := result.GetUint32(0)
+	factory := batching.NewBoundContract(snapshots.LoadDisputeGameFactoryABI(), factoryAddr)
+	result, err = caller.SingleCall(ctx, rpcblock.Latest, factory.Call(methodGameImpls, gameType))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get implementation of game type %d: %w", gameType, err)
+	}
+	impl := result.GetAddress(0)
+	if impl == (common.Address{}) {
+		return common.Hash{}, fmt.Errorf("dispute game factory %s has no implementation of the respected game type %d", factoryAddr, gameType)
+	}
+	game := batching.NewBoundContract(snapshots.LoadFaultDisputeGameABI(), impl)
+	result, err = caller.SingleCall(ctx, rpcblock.Latest, game.Call(methodAbsolutePrestate))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get absolute prestate of game type %d: %w", gameType, err)
+	}
+	return result.GetHash(0), nil
+}
+
+// CheckL2 reports a genesis.l2.hash of the rollup config that differs from the genesis block of the L2 chain.
+func CheckL2(ctx context.Context, headers HeaderSource, cfg *rollup.Config) ([]Difference, error) {
+	header, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(cfg.Genesis.L2.Number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L2 genesis block %d: %w", cfg.Genesis.L2.Number, err)
+	}
+	if hash := header.Hash(); hash != cfg.Genesis.L2.Hash {
+		return []Difference{{Field: "genesis.l2.hash", Source: SourceL2, Expected: cfg.Genesis.L2.Hash.Hex(), Actual: hash.Hex()}}, nil
+	}
+	return nil, nil
+}
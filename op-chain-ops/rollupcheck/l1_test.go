@@ -0,0 +1,120 @@
+package rollupcheck
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/op-bindings/bindings"
+	bindingspreview "github.com/tokamak-network/tokamak-thanos/op-node/bindings/preview"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources/batching"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources/batching/rpcblock"
+	batchingTest "github.com/tokamak-network/tokamak-thanos/op-service/sources/batching/test"
+	"github.com/tokamak-network/tokamak-thanos/packages/tokamak/contracts-bedrock/snapshots"
+)
+
+var (
+	nativeTokenAddr = common.Address{0x07}
+	factoryAddr     = common.Address{0x08}
+	gameImplAddr    = common.Address{0x09}
+	prestate        = common.Hash{0x03, 0xab}
+)
+
+type stubHeaders map[uint64]*types.Header
+
+func (s stubHeaders) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := s[number.Uint64()]
+	if !ok {
+		return nil, fmt.Errorf("unknown block %v", number)
+	}
+	return header, nil
+}
+
+// setupL1 returns a config whose genesis L1 block is the block of the returned headers,
+// and a caller that serves a SystemConfig and dispute game factory matching the config.
+func setupL1(t *testing.T) (*rollup.Config, stubHeaders, *batching.MultiCaller) {
+	cfg := testConfig()
+	header := &types.Header{Number: new(big.Int).SetUint64(cfg.Genesis.L1.Number), Difficulty: common.Big0}
+	cfg.Genesis.L1.Hash = header.Hash()
+	headers := stubHeaders{cfg.Genesis.L1.Number: header}
+
+	systemConfigABI, err := bindings.SystemConfigMetaData.GetAbi()
+	require.NoError(t, err)
+	portalABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+	require.NoError(t, err)
+	stubRpc := batchingTest.NewAbiBasedRpc(t, cfg.L1SystemConfigAddress, systemConfigABI)
+	stubRpc.AddContract(cfg.DepositContractAddress, portalABI)
+	stubRpc.AddContract(factoryAddr, snapshots.LoadDisputeGameFactoryABI())
+	stubRpc.AddContract(gameImplAddr, snapshots.LoadFaultDisputeGameABI())
+
+	sysCfg := cfg.Genesis.SystemConfig
+	genesisBlock := rpcblock.ByNumber(cfg.Genesis.L1.Number)
+	stubRpc.SetResponse(cfg.L1SystemConfigAddress, methodBatchInbox, rpcblock.Latest, nil, []interface{}{cfg.BatchInboxAddress})
+	stubRpc.SetResponse(cfg.L1SystemConfigAddress, methodOptimismPortal, rpcblock.Latest, nil, []interface{}{cfg.DepositContractAddress})
+	stubRpc.SetResponse(cfg.L1SystemConfigAddress, methodStartBlock, rpcblock.Latest, nil, []interface{}{new(big.Int).SetUint64(cfg.Genesis.L1.Number)})
+	stubRpc.SetResponse(cfg.L1SystemConfigAddress, methodNativeTokenAddress, rpcblock.Latest, nil, []interface{}{nativeTokenAddr})
+	stubRpc.SetResponse(cfg.L1SystemConfigAddress, methodDisputeGameFactory, rpcblock.Latest, nil, []interface{}{factoryAddr})
+	stubRpc.SetResponse(cfg.L1SystemConfigAddress, methodBatcherHash, genesisBlock, nil, []interface{}{common.BytesToHash(sysCfg.BatcherAddr[:])})
+	stubRpc.SetResponse(cfg.L1SystemConfigAddress, methodGasLimit, genesisBlock, nil, []interface{}{sysCfg.GasLimit})
+	stubRpc.SetResponse(cfg.L1SystemConfigAddress, methodOverhead, genesisBlock, nil, []interface{}{new(big.Int).SetBytes(sysCfg.Overhead[:])})
+	stubRpc.SetResponse(cfg.L1SystemConfigAddress, methodScalar, genesisBlock, nil, []interface{}{new(big.Int).SetBytes(sysCfg.Scalar[:])})
+	stubRpc.SetResponse(cfg.DepositContractAddress, methodRespectedGameType, rpcblock.Latest, nil, []interface{}{uint32(1)})
+	stubRpc.SetResponse(factoryAddr, methodGameImpls, rpcblock.Latest, []interface{}{uint32(1)}, []interface{}{gameImplAddr})
+	stubRpc.SetResponse(gameImplAddr, methodAbsolutePrestate, rpcblock.Latest, nil, []interface{}{prestate})
+	return cfg, headers, batching.NewMultiCaller(stubRpc, batching.DefaultBatchSize)
+}
+
+func TestCheckL1(t *testing.T) {
+	t.Run("Match", func(t *testing.T) {
+		cfg, headers, caller := setupL1(t)
+		diffs, err := CheckL1(context.Background(), headers, caller, cfg, Expectations{NativeToken: &nativeTokenAddr, Prestate: &prestate})
+		require.NoError(t, err)
+		require.Empty(t, diffs)
+	})
+
+	t.Run("Differences", func(t *testing.T) {
+		cfg, headers, caller := setupL1(t)
+		expected := *cfg
+		expected.Genesis.L1.Hash = common.Hash{0xaa}
+		expected.BatchInboxAddress = common.Address{0xbb}
+		expected.Genesis.SystemConfig.GasLimit = 60_000_000
+		otherToken := common.Address{0xcc}
+		otherPrestate := common.Hash{0x03, 0xdd}
+		diffs, err := CheckL1(context.Background(), headers, caller, &expected, Expectations{NativeToken: &otherToken, Prestate: &otherPrestate})
+		require.NoError(t, err)
+		require.Equal(t, []Difference{
+			{Field: "genesis.l1.hash", Source: SourceL1, Expected: common.Hash{0xaa}.Hex(), Actual: cfg.Genesis.L1.Hash.Hex()},
+			{Field: "batch_inbox_address", Source: SourceSystemConfig, Expected: common.Address{0xbb}.Hex(), Actual: cfg.BatchInboxAddress.Hex()},
+			{Field: "nativeTokenAddress", Source: SourceSystemConfig, Expected: otherToken.Hex(), Actual: nativeTokenAddr.Hex()},
+			{Field: "genesis.system_config.gasLimit", Source: SourceSystemConfig, Expected: "60000000", Actual: "30000000"},
+			{Field: "faultGameAbsolutePrestate", Source: SourceDisputeGameFactory, Expected: otherPrestate.Hex(), Actual: prestate.Hex()},
+		}, diffs)
+	})
+
+	t.Run("MissingGenesisBlock", func(t *testing.T) {
+		cfg, _, caller := setupL1(t)
+		_, err := CheckL1(context.Background(), stubHeaders{}, caller, cfg, Expectations{})
+		require.ErrorContains(t, err, "failed to get L1 genesis block")
+	})
+}
+
+func TestCheckL2(t *testing.T) {
+	cfg := testConfig()
+	header := &types.Header{Number: new(big.Int).SetUint64(cfg.Genesis.L2.Number), Difficulty: common.Big0}
+	headers := stubHeaders{cfg.Genesis.L2.Number: header}
+
+	diffs, err := CheckL2(context.Background(), headers, cfg)
+	require.NoError(t, err)
+	require.Equal(t, []Difference{{Field: "genesis.l2.hash", Source: SourceL2, Expected: cfg.Genesis.L2.Hash.Hex(), Actual: header.Hash().Hex()}}, diffs)
+
+	cfg.Genesis.L2.Hash = header.Hash()
+	diffs, err = CheckL2(context.Background(), headers, cfg)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+}
@@ -108,30 +108,41 @@ func (c *CheatCodesPrecompile) ReadCallers() (callerMode *big.Int, msgSender com
 }
 
 // Record implements https://book.getfoundry.sh/cheatcodes/record
-func (c *CheatCodesPrecompile) Record() error {
-	panic("vm.record not supported")
+func (c *CheatCodesPrecompile) Record() {
+	c.h.accesses = newStorageAccesses()
 }
 
-// Accesses implements https://book.getfoundry.sh/cheatcodes/accesses
-func (c *CheatCodesPrecompile) Accesses() (reads [][32]byte, writes [][32]byte, err error) {
-	panic("vm.accesses not supported")
+// StopRecord implements https://book.getfoundry.sh/cheatcodes/stop-record
+func (c *CheatCodesPrecompile) StopRecord() {
+	c.h.accesses = nil
 }
 
-// RecordLogs implements https://book.getfoundry.sh/cheatcodes/record-logs
-func (c *CheatCodesPrecompile) RecordLogs() error {
-	panic("vm.recordLogs not supported")
+// Accesses implements https://book.getfoundry.sh/cheatcodes/accesses
+func (c *CheatCodesPrecompile) Accesses(target common.Address) (reads [][32]byte, writes [][32]byte) {
+	if c.h.accesses == nil {
+		return [][32]byte{}, [][32]byte{}
+	}
+	reads = append([][32]byte{}, c.h.accesses.reads[target]...)
+	writes = append([][32]byte{}, c.h.accesses.writes[target]...)
+	return reads, writes
 }
 
-type Log struct {
-	Topics  [][32]byte
-	Data    []byte
-	Emitter common.Address
+// RecordLogs implements https://book.getfoundry.sh/cheatcodes/record-logs
+func (c *CheatCodesPrecompile) RecordLogs() {
+	c.h.recordingLogs = true
+	c.h.recordedLogs = nil
 }
 
 // GetRecordedLogs implements https://book.getfoundry.sh/cheatcodes/get-recorded-logs
-//func (c *CheatCodesPrecompile) GetRecordedLogs() []Log {
-//	return nil // TODO
-//}
+// The returned logs are removed from the recording, logs remain to be recorded.
+func (c *CheatCodesPrecompile) GetRecordedLogs() []Log {
+	logs := c.h.recordedLogs
+	c.h.recordedLogs = nil
+	if logs == nil {
+		return []Log{}
+	}
+	return logs
+}
 
 // SetNonce implements https://book.getfoundry.sh/cheatcodes/set-nonce
 func (c *CheatCodesPrecompile) SetNonce(account common.Address, nonce uint64) {
@@ -155,28 +166,28 @@ func (c *CheatCodesPrecompile) ResetNonce(addr common.Address) {
 }
 
 // MockCall_b96213e4 implements https://book.getfoundry.sh/cheatcodes/mock-call
-func (c *CheatCodesPrecompile) MockCall_b96213e4(where common.Address, data []byte, retdata []byte) error {
-	panic("mockCall not supported")
+func (c *CheatCodesPrecompile) MockCall_b96213e4(where common.Address, data []byte, retdata []byte) {
+	c.h.MockCall(where, nil, data, retdata, false)
 }
 
 // MockCall_81409b91 implements https://book.getfoundry.sh/cheatcodes/mock-call
-func (c *CheatCodesPrecompile) MockCall_81409b91(where common.Address, value *big.Int, data []byte, retdata []byte) error {
-	panic("vm.mockCall not supported")
+func (c *CheatCodesPrecompile) MockCall_81409b91(where common.Address, value *big.Int, data []byte, retdata []byte) {
+	c.h.MockCall(where, value, data, retdata, false)
 }
 
 // MockCallRevert_dbaad147 implements https://book.getfoundry.sh/cheatcodes/mock-call-revert
-func (c *CheatCodesPrecompile) MockCallRevert_dbaad147(where common.Address, data []byte, retdata []byte) error {
-	panic("vm.mockCall not supported")
+func (c *CheatCodesPrecompile) MockCallRevert_dbaad147(where common.Address, data []byte, retdata []byte) {
+	c.h.MockCall(where, nil, data, retdata, true)
 }
 
 // MockCallRevert_d23cd037 implements https://book.getfoundry.sh/cheatcodes/mock-call-revert
-func (c *CheatCodesPrecompile) MockCallRevert_d23cd037(where common.Address, value *big.Int, data []byte, retdata []byte) error {
-	panic("vm.mockCall not supported")
+func (c *CheatCodesPrecompile) MockCallRevert_d23cd037(where common.Address, value *big.Int, data []byte, retdata []byte) {
+	c.h.MockCall(where, value, data, retdata, true)
 }
 
 // ClearMockedCalls implements https://book.getfoundry.sh/cheatcodes/clear-mocked-calls
-func (c *CheatCodesPrecompile) ClearMockedCalls() error {
-	panic("vm.clearMockedCalls not supported")
+func (c *CheatCodesPrecompile) ClearMockedCalls() {
+	c.h.ClearMockedCalls()
 }
 
 // Coinbase implements https://book.getfoundry.sh/cheatcodes/coinbase
@@ -210,13 +221,16 @@ func (c *CheatCodesPrecompile) StopBroadcast() error {
 }
 
 // PauseGasMetering implements https://book.getfoundry.sh/cheatcodes/pause-gas-metering
-func (c *CheatCodesPrecompile) PauseGasMetering() error {
-	panic("vm.pauseGasMetering not supported")
+func (c *CheatCodesPrecompile) PauseGasMetering() {
+	c.h.gasMeteringPaused = true
 }
 
 // ResumeGasMetering implements https://book.getfoundry.sh/cheatcodes/resume-gas-metering
-func (c *CheatCodesPrecompile) ResumeGasMetering() error {
-	panic("vm.resumeGasMetering not supported")
+func (c *CheatCodesPrecompile) ResumeGasMetering() {
+	c.h.gasMeteringPaused = false
+	for _, cf := range c.h.callStack {
+		cf.PausedGas = nil
+	}
 }
 
 // TxGasPrice implements https://book.getfoundry.sh/cheatcodes/tx-gas-price
@@ -225,11 +239,11 @@ func (c *CheatCodesPrecompile) TxGasPrice(newGasPrice *big.Int) {
 }
 
 // StartStateDiffRecording implements https://book.getfoundry.sh/cheatcodes/start-state-diff-recording
-func (c *CheatCodesPrecompile) StartStateDiffRecording() error {
-	panic("vm.startStateDiffRecording not supported")
+func (c *CheatCodesPrecompile) StartStateDiffRecording() {
+	c.h.StartStateDiffRecording()
 }
 
 // StopAndReturnStateDiff implements https://book.getfoundry.sh/cheatcodes/stop-and-return-state-diff
-func (c *CheatCodesPrecompile) StopAndReturnStateDiff() error {
-	panic("vm.stopAndReturnStateDiff not supported")
+func (c *CheatCodesPrecompile) StopAndReturnStateDiff() ([]AccountAccess, error) {
+	return c.h.StopAndReturnStateDiff()
 }
@@ -313,21 +313,29 @@ func (c *CheatCodesPrecompile) KeyExistsToml(data string, key string) (bool, err
 }
 
 // ParseJSON implements https://book.getfoundry.sh/cheatcodes/parse-json
-func (c *CheatCodesPrecompile) ParseJson_85940ef1(data string, key string) {
-	panic("parseJson(string,string) is not supported") // inferring an ABI type dynamically from sorted JSON content, this is mad.
+func (c *CheatCodesPrecompile) ParseJson_85940ef1(data string, key string) ([]byte, error) {
+	x, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+	return encodeInferredValue(x, key)
 }
 
-func (c *CheatCodesPrecompile) ParseJson_6a82600a(data string) {
-	panic("parseJson(string) is not supported")
+func (c *CheatCodesPrecompile) ParseJson_6a82600a(data string) ([]byte, error) {
+	return c.ParseJson_85940ef1(data, "$")
 }
 
 // ParseToml implements https://book.getfoundry.sh/cheatcodes/parse-toml
-func (c *CheatCodesPrecompile) ParseToml_37736e08(data string, key string) {
-	panic("parseToml(string,string) is not supported")
+func (c *CheatCodesPrecompile) ParseToml_37736e08(data string, key string) ([]byte, error) {
+	x, err := decodeTOMLValue(data)
+	if err != nil {
+		return nil, err
+	}
+	return encodeInferredValue(x, key)
 }
 
-func (c *CheatCodesPrecompile) ParseToml_592151f0(data string) {
-	panic("parseToml(string) is not supported")
+func (c *CheatCodesPrecompile) ParseToml_592151f0(data string) ([]byte, error) {
+	return c.ParseToml_37736e08(data, "$")
 }
 
 // See https://github.com/foundry-rs/foundry/issues/8672
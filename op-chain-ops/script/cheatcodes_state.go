@@ -1,12 +1,19 @@
 package script
 
 import (
-	"errors"
+	"fmt"
 )
 
+// LoadAllocs implements https://book.getfoundry.sh/cheatcodes/load-allocs
+// The allocs are loaded with the load-allocs hook of the Host, see WithLoadAllocsHook.
 func (c *CheatCodesPrecompile) LoadAllocs(pathToAllocsJson string) error {
 	c.h.log.Info("loading state", "target", pathToAllocsJson)
-	return errors.New("state-loading is not supported")
+	allocs, err := c.h.hooks.OnLoadAllocs(pathToAllocsJson)
+	if err != nil {
+		return fmt.Errorf("failed to load allocs %q: %w", pathToAllocsJson, err)
+	}
+	c.h.ImportState(allocs)
+	return nil
 }
 
 func (c *CheatCodesPrecompile) DumpState(pathToStateJson string) error {
@@ -0,0 +1,80 @@
+package script
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os/exec"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/foundry"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+// TestCheatcodeConformance runs the same cheatcode checks with the Go script host and with forge.
+// The script asserts the forge cheatcode semantics itself, and returns the final count and digests of the
+// state diff and the parsed values, which must be the same with both hosts.
+func TestCheatcodeConformance(t *testing.T) {
+	af := foundry.OpenArtifactsDir("./testdata/test-artifacts")
+	artifact, err := af.ReadArtifact("CheatcodeConformance.s.sol", "CheatcodeConformance")
+	require.NoError(t, err)
+
+	logger := testlog.Logger(t, log.LevelInfo)
+	h := NewHost(logger, af, nil, DefaultContext)
+	require.NoError(t, h.EnableCheats())
+
+	addr, err := h.LoadContract("CheatcodeConformance.s.sol", "CheatcodeConformance")
+	require.NoError(t, err)
+	h.AllowCheatcodes(addr)
+
+	input := bytes4("run()")
+	returnData, _, err := h.Call(DefaultContext.Sender, addr, input[:], DefaultFoundryGasLimit, uint256.NewInt(0))
+	require.NoError(t, err, "conformance script failed: %x", returnData)
+	values, err := artifact.ABI.Unpack("run", returnData)
+	require.NoError(t, err)
+	require.Len(t, values, 3)
+	goReturns := map[string]string{
+		"count":     values[0].(*big.Int).String(),
+		"stateDiff": common.Hash(values[1].([32]byte)).Hex(),
+		"parsed":    common.Hash(values[2].([32]byte)).Hex(),
+	}
+	// 1 increment by checkRecord and checkRecordLogs each, 10 by checkGasMetering, 1 by checkStateDiff.
+	require.Equal(t, "13", goReturns["count"])
+
+	forge, err := exec.LookPath("forge")
+	if err != nil {
+		t.Skip("forge is not installed, cannot compare with forge")
+	}
+	cmd := exec.Command(forge, "script", "scripts/CheatcodeConformance.s.sol:CheatcodeConformance", "--json")
+	cmd.Dir = "./testdata"
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "forge conformance script failed:\n%s", out)
+	require.Equal(t, goReturns, forgeReturns(t, out), "forge and the Go script host must return the same results")
+}
+
+// forgeReturns returns the named return values of the run of a forge script with JSON output.
+func forgeReturns(t *testing.T, out []byte) map[string]string {
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		var result struct {
+			Returns map[string]struct {
+				Value string `json:"value"`
+			} `json:"returns"`
+		}
+		if err := json.Unmarshal(line, &result); err != nil || result.Returns == nil {
+			continue
+		}
+		returns := make(map[string]string, len(result.Returns))
+		for name, v := range result.Returns {
+			returns[name] = v.Value
+		}
+		return returns
+	}
+	t.Fatalf("no return values in forge output:\n%s", out)
+	return nil
+}
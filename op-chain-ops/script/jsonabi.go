@@ -0,0 +1,302 @@
+package script
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// The parseJson(string) and parseToml(string) family of cheatcodes return ABI-encoded data,
+// without an ABI type definition to encode with. Like forge, the ABI type is inferred from the content:
+//   - objects are tuples, with the values ordered by key, alphabetically
+//   - arrays are dynamic arrays
+//   - integers are uint256, or int256 if negative
+//   - 0x-prefixed hex strings are address (20 bytes), bytes32 (32 bytes), or bytes (any other length)
+//   - other strings are strings, and booleans are bools
+//
+// The inferred value is ABI-encoded as single value, i.e. like abi.encode(value) in Solidity,
+// such that scripts can abi.decode it into the expected type.
+
+// abiValue is an ABI value with an inferred type.
+type abiValue interface {
+	// dynamic returns whether the ABI type is dynamically sized, i.e. encoded in the tail of a tuple
+	dynamic() bool
+	// encode returns the ABI encoding of the value
+	encode() []byte
+}
+
+// abiWord is a statically sized value, encoded as a single 32-byte word.
+type abiWord [32]byte
+
+func (w abiWord) dynamic() bool  { return false }
+func (w abiWord) encode() []byte { return w[:] }
+
+// abiBytes is a dynamically sized bytes or string value.
+type abiBytes []byte
+
+func (b abiBytes) dynamic() bool { return true }
+func (b abiBytes) encode() []byte {
+	out := encodeLength(len(b))
+	out = append(out, b...)
+	if rem := len(b) % 32; rem != 0 {
+		out = append(out, make([]byte, 32-rem)...)
+	}
+	return out
+}
+
+// abiArray is a dynamically sized array of values.
+type abiArray []abiValue
+
+func (a abiArray) dynamic() bool { return true }
+func (a abiArray) encode() []byte {
+	return append(encodeLength(len(a)), encodeTuple(a)...)
+}
+
+// abiTuple is a tuple of values, dynamically sized if any of its values are.
+type abiTuple []abiValue
+
+func (t abiTuple) dynamic() bool {
+	for _, v := range t {
+		if v.dynamic() {
+			return true
+		}
+	}
+	return false
+}
+func (t abiTuple) encode() []byte { return encodeTuple(t) }
+
+func encodeLength(n int) []byte {
+	var w abiWord
+	new(big.Int).SetUint64(uint64(n)).FillBytes(w[:])
+	return w[:]
+}
+
+// encodeTuple encodes the values in head-tail format:
+// dynamic values are encoded as offset in the head, with the contents in the tail.
+func encodeTuple(values []abiValue) []byte {
+	var head, tail []byte
+	headSize := 32 * len(values)
+	for _, v := range values {
+		if !v.dynamic() {
+			head = append(head, v.encode()...)
+			continue
+		}
+		head = append(head, encodeLength(headSize+len(tail))...)
+		tail = append(tail, v.encode()...)
+	}
+	return append(head, tail...)
+}
+
+// inferABIValue infers the ABI type of a decoded JSON value, like forge does.
+// Numbers are expected to be decoded as json.Number, to not lose precision.
+func inferABIValue(v any) (abiValue, error) {
+	switch x := v.(type) {
+	case nil:
+		// forge represents null as zero bytes32
+		return abiWord{}, nil
+	case bool:
+		var w abiWord
+		if x {
+			w[31] = 1
+		}
+		return w, nil
+	case json.Number:
+		n, err := parseIntegerNumber(string(x))
+		if err != nil {
+			return nil, err
+		}
+		if n.Cmp(minInt256) < 0 || n.Cmp(math.MaxBig256) > 0 {
+			return nil, fmt.Errorf("number %s does not fit in 256 bits", x)
+		}
+		return abiWord(math.U256Bytes(n)), nil
+	case string:
+		if hexStr, ok := strings.CutPrefix(x, "0x"); ok {
+			if len(hexStr)%2 != 0 {
+				hexStr = "0" + hexStr
+			}
+			if data, err := hex.DecodeString(hexStr); err == nil {
+				switch len(data) {
+				case common.AddressLength:
+					var w abiWord
+					copy(w[12:], data)
+					return w, nil
+				case 32:
+					return abiWord(data), nil
+				default:
+					return abiBytes(data), nil
+				}
+			}
+		}
+		return abiBytes(x), nil
+	case []any:
+		out := make(abiArray, len(x))
+		for i, elem := range x {
+			val, err := inferABIValue(elem)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array element %d: %w", i, err)
+			}
+			out[i] = val
+		}
+		return out, nil
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make(abiTuple, len(keys))
+		for i, k := range keys {
+			val, err := inferABIValue(x[k])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of key %q: %w", k, err)
+			}
+			out[i] = val
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+// parseIntegerNumber parses a JSON number, which must be an integer.
+// Numbers in scientific notation, like 1e18, are accepted, if integer.
+func parseIntegerNumber(s string) (*big.Int, error) {
+	if n, ok := new(big.Int).SetString(s, 10); ok {
+		return n, nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("number %q is not an integer", s)
+	}
+	return r.Num(), nil
+}
+
+// lookupValue selects the value at the given path, in the format of takePath.
+func lookupValue(v any, query string) (any, error) {
+	if query == "" {
+		return v, nil
+	}
+	trailing, stringKey, index, err := takePath(query)
+	if err != nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case []any:
+		if stringKey != "" {
+			return nil, fmt.Errorf("expected array index, but got string key in path: %q", stringKey)
+		}
+		if index >= uint64(len(x)) {
+			return nil, fmt.Errorf("index %d larger than length %d", index, len(x))
+		}
+		return lookupValue(x[index], trailing)
+	case map[string]any:
+		if stringKey == "" {
+			return nil, fmt.Errorf("expected string key, but got index in path: %d", index)
+		}
+		data, ok := x[stringKey]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", stringKey)
+		}
+		return lookupValue(data, trailing)
+	default:
+		return nil, fmt.Errorf("cannot select %q in value of type %T", query, x)
+	}
+}
+
+// trimKey checks a parseJson or parseToml key, which must be "$" or start with "." (optionally prefixed by "$"),
+// and returns the path to select with lookupValue.
+func trimKey(key string) (string, error) {
+	if key == "$" || key == "" {
+		return "", nil
+	}
+	key = strings.TrimPrefix(key, "$")
+	if !strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("key %q is invalid. A key must be \"$\" or start with \".\"", key)
+	}
+	return strings.TrimPrefix(key, "."), nil
+}
+
+// decodeJSONValue decodes JSON data, preserving the precision of numbers.
+func decodeJSONValue(data string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var x any
+	if err := dec.Decode(&x); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("invalid JSON: unexpected trailing data")
+	}
+	return x, nil
+}
+
+// decodeTOMLValue decodes TOML data, converting it to the value types of decodeJSONValue.
+func decodeTOMLValue(data string) (any, error) {
+	var x map[string]any
+	if _, err := toml.Decode(data, &x); err != nil {
+		return nil, fmt.Errorf("invalid TOML: %w", err)
+	}
+	return tomlToJSONValue(x), nil
+}
+
+func tomlToJSONValue(v any) any {
+	switch x := v.(type) {
+	case int64:
+		return json.Number(strconv.FormatInt(x, 10))
+	case float64:
+		return json.Number(strconv.FormatFloat(x, 'f', -1, 64))
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case []map[string]any:
+		out := make([]any, len(x))
+		for i, elem := range x {
+			out[i] = tomlToJSONValue(elem)
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, elem := range x {
+			out[i] = tomlToJSONValue(elem)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, elem := range x {
+			out[k] = tomlToJSONValue(elem)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// encodeInferredValue selects the value at the given key, infers its ABI type, and ABI-encodes it.
+func encodeInferredValue(v any, key string) ([]byte, error) {
+	path, err := trimKey(key)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := lookupValue(v, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select key %q: %w", key, err)
+	}
+	val, err := inferABIValue(selected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to infer ABI type of key %q: %w", key, err)
+	}
+	return encodeTuple([]abiValue{val}), nil
+}
@@ -0,0 +1,161 @@
+package script
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// mustPack ABI-encodes a single value of the given type, like abi.encode(value) in Solidity.
+func mustPack(t *testing.T, typ string, components []abi.ArgumentMarshaling, value any) []byte {
+	abiTyp, err := abi.NewType(typ, "", components)
+	require.NoError(t, err)
+	out, err := abi.Arguments{{Type: abiTyp}}.Pack(value)
+	require.NoError(t, err)
+	return out
+}
+
+func TestParseJsonInferredTypes(t *testing.T) {
+	c := &CheatCodesPrecompile{}
+	data := `{
+		"d": true,
+		"b": "0x0000000000000000000000000000000000000001",
+		"a": 42,
+		"c": ["hello", "world"],
+		"e": {"big": 115792089237316195423570985008687907853269984665640564039457584007913129639935, "neg": -5}
+	}`
+
+	out, err := c.ParseJson_6a82600a(data)
+	require.NoError(t, err)
+	type nested struct {
+		Big *big.Int
+		Neg *big.Int
+	}
+	type object struct {
+		A *big.Int
+		B common.Address
+		C []string
+		D bool
+		E nested
+	}
+	maxU256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	expected := mustPack(t, "tuple", []abi.ArgumentMarshaling{
+		{Name: "a", Type: "uint256"},
+		{Name: "b", Type: "address"},
+		{Name: "c", Type: "string[]"},
+		{Name: "d", Type: "bool"},
+		{Name: "e", Type: "tuple", Components: []abi.ArgumentMarshaling{
+			{Name: "big", Type: "uint256"},
+			{Name: "neg", Type: "int256"},
+		}},
+	}, object{
+		A: big.NewInt(42),
+		B: common.Address{19: 1},
+		C: []string{"hello", "world"},
+		D: true,
+		E: nested{Big: maxU256, Neg: big.NewInt(-5)},
+	})
+	require.Equal(t, expected, out)
+
+	out, err = c.ParseJson_85940ef1(data, ".c[1]")
+	require.NoError(t, err)
+	require.Equal(t, mustPack(t, "string", nil, "world"), out)
+
+	out, err = c.ParseJson_85940ef1(data, "$.e.neg")
+	require.NoError(t, err)
+	require.Equal(t, mustPack(t, "int256", nil, big.NewInt(-5)), out)
+
+	out, err = c.ParseJson_85940ef1(data, ".c")
+	require.NoError(t, err)
+	require.Equal(t, mustPack(t, "string[]", nil, []string{"hello", "world"}), out)
+}
+
+func TestParseJsonHexStrings(t *testing.T) {
+	c := &CheatCodesPrecompile{}
+	data := `{
+		"hash": "0x0101010101010101010101010101010101010101010101010101010101010101",
+		"short": "0x1234",
+		"odd": "0x123",
+		"notHex": "0xzz",
+		"sci": 1e18
+	}`
+
+	out, err := c.ParseJson_85940ef1(data, ".hash")
+	require.NoError(t, err)
+	var hash [32]byte
+	for i := range hash {
+		hash[i] = 1
+	}
+	require.Equal(t, mustPack(t, "bytes32", nil, hash), out)
+
+	out, err = c.ParseJson_85940ef1(data, ".short")
+	require.NoError(t, err)
+	require.Equal(t, mustPack(t, "bytes", nil, []byte{0x12, 0x34}), out)
+
+	out, err = c.ParseJson_85940ef1(data, ".odd")
+	require.NoError(t, err)
+	require.Equal(t, mustPack(t, "bytes", nil, []byte{0x01, 0x23}), out)
+
+	out, err = c.ParseJson_85940ef1(data, ".notHex")
+	require.NoError(t, err)
+	require.Equal(t, mustPack(t, "string", nil, "0xzz"), out)
+
+	out, err = c.ParseJson_85940ef1(data, ".sci")
+	require.NoError(t, err)
+	require.Equal(t, mustPack(t, "uint256", nil, new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)), out)
+}
+
+func TestParseJsonErrors(t *testing.T) {
+	c := &CheatCodesPrecompile{}
+	_, err := c.ParseJson_85940ef1(`{"a": 1.5}`, ".a")
+	require.ErrorContains(t, err, "not an integer")
+
+	_, err = c.ParseJson_85940ef1(`{"a": 1}`, "a")
+	require.ErrorContains(t, err, "invalid")
+
+	_, err = c.ParseJson_85940ef1(`{"a": 1}`, ".b")
+	require.ErrorContains(t, err, "unknown key")
+
+	_, err = c.ParseJson_6a82600a(`{"a": `)
+	require.ErrorContains(t, err, "invalid JSON")
+}
+
+func TestParseTomlInferredTypes(t *testing.T) {
+	c := &CheatCodesPrecompile{}
+	data := `
+a = 42
+b = "0x0000000000000000000000000000000000000001"
+
+[c]
+d = true
+e = -1
+`
+	out, err := c.ParseToml_592151f0(data)
+	require.NoError(t, err)
+	type nested struct {
+		D bool
+		E *big.Int
+	}
+	type object struct {
+		A *big.Int
+		B common.Address
+		C nested
+	}
+	expected := mustPack(t, "tuple", []abi.ArgumentMarshaling{
+		{Name: "a", Type: "uint256"},
+		{Name: "b", Type: "address"},
+		{Name: "c", Type: "tuple", Components: []abi.ArgumentMarshaling{
+			{Name: "d", Type: "bool"},
+			{Name: "e", Type: "int256"},
+		}},
+	}, object{A: big.NewInt(42), B: common.Address{19: 1}, C: nested{D: true, E: big.NewInt(-1)}})
+	require.Equal(t, expected, out)
+
+	out, err = c.ParseToml_37736e08(data, ".c.d")
+	require.NoError(t, err)
+	require.Equal(t, mustPack(t, "bool", nil, true), out)
+}
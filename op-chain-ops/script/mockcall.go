@@ -0,0 +1,155 @@
+package script
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// mockedCall is a call response registered with the mockCall or mockCallRevert cheatcodes.
+type mockedCall struct {
+	// calldata is matched exactly, or as prefix of the call input
+	calldata []byte
+	// value is the call value to match, or nil to match any value
+	value *big.Int
+
+	returnData []byte
+	revert     bool
+}
+
+// less orders mocked calls by match preference: longer calldata first, such that an exact match
+// is preferred over a prefix match, and mocks of a specific value before mocks that match any value.
+func (m *mockedCall) less(other *mockedCall) bool {
+	if len(m.calldata) != len(other.calldata) {
+		return len(m.calldata) > len(other.calldata)
+	}
+	if c := bytes.Compare(m.calldata, other.calldata); c != 0 {
+		return c < 0
+	}
+	if (m.value == nil) != (other.value == nil) {
+		return m.value != nil
+	}
+	return m.value != nil && m.value.Cmp(other.value) < 0
+}
+
+// sameKey returns whether the mocked call matches the same calldata and value as the other.
+func (m *mockedCall) sameKey(other *mockedCall) bool {
+	return !m.less(other) && !other.less(m)
+}
+
+// mockPrecompile replaces a mocked call, returning the mocked return-data without executing any code.
+type mockPrecompile struct {
+	mock *mockedCall
+}
+
+var _ vm.PrecompiledContract = (*mockPrecompile)(nil)
+
+func (m *mockPrecompile) RequiredGas(input []byte) uint64 {
+	return 0
+}
+
+func (m *mockPrecompile) Run(input []byte) ([]byte, error) {
+	out := bytes.Clone(m.mock.returnData)
+	if m.mock.revert {
+		return out, vm.ErrExecutionReverted
+	}
+	return out, nil
+}
+
+// MockCall registers a mocked response for calls to the given address with the given calldata,
+// and optionally call-value. The calldata may be a prefix, e.g. just a 4-byte selector.
+// An exact calldata match takes priority over a prefix match.
+func (h *Host) MockCall(where common.Address, value *big.Int, calldata []byte, returnData []byte, revert bool) {
+	mock := &mockedCall{
+		calldata:   bytes.Clone(calldata),
+		returnData: bytes.Clone(returnData),
+		revert:     revert,
+	}
+	if value != nil {
+		mock.value = new(big.Int).Set(value)
+	}
+	// Like forge, make the account non-empty, so Solidity extcodesize checks pass.
+	if h.state.GetCodeSize(where) == 0 {
+		h.state.SetCode(where, []byte{0})
+	}
+	mocks := h.mockedCalls[where]
+	for i, existing := range mocks {
+		if existing.sameKey(mock) {
+			mocks[i] = mock
+			return
+		}
+	}
+	mocks = append(mocks, mock)
+	sort.SliceStable(mocks, func(i, j int) bool {
+		return mocks[i].less(mocks[j])
+	})
+	h.mockedCalls[where] = mocks
+}
+
+// ClearMockedCalls removes all mocked calls.
+func (h *Host) ClearMockedCalls() {
+	h.mockedCalls = make(map[common.Address][]*mockedCall)
+}
+
+// findMockedCall finds the mocked call that applies to a call, if any.
+// The value is nil if the call does not transfer value (delegate-call and static-call).
+func (h *Host) findMockedCall(to common.Address, input []byte, value *big.Int) *mockedCall {
+	mocks := h.mockedCalls[to]
+	if len(mocks) == 0 {
+		return nil
+	}
+	// mocks are ordered by preference, so the first match is the best match
+	for _, m := range mocks {
+		if !bytes.HasPrefix(input, m.calldata) {
+			continue
+		}
+		if m.value == nil || (value != nil && m.value.Cmp(value) == 0) {
+			return m
+		}
+	}
+	return nil
+}
+
+// checkMockedCall inspects a call op-code that is about to run, and prepares the mocked response,
+// if the call is mocked. The mock is picked up when the EVM looks up the precompile of the call target.
+func (h *Host) checkMockedCall(op vm.OpCode, scope *vm.ScopeContext) {
+	h.pendingMock = nil
+	if len(h.mockedCalls) == 0 {
+		return
+	}
+	stack := scope.Stack
+	var value *big.Int
+	var inOffsetPos, inSizePos int
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		if len(stack.Data()) < 7 {
+			return
+		}
+		value = stack.Back(2).ToBig()
+		inOffsetPos, inSizePos = 3, 4
+	case vm.DELEGATECALL, vm.STATICCALL:
+		if len(stack.Data()) < 6 {
+			return
+		}
+		inOffsetPos, inSizePos = 2, 3
+	default:
+		return
+	}
+	to := common.Address(stack.Back(1).Bytes20())
+	input := readMemory(scope.Memory, stack.Back(inOffsetPos), stack.Back(inSizePos))
+	if input == nil {
+		return
+	}
+	if mock := h.findMockedCall(to, input, value); mock != nil {
+		h.pendingMock = &pendingMockCall{to: to, mock: mock}
+	}
+}
+
+// pendingMockCall is a mocked call that is about to be made
+type pendingMockCall struct {
+	to   common.Address
+	mock *mockedCall
+}
@@ -348,11 +348,44 @@ func convertType(src, dest any) (out any, err error) {
 
 // goTypeToABIType infers the geth ABI type definition from a Go reflect type definition.
 func goTypeToABIType(typ reflect.Type) (abi.Type, error) {
-	solType, internalType, err := goTypeToSolidityType(typ)
+	m, err := goTypeToArgumentMarshaling("", typ)
 	if err != nil {
 		return abi.Type{}, err
 	}
-	return abi.NewType(solType, internalType, nil)
+	return abi.NewType(m.Type, m.InternalType, m.Components)
+}
+
+// goTypeToArgumentMarshaling infers the ABI argument definition from a Go reflect type definition.
+// Structs are represented as tuples, with a component for each of the exported fields, in order.
+// The component names match the Go field names, so the Geth ABI utils can encode the struct fields.
+func goTypeToArgumentMarshaling(name string, typ reflect.Type) (abi.ArgumentMarshaling, error) {
+	solType, internalType, err := goTypeToSolidityType(typ)
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+	out := abi.ArgumentMarshaling{Name: name, Type: solType, InternalType: internalType}
+	if !strings.HasPrefix(solType, "tuple") {
+		return out, nil
+	}
+	structTyp := typ
+	for structTyp.Kind() == reflect.Pointer || structTyp.Kind() == reflect.Slice || structTyp.Kind() == reflect.Array {
+		structTyp = structTyp.Elem()
+	}
+	for i := 0; i < structTyp.NumField(); i++ {
+		field := structTyp.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		component, err := goTypeToArgumentMarshaling(field.Name, field.Type)
+		if err != nil {
+			return abi.ArgumentMarshaling{}, fmt.Errorf("failed to determine ABI type of struct field %s: %w", field.Name, err)
+		}
+		out.Components = append(out.Components, component)
+	}
+	if len(out.Components) == 0 {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("struct %s has no exported fields", structTyp)
+	}
+	return out, nil
 }
 
 // ABIInt256 is an alias for big.Int that is represented as int256 in ABI method signature,
@@ -406,6 +439,7 @@ func goTypeToSolidityType(typ reflect.Type) (typeDef, internalType string, err e
 		if internalTyp != "" {
 			return "", "", fmt.Errorf("nested internal types not supported: %w", err)
 		}
+		// slices of structs are tuple arrays, the tuple components are added by goTypeToArgumentMarshaling
 		return elemABITyp + "[]", "", nil
 	case reflect.Struct:
 		if typ.AssignableTo(abiInt256Type) {
@@ -414,8 +448,8 @@ func goTypeToSolidityType(typ reflect.Type) (typeDef, internalType string, err e
 		if typ.ConvertibleTo(reflect.TypeFor[big.Int]()) {
 			return "uint256", "", nil
 		}
-		// We can parse into abi.TupleTy in the future, if necessary
-		return "", "", fmt.Errorf("structs are not supported, cannot handle type %s", typ)
+		// Structs are encoded as tuples, the components are added by goTypeToArgumentMarshaling
+		return "tuple", "", nil
 	case reflect.Pointer:
 		elemABITyp, internalTyp, err := goTypeToSolidityType(typ.Elem())
 		if err != nil {
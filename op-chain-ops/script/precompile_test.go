@@ -121,6 +121,46 @@ func TestPrecompile(t *testing.T) {
 	require.Equal(t, b32((42+100+7)*3), out)
 }
 
+type ExamplePair struct {
+	Num  uint64
+	Addr common.Address
+}
+
+type TupleExamplePrecompile struct{}
+
+func (e *TupleExamplePrecompile) Swap(p ExamplePair) ExamplePair {
+	return ExamplePair{Num: p.Num + 1, Addr: common.Address{0xaa}}
+}
+
+func (e *TupleExamplePrecompile) Pairs() []ExamplePair {
+	return []ExamplePair{{Num: 1, Addr: common.Address{0x01}}, {Num: 2, Addr: common.Address{0x02}}}
+}
+
+func TestPrecompileTuples(t *testing.T) {
+	p, err := NewPrecompile[*TupleExamplePrecompile](&TupleExamplePrecompile{})
+	require.NoError(t, err)
+
+	// struct input and output
+	input := crypto.Keccak256([]byte("swap((uint64,address))"))[:4]
+	input = append(input, b32(41)...)
+	input = append(input, leftPad32(common.Address{0x01}.Bytes())...)
+	out, err := p.Run(input)
+	require.NoError(t, err)
+	require.Equal(t, b32(42), out[:32])
+	require.Equal(t, leftPad32(common.Address{0xaa}.Bytes()), out[32:64])
+
+	// slice of structs output
+	input = crypto.Keccak256([]byte("pairs()"))[:4]
+	out, err = p.Run(input)
+	require.NoError(t, err)
+	require.Equal(t, b32(0x20), out[:32]) // offset
+	require.Equal(t, b32(2), out[32:64])  // length
+	require.Equal(t, b32(1), out[64:96])
+	require.Equal(t, leftPad32(common.Address{0x01}.Bytes()), out[96:128])
+	require.Equal(t, b32(2), out[128:160])
+	require.Equal(t, leftPad32(common.Address{0x02}.Bytes()), out[160:192])
+}
+
 type DeploymentExample struct {
 	FooBar    common.Address
 	IsEnabled bool
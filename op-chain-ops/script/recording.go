@@ -0,0 +1,375 @@
+package script
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/script/addresses"
+)
+
+// maxMemoryRead is the maximum amount of bytes that is read from EVM memory beyond the current memory size,
+// when inspecting op-code inputs before the memory is expanded. Larger reads fail on gas anyway.
+const maxMemoryRead = 1 << 20
+
+// storageAccesses tracks the storage slots read and written by each account,
+// for the record and accesses cheatcodes.
+type storageAccesses struct {
+	reads  map[common.Address][][32]byte
+	writes map[common.Address][][32]byte
+}
+
+func newStorageAccesses() *storageAccesses {
+	return &storageAccesses{
+		reads:  make(map[common.Address][][32]byte),
+		writes: make(map[common.Address][][32]byte),
+	}
+}
+
+// Log is a log event, as returned by the getRecordedLogs cheatcode.
+type Log struct {
+	Topics  [][32]byte
+	Data    []byte
+	Emitter common.Address
+}
+
+// AccountAccessKind is the kind of account access, matching the Vm.AccountAccessKind enum of forge-std.
+type AccountAccessKind uint8
+
+const (
+	AccountAccessCall AccountAccessKind = iota
+	AccountAccessDelegateCall
+	AccountAccessCallCode
+	AccountAccessStaticCall
+	AccountAccessCreate
+	AccountAccessSelfDestruct
+	AccountAccessResume
+	AccountAccessBalance
+	AccountAccessExtcodesize
+	AccountAccessExtcodehash
+	AccountAccessExtcodecopy
+)
+
+// ChainInfo identifies the chain an account access happened on, matching Vm.ChainInfo of forge-std.
+type ChainInfo struct {
+	ForkId  *big.Int
+	ChainId *big.Int
+}
+
+// StorageAccess is a storage read or write, matching Vm.StorageAccess of forge-std.
+type StorageAccess struct {
+	Account       common.Address
+	Slot          [32]byte
+	IsWrite       bool
+	PreviousValue [32]byte
+	NewValue      [32]byte
+	Reverted      bool
+}
+
+// AccountAccess is a recorded account access, matching Vm.AccountAccess of forge-std.
+// The field order matters, as it determines the ABI encoding.
+type AccountAccess struct {
+	ChainInfo       ChainInfo
+	Kind            AccountAccessKind
+	Account         common.Address
+	Accessor        common.Address
+	Initialized     bool
+	OldBalance      *big.Int
+	NewBalance      *big.Int
+	DeployedCode    []byte
+	Value           *big.Int
+	Data            []byte
+	Reverted        bool
+	StorageAccesses []StorageAccess
+	Depth           uint64
+}
+
+// recordedFrame tracks the account accesses of a call-frame that started while recording a state-diff.
+type recordedFrame struct {
+	// depth of the caller, as passed to onEnter and onExit
+	depth int
+	// first is the index of the account access that entered the frame
+	first int
+	// last is the index of the latest account access that storage accesses of the frame are added to:
+	// the entering access, or a Resume access after a sub-call.
+	last int
+}
+
+// stateDiffRecorder records account and storage accesses,
+// for the startStateDiffRecording and stopAndReturnStateDiff cheatcodes.
+type stateDiffRecorder struct {
+	accesses []AccountAccess
+	frames   []recordedFrame
+}
+
+// StartStateDiffRecording starts recording account and storage accesses of any following calls.
+// Any previous recording is discarded.
+func (h *Host) StartStateDiffRecording() {
+	h.stateDiff = &stateDiffRecorder{}
+}
+
+// StopAndReturnStateDiff stops the state-diff recording, and returns the recorded accesses, in order of execution.
+func (h *Host) StopAndReturnStateDiff() ([]AccountAccess, error) {
+	if h.stateDiff == nil {
+		return nil, errors.New("no state-diff recording in progress")
+	}
+	accesses := h.stateDiff.accesses
+	h.stateDiff = nil
+	if accesses == nil {
+		return []AccountAccess{}, nil
+	}
+	return accesses, nil
+}
+
+// chainInfo returns the chain info of the active fork, or the fork-id 0 if no fork is active.
+func (h *Host) chainInfo() ChainInfo {
+	forkID := new(big.Int)
+	if id, active := h.state.ActiveFork(); active {
+		forkID = id.U256().ToBig()
+	}
+	return ChainInfo{ForkId: forkID, ChainId: new(big.Int).Set(h.chainCfg.ChainID)}
+}
+
+// balanceOf returns the balance of the given account, as big.Int
+func (h *Host) balanceOf(addr common.Address) *big.Int {
+	return h.state.GetBalance(addr).ToBig()
+}
+
+// recordEnter records the account access of a call-frame that is being entered,
+// if a state-diff is being recorded.
+func (h *Host) recordEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, value *big.Int) {
+	rec := h.stateDiff
+	if rec == nil {
+		return
+	}
+	// like forge, calls to the cheatcodes and console are not recorded
+	if to == addresses.VMAddr || to == addresses.ConsoleAddr {
+		return
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+	access := AccountAccess{
+		ChainInfo:   h.chainInfo(),
+		Account:     to,
+		Accessor:    from,
+		Initialized: !h.state.Empty(to),
+		OldBalance:  h.balanceOf(to),
+		NewBalance:  h.balanceOf(to),
+		Value:       new(big.Int).Set(value),
+		Data:        append([]byte(nil), input...),
+		Depth:       uint64(depth),
+	}
+	switch vm.OpCode(typ) {
+	case vm.CALL:
+		access.Kind = AccountAccessCall
+	case vm.CALLCODE:
+		access.Kind = AccountAccessCallCode
+	case vm.DELEGATECALL:
+		access.Kind = AccountAccessDelegateCall
+	case vm.STATICCALL:
+		access.Kind = AccountAccessStaticCall
+	case vm.CREATE, vm.CREATE2:
+		// Like forge, a created account is reported as initialized, with a zero balance before the value transfer.
+		access.Kind = AccountAccessCreate
+		access.Initialized = true
+		access.OldBalance = new(big.Int)
+	case vm.SELFDESTRUCT:
+		// The self-destructed account is reported as the accessed account, the value is sent to the beneficiary.
+		access.Kind = AccountAccessSelfDestruct
+		access.Account = from
+		access.OldBalance = h.balanceOf(from)
+		access.NewBalance = h.balanceOf(from)
+	default:
+		return
+	}
+	rec.accesses = append(rec.accesses, access)
+	index := len(rec.accesses) - 1
+	rec.frames = append(rec.frames, recordedFrame{depth: depth, first: index, last: index})
+}
+
+// recordExit completes the account access of the call-frame that is being exited,
+// if a state-diff is being recorded. If the call-frame reverted, all its accesses are marked as reverted.
+func (h *Host) recordExit(depth int, reverted bool) {
+	rec := h.stateDiff
+	if rec == nil {
+		return
+	}
+	for len(rec.frames) > 0 && rec.frames[len(rec.frames)-1].depth >= depth {
+		frame := rec.frames[len(rec.frames)-1]
+		rec.frames = rec.frames[:len(rec.frames)-1]
+		access := &rec.accesses[frame.first]
+		access.NewBalance = h.balanceOf(access.Account)
+		if access.Kind == AccountAccessCreate && !reverted {
+			access.DeployedCode = append([]byte(nil), h.state.GetCode(access.Account)...)
+		}
+		if frame.depth != depth || !reverted {
+			continue
+		}
+		for i := frame.first; i < len(rec.accesses); i++ {
+			rec.accesses[i].Reverted = true
+			for j := range rec.accesses[i].StorageAccesses {
+				rec.accesses[i].StorageAccesses[j].Reverted = true
+			}
+		}
+	}
+}
+
+// currentRecordedFrame returns the recorded frame of the call-frame executing at the given op-code depth,
+// or nil if the frame started before the state-diff recording.
+func (rec *stateDiffRecorder) currentRecordedFrame(depth int) *recordedFrame {
+	if len(rec.frames) == 0 {
+		return nil
+	}
+	frame := &rec.frames[len(rec.frames)-1]
+	// op-codes run one level deeper than the call that entered the frame
+	if frame.depth != depth-1 {
+		return nil
+	}
+	return frame
+}
+
+// recordStorageAccess adds a storage access to the account access of the current call-frame.
+// If sub-calls were recorded since, the access is added to a Resume account access instead,
+// to preserve the order of accesses, like forge does.
+func (h *Host) recordStorageAccess(depth int, access StorageAccess) {
+	rec := h.stateDiff
+	if rec == nil {
+		return
+	}
+	frame := rec.currentRecordedFrame(depth)
+	if frame == nil {
+		return
+	}
+	if frame.last != len(rec.accesses)-1 {
+		entry := rec.accesses[frame.first]
+		rec.accesses = append(rec.accesses, AccountAccess{
+			ChainInfo:   entry.ChainInfo,
+			Kind:        AccountAccessResume,
+			Account:     entry.Account,
+			Accessor:    entry.Accessor,
+			Initialized: entry.Initialized,
+			OldBalance:  new(big.Int),
+			NewBalance:  new(big.Int),
+			Value:       new(big.Int),
+			Reverted:    entry.Reverted,
+			Depth:       entry.Depth,
+		})
+		frame.last = len(rec.accesses) - 1
+	}
+	last := &rec.accesses[frame.last]
+	last.StorageAccesses = append(last.StorageAccesses, access)
+}
+
+// recordAccountQuery records an account access by the BALANCE and EXTCODE* op-codes.
+func (h *Host) recordAccountQuery(depth int, kind AccountAccessKind, accessor common.Address, addr common.Address) {
+	rec := h.stateDiff
+	if rec == nil || rec.currentRecordedFrame(depth) == nil {
+		return
+	}
+	rec.accesses = append(rec.accesses, AccountAccess{
+		ChainInfo:   h.chainInfo(),
+		Kind:        kind,
+		Account:     addr,
+		Accessor:    accessor,
+		Initialized: !h.state.Empty(addr),
+		OldBalance:  h.balanceOf(addr),
+		NewBalance:  h.balanceOf(addr),
+		Value:       new(big.Int),
+		Depth:       uint64(depth),
+	})
+}
+
+// recordOpcode inspects the op-code that is about to run, to record storage accesses,
+// log events and account accesses, for the recording cheatcodes.
+func (h *Host) recordOpcode(op vm.OpCode, scope *vm.ScopeContext, depth int) {
+	stack := scope.Stack
+	self := scope.Contract.Address()
+	switch op {
+	case vm.SLOAD:
+		if len(stack.Data()) < 1 {
+			return
+		}
+		slot := common.Hash(stack.Back(0).Bytes32())
+		if h.accesses != nil {
+			h.accesses.reads[self] = append(h.accesses.reads[self], slot)
+		}
+		value := h.state.GetState(self, slot)
+		h.recordStorageAccess(depth, StorageAccess{
+			Account:       self,
+			Slot:          slot,
+			PreviousValue: value,
+			NewValue:      value,
+		})
+	case vm.SSTORE:
+		if len(stack.Data()) < 2 {
+			return
+		}
+		slot := common.Hash(stack.Back(0).Bytes32())
+		value := common.Hash(stack.Back(1).Bytes32())
+		prev := h.state.GetState(self, slot)
+		h.onStorageChange(self, slot, prev, value)
+		h.recordStorageAccess(depth, StorageAccess{
+			Account:       self,
+			Slot:          slot,
+			IsWrite:       true,
+			PreviousValue: prev,
+			NewValue:      value,
+		})
+	case vm.LOG0, vm.LOG1, vm.LOG2, vm.LOG3, vm.LOG4:
+		topicCount := int(op - vm.LOG0)
+		if len(stack.Data()) < 2+topicCount {
+			return
+		}
+		ev := &types.Log{
+			Address: self,
+			Data:    readMemory(scope.Memory, stack.Back(0), stack.Back(1)),
+		}
+		for i := 0; i < topicCount; i++ {
+			ev.Topics = append(ev.Topics, stack.Back(2+i).Bytes32())
+		}
+		h.onLog(ev)
+	case vm.BALANCE:
+		if len(stack.Data()) >= 1 {
+			h.recordAccountQuery(depth, AccountAccessBalance, self, stack.Back(0).Bytes20())
+		}
+	case vm.EXTCODESIZE:
+		if len(stack.Data()) >= 1 {
+			h.recordAccountQuery(depth, AccountAccessExtcodesize, self, stack.Back(0).Bytes20())
+		}
+	case vm.EXTCODEHASH:
+		if len(stack.Data()) >= 1 {
+			h.recordAccountQuery(depth, AccountAccessExtcodehash, self, stack.Back(0).Bytes20())
+		}
+	case vm.EXTCODECOPY:
+		if len(stack.Data()) >= 1 {
+			h.recordAccountQuery(depth, AccountAccessExtcodecopy, self, stack.Back(0).Bytes20())
+		}
+	}
+}
+
+// readMemory copies a range of EVM memory. Op-codes are inspected before the memory is expanded,
+// so any range beyond the current memory size reads as zeroes, like it will after expansion.
+func readMemory(mem *vm.Memory, offset, size *uint256.Int) []byte {
+	if size.IsZero() {
+		return []byte{}
+	}
+	if !offset.IsUint64() || !size.IsUint64() {
+		return nil
+	}
+	data := mem.Data()
+	start, length := offset.Uint64(), size.Uint64()
+	if start+length < start || start+length > uint64(len(data))+maxMemoryRead {
+		return nil
+	}
+	out := make([]byte, length)
+	if start < uint64(len(data)) {
+		copy(out, data[start:])
+	}
+	return out
+}
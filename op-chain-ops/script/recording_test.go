@@ -0,0 +1,162 @@
+package script
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/foundry"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+var (
+	// recordTargetCode reads slot 1, writes 0x42 to slot 2, and emits a log with topic 0xaa and no data.
+	recordTargetCode = common.FromHex("0x600154506042600255" + "60aa60006000a1" + "00")
+	// mockCallerCode calls the target with calldata 0x12345678, and returns the return data, also if reverted.
+	mockCallerCode = common.FromHex("0x6312345678" + "60e01b600052" +
+		"6020600060046000600073" + "1111111111111111111111111111111111111111" + "5af1" +
+		"503d600060003e3d6000f3")
+)
+
+func newRecordingTestHost(t *testing.T) *Host {
+	logger := testlog.Logger(t, log.LevelInfo)
+	h := NewHost(logger, foundry.OpenArtifactsDir("./testdata/test-artifacts"), nil, DefaultContext)
+	require.NoError(t, h.EnableCheats())
+	return h
+}
+
+func TestRecordAccessesAndLogs(t *testing.T) {
+	h := newRecordingTestHost(t)
+	target := common.Address{0x22}
+	h.state.SetCode(target, recordTargetCode)
+	c := h.cheatcodes.Precompile
+
+	c.Record()
+	c.RecordLogs()
+	_, _, err := h.Call(DefaultContext.Sender, target, nil, DefaultFoundryGasLimit, uint256.NewInt(0))
+	require.NoError(t, err)
+
+	reads, writes := c.Accesses(target)
+	require.Equal(t, [][32]byte{{31: 1}, {31: 2}}, reads, "a write also counts as read")
+	require.Equal(t, [][32]byte{{31: 2}}, writes)
+
+	logs := c.GetRecordedLogs()
+	require.Equal(t, []Log{{Topics: [][32]byte{{31: 0xaa}}, Data: []byte{}, Emitter: target}}, logs)
+	require.Empty(t, c.GetRecordedLogs(), "recorded logs are consumed")
+
+	// a new recording starts from scratch
+	c.Record()
+	reads, writes = c.Accesses(target)
+	require.Empty(t, reads)
+	require.Empty(t, writes)
+}
+
+func TestStateDiffRecording(t *testing.T) {
+	h := newRecordingTestHost(t)
+	target := common.Address{0x22}
+	h.state.SetCode(target, recordTargetCode)
+	h.state.SetBalance(target, uint256.NewInt(100))
+	c := h.cheatcodes.Precompile
+
+	c.StartStateDiffRecording()
+	_, _, err := h.Call(DefaultContext.Sender, target, []byte{0x01}, DefaultFoundryGasLimit, uint256.NewInt(0))
+	require.NoError(t, err)
+	accesses, err := c.StopAndReturnStateDiff()
+	require.NoError(t, err)
+
+	require.Len(t, accesses, 1)
+	access := accesses[0]
+	require.Equal(t, AccountAccessCall, access.Kind)
+	require.Equal(t, target, access.Account)
+	require.Equal(t, DefaultContext.Sender, access.Accessor)
+	require.True(t, access.Initialized)
+	require.Equal(t, big.NewInt(100), access.OldBalance)
+	require.Equal(t, big.NewInt(100), access.NewBalance)
+	require.Equal(t, []byte{0x01}, access.Data)
+	require.False(t, access.Reverted)
+	require.Equal(t, h.ChainID(), access.ChainInfo.ChainId)
+	require.Equal(t, []StorageAccess{
+		{Account: target, Slot: [32]byte{31: 1}},
+		{Account: target, Slot: [32]byte{31: 2}, IsWrite: true, NewValue: [32]byte{31: 0x42}},
+	}, access.StorageAccesses)
+
+	_, err = c.StopAndReturnStateDiff()
+	require.Error(t, err, "recording was stopped")
+}
+
+func TestMockCall(t *testing.T) {
+	h := newRecordingTestHost(t)
+	caller := common.Address{0x33}
+	target := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	h.state.SetCode(caller, mockCallerCode)
+	c := h.cheatcodes.Precompile
+
+	call := func() []byte {
+		out, _, err := h.Call(DefaultContext.Sender, caller, nil, DefaultFoundryGasLimit, uint256.NewInt(0))
+		require.NoError(t, err)
+		return out
+	}
+
+	c.MockCall_b96213e4(target, []byte{0x12, 0x34}, []byte("prefix"))
+	require.NotEmpty(t, h.GetCode(target), "mocked account must have code")
+	require.Equal(t, []byte("prefix"), call())
+
+	// an exact match is preferred over a prefix match
+	c.MockCall_b96213e4(target, []byte{0x12, 0x34, 0x56, 0x78}, []byte("exact"))
+	require.Equal(t, []byte("exact"), call())
+
+	// mocks with a non-matching value do not apply
+	c.MockCall_81409b91(target, big.NewInt(1), []byte{0x12}, []byte("value"))
+	require.Equal(t, []byte("exact"), call())
+
+	// a mock with the same calldata and value replaces the existing mock
+	c.MockCallRevert_dbaad147(target, []byte{0x12, 0x34, 0x56, 0x78}, []byte("reverted"))
+	require.Equal(t, []byte("reverted"), call())
+
+	c.ClearMockedCalls()
+	require.Empty(t, call(), "target code is a STOP op-code without mock")
+}
+
+func TestPauseGasMetering(t *testing.T) {
+	h := newRecordingTestHost(t)
+	target := common.Address{0x22}
+	h.state.SetCode(target, recordTargetCode)
+	c := h.cheatcodes.Precompile
+
+	c.PauseGasMetering()
+	_, leftOver, err := h.Call(DefaultContext.Sender, target, nil, DefaultFoundryGasLimit, uint256.NewInt(0))
+	require.NoError(t, err)
+	require.Equal(t, uint64(DefaultFoundryGasLimit), leftOver)
+
+	c.ResumeGasMetering()
+	_, leftOver, err = h.Call(DefaultContext.Sender, target, nil, DefaultFoundryGasLimit, uint256.NewInt(0))
+	require.NoError(t, err)
+	require.Less(t, leftOver, uint64(DefaultFoundryGasLimit))
+}
+
+func TestLoadAllocs(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelInfo)
+	addr := common.Address{0x44}
+	allocs := &foundry.ForgeAllocs{}
+	require.NoError(t, allocs.UnmarshalJSON([]byte(`{"0x4400000000000000000000000000000000000000": {"balance": "0x10", "nonce": "0x2"}}`)))
+	var loaded string
+	h := NewHost(logger, nil, nil, DefaultContext, WithLoadAllocsHook(func(path string) (*foundry.ForgeAllocs, error) {
+		loaded = path
+		return allocs, nil
+	}))
+	require.NoError(t, h.EnableCheats())
+
+	require.NoError(t, h.cheatcodes.Precompile.LoadAllocs("state.json"))
+	require.Equal(t, "state.json", loaded)
+	require.Equal(t, uint64(2), h.GetNonce(addr))
+	require.Equal(t, uint256.NewInt(16), h.state.GetBalance(addr))
+
+	// loading is not enabled by default
+	h = newRecordingTestHost(t)
+	require.Error(t, h.cheatcodes.Precompile.LoadAllocs("state.json"))
+}
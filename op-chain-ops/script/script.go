@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"strings"
 
 	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/script/addresses"
@@ -64,6 +65,10 @@ type CallFrame struct {
 	// CallerNonce keeps track of the nonce of the caller who entered the callframe
 	// (nonce of pranked caller, if pranked).
 	CallerNonce uint64
+
+	// PausedGas is the gas of this call frame at the time gas metering was paused, if paused.
+	// While paused, the gas is reset to this value before each op-code.
+	PausedGas *uint64
 }
 
 // Host is an EVM executor that runs Forge scripts.
@@ -114,15 +119,35 @@ type Host struct {
 	// useCreate2Deployer uses the Create2Deployer for broadcasted
 	// create2 calls.
 	useCreate2Deployer bool
+
+	// accesses are the recorded storage accesses, if recording with the record cheatcode.
+	accesses *storageAccesses
+	// recordedLogs are the recorded log events, if recordingLogs is enabled with the recordLogs cheatcode.
+	recordedLogs  []Log
+	recordingLogs bool
+	// stateDiff is the state-diff recorder, if recording with the startStateDiffRecording cheatcode.
+	stateDiff *stateDiffRecorder
+
+	// mockedCalls are the mocked call responses by call target, registered with the mockCall cheatcodes.
+	mockedCalls map[common.Address][]*mockedCall
+	// pendingMock is the mocked response of the call op-code that is about to run, if any.
+	pendingMock *pendingMockCall
+
+	// gasMeteringPaused is set with the pauseGasMetering cheatcode, and unset with resumeGasMetering.
+	gasMeteringPaused bool
 }
 
 type HostOption func(h *Host)
 
 type BroadcastHook func(broadcast Broadcast)
 
+// LoadAllocsHook loads the state allocations of the given path, for the loadAllocs cheatcode.
+type LoadAllocsHook func(path string) (*foundry.ForgeAllocs, error)
+
 type Hooks struct {
-	OnBroadcast BroadcastHook
-	OnFork      ForkHook
+	OnBroadcast  BroadcastHook
+	OnFork       ForkHook
+	OnLoadAllocs LoadAllocsHook
 }
 
 func WithBroadcastHook(hook BroadcastHook) HostOption {
@@ -137,6 +162,21 @@ func WithForkHook(hook ForkHook) HostOption {
 	}
 }
 
+// WithLoadAllocsHook enables the loadAllocs cheatcode, loading state allocations with the given hook.
+func WithLoadAllocsHook(hook LoadAllocsHook) HostOption {
+	return func(h *Host) {
+		h.hooks.OnLoadAllocs = hook
+	}
+}
+
+// WithAllocsDir enables the loadAllocs cheatcode, loading state allocation JSON files from the given directory.
+// Paths outside the directory are rejected.
+func WithAllocsDir(dir string) HostOption {
+	return WithLoadAllocsHook(func(path string) (*foundry.ForgeAllocs, error) {
+		return foundry.LoadForgeAllocs(filepath.Join(dir, filepath.Clean("/"+path)))
+	})
+}
+
 // WithIsolatedBroadcasts makes each broadcast clean the context,
 // by flushing the dirty storage changes, and preparing the ephemeral state again.
 // This then produces more accurate gas estimation for broadcast calls.
@@ -182,7 +222,11 @@ func NewHost(
 			OnFork: func(opts *ForkConfig) (forking.ForkSource, error) {
 				return nil, errors.New("no forking configured")
 			},
+			OnLoadAllocs: func(path string) (*foundry.ForgeAllocs, error) {
+				return nil, errors.New("no allocs loading configured")
+			},
 		},
+		mockedCalls:       make(map[common.Address][]*mockedCall),
 		allowedCheatcodes: make(map[common.Address]struct{}),
 	}
 
@@ -270,8 +314,9 @@ func NewHost(
 
 	// Configure the EVM without basefee (because scripting), our trace hooks, and runtime precompile overrides.
 	vmCfg := vm.Config{
-		NoBaseFee: true,
-		Tracer:    tracer,
+		NoBaseFee:                   true,
+		Tracer:                      tracer,
+		OptimismPrecompileOverrides: h.getPrecompile,
 	}
 
 	h.env = WrapEVM(vm.NewEVM(blockContext, txContext, h.state, h.chainCfg, vmCfg))
@@ -465,12 +510,22 @@ func (h *Host) SetStorage(addr common.Address, key common.Hash, value common.Has
 	h.state.SetState(addr, key, value)
 }
 
+// GetStorage returns the value of a storage slot of an account from the state.
+func (h *Host) GetStorage(addr common.Address, key common.Hash) common.Hash {
+	return h.state.GetState(addr, key)
+}
+
 // getPrecompile overrides any accounts during runtime, to insert special precompiles, if activated.
-func (h *Host) getPrecompile(rules params.Rules, original vm.PrecompiledContract, addr common.Address) vm.PrecompiledContract {
+// A mocked call is executed as precompile too, returning the mocked response.
+func (h *Host) getPrecompile(rules params.Rules, original vm.PrecompiledContract, addr common.Address) (vm.PrecompiledContract, bool) {
+	if pending := h.pendingMock; pending != nil && pending.to == addr {
+		h.pendingMock = nil
+		return &mockPrecompile{mock: pending.mock}, true
+	}
 	if p, ok := h.precompiles[addr]; ok {
-		return &AccessControlledPrecompile{h: h, inner: p}
+		return &AccessControlledPrecompile{h: h, inner: p}, true
 	}
-	return original
+	return original, original != nil
 }
 
 // SetPrecompile inserts a precompile at the given address.
@@ -502,6 +557,8 @@ func (h *Host) GetCode(addr common.Address) []byte {
 // onEnter is a trace-hook, which we use to apply changes to the state-DB, to simulate isolated broadcast calls,
 // for better gas estimation of the exact broadcast call execution.
 func (h *Host) onEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	h.recordEnter(depth, typ, from, to, input, value)
+
 	if len(h.callStack) == 0 {
 		return
 	}
@@ -561,7 +618,11 @@ func (h *Host) onExit(depth int, output []byte, gasUsed uint64, err error, rever
 		}
 	}
 
-	h.callStack[len(h.callStack)-1].GasUsed += gasUsed
+	h.recordExit(depth, reverted)
+
+	if len(h.callStack) > 0 {
+		h.callStack[len(h.callStack)-1].GasUsed += gasUsed
+	}
 	h.unwindCallstack(depth)
 }
 
@@ -670,12 +731,35 @@ func (h *Host) onOpcode(pc uint64, op byte, gas, cost uint64, scope *vm.ScopeCon
 	if cf.LastOp == vm.CREATE2 {
 		cf.LastCreate2Salt = scopeCtx.Stack.Back(3).Bytes32()
 	}
+	// Without active cheats, op-codes are not inspected, to keep scripts as fast as without the cheats.
+	if !h.inspectsOpcodes() {
+		h.pendingMock = nil
+		return
+	}
+	h.recordOpcode(cf.LastOp, scopeCtx, depth)
+	h.checkMockedCall(cf.LastOp, scopeCtx)
+	if h.gasMeteringPaused {
+		if cf.PausedGas == nil {
+			cf.PausedGas = &gas
+		}
+		scopeCtx.Contract.Gas = *cf.PausedGas
+	}
+}
+
+// inspectsOpcodes returns whether any cheat needs the op-codes to be inspected:
+// access, log or state-diff recording, mocked calls, or paused gas metering.
+func (h *Host) inspectsOpcodes() bool {
+	return h.accesses != nil || h.recordingLogs || h.stateDiff != nil || len(h.mockedCalls) > 0 || h.gasMeteringPaused
 }
 
 // onStorageChange is a trace-hook to capture state changes
 func (h *Host) onStorageChange(addr common.Address, slot common.Hash, prev, new common.Hash) {
 	h.log.Debug("storage change", "addr", addr, "slot", slot, "prev_value", prev, "new_value", new)
-	// future storage recording
+	if h.accesses != nil {
+		// like forge, a storage write is recorded as a read too
+		h.accesses.reads[addr] = append(h.accesses.reads[addr], slot)
+		h.accesses.writes[addr] = append(h.accesses.writes[addr], slot)
+	}
 }
 
 // onLog is a trace-hook to capture log events
@@ -685,7 +769,13 @@ func (h *Host) onLog(ev *types.Log) {
 		logger = logger.With(fmt.Sprintf("topic%d", i), topic)
 	}
 	logger.Debug("log event", "data", hexutil.Bytes(ev.Data))
-	// future log recording
+	if h.recordingLogs {
+		topics := make([][32]byte, len(ev.Topics))
+		for i, topic := range ev.Topics {
+			topics[i] = topic
+		}
+		h.recordedLogs = append(h.recordedLogs, Log{Topics: topics, Data: ev.Data, Emitter: ev.Address})
+	}
 }
 
 // CurrentCall returns the top of the callstack. Or zeroed if there was no call frame yet.
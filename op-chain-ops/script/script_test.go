@@ -67,6 +67,39 @@ func TestScript(t *testing.T) {
 	require.NoError(t, h.cheatcodes.Precompile.DumpState("noop"))
 }
 
+// TestScriptGasWithoutCheats checks that the op-code hooks of the cheats do not change the gas use of a script,
+// whether they are inactive, like in scripts that do not use them, or active.
+func TestScriptGasWithoutCheats(t *testing.T) {
+	af := foundry.OpenArtifactsDir("./testdata/test-artifacts")
+	runScript := func(t *testing.T, activate func(h *Host)) uint64 {
+		scriptContext := DefaultContext
+		h := NewHost(testlog.Logger(t, log.LevelInfo), af, nil, scriptContext)
+		require.NoError(t, h.EnableCheats())
+		addr, err := h.LoadContract("ScriptExample.s.sol", "ScriptExample")
+		require.NoError(t, err)
+		h.AllowCheatcodes(addr)
+		h.SetEnvVar("EXAMPLE_BOOL", "true")
+		activate(h)
+
+		input := bytes4("run()")
+		returnData, leftOverGas, err := h.Call(scriptContext.Sender, addr, input[:], DefaultFoundryGasLimit, uint256.NewInt(0))
+		require.NoError(t, err, "call failed: %x", string(returnData))
+		return DefaultFoundryGasLimit - leftOverGas
+	}
+
+	inactive := runScript(t, func(h *Host) {
+		require.False(t, h.inspectsOpcodes())
+	})
+	active := runScript(t, func(h *Host) {
+		h.accesses = newStorageAccesses()
+		h.recordingLogs = true
+		h.MockCall(common.Address{0x42}, nil, []byte{0x01}, nil, false)
+		require.True(t, h.inspectsOpcodes())
+	})
+	require.NotZero(t, inactive)
+	require.Equal(t, inactive, active, "cheat hooks must not change the gas use")
+}
+
 func mustEncodeStringCalldata(t *testing.T, method, input string) []byte {
 	packer, err := abi.JSON(strings.NewReader(fmt.Sprintf(`[{"type":"function","name":"%s","inputs":[{"type":"string","name":"input"}]}]`, method)))
 	require.NoError(t, err)
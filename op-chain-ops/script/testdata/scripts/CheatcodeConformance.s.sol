@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.15;
+
+// Vm is a minimal interface to the forge cheatcode precompile,
+// with the cheatcodes that are checked for conformance between forge and the Go script host.
+interface Vm {
+    struct Log {
+        bytes32[] topics;
+        bytes data;
+        address emitter;
+    }
+
+    struct ChainInfo {
+        uint256 forkId;
+        uint256 chainId;
+    }
+
+    struct StorageAccess {
+        address account;
+        bytes32 slot;
+        bool isWrite;
+        bytes32 previousValue;
+        bytes32 newValue;
+        bool reverted;
+    }
+
+    struct AccountAccess {
+        ChainInfo chainInfo;
+        uint8 kind;
+        address account;
+        address accessor;
+        bool initialized;
+        uint256 oldBalance;
+        uint256 newBalance;
+        bytes deployedCode;
+        uint256 value;
+        bytes data;
+        bool reverted;
+        StorageAccess[] storageAccesses;
+        uint64 depth;
+    }
+
+    function record() external;
+    function accesses(address target) external returns (bytes32[] memory readSlots, bytes32[] memory writeSlots);
+    function recordLogs() external;
+    function getRecordedLogs() external returns (Log[] memory logs);
+    function mockCall(address callee, bytes calldata data, bytes calldata returnData) external;
+    function mockCallRevert(address callee, bytes calldata data, bytes calldata revertData) external;
+    function clearMockedCalls() external;
+    function pauseGasMetering() external;
+    function resumeGasMetering() external;
+    function startStateDiffRecording() external;
+    function stopAndReturnStateDiff() external returns (AccountAccess[] memory accountAccesses);
+    function parseJson(string calldata json) external pure returns (bytes memory abiEncodedData);
+    function parseJson(string calldata json, string calldata key) external pure returns (bytes memory abiEncodedData);
+    function parseToml(string calldata toml, string calldata key) external pure returns (bytes memory abiEncodedData);
+}
+
+contract Counter {
+    uint256 public count;
+
+    event Counted(uint256 indexed count, bytes32 note);
+
+    // increment reads and writes the count storage slot exactly once.
+    function increment(bytes32 note) external returns (uint256) {
+        uint256 next = count + 1;
+        count = next;
+        emit Counted(next, note);
+        return next;
+    }
+
+    function fail() external {
+        count = count + 100;
+        revert("fail");
+    }
+}
+
+interface Oracle {
+    function price(uint256 asset) external view returns (uint256);
+}
+
+contract CheatcodeConformance {
+    address internal constant VM_ADDRESS = address(uint160(uint256(keccak256("hevm cheat code"))));
+    Vm internal constant vm = Vm(VM_ADDRESS);
+
+    struct Parsed {
+        uint256 a;
+        address b;
+        string[] c;
+        bool d;
+    }
+
+    // run returns the final count and digests of the state diff and parsed values,
+    // which must be the same with forge and the Go script host.
+    function run() public returns (uint256 count, bytes32 stateDiff, bytes32 parsed) {
+        Counter counter = new Counter();
+        checkRecord(counter);
+        checkRecordLogs(counter);
+        checkMockCall();
+        checkGasMetering(counter);
+        stateDiff = checkStateDiff(counter);
+        parsed = checkParse();
+        count = counter.count();
+    }
+
+    function checkRecord(Counter counter) internal {
+        vm.record();
+        counter.increment(bytes32(0));
+        (bytes32[] memory reads, bytes32[] memory writes) = vm.accesses(address(counter));
+        require(reads.length == 2, "record: expected SLOAD and SSTORE reads");
+        require(writes.length == 1, "record: expected one write");
+        require(reads[0] == bytes32(0) && writes[0] == bytes32(0), "record: unexpected slot");
+    }
+
+    function checkRecordLogs(Counter counter) internal {
+        vm.recordLogs();
+        counter.increment(bytes32(uint256(0xaa)));
+        Vm.Log[] memory logs = vm.getRecordedLogs();
+        require(logs.length == 1, "recordLogs: expected one log");
+        require(logs[0].emitter == address(counter), "recordLogs: unexpected emitter");
+        require(logs[0].topics.length == 2, "recordLogs: expected two topics");
+        require(logs[0].topics[0] == keccak256("Counted(uint256,bytes32)"), "recordLogs: unexpected event");
+        require(uint256(logs[0].topics[1]) == counter.count(), "recordLogs: unexpected count");
+        require(abi.decode(logs[0].data, (bytes32)) == bytes32(uint256(0xaa)), "recordLogs: unexpected data");
+        require(vm.getRecordedLogs().length == 0, "recordLogs: logs must be consumed");
+    }
+
+    function checkMockCall() internal {
+        Oracle oracle = Oracle(address(0x1234));
+        vm.mockCall(address(oracle), abi.encodeCall(Oracle.price, (1)), abi.encode(100));
+        vm.mockCall(address(oracle), abi.encodeWithSelector(Oracle.price.selector), abi.encode(42));
+        require(oracle.price(1) == 100, "mockCall: exact match must take priority");
+        require(oracle.price(2) == 42, "mockCall: expected selector match");
+
+        vm.mockCallRevert(address(oracle), abi.encodeCall(Oracle.price, (3)), abi.encodeWithSignature("Error(string)", "mocked"));
+        try oracle.price(3) returns (uint256) {
+            revert("mockCallRevert: expected revert");
+        } catch Error(string memory reason) {
+            require(keccak256(bytes(reason)) == keccak256("mocked"), "mockCallRevert: unexpected reason");
+        }
+
+        vm.clearMockedCalls();
+        (bool ok, ) = address(oracle).staticcall(abi.encodeCall(Oracle.price, (1)));
+        require(ok, "clearMockedCalls: call to empty code must succeed");
+    }
+
+    function checkGasMetering(Counter counter) internal {
+        vm.pauseGasMetering();
+        uint256 before = gasleft();
+        for (uint256 i = 0; i < 10; i++) {
+            counter.increment(bytes32(i));
+        }
+        uint256 used = before - gasleft();
+        vm.resumeGasMetering();
+        require(used < 1000, "pauseGasMetering: gas must not be metered");
+    }
+
+    function checkStateDiff(Counter counter) internal returns (bytes32 digest) {
+        vm.startStateDiffRecording();
+        counter.increment(bytes32(0));
+        try counter.fail() {} catch {}
+        Vm.AccountAccess[] memory diff = vm.stopAndReturnStateDiff();
+        require(diff.length == 2, "stateDiff: expected two calls");
+        require(diff[0].kind == 0 && diff[0].account == address(counter), "stateDiff: expected call to counter");
+        require(diff[0].accessor == address(this), "stateDiff: unexpected accessor");
+        require(!diff[0].reverted, "stateDiff: first call did not revert");
+        require(diff[0].storageAccesses.length == 2, "stateDiff: expected read and write");
+        require(!diff[0].storageAccesses[0].isWrite, "stateDiff: expected read");
+        require(diff[0].storageAccesses[1].isWrite, "stateDiff: expected write");
+        require(
+            uint256(diff[0].storageAccesses[1].newValue) == uint256(diff[0].storageAccesses[1].previousValue) + 1,
+            "stateDiff: unexpected written value"
+        );
+        require(diff[1].reverted, "stateDiff: second call reverted");
+        require(diff[1].storageAccesses[0].reverted, "stateDiff: storage access of reverted call is reverted");
+        require(diff[0].chainInfo.chainId == block.chainid, "stateDiff: unexpected chain id");
+
+        // The accounts are left out, as the address of the script differs between forge and the Go script host.
+        for (uint256 i = 0; i < diff.length; i++) {
+            Vm.AccountAccess memory access = diff[i];
+            digest = keccak256(
+                abi.encode(
+                    digest, access.kind, access.reverted, access.oldBalance, access.newBalance, access.value, access.data
+                )
+            );
+            for (uint256 j = 0; j < access.storageAccesses.length; j++) {
+                Vm.StorageAccess memory s = access.storageAccesses[j];
+                digest = keccak256(abi.encode(digest, s.slot, s.isWrite, s.previousValue, s.newValue, s.reverted));
+            }
+        }
+    }
+
+    function checkParse() internal pure returns (bytes32) {
+        string memory json = '{"d": true, "b": "0x0000000000000000000000000000000000000001", "a": 42, "c": ["x", "y"]}';
+        Parsed memory parsed = abi.decode(vm.parseJson(json), (Parsed));
+        require(parsed.a == 42, "parseJson: unexpected a");
+        require(parsed.b == address(1), "parseJson: unexpected b");
+        require(parsed.c.length == 2 && keccak256(bytes(parsed.c[1])) == keccak256("y"), "parseJson: unexpected c");
+        require(parsed.d, "parseJson: unexpected d");
+        require(abi.decode(vm.parseJson(json, ".a"), (uint256)) == 42, "parseJson: unexpected key value");
+
+        string memory toml = "a = 42\n[nested]\nvalue = -7\n";
+        int256 value = abi.decode(vm.parseToml(toml, ".nested.value"), (int256));
+        require(value == -7, "parseToml: unexpected value");
+        return keccak256(abi.encode(parsed, value));
+    }
+}
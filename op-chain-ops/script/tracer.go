@@ -11,6 +11,11 @@ import (
 // It delegates to the Host's hook methods.
 type scriptTracer struct {
 	host *Host
+
+	// depth is the call depth of the currently executing call-frame, starting at 1 for the outermost call.
+	// The enter and exit hooks are given the depth of the caller, 0 for the outermost call,
+	// matching the depth of the op-code hook of the calling call-frame.
+	depth int
 }
 
 var _ vm.EVMLogger = (*scriptTracer)(nil)
@@ -22,27 +27,31 @@ func (t *scriptTracer) CaptureStart(env *vm.EVM, from common.Address, to common.
 	} else {
 		typ = byte(vm.CALL)
 	}
+	t.depth = 1
 	t.host.onEnter(0, typ, from, to, input, gas, value)
 }
 
 func (t *scriptTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
-	t.host.onExit(0, output, gasUsed, err, false)
+	t.depth = 0
+	t.host.onExit(0, output, gasUsed, err, err != nil)
 }
 
 func (t *scriptTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
-	t.host.onEnter(1, byte(typ), from, to, input, gas, value)
+	t.host.onEnter(t.depth, byte(typ), from, to, input, gas, value)
+	t.depth++
 }
 
 func (t *scriptTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
-	t.host.onExit(1, output, gasUsed, err, false)
+	t.depth--
+	t.host.onExit(t.depth, output, gasUsed, err, err != nil)
 }
 
 func (t *scriptTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
-	// onOpcode and onStorageChange/onLog are handled here
-	// We can't easily call onOpcode with tracing.OpContext since old geth uses ScopeContext
+	t.host.onOpcode(pc, byte(op), gas, cost, scope, rData, depth, err)
 }
 
 func (t *scriptTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	t.host.onFault(pc, byte(op), gas, cost, scope, depth, err)
 }
 
 func (t *scriptTracer) CaptureTxStart(gasLimit uint64) {}
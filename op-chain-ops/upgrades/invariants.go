@@ -0,0 +1,85 @@
+package upgrades
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Invariant is a property of the L1 contracts of a chain that an upgrade must not break.
+// Check is given the state before and after the upgrade, and returns an error if the property does not hold.
+type Invariant struct {
+	Name  string
+	Check func(pre, post *Snapshot) error
+}
+
+// DefaultInvariants are the invariants that are checked after simulating an upgrade batch.
+var DefaultInvariants = []Invariant{
+	{Name: "implementations are upgraded to the target", Check: checkTargetImplementations},
+	{Name: "contract versions match the target", Check: checkTargetVersions},
+	{Name: "proxy admins are unchanged", Check: checkProxyAdmins},
+	{Name: "contracts are initialized", Check: checkInitialized},
+	{Name: "system config is preserved", Check: checkSystemConfig},
+}
+
+// forEachContract runs the check on the pre and post state of each contract, and joins the errors.
+func forEachContract(pre, post *Snapshot, check func(before, after *ContractState) error) error {
+	if len(pre.Contracts) != len(post.Contracts) {
+		return fmt.Errorf("snapshot mismatch: %d contracts before, %d after", len(pre.Contracts), len(post.Contracts))
+	}
+	var errs []error
+	for i := range pre.Contracts {
+		if err := check(&pre.Contracts[i], &post.Contracts[i]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", post.Contracts[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkTargetImplementations(pre, post *Snapshot) error {
+	return forEachContract(pre, post, func(before, after *ContractState) error {
+		if after.Implementation != after.TargetImplementation {
+			return fmt.Errorf("implementation is %s, expected %s", after.Implementation, after.TargetImplementation)
+		}
+		if after.ImplementationCodeSize == 0 {
+			return fmt.Errorf("no code at implementation %s", after.Implementation)
+		}
+		return nil
+	})
+}
+
+func checkTargetVersions(pre, post *Snapshot) error {
+	return forEachContract(pre, post, func(before, after *ContractState) error {
+		if !cmpVersion(after.Version, after.TargetVersion) {
+			return fmt.Errorf("version is %q, expected %q", after.Version, after.TargetVersion)
+		}
+		return nil
+	})
+}
+
+func checkProxyAdmins(pre, post *Snapshot) error {
+	return forEachContract(pre, post, func(before, after *ContractState) error {
+		if before.Admin != after.Admin {
+			return fmt.Errorf("admin changed from %s to %s", before.Admin, after.Admin)
+		}
+		return nil
+	})
+}
+
+func checkInitialized(pre, post *Snapshot) error {
+	return forEachContract(pre, post, func(before, after *ContractState) error {
+		if after.Initialized == 0 {
+			return errors.New("not initialized")
+		}
+		if after.Initialized < before.Initialized {
+			return fmt.Errorf("initialized version decreased from %d to %d", before.Initialized, after.Initialized)
+		}
+		return nil
+	})
+}
+
+func checkSystemConfig(pre, post *Snapshot) error {
+	if !pre.SystemConfig.Equal(&post.SystemConfig) {
+		return fmt.Errorf("changed from %+v to %+v", pre.SystemConfig, post.SystemConfig)
+	}
+	return nil
+}
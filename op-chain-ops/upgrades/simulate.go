@@ -0,0 +1,474 @@
+package upgrades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/safe"
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/script"
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/script/forking"
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/upgrades/bindings"
+
+	"github.com/ethereum-optimism/superchain-registry/superchain"
+)
+
+const (
+	// simulationGas is the gas limit of each simulated batch transaction.
+	simulationGas = 30_000_000
+
+	// simulationForkAlias is the alias of the L1 fork that the batch is simulated on.
+	simulationForkAlias = "l1"
+)
+
+var (
+	// implementationSlot is the EIP-1967 storage slot of the proxy implementation address.
+	implementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	// adminSlot is the EIP-1967 storage slot of the proxy admin address.
+	adminSlot = common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103")
+	// initializedSlot is the storage slot that holds the Initializable `_initialized` version.
+	initializedSlot = common.Hash{}
+)
+
+// SimulationConfig configures the simulation of an upgrade batch on a fork of L1.
+type SimulationConfig struct {
+	// Client is used to fetch the L1 state that the fork is based on.
+	Client forking.RPCClient
+	// Header is the L1 block to fork from. The batch is executed on top of its post-state.
+	Header *types.Header
+	// L1ChainID is the chain ID of the L1 chain.
+	L1ChainID *big.Int
+	// Invariants are checked after the batch is executed. DefaultInvariants are used if nil.
+	Invariants []Invariant
+}
+
+// upgradedContract is an upgradeable L1 contract of a chain that is part of the upgrade.
+type upgradedContract struct {
+	name   string
+	proxy  common.Address
+	target superchain.VersionedContract
+	// initializedOffset is the offset of the `_initialized` byte in the initializedSlot,
+	// counted from the lowest-order byte.
+	initializedOffset int
+}
+
+// upgradedContracts lists the L1 contracts that are upgraded by L1.
+func upgradedContracts(implementations superchain.ImplementationList, list superchain.AddressList) []upgradedContract {
+	return []upgradedContract{
+		// The CrossDomainMessenger packs a legacy 20-byte spacer before the Initializable fields.
+		{name: "L1CrossDomainMessenger", proxy: common.Address(list.L1CrossDomainMessengerProxy), target: implementations.L1CrossDomainMessenger, initializedOffset: 20},
+		{name: "L1ERC721Bridge", proxy: common.Address(list.L1ERC721BridgeProxy), target: implementations.L1ERC721Bridge},
+		{name: "L1StandardBridge", proxy: common.Address(list.L1StandardBridgeProxy), target: implementations.L1StandardBridge},
+		{name: "L2OutputOracle", proxy: common.Address(list.L2OutputOracleProxy), target: implementations.L2OutputOracle},
+		{name: "OptimismMintableERC20Factory", proxy: common.Address(list.OptimismMintableERC20FactoryProxy), target: implementations.OptimismMintableERC20Factory},
+		{name: "OptimismPortal", proxy: common.Address(list.OptimismPortalProxy), target: implementations.OptimismPortal},
+		{name: "SystemConfig", proxy: common.Address(list.SystemConfigProxy), target: implementations.SystemConfig},
+	}
+}
+
+// ContractState is the state of an upgradeable L1 contract that is relevant to an upgrade.
+type ContractState struct {
+	Name                   string
+	Proxy                  common.Address
+	Implementation         common.Address
+	ImplementationCodeSize int
+	Admin                  common.Address
+	Initialized            uint8
+	// Version is the result of version(), or empty if the contract does not implement it.
+	Version string
+	// TargetImplementation and TargetVersion are the implementation that the batch upgrades to.
+	TargetImplementation common.Address
+	TargetVersion        string
+}
+
+// SystemConfigState is the chain configuration in the SystemConfig.
+// The configuration is expected to be preserved by upgrades.
+type SystemConfigState struct {
+	Owner             common.Address
+	Overhead          *big.Int
+	Scalar            *big.Int
+	BatcherHash       common.Hash
+	GasLimit          uint64
+	UnsafeBlockSigner common.Address
+}
+
+// Equal returns whether the configuration matches the other configuration.
+func (s *SystemConfigState) Equal(o *SystemConfigState) bool {
+	return s.Owner == o.Owner &&
+		s.Overhead.Cmp(o.Overhead) == 0 &&
+		s.Scalar.Cmp(o.Scalar) == 0 &&
+		s.BatcherHash == o.BatcherHash &&
+		s.GasLimit == o.GasLimit &&
+		s.UnsafeBlockSigner == o.UnsafeBlockSigner
+}
+
+// Snapshot is the state of the L1 contracts of a chain, before or after an upgrade.
+type Snapshot struct {
+	Contracts    []ContractState
+	SystemConfig SystemConfigState
+}
+
+// StorageChange is a change of a storage slot by the batch.
+type StorageChange struct {
+	Slot   common.Hash
+	Label  string
+	Before common.Hash
+	After  common.Hash
+}
+
+// FieldChange is the before and after value of a contract field that is relevant to the upgrade.
+type FieldChange struct {
+	Name   string
+	Before string
+	After  string
+}
+
+// Changed returns whether the field was modified by the batch.
+func (f *FieldChange) Changed() bool {
+	return f.Before != f.After
+}
+
+// ContractDiff is the state diff of a single contract by the batch.
+type ContractDiff struct {
+	Name    string
+	Address common.Address
+	Fields  []FieldChange
+	Storage []StorageChange
+}
+
+// InvariantResult is the outcome of a single invariant check.
+type InvariantResult struct {
+	Name string
+	Err  error
+}
+
+// Report is the outcome of simulating an upgrade batch.
+type Report struct {
+	Chain       string
+	BlockNumber uint64
+	Pre         *Snapshot
+	Post        *Snapshot
+	Diffs       []ContractDiff
+	Invariants  []InvariantResult
+}
+
+// Err returns an error if any of the invariants failed.
+func (r *Report) Err() error {
+	var errs []error
+	for _, res := range r.Invariants {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Name, res.Err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s: %d invariant(s) failed: %w", r.Chain, len(errs), errors.Join(errs...))
+}
+
+// Write writes a human-readable representation of the report.
+func (r *Report) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Upgrade simulation of %s on L1 block %d\n", r.Chain, r.BlockNumber); err != nil {
+		return err
+	}
+	for _, diff := range r.Diffs {
+		if _, err := fmt.Fprintf(w, "\n%s (%s)\n", diff.Name, diff.Address); err != nil {
+			return err
+		}
+		for _, f := range diff.Fields {
+			var err error
+			if f.Changed() {
+				_, err = fmt.Fprintf(w, "  ~ %s: %s -> %s\n", f.Name, f.Before, f.After)
+			} else {
+				_, err = fmt.Fprintf(w, "  = %s: %s\n", f.Name, f.Before)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, s := range diff.Storage {
+			label := s.Slot.String()
+			if s.Label != "" {
+				label = fmt.Sprintf("%s (%s)", s.Label, s.Slot)
+			}
+			if _, err := fmt.Fprintf(w, "  storage %s\n    - %s\n    + %s\n", label, s.Before, s.After); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := fmt.Fprintln(w, "\nInvariants"); err != nil {
+		return err
+	}
+	for _, res := range r.Invariants {
+		var err error
+		if res.Err != nil {
+			_, err = fmt.Fprintf(w, "  FAIL %s: %v\n", res.Name, res.Err)
+		} else {
+			_, err = fmt.Fprintf(w, "  ok   %s\n", res.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SimulateBatch executes the batch transactions on a fork of L1, as sent by the owner of the ProxyAdmin,
+// and reports the resulting state diff and the outcome of the invariant checks.
+// An error is returned if the simulation could not be run; broken invariants are reported by Report.Err.
+func SimulateBatch(logger log.Logger, cfg *SimulationConfig, chainName string, txs []safe.BatchTransaction, implementations superchain.ImplementationList, list superchain.AddressList) (*Report, error) {
+	h := script.NewHost(logger, nil, nil, script.Context{
+		ChainID:   cfg.L1ChainID,
+		GasLimit:  cfg.Header.GasLimit,
+		BlockNum:  cfg.Header.Number.Uint64(),
+		Timestamp: cfg.Header.Time,
+	}, script.WithForkHook(func(opts *script.ForkConfig) (forking.ForkSource, error) {
+		num := cfg.Header.Number.Uint64()
+		if opts.BlockNumber != nil {
+			num = *opts.BlockNumber
+		}
+		return forking.RPCSourceByNumber(opts.URLOrAlias, cfg.Client, num)
+	}))
+	if _, err := h.CreateSelectFork(script.ForkWithURLOrAlias(simulationForkAlias), script.ForkWithBlockNumberU256(cfg.Header.Number)); err != nil {
+		return nil, fmt.Errorf("failed to fork L1: %w", err)
+	}
+
+	contracts := upgradedContracts(implementations, list)
+	caller := &hostCaller{h: h}
+	proxyAdmin, err := bindings.NewProxyAdminCaller(common.Address(list.ProxyAdmin), caller)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := proxyAdmin.Owner(&bind.CallOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ProxyAdmin owner: %w", err)
+	}
+
+	pre, err := takeSnapshot(h, caller, proxyAdmin, contracts, common.Address(list.SystemConfigProxy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot pre-upgrade state: %w", err)
+	}
+
+	h.StartStateDiffRecording()
+	var txErrs []error
+	for i, tx := range txs {
+		if len(tx.Data) == 0 {
+			return nil, fmt.Errorf("batch transaction %d (%s) has no calldata", i, tx.Method)
+		}
+		value := new(uint256.Int)
+		if tx.Value != nil {
+			value = uint256.MustFromBig(tx.Value)
+		}
+		if _, _, err := h.Call(owner, tx.To, tx.Data, simulationGas, value); err != nil {
+			txErrs = append(txErrs, fmt.Errorf("transaction %d (%s to %s): %w", i, tx.Method, tx.To, err))
+		}
+	}
+	accesses, err := h.StopAndReturnStateDiff()
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := takeSnapshot(h, caller, proxyAdmin, contracts, common.Address(list.SystemConfigProxy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot post-upgrade state: %w", err)
+	}
+
+	invariants := cfg.Invariants
+	if invariants == nil {
+		invariants = DefaultInvariants
+	}
+	report := &Report{
+		Chain:       chainName,
+		BlockNumber: cfg.Header.Number.Uint64(),
+		Pre:         pre,
+		Post:        post,
+		Diffs:       diffContracts(pre, post, storageChanges(accesses), common.Address(list.SystemConfigProxy)),
+	}
+	report.Invariants = append(report.Invariants, InvariantResult{Name: "batch transactions succeed", Err: errors.Join(txErrs...)})
+	for _, inv := range invariants {
+		report.Invariants = append(report.Invariants, InvariantResult{Name: inv.Name, Err: inv.Check(pre, post)})
+	}
+	return report, nil
+}
+
+// takeSnapshot reads the upgrade-relevant state of the contracts from the simulation host.
+func takeSnapshot(h *script.Host, caller bind.ContractCaller, proxyAdmin *bindings.ProxyAdminCaller, contracts []upgradedContract, systemConfigAddr common.Address) (*Snapshot, error) {
+	opts := &bind.CallOpts{}
+	snap := &Snapshot{}
+	for _, c := range contracts {
+		impl, err := proxyAdmin.GetProxyImplementation(opts, c.proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to read implementation: %w", c.name, err)
+		}
+		admin, err := proxyAdmin.GetProxyAdmin(opts, c.proxy)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to read admin: %w", c.name, err)
+		}
+		state := ContractState{
+			Name:                   c.name,
+			Proxy:                  c.proxy,
+			Implementation:         impl,
+			ImplementationCodeSize: len(h.GetCode(impl)),
+			Admin:                  admin,
+			Initialized:            h.GetStorage(c.proxy, initializedSlot)[common.HashLength-1-c.initializedOffset],
+			TargetImplementation:   common.Address(c.target.Address),
+			TargetVersion:          c.target.Version,
+		}
+		if semver, err := bindings.NewISemverCaller(c.proxy, caller); err == nil {
+			// Contracts from before versioning was introduced have no version, and are reported without.
+			state.Version, _ = semver.Version(opts)
+		}
+		snap.Contracts = append(snap.Contracts, state)
+	}
+
+	sysCfg, err := bindings.NewSystemConfigCaller(systemConfigAddr, caller)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	collect := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s := &snap.SystemConfig
+	s.Owner, err = sysCfg.Owner(opts)
+	collect(err)
+	s.Overhead, err = sysCfg.Overhead(opts)
+	collect(err)
+	s.Scalar, err = sysCfg.Scalar(opts)
+	collect(err)
+	s.BatcherHash, err = sysCfg.BatcherHash(opts)
+	collect(err)
+	s.GasLimit, err = sysCfg.GasLimit(opts)
+	collect(err)
+	s.UnsafeBlockSigner, err = sysCfg.UnsafeBlockSigner(opts)
+	collect(err)
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("SystemConfig: %w", err)
+	}
+	return snap, nil
+}
+
+// storageChanges aggregates the recorded storage writes into the net change per account and slot.
+// Writes of reverted calls are ignored, and slots that end up with their original value are omitted.
+func storageChanges(accesses []script.AccountAccess) map[common.Address][]StorageChange {
+	type slotKey struct {
+		account common.Address
+		slot    common.Hash
+	}
+	var order []slotKey
+	changes := make(map[slotKey]*StorageChange)
+	for _, access := range accesses {
+		for _, sa := range access.StorageAccesses {
+			if !sa.IsWrite || sa.Reverted {
+				continue
+			}
+			key := slotKey{account: sa.Account, slot: sa.Slot}
+			change, ok := changes[key]
+			if !ok {
+				change = &StorageChange{Slot: sa.Slot, Label: slotLabel(sa.Slot), Before: sa.PreviousValue}
+				changes[key] = change
+				order = append(order, key)
+			}
+			change.After = sa.NewValue
+		}
+	}
+	out := make(map[common.Address][]StorageChange)
+	for _, key := range order {
+		if change := changes[key]; change.Before != change.After {
+			out[key.account] = append(out[key.account], *change)
+		}
+	}
+	return out
+}
+
+// slotLabel returns a human-readable name of well-known proxy storage slots.
+func slotLabel(slot common.Hash) string {
+	switch slot {
+	case implementationSlot:
+		return "EIP-1967 implementation"
+	case adminSlot:
+		return "EIP-1967 admin"
+	case initializedSlot:
+		return "slot 0, initialized"
+	default:
+		return ""
+	}
+}
+
+// diffContracts builds the per-contract diff from the snapshots and the storage changes.
+// Storage changes of accounts that are not one of the upgraded contracts are reported as separate diffs.
+func diffContracts(pre, post *Snapshot, storage map[common.Address][]StorageChange, systemConfigAddr common.Address) []ContractDiff {
+	var diffs []ContractDiff
+	known := make(map[common.Address]struct{})
+	for i, before := range pre.Contracts {
+		after := post.Contracts[i]
+		known[before.Proxy] = struct{}{}
+		diff := ContractDiff{
+			Name:    before.Name,
+			Address: before.Proxy,
+			Fields: []FieldChange{
+				{Name: "implementation", Before: before.Implementation.String(), After: after.Implementation.String()},
+				{Name: "admin", Before: before.Admin.String(), After: after.Admin.String()},
+				{Name: "initialized", Before: fmt.Sprint(before.Initialized), After: fmt.Sprint(after.Initialized)},
+				{Name: "version", Before: before.Version, After: after.Version},
+			},
+			Storage: storage[before.Proxy],
+		}
+		if before.Proxy == systemConfigAddr {
+			b, a := &pre.SystemConfig, &post.SystemConfig
+			diff.Fields = append(diff.Fields,
+				FieldChange{Name: "owner", Before: b.Owner.String(), After: a.Owner.String()},
+				FieldChange{Name: "overhead", Before: b.Overhead.String(), After: a.Overhead.String()},
+				FieldChange{Name: "scalar", Before: b.Scalar.String(), After: a.Scalar.String()},
+				FieldChange{Name: "batcherHash", Before: b.BatcherHash.String(), After: a.BatcherHash.String()},
+				FieldChange{Name: "gasLimit", Before: fmt.Sprint(b.GasLimit), After: fmt.Sprint(a.GasLimit)},
+				FieldChange{Name: "unsafeBlockSigner", Before: b.UnsafeBlockSigner.String(), After: a.UnsafeBlockSigner.String()},
+			)
+		}
+		diffs = append(diffs, diff)
+	}
+	for addr, changes := range storage {
+		if _, ok := known[addr]; ok {
+			continue
+		}
+		diffs = append(diffs, ContractDiff{Name: "unknown", Address: addr, Storage: changes})
+	}
+	// keep the output deterministic, the storage map is unordered
+	slices.SortFunc(diffs[len(pre.Contracts):], func(a, b ContractDiff) int {
+		return a.Address.Cmp(b.Address)
+	})
+	return diffs
+}
+
+// hostCaller implements bind.ContractCaller on top of the script host,
+// such that the contract bindings can be used to read the simulated state.
+type hostCaller struct {
+	h *script.Host
+}
+
+var _ bind.ContractCaller = (*hostCaller)(nil)
+
+func (c *hostCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.h.GetCode(contract), nil
+}
+
+func (c *hostCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if call.To == nil {
+		return nil, errors.New("contract creation is not supported")
+	}
+	ret, _, err := c.h.Call(call.From, *call.To, call.Data, simulationGas, new(uint256.Int))
+	return ret, err
+}
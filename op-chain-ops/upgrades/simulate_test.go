@@ -0,0 +1,181 @@
+package upgrades
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/script"
+)
+
+var errBroken = errors.New("broken")
+
+func testSnapshots() (pre, post *Snapshot) {
+	systemConfig := SystemConfigState{
+		Owner:             common.Address{0xaa},
+		Overhead:          big.NewInt(188),
+		Scalar:            big.NewInt(684000),
+		BatcherHash:       common.Hash{0xbb},
+		GasLimit:          30_000_000,
+		UnsafeBlockSigner: common.Address{0xcc},
+	}
+	pre = &Snapshot{
+		Contracts: []ContractState{{
+			Name:                   "SystemConfig",
+			Proxy:                  common.Address{0x01},
+			Implementation:         common.Address{0x02},
+			ImplementationCodeSize: 100,
+			Admin:                  common.Address{0x03},
+			Initialized:            1,
+			Version:                "1.3.0",
+			TargetImplementation:   common.Address{0x04},
+			TargetVersion:          "1.12.0",
+		}},
+		SystemConfig: systemConfig,
+	}
+	post = &Snapshot{
+		Contracts: []ContractState{{
+			Name:                   "SystemConfig",
+			Proxy:                  common.Address{0x01},
+			Implementation:         common.Address{0x04},
+			ImplementationCodeSize: 200,
+			Admin:                  common.Address{0x03},
+			Initialized:            1,
+			Version:                "1.12.0",
+			TargetImplementation:   common.Address{0x04},
+			TargetVersion:          "1.12.0",
+		}},
+		SystemConfig: systemConfig,
+	}
+	return pre, post
+}
+
+func checkInvariants(pre, post *Snapshot) map[string]error {
+	out := make(map[string]error)
+	for _, inv := range DefaultInvariants {
+		if err := inv.Check(pre, post); err != nil {
+			out[inv.Name] = err
+		}
+	}
+	return out
+}
+
+func TestDefaultInvariants(t *testing.T) {
+	pre, post := testSnapshots()
+	require.Empty(t, checkInvariants(pre, post))
+
+	t.Run("wrong implementation", func(t *testing.T) {
+		pre, post := testSnapshots()
+		post.Contracts[0].Implementation = common.Address{0x05}
+		failed := checkInvariants(pre, post)
+		require.Len(t, failed, 1)
+		require.ErrorContains(t, failed["implementations are upgraded to the target"], "SystemConfig: implementation is")
+	})
+
+	t.Run("implementation without code", func(t *testing.T) {
+		pre, post := testSnapshots()
+		post.Contracts[0].ImplementationCodeSize = 0
+		require.Contains(t, checkInvariants(pre, post), "implementations are upgraded to the target")
+	})
+
+	t.Run("wrong version", func(t *testing.T) {
+		pre, post := testSnapshots()
+		post.Contracts[0].Version = "1.11.0"
+		require.Contains(t, checkInvariants(pre, post), "contract versions match the target")
+
+		// versions may be prefixed with a v
+		post.Contracts[0].Version = "v1.12.0"
+		require.Empty(t, checkInvariants(pre, post))
+	})
+
+	t.Run("admin changed", func(t *testing.T) {
+		pre, post := testSnapshots()
+		post.Contracts[0].Admin = common.Address{0x06}
+		require.Contains(t, checkInvariants(pre, post), "proxy admins are unchanged")
+	})
+
+	t.Run("initialized", func(t *testing.T) {
+		pre, post := testSnapshots()
+		post.Contracts[0].Initialized = 0
+		require.ErrorContains(t, checkInvariants(pre, post)["contracts are initialized"], "not initialized")
+
+		pre.Contracts[0].Initialized = 3
+		post.Contracts[0].Initialized = 2
+		require.ErrorContains(t, checkInvariants(pre, post)["contracts are initialized"], "decreased")
+	})
+
+	t.Run("system config changed", func(t *testing.T) {
+		pre, post := testSnapshots()
+		post.SystemConfig.Scalar = big.NewInt(1)
+		require.Contains(t, checkInvariants(pre, post), "system config is preserved")
+	})
+}
+
+func TestStorageChanges(t *testing.T) {
+	proxy := common.Address{0x01}
+	other := common.Address{0x02}
+	slot1 := [32]byte{31: 1}
+	accesses := []script.AccountAccess{
+		{Account: proxy, StorageAccesses: []script.StorageAccess{
+			{Account: proxy, Slot: slot1},
+			{Account: proxy, Slot: implementationSlot, IsWrite: true, PreviousValue: [32]byte{31: 0xa}, NewValue: [32]byte{31: 0xb}},
+			{Account: proxy, Slot: initializedSlot, IsWrite: true, PreviousValue: [32]byte{31: 1}, NewValue: [32]byte{}},
+		}},
+		{Account: proxy, StorageAccesses: []script.StorageAccess{
+			// set back to the original value: not a change
+			{Account: proxy, Slot: initializedSlot, IsWrite: true, PreviousValue: [32]byte{}, NewValue: [32]byte{31: 1}},
+			// reverted writes are ignored
+			{Account: proxy, Slot: slot1, IsWrite: true, NewValue: [32]byte{31: 0xff}, Reverted: true},
+		}},
+		{Account: other, StorageAccesses: []script.StorageAccess{
+			{Account: other, Slot: slot1, IsWrite: true, NewValue: [32]byte{31: 0x42}},
+		}},
+	}
+	changes := storageChanges(accesses)
+	require.Equal(t, map[common.Address][]StorageChange{
+		proxy: {{Slot: implementationSlot, Label: "EIP-1967 implementation", Before: common.Hash{31: 0xa}, After: common.Hash{31: 0xb}}},
+		other: {{Slot: slot1, After: common.Hash{31: 0x42}}},
+	}, changes)
+
+	pre, post := testSnapshots()
+	diffs := diffContracts(pre, post, changes, proxy)
+	require.Len(t, diffs, 2)
+	require.Equal(t, "SystemConfig", diffs[0].Name)
+	require.Equal(t, changes[proxy], diffs[0].Storage)
+	require.Equal(t, FieldChange{Name: "implementation", Before: common.Address{0x02}.String(), After: common.Address{0x04}.String()}, diffs[0].Fields[0])
+	require.True(t, diffs[0].Fields[0].Changed())
+	require.Contains(t, diffs[0].Fields, FieldChange{Name: "gasLimit", Before: "30000000", After: "30000000"})
+	require.Equal(t, ContractDiff{Name: "unknown", Address: other, Storage: changes[other]}, diffs[1])
+}
+
+func TestReport(t *testing.T) {
+	pre, post := testSnapshots()
+	report := &Report{
+		Chain:       "Test",
+		BlockNumber: 123,
+		Diffs:       diffContracts(pre, post, nil, common.Address{0x01}),
+		Invariants: []InvariantResult{
+			{Name: "passes"},
+			{Name: "breaks", Err: errBroken},
+		},
+	}
+	require.ErrorIs(t, report.Err(), errBroken)
+	require.ErrorContains(t, report.Err(), "Test: 1 invariant(s) failed")
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+	out := buf.String()
+	require.Contains(t, out, "Upgrade simulation of Test on L1 block 123")
+	require.Contains(t, out, "~ version: 1.3.0 -> 1.12.0")
+	require.Contains(t, out, "= owner: "+common.Address{0xaa}.String())
+	require.Contains(t, out, "ok   passes")
+	require.Contains(t, out, "FAIL breaks: broken")
+
+	report.Invariants = report.Invariants[:1]
+	require.NoError(t, report.Err())
+}
@@ -0,0 +1,8 @@
+// Package withdrawals finds the L2 to L1 withdrawals of a set of addresses
+// that have not been completed yet, and proves or finalizes them on L1.
+//
+// Withdrawals are discovered by their MessagePassed events of the
+// L2ToL1MessagePasser. The status of each withdrawal is read from the
+// OptimismPortal, which may be backed by either the L2OutputOracle or
+// the DisputeGameFactory.
+package withdrawals
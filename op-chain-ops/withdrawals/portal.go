@@ -0,0 +1,336 @@
+package withdrawals
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	bindingspreview "github.com/tokamak-network/tokamak-thanos/op-node/bindings/preview"
+	opwithdrawals "github.com/tokamak-network/tokamak-thanos/op-node/withdrawals"
+)
+
+type Status string
+
+const (
+	// StatusWaitingForOutput is the status of withdrawals that are not covered by any L2 output yet.
+	StatusWaitingForOutput Status = "waiting-for-output"
+	StatusReadyToProve     Status = "ready-to-prove"
+	// StatusProven is the status of proven withdrawals that cannot be finalized yet.
+	StatusProven          Status = "proven"
+	StatusReadyToFinalize Status = "ready-to-finalize"
+	StatusFinalized       Status = "finalized"
+)
+
+// State is the state of a withdrawal on L1.
+type State struct {
+	Status Status `json:"status"`
+	// ProofSubmitter is the account that proved the withdrawal, it is only known with fault proofs.
+	ProofSubmitter *common.Address `json:"proofSubmitter,omitempty"`
+	// Reason explains why the withdrawal cannot progress, or why it must be proven again.
+	Reason string `json:"reason,omitempty"`
+}
+
+type OutputRootProof struct {
+	Version                  common.Hash `json:"version"`
+	StateRoot                common.Hash `json:"stateRoot"`
+	MessagePasserStorageRoot common.Hash `json:"messagePasserStorageRoot"`
+	LatestBlockhash          common.Hash `json:"latestBlockhash"`
+}
+
+// Proof is the set of parameters to prove a withdrawal with.
+type Proof struct {
+	// L2OutputIndex is the index of the output proposal, or of the dispute game with fault proofs.
+	L2OutputIndex   *big.Int        `json:"l2OutputIndex"`
+	L2BlockNumber   uint64          `json:"l2BlockNumber"`
+	OutputRootProof OutputRootProof `json:"outputRootProof"`
+	WithdrawalProof []hexutil.Bytes `json:"withdrawalProof"`
+}
+
+func (p *Proof) bindings() (bindings.TypesOutputRootProof, [][]byte) {
+	trieNodes := make([][]byte, len(p.WithdrawalProof))
+	for i, node := range p.WithdrawalProof {
+		trieNodes[i] = node
+	}
+	return bindings.TypesOutputRootProof{
+		Version:                  p.OutputRootProof.Version,
+		StateRoot:                p.OutputRootProof.StateRoot,
+		MessagePasserStorageRoot: p.OutputRootProof.MessagePasserStorageRoot,
+		LatestBlockhash:          p.OutputRootProof.LatestBlockhash,
+	}, trieNodes
+}
+
+// Portal reads the state of withdrawals from the OptimismPortal, and proves them against its outputs.
+type Portal interface {
+	Address() common.Address
+	FaultProofs() bool
+	// State returns the state of the withdrawal, where l1Time is the timestamp of the L1 head.
+	State(ctx context.Context, w *Withdrawal, l1Time uint64) (State, error)
+	// Proof proves the withdrawal against the first output that includes it.
+	Proof(ctx context.Context, w *Withdrawal) (*Proof, error)
+}
+
+// NewPortal creates the Portal of the OptimismPortal at the address. The portal is backed by
+// the DisputeGameFactory if it has one, otherwise by its L2OutputOracle.
+func NewPortal(ctx context.Context, l1 bind.ContractCaller, l2Proofs opwithdrawals.ProofClient, l2Headers opwithdrawals.HeaderClient, addr common.Address) (Portal, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	portal2, err := bindingspreview.NewOptimismPortal2Caller(addr, l1)
+	if err != nil {
+		return nil, err
+	}
+	if factoryAddr, err := portal2.DisputeGameFactory(opts); err == nil && factoryAddr != (common.Address{}) {
+		factory, err := bindings.NewDisputeGameFactoryCaller(factoryAddr, l1)
+		if err != nil {
+			return nil, err
+		}
+		return &faultProofPortal{
+			addr:      addr,
+			portal:    portal2,
+			factory:   factory,
+			l2Proofs:  l2Proofs,
+			l2Headers: l2Headers,
+		}, nil
+	}
+
+	portal, err := bindings.NewOptimismPortalCaller(addr, l1)
+	if err != nil {
+		return nil, err
+	}
+	oracleAddr, err := portal.L2Oracle(opts)
+	if err != nil {
+		return nil, fmt.Errorf("portal %s has neither a dispute game factory nor an output oracle: %w", addr, err)
+	}
+	oracle, err := bindings.NewL2OutputOracleCaller(oracleAddr, l1)
+	if err != nil {
+		return nil, err
+	}
+	return &outputOraclePortal{
+		addr:      addr,
+		portal:    portal,
+		oracle:    oracle,
+		l2Proofs:  l2Proofs,
+		l2Headers: l2Headers,
+	}, nil
+}
+
+type outputOraclePortal struct {
+	addr      common.Address
+	portal    *bindings.OptimismPortalCaller
+	oracle    *bindings.L2OutputOracleCaller
+	l2Proofs  opwithdrawals.ProofClient
+	l2Headers opwithdrawals.HeaderClient
+}
+
+func (p *outputOraclePortal) Address() common.Address {
+	return p.addr
+}
+
+func (p *outputOraclePortal) FaultProofs() bool {
+	return false
+}
+
+func (p *outputOraclePortal) State(ctx context.Context, w *Withdrawal, l1Time uint64) (State, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	finalized, err := p.portal.FinalizedWithdrawals(opts, w.Hash)
+	if err != nil {
+		return State{}, fmt.Errorf("failed to get finalized status: %w", err)
+	}
+	if finalized {
+		return State{Status: StatusFinalized}, nil
+	}
+
+	proven, err := p.portal.ProvenWithdrawals(opts, w.Hash)
+	if err != nil {
+		return State{}, fmt.Errorf("failed to get proven status: %w", err)
+	}
+	if proven.Timestamp.Sign() != 0 {
+		latestIndex, err := p.oracle.LatestOutputIndex(opts)
+		if err != nil {
+			return State{}, fmt.Errorf("failed to get latest output index: %w", err)
+		}
+		if proven.L2OutputIndex.Cmp(latestIndex) > 0 {
+			return State{Status: StatusReadyToProve, Reason: "proven output was deleted"}, nil
+		}
+		output, err := p.oracle.GetL2Output(opts, proven.L2OutputIndex)
+		if err != nil {
+			return State{}, fmt.Errorf("failed to get output %v: %w", proven.L2OutputIndex, err)
+		}
+		if output.OutputRoot != proven.OutputRoot {
+			return State{Status: StatusReadyToProve, Reason: "proven output root was replaced"}, nil
+		}
+		period, err := p.oracle.FinalizationPeriodSeconds(opts)
+		if err != nil {
+			return State{}, fmt.Errorf("failed to get finalization period: %w", err)
+		}
+		// Both the proof and the output must be older than the finalization period.
+		start := proven.Timestamp
+		if output.Timestamp.Cmp(start) > 0 {
+			start = output.Timestamp
+		}
+		end := new(big.Int).Add(start, period)
+		if end.Cmp(new(big.Int).SetUint64(l1Time)) > 0 {
+			return State{Status: StatusProven, Reason: fmt.Sprintf("finalization period ends at %v", end)}, nil
+		}
+		return State{Status: StatusReadyToFinalize}, nil
+	}
+
+	latest, err := p.oracle.LatestBlockNumber(opts)
+	if err != nil {
+		return State{}, fmt.Errorf("failed to get latest output block: %w", err)
+	}
+	if latest.Cmp(new(big.Int).SetUint64(w.L2Block)) < 0 {
+		return State{Status: StatusWaitingForOutput}, nil
+	}
+	return State{Status: StatusReadyToProve}, nil
+}
+
+func (p *outputOraclePortal) Proof(ctx context.Context, w *Withdrawal) (*Proof, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	index, err := p.oracle.GetL2OutputIndexAfter(opts, new(big.Int).SetUint64(w.L2Block))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output index after block %d: %w", w.L2Block, err)
+	}
+	output, err := p.oracle.GetL2Output(opts, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output %v: %w", index, err)
+	}
+	return prove(ctx, p.l2Proofs, p.l2Headers, w, index, output.L2BlockNumber)
+}
+
+type faultProofPortal struct {
+	addr      common.Address
+	portal    *bindingspreview.OptimismPortal2Caller
+	factory   *bindings.DisputeGameFactoryCaller
+	l2Proofs  opwithdrawals.ProofClient
+	l2Headers opwithdrawals.HeaderClient
+}
+
+func (p *faultProofPortal) Address() common.Address {
+	return p.addr
+}
+
+func (p *faultProofPortal) FaultProofs() bool {
+	return true
+}
+
+func (p *faultProofPortal) State(ctx context.Context, w *Withdrawal, l1Time uint64) (State, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	finalized, err := p.portal.FinalizedWithdrawals(opts, w.Hash)
+	if err != nil {
+		return State{}, fmt.Errorf("failed to get finalized status: %w", err)
+	}
+	if finalized {
+		return State{Status: StatusFinalized}, nil
+	}
+
+	// The withdrawal may be proven by multiple accounts, any valid proof can be used to finalize it.
+	count, err := p.portal.NumProofSubmitters(opts, w.Hash)
+	if err != nil {
+		return State{}, fmt.Errorf("failed to get number of proof submitters: %w", err)
+	}
+	var pending *State
+	for i := int64(0); i < count.Int64(); i++ {
+		submitter, err := p.portal.ProofSubmitters(opts, w.Hash, big.NewInt(i))
+		if err != nil {
+			return State{}, fmt.Errorf("failed to get proof submitter %d: %w", i, err)
+		}
+		proven, err := p.portal.ProvenWithdrawals(opts, w.Hash, submitter)
+		if err != nil {
+			return State{}, fmt.Errorf("failed to get proven status: %w", err)
+		}
+		blacklisted, err := p.portal.DisputeGameBlacklist(opts, proven.DisputeGameProxy)
+		if err != nil {
+			return State{}, fmt.Errorf("failed to get blacklist status of game %s: %w", proven.DisputeGameProxy, err)
+		}
+		if blacklisted {
+			continue
+		}
+		// checkWithdrawal reverts with the reason the withdrawal cannot be finalized.
+		if err := p.portal.CheckWithdrawal(opts, w.Hash, submitter); err != nil {
+			if pending == nil {
+				pending = &State{Status: StatusProven, ProofSubmitter: &submitter, Reason: err.Error()}
+			}
+			continue
+		}
+		return State{Status: StatusReadyToFinalize, ProofSubmitter: &submitter}, nil
+	}
+	if pending != nil {
+		return *pending, nil
+	}
+
+	game, err := p.latestGame(ctx)
+	if err != nil {
+		return State{}, err
+	}
+	if game == nil || gameBlock(game).Cmp(new(big.Int).SetUint64(w.L2Block)) < 0 {
+		return State{Status: StatusWaitingForOutput}, nil
+	}
+	state := State{Status: StatusReadyToProve}
+	if count.Sign() != 0 {
+		state.Reason = "all proven dispute games are blacklisted"
+	}
+	return state, nil
+}
+
+func (p *faultProofPortal) Proof(ctx context.Context, w *Withdrawal) (*Proof, error) {
+	game, err := p.latestGame(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, fmt.Errorf("no dispute game to prove withdrawal %s against", w.Hash)
+	}
+	return prove(ctx, p.l2Proofs, p.l2Headers, w, game.Index, gameBlock(game))
+}
+
+// latestGame returns the latest game of the respected game type, or nil if there are no games.
+func (p *faultProofPortal) latestGame(ctx context.Context) (*bindings.IDisputeGameFactoryGameSearchResult, error) {
+	count, err := p.factory.GameCount(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game count: %w", err)
+	}
+	if count.Sign() == 0 {
+		return nil, nil
+	}
+	return opwithdrawals.FindLatestGame(ctx, p.factory, p.portal)
+}
+
+// gameBlock returns the L2 block number of the game, which is the start of its extra data.
+func gameBlock(game *bindings.IDisputeGameFactoryGameSearchResult) *big.Int {
+	return new(big.Int).SetBytes(game.ExtraData[0:32])
+}
+
+func prove(ctx context.Context, l2Proofs opwithdrawals.ProofClient, l2Headers opwithdrawals.HeaderClient, w *Withdrawal, index *big.Int, l2Block *big.Int) (*Proof, error) {
+	header, err := l2Headers.HeaderByNumber(ctx, l2Block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L2 block %v: %w", l2Block, err)
+	}
+	params, err := opwithdrawals.ProveWithdrawalParametersForEvent(ctx, l2Proofs, w.Event(), header, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prove withdrawal %s: %w", w.Hash, err)
+	}
+	withdrawalProof := make([]hexutil.Bytes, len(params.WithdrawalProof))
+	for i, node := range params.WithdrawalProof {
+		withdrawalProof[i] = node
+	}
+	return &Proof{
+		L2OutputIndex: index,
+		L2BlockNumber: header.Number.Uint64(),
+		OutputRootProof: OutputRootProof{
+			Version:                  params.OutputRootProof.Version,
+			StateRoot:                params.OutputRootProof.StateRoot,
+			MessagePasserStorageRoot: params.OutputRootProof.MessagePasserStorageRoot,
+			LatestBlockhash:          params.OutputRootProof.LatestBlockhash,
+		},
+		WithdrawalProof: withdrawalProof,
+	}, nil
+}
+
+var (
+	_ Portal = (*outputOraclePortal)(nil)
+	_ Portal = (*faultProofPortal)(nil)
+)
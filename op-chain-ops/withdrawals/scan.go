@@ -0,0 +1,145 @@
+package withdrawals
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/crossdomain"
+	"github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+)
+
+// Withdrawal is a withdrawal that was initiated on L2, as emitted by the L2ToL1MessagePasser.
+type Withdrawal struct {
+	Hash     common.Hash    `json:"withdrawalHash"`
+	TxHash   common.Hash    `json:"txHash"`
+	L2Block  uint64         `json:"l2BlockNumber"`
+	Nonce    *big.Int       `json:"nonce"`
+	Sender   common.Address `json:"sender"`
+	Target   common.Address `json:"target"`
+	Value    *big.Int       `json:"value"`
+	GasLimit *big.Int       `json:"gasLimit"`
+	Data     hexutil.Bytes  `json:"data"`
+
+	// MessageSender and MessageTarget are set for withdrawals of the L2CrossDomainMessenger,
+	// they are the sender and target of the relayed message.
+	MessageSender *common.Address `json:"messageSender,omitempty"`
+	MessageTarget *common.Address `json:"messageTarget,omitempty"`
+}
+
+// NewWithdrawal creates a Withdrawal from its MessagePassed event.
+func NewWithdrawal(ev *bindings.L2ToL1MessagePasserMessagePassed) *Withdrawal {
+	w := &Withdrawal{
+		Hash:     ev.WithdrawalHash,
+		TxHash:   ev.Raw.TxHash,
+		L2Block:  ev.Raw.BlockNumber,
+		Nonce:    ev.Nonce,
+		Sender:   ev.Sender,
+		Target:   ev.Target,
+		Value:    ev.Value,
+		GasLimit: ev.GasLimit,
+		Data:     ev.Data,
+	}
+	if ev.Sender == predeploys.L2CrossDomainMessengerAddr {
+		// Messages that cannot be decoded are only matched by the withdrawal sender and target.
+		if msg, err := crossdomain.DecodeCrossDomainMessage(ev.Data); err == nil {
+			w.MessageSender = &msg.Sender
+			w.MessageTarget = &msg.Target
+		}
+	}
+	return w
+}
+
+// Event returns the MessagePassed event of the withdrawal, without the log metadata.
+func (w *Withdrawal) Event() *bindings.L2ToL1MessagePasserMessagePassed {
+	return &bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:          w.Nonce,
+		Sender:         w.Sender,
+		Target:         w.Target,
+		Value:          w.Value,
+		GasLimit:       w.GasLimit,
+		Data:           w.Data,
+		WithdrawalHash: w.Hash,
+	}
+}
+
+// Transaction returns the withdrawal transaction, as passed to the OptimismPortal.
+func (w *Withdrawal) Transaction() bindings.TypesWithdrawalTransaction {
+	return bindings.TypesWithdrawalTransaction{
+		Nonce:    w.Nonce,
+		Sender:   w.Sender,
+		Target:   w.Target,
+		Value:    w.Value,
+		GasLimit: w.GasLimit,
+		Data:     w.Data,
+	}
+}
+
+// AddressFilter matches the withdrawals that involve one of its addresses,
+// either as sender or target of the withdrawal or of the relayed message.
+// An empty filter matches all withdrawals.
+type AddressFilter map[common.Address]struct{}
+
+func NewAddressFilter(addrs ...common.Address) AddressFilter {
+	f := make(AddressFilter, len(addrs))
+	for _, addr := range addrs {
+		f[addr] = struct{}{}
+	}
+	return f
+}
+
+func (f AddressFilter) Matches(w *Withdrawal) bool {
+	if len(f) == 0 {
+		return true
+	}
+	candidates := []*common.Address{&w.Sender, &w.Target, w.MessageSender, w.MessageTarget}
+	for _, addr := range candidates {
+		if addr == nil {
+			continue
+		}
+		if _, ok := f[*addr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan returns the withdrawals initiated in the L2 blocks start to end (inclusive) that match the filter.
+// The logs are requested in ranges of at most step blocks, to stay within the limits of the RPC provider.
+func Scan(ctx context.Context, l2 bind.ContractFilterer, start, end, step uint64, filter AddressFilter) ([]*Withdrawal, error) {
+	if step == 0 {
+		return nil, fmt.Errorf("invalid block range step: %d", step)
+	}
+	passer, err := bindings.NewL2ToL1MessagePasserFilterer(predeploys.L2ToL1MessagePasserAddr, l2)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Withdrawal
+	for from := start; from <= end; from += step {
+		to := min(from+step-1, end)
+		iter, err := passer.FilterMessagePassed(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter MessagePassed events in blocks %d-%d: %w", from, to, err)
+		}
+		for iter.Next() {
+			w := NewWithdrawal(iter.Event)
+			if filter.Matches(w) {
+				out = append(out, w)
+			}
+		}
+		err = iter.Error()
+		iter.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MessagePassed events in blocks %d-%d: %w", from, to, err)
+		}
+		if to == end {
+			break // avoid overflow of from at the end of the uint64 range
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,76 @@
+package withdrawals
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/op-chain-ops/crossdomain"
+	"github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+)
+
+func messengerEvent(t *testing.T, sender, target common.Address) *bindings.L2ToL1MessagePasserMessagePassed {
+	nonce := crossdomain.EncodeVersionedNonce(big.NewInt(3), common.Big1)
+	data, err := crossdomain.EncodeCrossDomainMessageV1(nonce, sender, target, big.NewInt(10), big.NewInt(100_000), nil)
+	require.NoError(t, err)
+	return &bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:          big.NewInt(1),
+		Sender:         predeploys.L2CrossDomainMessengerAddr,
+		Target:         common.Address{0xee},
+		Value:          big.NewInt(10),
+		GasLimit:       big.NewInt(200_000),
+		Data:           data,
+		WithdrawalHash: common.Hash{0xaa},
+		Raw:            types.Log{TxHash: common.Hash{0xbb}, BlockNumber: 42},
+	}
+}
+
+func TestNewWithdrawal(t *testing.T) {
+	user := common.Address{0x01}
+	recipient := common.Address{0x02}
+
+	w := NewWithdrawal(messengerEvent(t, user, recipient))
+	require.Equal(t, common.Hash{0xaa}, w.Hash)
+	require.Equal(t, common.Hash{0xbb}, w.TxHash)
+	require.Equal(t, uint64(42), w.L2Block)
+	require.Equal(t, user, *w.MessageSender)
+	require.Equal(t, recipient, *w.MessageTarget)
+
+	ev := w.Event()
+	require.Equal(t, w.Hash, ev.WithdrawalHash)
+	require.Equal(t, predeploys.L2CrossDomainMessengerAddr, ev.Sender)
+
+	direct := NewWithdrawal(&bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:    big.NewInt(2),
+		Sender:   user,
+		Target:   recipient,
+		Value:    big.NewInt(1),
+		GasLimit: big.NewInt(21_000),
+		Data:     []byte{0x01, 0x02, 0x03, 0x04},
+	})
+	require.Nil(t, direct.MessageSender, "only messenger withdrawals carry a message")
+	require.Nil(t, direct.MessageTarget)
+}
+
+func TestAddressFilter(t *testing.T) {
+	user := common.Address{0x01}
+	recipient := common.Address{0x02}
+	other := common.Address{0x03}
+
+	viaMessenger := NewWithdrawal(messengerEvent(t, user, recipient))
+	direct := &Withdrawal{Sender: user, Target: other}
+
+	require.True(t, NewAddressFilter().Matches(direct), "empty filter matches all")
+	require.True(t, NewAddressFilter(user).Matches(direct))
+	require.True(t, NewAddressFilter(other).Matches(direct))
+	require.False(t, NewAddressFilter(recipient).Matches(direct))
+
+	require.True(t, NewAddressFilter(user).Matches(viaMessenger), "message sender")
+	require.True(t, NewAddressFilter(recipient).Matches(viaMessenger), "message target")
+	require.True(t, NewAddressFilter(predeploys.L2CrossDomainMessengerAddr).Matches(viaMessenger))
+	require.False(t, NewAddressFilter(other).Matches(viaMessenger))
+}
@@ -0,0 +1,170 @@
+package withdrawals
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	bindingspreview "github.com/tokamak-network/tokamak-thanos/op-node/bindings/preview"
+	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
+)
+
+// Entry is the report of a single withdrawal.
+type Entry struct {
+	*Withdrawal
+	State
+	// Proof is set for withdrawals that are ready to be proven.
+	Proof *Proof `json:"proof,omitempty"`
+}
+
+// Inspect returns the report of the withdrawals that are not finalized yet.
+// Proofs are generated for the withdrawals that are ready to be proven, if withProofs is set.
+func Inspect(ctx context.Context, lgr log.Logger, portal Portal, withdrawals []*Withdrawal, l1Time uint64, withProofs bool) ([]*Entry, error) {
+	var out []*Entry
+	for _, w := range withdrawals {
+		state, err := portal.State(ctx, w, l1Time)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get state of withdrawal %s: %w", w.Hash, err)
+		}
+		lgr.Debug("Withdrawal state", "withdrawal", w.Hash, "tx", w.TxHash, "status", state.Status, "reason", state.Reason)
+		if state.Status == StatusFinalized {
+			continue
+		}
+		entry := &Entry{Withdrawal: w, State: state}
+		if withProofs && state.Status == StatusReadyToProve {
+			entry.Proof, err = portal.Proof(ctx, w)
+			if err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+// ProveTx returns the transaction to prove the withdrawal with.
+func ProveTx(portal common.Address, w *Withdrawal, proof *Proof) (txmgr.TxCandidate, error) {
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	if err != nil {
+		return txmgr.TxCandidate{}, err
+	}
+	outputRootProof, withdrawalProof := proof.bindings()
+	// The dispute game index takes the place of the output index with fault proofs,
+	// the encoding of the call is the same.
+	data, err := portalABI.Pack("proveWithdrawalTransaction", w.Transaction(), proof.L2OutputIndex, outputRootProof, withdrawalProof)
+	if err != nil {
+		return txmgr.TxCandidate{}, fmt.Errorf("failed to encode proveWithdrawalTransaction: %w", err)
+	}
+	return txmgr.TxCandidate{TxData: data, To: &portal}, nil
+}
+
+// FinalizeTx returns the transaction for the account from to finalize the withdrawal with.
+// Withdrawals that were proven by another account are finalized with the proof of that account.
+func FinalizeTx(portal common.Address, from common.Address, w *Withdrawal, state State) (txmgr.TxCandidate, error) {
+	if state.ProofSubmitter != nil && *state.ProofSubmitter != from {
+		portalABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+		if err != nil {
+			return txmgr.TxCandidate{}, err
+		}
+		data, err := portalABI.Pack("finalizeWithdrawalTransactionExternalProof", w.Transaction(), *state.ProofSubmitter)
+		if err != nil {
+			return txmgr.TxCandidate{}, fmt.Errorf("failed to encode finalizeWithdrawalTransactionExternalProof: %w", err)
+		}
+		return txmgr.TxCandidate{TxData: data, To: &portal}, nil
+	}
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	if err != nil {
+		return txmgr.TxCandidate{}, err
+	}
+	data, err := portalABI.Pack("finalizeWithdrawalTransaction", w.Transaction())
+	if err != nil {
+		return txmgr.TxCandidate{}, fmt.Errorf("failed to encode finalizeWithdrawalTransaction: %w", err)
+	}
+	return txmgr.TxCandidate{TxData: data, To: &portal}, nil
+}
+
+// Result is the outcome of a prove or finalize transaction.
+type Result struct {
+	Withdrawal common.Hash `json:"withdrawalHash"`
+	Action     string      `json:"action"`
+	TxHash     common.Hash `json:"txHash,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+type action struct {
+	name      string
+	entry     *Entry
+	candidate txmgr.TxCandidate
+}
+
+// Submit proves the entries that are ready to be proven and finalizes the ones that are ready to be finalized.
+// The transactions are sent in batches of batchSize concurrent transactions, each batch is included before the
+// next one is sent. Entries that are ready to be proven must have a proof.
+// A failed transaction does not stop the submission of the others, the failures are part of the results.
+func Submit(ctx context.Context, lgr log.Logger, txMgr txmgr.TxManager, portal common.Address, entries []*Entry, batchSize int) ([]Result, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("invalid batch size: %d", batchSize)
+	}
+	var actions []action
+	for _, entry := range entries {
+		switch entry.Status {
+		case StatusReadyToProve:
+			if entry.Proof == nil {
+				return nil, fmt.Errorf("missing proof of withdrawal %s", entry.Hash)
+			}
+			candidate, err := ProveTx(portal, entry.Withdrawal, entry.Proof)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, action{name: "prove", entry: entry, candidate: candidate})
+		case StatusReadyToFinalize:
+			candidate, err := FinalizeTx(portal, txMgr.From(), entry.Withdrawal, entry.State)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, action{name: "finalize", entry: entry, candidate: candidate})
+		}
+	}
+
+	results := make([]Result, len(actions))
+	for start := 0; start < len(actions); start += batchSize {
+		end := min(start+batchSize, len(actions))
+		lgr.Info("Sending batch", "from", start, "to", end, "total", len(actions))
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = send(ctx, lgr, txMgr, actions[i])
+			}(i)
+		}
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return results[:end], err
+		}
+	}
+	return results, nil
+}
+
+func send(ctx context.Context, lgr log.Logger, txMgr txmgr.TxManager, a action) Result {
+	res := Result{Withdrawal: a.entry.Hash, Action: a.name}
+	receipt, err := txMgr.Send(ctx, a.candidate)
+	if receipt != nil {
+		res.TxHash = receipt.TxHash
+	}
+	if err == nil && receipt.Status != types.ReceiptStatusSuccessful {
+		err = fmt.Errorf("transaction %s reverted", receipt.TxHash)
+	}
+	if err != nil {
+		lgr.Error("Withdrawal transaction failed", "action", a.name, "withdrawal", a.entry.Hash, "err", err)
+		res.Error = err.Error()
+		return res
+	}
+	lgr.Info("Withdrawal transaction included", "action", a.name, "withdrawal", a.entry.Hash, "tx", receipt.TxHash)
+	return res
+}
@@ -0,0 +1,167 @@
+package withdrawals
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	bindingspreview "github.com/tokamak-network/tokamak-thanos/op-node/bindings/preview"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
+)
+
+type fakeTxManager struct {
+	from common.Address
+	fail map[common.Hash]bool
+
+	mu         sync.Mutex
+	pending    int
+	maxPending int
+	sent       []txmgr.TxCandidate
+}
+
+func (m *fakeTxManager) Send(ctx context.Context, candidate txmgr.TxCandidate) (*types.Receipt, error) {
+	m.mu.Lock()
+	m.pending++
+	m.maxPending = max(m.maxPending, m.pending)
+	m.sent = append(m.sent, candidate)
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.pending--
+		m.mu.Unlock()
+	}()
+
+	txHash := crypto.Keccak256Hash(candidate.TxData)
+	if m.fail[txHash] {
+		return nil, errors.New("boom")
+	}
+	return &types.Receipt{TxHash: txHash, Status: types.ReceiptStatusSuccessful}, nil
+}
+
+func (m *fakeTxManager) From() common.Address                            { return m.from }
+func (m *fakeTxManager) BlockNumber(ctx context.Context) (uint64, error) { return 0, nil }
+func (m *fakeTxManager) Close()                                          {}
+func (m *fakeTxManager) IsClosed() bool                                  { return false }
+
+var _ txmgr.TxManager = (*fakeTxManager)(nil)
+
+func testWithdrawal(i byte) *Withdrawal {
+	return &Withdrawal{
+		Hash:     common.Hash{i},
+		Nonce:    big.NewInt(int64(i)),
+		Sender:   common.Address{0x01},
+		Target:   common.Address{0x02},
+		Value:    big.NewInt(1),
+		GasLimit: big.NewInt(100_000),
+		Data:     hexutil.Bytes{i},
+	}
+}
+
+func testProof() *Proof {
+	return &Proof{
+		L2OutputIndex:   big.NewInt(7),
+		L2BlockNumber:   100,
+		OutputRootProof: OutputRootProof{StateRoot: common.Hash{0x01}},
+		WithdrawalProof: []hexutil.Bytes{{0x01}, {0x02}},
+	}
+}
+
+func TestProveTx(t *testing.T) {
+	portal := common.Address{0x42}
+	w := testWithdrawal(1)
+	candidate, err := ProveTx(portal, w, testProof())
+	require.NoError(t, err)
+	require.Equal(t, portal, *candidate.To)
+
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	require.NoError(t, err)
+	method := portalABI.Methods["proveWithdrawalTransaction"]
+	require.Equal(t, method.ID, candidate.TxData[:4])
+	args, err := method.Inputs.Unpack(candidate.TxData[4:])
+	require.NoError(t, err)
+	require.Zero(t, big.NewInt(7).Cmp(args[1].(*big.Int)))
+	require.Equal(t, [][]byte{{0x01}, {0x02}}, args[3].([][]byte))
+}
+
+func TestFinalizeTx(t *testing.T) {
+	portal := common.Address{0x42}
+	from := common.Address{0xf0}
+	other := common.Address{0xf1}
+	w := testWithdrawal(1)
+
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	require.NoError(t, err)
+	portal2ABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+	require.NoError(t, err)
+
+	candidate, err := FinalizeTx(portal, from, w, State{Status: StatusReadyToFinalize})
+	require.NoError(t, err)
+	require.Equal(t, portalABI.Methods["finalizeWithdrawalTransaction"].ID, candidate.TxData[:4])
+
+	candidate, err = FinalizeTx(portal, from, w, State{Status: StatusReadyToFinalize, ProofSubmitter: &from})
+	require.NoError(t, err)
+	require.Equal(t, portalABI.Methods["finalizeWithdrawalTransaction"].ID, candidate.TxData[:4], "own proof")
+
+	candidate, err = FinalizeTx(portal, from, w, State{Status: StatusReadyToFinalize, ProofSubmitter: &other})
+	require.NoError(t, err)
+	method := portal2ABI.Methods["finalizeWithdrawalTransactionExternalProof"]
+	require.Equal(t, method.ID, candidate.TxData[:4], "external proof")
+	args, err := method.Inputs.Unpack(candidate.TxData[4:])
+	require.NoError(t, err)
+	require.Equal(t, other, args[1].(common.Address))
+}
+
+func TestSubmit(t *testing.T) {
+	portal := common.Address{0x42}
+	logger := testlog.Logger(t, log.LevelInfo)
+
+	var entries []*Entry
+	for i := byte(1); i <= 5; i++ {
+		entries = append(entries, &Entry{Withdrawal: testWithdrawal(i), State: State{Status: StatusReadyToProve}, Proof: testProof()})
+	}
+	entries = append(entries,
+		&Entry{Withdrawal: testWithdrawal(6), State: State{Status: StatusReadyToFinalize}},
+		&Entry{Withdrawal: testWithdrawal(7), State: State{Status: StatusProven}},
+		&Entry{Withdrawal: testWithdrawal(8), State: State{Status: StatusWaitingForOutput}},
+	)
+
+	failing, err := ProveTx(portal, entries[2].Withdrawal, entries[2].Proof)
+	require.NoError(t, err)
+	txMgr := &fakeTxManager{fail: map[common.Hash]bool{crypto.Keccak256Hash(failing.TxData): true}}
+
+	results, err := Submit(context.Background(), logger, txMgr, portal, entries, 2)
+	require.NoError(t, err)
+	require.Len(t, results, 6, "only proofs and finalizations are sent")
+	require.Len(t, txMgr.sent, 6)
+	require.LessOrEqual(t, txMgr.maxPending, 2, "batch size must be respected")
+
+	for i, res := range results[:5] {
+		require.Equal(t, entries[i].Hash, res.Withdrawal)
+		require.Equal(t, "prove", res.Action)
+		if i == 2 {
+			require.Equal(t, "boom", res.Error)
+		} else {
+			require.Empty(t, res.Error)
+			require.NotEqual(t, common.Hash{}, res.TxHash)
+		}
+	}
+	require.Equal(t, "finalize", results[5].Action)
+	require.Equal(t, entries[5].Hash, results[5].Withdrawal)
+
+	_, err = Submit(context.Background(), logger, txMgr, portal, []*Entry{{Withdrawal: testWithdrawal(9), State: State{Status: StatusReadyToProve}}}, 2)
+	require.ErrorContains(t, err, "missing proof")
+
+	_, err = Submit(context.Background(), logger, txMgr, portal, entries, 0)
+	require.ErrorContains(t, err, "invalid batch size")
+}
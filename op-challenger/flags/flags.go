@@ -49,7 +49,7 @@ var (
 	// Required Flags
 	L1EthRpcFlag = &cli.StringFlag{
 		Name:    "l1-eth-rpc",
-		Usage:   "HTTP provider URL for L1.",
+		Usage:   "HTTP provider URL for L1. Multiple endpoints can be given, separated by '|' and optionally followed by quorum=N, to fail over between them and to require N endpoints to agree on consensus-critical calls.",
 		EnvVars: prefixEnvVars("L1_ETH_RPC"),
 	}
 	L1BeaconFlag = &cli.StringFlag{
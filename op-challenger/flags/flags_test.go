@@ -161,3 +161,7 @@ func TestMultipleNetworksMustShareDisputeGameFactory(t *testing.T) {
 		require.Equal(t, actual, common.Address{0xBB})
 	})
 }
+
+func TestL1RPCUsageNamesSeparator(t *testing.T) {
+	require.Contains(t, L1EthRpcFlag.Usage, "separated by '|'")
+}
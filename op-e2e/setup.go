@@ -65,6 +65,7 @@ import (
 	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
 	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
 	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testutils/faultproxy"
 	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
 )
 
@@ -230,6 +231,18 @@ type SystemConfig struct {
 	// MaxPendingTransactions determines how many transactions the batcher will try to send
 	// concurrently. 0 means unlimited.
 	MaxPendingTransactions uint64
+
+	// FaultProxies, if not nil, puts fault-injecting proxies between the rollup nodes and L1, the L1 beacon API
+	// and their engines. The proxies are available in the System to script faults during the test.
+	FaultProxies *FaultProxyConfig
+}
+
+// FaultProxyConfig configures the faults that the fault-injecting proxies apply from the start of the system.
+type FaultProxyConfig struct {
+	L1       []faultproxy.Fault
+	L1Beacon []faultproxy.Fault
+	// Engine faults apply to the engines of all rollup nodes.
+	Engine []faultproxy.Fault
 }
 
 type GethInstance struct {
@@ -285,6 +298,12 @@ type System struct {
 
 	L1BeaconAPIAddr string
 
+	// L1Proxy, L1BeaconProxy and EngineProxies are the fault-injecting proxies in front of L1, the L1 beacon API
+	// and the engines of the rollup nodes by name. They are nil unless SystemConfig.FaultProxies was set.
+	L1Proxy       *faultproxy.Proxy
+	L1BeaconProxy *faultproxy.BeaconProxy
+	EngineProxies map[string]*faultproxy.Proxy
+
 	// TimeTravelClock is nil unless SystemConfig.SupportL1TimeTravel was set to true
 	// It provides access to the clock instance used by the L1 node. Calling TimeTravelClock.AdvanceBy
 	// allows tests to quickly time travel L1 into the future.
@@ -391,6 +410,9 @@ func (sys *System) Close() {
 	for _, client := range sys.rollupClients {
 		client.Close()
 	}
+	if err := sys.closeFaultProxies(); err != nil {
+		combinedErr = errors.Join(combinedErr, err)
+	}
 	if sys.Mocknet != nil {
 		if err := sys.Mocknet.Close(); err != nil {
 			combinedErr = errors.Join(combinedErr, fmt.Errorf("stop Mocknet: %w", err))
@@ -606,6 +628,11 @@ func (cfg SystemConfig) Start(t *testing.T, _opts ...SystemConfigOption) (*Syste
 			nodeCfg.Beacon = &rollupNode.L1BeaconEndpointConfig{BeaconAddr: sys.L1BeaconAPIAddr}
 		}
 	}
+	if cfg.FaultProxies != nil {
+		if err := sys.startFaultProxies(cfg.FaultProxies); err != nil {
+			return nil, err
+		}
+	}
 
 	time.Sleep(1 * time.Second)
 
@@ -954,6 +981,71 @@ func configureL2(rollupNodeCfg *rollupNode.Config, l2Node WSOrHTTPEndpoint, jwtS
 	}
 }
 
+// startFaultProxies starts the fault-injecting proxies, and points the rollup nodes at them.
+func (sys *System) startFaultProxies(cfg *FaultProxyConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	logger := testlog.Logger(sys.t, log.LevelInfo)
+
+	l1RPC, err := client.NewRPC(ctx, logger.New("role", "l1"), sys.EthInstances["l1"].WSEndpoint())
+	if err != nil {
+		return fmt.Errorf("failed to dial L1 for fault proxy: %w", err)
+	}
+	sys.L1Proxy = faultproxy.NewProxy(logger.New("role", "l1_proxy"), l1RPC)
+	if err := sys.L1Proxy.Start("127.0.0.1:0"); err != nil {
+		return err
+	}
+	sys.L1Proxy.AddFaults(cfg.L1...)
+
+	sys.L1BeaconProxy, err = faultproxy.NewBeaconProxy(logger.New("role", "l1_cl_proxy"), sys.L1BeaconAPIAddr)
+	if err != nil {
+		return err
+	}
+	if err := sys.L1BeaconProxy.Start("127.0.0.1:0"); err != nil {
+		return err
+	}
+	sys.L1BeaconProxy.AddFaults(cfg.L1Beacon...)
+
+	sys.EngineProxies = make(map[string]*faultproxy.Proxy)
+	for name, nodeCfg := range sys.Cfg.Nodes {
+		engineRPC, err := client.NewRPC(ctx, logger.New("role", name), sys.EthInstances[name].WSAuthEndpoint(),
+			client.WithGethRPCOptions(rpc.WithHTTPAuth(node.NewJWTAuth(sys.Cfg.JWTSecret))))
+		if err != nil {
+			return fmt.Errorf("failed to dial engine of %s for fault proxy: %w", name, err)
+		}
+		proxy := faultproxy.NewProxy(logger.New("role", name+"_engine_proxy"), engineRPC)
+		if err := proxy.Start("127.0.0.1:0"); err != nil {
+			return err
+		}
+		proxy.AddFaults(cfg.Engine...)
+		sys.EngineProxies[name] = proxy
+
+		nodeCfg.L1.L1NodeAddr = sys.L1Proxy.Endpoint()
+		nodeCfg.L2.L2EngineAddr = proxy.Endpoint()
+		if nodeCfg.Beacon != nil {
+			nodeCfg.Beacon = &rollupNode.L1BeaconEndpointConfig{BeaconAddr: sys.L1BeaconProxy.Endpoint()}
+		}
+	}
+	return nil
+}
+
+func (sys *System) closeFaultProxies() error {
+	var result error
+	if sys.L1Proxy != nil {
+		result = errors.Join(result, sys.L1Proxy.Close())
+	}
+	if sys.L1BeaconProxy != nil {
+		result = errors.Join(result, sys.L1BeaconProxy.Close())
+	}
+	for _, proxy := range sys.EngineProxies {
+		result = errors.Join(result, proxy.Close())
+	}
+	if result != nil {
+		return fmt.Errorf("stop fault proxies: %w", result)
+	}
+	return nil
+}
+
 func (cfg SystemConfig) L1ChainIDBig() *big.Int {
 	return new(big.Int).SetUint64(cfg.DeployConfig.L1ChainID)
 }
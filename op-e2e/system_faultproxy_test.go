@@ -0,0 +1,43 @@
+package op_e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/op-e2e/e2eutils/wait"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testutils/faultproxy"
+)
+
+// TestFaultProxies tests that the rollup nodes keep deriving the chain with flaky L1 and engine endpoints.
+func TestFaultProxies(t *testing.T) {
+	InitParallel(t)
+
+	cfg := DefaultSystemConfig(t)
+	cfg.FaultProxies = &FaultProxyConfig{
+		L1: []faultproxy.Fault{
+			{Kind: faultproxy.FaultLatency, Latency: 20 * time.Millisecond},
+			{Kind: faultproxy.FaultStaleHead, Skip: 10, Times: 5},
+			{Kind: faultproxy.FaultMissingReceipts, Times: 3},
+			{Kind: faultproxy.FaultDrop, Skip: 20, Times: 2},
+		},
+		L1Beacon: []faultproxy.Fault{{Kind: faultproxy.FaultBlobsNotFound, Times: 2}},
+		Engine:   []faultproxy.Fault{{Kind: faultproxy.FaultDrop, Skip: 10, Times: 1}},
+	}
+	sys, err := cfg.Start(t)
+	require.Nil(t, err, "Error starting up system")
+	defer sys.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	require.NoError(t, wait.ForSafeBlock(ctx, sys.RollupClient("verifier"), 5))
+
+	// Faults can be scripted while the system runs.
+	sys.EngineProxies["verifier"].AddFaults(faultproxy.Fault{Kind: faultproxy.FaultLatency, Latency: 200 * time.Millisecond, Times: 10})
+	sys.L1Proxy.AddFaults(faultproxy.Fault{Kind: faultproxy.FaultMissingReceipts, Times: 3})
+	status, err := sys.RollupClient("verifier").SyncStatus(ctx)
+	require.NoError(t, err)
+	require.NoError(t, wait.ForSafeBlock(ctx, sys.RollupClient("verifier"), status.SafeL2.Number+5))
+}
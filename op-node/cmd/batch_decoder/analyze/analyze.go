@@ -0,0 +1,419 @@
+package analyze
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/batch_decoder/fetch"
+	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/batch_decoder/reassemble"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+type Config struct {
+	// BatchInbox filters the transactions and channels by inbox, all are analyzed if it is the zero address.
+	BatchInbox       common.Address
+	TxDirectory      string
+	ChannelDirectory string
+}
+
+// ChannelStats are the statistics of a reassembled channel.
+type ChannelStats struct {
+	ID                  derive.ChannelID       `json:"id"`
+	IsReady             bool                   `json:"is_ready"`
+	InvalidFrames       bool                   `json:"invalid_frames"`
+	InvalidBatches      bool                   `json:"invalid_batches"`
+	ComprAlgo           derive.CompressionAlgo `json:"compr_algo"`
+	Frames              int                    `json:"frames"`
+	L1Transactions      int                    `json:"l1_transactions"`
+	FirstInclusionBlock uint64                 `json:"first_inclusion_block"`
+	LastInclusionBlock  uint64                 `json:"last_inclusion_block"`
+	CompressedSize      uint64                 `json:"compressed_size"`
+	UncompressedSize    uint64                 `json:"uncompressed_size"`
+	// CompressionRatio is the compressed size divided by the uncompressed size.
+	CompressionRatio float64 `json:"compression_ratio"`
+	L2Blocks         int     `json:"l2_blocks"`
+	L2Transactions   uint64  `json:"l2_transactions"`
+	// BlobFillRate is the share of the blob space of the channel's blob transactions that holds its frames,
+	// or 0 if the channel is not submitted in blobs.
+	BlobFillRate float64 `json:"blob_fill_rate"`
+	// L1Fee is the share of the fees of the L1 transactions that is attributed to the channel, by frame size.
+	L1Fee          *big.Int `json:"l1_fee"`
+	L1FeePerL2Byte float64  `json:"l1_fee_per_l2_byte"`
+	L1FeePerL2Tx   float64  `json:"l1_fee_per_l2_tx"`
+	// Latency is the time from the L2 block timestamps to the inclusion of the last frame of the channel on L1.
+	Latency LatencyStats `json:"latency"`
+}
+
+// LatencyStats are the L2-block-to-L1-inclusion latencies in seconds.
+type LatencyStats struct {
+	Min  uint64  `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  uint64  `json:"p50"`
+	P95  uint64  `json:"p95"`
+	Max  uint64  `json:"max"`
+}
+
+// ComprAlgoStats are the aggregate compression statistics of the channels of a compression algorithm.
+type ComprAlgoStats struct {
+	ComprAlgo        derive.CompressionAlgo `json:"compr_algo"`
+	Channels         int                    `json:"channels"`
+	CompressedSize   uint64                 `json:"compressed_size"`
+	UncompressedSize uint64                 `json:"uncompressed_size"`
+	CompressionRatio float64                `json:"compression_ratio"`
+}
+
+// Summary are the aggregate statistics of all transactions and channels.
+type Summary struct {
+	L1Transactions     int `json:"l1_transactions"`
+	BlobTransactions   int `json:"blob_transactions"`
+	Blobs              int `json:"blobs"`
+	InvalidSenderTxs   int `json:"invalid_sender_transactions"`
+	InvalidDataTxs     int `json:"invalid_data_transactions"`
+	MissingReceiptsTxs int `json:"missing_receipt_transactions"`
+
+	Channels             int `json:"channels"`
+	ReadyChannels        int `json:"ready_channels"`
+	InvalidFrameChannels int `json:"invalid_frame_channels"`
+	InvalidBatchChannels int `json:"invalid_batch_channels"`
+	Frames               int `json:"frames"`
+	// DroppedFrames are the frames of channels that are never ready.
+	DroppedFrames int `json:"dropped_frames"`
+
+	CompressedSize   uint64           `json:"compressed_size"`
+	UncompressedSize uint64           `json:"uncompressed_size"`
+	CompressionRatio float64          `json:"compression_ratio"`
+	Compression      []ComprAlgoStats `json:"compression"`
+	BlobFillRate     float64          `json:"blob_fill_rate"`
+
+	L2Blocks       int      `json:"l2_blocks"`
+	L2Transactions uint64   `json:"l2_transactions"`
+	L1Fee          *big.Int `json:"l1_fee"`
+	L1FeePerL2Byte float64  `json:"l1_fee_per_l2_byte"`
+	L1FeePerL2Tx   float64  `json:"l1_fee_per_l2_tx"`
+
+	Latency LatencyStats `json:"latency"`
+}
+
+type Report struct {
+	Summary  Summary        `json:"summary"`
+	Channels []ChannelStats `json:"channels"`
+}
+
+// channelFile is a reassembled channel as written by reassemble.Channels.
+// The batches are not decoded, the statistics only need the metadata derived from them.
+type channelFile struct {
+	ID                derive.ChannelID               `json:"id"`
+	IsReady           bool                           `json:"is_ready"`
+	InvalidFrames     bool                           `json:"invalid_frames"`
+	InvalidBatches    bool                           `json:"invalid_batches"`
+	Frames            []reassemble.FrameWithMetadata `json:"frames"`
+	ComprAlgos        []derive.CompressionAlgo       `json:"compr_algos"`
+	CompressedSize    uint64                         `json:"compressed_size"`
+	UncompressedSize  uint64                         `json:"uncompressed_size"`
+	L2BlockTimestamps []uint64                       `json:"l2_block_timestamps"`
+	L2Transactions    uint64                         `json:"l2_transactions"`
+}
+
+// txCost is the fee and blob space of a transaction, to attribute to the frames it carries.
+type txCost struct {
+	fee *big.Int
+	// frameBytes is the encoded size of the frames of the transaction.
+	frameBytes uint64
+	// blobBytes is the blob data capacity of the transaction, 0 for calldata transactions.
+	blobBytes uint64
+	// usedBlobBytes is the blob data of the transaction that holds frames, including the version bytes.
+	usedBlobBytes uint64
+}
+
+// share returns the part of v that is attributed to a frame of the given encoded size.
+func (c *txCost) share(v float64, size uint64) float64 {
+	if c.frameBytes == 0 {
+		return 0
+	}
+	return v * float64(size) / float64(c.frameBytes)
+}
+
+// Analyze loads the fetched transactions and reassembled channels, and computes their statistics.
+func Analyze(config Config) (*Report, error) {
+	txs, err := loadTransactions(config.TxDirectory, config.BatchInbox)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := loadChannels(config.ChannelDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary Summary
+	summary.L1Fee = new(big.Int)
+	costs := make(map[common.Hash]*txCost)
+	var blobBytes, usedBlobBytes uint64
+	for _, tx := range txs {
+		summary.L1Transactions++
+		if !tx.ValidSender {
+			summary.InvalidSenderTxs++
+			continue
+		}
+		if slices.Contains(tx.ValidFrames, false) {
+			summary.InvalidDataTxs++
+		}
+		cost := &txCost{fee: new(big.Int)}
+		for _, frame := range tx.Frames {
+			cost.frameBytes += frameSize(frame)
+		}
+		if tx.Tx.Type() == types.BlobTxType {
+			blobs := uint64(len(tx.Tx.BlobHashes()))
+			summary.BlobTransactions++
+			summary.Blobs += int(blobs)
+			cost.blobBytes = blobs * eth.MaxBlobDataSize
+			// Each blob starts with the derivation version byte.
+			cost.usedBlobBytes = cost.frameBytes + blobs
+			blobBytes += cost.blobBytes
+			usedBlobBytes += cost.usedBlobBytes
+		}
+		if tx.Receipt != nil {
+			cost.fee = txFee(tx.Receipt)
+		} else {
+			summary.MissingReceiptsTxs++
+		}
+		summary.L1Fee.Add(summary.L1Fee, cost.fee)
+		costs[tx.Tx.Hash()] = cost
+	}
+	if blobBytes > 0 {
+		summary.BlobFillRate = float64(usedBlobBytes) / float64(blobBytes)
+	}
+
+	report := &Report{Channels: make([]ChannelStats, 0, len(channels))}
+	compression := make(map[derive.CompressionAlgo]*ComprAlgoStats)
+	var latencies []uint64
+	for _, ch := range channels {
+		// Only channels of the analyzed transactions are included.
+		if _, ok := costs[ch.Frames[0].TxHash]; !ok {
+			continue
+		}
+		stats, chLatencies := channelStats(ch, costs)
+		report.Channels = append(report.Channels, stats)
+		latencies = append(latencies, chLatencies...)
+
+		summary.Channels++
+		summary.Frames += stats.Frames
+		if stats.IsReady {
+			summary.ReadyChannels++
+		} else {
+			summary.DroppedFrames += stats.Frames
+		}
+		if stats.InvalidFrames {
+			summary.InvalidFrameChannels++
+		}
+		if stats.InvalidBatches {
+			summary.InvalidBatchChannels++
+		}
+		summary.CompressedSize += stats.CompressedSize
+		summary.UncompressedSize += stats.UncompressedSize
+		summary.L2Blocks += stats.L2Blocks
+		summary.L2Transactions += stats.L2Transactions
+		if stats.ComprAlgo != "" {
+			algo, ok := compression[stats.ComprAlgo]
+			if !ok {
+				algo = &ComprAlgoStats{ComprAlgo: stats.ComprAlgo}
+				compression[stats.ComprAlgo] = algo
+			}
+			algo.Channels++
+			algo.CompressedSize += stats.CompressedSize
+			algo.UncompressedSize += stats.UncompressedSize
+		}
+	}
+	slices.SortFunc(report.Channels, func(a, b ChannelStats) int {
+		return cmp.Or(cmp.Compare(a.FirstInclusionBlock, b.FirstInclusionBlock), slices.Compare(a.ID[:], b.ID[:]))
+	})
+
+	for _, algo := range compression {
+		algo.CompressionRatio = ratio(algo.CompressedSize, algo.UncompressedSize)
+		summary.Compression = append(summary.Compression, *algo)
+	}
+	slices.SortFunc(summary.Compression, func(a, b ComprAlgoStats) int {
+		return cmp.Compare(a.ComprAlgo, b.ComprAlgo)
+	})
+	summary.CompressionRatio = ratio(summary.CompressedSize, summary.UncompressedSize)
+	summary.L1FeePerL2Byte = feePer(summary.L1Fee, summary.UncompressedSize)
+	summary.L1FeePerL2Tx = feePer(summary.L1Fee, summary.L2Transactions)
+	summary.Latency = latencyStats(latencies)
+	report.Summary = summary
+	return report, nil
+}
+
+// channelStats returns the statistics of the channel, and the latencies of its L2 blocks.
+func channelStats(ch *channelFile, costs map[common.Hash]*txCost) (ChannelStats, []uint64) {
+	stats := ChannelStats{
+		ID:                  ch.ID,
+		IsReady:             ch.IsReady,
+		InvalidFrames:       ch.InvalidFrames,
+		InvalidBatches:      ch.InvalidBatches,
+		Frames:              len(ch.Frames),
+		FirstInclusionBlock: math.MaxUint64,
+		CompressedSize:      ch.CompressedSize,
+		UncompressedSize:    ch.UncompressedSize,
+		CompressionRatio:    ratio(ch.CompressedSize, ch.UncompressedSize),
+		L2Blocks:            len(ch.L2BlockTimestamps),
+		L2Transactions:      ch.L2Transactions,
+	}
+	if len(ch.ComprAlgos) > 0 {
+		stats.ComprAlgo = ch.ComprAlgos[0]
+	}
+
+	var fee, blobBytes, usedBlobBytes float64
+	var readyTime uint64
+	txHashes := make(map[common.Hash]struct{})
+	for _, frame := range ch.Frames {
+		txHashes[frame.TxHash] = struct{}{}
+		stats.FirstInclusionBlock = min(stats.FirstInclusionBlock, frame.InclusionBlock)
+		stats.LastInclusionBlock = max(stats.LastInclusionBlock, frame.InclusionBlock)
+		readyTime = max(readyTime, frame.Timestamp)
+		cost, ok := costs[frame.TxHash]
+		if !ok {
+			continue
+		}
+		size := frameSize(frame.Frame)
+		txFeeF, _ := new(big.Float).SetInt(cost.fee).Float64()
+		fee += cost.share(txFeeF, size)
+		if cost.blobBytes > 0 {
+			blobBytes += cost.share(float64(cost.blobBytes), size)
+			usedBlobBytes += cost.share(float64(cost.usedBlobBytes), size)
+		}
+	}
+	stats.L1Transactions = len(txHashes)
+	if blobBytes > 0 {
+		stats.BlobFillRate = usedBlobBytes / blobBytes
+	}
+	stats.L1Fee, _ = new(big.Float).SetFloat64(fee).Int(nil)
+	stats.L1FeePerL2Byte = feePer(stats.L1Fee, stats.UncompressedSize)
+	stats.L1FeePerL2Tx = feePer(stats.L1Fee, stats.L2Transactions)
+
+	var latencies []uint64
+	if ch.IsReady {
+		for _, ts := range ch.L2BlockTimestamps {
+			if readyTime > ts {
+				latencies = append(latencies, readyTime-ts)
+			} else {
+				latencies = append(latencies, 0)
+			}
+		}
+	}
+	stats.Latency = latencyStats(slices.Clone(latencies))
+	return stats, latencies
+}
+
+// txFee returns the execution and blob fee the transaction paid.
+func txFee(receipt *types.Receipt) *big.Int {
+	fee := new(big.Int)
+	if receipt.EffectiveGasPrice != nil {
+		fee.Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	}
+	if receipt.BlobGasPrice != nil {
+		fee.Add(fee, new(big.Int).Mul(new(big.Int).SetUint64(receipt.BlobGasUsed), receipt.BlobGasPrice))
+	}
+	return fee
+}
+
+// frameSize returns the encoded size of the frame.
+func frameSize(frame derive.Frame) uint64 {
+	return derive.FrameV0OverHeadSize + uint64(len(frame.Data))
+}
+
+func ratio(compressed, uncompressed uint64) float64 {
+	if uncompressed == 0 {
+		return 0
+	}
+	return float64(compressed) / float64(uncompressed)
+}
+
+func feePer(fee *big.Int, n uint64) float64 {
+	if n == 0 {
+		return 0
+	}
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(fee), new(big.Float).SetUint64(n)).Float64()
+	return f
+}
+
+// latencyStats sorts the latencies and returns their statistics.
+func latencyStats(latencies []uint64) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+	slices.Sort(latencies)
+	var sum float64
+	for _, l := range latencies {
+		sum += float64(l)
+	}
+	percentile := func(p int) uint64 {
+		return latencies[(len(latencies)-1)*p/100]
+	}
+	return LatencyStats{
+		Min:  latencies[0],
+		Mean: sum / float64(len(latencies)),
+		P50:  percentile(50),
+		P95:  percentile(95),
+		Max:  latencies[len(latencies)-1],
+	}
+}
+
+// loadTransactions loads the fetched transactions of the inbox, or all if the inbox is the zero address.
+// Unlike the transactions of reassemble.LoadFrames, these include those of invalid senders.
+func loadTransactions(dir string, inbox common.Address) ([]*fetch.TransactionWithMetadata, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []*fetch.TransactionWithMetadata
+	for _, file := range files {
+		var txm fetch.TransactionWithMetadata
+		if err := loadJSON(path.Join(dir, file.Name()), &txm); err != nil {
+			return nil, err
+		}
+		if inbox == (common.Address{}) || txm.InboxAddr == inbox {
+			out = append(out, &txm)
+		}
+	}
+	return out, nil
+}
+
+func loadChannels(dir string) ([]*channelFile, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []*channelFile
+	for _, file := range files {
+		var ch channelFile
+		if err := loadJSON(path.Join(dir, file.Name()), &ch); err != nil {
+			return nil, err
+		}
+		if len(ch.Frames) == 0 {
+			continue
+		}
+		out = append(out, &ch)
+	}
+	return out, nil
+}
+
+func loadJSON(file string, v any) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %v: %w", file, err)
+	}
+	return nil
+}
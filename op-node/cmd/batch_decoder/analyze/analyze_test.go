@@ -0,0 +1,146 @@
+package analyze
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/batch_decoder/reassemble"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+func TestRatio(t *testing.T) {
+	for _, test := range []struct {
+		name                     string
+		compressed, uncompressed uint64
+		expected                 float64
+	}{
+		{name: "Empty", expected: 0},
+		{name: "NoUncompressed", compressed: 100, expected: 0},
+		{name: "Half", compressed: 50, uncompressed: 100, expected: 0.5},
+		{name: "Expanded", compressed: 120, uncompressed: 100, expected: 1.2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, ratio(test.compressed, test.uncompressed))
+		})
+	}
+}
+
+func TestFeePer(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		fee      int64
+		n        uint64
+		expected float64
+	}{
+		{name: "None", fee: 1000, n: 0, expected: 0},
+		{name: "Even", fee: 1000, n: 10, expected: 100},
+		{name: "Fraction", fee: 1, n: 4, expected: 0.25},
+		{name: "NoFee", fee: 0, n: 10, expected: 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, feePer(big.NewInt(test.fee), test.n))
+		})
+	}
+}
+
+func TestTxFee(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		receipt  *types.Receipt
+		expected int64
+	}{
+		{name: "NoPrices", receipt: &types.Receipt{GasUsed: 21000}, expected: 0},
+		{name: "Calldata", receipt: &types.Receipt{GasUsed: 21000, EffectiveGasPrice: big.NewInt(10)}, expected: 210_000},
+		{
+			name:     "Blob",
+			receipt:  &types.Receipt{GasUsed: 21000, EffectiveGasPrice: big.NewInt(10), BlobGasUsed: 131072, BlobGasPrice: big.NewInt(2)},
+			expected: 210_000 + 262_144,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, big.NewInt(test.expected), txFee(test.receipt))
+		})
+	}
+}
+
+func TestTxCostShare(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		frameBytes uint64
+		size       uint64
+		expected   float64
+	}{
+		{name: "NoFrames", frameBytes: 0, size: 100, expected: 0},
+		{name: "AllFrames", frameBytes: 100, size: 100, expected: 1000},
+		{name: "Quarter", frameBytes: 400, size: 100, expected: 250},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cost := &txCost{fee: new(big.Int), frameBytes: test.frameBytes}
+			require.Equal(t, test.expected, cost.share(1000, test.size))
+		})
+	}
+}
+
+func TestLatencyStats(t *testing.T) {
+	require.Equal(t, LatencyStats{}, latencyStats(nil))
+	require.Equal(t, LatencyStats{Min: 4, Mean: 4, P50: 4, P95: 4, Max: 4}, latencyStats([]uint64{4}))
+	require.Equal(t, LatencyStats{Min: 1, Mean: 3, P50: 3, P95: 3, Max: 5}, latencyStats([]uint64{5, 1, 3}))
+
+	latencies := make([]uint64, 0, 101)
+	for i := range uint64(101) {
+		latencies = append(latencies, 100-i)
+	}
+	require.Equal(t, LatencyStats{Min: 0, Mean: 50, P50: 50, P95: 95, Max: 100}, latencyStats(latencies))
+}
+
+func TestChannelStats(t *testing.T) {
+	// Frames of 77 data bytes are 100 bytes encoded.
+	frame := func(number uint16) derive.Frame {
+		return derive.Frame{FrameNumber: number, Data: make([]byte, 100-derive.FrameV0OverHeadSize)}
+	}
+	blobTx, calldataTx, unknownTx := common.Hash{0x01}, common.Hash{0x02}, common.Hash{0x03}
+	costs := map[common.Hash]*txCost{
+		// A blob transaction with only a frame of the channel.
+		blobTx: {fee: big.NewInt(1000), frameBytes: 100, blobBytes: eth.MaxBlobDataSize, usedBlobBytes: 101},
+		// A calldata transaction that also carries a frame of another channel.
+		calldataTx: {fee: big.NewInt(2000), frameBytes: 200},
+	}
+	ch := &channelFile{
+		IsReady: true,
+		Frames: []reassemble.FrameWithMetadata{
+			{TxHash: blobTx, InclusionBlock: 7, Timestamp: 30, Frame: frame(0)},
+			{TxHash: calldataTx, InclusionBlock: 5, Timestamp: 25, Frame: frame(1)},
+			{TxHash: unknownTx, InclusionBlock: 6, Timestamp: 26, Frame: frame(2)},
+		},
+		ComprAlgos:        []derive.CompressionAlgo{derive.Brotli10, derive.Brotli10},
+		CompressedSize:    400,
+		UncompressedSize:  1000,
+		L2BlockTimestamps: []uint64{10, 20, 31},
+		L2Transactions:    4,
+	}
+
+	stats, latencies := channelStats(ch, costs)
+	require.Equal(t, 3, stats.Frames)
+	require.Equal(t, 3, stats.L1Transactions)
+	require.Equal(t, uint64(5), stats.FirstInclusionBlock)
+	require.Equal(t, uint64(7), stats.LastInclusionBlock)
+	require.Equal(t, derive.Brotli10, stats.ComprAlgo)
+	require.Equal(t, 0.4, stats.CompressionRatio)
+	require.Equal(t, 101/float64(eth.MaxBlobDataSize), stats.BlobFillRate, "only the blob transaction counts for the fill rate")
+	require.Equal(t, big.NewInt(1000+1000), stats.L1Fee, "fees are attributed by frame size")
+	require.Equal(t, 2.0, stats.L1FeePerL2Byte)
+	require.Equal(t, 500.0, stats.L1FeePerL2Tx)
+	require.Equal(t, []uint64{20, 10, 0}, latencies, "latency to the last frame, and 0 for blocks after it")
+	require.Equal(t, LatencyStats{Min: 0, Mean: 10, P50: 10, P95: 10, Max: 20}, stats.Latency)
+
+	ch.IsReady = false
+	stats, latencies = channelStats(ch, costs)
+	require.Empty(t, latencies, "channels that are never ready have no latency")
+	require.Equal(t, LatencyStats{}, stats.Latency)
+}
@@ -0,0 +1,95 @@
+package analyze
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path"
+	"strconv"
+)
+
+// WriteReport writes the report to the out directory, as report.json, and as channels.csv and compression.csv
+// with the per-channel and per-compression-algorithm statistics.
+func WriteReport(report *Report, outDirectory string) error {
+	if err := os.MkdirAll(outDirectory, 0o750); err != nil {
+		return err
+	}
+	if err := writeJSON(report, path.Join(outDirectory, "report.json")); err != nil {
+		return err
+	}
+	channels := [][]string{{
+		"id", "is_ready", "invalid_frames", "invalid_batches", "compr_algo", "frames", "l1_transactions",
+		"first_inclusion_block", "last_inclusion_block", "compressed_size", "uncompressed_size", "compression_ratio",
+		"l2_blocks", "l2_transactions", "blob_fill_rate", "l1_fee", "l1_fee_per_l2_byte", "l1_fee_per_l2_tx",
+		"latency_min", "latency_mean", "latency_p50", "latency_p95", "latency_max",
+	}}
+	for _, ch := range report.Channels {
+		channels = append(channels, []string{
+			ch.ID.String(),
+			strconv.FormatBool(ch.IsReady),
+			strconv.FormatBool(ch.InvalidFrames),
+			strconv.FormatBool(ch.InvalidBatches),
+			string(ch.ComprAlgo),
+			strconv.Itoa(ch.Frames),
+			strconv.Itoa(ch.L1Transactions),
+			formatUint(ch.FirstInclusionBlock),
+			formatUint(ch.LastInclusionBlock),
+			formatUint(ch.CompressedSize),
+			formatUint(ch.UncompressedSize),
+			formatFloat(ch.CompressionRatio),
+			strconv.Itoa(ch.L2Blocks),
+			formatUint(ch.L2Transactions),
+			formatFloat(ch.BlobFillRate),
+			ch.L1Fee.String(),
+			formatFloat(ch.L1FeePerL2Byte),
+			formatFloat(ch.L1FeePerL2Tx),
+			formatUint(ch.Latency.Min),
+			formatFloat(ch.Latency.Mean),
+			formatUint(ch.Latency.P50),
+			formatUint(ch.Latency.P95),
+			formatUint(ch.Latency.Max),
+		})
+	}
+	if err := writeCSV(channels, path.Join(outDirectory, "channels.csv")); err != nil {
+		return err
+	}
+	compression := [][]string{{"compr_algo", "channels", "compressed_size", "uncompressed_size", "compression_ratio"}}
+	for _, algo := range report.Summary.Compression {
+		compression = append(compression, []string{
+			string(algo.ComprAlgo),
+			strconv.Itoa(algo.Channels),
+			formatUint(algo.CompressedSize),
+			formatUint(algo.UncompressedSize),
+			formatFloat(algo.CompressionRatio),
+		})
+	}
+	return writeCSV(compression, path.Join(outDirectory, "compression.csv"))
+}
+
+func writeJSON(v any, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeCSV(records [][]string, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return csv.NewWriter(file).WriteAll(records)
+}
+
+func formatUint(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
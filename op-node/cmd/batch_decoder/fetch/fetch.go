@@ -34,6 +34,9 @@ type TransactionWithMetadata struct {
 	FrameErrs   []string           `json:"frame_parse_error"`
 	ValidFrames []bool             `json:"valid_data"`
 	Tx          *types.Transaction `json:"tx"`
+	// Receipt is the receipt of the transaction, for the fees paid.
+	// It is only fetched if Config.Receipts is set, and missing if it could not be fetched.
+	Receipt *types.Receipt `json:"receipt,omitempty"`
 }
 
 type Config struct {
@@ -43,6 +46,8 @@ type Config struct {
 	BatchSenders       map[common.Address]struct{}
 	OutDirectory       string
 	ConcurrentRequests uint64
+	// Receipts enables fetching the receipts of the transactions, for the fees paid.
+	Receipts bool
 }
 
 // Batches fetches & stores all transactions sent to the batch inbox address in
@@ -103,6 +108,13 @@ func fetchBatchesPerBlock(ctx context.Context, client *ethclient.Client, beacon
 				invalidBatchCount += 1
 				validSender = false
 			}
+			var receipt *types.Receipt
+			if config.Receipts {
+				receipt, err = client.TransactionReceipt(ctx, tx.Hash())
+				if err != nil {
+					fmt.Printf("Unable to fetch the receipt of transaction (%s): %v\n", tx.Hash().String(), err)
+				}
+			}
 			var datas []hexutil.Bytes
 			if tx.Type() != types.BlobTxType {
 				datas = append(datas, tx.Data())
@@ -165,6 +177,7 @@ func fetchBatchesPerBlock(ctx context.Context, client *ethclient.Client, beacon
 			}
 			txm := &TransactionWithMetadata{
 				Tx:          tx,
+				Receipt:     receipt,
 				Sender:      sender,
 				ValidSender: validSender,
 				TxIndex:     uint64(i),
@@ -9,6 +9,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/batch_decoder/analyze"
 	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/batch_decoder/fetch"
 	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/batch_decoder/reassemble"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
@@ -73,6 +74,10 @@ func main() {
 					Value: 10,
 					Usage: "Concurrency level when fetching L1",
 				},
+				&cli.BoolFlag{
+					Name:  "receipts",
+					Usage: "Fetch the receipts of the batch transactions, for the L1 fees reported by analyze",
+				},
 			},
 			Action: func(cliCtx *cli.Context) error {
 				l1Client, err := ethclient.Dial(cliCtx.String("l1"))
@@ -124,6 +129,7 @@ func main() {
 					BatchInbox:         inbox,
 					OutDirectory:       cliCtx.String("out"),
 					ConcurrentRequests: uint64(cliCtx.Int("concurrent-requests")),
+					Receipts:           cliCtx.Bool("receipts"),
 				}
 				fmt.Printf("Fetch Config: L1 Chain ID: %v. Inbox Address: %v. Valid Senders: %v.\n", config.ChainID, config.BatchInbox, config.BatchSenders)
 
@@ -193,6 +199,53 @@ func main() {
 			},
 		},
 
+		{
+			Name:  "analyze",
+			Usage: "Reports per-channel and aggregate statistics of fetched transactions and reassembled channels",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "in",
+					Value: "/tmp/batch_decoder/transactions_cache",
+					Usage: "Cache directory for the found transactions",
+				},
+				&cli.StringFlag{
+					Name:  "channels",
+					Value: "/tmp/batch_decoder/channel_cache",
+					Usage: "Cache directory for the found channels",
+				},
+				&cli.StringFlag{
+					Name:  "out",
+					Value: "/tmp/batch_decoder/analysis",
+					Usage: "Directory for the JSON and CSV reports",
+				},
+				&cli.StringFlag{
+					Name:  "inbox",
+					Value: "0x0000000000000000000000000000000000000000",
+					Usage: "(Optional) Batch Inbox Address",
+				},
+			},
+			Action: func(cliCtx *cli.Context) error {
+				config := analyze.Config{
+					BatchInbox:       common.HexToAddress(cliCtx.String("inbox")),
+					TxDirectory:      cliCtx.String("in"),
+					ChannelDirectory: cliCtx.String("channels"),
+				}
+				report, err := analyze.Analyze(config)
+				if err != nil {
+					return err
+				}
+				out := cliCtx.String("out")
+				if err := analyze.WriteReport(report, out); err != nil {
+					return err
+				}
+				s := report.Summary
+				fmt.Printf("Analyzed %v transactions and %v channels (%v ready). Compression ratio: %.3f. Blob fill rate: %.3f. L1 fee per L2 byte: %.0f wei\n",
+					s.L1Transactions, s.Channels, s.ReadyChannels, s.CompressionRatio, s.BlobFillRate, s.L1FeePerL2Byte)
+				fmt.Printf("Wrote reports to %v\n", out)
+				return nil
+			},
+		},
+
 		{
 			Name:  "force-close",
 			Usage: "Create the tx data which will force close a channel",
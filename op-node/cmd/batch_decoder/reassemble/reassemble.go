@@ -16,6 +16,7 @@ import (
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 type ChannelWithMetadata struct {
@@ -27,6 +28,13 @@ type ChannelWithMetadata struct {
 	Batches        []derive.Batch           `json:"batches"`
 	BatchTypes     []int                    `json:"batch_types"`
 	ComprAlgos     []derive.CompressionAlgo `json:"compr_algos"`
+	// CompressedSize is the size of the frame data of the channel,
+	// UncompressedSize is the RLP encoded size of the batches read from it.
+	CompressedSize   uint64 `json:"compressed_size"`
+	UncompressedSize uint64 `json:"uncompressed_size"`
+	// L2BlockTimestamps are the timestamps of the L2 blocks of the batches.
+	L2BlockTimestamps []uint64 `json:"l2_block_timestamps"`
+	L2Transactions    uint64   `json:"l2_transactions"`
 }
 
 type FrameWithMetadata struct {
@@ -100,8 +108,10 @@ func ProcessFrames(cfg Config, rollupCfg *rollup.Config, id derive.ChannelID, fr
 	spec := rollup.NewChainSpec(rollupCfg)
 	ch := derive.NewChannel(id, eth.L1BlockRef{Number: frames[0].InclusionBlock}, rollupCfg.IsHolocene(frames[0].Timestamp))
 	invalidFrame := false
+	var compressedSize uint64
 
 	for _, frame := range frames {
+		compressedSize += uint64(len(frame.Frame.Data))
 		if ch.IsReady() {
 			fmt.Printf("Channel %v is ready despite having more frames\n", id.String())
 			invalidFrame = true
@@ -114,9 +124,12 @@ func ProcessFrames(cfg Config, rollupCfg *rollup.Config, id derive.ChannelID, fr
 	}
 
 	var (
-		batches    []derive.Batch
-		batchTypes []int
-		comprAlgos []derive.CompressionAlgo
+		batches           []derive.Batch
+		batchTypes        []int
+		comprAlgos        []derive.CompressionAlgo
+		uncompressedSize  uint64
+		l2BlockTimestamps []uint64
+		l2Transactions    uint64
 	)
 
 	invalidBatches := false
@@ -129,6 +142,9 @@ func ProcessFrames(cfg Config, rollupCfg *rollup.Config, id derive.ChannelID, fr
 					invalidBatches = true
 				} else {
 					comprAlgos = append(comprAlgos, batchData.ComprAlgo)
+					if enc, err := rlp.EncodeToBytes(batchData); err == nil {
+						uncompressedSize += uint64(len(enc))
+					}
 					batchType := batchData.GetBatchType()
 					batchTypes = append(batchTypes, int(batchType))
 					switch batchType {
@@ -137,6 +153,9 @@ func ProcessFrames(cfg Config, rollupCfg *rollup.Config, id derive.ChannelID, fr
 						if err != nil {
 							invalidBatches = true
 							fmt.Printf("Error converting singularBatch from batchData for channel %v. Err: %v\n", id.String(), err)
+						} else {
+							l2BlockTimestamps = append(l2BlockTimestamps, singularBatch.Timestamp)
+							l2Transactions += uint64(len(singularBatch.Transactions))
 						}
 						// singularBatch will be nil when errored
 						batches = append(batches, singularBatch)
@@ -145,6 +164,11 @@ func ProcessFrames(cfg Config, rollupCfg *rollup.Config, id derive.ChannelID, fr
 						if err != nil {
 							invalidBatches = true
 							fmt.Printf("Error deriving spanBatch from batchData for channel %v. Err: %v\n", id.String(), err)
+						} else {
+							for _, block := range spanBatch.Batches {
+								l2BlockTimestamps = append(l2BlockTimestamps, block.Timestamp)
+								l2Transactions += uint64(len(block.Transactions))
+							}
 						}
 						// spanBatch will be nil when errored
 						batches = append(batches, spanBatch)
@@ -169,6 +193,11 @@ func ProcessFrames(cfg Config, rollupCfg *rollup.Config, id derive.ChannelID, fr
 		Batches:        batches,
 		BatchTypes:     batchTypes,
 		ComprAlgos:     comprAlgos,
+
+		CompressedSize:    compressedSize,
+		UncompressedSize:  uncompressedSize,
+		L2BlockTimestamps: l2BlockTimestamps,
+		L2Transactions:    l2Transactions,
 	}
 }
 
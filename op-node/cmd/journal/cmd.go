@@ -0,0 +1,76 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/node/replay"
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
+)
+
+var (
+	PathFlag = &cli.PathFlag{
+		Name:     "journal.path",
+		Usage:    "File path of the event journal recorded by op-node with --journal.path",
+		Required: true,
+	}
+	UntilFlag = &cli.Uint64Flag{
+		Name:  "until",
+		Usage: "Stop the replay after the input or event with this journal sequence number. Replays the whole journal if 0.",
+	}
+)
+
+var Subcommands = []*cli.Command{
+	{
+		Name:  "replay",
+		Usage: "Replays an event journal against the derivers, and reports where the replay diverges from the recording",
+		Flags: []cli.Flag{PathFlag, UntilFlag},
+		Action: func(ctx *cli.Context) error {
+			logger := oplog.NewLogger(oplog.AppOut(ctx), oplog.ReadCLIConfig(ctx))
+			entries, err := journal.Read(ctx.Path(PathFlag.Name))
+			if err != nil {
+				return err
+			}
+			r, err := replay.NewReplayer(logger, entries)
+			if err != nil {
+				return fmt.Errorf("failed to set up replay: %w", err)
+			}
+			until := ctx.Uint64(UntilFlag.Name)
+			logger.Info("Replaying event journal", "entries", len(entries), "until", until)
+
+			var replayErr error
+			for {
+				seq, err := r.Step(ctx.Context)
+				if errors.Is(err, io.EOF) {
+					break
+				} else if err != nil {
+					replayErr = err
+					break
+				}
+				if status := r.SyncStatus(); status != nil {
+					logger.Debug("Replayed", "seq", seq, "currentL1", status.CurrentL1, "unsafe", status.UnsafeL2, "safe", status.SafeL2)
+				}
+				if until != 0 && seq >= until {
+					break
+				}
+			}
+
+			out := json.NewEncoder(os.Stdout)
+			out.SetIndent("", "  ")
+			if err := out.Encode(r.SyncStatus()); err != nil {
+				return fmt.Errorf("failed to write sync status: %w", err)
+			}
+			var div *replay.Divergence
+			if errors.As(replayErr, &div) {
+				logger.Error("Replay diverged from the recording", "seq", div.Seq, "expected", div.Expected, "got", div.Got)
+			}
+			return replayErr
+		},
+	},
+}
@@ -12,8 +12,10 @@ import (
 	opnode "github.com/tokamak-network/tokamak-thanos/op-node"
 	"github.com/tokamak-network/tokamak-thanos/op-node/chaincfg"
 	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/genesis"
+	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/journal"
 	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/networks"
 	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/p2p"
+	"github.com/tokamak-network/tokamak-thanos/op-node/cmd/safedb"
 	"github.com/tokamak-network/tokamak-thanos/op-node/flags"
 	"github.com/tokamak-network/tokamak-thanos/op-node/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-node/node"
@@ -62,6 +64,16 @@ func main() {
 			Name:        "networks",
 			Subcommands: networks.Subcommands,
 		},
+		{
+			Name:        "safedb",
+			Usage:       "Export, import and prune the safe head database",
+			Subcommands: safedb.Subcommands,
+		},
+		{
+			Name:        "journal",
+			Usage:       "Replay event journals recorded with --journal.path",
+			Subcommands: journal.Subcommands,
+		},
 	}
 
 	ctx := ctxinterrupt.WithSignalWaiterMain(context.Background())
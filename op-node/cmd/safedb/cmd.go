@@ -0,0 +1,124 @@
+package safedb
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/node/safedb"
+	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
+)
+
+// The safe head database is opened directly, so these commands must not run while op-node is using the database.
+var (
+	DBPathFlag = &cli.StringFlag{
+		Name:     "safedb.path",
+		Usage:    "File path of the safe head database. op-node must not be running with this database.",
+		Required: true,
+	}
+	StartFlag = &cli.Uint64Flag{
+		Name:  "start",
+		Usage: "First L1 block number to export safe head updates for",
+		Value: 0,
+	}
+	EndFlag = &cli.Uint64Flag{
+		Name:  "end",
+		Usage: "Last L1 block number to export safe head updates for",
+		Value: math.MaxUint64,
+	}
+	OutFlag = &cli.PathFlag{
+		Name:  "out",
+		Usage: "File to export newline-delimited JSON to. Defaults to stdout.",
+	}
+	InFlag = &cli.PathFlag{
+		Name:  "in",
+		Usage: "File to import newline-delimited JSON from. Defaults to stdin.",
+	}
+	BeforeFlag = &cli.Uint64Flag{
+		Name:     "before",
+		Usage:    "Prune safe head updates recorded before this L1 block number. The last update at or before it is kept.",
+		Required: true,
+	}
+)
+
+var Subcommands = []*cli.Command{
+	{
+		Name:  "export",
+		Usage: "Exports safe head updates as newline-delimited JSON",
+		Flags: []cli.Flag{DBPathFlag, StartFlag, EndFlag, OutFlag},
+		Action: func(ctx *cli.Context) error {
+			logger := oplog.NewLogger(oplog.AppOut(ctx), oplog.ReadCLIConfig(ctx))
+			start, end := ctx.Uint64(StartFlag.Name), ctx.Uint64(EndFlag.Name)
+			if end < start {
+				return fmt.Errorf("end %d is before start %d", end, start)
+			}
+			db, err := safedb.NewSafeDB(logger, ctx.String(DBPathFlag.Name))
+			if err != nil {
+				return fmt.Errorf("failed to open safe head database: %w", err)
+			}
+			defer db.Close()
+
+			var out io.Writer = os.Stdout
+			if path := ctx.Path(OutFlag.Name); path != "" {
+				f, err := os.Create(path)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+			count, err := db.Export(ctx.Context, out, start, end)
+			if err != nil {
+				return fmt.Errorf("failed to export safe heads: %w", err)
+			}
+			logger.Info("Exported safe heads", "count", count, "start", start, "end", end)
+			return nil
+		},
+	},
+	{
+		Name:  "import",
+		Usage: "Imports newline-delimited JSON safe head updates, following the last update in the database",
+		Flags: []cli.Flag{DBPathFlag, InFlag},
+		Action: func(ctx *cli.Context) error {
+			logger := oplog.NewLogger(oplog.AppOut(ctx), oplog.ReadCLIConfig(ctx))
+			db, err := safedb.NewSafeDB(logger, ctx.String(DBPathFlag.Name))
+			if err != nil {
+				return fmt.Errorf("failed to open safe head database: %w", err)
+			}
+			defer db.Close()
+
+			var in io.Reader = os.Stdin
+			if path := ctx.Path(InFlag.Name); path != "" {
+				f, err := os.Open(path)
+				if err != nil {
+					return fmt.Errorf("failed to open input file: %w", err)
+				}
+				defer f.Close()
+				in = f
+			}
+			count, err := db.Import(ctx.Context, in)
+			if err != nil {
+				return fmt.Errorf("failed to import safe heads after %d entries: %w", count, err)
+			}
+			logger.Info("Imported safe heads", "count", count)
+			return nil
+		},
+	},
+	{
+		Name:  "prune",
+		Usage: "Deletes safe head updates recorded before an L1 block",
+		Flags: []cli.Flag{DBPathFlag, BeforeFlag},
+		Action: func(ctx *cli.Context) error {
+			logger := oplog.NewLogger(oplog.AppOut(ctx), oplog.ReadCLIConfig(ctx))
+			db, err := safedb.NewSafeDB(logger, ctx.String(DBPathFlag.Name))
+			if err != nil {
+				return fmt.Errorf("failed to open safe head database: %w", err)
+			}
+			defer db.Close()
+			return db.PruneBefore(ctx.Uint64(BeforeFlag.Name))
+		},
+	},
+}
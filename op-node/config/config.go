@@ -15,10 +15,13 @@ import (
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/driver"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/interop"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing/inclusion"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing/preconf"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sync"
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
 	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 	"github.com/tokamak-network/tokamak-thanos/op-supervisor/supervisor/backend/depset"
 	"github.com/ethereum/go-ethereum/params"
 )
@@ -33,6 +36,12 @@ type Config struct {
 
 	Driver driver.Config
 
+	// Inclusion configures the transactions that the sequencer includes ahead of the tx pool
+	Inclusion inclusion.Config
+
+	// Preconf configures the issuing and monitoring of sequencer preconfirmations
+	Preconf preconf.Config
+
 	Rollup rollup.Config
 
 	L1ChainConfig *params.ChainConfig
@@ -51,6 +60,8 @@ type Config struct {
 
 	Pprof oppprof.CLIConfig
 
+	Tracing tracing.CLIConfig
+
 	// Used to poll the L1 for new finalized or safe blocks
 	L1EpochPollInterval time.Duration
 
@@ -59,6 +70,12 @@ type Config struct {
 	// Path to store safe head database. Disabled when set to empty string
 	SafeDBPath string
 
+	// SafeDBRetention is the number of L1 blocks of safe head history to keep. 0 keeps all history.
+	SafeDBRetention uint64
+
+	// Path to record the event journal to. Disabled when set to empty string
+	JournalPath string
+
 	// RuntimeConfigReloadInterval defines the interval between runtime config reloads.
 	// Disabled if <= 0.
 	// Runtime config changes should be picked up from log-events,
@@ -162,6 +179,9 @@ func (cfg *Config) Check() error {
 	if err := cfg.Pprof.Check(); err != nil {
 		return fmt.Errorf("pprof config error: %w", err)
 	}
+	if err := cfg.Tracing.Check(); err != nil {
+		return fmt.Errorf("tracing config error: %w", err)
+	}
 	if cfg.P2P != nil {
 		if err := cfg.P2P.Check(); err != nil {
 			return fmt.Errorf("p2p config error: %w", err)
@@ -178,6 +198,15 @@ func (cfg *Config) Check() error {
 			return fmt.Errorf("sequencer must be enabled when conductor is enabled")
 		}
 	}
+	if err := cfg.Inclusion.Check(); err != nil {
+		return fmt.Errorf("inclusion policy config error: %w", err)
+	}
+	if cfg.Inclusion.Enabled() && !cfg.Driver.SequencerEnabled {
+		return fmt.Errorf("sequencer must be enabled when an inclusion policy is configured")
+	}
+	if cfg.Preconf.Enabled && !cfg.Driver.SequencerEnabled {
+		return fmt.Errorf("sequencer must be enabled when preconfirmations are enabled")
+	}
 	if err := cfg.AltDA.Check(); err != nil {
 		return fmt.Errorf("altDA config error: %w", err)
 	}
@@ -18,6 +18,7 @@ import (
 	"github.com/tokamak-network/tokamak-thanos/op-service/ptr"
 	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
 	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 )
 
 // Flags
@@ -52,7 +53,7 @@ var (
 	/* Required Flags */
 	L1NodeAddr = &cli.StringFlag{
 		Name:     "l1",
-		Usage:    "Address of L1 User JSON-RPC endpoint to use (eth namespace required)",
+		Usage:    "Address of L1 User JSON-RPC endpoint to use (eth namespace required). Multiple endpoints can be given, separated by '|' and optionally followed by quorum=N, to fail over between them and to require N endpoints to agree on consensus-critical calls.",
 		Value:    "http://127.0.0.1:8545",
 		EnvVars:  prefixEnvVars("L1_ETH_RPC"),
 		Category: RollupCategory,
@@ -271,6 +272,75 @@ var (
 		Value:    false,
 		Category: SequencerCategory,
 	}
+	SequencerForcedTxsFlag = &cli.PathFlag{
+		Name:      "sequencer.inclusion.forced-txs",
+		Usage:     "Path of a file with signed transactions, one 0x-prefixed hex-encoded transaction per line, to include ahead of the tx pool.",
+		EnvVars:   prefixEnvVars("SEQUENCER_INCLUSION_FORCED_TXS"),
+		TakesFile: true,
+		Category:  SequencerCategory,
+	}
+	SequencerDenyListFlag = &cli.StringSliceFlag{
+		Name:     "sequencer.inclusion.deny-list",
+		Usage:    "Addresses whose forced transactions and bundles, as sender or recipient, are never included.",
+		EnvVars:  prefixEnvVars("SEQUENCER_INCLUSION_DENY_LIST"),
+		Category: SequencerCategory,
+	}
+	SequencerSenderRateLimitFlag = &cli.Float64Flag{
+		Name:     "sequencer.inclusion.sender-rate-limit",
+		Usage:    "Maximum number of forced transactions and bundle transactions per second of L2 block time, per sender. Disabled if 0.",
+		EnvVars:  prefixEnvVars("SEQUENCER_INCLUSION_SENDER_RATE_LIMIT"),
+		Value:    0,
+		Category: SequencerCategory,
+	}
+	SequencerSenderBurstFlag = &cli.IntFlag{
+		Name:     "sequencer.inclusion.sender-burst",
+		Usage:    "Maximum number of transactions per sender that the sender rate limit allows at once.",
+		EnvVars:  prefixEnvVars("SEQUENCER_INCLUSION_SENDER_BURST"),
+		Value:    10,
+		Category: SequencerCategory,
+	}
+	SequencerBundlesMaxFlag = &cli.IntFlag{
+		Name:     "sequencer.bundles.max",
+		Usage:    "Maximum number of pending bundles.",
+		EnvVars:  prefixEnvVars("SEQUENCER_BUNDLES_MAX"),
+		Value:    1000,
+		Category: SequencerCategory,
+	}
+	SequencerBundlesRPCAddrFlag = &cli.StringFlag{
+		Name:     "sequencer.bundles.rpc.addr",
+		Usage:    "Bundle-submission RPC listening address, serving inclusion_sendBundle. Optional, disabled if left empty.",
+		EnvVars:  prefixEnvVars("SEQUENCER_BUNDLES_RPC_ADDR"),
+		Value:    "",
+		Category: SequencerCategory,
+	}
+	SequencerBundlesRPCPortFlag = &cli.IntFlag{
+		Name:     "sequencer.bundles.rpc.port",
+		Usage:    "Bundle-submission RPC listening port.",
+		EnvVars:  prefixEnvVars("SEQUENCER_BUNDLES_RPC_PORT"),
+		Value:    9646,
+		Category: SequencerCategory,
+	}
+	SequencerBundlesJWTSecretFlag = &cli.StringFlag{
+		Name: "sequencer.bundles.jwt-secret",
+		Usage: "Bundle-submission RPC server authentication. Path to JWT secret key. Keys are 32 bytes, hex encoded in a file. " +
+			"A new key will be generated if the file is empty.",
+		EnvVars:  prefixEnvVars("SEQUENCER_BUNDLES_JWT_SECRET"),
+		Value:    "",
+		Category: SequencerCategory,
+	}
+	SequencerPreconfEnabledFlag = &cli.BoolFlag{
+		Name: "sequencer.preconf.enabled",
+		Usage: "Sign and gossip preconfirmations of the transactions of the blocks that the sequencer builds: those selected by the inclusion policy when the block starts building, " +
+			"and those from the tx pool of the engine when the block is sealed. Requires a p2p sequencer key.",
+		EnvVars:  prefixEnvVars("SEQUENCER_PRECONF_ENABLED"),
+		Category: SequencerCategory,
+	}
+	PreconfMonitorFlag = &cli.BoolFlag{
+		Name:     "preconf.monitor",
+		Usage:    "Monitor the preconfirmations of the sequencer, received from p2p gossip, and report those not kept by the canonical chain.",
+		EnvVars:  prefixEnvVars("PRECONF_MONITOR"),
+		Category: OperationsCategory,
+	}
 	FinalityLookbackFlag = &cli.Uint64Flag{
 		Name:     "finality.lookback",
 		Usage:    "Number of L1 blocks to look back for finality verification. Uses default calculation if 0 (considers alt-DA challenge/resolve windows if applicable).",
@@ -343,6 +413,19 @@ var (
 		EnvVars:  prefixEnvVars("SAFEDB_PATH"),
 		Category: OperationsCategory,
 	}
+	JournalPath = &cli.StringFlag{
+		Name:     "journal.path",
+		Usage:    "File path to record the event journal to, for replay with 'op-node journal replay'. Compressed with gzip if the path ends with .gz. Disabled if not set.",
+		EnvVars:  prefixEnvVars("JOURNAL_PATH"),
+		Category: OperationsCategory,
+	}
+	SafeDBRetention = &cli.Uint64Flag{
+		Name:     "safedb.retention",
+		Usage:    "Number of L1 blocks of safe head history to keep in the safe head database. Older entries are pruned. 0 keeps all history.",
+		EnvVars:  prefixEnvVars("SAFEDB_RETENTION"),
+		Value:    0,
+		Category: OperationsCategory,
+	}
 	/* Deprecated Flags */
 	L2EngineSyncEnabled = &cli.BoolFlag{
 		Name:    "l2.engine-sync",
@@ -482,6 +565,16 @@ var optionalFlags = []cli.Flag{
 	SequencerMaxSafeLagFlag,
 	SequencerL1Confs,
 	SequencerRecoverMode,
+	SequencerForcedTxsFlag,
+	SequencerDenyListFlag,
+	SequencerSenderRateLimitFlag,
+	SequencerSenderBurstFlag,
+	SequencerBundlesMaxFlag,
+	SequencerBundlesRPCAddrFlag,
+	SequencerBundlesRPCPortFlag,
+	SequencerBundlesJWTSecretFlag,
+	SequencerPreconfEnabledFlag,
+	PreconfMonitorFlag,
 	FinalityLookbackFlag,
 	FinalityDelayFlag,
 	L1EpochPollIntervalFlag,
@@ -497,6 +590,8 @@ var optionalFlags = []cli.Flag{
 	ConductorRpcFlag,
 	ConductorRpcTimeoutFlag,
 	SafeDBPath,
+	SafeDBRetention,
+	JournalPath,
 	L1ChainConfig,
 	L2EngineKind,
 	L2EngineRpcTimeout,
@@ -533,6 +628,7 @@ func init() {
 	optionalFlags = append(optionalFlags, oplog.CLIFlagsWithCategory(EnvVarPrefix, OperationsCategory)...)
 	optionalFlags = append(optionalFlags, oppprof.CLIFlagsWithCategory(EnvVarPrefix, OperationsCategory)...)
 	optionalFlags = append(optionalFlags, opmetrics.CLIFlagsWithCategory(EnvVarPrefix, OperationsCategory)...)
+	optionalFlags = append(optionalFlags, tracing.CLIFlagsWithCategory(EnvVarPrefix, OperationsCategory)...)
 	optionalFlags = append(optionalFlags, oprpc.CLIFlagsWithCategory(EnvVarPrefix, OperationsCategory)...)
 	optionalFlags = append(optionalFlags, DeprecatedFlags...)
 	optionalFlags = append(optionalFlags, opflags.CLIFlags(EnvVarPrefix, RollupCategory)...)
@@ -142,3 +142,17 @@ func TestEnvVarFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestL1RPCUsageNamesSeparator(t *testing.T) {
+	require.Contains(t, L1NodeAddr.Usage, "separated by '|'")
+}
+
+func TestRangeSyncDisabledByDefault(t *testing.T) {
+	for _, flag := range P2PFlags(EnvVarPrefix) {
+		if flag.Names()[0] == SyncReqRespRangeName {
+			require.False(t, flag.(*cli.BoolFlag).Value, "range sync is experimental, and must be opted in to")
+			return
+		}
+	}
+	t.Fatalf("missing %s flag", SyncReqRespRangeName)
+}
@@ -56,6 +56,7 @@ var (
 	GossipTimestampThresholdName = "p2p.gossip.timestamp.threshold"
 	SyncReqRespName              = "p2p.sync.req-resp"
 	SyncOnlyReqToStaticName      = "p2p.sync.onlyreqtostatic"
+	SyncReqRespRangeName         = "p2p.sync.req-resp.range"
 	P2PPingName                  = "p2p.ping"
 )
 
@@ -411,6 +412,14 @@ func P2PFlags(envPrefix string) []cli.Flag {
 			EnvVars:  p2pEnv(envPrefix, "SYNC_ONLYREQTOSTATIC"),
 			Category: P2PCategory,
 		},
+		&cli.BoolFlag{
+			Name:     SyncReqRespRangeName,
+			Usage:    "Experimental: fetch P2P req-resp sync payloads in batches from multiple peers in parallel. Peers that do not serve batches are requested one payload at a time. Batches are served to peers whenever req-resp sync is enabled.",
+			Value:    false,
+			Required: false,
+			EnvVars:  p2pEnv(envPrefix, "SYNC_REQ_RESP_RANGE"),
+			Category: P2PCategory,
+		},
 		&cli.BoolFlag{
 			Name:     P2PPingName,
 			Usage:    "Enables P2P ping-pong background service",
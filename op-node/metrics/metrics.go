@@ -49,6 +49,8 @@ type Metricer interface {
 	RecordL1ReorgDepth(d uint64)
 	RecordSequencerInconsistentL1Origin(from eth.BlockID, to eth.BlockID)
 	RecordSequencerReset()
+	RecordInclusionDecision(policy string, decision string)
+	RecordPreconfirmation(status string)
 	RecordGossipEvent(evType int32)
 	IncPeerCount()
 	DecPeerCount()
@@ -66,6 +68,8 @@ type Metricer interface {
 	SetPeerScores(allScores []store.PeerScores)
 	ClientPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration)
 	ServerPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration)
+	ClientPayloadsByRangeEvent(start uint64, count int, resultCode byte, duration time.Duration)
+	ServerPayloadsByRangeEvent(start uint64, count int, resultCode byte, duration time.Duration)
 	PayloadsQuarantineSize(n int)
 	RecordPeerUnban()
 	RecordIPUnban()
@@ -102,6 +106,7 @@ type Metrics struct {
 	P2PReqDurationSeconds *prometheus.HistogramVec
 	P2PReqTotal           *prometheus.CounterVec
 	P2PPayloadByNumber    *prometheus.GaugeVec
+	P2PPayloadsByRange    *prometheus.CounterVec
 
 	PayloadsQuarantineTotal prometheus.Gauge
 
@@ -125,6 +130,10 @@ type Metrics struct {
 
 	TransactionsSequencedTotal *prometheus.CounterVec
 
+	InclusionDecisionsTotal *prometheus.CounterVec
+
+	PreconfirmationsTotal *prometheus.CounterVec
+
 	AltDAMetrics altda.Metricer
 
 	// Channel Bank Metrics
@@ -238,6 +247,16 @@ func NewMetrics(procName string) *Metrics {
 			Name:      "transactions_sequenced_total",
 			Help:      "Count of total transactions sequenced",
 		}, []string{"type"}),
+		InclusionDecisionsTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "inclusion_decisions_total",
+			Help:      "Count of sequencer inclusion-policy decisions on forced transactions and bundles",
+		}, []string{"policy", "decision"}),
+		PreconfirmationsTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "preconfirmations_total",
+			Help:      "Count of sequencer preconfirmations, by status: issued, failed, kept or broken",
+		}, []string{"status"}),
 		PeerCount: factory.NewGauge(prometheus.GaugeOpts{
 			Namespace: ns,
 			Subsystem: "p2p",
@@ -338,6 +357,14 @@ func NewMetrics(procName string) *Metrics {
 		}, []string{
 			"p2p_role", // "client" or "server"
 		}),
+		P2PPayloadsByRange: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Subsystem: "p2p",
+			Name:      "payloads_by_range_total",
+			Help:      "Number of payloads transferred through payloads by range requests",
+		}, []string{
+			"p2p_role", // "client" or "server"
+		}),
 		PayloadsQuarantineTotal: factory.NewGauge(prometheus.GaugeOpts{
 			Namespace: ns,
 			Subsystem: "p2p",
@@ -498,6 +525,14 @@ func (m *Metrics) RecordSequencerReset() {
 	m.SequencerResets.Record()
 }
 
+func (m *Metrics) RecordInclusionDecision(policy string, decision string) {
+	m.InclusionDecisionsTotal.WithLabelValues(policy, decision).Inc()
+}
+
+func (m *Metrics) RecordPreconfirmation(status string) {
+	m.PreconfirmationsTotal.WithLabelValues(status).Inc()
+}
+
 func (m *Metrics) RecordGossipEvent(evType int32) {
 	m.GossipEventsTotal.WithLabelValues(pb.TraceEvent_Type_name[evType]).Inc()
 }
@@ -591,6 +626,23 @@ func (m *Metrics) ServerPayloadByNumberEvent(num uint64, resultCode byte, durati
 	m.P2PPayloadByNumber.WithLabelValues("server").Set(float64(num))
 }
 
+func (m *Metrics) ClientPayloadsByRangeEvent(start uint64, count int, resultCode byte, duration time.Duration) {
+	if resultCode > 4 { // summarize all high codes to reduce metrics overhead
+		resultCode = 5
+	}
+	code := strconv.FormatUint(uint64(resultCode), 10)
+	m.P2PReqTotal.WithLabelValues("client", "payloads_by_range", code).Inc()
+	m.P2PReqDurationSeconds.WithLabelValues("client", "payloads_by_range", code).Observe(float64(duration) / float64(time.Second))
+	m.P2PPayloadsByRange.WithLabelValues("client").Add(float64(count))
+}
+
+func (m *Metrics) ServerPayloadsByRangeEvent(start uint64, count int, resultCode byte, duration time.Duration) {
+	code := strconv.FormatUint(uint64(resultCode), 10)
+	m.P2PReqTotal.WithLabelValues("server", "payloads_by_range", code).Inc()
+	m.P2PReqDurationSeconds.WithLabelValues("server", "payloads_by_range", code).Observe(float64(duration) / float64(time.Second))
+	m.P2PPayloadsByRange.WithLabelValues("server").Add(float64(count))
+}
+
 func (m *Metrics) PayloadsQuarantineSize(n int) {
 	m.PayloadsQuarantineTotal.Set(float64(n))
 }
@@ -703,6 +755,12 @@ func (n *noopMetricer) RecordSequencerInconsistentL1Origin(from eth.BlockID, to
 func (n *noopMetricer) RecordSequencerReset() {
 }
 
+func (n *noopMetricer) RecordInclusionDecision(policy string, decision string) {
+}
+
+func (n *noopMetricer) RecordPreconfirmation(status string) {
+}
+
 func (n *noopMetricer) RecordGossipEvent(evType int32) {
 }
 
@@ -740,6 +798,12 @@ func (n *noopMetricer) ClientPayloadByNumberEvent(num uint64, resultCode byte, d
 func (n *noopMetricer) ServerPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration) {
 }
 
+func (n *noopMetricer) ClientPayloadsByRangeEvent(start uint64, count int, resultCode byte, duration time.Duration) {
+}
+
+func (n *noopMetricer) ServerPayloadsByRangeEvent(start uint64, count int, resultCode byte, duration time.Duration) {
+}
+
 func (n *noopMetricer) PayloadsQuarantineSize(int) {
 }
 
@@ -45,8 +45,12 @@ type driverClient interface {
 
 type SafeDBReader interface {
 	SafeHeadAtL1(ctx context.Context, l1BlockNum uint64) (l1 eth.BlockID, l2 eth.BlockID, err error)
+	SafeHeadsInRange(ctx context.Context, start uint64, end uint64, limit int) ([]eth.SafeHeadResponse, error)
 }
 
+// maxSafeHeadsPerRequest limits the number of entries returned by a single optimism_safeHeadsInRange call.
+const maxSafeHeadsPerRequest = 1000
+
 type adminAPI struct {
 	*rpc.CommonAdminAPI
 	dr driverClient
@@ -160,6 +164,23 @@ func (n *nodeAPI) SafeHeadAtL1Block(ctx context.Context, number hexutil.Uint64)
 	}, nil
 }
 
+// SafeHeadsInRange returns the safe head updates recorded at L1 blocks in the inclusive range [start, end],
+// up to maxSafeHeadsPerRequest entries. Callers page through larger ranges by continuing from the L1 block
+// after the last returned entry.
+func (n *nodeAPI) SafeHeadsInRange(ctx context.Context, start hexutil.Uint64, end hexutil.Uint64) ([]eth.SafeHeadResponse, error) {
+	if end < start {
+		return nil, fmt.Errorf("invalid range: end %d is before start %d", end, start)
+	}
+	entries, err := n.safeDB.SafeHeadsInRange(ctx, uint64(start), uint64(end), maxSafeHeadsPerRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get safe heads in l1 block range %d-%d: %w", start, end, err)
+	}
+	if entries == nil {
+		entries = []eth.SafeHeadResponse{}
+	}
+	return entries, nil
+}
+
 func (n *nodeAPI) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
 	return n.dr.SyncStatus(ctx)
 }
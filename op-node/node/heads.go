@@ -0,0 +1,76 @@
+package node
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/status"
+)
+
+// headsAPI serves subscriptions to L2 head changes in the "optimism" namespace, over websocket:
+// optimism_subscribe("unsafeHead"), and likewise "safeHead", "crossSafeHead", "finalizedHead" and "reorg".
+type headsAPI struct {
+	heads *status.HeadNotifier
+	log   log.Logger
+}
+
+func NewHeadsAPI(heads *status.HeadNotifier, log log.Logger) *headsAPI {
+	return &headsAPI{heads: heads, log: log}
+}
+
+// UnsafeHead notifies of new unsafe heads.
+func (api *headsAPI) UnsafeHead(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, status.HeadUnsafe)
+}
+
+// SafeHead notifies of new local-safe heads, with the L1 block they were derived from.
+func (api *headsAPI) SafeHead(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, status.HeadSafe)
+}
+
+// CrossSafeHead notifies of new cross-safe heads, with the L1 block they were derived from.
+func (api *headsAPI) CrossSafeHead(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, status.HeadCrossSafe)
+}
+
+// FinalizedHead notifies of new finalized heads, with the L1 block they were derived from, if known.
+func (api *headsAPI) FinalizedHead(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, status.HeadFinalized)
+}
+
+// Reorg notifies of unsafe heads that replace the previous unsafe head.
+func (api *headsAPI) Reorg(ctx context.Context) (*rpc.Subscription, error) {
+	return api.subscribe(ctx, status.HeadReorg)
+}
+
+func (api *headsAPI) subscribe(ctx context.Context, kind status.HeadKind) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	sub := api.heads.Subscribe()
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-sub.Events():
+				if ev.Kind != kind {
+					continue
+				}
+				if err := notifier.Notify(rpcSub.ID, ev); err != nil {
+					api.log.Debug("Failed to notify head subscriber", "kind", kind, "err", err)
+					return
+				}
+			case <-sub.Err():
+				api.log.Warn("Head subscriber fell behind, no longer notifying", "kind", kind, "id", rpcSub.ID)
+				return
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,67 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/engine"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/status"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+func TestHeadsSubscription(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelError)
+	heads := status.NewHeadNotifier(logger)
+	server := oprpc.NewServer("127.0.0.1", 0, "test", oprpc.WithLogger(logger), oprpc.WithWebsocketEnabled())
+	server.AddAPI(rpc.API{
+		Namespace: "optimism",
+		Service:   NewHeadsAPI(heads, logger),
+	})
+	require.NoError(t, server.Start())
+	defer func() {
+		_ = server.Stop()
+	}()
+
+	client, err := rpc.Dial(fmt.Sprintf("ws://%s", server.Endpoint()))
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	finalized := make(chan status.HeadEvent, 10)
+	sub, err := client.Subscribe(ctx, "optimism", finalized, "finalizedHead")
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	l1 := eth.L1BlockRef{Number: 100, Hash: common.Hash{0x10}}
+	l2 := eth.L2BlockRef{Number: 7, Hash: common.Hash{0x07}}
+	heads.OnEvent(ctx, engine.UnsafeUpdateEvent{Ref: l2})
+	heads.OnEvent(ctx, engine.SafeDerivedEvent{Safe: l2, Source: l1})
+	heads.OnEvent(ctx, engine.FinalizedUpdateEvent{Ref: l2})
+
+	select {
+	case ev := <-finalized:
+		require.Equal(t, status.HeadEvent{Kind: status.HeadFinalized, Head: l2, Source: l1}, ev)
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-ctx.Done():
+		t.Fatal("expected finalized head notification")
+	}
+
+	// subscriptions are not supported over HTTP
+	httpClient, err := rpc.Dial(fmt.Sprintf("http://%s", server.Endpoint()))
+	require.NoError(t, err)
+	defer httpClient.Close()
+	_, err = httpClient.Subscribe(ctx, "optimism", finalized, "finalizedHead")
+	require.ErrorIs(t, err, rpc.ErrNotificationsUnsupported)
+}
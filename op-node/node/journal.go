@@ -0,0 +1,52 @@
+package node
+
+import (
+	"context"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/config"
+	"github.com/tokamak-network/tokamak-thanos/op-node/node/replay"
+	"github.com/tokamak-network/tokamak-thanos/op-node/p2p"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+)
+
+// initJournal opens the event journal, if enabled.
+// The journal header is completed by the client setup, and written once the clients are set up.
+func initJournal(cfg *config.Config, node *OpNode) (*journal.Writer, error) {
+	if cfg.JournalPath == "" {
+		return nil, nil
+	}
+	w, err := journal.Create(cfg.JournalPath)
+	if err != nil {
+		return nil, err
+	}
+	node.journalHeader = &replay.Header{
+		Rollup:        &cfg.Rollup,
+		L1ChainConfig: cfg.L1ChainConfig,
+		Driver:        cfg.Driver,
+		Sync:          cfg.Sync,
+	}
+	node.log.Warn("Recording event journal, for debugging only: the journal grows quickly", "path", cfg.JournalPath)
+	return w, nil
+}
+
+// recordInput records an input to the journal, if enabled,
+// and returns the context to attribute the events emitted by the input to it.
+func (n *OpNode) recordInput(ctx context.Context, name string, input any) context.Context {
+	if n.journal == nil {
+		return ctx
+	}
+	return n.journal.WriteInput(ctx, name, input)
+}
+
+// journalSyncDeriver records the unsafe payloads received via p2p to the journal.
+type journalSyncDeriver struct {
+	w           *journal.Writer
+	syncDeriver p2p.SyncDeriver
+}
+
+var _ p2p.SyncDeriver = (*journalSyncDeriver)(nil)
+
+func (j *journalSyncDeriver) OnUnsafeL2Payload(ctx context.Context, envelope *eth.ExecutionPayloadEnvelope) {
+	j.syncDeriver.OnUnsafeL2Payload(j.w.WriteInput(ctx, replay.InputUnsafePayload, envelope), envelope)
+}
@@ -20,9 +20,12 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/prometheus/client_golang/prometheus"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
 	altda "github.com/tokamak-network/tokamak-thanos/op-alt-da"
 	"github.com/tokamak-network/tokamak-thanos/op-node/config"
 	"github.com/tokamak-network/tokamak-thanos/op-node/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-node/node/replay"
 	"github.com/tokamak-network/tokamak-thanos/op-node/node/runcfg"
 	"github.com/tokamak-network/tokamak-thanos/op-node/node/safedb"
 	"github.com/tokamak-network/tokamak-thanos/op-node/node/tracer"
@@ -33,17 +36,22 @@ import (
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/interop"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/interop/indexing"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing/inclusion"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing/preconf"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/status"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sync"
 	"github.com/tokamak-network/tokamak-thanos/op-service/client"
 	"github.com/tokamak-network/tokamak-thanos/op-service/clock"
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	"github.com/tokamak-network/tokamak-thanos/op-service/event"
 	"github.com/tokamak-network/tokamak-thanos/op-service/httputil"
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
 	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
 	"github.com/tokamak-network/tokamak-thanos/op-service/retry"
 	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
 	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
 	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 )
 
 var ErrAlreadyClosed = errors.New("node is already closed")
@@ -116,6 +124,8 @@ type OpNode struct {
 	eventDrain driver.Drain
 
 	l1Source  L1Source              // L1 Client to fetch data from
+	l1RPC     client.RPC            // RPC of the L1 source, nil if the L1 source is overridden
+	l2RPC     client.RPC            // RPC of the L2 source
 	l2Driver  *driver.Driver        // L2 Engine to Sync
 	l2Source  *sources.EngineClient // L2 Execution Engine RPC bindings
 	server    *oprpc.Server         // RPC server hosting the rollup-node API
@@ -133,10 +143,22 @@ type OpNode struct {
 	pprofService *oppprof.Service
 	metricsSrv   *httputil.HTTPServer
 
+	tracerProvider *sdktrace.TracerProvider // nil if tracing is disabled
+
+	journal       *journal.Writer // nil if the event journal is disabled
+	journalHeader *replay.Header  // completed while the clients are set up
+
 	beacon L1Beacon
 
 	interopSys interop.SubSystem
 
+	bundleServer *oprpc.Server // nil if the bundle RPC is disabled
+
+	preconfIssuer  *preconf.Issuer  // nil if preconfirmations are not issued
+	preconfMonitor *preconf.Monitor // nil if preconfirmations are not monitored
+
+	heads *status.HeadNotifier // notifies RPC subscribers of L2 head changes
+
 	// some resources cannot be stopped directly, like the p2p gossipsub router (not our design),
 	// and depend on this ctx to be closed.
 	resourcesCtx   context.Context
@@ -220,6 +242,17 @@ func (n *OpNode) init(ctx context.Context, cfg *config.Config, overrides Initial
 	}
 	n.log.Info("Safety levels", "unsafe", "enabled", "safe", safe)
 
+	// tracing is set up before the event system, to trace its events
+	n.tracerProvider, err = initTracing(ctx, cfg, n)
+	if err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+
+	n.journal, err = initJournal(cfg, n)
+	if err != nil {
+		return fmt.Errorf("failed to init event journal: %w", err)
+	}
+
 	n.eventSys, n.eventDrain, err = initEventSystem(n)
 	if err != nil {
 		return fmt.Errorf("failed to init event system: %w", err)
@@ -251,6 +284,12 @@ func (n *OpNode) init(ctx context.Context, cfg *config.Config, overrides Initial
 		return fmt.Errorf("failed to init L2: %w", err)
 	}
 
+	if n.journal != nil {
+		if err := replay.WriteHeader(n.journal, n.journalHeader); err != nil {
+			return err
+		}
+	}
+
 	n.l1HeadsSub, n.l1SafeSub, n.l1FinalizedSub, err = initL1Handlers(cfg, n)
 	if err != nil {
 		return fmt.Errorf("failed to init L1 Source: %w", err)
@@ -317,6 +356,12 @@ func initEventSystem(node *OpNode) (event.System, driver.Drain, error) {
 	executor := event.NewGlobalSynchronous(node.resourcesCtx).WithMetrics(node.metrics)
 	sys := event.NewSystem(node.log, executor)
 	sys.AddTracer(event.NewMetricsTracer(node.metrics))
+	if node.tracerProvider != nil {
+		sys.AddTracer(event.NewOTelTracer(node.tracerProvider.Tracer("github.com/tokamak-network/tokamak-thanos/op-service/event")))
+	}
+	if node.journal != nil {
+		sys.AddTracer(event.NewJournalTracer(node.journal))
+	}
 	sys.Register("node", event.DeriverFunc(node.onEvent))
 	return sys, executor, nil
 }
@@ -328,11 +373,16 @@ func initL1Source(ctx context.Context, cfg *config.Config, node *OpNode) (*sourc
 	if err != nil {
 		return nil, fmt.Errorf("failed to get L1 RPC client: %w", err)
 	}
+	if node.journal != nil {
+		l1RPC = client.NewRecordingRPC(l1RPC, node.journal, replay.SourceL1)
+		node.journalHeader.L1 = l1Cfg
+	}
 
 	l1Source, err := sources.NewL1Client(l1RPC, node.log, node.metrics.L1SourceCache, l1Cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create L1 source: %w", err)
 	}
+	node.l1RPC = l1RPC
 
 	if err := cfg.Rollup.ValidateL1Config(ctx, node.log, l1Source); err != nil {
 		return nil, fmt.Errorf("failed to validate the L1 config: %w", err)
@@ -351,19 +401,15 @@ func initL1Handlers(cfg *config.Config, node *OpNode) (ethereum.Subscription, et
 		if node.cfg.Tracer != nil {
 			node.cfg.Tracer.OnNewL1Head(ctx, sig)
 		}
-		node.l2Driver.SyncDeriver.L1Tracker.OnL1Unsafe(sig)
-		node.l2Driver.StatusTracker.OnL1Unsafe(sig)
-		node.l2Driver.SyncDeriver.OnL1Unsafe(ctx)
+		node.l2Driver.OnL1Unsafe(node.recordInput(ctx, replay.InputL1Unsafe, sig), sig)
 	}
 	onL1Safe := func(ctx context.Context, sig eth.L1BlockRef) {
-		node.l2Driver.StatusTracker.OnL1Safe(sig)
+		node.l2Driver.OnL1Safe(node.recordInput(ctx, replay.InputL1Safe, sig), sig)
 	}
 	onL1Finalized := func(ctx context.Context, sig eth.L1BlockRef) {
 		// TODO(#16917) Remove Event System Refactor Comments
 		//  FinalizeL1Event fan out is updated to procedural method calls
-		node.l2Driver.StatusTracker.OnL1Finalized(sig)
-		node.l2Driver.Finalizer.OnL1Finalized(sig)
-		node.l2Driver.SyncDeriver.OnL1Finalized(ctx)
+		node.l2Driver.OnL1Finalized(node.recordInput(ctx, replay.InputL1Finalized, sig), sig)
 	}
 
 	// Keep subscribed to the L1 heads, which keeps the L1 maintainer pointing to the best headers to sync
@@ -511,6 +557,13 @@ func initL1BeaconAPI(ctx context.Context, cfg *config.Config, node *OpNode) (*so
 	for _, fb := range fallbacks {
 		fetchers = append(fetchers, fb)
 	}
+	if node.journal != nil {
+		beaconClient = replay.NewRecordingBeaconClient(beaconClient, node.journal)
+		for i, fb := range fetchers {
+			fetchers[i] = replay.NewRecordingBlobSideCarsFetcher(fb, node.journal)
+		}
+		node.journalHeader.Beacon = &beaconCfg
+	}
 	beacon := sources.NewL1BeaconClient(beaconClient, beaconCfg, fetchers...)
 
 	// Retry retrieval of the Beacon API version, to be more robust on startup against Beacon API connection issues.
@@ -559,11 +612,16 @@ func initL2(ctx context.Context, cfg *config.Config, node *OpNode) (*sources.Eng
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to setup L2 execution-engine RPC client: %w", err)
 	}
+	if node.journal != nil {
+		rpcClient = client.NewRecordingRPC(rpcClient, node.journal, replay.SourceL2)
+		node.journalHeader.L2 = rpcCfg
+	}
 
 	l2Source, err := sources.NewEngineClient(rpcClient, node.log, node.metrics.L2SourceCache, rpcCfg)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to create Engine client: %w", err)
 	}
+	node.l2RPC = rpcClient
 
 	if err := cfg.Rollup.ValidateL2Config(ctx, l2Source, cfg.Sync.SyncMode == sync.ELSync); err != nil {
 		return nil, nil, nil, nil, err
@@ -592,10 +650,12 @@ func initL2(ctx context.Context, cfg *config.Config, node *OpNode) (*sources.Eng
 	var safeDB closableSafeDB
 	if cfg.SafeDBPath != "" {
 		node.log.Info("Safe head database enabled", "path", cfg.SafeDBPath)
-		safeDB, err = safedb.NewSafeDB(node.log, cfg.SafeDBPath)
+		db, err := safedb.NewSafeDB(node.log, cfg.SafeDBPath)
 		if err != nil {
 			return nil, nil, nil, nil, fmt.Errorf("failed to create safe head database at %v: %w", cfg.SafeDBPath, err)
 		}
+		db.SetRetention(cfg.SafeDBRetention)
+		safeDB = db
 	} else {
 		safeDB = safedb.Disabled
 	}
@@ -609,8 +669,35 @@ func initL2(ctx context.Context, cfg *config.Config, node *OpNode) (*sources.Eng
 		upstreamFollowSource = driver.NewL2FollowSource(node.l2FollowSource, node.l1Source)
 	}
 
+	initPreconf(cfg, node, l2Source)
+
+	node.heads = status.NewHeadNotifier(node.log.New("module", "heads"))
+	node.eventSys.Register("heads", node.heads)
+
+	var inclusionPolicy sequencing.InclusionPolicy = sequencing.NoInclusionPolicy{}
+	if cfg.Driver.SequencerEnabled && cfg.Inclusion.Enabled() {
+		policy, err := inclusion.NewPolicy(node.log.New("module", "inclusion"), &cfg.Inclusion, cfg.Rollup.L2ChainID, node.metrics)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("failed to create inclusion policy: %w", err)
+		}
+		inclusionPolicy = policy
+		if cfg.Inclusion.RPCAddr != "" {
+			node.bundleServer, err = inclusion.NewServer(node.log, &cfg.Inclusion, policy)
+			if err != nil {
+				return nil, nil, nil, nil, fmt.Errorf("failed to create bundle RPC server: %w", err)
+			}
+			if node.preconfIssuer != nil {
+				node.bundleServer.AddAPI(rpc.API{
+					Namespace:     "preconf",
+					Service:       preconf.NewSequencerAPI(node.preconfIssuer, policy),
+					Authenticated: true,
+				})
+			}
+		}
+	}
+
 	l2Driver := driver.NewDriver(node.eventSys, node.eventDrain, &cfg.Driver, &cfg.Rollup, cfg.L1ChainConfig, cfg.DependencySet, l2Source, node.l1Source, upstreamFollowSource,
-		node.beacon, node, node, node.log, node.metrics, cfg.ConfigPersistence, safeDB, &cfg.Sync, sequencerConductor, altDA, indexingMode)
+		node.beacon, node, node, node.log, node.metrics, cfg.ConfigPersistence, safeDB, &cfg.Sync, sequencerConductor, inclusionPolicy, altDA, indexingMode)
 
 	// Wire up IndexingMode to engine controller for direct procedure call
 	if sys != nil {
@@ -695,6 +782,35 @@ func registerOptionalAPIs(cfg *config.Config, node *OpNode, addAPI func(rpc.API)
 		}
 		node.log.Info("Admin RPC enabled")
 	}
+	if node.heads != nil {
+		if err := addAPI(rpc.API{
+			Namespace: "optimism",
+			Service:   NewHeadsAPI(node.heads, node.log),
+		}); err != nil {
+			return fmt.Errorf("failed to add heads API: %w", err)
+		}
+	}
+	if node.l1RPC != nil && node.l2RPC != nil {
+		api, err := NewWithdrawalsAPI(&cfg.Rollup, node.l1RPC, node.l2Source, node.l2RPC, node.log)
+		if err != nil {
+			return fmt.Errorf("failed to create withdrawals API: %w", err)
+		}
+		if err := addAPI(rpc.API{
+			Namespace: "optimism",
+			Service:   api,
+		}); err != nil {
+			return fmt.Errorf("failed to add withdrawals API: %w", err)
+		}
+	}
+	if node.preconfIssuer != nil || node.preconfMonitor != nil {
+		if err := addAPI(rpc.API{
+			Namespace: "preconf",
+			Service:   preconf.NewAPI(node.preconfIssuer, node.preconfMonitor),
+		}); err != nil {
+			return fmt.Errorf("failed to add preconfirmation API: %w", err)
+		}
+		node.log.Info("Preconfirmation RPC enabled")
+	}
 	return nil
 }
 
@@ -712,6 +828,18 @@ func initMetricsServer(cfg *config.Config, node *OpNode) (*httputil.HTTPServer,
 	return metricsSrv, nil
 }
 
+func initTracing(ctx context.Context, cfg *config.Config, node *OpNode) (*sdktrace.TracerProvider, error) {
+	if !cfg.Tracing.Enabled {
+		return nil, nil
+	}
+	tp, err := tracing.Start(ctx, cfg.Tracing, "op-node", node.appVersion)
+	if err != nil {
+		return nil, err
+	}
+	node.log.Info("started tracing", "endpoint", cfg.Tracing.Endpoint, "sampleRatio", cfg.Tracing.SampleRatio)
+	return tp, nil
+}
+
 func initPProf(cfg *config.Config, node *OpNode) (*oppprof.Service, error) {
 	pprofService := oppprof.New(
 		cfg.Pprof.ListenEnabled,
@@ -744,8 +872,17 @@ func initP2P(cfg *config.Config, node *OpNode) (*p2p.NodeP2P, error) {
 			panic("SyncDeriver must be initialized")
 		}
 		// embed syncDeriver and tracer(optional) to the blockReceiver to handle unsafe payloads via p2p
-		rec := p2p.NewBlockReceiver(node.log, node.metrics, node.l2Driver.SyncDeriver, node.cfg.Tracer)
-		p2pNode, err := p2p.NewNodeP2P(node.resourcesCtx, &cfg.Rollup, node.log, cfg.P2P, rec, node.l2Source, node.runCfg, node.metrics, node.clock)
+		var syncDeriver p2p.SyncDeriver = node.l2Driver.SyncDeriver
+		if node.journal != nil {
+			syncDeriver = &journalSyncDeriver{w: node.journal, syncDeriver: syncDeriver}
+		}
+		rec := p2p.NewBlockReceiver(node.log, node.metrics, syncDeriver, node.cfg.Tracer)
+		var gossipIn p2p.GossipIn = rec
+		if node.preconfMonitor != nil {
+			// only subscribe to preconfirmations if there is a monitor to check them
+			gossipIn = &preconfGossipIn{BlockReceiver: rec, monitor: node.preconfMonitor}
+		}
+		p2pNode, err := p2p.NewNodeP2P(node.resourcesCtx, &cfg.Rollup, node.log, cfg.P2P, gossipIn, node.l2Source, node.runCfg, node.metrics, node.clock)
 		if err != nil {
 			return nil, err
 		}
@@ -774,6 +911,16 @@ func (n *OpNode) Start(ctx context.Context) error {
 			return err
 		}
 	}
+	if n.bundleServer != nil {
+		if err := n.bundleServer.Start(); err != nil {
+			n.log.Error("Could not start bundle RPC server", "err", err)
+			return err
+		}
+		n.log.Info("Started bundle RPC server", "endpoint", n.bundleServer.Endpoint())
+	}
+	if n.preconfMonitor != nil {
+		n.preconfMonitor.Start()
+	}
 	n.log.Info("Starting execution engine driver")
 	// start driving engine: sync blocks by deriving them from L1 and driving them into the engine
 	if err := n.l2Driver.Start(); err != nil {
@@ -914,7 +1061,25 @@ func (n *OpNode) Stop(ctx context.Context) error {
 		n.l1FinalizedSub.Unsubscribe()
 	}
 
+	if n.bundleServer != nil {
+		if err := n.bundleServer.Stop(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close bundle RPC server: %w", err))
+		}
+	}
+
+	if n.preconfIssuer != nil {
+		if err := n.preconfIssuer.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close preconfirmation issuer: %w", err))
+		}
+	}
+	if n.preconfMonitor != nil {
+		if err := n.preconfMonitor.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close preconfirmation monitor: %w", err))
+		}
+	}
+
 	// close L2 driver
+
 	if n.l2Driver != nil {
 		if err := n.l2Driver.Close(); err != nil {
 			result = multierror.Append(result, fmt.Errorf("failed to close L2 engine driver cleanly: %w", err))
@@ -938,6 +1103,12 @@ func (n *OpNode) Stop(ctx context.Context) error {
 		}
 	}
 
+	if n.journal != nil {
+		if err := n.journal.Close(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to close event journal: %w", err))
+		}
+	}
+
 	// Wait for the runtime config loader to be done using the data sources before closing them
 	if n.runtimeConfigReloaderDone != nil {
 		<-n.runtimeConfigReloaderDone
@@ -979,6 +1150,12 @@ func (n *OpNode) Stop(ctx context.Context) error {
 			result = multierror.Append(result, fmt.Errorf("failed to close metrics server: %w", err))
 		}
 	}
+	// flush the remaining spans last, after all traced components stopped
+	if n.tracerProvider != nil {
+		if err := n.tracerProvider.Shutdown(ctx); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to shut down tracing: %w", err))
+		}
+	}
 
 	return result.ErrorOrNil()
 }
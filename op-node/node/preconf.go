@@ -0,0 +1,61 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/config"
+	"github.com/tokamak-network/tokamak-thanos/op-node/p2p"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing/preconf"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
+)
+
+// initPreconf sets up the preconfirmation monitor and issuer, if enabled, and registers them to the event system.
+func initPreconf(cfg *config.Config, node *OpNode, l2Source preconf.L2Source) {
+	if cfg.Preconf.Monitor {
+		node.preconfMonitor = preconf.NewMonitor(node.log.New("module", "preconf-monitor"), node.metrics, l2Source)
+		node.eventSys.Register("preconf-monitor", node.preconfMonitor)
+	}
+	if cfg.Driver.SequencerEnabled && cfg.Preconf.Enabled {
+		node.preconfIssuer = preconf.NewIssuer(node.log.New("module", "preconf"), node.metrics, node, node.preconfMonitor)
+		node.eventSys.Register("preconf-issuer", node.preconfIssuer)
+	}
+}
+
+func (n *OpNode) SignAndPublishPreconfirmation(ctx context.Context, p opsigner.Preconfirmation) (*opsigner.SignedPreconfirmation, error) {
+	if n.p2pSigner == nil {
+		return nil, errors.New("node has no p2p signer, preconfirmations cannot be signed")
+	}
+	preconfSigner, ok := n.p2pSigner.(opsigner.PreconfirmationSigner)
+	if !ok {
+		return nil, errors.New("p2p signer does not support signing preconfirmations")
+	}
+	signed, err := opsigner.SignPreconfirmation(ctx, preconfSigner, eth.ChainIDFromBig(n.cfg.Rollup.L2ChainID), p)
+	if err != nil {
+		return nil, err
+	}
+	// publish to p2p, if we are running p2p at all
+	if p2pNode := n.getP2PNodeIfEnabled(); p2pNode != nil {
+		if err := p2pNode.GossipOut().PublishPreconfirmation(ctx, signed); err != nil {
+			return signed, fmt.Errorf("failed to publish %s: %w", &p, err)
+		}
+	}
+	return signed, nil
+}
+
+// preconfGossipIn passes the preconfirmations received via p2p to the monitor.
+type preconfGossipIn struct {
+	*p2p.BlockReceiver
+	monitor *preconf.Monitor
+}
+
+var _ p2p.PreconfirmationsIn = (*preconfGossipIn)(nil)
+
+func (g *preconfGossipIn) OnPreconfirmation(ctx context.Context, from peer.ID, msg *opsigner.SignedPreconfirmation) error {
+	g.monitor.Add(msg)
+	return nil
+}
@@ -0,0 +1,111 @@
+package replay
+
+import (
+	"context"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
+)
+
+// RecordingBeaconClient records the responses of a beacon API client to a journal.
+type RecordingBeaconClient struct {
+	cl sources.BeaconClient
+	w  *journal.Writer
+}
+
+var _ sources.BeaconClient = (*RecordingBeaconClient)(nil)
+
+func NewRecordingBeaconClient(cl sources.BeaconClient, w *journal.Writer) *RecordingBeaconClient {
+	return &RecordingBeaconClient{cl: cl, w: w}
+}
+
+func (r *RecordingBeaconClient) NodeVersion(ctx context.Context) (string, error) {
+	res, err := r.cl.NodeVersion(ctx)
+	r.w.WriteCall(SourceBeacon, "NodeVersion", []any{}, res, err)
+	return res, err
+}
+
+func (r *RecordingBeaconClient) ConfigSpec(ctx context.Context) (eth.APIConfigResponse, error) {
+	res, err := r.cl.ConfigSpec(ctx)
+	r.w.WriteCall(SourceBeacon, "ConfigSpec", []any{}, res, err)
+	return res, err
+}
+
+func (r *RecordingBeaconClient) BeaconGenesis(ctx context.Context) (eth.APIGenesisResponse, error) {
+	res, err := r.cl.BeaconGenesis(ctx)
+	r.w.WriteCall(SourceBeacon, "BeaconGenesis", []any{}, res, err)
+	return res, err
+}
+
+func (r *RecordingBeaconClient) BeaconBlobs(ctx context.Context, slot uint64, hashes []eth.IndexedBlobHash) (eth.APIBeaconBlobsResponse, error) {
+	res, err := r.cl.BeaconBlobs(ctx, slot, hashes)
+	r.w.WriteCall(SourceBeacon, "BeaconBlobs", []any{slot, hashes}, res, err)
+	return res, err
+}
+
+func (r *RecordingBeaconClient) BeaconBlobSideCars(ctx context.Context, fetchAllSidecars bool, slot uint64, hashes []eth.IndexedBlobHash) (eth.APIGetBlobSidecarsResponse, error) {
+	res, err := r.cl.BeaconBlobSideCars(ctx, fetchAllSidecars, slot, hashes)
+	r.w.WriteCall(SourceBeacon, "BeaconBlobSideCars", []any{fetchAllSidecars, slot, hashes}, res, err)
+	return res, err
+}
+
+// RecordingBlobSideCarsFetcher records the responses of a fallback blob sidecars fetcher to a journal.
+// The responses are recorded as responses of the beacon API, which serves them in a replay.
+type RecordingBlobSideCarsFetcher struct {
+	f sources.BlobSideCarsFetcher
+	w *journal.Writer
+}
+
+var _ sources.BlobSideCarsFetcher = (*RecordingBlobSideCarsFetcher)(nil)
+
+func NewRecordingBlobSideCarsFetcher(f sources.BlobSideCarsFetcher, w *journal.Writer) *RecordingBlobSideCarsFetcher {
+	return &RecordingBlobSideCarsFetcher{f: f, w: w}
+}
+
+func (r *RecordingBlobSideCarsFetcher) BeaconBlobSideCars(ctx context.Context, fetchAllSidecars bool, slot uint64, hashes []eth.IndexedBlobHash) (eth.APIGetBlobSidecarsResponse, error) {
+	res, err := r.f.BeaconBlobSideCars(ctx, fetchAllSidecars, slot, hashes)
+	r.w.WriteCall(SourceBeacon, "BeaconBlobSideCars", []any{fetchAllSidecars, slot, hashes}, res, err)
+	return res, err
+}
+
+// ReplayBeaconClient serves the responses recorded by a RecordingBeaconClient.
+type ReplayBeaconClient struct {
+	responses *journal.Responses
+}
+
+var _ sources.BeaconClient = (*ReplayBeaconClient)(nil)
+
+func NewReplayBeaconClient(responses *journal.Responses) *ReplayBeaconClient {
+	return &ReplayBeaconClient{responses: responses}
+}
+
+func (r *ReplayBeaconClient) NodeVersion(ctx context.Context) (string, error) {
+	var res string
+	err := r.responses.Call(SourceBeacon, "NodeVersion", []any{}, &res)
+	return res, err
+}
+
+func (r *ReplayBeaconClient) ConfigSpec(ctx context.Context) (eth.APIConfigResponse, error) {
+	var res eth.APIConfigResponse
+	err := r.responses.Call(SourceBeacon, "ConfigSpec", []any{}, &res)
+	return res, err
+}
+
+func (r *ReplayBeaconClient) BeaconGenesis(ctx context.Context) (eth.APIGenesisResponse, error) {
+	var res eth.APIGenesisResponse
+	err := r.responses.Call(SourceBeacon, "BeaconGenesis", []any{}, &res)
+	return res, err
+}
+
+func (r *ReplayBeaconClient) BeaconBlobs(ctx context.Context, slot uint64, hashes []eth.IndexedBlobHash) (eth.APIBeaconBlobsResponse, error) {
+	var res eth.APIBeaconBlobsResponse
+	err := r.responses.Call(SourceBeacon, "BeaconBlobs", []any{slot, hashes}, &res)
+	return res, err
+}
+
+func (r *ReplayBeaconClient) BeaconBlobSideCars(ctx context.Context, fetchAllSidecars bool, slot uint64, hashes []eth.IndexedBlobHash) (eth.APIGetBlobSidecarsResponse, error) {
+	var res eth.APIGetBlobSidecarsResponse
+	err := r.responses.Call(SourceBeacon, "BeaconBlobSideCars", []any{fetchAllSidecars, slot, hashes}, &res)
+	return res, err
+}
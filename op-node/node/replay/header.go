@@ -0,0 +1,74 @@
+// Package replay replays an op-node journal: the recorded inputs and events are fed back into
+// the derivers of the driver, with the L1, engine and beacon clients served from the recorded responses.
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/driver"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sync"
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
+)
+
+// Sources of the recorded calls
+const (
+	SourceL1     = "l1"
+	SourceL2     = "l2"
+	SourceBeacon = "beacon"
+)
+
+// Names of the recorded inputs
+const (
+	InputL1Unsafe      = "l1-unsafe"
+	InputL1Safe        = "l1-safe"
+	InputL1Finalized   = "l1-finalized"
+	InputUnsafePayload = "unsafe-payload"
+)
+
+// Header is the configuration of the recording op-node, needed to replay its journal.
+type Header struct {
+	Rollup        *rollup.Config      `json:"rollup"`
+	L1ChainConfig *params.ChainConfig `json:"l1ChainConfig"`
+	Driver        driver.Config       `json:"driver"`
+	Sync          sync.Config         `json:"sync"`
+
+	L1 *sources.L1ClientConfig     `json:"l1"`
+	L2 *sources.EngineClientConfig `json:"l2"`
+	// Beacon is nil if the node does not use a beacon API
+	Beacon *sources.L1BeaconClientConfig `json:"beacon,omitempty"`
+}
+
+// WriteHeader writes the header to the journal.
+func WriteHeader(w *journal.Writer, h *Header) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal header: %w", err)
+	}
+	w.Write(journal.Entry{Kind: journal.KindHeader, Data: data})
+	return nil
+}
+
+// ReadHeader decodes the header of a journal.
+// The header is written once the clients are set up, so calls made during the setup may precede it.
+func ReadHeader(entries []journal.Entry) (*Header, error) {
+	for _, e := range entries {
+		if e.Kind != journal.KindHeader {
+			continue
+		}
+		var h Header
+		if err := json.Unmarshal(e.Data, &h); err != nil {
+			return nil, fmt.Errorf("failed to decode journal header: %w", err)
+		}
+		if h.Rollup == nil || h.L1 == nil || h.L2 == nil {
+			return nil, errors.New("journal header is incomplete, the L1 or L2 responses were not recorded")
+		}
+		return &h, nil
+	}
+	return nil, errors.New("journal has no header")
+}
@@ -0,0 +1,363 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	gosync "sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/config"
+	"github.com/tokamak-network/tokamak-thanos/op-node/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-node/node/safedb"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/conductor"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/driver"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing"
+	"github.com/tokamak-network/tokamak-thanos/op-service/client"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/event"
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
+)
+
+// ErrUnsupportedEvent is returned when the journal contains an event emitted outside of deriver processing
+// that the replay cannot emit again.
+var ErrUnsupportedEvent = errors.New("event cannot be replayed")
+
+// rootEvents decodes the events that are emitted outside of deriver processing, e.g. by the driver event loop.
+// All other events are emitted again by the derivers during the replay.
+var rootEvents = map[string]func(data json.RawMessage) (event.Event, error){
+	driver.StepEvent{}.String():                decodeEvent[driver.StepEvent],
+	sequencing.SequencerActionEvent{}.String(): decodeEvent[sequencing.SequencerActionEvent],
+	derive.DeriverL1StatusEvent{}.String():     decodeEvent[derive.DeriverL1StatusEvent],
+}
+
+func decodeEvent[E event.Event](data json.RawMessage) (event.Event, error) {
+	var ev E
+	if len(data) == 0 {
+		return ev, nil
+	}
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// Target receives the recorded inputs. It is implemented by the driver.
+type Target interface {
+	OnL1Unsafe(ctx context.Context, sig eth.L1BlockRef)
+	OnL1Safe(ctx context.Context, sig eth.L1BlockRef)
+	OnL1Finalized(ctx context.Context, sig eth.L1BlockRef)
+	OnUnsafeL2Payload(ctx context.Context, payload *eth.ExecutionPayloadEnvelope)
+}
+
+// Divergence is a difference between the recorded events and the replayed events.
+type Divergence struct {
+	// Seq is the journal entry of the recorded emission that differs,
+	// or the last replayed entry if the replay emitted more events than recorded.
+	Seq uint64
+	// Expected is the recorded event name, empty if the replay emitted more events than recorded.
+	Expected string
+	// Got is the replayed event name, empty if the replay emitted fewer events than recorded.
+	Got string
+}
+
+func (d *Divergence) Error() string {
+	switch {
+	case d.Expected == "":
+		return fmt.Sprintf("replay diverged after entry %d: unexpected event %q", d.Seq, d.Got)
+	case d.Got == "":
+		return fmt.Sprintf("replay diverged at entry %d: expected event %q, but it was not emitted", d.Seq, d.Expected)
+	default:
+		return fmt.Sprintf("replay diverged at entry %d: expected event %q, got %q", d.Seq, d.Expected, d.Got)
+	}
+}
+
+// Replayer feeds the entries of a journal back into the event system.
+//
+// The journal is replayed one stimulus at a time: a recorded input, or an event that was emitted
+// outside of deriver processing. Before each stimulus, the events are processed up to the point
+// at which the stimulus was recorded. The events emitted by the derivers are compared with the
+// recorded ones, and the first difference stops the replay with a Divergence.
+//
+// Changes made outside of the event system and the recorded inputs, like an admin RPC that resets
+// the derivation pipeline, are not replayed, and show up as a divergence.
+type Replayer struct {
+	log     log.Logger
+	entries []journal.Entry
+	// index of the next entry to replay
+	next int
+
+	exec    *event.GlobalSyncExec
+	emitter event.Emitter
+	target  Target
+	tracer  *replayTracer
+	driver  *driver.Driver
+
+	// recorded emissions and number of processed events, up to the next entry
+	expected          []journal.Entry
+	expectedProcessed int
+	// number of emissions that have been compared
+	checked int
+
+	err error
+}
+
+// NewReplayer sets up the driver of the journal header with clients that serve the recorded responses.
+func NewReplayer(logger log.Logger, entries []journal.Entry) (*Replayer, error) {
+	header, err := ReadHeader(entries)
+	if err != nil {
+		return nil, err
+	}
+	if header.Rollup.AltDAEnabled() {
+		return nil, errors.New("replay of alt-DA chains is not supported")
+	}
+	if header.Rollup.InteropTime != nil {
+		return nil, errors.New("replay of interop chains is not supported")
+	}
+	responses := journal.NewResponses(entries)
+
+	// receipts are not read from a local database, only the recorded responses are served
+	l1Cfg := *header.L1
+	l1Cfg.RethDBPath = ""
+	l1, err := sources.NewL1Client(client.NewReplayRPC(responses, SourceL1), logger, nil, &l1Cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create L1 replay client: %w", err)
+	}
+	l2Cfg := *header.L2
+	l2Cfg.RethDBPath = ""
+	l2Cfg.RollupCfg = header.Rollup
+	l2, err := sources.NewEngineClient(client.NewReplayRPC(responses, SourceL2), logger, nil, &l2Cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create engine replay client: %w", err)
+	}
+	var blobs derive.L1BlobsFetcher
+	if header.Beacon != nil {
+		blobs = sources.NewL1BeaconClient(NewReplayBeaconClient(responses), *header.Beacon)
+	}
+
+	exec := event.NewGlobalSynchronous(context.Background())
+	sys := event.NewSystem(logger, exec)
+	d := driver.NewDriver(sys, exec, &header.Driver, header.Rollup, header.L1ChainConfig, nil, l2, l1, nil,
+		blobs, nil, noopNetwork{}, logger, metrics.NoopMetrics, config.DisabledConfigPersistence{}, safedb.Disabled,
+		&header.Sync, &conductor.NoOpConductor{}, sequencing.NoInclusionPolicy{}, nil, false)
+
+	r := newReplayer(logger, entries, exec, sys, d)
+	r.driver = d
+	return r, nil
+}
+
+func newReplayer(logger log.Logger, entries []journal.Entry, exec *event.GlobalSyncExec, sys event.System, target Target) *Replayer {
+	tracer := &replayTracer{}
+	sys.AddTracer(tracer)
+	return &Replayer{
+		log:     logger,
+		entries: entries,
+		exec:    exec,
+		emitter: sys.Register("journal-replay", nil),
+		target:  target,
+		tracer:  tracer,
+	}
+}
+
+// Step replays the journal up to and including the next stimulus.
+// It returns the journal sequence number of the stimulus, or io.EOF once the whole journal is replayed.
+func (r *Replayer) Step(ctx context.Context) (uint64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	seq, err := r.step(ctx)
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = err
+	}
+	return seq, err
+}
+
+func (r *Replayer) step(ctx context.Context) (uint64, error) {
+	for r.next < len(r.entries) {
+		e := r.entries[r.next]
+		r.next++
+		switch e.Kind {
+		case journal.KindProcessed:
+			r.expectedProcessed++
+		case journal.KindEmit:
+			r.expected = append(r.expected, e)
+			if e.DerivContext != 0 || e.Input != 0 {
+				// emitted again by the deriver or input that emitted it
+				continue
+			}
+			decode, ok := rootEvents[e.Event]
+			if !ok {
+				return e.Seq, fmt.Errorf("%w: %q emitted by %s (entry %d)", ErrUnsupportedEvent, e.Event, e.Name, e.Seq)
+			}
+			ev, err := decode(e.Data)
+			if err != nil {
+				return e.Seq, fmt.Errorf("failed to decode event %q of entry %d: %w", e.Event, e.Seq, err)
+			}
+			if err := r.catchUp(); err != nil {
+				return e.Seq, err
+			}
+			r.emitter.Emit(ctx, ev)
+			return e.Seq, nil
+		case journal.KindInput:
+			if err := r.catchUp(); err != nil {
+				return e.Seq, err
+			}
+			if err := r.applyInput(ctx, e); err != nil {
+				return e.Seq, err
+			}
+			return e.Seq, nil
+		}
+	}
+	if err := r.exec.Drain(); err != nil {
+		return 0, fmt.Errorf("failed to process remaining events: %w", err)
+	}
+	if err := r.check(true); err != nil {
+		return 0, err
+	}
+	return 0, io.EOF
+}
+
+// Run replays the journal up to and including the stimulus with the given sequence number,
+// or the whole journal if until is 0.
+func (r *Replayer) Run(ctx context.Context, until uint64) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		seq, err := r.Step(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if until != 0 && seq >= until {
+			return r.catchUp()
+		}
+	}
+}
+
+// SyncStatus returns the sync status of the replayed driver.
+func (r *Replayer) SyncStatus() *eth.SyncStatus {
+	if r.driver == nil {
+		return nil
+	}
+	return r.driver.StatusTracker.SyncStatus()
+}
+
+// catchUp processes events until as many events are processed as were recorded up to the current entry.
+func (r *Replayer) catchUp() error {
+	for r.tracer.Processed() < r.expectedProcessed {
+		err := r.exec.DrainUntil(func(event.Event) bool { return true }, false)
+		if errors.Is(err, io.EOF) {
+			break // fewer events than recorded, which check reports
+		} else if err != nil {
+			return fmt.Errorf("failed to process events: %w", err)
+		}
+	}
+	return r.check(false)
+}
+
+// check compares the replayed emissions with the recorded ones.
+// The replay may be ahead of the recording while an event is being processed,
+// so only complete checks require the same number of emissions.
+func (r *Replayer) check(complete bool) error {
+	emitted := r.tracer.Emitted()
+	n := min(len(emitted), len(r.expected))
+	for i := r.checked; i < n; i++ {
+		if emitted[i] != r.expected[i].Event {
+			return &Divergence{Seq: r.expected[i].Seq, Expected: r.expected[i].Event, Got: emitted[i]}
+		}
+	}
+	r.checked = n
+	if !complete {
+		return nil
+	}
+	if len(emitted) > n {
+		return &Divergence{Seq: r.entries[len(r.entries)-1].Seq, Got: emitted[n]}
+	}
+	if len(r.expected) > n {
+		return &Divergence{Seq: r.expected[n].Seq, Expected: r.expected[n].Event}
+	}
+	return nil
+}
+
+func (r *Replayer) applyInput(ctx context.Context, e journal.Entry) error {
+	switch e.Name {
+	case InputL1Unsafe, InputL1Safe, InputL1Finalized:
+		var sig eth.L1BlockRef
+		if err := json.Unmarshal(e.Data, &sig); err != nil {
+			return fmt.Errorf("failed to decode input %s of entry %d: %w", e.Name, e.Seq, err)
+		}
+		switch e.Name {
+		case InputL1Unsafe:
+			r.target.OnL1Unsafe(ctx, sig)
+		case InputL1Safe:
+			r.target.OnL1Safe(ctx, sig)
+		case InputL1Finalized:
+			r.target.OnL1Finalized(ctx, sig)
+		}
+	case InputUnsafePayload:
+		var envelope eth.ExecutionPayloadEnvelope
+		if err := json.Unmarshal(e.Data, &envelope); err != nil {
+			return fmt.Errorf("failed to decode input %s of entry %d: %w", e.Name, e.Seq, err)
+		}
+		r.target.OnUnsafeL2Payload(ctx, &envelope)
+	default:
+		return fmt.Errorf("unknown input %q of entry %d", e.Name, e.Seq)
+	}
+	return nil
+}
+
+// replayTracer collects the replayed emissions.
+type replayTracer struct {
+	mu        gosync.Mutex
+	emitted   []string
+	processed int
+}
+
+var _ event.Tracer = (*replayTracer)(nil)
+
+func (rt *replayTracer) OnDeriveStart(name string, ev event.AnnotatedEvent, derivContext uint64, startTime time.Time) {
+}
+
+func (rt *replayTracer) OnDeriveEnd(name string, ev event.AnnotatedEvent, derivContext uint64, startTime time.Time, duration time.Duration, effect bool) {
+}
+
+func (rt *replayTracer) OnRateLimited(name string, derivContext uint64) {}
+
+func (rt *replayTracer) OnEmit(name string, ev event.AnnotatedEvent, derivContext uint64, emitTime time.Time) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.emitted = append(rt.emitted, ev.Event.String())
+}
+
+func (rt *replayTracer) OnAfterProcessed(evtype string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.processed++
+}
+
+func (rt *replayTracer) Emitted() []string {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.emitted
+}
+
+func (rt *replayTracer) Processed() int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.processed
+}
+
+// noopNetwork drops the payloads that a replayed sequencer publishes.
+type noopNetwork struct{}
+
+func (noopNetwork) SignAndPublishL2Payload(ctx context.Context, payload *eth.ExecutionPayloadEnvelope) error {
+	return nil
+}
@@ -0,0 +1,141 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/driver"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/event"
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+type testEvent string
+
+func (ev testEvent) String() string {
+	return string(ev)
+}
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// testTarget emits an event for every L1 head it receives.
+type testTarget struct {
+	emitter event.Emitter
+	heads   []eth.L1BlockRef
+}
+
+func (tt *testTarget) OnL1Unsafe(ctx context.Context, sig eth.L1BlockRef) {
+	tt.heads = append(tt.heads, sig)
+	tt.emitter.Emit(ctx, testEvent("l1-head"))
+}
+
+func (tt *testTarget) OnL1Safe(ctx context.Context, sig eth.L1BlockRef)      {}
+func (tt *testTarget) OnL1Finalized(ctx context.Context, sig eth.L1BlockRef) {}
+func (tt *testTarget) OnUnsafeL2Payload(ctx context.Context, payload *eth.ExecutionPayloadEnvelope) {
+}
+
+type testSystem struct {
+	exec   *event.GlobalSyncExec
+	sys    *event.Sys
+	step   event.Emitter
+	target *testTarget
+}
+
+// newTestSystem sets up a deriver that emits the given event on every step.
+func newTestSystem(t *testing.T, onStep testEvent) *testSystem {
+	exec := event.NewGlobalSynchronous(context.Background())
+	sys := event.NewSystem(testlog.Logger(t, log.LevelError), exec)
+	var em event.Emitter
+	em = sys.Register("stepper", event.DeriverFunc(func(ctx context.Context, ev event.Event) bool {
+		if _, ok := ev.(driver.StepEvent); ok {
+			em.Emit(ctx, onStep)
+			return true
+		}
+		return false
+	}))
+	return &testSystem{
+		exec:   exec,
+		sys:    sys,
+		step:   sys.Register("step-scheduler", nil),
+		target: &testTarget{emitter: sys.Register("l1", nil)},
+	}
+}
+
+func record(t *testing.T, rootEvent event.Event) []journal.Entry {
+	var buf bytes.Buffer
+	w := journal.NewWriter(nopCloser{&buf})
+	ts := newTestSystem(t, "derived")
+	ts.sys.AddTracer(event.NewJournalTracer(w))
+
+	ctx := context.Background()
+	ts.step.Emit(ctx, driver.StepEvent{})
+	require.NoError(t, ts.exec.Drain())
+	head := eth.L1BlockRef{Hash: common.Hash{0xaa}, Number: 100}
+	ts.target.OnL1Unsafe(w.WriteInput(ctx, InputL1Unsafe, head), head)
+	require.NoError(t, ts.exec.Drain())
+	ts.step.Emit(ctx, rootEvent)
+	require.NoError(t, ts.exec.Drain())
+	require.NoError(t, w.Close())
+
+	entries, err := journal.Decode(&buf)
+	require.NoError(t, err)
+	return entries
+}
+
+func TestReplay(t *testing.T) {
+	entries := record(t, driver.StepEvent{})
+	ts := newTestSystem(t, "derived")
+	r := newReplayer(testlog.Logger(t, log.LevelError), entries, ts.exec, ts.sys, ts.target)
+	require.NoError(t, r.Run(context.Background(), 0))
+	require.Equal(t, []eth.L1BlockRef{{Hash: common.Hash{0xaa}, Number: 100}}, ts.target.heads)
+	require.Equal(t, []string{"step", "derived", "l1-head", "step", "derived"}, r.tracer.Emitted())
+}
+
+func TestReplayUntil(t *testing.T) {
+	entries := record(t, driver.StepEvent{})
+	var inputSeq uint64
+	for _, e := range entries {
+		if e.Kind == journal.KindInput {
+			inputSeq = e.Seq
+		}
+	}
+	ts := newTestSystem(t, "derived")
+	r := newReplayer(testlog.Logger(t, log.LevelError), entries, ts.exec, ts.sys, ts.target)
+	require.NoError(t, r.Run(context.Background(), inputSeq))
+	require.Len(t, ts.target.heads, 1)
+	require.Equal(t, []string{"step", "derived", "l1-head"}, r.tracer.Emitted())
+}
+
+func TestReplayDivergence(t *testing.T) {
+	entries := record(t, driver.StepEvent{})
+	ts := newTestSystem(t, "changed")
+	r := newReplayer(testlog.Logger(t, log.LevelError), entries, ts.exec, ts.sys, ts.target)
+	err := r.Run(context.Background(), 0)
+	var div *Divergence
+	require.True(t, errors.As(err, &div))
+	require.Equal(t, "derived", div.Expected)
+	require.Equal(t, "changed", div.Got)
+	_, err = r.Step(context.Background())
+	require.ErrorIs(t, err, div, "replay stops at the divergence")
+}
+
+func TestReplayUnsupportedEvent(t *testing.T) {
+	entries := record(t, testEvent("custom"))
+	ts := newTestSystem(t, "derived")
+	r := newReplayer(testlog.Logger(t, log.LevelError), entries, ts.exec, ts.sys, ts.target)
+	err := r.Run(context.Background(), 0)
+	require.ErrorIs(t, err, ErrUnsupportedEvent)
+	require.ErrorContains(t, err, "custom")
+}
@@ -0,0 +1,222 @@
+package safedb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+var ErrInvalidImport = errors.New("invalid import")
+
+// importBatchSize is the number of entries written to the database in a single batch during import.
+const importBatchSize = 1000
+
+// iterRange calls fn with each entry recorded at an L1 block in the inclusive range [start, end], in ascending order.
+// Iteration stops early, without error, if fn returns false.
+// The caller must hold the read lock.
+func (d *SafeDB) iterRange(ctx context.Context, start uint64, end uint64, fn func(entry eth.SafeHeadResponse) (bool, error)) error {
+	if end < start {
+		return nil
+	}
+	if d.closed {
+		return errors.New("safe head database is closed")
+	}
+	iter, err := d.db.NewIterWithContext(ctx, safeByL1BlockNumKey.IterRange())
+	if err != nil {
+		return fmt.Errorf("failed to create iterator: %w", err)
+	}
+	defer iter.Close()
+	for valid := iter.SeekGE(safeByL1BlockNumKey.Of(start)); valid; valid = iter.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		val, err := iter.ValueAndErr()
+		if err != nil {
+			return fmt.Errorf("failed to read entry: %w", err)
+		}
+		l1Block, safeHead, err := decodeSafeByL1BlockNum(iter.Key(), val)
+		if err != nil {
+			return err
+		}
+		if l1Block.Number > end {
+			return nil
+		}
+		if cont, err := fn(eth.SafeHeadResponse{L1Block: l1Block, SafeHead: safeHead}); err != nil {
+			return err
+		} else if !cont {
+			return nil
+		}
+	}
+	return iter.Error()
+}
+
+// SafeHeadsInRange returns the safe head updates recorded at L1 blocks in the inclusive range [start, end],
+// ordered by L1 block number. At most limit entries are returned, a limit of 0 means no limit.
+// Callers can page through a larger range by continuing from the L1 block after the last returned entry.
+func (d *SafeDB) SafeHeadsInRange(ctx context.Context, start uint64, end uint64, limit int) ([]eth.SafeHeadResponse, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	var entries []eth.SafeHeadResponse
+	err := d.iterRange(ctx, start, end, func(entry eth.SafeHeadResponse) (bool, error) {
+		entries = append(entries, entry)
+		return limit <= 0 || len(entries) < limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Export writes the safe head updates recorded at L1 blocks in the inclusive range [start, end] to w,
+// as newline-delimited JSON, and returns the number of entries written.
+func (d *SafeDB) Export(ctx context.Context, w io.Writer, start uint64, end uint64) (int, error) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	count := 0
+	err := d.iterRange(ctx, start, end, func(entry eth.SafeHeadResponse) (bool, error) {
+		if err := enc.Encode(entry); err != nil {
+			return false, fmt.Errorf("failed to write entry at L1 block %v: %w", entry.L1Block, err)
+		}
+		count++
+		return true, nil
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := bw.Flush(); err != nil {
+		return count, fmt.Errorf("failed to flush export: %w", err)
+	}
+	return count, nil
+}
+
+// Import reads newline-delimited JSON safe head updates, as written by Export, and records them.
+// Entries must be ordered by L1 block number, and must follow the last entry already in the database,
+// so an import can only extend the recorded history. Returns the number of entries imported.
+func (d *SafeDB) Import(ctx context.Context, r io.Reader) (int, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if d.closed {
+		return 0, errors.New("safe head database is closed")
+	}
+
+	var last *eth.SafeHeadResponse
+	iter, err := d.db.NewIterWithContext(ctx, safeByL1BlockNumKey.IterRange())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create iterator: %w", err)
+	}
+	if iter.Last() {
+		val, err := iter.ValueAndErr()
+		if err != nil {
+			_ = iter.Close()
+			return 0, fmt.Errorf("failed to read last entry: %w", err)
+		}
+		l1Block, safeHead, err := decodeSafeByL1BlockNum(iter.Key(), val)
+		if err != nil {
+			_ = iter.Close()
+			return 0, err
+		}
+		last = &eth.SafeHeadResponse{L1Block: l1Block, SafeHead: safeHead}
+	}
+	if err := iter.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close iterator: %w", err)
+	}
+
+	batch := d.db.NewBatch()
+	defer func() {
+		_ = batch.Close()
+	}()
+	imported := 0
+	pending := 0
+	dec := json.NewDecoder(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return imported, err
+		}
+		var entry eth.SafeHeadResponse
+		if err := dec.Decode(&entry); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return imported, fmt.Errorf("failed to decode entry %d: %w", imported+pending, err)
+		}
+		if last != nil {
+			if entry.L1Block.Number <= last.L1Block.Number {
+				return imported, fmt.Errorf("%w: entry at L1 block %v does not follow L1 block %v", ErrInvalidImport, entry.L1Block, last.L1Block)
+			}
+			if entry.SafeHead.Number < last.SafeHead.Number {
+				return imported, fmt.Errorf("%w: safe head %v at L1 block %v is before safe head %v",
+					ErrInvalidImport, entry.SafeHead, entry.L1Block, last.SafeHead)
+			}
+		}
+		if err := batch.Set(safeByL1BlockNumKey.Of(entry.L1Block.Number), safeByL1BlockNumValue(entry.L1Block, entry.SafeHead), d.writeOpts); err != nil {
+			return imported, fmt.Errorf("failed to record entry at L1 block %v: %w", entry.L1Block, err)
+		}
+		last = &entry
+		pending++
+		if pending >= importBatchSize {
+			if err := batch.Commit(d.writeOpts); err != nil {
+				return imported, fmt.Errorf("failed to commit imported entries: %w", err)
+			}
+			_ = batch.Close()
+			batch = d.db.NewBatch()
+			imported += pending
+			pending = 0
+		}
+	}
+	if pending > 0 {
+		if err := batch.Commit(d.writeOpts); err != nil {
+			return imported, fmt.Errorf("failed to commit imported entries: %w", err)
+		}
+		imported += pending
+	}
+	d.log.Info("Imported safe head entries", "count", imported, "last", last)
+	return imported, nil
+}
+
+// PruneBefore deletes the safe head updates recorded before the given L1 block.
+// The last update at or before the L1 block is kept, so the safe head at any L1 block from l1BlockNum onwards
+// can still be looked up.
+func (d *SafeDB) PruneBefore(l1BlockNum uint64) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.pruneBefore(l1BlockNum)
+}
+
+// pruneBefore implements PruneBefore. The caller must hold the write lock.
+func (d *SafeDB) pruneBefore(l1BlockNum uint64) error {
+	if d.closed {
+		return errors.New("safe head database is closed")
+	}
+	iter, err := d.db.NewIter(safeByL1BlockNumKey.IterRange())
+	if err != nil {
+		return fmt.Errorf("prune failed to create iterator: %w", err)
+	}
+	if valid := iter.SeekLT(safeByL1BlockNumKey.Of(l1BlockNum + 1)); !valid {
+		// No entries at or before the L1 block, so nothing to prune
+		return iter.Close()
+	}
+	val, err := iter.ValueAndErr()
+	if err != nil {
+		_ = iter.Close()
+		return fmt.Errorf("prune failed to read entry: %w", err)
+	}
+	l1Block, _, err := decodeSafeByL1BlockNum(iter.Key(), val)
+	if err != nil {
+		_ = iter.Close()
+		return fmt.Errorf("prune encountered invalid entry: %w", err)
+	}
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("prune failed to close iterator: %w", err)
+	}
+	if err := d.db.DeleteRange(safeByL1BlockNumKey.Of(0), safeByL1BlockNumKey.Of(l1Block.Number), d.writeOpts); err != nil {
+		return fmt.Errorf("prune failed to delete entries before L1 block %v: %w", l1Block, err)
+	}
+	d.log.Info("Pruned safe head database", "before", l1Block)
+	return nil
+}
@@ -0,0 +1,148 @@
+package safedb
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+func newTestDB(t *testing.T, entries ...eth.SafeHeadResponse) *SafeDB {
+	db, err := NewSafeDB(testlog.Logger(t, log.LvlInfo), t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+	for _, entry := range entries {
+		require.NoError(t, db.SafeHeadUpdated(eth.L2BlockRef{Hash: entry.SafeHead.Hash, Number: entry.SafeHead.Number}, entry.L1Block))
+	}
+	return db
+}
+
+func testEntries(n int) []eth.SafeHeadResponse {
+	entries := make([]eth.SafeHeadResponse, n)
+	for i := range entries {
+		entries[i] = eth.SafeHeadResponse{
+			L1Block:  eth.BlockID{Hash: common.Hash{0x01, byte(i)}, Number: 100 + uint64(i)*10},
+			SafeHead: eth.BlockID{Hash: common.Hash{0x02, byte(i)}, Number: 20 + uint64(i)*5},
+		}
+	}
+	return entries
+}
+
+func TestSafeHeadsInRange(t *testing.T) {
+	entries := testEntries(5)
+	db := newTestDB(t, entries...)
+	ctx := context.Background()
+
+	actual, err := db.SafeHeadsInRange(ctx, 0, 1000, 0)
+	require.NoError(t, err)
+	require.Equal(t, entries, actual)
+
+	// Bounds are inclusive
+	actual, err = db.SafeHeadsInRange(ctx, 110, 130, 0)
+	require.NoError(t, err)
+	require.Equal(t, entries[1:4], actual)
+
+	actual, err = db.SafeHeadsInRange(ctx, 111, 129, 0)
+	require.NoError(t, err)
+	require.Equal(t, entries[2:3], actual)
+
+	actual, err = db.SafeHeadsInRange(ctx, 0, 1000, 2)
+	require.NoError(t, err)
+	require.Equal(t, entries[:2], actual)
+
+	actual, err = db.SafeHeadsInRange(ctx, 200, 300, 0)
+	require.NoError(t, err)
+	require.Empty(t, actual)
+}
+
+func TestExportImport(t *testing.T) {
+	entries := testEntries(5)
+	src := newTestDB(t, entries...)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	count, err := src.Export(ctx, &buf, 110, 1000)
+	require.NoError(t, err)
+	require.Equal(t, 4, count)
+	require.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 4)
+
+	// Importing into a database with older history extends it
+	dst := newTestDB(t, entries[0])
+	count, err = dst.Import(ctx, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 4, count)
+	actual, err := dst.SafeHeadsInRange(ctx, 0, 1000, 0)
+	require.NoError(t, err)
+	require.Equal(t, entries, actual)
+
+	l1, safeHead, err := dst.SafeHeadAtL1(ctx, 125)
+	require.NoError(t, err)
+	require.Equal(t, entries[2].L1Block, l1)
+	require.Equal(t, entries[2].SafeHead, safeHead)
+}
+
+func TestImportRejectsOverlap(t *testing.T) {
+	entries := testEntries(3)
+	src := newTestDB(t, entries...)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	_, err := src.Export(ctx, &buf, 0, 1000)
+	require.NoError(t, err)
+
+	dst := newTestDB(t, entries[1])
+	count, err := dst.Import(ctx, bytes.NewReader(buf.Bytes()))
+	require.ErrorIs(t, err, ErrInvalidImport)
+	require.Zero(t, count)
+
+	// Nothing was written
+	actual, err := dst.SafeHeadsInRange(ctx, 0, 1000, 0)
+	require.NoError(t, err)
+	require.Equal(t, entries[1:2], actual)
+}
+
+func TestPruneBefore(t *testing.T) {
+	entries := testEntries(5)
+	db := newTestDB(t, entries...)
+	ctx := context.Background()
+
+	require.NoError(t, db.PruneBefore(125))
+	actual, err := db.SafeHeadsInRange(ctx, 0, 1000, 0)
+	require.NoError(t, err)
+	// The entry at L1 block 120 is kept, as it's the safe head at L1 block 125
+	require.Equal(t, entries[2:], actual)
+
+	l1, safeHead, err := db.SafeHeadAtL1(ctx, 125)
+	require.NoError(t, err)
+	require.Equal(t, entries[2].L1Block, l1)
+	require.Equal(t, entries[2].SafeHead, safeHead)
+
+	_, _, err = db.SafeHeadAtL1(ctx, 119)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPruneWithRetention(t *testing.T) {
+	db := newTestDB(t)
+	db.SetRetention(50)
+	ctx := context.Background()
+
+	for i := uint64(0); i < 30; i++ {
+		l1 := eth.BlockID{Hash: common.Hash{0x01, byte(i)}, Number: 10 * i}
+		require.NoError(t, db.SafeHeadUpdated(eth.L2BlockRef{Hash: common.Hash{0x02, byte(i)}, Number: i}, l1))
+	}
+	// Pruning happens in steps of pruneInterval blocks: the update at L1 block 250 pruned everything before L1 block 200,
+	// and later updates are not yet pruneInterval blocks further.
+	actual, err := db.SafeHeadsInRange(ctx, 0, 1000, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(200), actual[0].L1Block.Number)
+	require.Equal(t, uint64(290), actual[len(actual)-1].L1Block.Number)
+}
@@ -19,6 +19,11 @@ var (
 	ErrInvalidEntry = errors.New("invalid db entry")
 )
 
+const (
+	// pruneInterval is the minimum number of L1 blocks between automatic prunes of the database
+	pruneInterval = 100
+)
+
 const (
 	// Keys are prefixed with a constant byte to allow us to differentiate different "columns" within the data
 	keyPrefixSafeByL1BlockNum byte = 0
@@ -58,6 +63,11 @@ type SafeDB struct {
 
 	writeOpts *pebble.WriteOptions
 
+	// retention is the number of L1 blocks of history to keep, or 0 to keep all history.
+	retention uint64
+	// prunedTo is the L1 block number the database was last pruned to.
+	prunedTo uint64
+
 	closed bool
 }
 
@@ -93,6 +103,14 @@ func NewSafeDB(logger log.Logger, path string) (*SafeDB, error) {
 	}, nil
 }
 
+// SetRetention configures the database to automatically prune entries older than the given number of L1 blocks,
+// as new safe heads are recorded. A retention of 0 disables pruning.
+func (d *SafeDB) SetRetention(blocks uint64) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	d.retention = blocks
+}
+
 func (d *SafeDB) Enabled() bool {
 	return true
 }
@@ -109,6 +127,16 @@ func (d *SafeDB) SafeHeadUpdated(safeHead eth.L2BlockRef, l1Head eth.BlockID) er
 	if err := batch.Commit(d.writeOpts); err != nil {
 		return fmt.Errorf("failed to commit safe head update: %w", err)
 	}
+	if d.retention > 0 && l1Head.Number > d.retention {
+		if pruneTo := l1Head.Number - d.retention; pruneTo >= d.prunedTo+pruneInterval {
+			// The update itself succeeded, so only log pruning failures and retry on a later update.
+			if err := d.pruneBefore(pruneTo); err != nil {
+				d.log.Error("Failed to prune safe head database", "before", pruneTo, "err", err)
+			} else {
+				d.prunedTo = pruneTo
+			}
+		}
+	}
 	return nil
 }
 
@@ -281,6 +281,44 @@ func TestSafeHeadAtL1Block(t *testing.T) {
 	safeReader.Mock.AssertExpectations(t)
 }
 
+func TestSafeHeadsInRange(t *testing.T) {
+	log := testlog.Logger(t, log.LevelError)
+	l2Client := &testutils.MockL2Client{}
+	drClient := &mockDriverClient{}
+	safeReader := &mockSafeDBReader{}
+	expected := []eth.SafeHeadResponse{
+		{L1Block: eth.BlockID{Hash: common.Hash{0xd1}, Number: 101}, SafeHead: eth.BlockID{Hash: common.Hash{0xe1}, Number: 20}},
+		{L1Block: eth.BlockID{Hash: common.Hash{0xd2}, Number: 105}, SafeHead: eth.BlockID{Hash: common.Hash{0xe2}, Number: 28}},
+	}
+	safeReader.ExpectSafeHeadsInRange(100, 200, expected, nil)
+
+	rpcCfg := &oprpc.CLIConfig{
+		ListenAddr: "localhost",
+		ListenPort: 0,
+	}
+	rollupCfg := &rollup.Config{
+		// ignore other rollup config info in this test
+	}
+	m := &opmetrics.NoopRPCMetrics{}
+	server := newRPCServer(rpcCfg, rollupCfg, nil, l2Client, drClient, safeReader, log, m, "0.0")
+	require.NoError(t, server.Start())
+	defer func() {
+		require.NoError(t, server.Stop())
+	}()
+
+	client, err := rpcclient.NewRPC(context.Background(), log, "http://"+server.Endpoint(), rpcclient.WithDialAttempts(3))
+	require.NoError(t, err)
+
+	var out []eth.SafeHeadResponse
+	err = client.CallContext(context.Background(), &out, "optimism_safeHeadsInRange", hexutil.Uint64(100).String(), hexutil.Uint64(200).String())
+	require.NoError(t, err)
+	require.Equal(t, expected, out)
+
+	err = client.CallContext(context.Background(), &out, "optimism_safeHeadsInRange", hexutil.Uint64(200).String(), hexutil.Uint64(100).String())
+	require.ErrorContains(t, err, "invalid range")
+	safeReader.Mock.AssertExpectations(t)
+}
+
 type mockDriverClient struct {
 	mock.Mock
 }
@@ -343,3 +381,12 @@ func (m *mockSafeDBReader) SafeHeadAtL1(ctx context.Context, l1BlockNum uint64)
 func (m *mockSafeDBReader) ExpectSafeHeadAtL1(l1BlockNum uint64, l1 eth.BlockID, safeHead eth.BlockID, err error) {
 	m.Mock.On("SafeHeadAtL1", l1BlockNum).Return(l1, safeHead, &err)
 }
+
+func (m *mockSafeDBReader) SafeHeadsInRange(ctx context.Context, start uint64, end uint64, limit int) ([]eth.SafeHeadResponse, error) {
+	r := m.Mock.MethodCalled("SafeHeadsInRange", start, end, limit)
+	return r[0].([]eth.SafeHeadResponse), *r[1].(*error)
+}
+
+func (m *mockSafeDBReader) ExpectSafeHeadsInRange(start uint64, end uint64, entries []eth.SafeHeadResponse, err error) {
+	m.Mock.On("SafeHeadsInRange", start, end, maxSafeHeadsPerRequest).Return(entries, &err)
+}
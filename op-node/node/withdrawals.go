@@ -0,0 +1,332 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	opbindings "github.com/tokamak-network/tokamak-thanos/op-bindings/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	bindingspreview "github.com/tokamak-network/tokamak-thanos/op-node/bindings/preview"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
+	"github.com/tokamak-network/tokamak-thanos/op-node/withdrawals"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources/batching"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources/batching/rpcblock"
+	"github.com/tokamak-network/tokamak-thanos/packages/tokamak/contracts-bedrock/snapshots"
+)
+
+const (
+	methodDisputeGameFactory = "disputeGameFactory"
+	methodRespectedGameType  = "respectedGameType"
+	methodL2Oracle           = "l2Oracle"
+	methodGetL2Output        = "getL2Output"
+	methodGameAtIndex        = "gameAtIndex"
+	methodRootClaim          = "rootClaim"
+	methodL2BlockNumber      = "l2BlockNumber"
+	methodNativeTokenAddress = "nativeTokenAddress"
+	methodDecimals           = "decimals"
+)
+
+// withdrawalSearchRange is the number of L2 blocks, up to the proven output, that are searched for a withdrawal hash.
+const withdrawalSearchRange = 500_000
+
+// withdrawalSearchBatch is the number of L2 blocks that are searched per eth_getLogs request.
+const withdrawalSearchBatch = 10_000
+
+type withdrawalsL2Client interface {
+	InfoByNumber(ctx context.Context, number uint64) (eth.BlockInfo, error)
+	GetProof(ctx context.Context, address common.Address, storage []common.Hash, blockTag string) (*eth.AccountResult, error)
+}
+
+// withdrawalsL2RPC serves the L2 receipts and logs, which are not available through the L2 client.
+type withdrawalsL2RPC interface {
+	CallContext(ctx context.Context, result any, method string, args ...any) error
+}
+
+// outputSource is the L1 contract that the portal proves withdrawals against.
+type outputSource struct {
+	disputeGame bool
+	// contract is the DisputeGameFactory if disputeGame, or else the L2OutputOracle.
+	contract *batching.BoundContract
+}
+
+// withdrawalsAPI serves the proofs to prove withdrawals on L1, in the "optimism" namespace.
+type withdrawalsAPI struct {
+	log   log.Logger
+	l1    *batching.MultiCaller
+	l2    withdrawalsL2Client
+	l2RPC withdrawalsL2RPC
+
+	// portal and portal2 are the OptimismPortal, bound to the ABI of the L2OutputOracle and dispute game versions.
+	portal       *batching.BoundContract
+	portal2      *batching.BoundContract
+	systemConfig *batching.BoundContract
+
+	oracleABI  *abi.ABI
+	factoryABI *abi.ABI
+	gameABI    *abi.ABI
+	erc20ABI   *abi.ABI
+
+	mu     sync.Mutex
+	source *outputSource // resolved on first use, the portal version does not change
+}
+
+func NewWithdrawalsAPI(cfg *rollup.Config, l1 batching.EthRpc, l2 withdrawalsL2Client, l2RPC withdrawalsL2RPC, log log.Logger) (*withdrawalsAPI, error) {
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OptimismPortal ABI: %w", err)
+	}
+	portal2ABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OptimismPortal2 ABI: %w", err)
+	}
+	oracleABI, err := bindings.L2OutputOracleMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load L2OutputOracle ABI: %w", err)
+	}
+	systemConfigABI, err := opbindings.SystemConfigMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SystemConfig ABI: %w", err)
+	}
+	erc20ABI, err := opbindings.ERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ERC20 ABI: %w", err)
+	}
+	return &withdrawalsAPI{
+		log:          log,
+		l1:           batching.NewMultiCaller(l1, batching.DefaultBatchSize),
+		l2:           l2,
+		l2RPC:        l2RPC,
+		portal:       batching.NewBoundContract(portalABI, cfg.DepositContractAddress),
+		portal2:      batching.NewBoundContract(portal2ABI, cfg.DepositContractAddress),
+		systemConfig: batching.NewBoundContract(systemConfigABI, cfg.L1SystemConfigAddress),
+		oracleABI:    oracleABI,
+		factoryABI:   snapshots.LoadDisputeGameFactoryABI(),
+		gameABI:      snapshots.LoadFaultDisputeGameABI(),
+		erc20ABI:     erc20ABI,
+	}, nil
+}
+
+// WithdrawalProof returns the arguments of OptimismPortal.proveWithdrawalTransaction, to prove a withdrawal against
+// the output (L2OutputOracle portal) or dispute game (dispute game portal) at the given index.
+// The hash is either the hash of the L2 transaction that initiated the withdrawal, or the withdrawal hash.
+func (api *withdrawalsAPI) WithdrawalProof(ctx context.Context, hash common.Hash, index hexutil.Uint64) (*eth.WithdrawalProofResponse, error) {
+	src, err := api.outputSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	outputRoot, outputNum, err := api.provenOutput(ctx, src, uint64(index))
+	if err != nil {
+		return nil, err
+	}
+	ev, err := api.findWithdrawal(ctx, hash, outputNum)
+	if err != nil {
+		return nil, err
+	}
+	if ev.Raw.BlockNumber > outputNum {
+		return nil, fmt.Errorf("withdrawal was initiated in L2 block %d, after L2 block %d of output %d", ev.Raw.BlockNumber, outputNum, index)
+	}
+	withdrawalHash, err := withdrawals.WithdrawalHash(ev)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawalHash != ev.WithdrawalHash {
+		return nil, fmt.Errorf("computed withdrawal hash %s does not match withdrawal hash %s of the event", withdrawalHash, common.Hash(ev.WithdrawalHash))
+	}
+
+	block, err := api.l2.InfoByNumber(ctx, outputNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get L2 block %d of output %d: %w", outputNum, index, err)
+	}
+	slot := withdrawals.StorageSlotOfWithdrawalHash(withdrawalHash)
+	proof, err := api.l2.GetProof(ctx, predeploys.L2ToL1MessagePasserAddr, []common.Hash{slot}, block.Hash().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawal proof at L2 block %s: %w", block.Hash(), err)
+	}
+	if proof == nil {
+		return nil, fmt.Errorf("withdrawal proof %w", ethereum.NotFound)
+	}
+	if err := proof.Verify(block.Root()); err != nil {
+		return nil, fmt.Errorf("invalid withdrawal proof, state root was %s: %w", block.Root(), err)
+	}
+	if len(proof.StorageProof) != 1 || proof.StorageProof[0].Value.ToInt().Sign() == 0 {
+		return nil, fmt.Errorf("withdrawal %s is not stored in the L2ToL1MessagePasser at L2 block %d", withdrawalHash, outputNum)
+	}
+	output := &eth.OutputV0{
+		StateRoot:                eth.Bytes32(block.Root()),
+		MessagePasserStorageRoot: eth.Bytes32(proof.StorageHash),
+		BlockHash:                block.Hash(),
+	}
+	if root := eth.OutputRoot(output); root != outputRoot {
+		return nil, fmt.Errorf("output root %s of index %d does not match output root %s of L2 block %s", outputRoot, index, root, block.Hash())
+	}
+
+	token, err := api.nativeToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &eth.WithdrawalProofResponse{
+		Withdrawal: eth.WithdrawalTransaction{
+			Nonce:    (*hexutil.Big)(ev.Nonce),
+			Sender:   ev.Sender,
+			Target:   ev.Target,
+			Value:    (*hexutil.Big)(ev.Value),
+			GasLimit: (*hexutil.Big)(ev.GasLimit),
+			Data:     ev.Data,
+		},
+		WithdrawalHash:  withdrawalHash,
+		TransactionHash: ev.Raw.TxHash,
+		DisputeGame:     src.disputeGame,
+		Index:           index,
+		OutputRoot:      outputRoot,
+		OutputBlock:     eth.BlockID{Hash: block.Hash(), Number: outputNum},
+		OutputRootProof: eth.OutputRootProof{
+			Version:                  output.Version(),
+			StateRoot:                block.Root(),
+			MessagePasserStorageRoot: proof.StorageHash,
+			LatestBlockhash:          block.Hash(),
+		},
+		WithdrawalProof: proof.StorageProof[0].Proof,
+		NativeToken:     token,
+	}, nil
+}
+
+// outputSource determines whether the portal proves withdrawals against dispute games or L2 outputs.
+func (api *withdrawalsAPI) outputSource(ctx context.Context) (*outputSource, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.source != nil {
+		return api.source, nil
+	}
+	result, factoryErr := api.l1.SingleCall(ctx, rpcblock.Latest, api.portal2.Call(methodDisputeGameFactory))
+	if factoryErr == nil {
+		api.source = &outputSource{disputeGame: true, contract: batching.NewBoundContract(api.factoryABI, result.GetAddress(0))}
+		return api.source, nil
+	}
+	result, oracleErr := api.l1.SingleCall(ctx, rpcblock.Latest, api.portal.Call(methodL2Oracle))
+	if oracleErr != nil {
+		return nil, fmt.Errorf("portal %s has neither a dispute game factory (%w) nor an L2 output oracle (%w)", api.portal.Addr(), factoryErr, oracleErr)
+	}
+	api.source = &outputSource{contract: batching.NewBoundContract(api.oracleABI, result.GetAddress(0))}
+	return api.source, nil
+}
+
+// provenOutput returns the output root at the given index, and the number of the L2 block it commits to.
+func (api *withdrawalsAPI) provenOutput(ctx context.Context, src *outputSource, index uint64) (eth.Bytes32, uint64, error) {
+	idx := new(big.Int).SetUint64(index)
+	if !src.disputeGame {
+		result, err := api.l1.SingleCall(ctx, rpcblock.Latest, src.contract.Call(methodGetL2Output, idx))
+		if err != nil {
+			return eth.Bytes32{}, 0, fmt.Errorf("failed to get output %d: %w", index, err)
+		}
+		var proposal bindings.TypesOutputProposal
+		result.GetStruct(0, &proposal)
+		return proposal.OutputRoot, proposal.L2BlockNumber.Uint64(), nil
+	}
+
+	results, err := api.l1.Call(ctx, rpcblock.Latest, src.contract.Call(methodGameAtIndex, idx), api.portal2.Call(methodRespectedGameType))
+	if err != nil {
+		return eth.Bytes32{}, 0, fmt.Errorf("failed to get dispute game %d: %w", index, err)
+	}
+	gameType, proxy := results[0].GetUint32(0), results[0].GetAddress(2)
+	if respected := results[1].GetUint32(0); gameType != respected {
+		return eth.Bytes32{}, 0, fmt.Errorf("dispute game %d has type %d, but the portal only accepts games of type %d", index, gameType, respected)
+	}
+	game := batching.NewBoundContract(api.gameABI, proxy)
+	results, err = api.l1.Call(ctx, rpcblock.Latest, game.Call(methodRootClaim), game.Call(methodL2BlockNumber))
+	if err != nil {
+		return eth.Bytes32{}, 0, fmt.Errorf("failed to get root claim of dispute game %d at %s: %w", index, proxy, err)
+	}
+	return eth.Bytes32(results[0].GetHash(0)), results[1].GetBigInt(0).Uint64(), nil
+}
+
+// findWithdrawal finds the withdrawal initiated by the transaction with the given hash. If there is no such
+// transaction, the hash is taken as withdrawal hash and searched for in the L2 blocks up to the given block.
+func (api *withdrawalsAPI) findWithdrawal(ctx context.Context, hash common.Hash, upTo uint64) (*bindings.L2ToL1MessagePasserMessagePassed, error) {
+	var receipt *types.Receipt
+	if err := api.l2RPC.CallContext(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, fmt.Errorf("failed to get receipt of %s: %w", hash, err)
+	}
+	if receipt != nil {
+		evs, err := messagesPassed(receipt.Logs)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", hash, err)
+		}
+		if len(evs) > 1 {
+			return nil, fmt.Errorf("transaction %s initiated %d withdrawals, select one by its withdrawal hash", hash, len(evs))
+		}
+		return evs[0], nil
+	}
+
+	var lowest uint64
+	if upTo > withdrawalSearchRange {
+		lowest = upTo - withdrawalSearchRange
+	}
+	api.log.Debug("Searching withdrawal hash", "hash", hash, "from", lowest, "to", upTo)
+	for end := upTo + 1; end > lowest; {
+		start := lowest
+		if end-lowest > withdrawalSearchBatch {
+			start = end - withdrawalSearchBatch
+		}
+		var logs []*types.Log
+		if err := api.l2RPC.CallContext(ctx, &logs, "eth_getLogs", map[string]any{
+			"fromBlock": hexutil.Uint64(start),
+			"toBlock":   hexutil.Uint64(end - 1),
+			"address":   predeploys.L2ToL1MessagePasserAddr,
+			"topics":    [][]common.Hash{{withdrawals.MessagePassedTopic}},
+		}); err != nil {
+			return nil, fmt.Errorf("failed to get withdrawals of L2 blocks %d to %d: %w", start, end-1, err)
+		}
+		if len(logs) > 0 {
+			evs, err := messagesPassed(logs)
+			if err != nil {
+				return nil, err
+			}
+			for _, ev := range evs {
+				if ev.WithdrawalHash == hash {
+					return ev, nil
+				}
+			}
+		}
+		end = start
+	}
+	return nil, fmt.Errorf("no transaction or withdrawal with hash %s in L2 blocks %d to %d", hash, lowest, upTo)
+}
+
+// messagesPassed parses the withdrawals of the L2ToL1MessagePasser, ignoring events of other contracts.
+func messagesPassed(logs []*types.Log) ([]*bindings.L2ToL1MessagePasserMessagePassed, error) {
+	var passed []*types.Log
+	for _, l := range logs {
+		if l.Address == predeploys.L2ToL1MessagePasserAddr {
+			passed = append(passed, l)
+		}
+	}
+	return withdrawals.ParseMessagesPassed(&types.Receipt{Logs: passed})
+}
+
+// nativeToken returns the L1 token that withdrawal values are denominated in, or nil if the chain uses ether.
+func (api *withdrawalsAPI) nativeToken(ctx context.Context) (*eth.NativeToken, error) {
+	result, err := api.l1.SingleCall(ctx, rpcblock.Latest, api.systemConfig.Call(methodNativeTokenAddress))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get native token: %w", err)
+	}
+	addr := result.GetAddress(0)
+	if addr == (common.Address{}) {
+		return nil, nil
+	}
+	result, err = api.l1.SingleCall(ctx, rpcblock.Latest, batching.NewBoundContract(api.erc20ABI, addr).Call(methodDecimals))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decimals of native token %s: %w", addr, err)
+	}
+	return &eth.NativeToken{Address: addr, Decimals: result.GetUint8(0)}, nil
+}
@@ -0,0 +1,307 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+
+	opbindings "github.com/tokamak-network/tokamak-thanos/op-bindings/bindings"
+	"github.com/tokamak-network/tokamak-thanos/op-node/bindings"
+	bindingspreview "github.com/tokamak-network/tokamak-thanos/op-node/bindings/preview"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
+	"github.com/tokamak-network/tokamak-thanos/op-node/withdrawals"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/predeploys"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources/batching/rpcblock"
+	batchingTest "github.com/tokamak-network/tokamak-thanos/op-service/sources/batching/test"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testutils"
+	"github.com/tokamak-network/tokamak-thanos/packages/tokamak/contracts-bedrock/snapshots"
+)
+
+var (
+	portalAddr       = common.Address{0xaa}
+	systemConfigAddr = common.Address{0xbb}
+	factoryAddr      = common.Address{0xcc}
+	gameAddr         = common.Address{0xdd}
+	oracleAddr       = common.Address{0xee}
+	nativeTokenAddr  = common.Address{0xff}
+)
+
+type proofNodes []hexutil.Bytes
+
+func (p *proofNodes) Put(key []byte, value []byte) error {
+	*p = append(*p, common.CopyBytes(value))
+	return nil
+}
+
+func (p *proofNodes) Delete(key []byte) error {
+	return errors.New("not supported")
+}
+
+// withdrawalState creates an L2 state with the given withdrawal stored in the L2ToL1MessagePasser,
+// and returns the state root with the proof of the withdrawal.
+func withdrawalState(t *testing.T, withdrawalHash common.Hash) (common.Hash, *eth.AccountResult) {
+	slot := withdrawals.StorageSlotOfWithdrawalHash(withdrawalHash)
+	storage := trie.NewEmpty(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	value, err := rlp.EncodeToBytes([]byte{1})
+	require.NoError(t, err)
+	require.NoError(t, storage.Update(crypto.Keccak256(slot[:]), value))
+	var storageProof proofNodes
+	require.NoError(t, storage.Prove(crypto.Keccak256(slot[:]), &storageProof))
+
+	state := trie.NewEmpty(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	account, err := rlp.EncodeToBytes([]any{uint64(0), []byte{}, storage.Hash(), types.EmptyCodeHash})
+	require.NoError(t, err)
+	require.NoError(t, state.Update(crypto.Keccak256(predeploys.L2ToL1MessagePasserAddr[:]), account))
+	var accountProof proofNodes
+	require.NoError(t, state.Prove(crypto.Keccak256(predeploys.L2ToL1MessagePasserAddr[:]), &accountProof))
+
+	return state.Hash(), &eth.AccountResult{
+		AccountProof: accountProof,
+		Address:      predeploys.L2ToL1MessagePasserAddr,
+		Balance:      (*hexutil.Big)(new(big.Int)),
+		CodeHash:     types.EmptyCodeHash,
+		StorageHash:  storage.Hash(),
+		StorageProof: []eth.StorageProofEntry{{Key: slot, Value: hexutil.Big(*big.NewInt(1)), Proof: storageProof}},
+	}
+}
+
+func messagePassedLog(t *testing.T, ev *bindings.L2ToL1MessagePasserMessagePassed, blockNum uint64, txHash common.Hash) *types.Log {
+	passerABI, err := bindings.L2ToL1MessagePasserMetaData.GetAbi()
+	require.NoError(t, err)
+	event := passerABI.Events["MessagePassed"]
+	data, err := event.Inputs.NonIndexed().Pack(ev.Value, ev.GasLimit, ev.Data, ev.WithdrawalHash)
+	require.NoError(t, err)
+	return &types.Log{
+		Address:     predeploys.L2ToL1MessagePasserAddr,
+		Topics:      []common.Hash{event.ID, common.BigToHash(ev.Nonce), common.BytesToHash(ev.Sender[:]), common.BytesToHash(ev.Target[:])},
+		Data:        data,
+		BlockNumber: blockNum,
+		TxHash:      txHash,
+	}
+}
+
+type stubWithdrawalsL2 struct {
+	block    *testutils.MockBlockInfo
+	proof    *eth.AccountResult
+	receipts map[common.Hash]*types.Receipt
+	logs     []*types.Log
+}
+
+func (s *stubWithdrawalsL2) InfoByNumber(ctx context.Context, number uint64) (eth.BlockInfo, error) {
+	if number != s.block.InfoNum {
+		return nil, ethereum.NotFound
+	}
+	return s.block, nil
+}
+
+func (s *stubWithdrawalsL2) GetProof(ctx context.Context, address common.Address, storage []common.Hash, blockTag string) (*eth.AccountResult, error) {
+	if address != s.proof.Address || blockTag != s.block.InfoHash.String() {
+		return nil, ethereum.NotFound
+	}
+	return s.proof, nil
+}
+
+func (s *stubWithdrawalsL2) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	switch method {
+	case "eth_getTransactionReceipt":
+		*result.(**types.Receipt) = s.receipts[args[0].(common.Hash)]
+	case "eth_getLogs":
+		*result.(*[]*types.Log) = s.logs
+	default:
+		return fmt.Errorf("unexpected method %s", method)
+	}
+	return nil
+}
+
+// legacyPortalRPC reverts calls of disputeGameFactory(), like the L2OutputOracle version of the portal.
+type legacyPortalRPC struct {
+	*batchingTest.AbiBasedRpc
+	selector []byte
+}
+
+func (r *legacyPortalRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	for i := range b {
+		b[i].Error = r.CallContext(ctx, b[i].Result, b[i].Method, b[i].Args...)
+	}
+	return nil
+}
+
+func (r *legacyPortalRPC) CallContext(ctx context.Context, out any, method string, args ...any) error {
+	if call, ok := args[0].(map[string]any); ok && bytes.HasPrefix(call["input"].(hexutil.Bytes), r.selector) {
+		return errors.New("execution reverted")
+	}
+	return r.AbiBasedRpc.CallContext(ctx, out, method, args...)
+}
+
+func systemConfigABI(t *testing.T) *abi.ABI {
+	systemConfigABI, err := opbindings.SystemConfigMetaData.GetAbi()
+	require.NoError(t, err)
+	return systemConfigABI
+}
+
+func erc20ABI(t *testing.T) *abi.ABI {
+	erc20ABI, err := opbindings.ERC20MetaData.GetAbi()
+	require.NoError(t, err)
+	return erc20ABI
+}
+
+type withdrawalTest struct {
+	ev     *bindings.L2ToL1MessagePasserMessagePassed
+	txHash common.Hash
+	l2     *stubWithdrawalsL2
+	output eth.Bytes32
+}
+
+func newWithdrawalTest(t *testing.T) *withdrawalTest {
+	ev := &bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:    big.NewInt(7),
+		Sender:   common.Address{0x01},
+		Target:   common.Address{0x02},
+		Value:    big.NewInt(1_000_000),
+		GasLimit: big.NewInt(100_000),
+		Data:     []byte{0xde, 0xad},
+	}
+	hash, err := withdrawals.WithdrawalHash(ev)
+	require.NoError(t, err)
+	ev.WithdrawalHash = hash
+
+	stateRoot, proof := withdrawalState(t, hash)
+	block := &testutils.MockBlockInfo{InfoHash: common.Hash{0x42}, InfoNum: 100, InfoRoot: stateRoot}
+	txHash := common.Hash{0x7a}
+	passed := messagePassedLog(t, ev, 90, txHash)
+	return &withdrawalTest{
+		ev:     ev,
+		txHash: txHash,
+		l2: &stubWithdrawalsL2{
+			block:    block,
+			proof:    proof,
+			receipts: map[common.Hash]*types.Receipt{txHash: {TxHash: txHash, Logs: []*types.Log{passed}}},
+			logs:     []*types.Log{passed},
+		},
+		output: eth.OutputRoot(&eth.OutputV0{
+			StateRoot:                eth.Bytes32(stateRoot),
+			MessagePasserStorageRoot: eth.Bytes32(proof.StorageHash),
+			BlockHash:                block.InfoHash,
+		}),
+	}
+}
+
+func (w *withdrawalTest) requireProof(t *testing.T, res *eth.WithdrawalProofResponse) {
+	require.Equal(t, eth.WithdrawalTransaction{
+		Nonce:    (*hexutil.Big)(w.ev.Nonce),
+		Sender:   w.ev.Sender,
+		Target:   w.ev.Target,
+		Value:    (*hexutil.Big)(w.ev.Value),
+		GasLimit: (*hexutil.Big)(w.ev.GasLimit),
+		Data:     w.ev.Data,
+	}, res.Withdrawal)
+	require.Equal(t, common.Hash(w.ev.WithdrawalHash), res.WithdrawalHash)
+	require.Equal(t, w.txHash, res.TransactionHash)
+	require.Equal(t, w.output, res.OutputRoot)
+	require.Equal(t, eth.BlockID{Hash: w.l2.block.InfoHash, Number: w.l2.block.InfoNum}, res.OutputBlock)
+	require.Equal(t, eth.OutputRootProof{
+		StateRoot:                w.l2.block.InfoRoot,
+		MessagePasserStorageRoot: w.l2.proof.StorageHash,
+		LatestBlockhash:          w.l2.block.InfoHash,
+	}, res.OutputRootProof)
+	require.Equal(t, w.l2.proof.StorageProof[0].Proof, res.WithdrawalProof)
+}
+
+func TestWithdrawalProof(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelError)
+	cfg := &rollup.Config{DepositContractAddress: portalAddr, L1SystemConfigAddress: systemConfigAddr}
+	ctx := context.Background()
+
+	t.Run("dispute game", func(t *testing.T) {
+		w := newWithdrawalTest(t)
+		portal2ABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+		require.NoError(t, err)
+		l1 := batchingTest.NewAbiBasedRpc(t, portalAddr, portal2ABI)
+		l1.AddContract(factoryAddr, snapshots.LoadDisputeGameFactoryABI())
+		l1.AddContract(gameAddr, snapshots.LoadFaultDisputeGameABI())
+		l1.AddContract(systemConfigAddr, systemConfigABI(t))
+		l1.AddContract(nativeTokenAddr, erc20ABI(t))
+		l1.SetResponse(portalAddr, methodDisputeGameFactory, rpcblock.Latest, nil, []any{factoryAddr})
+		l1.SetResponse(portalAddr, methodRespectedGameType, rpcblock.Latest, nil, []any{uint32(1)})
+		l1.SetResponse(factoryAddr, methodGameAtIndex, rpcblock.Latest, []any{big.NewInt(3)}, []any{uint32(1), uint64(1234), gameAddr})
+		l1.SetResponse(factoryAddr, methodGameAtIndex, rpcblock.Latest, []any{big.NewInt(4)}, []any{uint32(0), uint64(1234), gameAddr})
+		l1.SetResponse(gameAddr, methodRootClaim, rpcblock.Latest, nil, []any{[32]byte(w.output)})
+		l1.SetResponse(gameAddr, methodL2BlockNumber, rpcblock.Latest, nil, []any{big.NewInt(100)})
+		l1.SetResponse(systemConfigAddr, methodNativeTokenAddress, rpcblock.Latest, nil, []any{nativeTokenAddr})
+		l1.SetResponse(nativeTokenAddr, methodDecimals, rpcblock.Latest, nil, []any{uint8(18)})
+		api, err := NewWithdrawalsAPI(cfg, l1, w.l2, w.l2, logger)
+		require.NoError(t, err)
+
+		res, err := api.WithdrawalProof(ctx, w.txHash, 3)
+		require.NoError(t, err)
+		w.requireProof(t, res)
+		require.True(t, res.DisputeGame)
+		require.Equal(t, hexutil.Uint64(3), res.Index)
+		require.Equal(t, &eth.NativeToken{Address: nativeTokenAddr, Decimals: 18}, res.NativeToken)
+
+		_, err = api.WithdrawalProof(ctx, w.txHash, 4)
+		require.ErrorContains(t, err, "only accepts games of type 1")
+	})
+
+	t.Run("output oracle", func(t *testing.T) {
+		w := newWithdrawalTest(t)
+		portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+		require.NoError(t, err)
+		portal2ABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+		require.NoError(t, err)
+		oracleABI, err := bindings.L2OutputOracleMetaData.GetAbi()
+		require.NoError(t, err)
+		stub := batchingTest.NewAbiBasedRpc(t, portalAddr, portalABI)
+		stub.AddContract(oracleAddr, oracleABI)
+		stub.AddContract(systemConfigAddr, systemConfigABI(t))
+		stub.SetResponse(portalAddr, methodL2Oracle, rpcblock.Latest, nil, []any{oracleAddr})
+		stub.SetResponse(oracleAddr, methodGetL2Output, rpcblock.Latest, []any{big.NewInt(5)}, []any{bindings.TypesOutputProposal{
+			OutputRoot:    w.output,
+			Timestamp:     big.NewInt(1234),
+			L2BlockNumber: big.NewInt(100),
+		}})
+		stub.SetResponse(oracleAddr, methodGetL2Output, rpcblock.Latest, []any{big.NewInt(6)}, []any{bindings.TypesOutputProposal{
+			OutputRoot:    [32]byte{0x01},
+			Timestamp:     big.NewInt(1234),
+			L2BlockNumber: big.NewInt(100),
+		}})
+		stub.SetResponse(systemConfigAddr, methodNativeTokenAddress, rpcblock.Latest, nil, []any{common.Address{}})
+		l1 := &legacyPortalRPC{AbiBasedRpc: stub, selector: portal2ABI.Methods[methodDisputeGameFactory].ID}
+		api, err := NewWithdrawalsAPI(cfg, l1, w.l2, w.l2, logger)
+		require.NoError(t, err)
+
+		// by withdrawal hash, which is searched for in the L2 logs
+		res, err := api.WithdrawalProof(ctx, w.ev.WithdrawalHash, 5)
+		require.NoError(t, err)
+		w.requireProof(t, res)
+		require.False(t, res.DisputeGame)
+		require.Equal(t, hexutil.Uint64(5), res.Index)
+		require.Nil(t, res.NativeToken)
+
+		_, err = api.WithdrawalProof(ctx, w.ev.WithdrawalHash, 6)
+		require.ErrorContains(t, err, "does not match output root")
+
+		_, err = api.WithdrawalProof(ctx, common.Hash{0x99}, 5)
+		require.ErrorContains(t, err, "no transaction or withdrawal with hash")
+	})
+}
@@ -3,6 +3,7 @@ package p2p
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/tokamak-network/tokamak-thanos/op-node/p2p/store"
 	"github.com/tokamak-network/tokamak-thanos/op-service/clock"
@@ -20,6 +21,8 @@ type ApplicationScorer interface {
 	onValidResponse(id peer.ID)
 	onResponseError(id peer.ID)
 	onRejectedPayload(id peer.ID)
+	onRangeResponse(id peer.ID, payloads int, took time.Duration)
+	Throughput(id peer.ID) (float64, bool)
 	Start()
 	Stop()
 }
@@ -33,9 +36,17 @@ type peerApplicationScorer struct {
 	scorebook      ScoreBook
 	connectedPeers func() []peer.ID
 
+	// throughput is the moving average of payloads per second served by each peer through range requests.
+	// It is only used to prioritize peers for sync requests, and not persisted in the scorebook.
+	throughputLock sync.Mutex
+	throughput     map[peer.ID]float64
+
 	done sync.WaitGroup
 }
 
+// throughputSmoothing is the weight of a new sample in the moving throughput average of a peer.
+const throughputSmoothing = 0.25
+
 var _ ApplicationScorer = (*peerApplicationScorer)(nil)
 
 func NewPeerApplicationScorer(ctx context.Context, logger log.Logger, clock clock.Clock, params *ApplicationScoreParams, scorebook ScoreBook, connectedPeers func() []peer.ID) *peerApplicationScorer {
@@ -48,6 +59,7 @@ func NewPeerApplicationScorer(ctx context.Context, logger log.Logger, clock cloc
 		params:         params,
 		scorebook:      scorebook,
 		connectedPeers: connectedPeers,
+		throughput:     make(map[peer.ID]float64),
 	}
 }
 
@@ -87,6 +99,30 @@ func (s *peerApplicationScorer) onRejectedPayload(id peer.ID) {
 	}
 }
 
+// onRangeResponse records a range response of the given number of payloads.
+// Failed requests should be recorded with 0 payloads, to lower the throughput of the peer.
+func (s *peerApplicationScorer) onRangeResponse(id peer.ID, payloads int, took time.Duration) {
+	if took <= 0 {
+		took = time.Millisecond
+	}
+	sample := float64(payloads) / took.Seconds()
+	s.throughputLock.Lock()
+	defer s.throughputLock.Unlock()
+	if prev, ok := s.throughput[id]; ok {
+		sample = prev*(1-throughputSmoothing) + sample*throughputSmoothing
+	}
+	s.throughput[id] = sample
+}
+
+// Throughput returns the moving average of payloads per second served by the peer,
+// or false if the peer did not serve any range requests yet.
+func (s *peerApplicationScorer) Throughput(id peer.ID) (float64, bool) {
+	s.throughputLock.Lock()
+	defer s.throughputLock.Unlock()
+	t, ok := s.throughput[id]
+	return t, ok
+}
+
 func (s *peerApplicationScorer) decayScores(id peer.ID) {
 	_, err := s.scorebook.SetScore(id, &store.DecayApplicationScores{
 		ValidResponseDecay:   s.params.ValidResponseDecay,
@@ -101,9 +137,26 @@ func (s *peerApplicationScorer) decayScores(id peer.ID) {
 }
 
 func (s *peerApplicationScorer) decayConnectedPeerScores() {
-	for _, id := range s.connectedPeers() {
+	connected := s.connectedPeers()
+	for _, id := range connected {
 		s.decayScores(id)
 	}
+	s.pruneThroughput(connected)
+}
+
+// pruneThroughput forgets the throughput of disconnected peers.
+func (s *peerApplicationScorer) pruneThroughput(connected []peer.ID) {
+	isConnected := make(map[peer.ID]struct{}, len(connected))
+	for _, id := range connected {
+		isConnected[id] = struct{}{}
+	}
+	s.throughputLock.Lock()
+	defer s.throughputLock.Unlock()
+	for id := range s.throughput {
+		if _, ok := isConnected[id]; !ok {
+			delete(s.throughput, id)
+		}
+	}
 }
 
 func (s *peerApplicationScorer) Start() {
@@ -143,6 +196,13 @@ func (n *NoopApplicationScorer) onResponseError(_ peer.ID) {
 func (n *NoopApplicationScorer) onRejectedPayload(_ peer.ID) {
 }
 
+func (n *NoopApplicationScorer) onRangeResponse(_ peer.ID, _ int, _ time.Duration) {
+}
+
+func (n *NoopApplicationScorer) Throughput(_ peer.ID) (float64, bool) {
+	return 0, false
+}
+
 func (n *NoopApplicationScorer) Start() {
 }
 
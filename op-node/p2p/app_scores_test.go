@@ -165,3 +165,30 @@ func TestDecayScoresAfterDecayInterval(t *testing.T) {
 	require.Equal(t, stubScoreBookUpdate{id: "aaa", diff: expectedDecay}, data.WaitForNextScoreBookUpdate(t))
 	require.Equal(t, stubScoreBookUpdate{id: "bbb", diff: expectedDecay}, data.WaitForNextScoreBookUpdate(t))
 }
+
+func TestRangeThroughput(t *testing.T) {
+	data, appScorer := setupPeerApplicationScorerTest(t, &ApplicationScoreParams{})
+
+	_, ok := appScorer.Throughput("aaa")
+	require.False(t, ok)
+
+	appScorer.onRangeResponse("aaa", 64, time.Second)
+	throughput, ok := appScorer.Throughput("aaa")
+	require.True(t, ok)
+	require.Equal(t, 64.0, throughput)
+
+	// failed requests lower the moving average
+	appScorer.onRangeResponse("aaa", 0, time.Second)
+	throughput, ok = appScorer.Throughput("aaa")
+	require.True(t, ok)
+	require.Equal(t, 64.0*(1-throughputSmoothing), throughput)
+
+	// throughput of disconnected peers is forgotten
+	appScorer.onRangeResponse("bbb", 10, time.Second)
+	data.peers = []peer.ID{"bbb"}
+	appScorer.pruneThroughput(data.peers)
+	_, ok = appScorer.Throughput("aaa")
+	require.False(t, ok)
+	_, ok = appScorer.Throughput("bbb")
+	require.True(t, ok)
+}
@@ -67,6 +67,7 @@ func NewConfig(ctx cliiface.Context, blockTime uint64) (*p2p.Config, error) {
 	conf.EnableReqRespSync = ctx.Bool(flags.SyncReqRespName)
 	conf.EnablePingService = ctx.Bool(flags.P2PPingName)
 	conf.SyncOnlyReqToStatic = ctx.Bool(flags.SyncOnlyReqToStaticName)
+	conf.EnableRangeSync = ctx.Bool(flags.SyncReqRespRangeName)
 
 	return conf, nil
 }
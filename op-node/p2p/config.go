@@ -65,6 +65,7 @@ type SetupP2P interface {
 	BanDuration() time.Duration
 	GossipSetupConfigurables
 	ReqRespSyncEnabled() bool
+	ReqRespRangeSyncEnabled() bool
 }
 
 // ScoringParams defines the various types of peer scoring parameters.
@@ -137,6 +138,8 @@ type Config struct {
 
 	EnableReqRespSync   bool
 	SyncOnlyReqToStatic bool
+	// EnableRangeSync makes the req-resp sync client fetch payloads in batches from multiple peers in parallel.
+	EnableRangeSync bool
 
 	EnablePingService bool
 }
@@ -181,6 +184,10 @@ func (conf *Config) ReqRespSyncEnabled() bool {
 	return conf.EnableReqRespSync
 }
 
+func (conf *Config) ReqRespRangeSyncEnabled() bool {
+	return conf.EnableRangeSync
+}
+
 func (conf *Config) GetGossipTimestampThreshold() time.Duration {
 	return conf.GossipTimestampThreshold
 }
@@ -4,12 +4,18 @@ import (
 	"context"
 
 	"github.com/libp2p/go-libp2p/core/peer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 )
 
+// tracerName is the instrumentation name of the spans of received blocks
+const tracerName = "github.com/tokamak-network/tokamak-thanos/op-node/p2p"
+
 type BlockReceiverMetrics interface {
 	RecordReceivedUnsafePayload(payload *eth.ExecutionPayloadEnvelope)
 }
@@ -50,6 +56,15 @@ func (g *BlockReceiver) OnUnsafeL2Payload(ctx context.Context, from peer.ID, msg
 		"id", msg.ExecutionPayload.ID(),
 		"peer", from, "txs", len(msg.ExecutionPayload.Transactions))
 	g.metrics.RecordReceivedUnsafePayload(msg)
+	// start the trace of the payload, which the events of the sync deriver continue
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "p2p.unsafe_payload",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("block.hash", msg.ExecutionPayload.BlockHash.Hex()),
+			attribute.Int64("block.number", int64(msg.ExecutionPayload.BlockNumber)),
+			attribute.String("p2p.peer", from.String()),
+		))
+	defer span.End()
 	g.syncDeriver.OnUnsafeL2Payload(ctx, msg)
 	if g.tracer != nil { // tracer is optional
 		g.tracer.OnUnsafeL2Payload(ctx, from, msg)
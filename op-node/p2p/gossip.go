@@ -95,7 +95,8 @@ func BuildSubscriptionFilter(cfg *rollup.Config) pubsub.SubscriptionFilter {
 		blocksTopicV1(cfg),
 		blocksTopicV2(cfg),
 		blocksTopicV3(cfg),
-		blocksTopicV4(cfg), // add more topics here in the future, if any.
+		blocksTopicV4(cfg),
+		preconfirmationsTopicV1(cfg), // add more topics here in the future, if any.
 	)
 }
 
@@ -478,6 +479,7 @@ type GossipOut interface {
 	GossipTopicInfo
 	SignAndPublishL2Payload(ctx context.Context, msg *eth.ExecutionPayloadEnvelope, signer Signer) error
 	PublishSignedL2Payload(ctx context.Context, signedEnvelope *opsigner.SignedExecutionPayloadEnvelope) error
+	PublishPreconfirmation(ctx context.Context, preconf *opsigner.SignedPreconfirmation) error
 	Close() error
 }
 
@@ -487,12 +489,15 @@ type blockTopic struct {
 	// block events handler, to be cancelled before closing the blocks topic.
 	events *pubsub.TopicEventHandler
 	// block subscriptions, to be cancelled before closing blocks topic.
+	// Nil if the topic is only joined to publish to it.
 	sub *pubsub.Subscription
 }
 
 func (bt *blockTopic) Close() error {
 	bt.events.Cancel()
-	bt.sub.Cancel()
+	if bt.sub != nil {
+		bt.sub.Cancel()
+	}
 	return bt.topic.Close()
 }
 
@@ -510,6 +515,8 @@ type publisher struct {
 	blocksV3 *blockTopic
 	blocksV4 *blockTopic
 
+	preconfs *blockTopic
+
 	runCfg GossipRuntimeConfig
 }
 
@@ -631,7 +638,8 @@ func (p *publisher) Close() error {
 	p.p2pCancel()
 	e1 := p.blocksV1.Close()
 	e2 := p.blocksV2.Close()
-	return errors.Join(e1, e2)
+	e3 := p.preconfs.Close()
+	return errors.Join(e1, e2, e3)
 }
 
 func JoinGossip(self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Config, runCfg GossipRuntimeConfig, gossipIn GossipIn, gossipConf GossipSetupConfigurables, clk clock.Clock) (GossipOut, error) {
@@ -669,6 +677,17 @@ func JoinGossip(self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Con
 		return nil, fmt.Errorf("failed to setup blocks v4 p2p: %w", err)
 	}
 
+	// Only subscribe to preconfirmations if there is anything to receive them,
+	// but always join the topic, so the sequencer can publish them.
+	preconfIn, _ := gossipIn.(PreconfirmationsIn)
+	preconfLogger := log.New("topic", "preconfs")
+	preconfsValidator := guardGossipValidator(log, logValidationResult(self, "validated preconfirmation", preconfLogger, BuildPreconfirmationsValidator(preconfLogger, cfg, runCfg)))
+	preconfs, err := newPreconfirmationsTopic(p2pCtx, preconfirmationsTopicV1(cfg), ps, preconfLogger, preconfIn, preconfsValidator)
+	if err != nil {
+		p2pCancel()
+		return nil, fmt.Errorf("failed to setup preconfirmations p2p: %w", err)
+	}
+
 	return &publisher{
 		log:       log,
 		cfg:       cfg,
@@ -677,6 +696,7 @@ func JoinGossip(self peer.ID, ps *pubsub.PubSub, log log.Logger, cfg *rollup.Con
 		blocksV2:  blocksV2,
 		blocksV3:  blocksV3,
 		blocksV4:  blocksV4,
+		preconfs:  preconfs,
 		runCfg:    runCfg,
 	}, nil
 }
@@ -219,6 +219,73 @@ func TestBlockValidator(t *testing.T) {
 	}
 }
 
+func TestPreconfirmationsValidator(t *testing.T) {
+	cfg := &rollup.Config{
+		L2ChainID: big.NewInt(100),
+	}
+	secrets, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := opsigner.NewLocalSigner(secrets)
+	peerID := peer.ID("foo")
+
+	preconf, err := opsigner.SignPreconfirmation(context.Background(), signer, eth.ChainIDFromBig(cfg.L2ChainID),
+		opsigner.Preconfirmation{TxHash: common.HexToHash("0x1234"), BlockNumber: 42})
+	require.NoError(t, err)
+	data, err := preconf.MarshalBinary()
+	require.NoError(t, err)
+
+	validate := func(t *testing.T, seq common.Address, data []byte) (pubsub.ValidationResult, *pubsub.Message) {
+		runCfg := &testutils.MockRuntimeConfig{P2PSeqAddress: seq}
+		validator := BuildPreconfirmationsValidator(testlog.Logger(t, log.LevelCrit), cfg, runCfg)
+		message := &pubsub.Message{Message: &pubsub_pb.Message{Data: snappy.Encode(nil, data)}}
+		return validator(context.Background(), peerID, message), message
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		res, message := validate(t, crypto.PubkeyToAddress(secrets.PublicKey), data)
+		require.Equal(t, pubsub.ValidationAccept, res)
+		require.Equal(t, preconf, message.ValidatorData)
+	})
+
+	t.Run("WrongSigner", func(t *testing.T) {
+		res, _ := validate(t, common.HexToAddress("0x1234"), data)
+		require.Equal(t, pubsub.ValidationReject, res)
+	})
+
+	t.Run("NoSequencer", func(t *testing.T) {
+		res, _ := validate(t, common.Address{}, data)
+		require.Equal(t, pubsub.ValidationIgnore, res)
+	})
+
+	t.Run("WrongSize", func(t *testing.T) {
+		res, _ := validate(t, crypto.PubkeyToAddress(secrets.PublicKey), data[:len(data)-1])
+		require.Equal(t, pubsub.ValidationReject, res)
+	})
+
+	t.Run("ModifiedBlockNumber", func(t *testing.T) {
+		modified := bytes.Clone(data)
+		modified[len(modified)-1] ^= 1
+		res, _ := validate(t, crypto.PubkeyToAddress(secrets.PublicKey), modified)
+		require.Equal(t, pubsub.ValidationReject, res)
+	})
+
+	t.Run("BlockSignature", func(t *testing.T) {
+		raw, err := preconf.Preconfirmation.MarshalBinary()
+		require.NoError(t, err)
+		sig, err := signer.SignBlockV1(context.Background(), eth.ChainIDFromBig(cfg.L2ChainID), opsigner.PayloadHash(raw))
+		require.NoError(t, err)
+		res, _ := validate(t, crypto.PubkeyToAddress(secrets.PublicKey), append(sig[:], raw...))
+		require.Equal(t, pubsub.ValidationReject, res, "preconfirmations signed in the block signing domain are rejected")
+	})
+
+	t.Run("UnknownDomain", func(t *testing.T) {
+		modified := bytes.Clone(data)
+		copy(modified[65:97], make([]byte, 32))
+		res, _ := validate(t, crypto.PubkeyToAddress(secrets.PublicKey), modified)
+		require.Equal(t, pubsub.ValidationReject, res)
+	})
+}
+
 // TestGossipTimestampThreshold tests that the configurable timestamp threshold works correctly
 func TestGossipTimestampThreshold(t *testing.T) {
 	cfg := &rollup.Config{
@@ -132,7 +132,7 @@ func (n *NodeP2P) init(
 	}
 	// Activate the P2P req-resp sync if enabled by feature-flag.
 	if setup.ReqRespSyncEnabled() {
-		n.syncCl = NewSyncClient(log, rollupCfg, n.host, gossipIn.OnUnsafeL2Payload, metrics, n.appScorer)
+		n.syncCl = NewSyncClient(log, rollupCfg, n.host, gossipIn.OnUnsafeL2Payload, metrics, n.appScorer, setup.ReqRespRangeSyncEnabled())
 		n.host.Network().Notify(&network.NotifyBundle{
 			ConnectedF: func(nw network.Network, conn network.Conn) {
 				n.syncCl.AddPeer(conn.RemotePeer())
@@ -154,6 +154,8 @@ func (n *NodeP2P) init(
 			// register the sync protocol with libp2p host
 			payloadByNumber := MakeStreamHandler(resourcesCtx, log.New("serve", "payloads_by_number"), n.syncSrv.HandleSyncRequest)
 			n.host.SetStreamHandler(PayloadByNumberProtocolID(rollupCfg.L2ChainID), payloadByNumber)
+			payloadsByRange := MakeStreamHandler(resourcesCtx, log.New("serve", "payloads_by_range"), n.syncSrv.HandleRangeSyncRequest)
+			n.host.SetStreamHandler(PayloadsByRangeProtocolID(rollupCfg.L2ChainID), payloadsByRange)
 		}
 	}
 	n.scorer = NewScorer(eps, metrics, n.appScorer, log)
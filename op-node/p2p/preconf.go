@@ -0,0 +1,129 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/snappy"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
+)
+
+// signedPreconfirmationSize is the size of a gossiped preconfirmation: the signature and the preconfirmation.
+const signedPreconfirmationSize = 65 + opsigner.PreconfirmationSize
+
+func preconfirmationsTopicV1(cfg *rollup.Config) string {
+	return fmt.Sprintf("/optimism/%s/0/preconfs", cfg.L2ChainID.String())
+}
+
+// PreconfirmationsIn receives the preconfirmations of the sequencer from gossip.
+// The preconfirmations topic is only subscribed to if the GossipIn implements it.
+type PreconfirmationsIn interface {
+	OnPreconfirmation(ctx context.Context, from peer.ID, msg *opsigner.SignedPreconfirmation) error
+}
+
+func BuildPreconfirmationsValidator(log log.Logger, cfg *rollup.Config, runCfg GossipRuntimeConfig) pubsub.ValidatorEx {
+	return func(ctx context.Context, id peer.ID, message *pubsub.Message) pubsub.ValidationResult {
+		// [REJECT] if the compression is not valid, or the size does not match
+		outLen, err := snappy.DecodedLen(message.Data)
+		if err != nil || outLen != signedPreconfirmationSize {
+			log.Warn("invalid preconfirmation size", "decoded_length", outLen, "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+		data, err := snappy.Decode(nil, message.Data)
+		if err != nil {
+			log.Warn("invalid snappy compression", "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+
+		// [REJECT] if the preconfirmation encoding is not valid
+		var preconf opsigner.SignedPreconfirmation
+		if err := preconf.UnmarshalBinary(data); err != nil {
+			log.Warn("invalid preconfirmation", "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+
+		// [REJECT] if the signature by the sequencer is not valid
+		auth := &opsigner.OPStackP2PBlockAuthV1{
+			Allowed: runCfg.P2PSequencerAddress(),
+			Chain:   eth.ChainIDFromBig(cfg.L2ChainID),
+		}
+		if auth.Allowed == (common.Address{}) {
+			log.Warn("no configured p2p sequencer address, ignoring gossiped preconfirmation", "peer", id)
+			return pubsub.ValidationIgnore
+		}
+		if err := preconf.VerifySignature(auth); err != nil {
+			log.Warn("invalid preconfirmation signature", "err", err, "peer", id)
+			return pubsub.ValidationReject
+		}
+
+		message.ValidatorData = &preconf
+		return pubsub.ValidationAccept
+	}
+}
+
+func PreconfirmationsHandler(onPreconf func(ctx context.Context, from peer.ID, msg *opsigner.SignedPreconfirmation) error) MessageHandler {
+	return func(ctx context.Context, from peer.ID, msg any) error {
+		preconf, ok := msg.(*opsigner.SignedPreconfirmation)
+		if !ok {
+			return fmt.Errorf("expected topic validator to parse and validate data into preconfirmation, but got %T", msg)
+		}
+		return onPreconf(ctx, from, preconf)
+	}
+}
+
+// newPreconfirmationsTopic joins the preconfirmations topic, to publish to it,
+// and subscribes to it if preconfIn is not nil.
+func newPreconfirmationsTopic(ctx context.Context, topicId string, ps *pubsub.PubSub, log log.Logger, preconfIn PreconfirmationsIn, validator pubsub.ValidatorEx) (*blockTopic, error) {
+	err := ps.RegisterTopicValidator(topicId,
+		validator,
+		pubsub.WithValidatorTimeout(3*time.Second),
+		pubsub.WithValidatorConcurrency(4))
+	if err != nil {
+		return nil, fmt.Errorf("failed to register gossip topic: %w", err)
+	}
+
+	topic, err := ps.Join(topicId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join gossip topic: %w", err)
+	}
+
+	events, err := topic.EventHandler()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create preconfirmations gossip topic handler: %w", err)
+	}
+
+	go LogTopicEvents(ctx, log, events)
+
+	out := &blockTopic{topic: topic, events: events}
+	if preconfIn == nil {
+		return out, nil
+	}
+	out.sub, err = topic.Subscribe()
+	if err != nil {
+		events.Cancel()
+		err = errors.Join(err, topic.Close())
+		return nil, fmt.Errorf("failed to subscribe to preconfirmations gossip topic: %w", err)
+	}
+
+	subscriber := MakeSubscriber(log, PreconfirmationsHandler(preconfIn.OnPreconfirmation))
+	go subscriber(ctx, out.sub)
+	return out, nil
+}
+
+func (p *publisher) PublishPreconfirmation(ctx context.Context, preconf *opsigner.SignedPreconfirmation) error {
+	data, err := preconf.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode preconfirmation to publish: %w", err)
+	}
+	return p.preconfs.topic.Publish(ctx, snappy.Encode(nil, data))
+}
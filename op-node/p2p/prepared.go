@@ -25,6 +25,7 @@ type Prepared struct {
 	UDPv5     *discover.UDPv5
 
 	EnableReqRespSync bool
+	EnableRangeSync   bool
 }
 
 var _ SetupP2P = (*Prepared)(nil)
@@ -93,6 +94,10 @@ func (p *Prepared) ReqRespSyncEnabled() bool {
 	return p.EnableReqRespSync
 }
 
+func (p *Prepared) ReqRespRangeSyncEnabled() bool {
+	return p.EnableRangeSync
+}
+
 func (p *Prepared) GetGossipTimestampThreshold() time.Duration {
 	return 60 * time.Second
 }
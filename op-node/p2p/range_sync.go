@@ -0,0 +1,547 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"golang.org/x/time/rate"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+// The payloads_by_range protocol serves a batch of consecutive payloads per request,
+// so a syncing node can fetch a range from several peers in parallel, instead of one payload per request.
+const (
+	// maxRangeBatchSize is the maximum number of payloads requested, and served, in a single range request.
+	maxRangeBatchSize = 64
+	// minRangeBatchSize is the number of payloads requested from peers without a known throughput.
+	minRangeBatchSize = 8
+	// rangeBatchTargetDuration is the time a range request should take, given the throughput of the peer.
+	rangeBatchTargetDuration = 2 * time.Second
+	// maxRangeSyncPending limits the number of payloads in-flight and in quarantine,
+	// so range results are not evicted from quarantine before they can be promoted.
+	maxRangeSyncPending = 512
+	// rangeSyncQuarantineSize is the quarantine size when range sync is enabled.
+	rangeSyncQuarantineSize = 1024
+	// rangeUnsupportedBackoff is how long to fall back to payload_by_number requests
+	// after failing to open a range request stream to a peer.
+	rangeUnsupportedBackoff = 10 * time.Minute
+	// Do not serve more than 200 range payloads per second
+	globalServerRangeBlocksRateLimit rate.Limit = 200
+	// Allows a burst of 2x our rate limit
+	globalServerRangeBlocksBurst = 400
+	// Do not serve more than 64 range payloads per second to the same peer
+	peerServerRangeBlocksRateLimit rate.Limit = 64
+	// Allow a peer to request two full batches at once
+	peerServerRangeBlocksBurst = 2 * maxRangeBatchSize
+)
+
+func PayloadsByRangeProtocolID(l2ChainID *big.Int) protocol.ID {
+	return protocol.ID(fmt.Sprintf("/opstack/req/payloads_by_range/%d/0", l2ChainID))
+}
+
+// rangeSyncRequest is the payloads_by_range request: Count consecutive payloads, starting at block Start.
+// It is encoded as two little-endian uint64 values.
+type rangeSyncRequest struct {
+	Start uint64
+	Count uint64
+}
+
+// rangeBatch is a range of payloads scheduled for a single peer.
+type rangeBatch struct {
+	start      uint64
+	count      uint64
+	rangeReqId uint64
+}
+
+func (b rangeBatch) end() uint64 {
+	return b.start + b.count - 1
+}
+
+// rangePeer is the range sync state of a connected peer.
+type rangePeer struct {
+	id peer.ID
+	// batches holds the next batch the peer is scheduled to fetch. A peer with a queued batch is not idle.
+	batches chan rangeBatch
+	// rangeRL implements the same payload rate-limit as the server does per-peer.
+	rangeRL *rate.Limiter
+	// unsupportedUntil is set when the peer failed to open a range request stream,
+	// its batches are fetched through payload_by_number requests until then. Protected by the SyncClient peersLock.
+	unsupportedUntil time.Time
+	// closed is set when the peer loop exits, no batches are scheduled after that. Protected by the SyncClient peersLock.
+	closed bool
+}
+
+var errRangeStream = errors.New("failed to open range request stream")
+
+// onRangeRequestBatched is the range sync counterpart of onRangeRequest.
+// It is exclusively called by the main loop, and has thus direct access to the request bookkeeping state.
+// The range is split into batches from high to low, and each batch is assigned to an idle peer,
+// picking the peers with the highest throughput first, and sizing the batches by the throughput of the peer.
+// The results are verified against the trusted sync target, from high to low, the same as payload_by_number results.
+func (s *SyncClient) onRangeRequestBatched(ctx context.Context, req rangeRequest) {
+	log := s.log.New("target", req.start, "end", req.end)
+	log.Info("processing L2 range request", "rangeReqId", req.id)
+
+	// add req head to trusted set of blocks
+	s.trusted.Add(req.end.Hash, struct{}{})
+	s.trusted.Add(req.end.ParentHash, struct{}{})
+
+	// skip returns whether the block is already buffered or being fetched.
+	skip := func(num uint64) bool {
+		if h, ok := s.quarantineByNum[num]; ok {
+			if s.trusted.Contains(h) { // if we trust it, try to promote it.
+				s.tryPromote(h)
+			}
+			return true
+		}
+		return s.inFlight.get(num)
+	}
+
+	num := req.end.Number - 1
+	for _, p := range s.idleRangePeers() {
+		for num > req.start && skip(num) {
+			num--
+		}
+		if num <= req.start {
+			return
+		}
+		size := s.rangeBatchSize(p.id)
+		if pending := uint64(s.inFlight.len() + s.quarantine.Len()); pending+size > maxRangeSyncPending {
+			log.Info("too many pending P2P sync results, not scheduling more range requests", "current", num, "pending", pending)
+			return
+		}
+		high := num
+		for num > req.start && high-num < size && !skip(num) {
+			num--
+		}
+		batch := rangeBatch{start: num + 1, count: high - num, rangeReqId: req.id}
+		if ctx.Err() != nil {
+			log.Info("did not schedule full P2P sync range", "current", high, "err", ctx.Err())
+			return
+		}
+		// mark the batch as in-flight before the peer can pick it up, and possibly fail it already
+		s.setInFlight(batch.start, batch.count)
+		if s.scheduleBatch(p, batch) {
+			log.Debug("Scheduling P2P range request", "start", batch.start, "end", batch.end(), "peer", p.id, "rangeReqId", req.id)
+		} else { // the peer got busy or was removed in the meantime, try the next peer
+			s.clearInFlight(batch.start, batch.count)
+			num = high
+		}
+	}
+	if num > req.start {
+		log.Info("no peers ready to handle range requests for more P2P requests for L2 block history", "current", num)
+	}
+}
+
+// scheduleBatch queues the batch for the peer, and returns false if the peer is busy or its loop exited.
+// The peer loop marks the peer closed under the same lock before draining its queue,
+// so a queued batch is always either picked up or drained, and cleared from in-flight.
+func (s *SyncClient) scheduleBatch(p *rangePeer, b rangeBatch) bool {
+	s.peersLock.Lock()
+	defer s.peersLock.Unlock()
+	if p.closed {
+		return false
+	}
+	select {
+	case p.batches <- b:
+		return true
+	default:
+		return false
+	}
+}
+
+// idleRangePeers returns the peers without a scheduled batch, ordered by priority:
+// peers without a known throughput first, so their throughput gets measured, then by descending throughput.
+func (s *SyncClient) idleRangePeers() []*rangePeer {
+	s.peersLock.Lock()
+	peers := make([]*rangePeer, 0, len(s.rangePeers))
+	for id, p := range s.rangePeers {
+		if s.syncOnlyReqToStatic && !s.extra.IsStatic(id) {
+			continue
+		}
+		if len(p.batches) == 0 {
+			peers = append(peers, p)
+		}
+	}
+	s.peersLock.Unlock()
+
+	type scored struct {
+		p     *rangePeer
+		t     float64
+		known bool
+	}
+	scoredPeers := make([]scored, len(peers))
+	for i, p := range peers {
+		t, known := s.appScorer.Throughput(p.id)
+		scoredPeers[i] = scored{p: p, t: t, known: known}
+	}
+	slices.SortFunc(scoredPeers, func(a, b scored) int {
+		if a.known != b.known {
+			if !a.known {
+				return -1
+			}
+			return 1
+		}
+		if a.t > b.t {
+			return -1
+		} else if a.t < b.t {
+			return 1
+		}
+		return 0
+	})
+	for i, sp := range scoredPeers {
+		peers[i] = sp.p
+	}
+	return peers
+}
+
+// rangeBatchSize returns the number of payloads to request from the peer,
+// such that the request takes about rangeBatchTargetDuration given the throughput of the peer.
+func (s *SyncClient) rangeBatchSize(id peer.ID) uint64 {
+	t, ok := s.appScorer.Throughput(id)
+	if !ok {
+		return minRangeBatchSize
+	}
+	size := uint64(t * rangeBatchTargetDuration.Seconds())
+	return min(max(size, minRangeBatchSize), maxRangeBatchSize)
+}
+
+// rangeSupported returns whether range requests can be made to the peer.
+func (s *SyncClient) rangeSupported(p *rangePeer) bool {
+	s.peersLock.Lock()
+	defer s.peersLock.Unlock()
+	return time.Now().After(p.unsupportedUntil)
+}
+
+func (s *SyncClient) markRangeUnsupported(p *rangePeer) {
+	s.peersLock.Lock()
+	defer s.peersLock.Unlock()
+	p.unsupportedUntil = time.Now().Add(rangeUnsupportedBackoff)
+}
+
+// onRangeBatch fetches a batch scheduled for the peer. It only returns an error if the peer loop should exit.
+func (s *SyncClient) onRangeBatch(ctx context.Context, log log.Logger, rl *rate.Limiter, p *rangePeer, b rangeBatch) error {
+	if !s.activeRangeRequests.get(b.rangeReqId) {
+		log.Debug("dropping cancelled p2p range sync request", "start", b.start, "end", b.end())
+		s.clearInFlight(b.start, b.count)
+		return nil
+	}
+
+	if !s.rangeSupported(p) {
+		return s.fetchBatchByNumber(ctx, log, rl, p.id, b)
+	}
+
+	if err := s.globalRangeRL.WaitN(ctx, int(b.count)); err != nil {
+		s.clearInFlight(b.start, b.count)
+		return err
+	}
+	if err := p.rangeRL.WaitN(ctx, int(b.count)); err != nil {
+		s.clearInFlight(b.start, b.count)
+		return err
+	}
+
+	start := time.Now()
+	resultCode := ResultCodeSuccess
+	var n int
+	err := panicGuard(func(ctx context.Context, id peer.ID, b rangeBatch) (err error) {
+		n, err = s.doRangeRequest(ctx, id, b)
+		return err
+	})(ctx, p.id, b)
+	took := time.Since(start)
+	if errors.Is(err, errRangeStream) {
+		log.Info("peer does not serve range requests, falling back to payload_by_number requests", "err", err)
+		s.markRangeUnsupported(p)
+		return s.fetchBatchByNumber(ctx, log, rl, p.id, b)
+	}
+	// everything that was not received will have to be requested again
+	s.clearInFlight(b.start+uint64(n), b.count-uint64(n))
+	s.appScorer.onRangeResponse(p.id, n, took)
+	if err != nil {
+		log.Warn("failed p2p range sync request", "start", b.start, "end", b.end(), "received", n, "err", err)
+		resultCode = ResultCodeNotFoundErr
+		sendResponseError := true
+		var re requestResultErr
+		if errors.As(err, &re) {
+			resultCode = re.ResultCode()
+			if resultCode == ResultCodeNotFoundErr {
+				log.Warn("cancelling p2p sync range request", "rangeReqId", b.rangeReqId)
+				s.activeRangeRequests.delete(b.rangeReqId)
+				sendResponseError = false // don't penalize peer for this error
+			}
+		}
+		if sendResponseError {
+			s.appScorer.onResponseError(p.id)
+		}
+		// If we hit an error, then back off from this peer for a while.
+		if err := p.rangeRL.WaitN(ctx, clientErrRateCost); err != nil {
+			return err
+		}
+	} else {
+		log.Debug("completed p2p range sync request", "start", b.start, "end", b.end(), "received", n)
+		s.appScorer.onValidResponse(p.id)
+	}
+	s.metrics.ClientPayloadsByRangeEvent(b.start, n, resultCode, took)
+	return nil
+}
+
+// fetchBatchByNumber fetches a batch one payload at a time through payload_by_number requests, from high to low.
+func (s *SyncClient) fetchBatchByNumber(ctx context.Context, log log.Logger, rl *rate.Limiter, id peer.ID, b rangeBatch) error {
+	for i := uint64(0); i < b.count; i++ {
+		num := b.end() - i
+		// the remaining blocks below num are no longer in-flight if we exit early
+		if err := s.globalRL.Wait(ctx); err != nil {
+			s.clearInFlight(b.start, b.count-i)
+			return err
+		}
+		if err := rl.Wait(ctx); err != nil {
+			s.clearInFlight(b.start, b.count-i)
+			return err
+		}
+		if err := s.onPeerRequest(ctx, log, rl, id, peerRequest{num: num, rangeReqId: b.rangeReqId}); err != nil {
+			s.clearInFlight(b.start, b.count-i-1)
+			return err
+		}
+	}
+	return nil
+}
+
+// setInFlight marks count blocks, starting at start, as in-flight.
+func (s *SyncClient) setInFlight(start, count uint64) {
+	for i := uint64(0); i < count; i++ {
+		s.inFlight.set(start+i, true)
+	}
+}
+
+// clearInFlight marks count blocks, starting at start, as no longer in-flight.
+func (s *SyncClient) clearInFlight(start, count uint64) {
+	for i := uint64(0); i < count; i++ {
+		s.inFlight.delete(start + i)
+	}
+}
+
+// doRangeRequest requests the batch from the peer, and returns the number of payloads received.
+// The payloads are verified to form a chain, and passed on as sync results from high to low,
+// so they can be promoted as soon as the highest payload is trusted.
+// The peer may serve a prefix of the batch, e.g. if it does not have the higher blocks yet.
+func (s *SyncClient) doRangeRequest(ctx context.Context, id peer.ID, b rangeBatch) (int, error) {
+	// open stream to peer
+	reqCtx, reqCancel := context.WithTimeout(ctx, streamTimeout)
+	str, err := s.newStreamFn(reqCtx, id, s.payloadsByRange)
+	reqCancel()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errRangeStream, err)
+	}
+	defer str.Close()
+	// set write timeout (if available)
+	_ = str.SetWriteDeadline(time.Now().Add(clientWriteRequestTimeout))
+	if err := binary.Write(str, binary.LittleEndian, rangeSyncRequest{Start: b.start, Count: b.count}); err != nil {
+		return 0, fmt.Errorf("failed to write range request (%d-%d): %w", b.start, b.end(), err)
+	}
+	if err := str.CloseWrite(); err != nil {
+		return 0, fmt.Errorf("failed to close writer side while making request: %w", err)
+	}
+
+	envelopes := make([]*eth.ExecutionPayloadEnvelope, 0, b.count)
+	for i := uint64(0); i < b.count; i++ {
+		// set read timeout per payload (if available)
+		_ = str.SetReadDeadline(time.Now().Add(clientReadResponsetimeout))
+		envelope, err := s.readRangeResponseChunk(str, b.start+i)
+		if errors.Is(err, io.EOF) && i > 0 {
+			break // the peer served a prefix of the batch
+		}
+		var re requestResultErr
+		if errors.As(err, &re) && i > 0 {
+			break // the peer failed to serve the rest of the batch, but the prefix is still useful
+		}
+		if err != nil {
+			return 0, err
+		}
+		if i > 0 && envelope.ExecutionPayload.ParentHash != envelopes[i-1].ExecutionPayload.BlockHash {
+			return 0, fmt.Errorf("received execution payload %s does not build on previous payload %s",
+				envelope.ExecutionPayload.ID(), envelopes[i-1].ExecutionPayload.ID())
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	_ = str.CloseRead()
+
+	for i := len(envelopes) - 1; i >= 0; i-- {
+		select {
+		case s.results <- syncResult{payload: envelopes[i], peer: id}:
+		case <-ctx.Done():
+			// the payloads that were passed on are cleared from in-flight when they are processed
+			return 0, fmt.Errorf("failed to process response, sync client is too busy")
+		}
+	}
+	return len(envelopes), nil
+}
+
+// readRangeResponseChunk reads a single payload of a range response:
+// a result code byte, followed by a little-endian uint32 version, a little-endian uint32 length,
+// and the snappy block-compressed SSZ payload of that length.
+func (s *SyncClient) readRangeResponseChunk(r io.Reader, expectedBlockNum uint64) (*eth.ExecutionPayloadEnvelope, error) {
+	var result [1]byte
+	if _, err := io.ReadFull(r, result[:]); err != nil {
+		return nil, fmt.Errorf("failed to read result part of response: %w", err)
+	}
+	if res := result[0]; res != 0 {
+		return nil, requestResultErr(res)
+	}
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("failed to read header part of response: %w", err)
+	}
+	version := binary.LittleEndian.Uint32(header[:4])
+	length := binary.LittleEndian.Uint32(header[4:])
+	if length > maxGossipSize {
+		return nil, fmt.Errorf("response payload of %d bytes exceeds limit of %d bytes", length, maxGossipSize)
+	}
+	compressed := make([]byte, length)
+	if _, err := io.ReadFull(r, compressed); err != nil {
+		return nil, fmt.Errorf("failed to read response payload: %w", err)
+	}
+	if n, err := snappy.DecodedLen(compressed); err != nil {
+		return nil, fmt.Errorf("invalid compressed response payload: %w", err)
+	} else if n > maxGossipSize {
+		return nil, fmt.Errorf("decompressed response payload of %d bytes exceeds limit of %d bytes", n, maxGossipSize)
+	}
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response payload: %w", err)
+	}
+
+	isCanyon := s.cfg.IsCanyon(s.cfg.TimestampForBlock(expectedBlockNum))
+	isIsthmus := s.cfg.IsIsthmus(s.cfg.TimestampForBlock(expectedBlockNum))
+	envelope, err := readExecutionPayload(version, data, isCanyon, isIsthmus)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyBlock(envelope, expectedBlockNum); err != nil {
+		return nil, fmt.Errorf("received execution payload is invalid: %w", err)
+	}
+	return envelope, nil
+}
+
+// HandleRangeSyncRequest is a stream handler function to register the L2 unsafe payloads range-sync protocol.
+// See MakeStreamHandler to transform this into a LibP2P handler function.
+//
+// The caller must Close the stream.
+func (srv *ReqRespServer) HandleRangeSyncRequest(ctx context.Context, log log.Logger, stream network.Stream) {
+	start := time.Now()
+
+	// Serving a range request may be throttled for longer than a single payload request, since it covers more payloads.
+	ctx, cancel := context.WithTimeout(ctx, maxThrottleDelay)
+	req, served, err := srv.handleRangeSyncRequest(ctx, stream)
+	cancel()
+
+	resultCode := ResultCodeSuccess
+	if err != nil {
+		log.Warn("failed to serve p2p range sync request", "start", req.Start, "count", req.Count, "served", served, "err", err)
+		if errors.Is(err, ethereum.NotFound) {
+			resultCode = ResultCodeNotFoundErr
+		} else if errors.Is(err, errInvalidRequest) {
+			resultCode = ResultCodeInvalidErr
+		} else {
+			resultCode = ResultCodeUnknownErr
+		}
+		// try to write error code in place of the next payload, so the other peer can understand the reason for failure.
+		_, _ = stream.Write([]byte{resultCode})
+	} else {
+		log.Debug("successfully served range sync response", "start", req.Start, "count", req.Count, "served", served)
+	}
+	srv.metrics.ServerPayloadsByRangeEvent(req.Start, served, resultCode, time.Since(start))
+}
+
+func (srv *ReqRespServer) handleRangeSyncRequest(ctx context.Context, stream network.Stream) (rangeSyncRequest, int, error) {
+	var req rangeSyncRequest
+
+	// Set read deadline, if available
+	_ = stream.SetReadDeadline(time.Now().Add(serverReadRequestTimeout))
+	if err := binary.Read(stream, binary.LittleEndian, &req); err != nil {
+		return req, 0, fmt.Errorf("failed to read requested range: %w", err)
+	}
+	if err := stream.CloseRead(); err != nil {
+		return req, 0, fmt.Errorf("failed to close reading-side of a P2P range sync request call: %w", err)
+	}
+
+	// Check the request is within the expected range of blocks
+	if req.Count == 0 || req.Count > maxRangeBatchSize {
+		return req, 0, fmt.Errorf("cannot serve range request for %d blocks, max is %d: %w", req.Count, maxRangeBatchSize, errInvalidRequest)
+	}
+	if req.Start < srv.cfg.Genesis.L2.Number {
+		return req, 0, fmt.Errorf("cannot serve request for L2 block %d before genesis %d: %w", req.Start, srv.cfg.Genesis.L2.Number, errInvalidRequest)
+	}
+	max, err := srv.cfg.TargetBlockNumber(uint64(time.Now().Unix()))
+	if err != nil {
+		return req, 0, fmt.Errorf("cannot determine max target block number to verify request: %w", errInvalidRequest)
+	}
+	if req.Start > max {
+		return req, 0, fmt.Errorf("cannot serve request for L2 block %d after max expected block (%v): %w", req.Start, max, errInvalidRequest)
+	}
+	count := min(req.Count, max-req.Start+1)
+
+	// take tokens from the global and peer rate-limiters for every payload to serve,
+	// to make sure there's not too much concurrent server work between different peers.
+	if err := srv.globalRangeBlocksRL.WaitN(ctx, int(count)); err != nil {
+		return req, 0, fmt.Errorf("timed out waiting for global range sync rate limit: %w", err)
+	}
+	if err := srv.getPeerStat(stream.Conn().RemotePeer()).RangeBlocks.WaitN(ctx, int(count)); err != nil {
+		return req, 0, fmt.Errorf("timed out waiting for peer range sync rate limit: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for i := uint64(0); i < count; i++ {
+		num := req.Start + i
+		envelope, err := srv.l2.PayloadByNumber(ctx, num)
+		if errors.Is(err, ethereum.NotFound) && i > 0 {
+			// serve what we have, the peer will request the rest elsewhere
+			return req, int(i), nil
+		} else if err != nil {
+			return req, int(i), fmt.Errorf("failed to retrieve payload %d to serve to peer: %w", num, err)
+		}
+
+		buf.Reset()
+		var version uint32
+		if srv.cfg.IsEcotone(uint64(envelope.ExecutionPayload.Timestamp)) {
+			version = 1
+			_, err = envelope.MarshalSSZ(&buf)
+		} else {
+			_, err = envelope.ExecutionPayload.MarshalSSZ(&buf)
+		}
+		if err != nil {
+			return req, int(i), fmt.Errorf("failed to encode payload %d: %w", num, err)
+		}
+		compressed := snappy.Encode(nil, buf.Bytes())
+
+		// 0 - resultCode: success = 0
+		// 1:5 - version (little endian)
+		// 5:9 - length of the compressed payload (little endian)
+		var header [9]byte
+		binary.LittleEndian.PutUint32(header[1:5], version)
+		binary.LittleEndian.PutUint32(header[5:9], uint32(len(compressed)))
+
+		// We set write deadline, if available, to safely write without blocking on a throttling peer connection
+		_ = stream.SetWriteDeadline(time.Now().Add(serverWriteChunkTimeout))
+		if _, err := stream.Write(header[:]); err != nil {
+			return req, int(i), fmt.Errorf("failed to write response header data: %w", err)
+		}
+		if _, err := stream.Write(compressed); err != nil {
+			return req, int(i), fmt.Errorf("failed to write payload to range sync response: %w", err)
+		}
+	}
+	return req, int(count), nil
+}
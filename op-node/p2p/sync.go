@@ -148,8 +148,15 @@ func (r *requestIdMap) delete(key uint64) {
 	r.mu.Unlock()
 }
 
+func (r *requestIdMap) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
 type SyncClientMetrics interface {
 	ClientPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration)
+	ClientPayloadsByRangeEvent(start uint64, count int, resultCode byte, duration time.Duration)
 	PayloadsQuarantineSize(n int)
 }
 
@@ -157,6 +164,8 @@ type SyncPeerScorer interface {
 	onValidResponse(id peer.ID)
 	onResponseError(id peer.ID)
 	onRejectedPayload(id peer.ID)
+	onRangeResponse(id peer.ID, payloads int, took time.Duration)
+	Throughput(id peer.ID) (float64, bool)
 }
 
 // SyncClient implements a reverse chain sync with a minimal interface:
@@ -223,6 +232,19 @@ type SyncPeerScorer interface {
 // If the user does sync a long range of blocks through this mechanism,
 // it does end up traversing through the chain, but receives the blocks in reverse order.
 // It is up to the user to persist the blocks for later processing, or drop & resync them if persistence is limited.
+//
+// ### Range sync
+//
+// With range sync enabled, the range is not divided by block number, but into batches of consecutive blocks,
+// which are fetched with the payloads_by_range protocol, many payloads per request:
+//   - Batches are assigned to idle peers, from high to low, picking the peers with the highest throughput first.
+//     Peers without measured throughput are tried first, with a small batch, to measure their throughput.
+//   - The batch size of a peer is scaled to its throughput, so every request takes about the same time.
+//   - Payloads of a batch must form a chain; they are passed on to the main loop from high to low,
+//     where they are verified and promoted against the trusted sync target like any other result.
+//   - The number of in-flight and quarantined payloads is bounded, so results are not evicted before promotion.
+//   - Peers that do not support the range protocol are sent the same batches,
+//     but fetch them through payload_by_number requests.
 type SyncClient struct {
 	log log.Logger
 
@@ -233,10 +255,14 @@ type SyncClient struct {
 
 	newStreamFn     newStreamFn
 	payloadByNumber protocol.ID
+	payloadsByRange protocol.ID
 
 	peersLock sync.Mutex
 	// syncing worker per peer
 	peers map[peer.ID]context.CancelFunc
+	// range sync state per peer, only used if rangeSync is enabled
+	rangePeers map[peer.ID]*rangePeer
+	rangeSync  bool
 
 	// trusted blocks are, or have been, canonical at one point.
 	// Everything that's trusted is acceptable to pass to the sync receiver,
@@ -265,6 +291,8 @@ type SyncClient struct {
 
 	// Global rate limiter for all peers.
 	globalRL *rate.Limiter
+	// Global rate limiter of range request payloads for all peers.
+	globalRangeRL *rate.Limiter
 
 	// resource context: all peers and mainLoop tasks inherit this, and start shutting down once resCancel() is called.
 	resCtx    context.Context
@@ -281,7 +309,7 @@ type SyncClient struct {
 	syncOnlyReqToStatic bool
 }
 
-func NewSyncClient(log log.Logger, cfg *rollup.Config, host HostNewStream, rcv receivePayloadFn, metrics SyncClientMetrics, appScorer SyncPeerScorer) *SyncClient {
+func NewSyncClient(log log.Logger, cfg *rollup.Config, host HostNewStream, rcv receivePayloadFn, metrics SyncClientMetrics, appScorer SyncPeerScorer, rangeSync bool) *SyncClient {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	c := &SyncClient{
@@ -291,7 +319,10 @@ func NewSyncClient(log log.Logger, cfg *rollup.Config, host HostNewStream, rcv r
 		appScorer:           appScorer,
 		newStreamFn:         host.NewStream,
 		payloadByNumber:     PayloadByNumberProtocolID(cfg.L2ChainID),
+		payloadsByRange:     PayloadsByRangeProtocolID(cfg.L2ChainID),
 		peers:               make(map[peer.ID]context.CancelFunc),
+		rangePeers:          make(map[peer.ID]*rangePeer),
+		rangeSync:           rangeSync,
 		quarantineByNum:     make(map[uint64]common.Hash),
 		rangeRequests:       make(chan rangeRequest), // blocking
 		activeRangeRequests: newRequestIdMap(),
@@ -300,6 +331,7 @@ func NewSyncClient(log log.Logger, cfg *rollup.Config, host HostNewStream, rcv r
 		inFlight:            newRequestIdMap(),
 		inFlightChecks:      make(chan inFlightCheck, 128),
 		globalRL:            rate.NewLimiter(globalServerBlocksRateLimit, globalServerBlocksBurst),
+		globalRangeRL:       rate.NewLimiter(globalServerRangeBlocksRateLimit, globalServerRangeBlocksBurst),
 		resCtx:              ctx,
 		resCancel:           cancel,
 		receivePayload:      rcv,
@@ -312,7 +344,12 @@ func NewSyncClient(log log.Logger, cfg *rollup.Config, host HostNewStream, rcv r
 	// never errors with positive LRU cache size
 	// TODO: if we had an LRU based on on total payloads size, instead of payload count,
 	//  we can safely buffer more data in the happy case.
-	q, _ := simplelru.NewLRU[common.Hash, syncResult](100, c.onQuarantineEvict)
+	quarantineSize := 100
+	if rangeSync {
+		// range results arrive in batches, and need more room to wait for promotion
+		quarantineSize = rangeSyncQuarantineSize
+	}
+	q, _ := simplelru.NewLRU[common.Hash, syncResult](quarantineSize, c.onQuarantineEvict)
 	c.quarantine = q
 	trusted, _ := simplelru.NewLRU[common.Hash, struct{}](10000, nil)
 	c.trusted = trusted
@@ -340,7 +377,16 @@ func (s *SyncClient) AddPeer(id peer.ID) {
 	// add new peer routine
 	ctx, cancel := context.WithCancel(s.resCtx)
 	s.peers[id] = cancel
-	go s.peerLoop(ctx, id)
+	var rp *rangePeer
+	if s.rangeSync {
+		rp = &rangePeer{
+			id:      id,
+			batches: make(chan rangeBatch, 1),
+			rangeRL: rate.NewLimiter(peerServerRangeBlocksRateLimit, peerServerRangeBlocksBurst),
+		}
+		s.rangePeers[id] = rp
+	}
+	go s.peerLoop(ctx, id, rp)
 }
 
 func (s *SyncClient) RemovePeer(id peer.ID) {
@@ -356,6 +402,7 @@ func (s *SyncClient) RemovePeer(id peer.ID) {
 	}
 	cancel() // once loop exits
 	delete(s.peers, id)
+	delete(s.rangePeers, id)
 }
 
 // Close will shut down the sync client and all attached work, and block until shutdown is complete.
@@ -400,7 +447,11 @@ func (s *SyncClient) mainLoop() {
 		select {
 		case req := <-s.rangeRequests:
 			ctx, cancel := context.WithTimeout(s.resCtx, maxRequestScheduling)
-			s.onRangeRequest(ctx, req)
+			if s.rangeSync {
+				s.onRangeRequestBatched(ctx, req)
+			} else {
+				s.onRangeRequest(ctx, req)
+			}
 			cancel()
 		case res := <-s.results:
 			ctx, cancel := context.WithTimeout(s.resCtx, maxResultProcessing)
@@ -550,13 +601,27 @@ func (s *SyncClient) onResult(ctx context.Context, res syncResult) {
 }
 
 // peerLoop for syncing from a single peer
-func (s *SyncClient) peerLoop(ctx context.Context, id peer.ID) {
+func (s *SyncClient) peerLoop(ctx context.Context, id peer.ID, rp *rangePeer) {
 	defer func() {
 		s.peersLock.Lock()
 		delete(s.peers, id) // clean up
+		if rp != nil {
+			if s.rangePeers[id] == rp {
+				delete(s.rangePeers, id)
+			}
+			rp.closed = true
+		}
 		s.log.Debug("stopped syncing loop of peer", "id", id)
 		s.wg.Done()
 		s.peersLock.Unlock()
+		// a batch that was scheduled but not picked up anymore has to be requested again
+		if rp != nil {
+			select {
+			case b := <-rp.batches:
+				s.clearInFlight(b.start, b.count)
+			default:
+			}
+		}
 	}()
 
 	log := s.log.New("peer", id)
@@ -574,69 +639,123 @@ func (s *SyncClient) peerLoop(ctx context.Context, id peer.ID) {
 		// while sync-requests will block, the loop may still process other events (if added in the future).
 		peerRequests = nil
 	}
+	// range batches are assigned to this peer specifically by the main loop, which already applies onlyReqToStatic
+	var batches chan rangeBatch
+	if rp != nil {
+		batches = rp.batches
+	}
 
 	for {
-		// wait for a global allocation to be available
-		if err := s.globalRL.Wait(ctx); err != nil {
+		// range batches are rate-limited by the number of payloads in the batch
+		select {
+		case b := <-batches:
+			if err := s.onRangeBatch(ctx, log, rl, rp, b); err != nil {
+				return
+			}
+			continue
+		default:
+		}
+
+		// wait for a global allocation to be available, and for the peer to be available for more work.
+		// The allocation is only taken for a payload_by_number request: range batches are limited separately.
+		if err := waitTokenAvailable(ctx, s.globalRL); err != nil {
 			return
 		}
-		// wait for peer to be available for more work
-		if err := rl.Wait(ctx); err != nil {
+		if err := waitTokenAvailable(ctx, rl); err != nil {
 			return
 		}
 
 		// once the peer is available, wait for a sync request.
 		select {
 		case pr := <-peerRequests:
-			if !s.activeRangeRequests.get(pr.rangeReqId) {
-				log.Debug("dropping cancelled p2p sync request", "num", pr.num)
+			if err := s.globalRL.Wait(ctx); err != nil {
 				s.inFlight.delete(pr.num)
-				continue
+				return
 			}
-
-			// We already established the peer is available w.r.t. rate-limiting,
-			// and this is the only loop over this peer, so we can request now.
-			start := time.Now()
-
-			resultCode := ResultCodeSuccess
-			err := panicGuard(s.doRequest)(ctx, id, pr.num)
-			if err != nil {
+			if err := rl.Wait(ctx); err != nil {
 				s.inFlight.delete(pr.num)
-				log.Warn("failed p2p sync request", "num", pr.num, "err", err)
-				resultCode = ResultCodeNotFoundErr
-				sendResponseError := true
-
-				if re, ok := err.(requestResultErr); ok {
-					resultCode = re.ResultCode()
-					if resultCode == ResultCodeNotFoundErr {
-						log.Warn("cancelling p2p sync range request", "rangeReqId", pr.rangeReqId)
-						s.activeRangeRequests.delete(pr.rangeReqId)
-						sendResponseError = false // don't penalize peer for this error
-					}
-				}
-
-				if sendResponseError {
-					s.appScorer.onResponseError(id)
-				}
-
-				// If we hit an error, then count it as many requests.
-				// We'd like to avoid making more requests for a while, so back off.
-				if err := rl.WaitN(ctx, clientErrRateCost); err != nil {
-					return
-				}
-			} else {
-				log.Debug("completed p2p sync request", "num", pr.num)
-				s.appScorer.onValidResponse(id)
+				return
+			}
+			if err := s.onPeerRequest(ctx, log, rl, id, pr); err != nil {
+				return
+			}
+		case b := <-batches:
+			if err := s.onRangeBatch(ctx, log, rl, rp, b); err != nil {
+				return
 			}
-
-			took := time.Since(start)
-			s.metrics.ClientPayloadByNumberEvent(pr.num, resultCode, took)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// waitTokenAvailable waits until the rate-limiter has a token available, without taking it.
+func waitTokenAvailable(ctx context.Context, rl *rate.Limiter) error {
+	for {
+		tokens := rl.Tokens()
+		if tokens >= 1 {
+			return nil
+		}
+		delay := time.Duration((1 - tokens) / float64(rl.Limit()) * float64(time.Second))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// onPeerRequest fetches a single payload by number from the peer, after the rate-limits have been applied.
+// It only returns an error if the peer loop should exit.
+func (s *SyncClient) onPeerRequest(ctx context.Context, log log.Logger, rl *rate.Limiter, id peer.ID, pr peerRequest) error {
+	if !s.activeRangeRequests.get(pr.rangeReqId) {
+		log.Debug("dropping cancelled p2p sync request", "num", pr.num)
+		s.inFlight.delete(pr.num)
+		return nil
+	}
+
+	// We already established the peer is available w.r.t. rate-limiting,
+	// and this is the only loop over this peer, so we can request now.
+	start := time.Now()
+
+	resultCode := ResultCodeSuccess
+	err := panicGuard(s.doRequest)(ctx, id, pr.num)
+	if err != nil {
+		s.inFlight.delete(pr.num)
+		log.Warn("failed p2p sync request", "num", pr.num, "err", err)
+		resultCode = ResultCodeNotFoundErr
+		sendResponseError := true
+
+		if re, ok := err.(requestResultErr); ok {
+			resultCode = re.ResultCode()
+			if resultCode == ResultCodeNotFoundErr {
+				log.Warn("cancelling p2p sync range request", "rangeReqId", pr.rangeReqId)
+				s.activeRangeRequests.delete(pr.rangeReqId)
+				sendResponseError = false // don't penalize peer for this error
+			}
+		}
+
+		if sendResponseError {
+			s.appScorer.onResponseError(id)
+		}
+
+		// If we hit an error, then count it as many requests.
+		// We'd like to avoid making more requests for a while, so back off.
+		if err := rl.WaitN(ctx, clientErrRateCost); err != nil {
+			return err
+		}
+	} else {
+		log.Debug("completed p2p sync request", "num", pr.num)
+		s.appScorer.onValidResponse(id)
+	}
+
+	took := time.Since(start)
+	s.metrics.ClientPayloadByNumberEvent(pr.num, resultCode, took)
+	return nil
+}
+
 type requestResultErr byte
 
 func (r requestResultErr) Error() string {
@@ -781,6 +900,15 @@ func verifyBlock(envelope *eth.ExecutionPayloadEnvelope, expectedNum uint64) err
 type peerStat struct {
 	// Requests tokenizes each request to sync
 	Requests *rate.Limiter
+	// RangeBlocks tokenizes each payload served through range requests
+	RangeBlocks *rate.Limiter
+}
+
+func newPeerStat() *peerStat {
+	return &peerStat{
+		Requests:    rate.NewLimiter(peerServerBlocksRateLimit, peerServerBlocksBurst),
+		RangeBlocks: rate.NewLimiter(peerServerRangeBlocksRateLimit, peerServerRangeBlocksBurst),
+	}
 }
 
 type L2Chain interface {
@@ -789,6 +917,7 @@ type L2Chain interface {
 
 type ReqRespServerMetrics interface {
 	ServerPayloadByNumberEvent(num uint64, resultCode byte, duration time.Duration)
+	ServerPayloadsByRangeEvent(start uint64, count int, resultCode byte, duration time.Duration)
 }
 
 type ReqRespServer struct {
@@ -801,7 +930,8 @@ type ReqRespServer struct {
 	peerRateLimits *simplelru.LRU[peer.ID, *peerStat]
 	peerStatsLock  sync.Mutex
 
-	globalRequestsRL *rate.Limiter
+	globalRequestsRL    *rate.Limiter
+	globalRangeBlocksRL *rate.Limiter
 }
 
 func NewReqRespServer(cfg *rollup.Config, l2 L2Chain, metrics ReqRespServerMetrics) *ReqRespServer {
@@ -812,12 +942,25 @@ func NewReqRespServer(cfg *rollup.Config, l2 L2Chain, metrics ReqRespServerMetri
 	globalRequestsRL := rate.NewLimiter(globalServerBlocksRateLimit, globalServerBlocksBurst)
 
 	return &ReqRespServer{
-		cfg:              cfg,
-		l2:               l2,
-		metrics:          metrics,
-		peerRateLimits:   peerRateLimits,
-		globalRequestsRL: globalRequestsRL,
+		cfg:                 cfg,
+		l2:                  l2,
+		metrics:             metrics,
+		peerRateLimits:      peerRateLimits,
+		globalRequestsRL:    globalRequestsRL,
+		globalRangeBlocksRL: rate.NewLimiter(globalServerRangeBlocksRateLimit, globalServerRangeBlocksBurst),
+	}
+}
+
+// getPeerStat returns the rate limiting data of the peer, adding it if it is a new peer.
+func (srv *ReqRespServer) getPeerStat(id peer.ID) *peerStat {
+	srv.peerStatsLock.Lock()
+	defer srv.peerStatsLock.Unlock()
+	ps, _ := srv.peerRateLimits.Get(id)
+	if ps == nil {
+		ps = newPeerStat()
+		srv.peerRateLimits.Add(id, ps)
 	}
+	return ps
 }
 
 // HandleSyncRequest is a stream handler function to register the L2 unsafe payloads alt-sync protocol.
@@ -869,9 +1012,7 @@ func (srv *ReqRespServer) handleSyncRequest(ctx context.Context, stream network.
 	srv.peerStatsLock.Lock()
 	ps, _ := srv.peerRateLimits.Get(peerId)
 	if ps == nil {
-		ps = &peerStat{
-			Requests: rate.NewLimiter(peerServerBlocksRateLimit, peerServerBlocksBurst),
-		}
+		ps = newPeerStat()
 		srv.peerRateLimits.Add(peerId, ps)
 		ps.Requests.Reserve() // count the hit, but make it delay the next request rather than immediately waiting
 	} else {
@@ -165,7 +165,7 @@ func TestSinglePeerSync(t *testing.T) {
 	hostA.SetStreamHandler(PayloadByNumberProtocolID(cfg.L2ChainID), payloadByNumber)
 
 	// Setup host B as the client
-	cl := NewSyncClient(log.New("role", "client"), cfg, hostB, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{})
+	cl := NewSyncClient(log.New("role", "client"), cfg, hostB, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{}, false)
 
 	// Setup host B (client) to sync from its peer Host A (server)
 	cl.AddPeer(hostA.ID())
@@ -193,6 +193,130 @@ func TestSinglePeerSync(t *testing.T) {
 	}
 }
 
+func TestRangeSync(t *testing.T) {
+	t.Run("range", func(t *testing.T) {
+		testRangeSync(t, true)
+	})
+	t.Run("fallback to payload by number", func(t *testing.T) {
+		testRangeSync(t, false)
+	})
+}
+
+func testRangeSync(t *testing.T, serveRange bool) {
+	t.Parallel()
+
+	log := testlog.Logger(t, log.LevelError)
+
+	cfg, payloads := setupSyncTestData(200)
+
+	servePayload := mockPayloadFn(func(n uint64) (*eth.ExecutionPayloadEnvelope, error) {
+		p, ok := payloads.getPayload(n)
+		if !ok {
+			return nil, ethereum.NotFound
+		}
+		return p, nil
+	})
+
+	received := make(chan *eth.ExecutionPayloadEnvelope, 200)
+	receivePayload := receivePayloadFn(func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayloadEnvelope) error {
+		received <- payload
+		return nil
+	})
+
+	// Setup 2 servers and a client
+	mnet, err := mocknet.FullMeshConnected(3)
+	require.NoError(t, err, "failed to setup mocknet")
+	defer mnet.Close()
+	hosts := mnet.Hosts()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	for _, h := range hosts[:2] {
+		srv := NewReqRespServer(cfg, servePayload, metrics.NoopMetrics)
+		h.SetStreamHandler(PayloadByNumberProtocolID(cfg.L2ChainID),
+			MakeStreamHandler(ctx, log.New("serve", "payloads_by_number"), srv.HandleSyncRequest))
+		if serveRange {
+			h.SetStreamHandler(PayloadsByRangeProtocolID(cfg.L2ChainID),
+				MakeStreamHandler(ctx, log.New("serve", "payloads_by_range"), srv.HandleRangeSyncRequest))
+		}
+	}
+
+	cl := NewSyncClient(log.New("role", "client"), cfg, hosts[2], receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{}, true)
+	cl.AddPeer(hosts[0].ID())
+	cl.AddPeer(hosts[1].ID())
+	cl.Start()
+	defer cl.Close()
+
+	_, err = cl.RequestL2Range(ctx, payloads.getBlockRef(10), payloads.getBlockRef(180))
+	require.NoError(t, err)
+
+	// batches are fetched in parallel, but promoted from the trusted end, so results still arrive in reverse order
+	for i := uint64(179); i > 10; i-- {
+		select {
+		case p := <-received:
+			require.Equal(t, i, uint64(p.ExecutionPayload.BlockNumber), "expecting payloads in order")
+			exp, ok := payloads.getPayload(i)
+			require.True(t, ok, "expecting known payload")
+			require.Equal(t, exp.ExecutionPayload.BlockHash, p.ExecutionPayload.BlockHash, "expecting the correct payload")
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for payload %d", i)
+		}
+	}
+}
+
+func TestRangeSyncRemovedPeer(t *testing.T) {
+	log := testlog.Logger(t, log.LevelError)
+	cfg, payloads := setupSyncTestData(50)
+
+	mnet, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err, "failed to setup mocknet")
+	defer mnet.Close()
+	hosts := mnet.Hosts()
+
+	cl := NewSyncClient(log, cfg, hosts[1], func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayloadEnvelope) error {
+		return nil
+	}, metrics.NoopMetrics, &NoopApplicationScorer{}, true)
+	defer cl.Close()
+	id := hosts[0].ID()
+	cl.AddPeer(id)
+	cl.peersLock.Lock()
+	rp := cl.rangePeers[id]
+	cl.peersLock.Unlock()
+	require.NotNil(t, rp)
+
+	cl.RemovePeer(id)
+	require.Eventually(t, func() bool {
+		cl.peersLock.Lock()
+		defer cl.peersLock.Unlock()
+		return rp.closed
+	}, 10*time.Second, 10*time.Millisecond, "peer loop should exit")
+
+	// The main loop may still hold the peer, from a list of idle peers taken before the peer was removed.
+	cl.peersLock.Lock()
+	cl.rangePeers[id] = rp
+	cl.peersLock.Unlock()
+	cl.activeRangeRequests.set(1, true)
+	cl.onRangeRequestBatched(context.Background(), rangeRequest{start: 10, end: payloads.getBlockRef(30), id: 1})
+
+	require.Empty(t, rp.batches, "no batch should be queued for a removed peer")
+	require.Zero(t, cl.inFlight.len(), "no block should be left in-flight")
+}
+
+func TestWaitTokenAvailable(t *testing.T) {
+	rl := rate.NewLimiter(100, 1)
+	require.NoError(t, waitTokenAvailable(context.Background(), rl))
+	require.True(t, rl.Allow(), "waiting must not take the token")
+
+	// The limiter refills a token in 10ms.
+	require.NoError(t, waitTokenAvailable(context.Background(), rl))
+	require.True(t, rl.Allow())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, waitTokenAvailable(ctx, rate.NewLimiter(0.001, 0)), context.Canceled)
+}
+
 func TestMultiPeerSync(t *testing.T) {
 	t.Parallel() // Takes a while, but can run in parallel
 
@@ -226,7 +350,7 @@ func TestMultiPeerSync(t *testing.T) {
 		payloadByNumber := MakeStreamHandler(ctx, log.New("serve", "payloads_by_number"), srv.HandleSyncRequest)
 		h.SetStreamHandler(PayloadByNumberProtocolID(cfg.L2ChainID), payloadByNumber)
 
-		cl := NewSyncClient(log.New("role", "client"), cfg, h, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{})
+		cl := NewSyncClient(log.New("role", "client"), cfg, h, receivePayload, metrics.NoopMetrics, &NoopApplicationScorer{}, false)
 		return cl, received
 	}
 
@@ -372,7 +496,7 @@ func TestNetworkNotifyAddPeerAndRemovePeer(t *testing.T) {
 
 	syncCl := NewSyncClient(log, cfg, hostA, func(ctx context.Context, from peer.ID, payload *eth.ExecutionPayloadEnvelope) error {
 		return nil
-	}, metrics.NoopMetrics, &NoopApplicationScorer{})
+	}, metrics.NoopMetrics, &NoopApplicationScorer{}, false)
 
 	waitChan := make(chan struct{}, 2)
 	var connectedOnce sync.Once
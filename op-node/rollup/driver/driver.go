@@ -47,6 +47,7 @@ func NewDriver(
 	safeHeadListener rollup.SafeHeadListener,
 	syncCfg *sync.Config,
 	sequencerConductor conductor.SequencerConductor,
+	inclusion sequencing.InclusionPolicy,
 	altDA AltDAIface,
 	indexingMode bool,
 ) *Driver {
@@ -120,7 +121,7 @@ func NewDriver(
 		ec.SetOriginSelectorResetter(findL1Origin)
 
 		sequencer = sequencing.NewSequencer(driverCtx, log, cfg, attrBuilder, findL1Origin,
-			sequencerStateListener, sequencerConductor, asyncGossiper, metrics, ec)
+			sequencerStateListener, sequencerConductor, asyncGossiper, metrics, ec, inclusion)
 		sys.Register("sequencer", sequencer)
 	} else {
 		sequencer = sequencing.DisabledSequencer{}
@@ -467,6 +468,25 @@ func (s *Driver) OnUnsafeL2Payload(ctx context.Context, payload *eth.ExecutionPa
 	s.SyncDeriver.OnUnsafeL2Payload(ctx, payload)
 }
 
+// OnL1Unsafe signals a new L1 head to the L1 tracker, the status tracker and the sync deriver.
+func (s *Driver) OnL1Unsafe(ctx context.Context, sig eth.L1BlockRef) {
+	s.SyncDeriver.L1Tracker.OnL1Unsafe(sig)
+	s.StatusTracker.OnL1Unsafe(sig)
+	s.SyncDeriver.OnL1Unsafe(ctx)
+}
+
+// OnL1Safe signals a new safe L1 block to the status tracker.
+func (s *Driver) OnL1Safe(ctx context.Context, sig eth.L1BlockRef) {
+	s.StatusTracker.OnL1Safe(sig)
+}
+
+// OnL1Finalized signals a new finalized L1 block to the status tracker, the finalizer and the sync deriver.
+func (s *Driver) OnL1Finalized(ctx context.Context, sig eth.L1BlockRef) {
+	s.StatusTracker.OnL1Finalized(sig)
+	s.Finalizer.OnL1Finalized(sig)
+	s.SyncDeriver.OnL1Finalized(ctx)
+}
+
 // followUpstream reconciles the local engine state with upstream sources when
 // derivation is disabled (UnsafeOnly).
 //
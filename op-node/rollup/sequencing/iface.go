@@ -6,6 +6,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	"github.com/tokamak-network/tokamak-thanos/op-service/event"
 )
 
@@ -23,3 +24,29 @@ type SequencerIface interface {
 	SetRecoverMode(mode bool)
 	Close()
 }
+
+// InclusionPolicy selects transactions that the sequencer includes in new blocks,
+// after the deposits and ahead of the transactions of the engine tx pool.
+type InclusionPolicy interface {
+	// SelectTransactions returns the transactions to include in the block built on parent with attrs.
+	// It is not called for blocks that must not include sequencer transactions,
+	// like upgrade blocks, or blocks past the sequencer drift.
+	SelectTransactions(ctx context.Context, parent eth.L2BlockRef, attrs *eth.PayloadAttributes) []eth.Data
+	// OnIncluded is called with every payload that is inserted into the chain.
+	OnIncluded(payload *eth.ExecutionPayload)
+	// OnRejected is called with the selected transactions when the block that included them failed to build.
+	OnRejected(txs []eth.Data, err error)
+}
+
+// NoInclusionPolicy leaves the contents of blocks to the engine tx pool.
+type NoInclusionPolicy struct{}
+
+var _ InclusionPolicy = NoInclusionPolicy{}
+
+func (NoInclusionPolicy) SelectTransactions(ctx context.Context, parent eth.L2BlockRef, attrs *eth.PayloadAttributes) []eth.Data {
+	return nil
+}
+
+func (NoInclusionPolicy) OnIncluded(payload *eth.ExecutionPayload) {}
+
+func (NoInclusionPolicy) OnRejected(txs []eth.Data, err error) {}
@@ -0,0 +1,43 @@
+package inclusion
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+)
+
+// API is the bundle-submission RPC of the sequencer, served in the "inclusion" namespace.
+type API struct {
+	policy *Policy
+}
+
+func NewAPI(policy *Policy) *API {
+	return &API{policy: policy}
+}
+
+// SendBundle submits a bundle for inclusion, and returns the hash of the bundle.
+func (api *API) SendBundle(ctx context.Context, bundle Bundle) (common.Hash, error) {
+	return api.policy.SubmitBundle(bundle)
+}
+
+// NewServer creates the JWT-authenticated RPC server that accepts bundles for the policy.
+func NewServer(logger log.Logger, cfg *Config, policy *Policy) (*rpc.Server, error) {
+	jwtSecret, err := rpc.ObtainJWTSecret(logger, cfg.RPCJwtSecretPath, true)
+	if err != nil {
+		return nil, err
+	}
+	srv := rpc.NewServer(cfg.RPCAddr, cfg.RPCPort, "v0.0.0",
+		rpc.WithLogger(logger),
+		rpc.WithJWTSecret(jwtSecret[:]),
+	)
+	srv.AddAPI(gethrpc.API{
+		Namespace:     "inclusion",
+		Service:       NewAPI(policy),
+		Authenticated: true,
+	})
+	return srv, nil
+}
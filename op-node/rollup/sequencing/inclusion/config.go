@@ -0,0 +1,51 @@
+package inclusion
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type Config struct {
+	// ForcedTxsPath is the path of a file with signed transactions, one 0x-prefixed hex-encoded transaction per line,
+	// that are included ahead of the tx pool. Optional.
+	ForcedTxsPath string
+	// DenyList holds the addresses whose transactions, as sender or recipient, are never selected.
+	DenyList []common.Address
+	// SenderRateLimit is the number of selected transactions per second of L2 block time, per sender.
+	// Zero disables the rate limit.
+	SenderRateLimit float64
+	// SenderBurst is the number of transactions a sender may have selected at once.
+	SenderBurst int
+
+	// MaxBundles is the maximum number of pending bundles.
+	MaxBundles int
+	// RPCAddr address to bind the bundle RPC server to. The bundle RPC is disabled if empty.
+	RPCAddr string
+	// RPCPort port to bind the bundle RPC server to.
+	// Binds to any available port if set to 0.
+	RPCPort int
+	// RPCJwtSecretPath path of JWT secret file to apply authentication to the bundle RPC server.
+	RPCJwtSecretPath string
+}
+
+// Enabled returns whether the sequencer needs an inclusion policy.
+func (cfg *Config) Enabled() bool {
+	return cfg.ForcedTxsPath != "" || len(cfg.DenyList) > 0 || cfg.SenderRateLimit > 0 || cfg.RPCAddr != ""
+}
+
+func (cfg *Config) Check() error {
+	if cfg.SenderRateLimit < 0 {
+		return errors.New("sender rate limit must not be negative")
+	}
+	if cfg.SenderRateLimit > 0 && cfg.SenderBurst <= 0 {
+		return errors.New("sender rate limit requires a positive burst")
+	}
+	if cfg.RPCAddr != "" && cfg.RPCJwtSecretPath == "" {
+		return errors.New("bundle RPC server requires JWT setup, but no JWT path was specified")
+	}
+	if cfg.RPCAddr != "" && cfg.MaxBundles <= 0 {
+		return errors.New("bundle RPC server requires a positive max number of bundles")
+	}
+	return nil
+}
@@ -0,0 +1,452 @@
+// Package inclusion implements the inclusion policy of the sequencer: it selects forced transactions
+// and submitted bundles for new blocks, and filters them by a deny-list and per-sender rate limits.
+package inclusion
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+	"golang.org/x/time/rate"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+// Policies, as labels of the decision metrics
+const (
+	PolicyForced    = "forced"
+	PolicyBundle    = "bundle"
+	PolicyDenyList  = "deny-list"
+	PolicyRateLimit = "sender-rate-limit"
+)
+
+// Decisions, as labels of the decision metrics
+const (
+	// DecisionAccepted is a bundle that was accepted into the pool.
+	DecisionAccepted = "accepted"
+	// DecisionSelected is a forced transaction or bundle that was selected for a block.
+	DecisionSelected = "selected"
+	// DecisionIncluded is a forced transaction or bundle that was included in the chain.
+	DecisionIncluded = "included"
+	// DecisionDeferred is a forced transaction or bundle that is kept for a later block.
+	DecisionDeferred = "deferred"
+	// DecisionDenied is a forced transaction or bundle that was dropped by the deny-list.
+	DecisionDenied = "denied"
+	// DecisionInvalid is a transaction or bundle that cannot be included.
+	DecisionInvalid = "invalid"
+	// DecisionExpired is a bundle that was not included before its max timestamp.
+	DecisionExpired = "expired"
+	// DecisionRejected is a forced transaction or bundle that was dropped, since the engine failed to build a block with it.
+	DecisionRejected = "rejected"
+	// DecisionPoolFull is a bundle that was not accepted, since the pool is full.
+	DecisionPoolFull = "pool-full"
+)
+
+// maxBundleTxs is the maximum number of transactions of a bundle.
+const maxBundleTxs = 16
+
+// senderLimitersSize is the number of senders that rate limits are tracked for.
+const senderLimitersSize = 10_000
+
+var (
+	ErrEmptyBundle   = errors.New("bundle has no transactions")
+	ErrBundleTooBig  = errors.New("bundle has too many transactions")
+	ErrInvalidBundle = errors.New("invalid bundle")
+	ErrDenied        = errors.New("transaction sender or recipient is denied")
+	ErrPoolFull      = errors.New("bundle pool is full")
+)
+
+type Metrics interface {
+	RecordInclusionDecision(policy string, decision string)
+}
+
+// Bundle is a list of transactions that is included atomically and in order, or not at all.
+type Bundle struct {
+	Txs []hexutil.Bytes `json:"txs"`
+	// MinTimestamp is the first L2 block timestamp the bundle may be included at. Optional.
+	MinTimestamp hexutil.Uint64 `json:"minTimestamp,omitempty"`
+	// MaxTimestamp is the last L2 block timestamp the bundle may be included at. Optional.
+	MaxTimestamp hexutil.Uint64 `json:"maxTimestamp,omitempty"`
+}
+
+// item is a forced transaction, or a bundle, pending inclusion.
+type item struct {
+	policy string
+	hash   common.Hash
+
+	raw     []eth.Data
+	hashes  []common.Hash
+	txs     []*types.Transaction
+	senders []common.Address
+	gas     uint64
+
+	minTimestamp uint64
+	maxTimestamp uint64
+}
+
+// Policy is the inclusion policy of the sequencer.
+//
+// Forced transactions are selected first, in the order of the forced transactions file,
+// and bundles after that, in the order they were submitted.
+// Both stay pending until they are included in the chain. They are dropped if the engine fails
+// to build a block with them, so that a single bad transaction cannot stall the sequencer.
+type Policy struct {
+	log     log.Logger
+	signer  types.Signer
+	metrics Metrics
+
+	maxBundles int
+	denied     map[common.Address]struct{}
+	rateLimit  rate.Limit
+	burst      int
+
+	mu       sync.Mutex
+	forced   []*item
+	bundles  []*item
+	limiters *simplelru.LRU[common.Address, *rate.Limiter]
+}
+
+var _ sequencing.InclusionPolicy = (*Policy)(nil)
+
+func NewPolicy(log log.Logger, cfg *Config, chainID *big.Int, m Metrics) (*Policy, error) {
+	limiters, err := simplelru.NewLRU[common.Address, *rate.Limiter](senderLimitersSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{
+		log:        log,
+		signer:     types.LatestSignerForChainID(chainID),
+		metrics:    m,
+		maxBundles: cfg.MaxBundles,
+		denied:     make(map[common.Address]struct{}, len(cfg.DenyList)),
+		rateLimit:  rate.Limit(cfg.SenderRateLimit),
+		burst:      cfg.SenderBurst,
+		limiters:   limiters,
+	}
+	for _, addr := range cfg.DenyList {
+		p.denied[addr] = struct{}{}
+	}
+	if cfg.ForcedTxsPath != "" {
+		forced, err := p.loadForcedTxs(cfg.ForcedTxsPath)
+		if err != nil {
+			return nil, err
+		}
+		p.forced = forced
+		log.Info("Loaded forced transactions", "count", len(forced))
+	}
+	return p, nil
+}
+
+func (p *Policy) loadForcedTxs(path string) ([]*item, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open forced transactions: %w", err)
+	}
+	defer f.Close()
+	var out []*item
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		raw, err := hexutil.Decode(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid forced transaction on line %d: %w", line, err)
+		}
+		it, err := p.newItem(PolicyForced, []hexutil.Bytes{raw})
+		if err != nil {
+			return nil, fmt.Errorf("invalid forced transaction on line %d: %w", line, err)
+		}
+		out = append(out, it)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read forced transactions: %w", err)
+	}
+	return out, nil
+}
+
+func (p *Policy) newItem(policy string, txs []hexutil.Bytes) (*item, error) {
+	it := &item{policy: policy}
+	var hashes []byte
+	for i, raw := range txs {
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, fmt.Errorf("failed to decode transaction %d: %w", i, err)
+		}
+		if tx.IsDepositTx() {
+			return nil, fmt.Errorf("transaction %d is a deposit", i)
+		}
+		sender, err := types.Sender(p.signer, &tx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature of transaction %d: %w", i, err)
+		}
+		it.raw = append(it.raw, eth.Data(raw))
+		it.hashes = append(it.hashes, tx.Hash())
+		it.txs = append(it.txs, &tx)
+		it.senders = append(it.senders, sender)
+		it.gas += tx.Gas()
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	if len(it.hashes) == 1 {
+		it.hash = it.hashes[0]
+	} else {
+		it.hash = crypto.Keccak256Hash(hashes)
+	}
+	return it, nil
+}
+
+// isDenied returns whether any transaction of the item is from or to a denied address.
+func (p *Policy) isDenied(it *item) bool {
+	if len(p.denied) == 0 {
+		return false
+	}
+	for i, tx := range it.txs {
+		if _, ok := p.denied[it.senders[i]]; ok {
+			return true
+		}
+		if to := tx.To(); to != nil {
+			if _, ok := p.denied[*to]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allow consumes the rate limits of the senders of the item, if all of them have enough allowance at t.
+func (p *Policy) allow(it *item, t time.Time) bool {
+	if p.rateLimit == 0 {
+		return true
+	}
+	counts := make(map[common.Address]int)
+	for _, sender := range it.senders {
+		counts[sender]++
+	}
+	limiters := make(map[common.Address]*rate.Limiter, len(counts))
+	for sender, n := range counts {
+		lim, ok := p.limiters.Get(sender)
+		if !ok {
+			lim = rate.NewLimiter(p.rateLimit, p.burst)
+			p.limiters.Add(sender, lim)
+		}
+		if lim.TokensAt(t) < float64(n) {
+			return false
+		}
+		limiters[sender] = lim
+	}
+	for sender, lim := range limiters {
+		lim.AllowN(t, counts[sender])
+	}
+	return true
+}
+
+// SubmitBundle validates the bundle and adds it to the pool. It returns the hash of the bundle.
+func (p *Policy) SubmitBundle(b Bundle) (common.Hash, error) {
+	if len(b.Txs) == 0 {
+		p.metrics.RecordInclusionDecision(PolicyBundle, DecisionInvalid)
+		return common.Hash{}, ErrEmptyBundle
+	}
+	if len(b.Txs) > maxBundleTxs {
+		p.metrics.RecordInclusionDecision(PolicyBundle, DecisionInvalid)
+		return common.Hash{}, fmt.Errorf("%w: %d > %d", ErrBundleTooBig, len(b.Txs), maxBundleTxs)
+	}
+	if b.MaxTimestamp != 0 && b.MinTimestamp > b.MaxTimestamp {
+		p.metrics.RecordInclusionDecision(PolicyBundle, DecisionInvalid)
+		return common.Hash{}, fmt.Errorf("%w: min timestamp %d is after max timestamp %d", ErrInvalidBundle, b.MinTimestamp, b.MaxTimestamp)
+	}
+	it, err := p.newItem(PolicyBundle, b.Txs)
+	if err != nil {
+		p.metrics.RecordInclusionDecision(PolicyBundle, DecisionInvalid)
+		return common.Hash{}, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	it.minTimestamp = uint64(b.MinTimestamp)
+	it.maxTimestamp = uint64(b.MaxTimestamp)
+	if p.isDenied(it) {
+		p.metrics.RecordInclusionDecision(PolicyDenyList, DecisionDenied)
+		return common.Hash{}, ErrDenied
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pending := range p.bundles {
+		if pending.hash == it.hash {
+			return it.hash, nil
+		}
+	}
+	if len(p.bundles) >= p.maxBundles {
+		p.metrics.RecordInclusionDecision(PolicyBundle, DecisionPoolFull)
+		return common.Hash{}, ErrPoolFull
+	}
+	p.bundles = append(p.bundles, it)
+	p.metrics.RecordInclusionDecision(PolicyBundle, DecisionAccepted)
+	p.log.Info("Accepted bundle", "hash", it.hash, "txs", len(it.txs))
+	return it.hash, nil
+}
+
+// Pending returns the number of pending forced transactions and bundles.
+func (p *Policy) Pending() (forced int, bundles int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.forced), len(p.bundles)
+}
+
+func (p *Policy) SelectTransactions(ctx context.Context, parent eth.L2BlockRef, attrs *eth.PayloadAttributes) []eth.Data {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.forced) == 0 && len(p.bundles) == 0 {
+		return nil
+	}
+
+	// The selected transactions may use the gas that the deposits leave.
+	gasBudget := uint64(math.MaxUint64)
+	if attrs.GasLimit != nil {
+		gasBudget = uint64(*attrs.GasLimit)
+	}
+	for _, raw := range attrs.Transactions {
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			continue // not ours to validate, the engine will reject it
+		}
+		gasBudget -= min(gasBudget, tx.Gas())
+	}
+
+	sel := &selection{
+		timestamp: uint64(attrs.Timestamp),
+		gasBudget: gasBudget,
+	}
+	p.forced = p.selectFrom(p.forced, sel)
+	p.bundles = p.selectFrom(p.bundles, sel)
+	if len(sel.txs) > 0 {
+		p.log.Info("Selected transactions for block", "parent", parent, "timestamp", sel.timestamp, "txs", len(sel.txs))
+	}
+	return sel.txs
+}
+
+type selection struct {
+	timestamp uint64
+	gasBudget uint64
+	txs       []eth.Data
+}
+
+// selectFrom adds the items that are ready to the selection, and returns the items that remain pending.
+func (p *Policy) selectFrom(items []*item, sel *selection) []*item {
+	t := time.Unix(int64(sel.timestamp), 0)
+	remaining := items[:0]
+	for _, it := range items {
+		if it.maxTimestamp != 0 && sel.timestamp > it.maxTimestamp {
+			p.metrics.RecordInclusionDecision(it.policy, DecisionExpired)
+			p.log.Info("Dropped expired bundle", "hash", it.hash)
+			continue
+		}
+		remaining = append(remaining, it)
+		if sel.timestamp < it.minTimestamp {
+			continue
+		}
+		if p.isDenied(it) {
+			// The forced transactions are checked here, since they are not checked on submission.
+			p.metrics.RecordInclusionDecision(PolicyDenyList, DecisionDenied)
+			p.log.Warn("Dropped denied transactions", "policy", it.policy, "hash", it.hash)
+			remaining = remaining[:len(remaining)-1]
+			continue
+		}
+		if it.gas > sel.gasBudget {
+			p.metrics.RecordInclusionDecision(it.policy, DecisionDeferred)
+			continue
+		}
+		if !p.allow(it, t) {
+			p.metrics.RecordInclusionDecision(PolicyRateLimit, DecisionDeferred)
+			continue
+		}
+		sel.gasBudget -= it.gas
+		sel.txs = append(sel.txs, it.raw...)
+		p.metrics.RecordInclusionDecision(it.policy, DecisionSelected)
+	}
+	// clear the dropped tail, for garbage collection
+	clear(items[len(remaining):])
+	return remaining
+}
+
+func (p *Policy) OnIncluded(payload *eth.ExecutionPayload) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.forced) == 0 && len(p.bundles) == 0 {
+		return
+	}
+	// The hash of a transaction is the hash of its binary encoding.
+	included := make(map[common.Hash]struct{}, len(payload.Transactions))
+	for _, raw := range payload.Transactions {
+		included[crypto.Keccak256Hash(raw)] = struct{}{}
+	}
+	drop := func(it *item) bool {
+		n := 0
+		for _, h := range it.hashes {
+			if _, ok := included[h]; ok {
+				n++
+			}
+		}
+		switch n {
+		case 0:
+			return false
+		case len(it.hashes):
+			p.metrics.RecordInclusionDecision(it.policy, DecisionIncluded)
+			p.log.Info("Included transactions", "policy", it.policy, "hash", it.hash, "block", payload.ID())
+		default:
+			// Part of a bundle was included by other means, the rest cannot be included atomically anymore.
+			p.metrics.RecordInclusionDecision(it.policy, DecisionInvalid)
+			p.log.Warn("Dropped partially included bundle", "hash", it.hash, "block", payload.ID())
+		}
+		return true
+	}
+	p.forced = removeItems(p.forced, drop)
+	p.bundles = removeItems(p.bundles, drop)
+}
+
+func (p *Policy) OnRejected(txs []eth.Data, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rejected := make(map[common.Hash]struct{}, len(txs))
+	for _, raw := range txs {
+		rejected[crypto.Keccak256Hash(raw)] = struct{}{}
+	}
+	drop := func(it *item) bool {
+		for _, h := range it.hashes {
+			if _, ok := rejected[h]; ok {
+				p.metrics.RecordInclusionDecision(it.policy, DecisionRejected)
+				p.log.Warn("Dropped transactions, engine failed to build block with them",
+					"policy", it.policy, "hash", it.hash, "err", err)
+				return true
+			}
+		}
+		return false
+	}
+	p.forced = removeItems(p.forced, drop)
+	p.bundles = removeItems(p.bundles, drop)
+}
+
+func removeItems(items []*item, drop func(it *item) bool) []*item {
+	remaining := items[:0]
+	for _, it := range items {
+		if !drop(it) {
+			remaining = append(remaining, it)
+		}
+	}
+	clear(items[len(remaining):])
+	return remaining
+}
@@ -0,0 +1,248 @@
+package inclusion
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+var testChainID = big.NewInt(901)
+
+type testMetrics struct {
+	decisions map[[2]string]int
+}
+
+func (m *testMetrics) RecordInclusionDecision(policy string, decision string) {
+	if m.decisions == nil {
+		m.decisions = make(map[[2]string]int)
+	}
+	m.decisions[[2]string{policy, decision}]++
+}
+
+func (m *testMetrics) count(policy string, decision string) int {
+	return m.decisions[[2]string{policy, decision}]
+}
+
+type testAccount struct {
+	key   *ecdsa.PrivateKey
+	addr  common.Address
+	nonce uint64
+}
+
+func newTestAccount(t *testing.T) *testAccount {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return &testAccount{key: key, addr: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// tx signs the next transaction of the account, and returns its binary encoding.
+func (a *testAccount) tx(t *testing.T, to common.Address, gas uint64) hexutil.Bytes {
+	tx, err := types.SignNewTx(a.key, types.LatestSignerForChainID(testChainID), &types.DynamicFeeTx{
+		ChainID:   testChainID,
+		Nonce:     a.nonce,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1_000_000_000),
+		Gas:       gas,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	require.NoError(t, err)
+	a.nonce++
+	data, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return data
+}
+
+func newTestPolicy(t *testing.T, cfg *Config) (*Policy, *testMetrics) {
+	m := &testMetrics{}
+	p, err := NewPolicy(testlog.Logger(t, log.LevelError), cfg, testChainID, m)
+	require.NoError(t, err)
+	return p, m
+}
+
+func testAttrs(timestamp uint64, gasLimit uint64) *eth.PayloadAttributes {
+	gl := eth.Uint64Quantity(gasLimit)
+	return &eth.PayloadAttributes{
+		Timestamp: eth.Uint64Quantity(timestamp),
+		GasLimit:  &gl,
+	}
+}
+
+func TestPolicyForcedAndBundles(t *testing.T) {
+	alice, bob := newTestAccount(t), newTestAccount(t)
+	forcedA := alice.tx(t, bob.addr, 21_000)
+	forcedB := alice.tx(t, bob.addr, 21_000)
+	path := filepath.Join(t.TempDir(), "forced.txt")
+	content := "# forced transactions\n" + forcedA.String() + "\n\n" + forcedB.String() + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	p, m := newTestPolicy(t, &Config{ForcedTxsPath: path, MaxBundles: 10})
+	bundleTxs := []hexutil.Bytes{bob.tx(t, alice.addr, 21_000), bob.tx(t, alice.addr, 21_000)}
+	hash, err := p.SubmitBundle(Bundle{Txs: bundleTxs})
+	require.NoError(t, err)
+	require.NotEqual(t, common.Hash{}, hash)
+	again, err := p.SubmitBundle(Bundle{Txs: bundleTxs})
+	require.NoError(t, err)
+	require.Equal(t, hash, again, "resubmitting a bundle is a no-op")
+	require.Equal(t, 1, m.count(PolicyBundle, DecisionAccepted))
+
+	selected := p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(100, 30_000_000))
+	require.Equal(t, []eth.Data{
+		eth.Data(forcedA), eth.Data(forcedB), eth.Data(bundleTxs[0]), eth.Data(bundleTxs[1]),
+	}, selected, "forced txs come first, in file order")
+	require.Equal(t, 2, m.count(PolicyForced, DecisionSelected))
+	require.Equal(t, 1, m.count(PolicyBundle, DecisionSelected))
+
+	// Only the forced txs made it into the block, the bundle stays pending
+	p.OnIncluded(&eth.ExecutionPayload{Transactions: []eth.Data{{0x7e}, eth.Data(forcedA), eth.Data(forcedB)}})
+	require.Equal(t, 2, m.count(PolicyForced, DecisionIncluded))
+	forced, bundles := p.Pending()
+	require.Equal(t, 0, forced)
+	require.Equal(t, 1, bundles)
+
+	p.OnIncluded(&eth.ExecutionPayload{Transactions: []eth.Data{eth.Data(bundleTxs[0]), eth.Data(bundleTxs[1])}})
+	require.Equal(t, 1, m.count(PolicyBundle, DecisionIncluded))
+	require.Empty(t, p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(102, 30_000_000)))
+}
+
+func TestPolicyInvalidForcedTxs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "forced.txt")
+	require.NoError(t, os.WriteFile(path, []byte("0x1234\n"), 0o644))
+	_, err := NewPolicy(testlog.Logger(t, log.LevelError), &Config{ForcedTxsPath: path}, testChainID, &testMetrics{})
+	require.ErrorContains(t, err, "line 1")
+}
+
+func TestPolicySubmitBundle(t *testing.T) {
+	alice, bob, mallory := newTestAccount(t), newTestAccount(t), newTestAccount(t)
+	p, m := newTestPolicy(t, &Config{DenyList: []common.Address{mallory.addr}, MaxBundles: 1})
+
+	_, err := p.SubmitBundle(Bundle{})
+	require.ErrorIs(t, err, ErrEmptyBundle)
+	_, err = p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{{0x02, 0x12}}})
+	require.ErrorIs(t, err, ErrInvalidBundle)
+	_, err = p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{alice.tx(t, bob.addr, 21_000)}, MinTimestamp: 10, MaxTimestamp: 5})
+	require.ErrorIs(t, err, ErrInvalidBundle)
+	require.Equal(t, 3, m.count(PolicyBundle, DecisionInvalid))
+
+	// denied as recipient and as sender
+	_, err = p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{alice.tx(t, bob.addr, 21_000), alice.tx(t, mallory.addr, 21_000)}})
+	require.ErrorIs(t, err, ErrDenied)
+	_, err = p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{mallory.tx(t, bob.addr, 21_000)}})
+	require.ErrorIs(t, err, ErrDenied)
+	require.Equal(t, 2, m.count(PolicyDenyList, DecisionDenied))
+
+	_, err = p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{alice.tx(t, bob.addr, 21_000)}})
+	require.NoError(t, err)
+	_, err = p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{bob.tx(t, alice.addr, 21_000)}})
+	require.ErrorIs(t, err, ErrPoolFull)
+	require.Equal(t, 1, m.count(PolicyBundle, DecisionPoolFull))
+}
+
+func TestPolicyBundleTimestamps(t *testing.T) {
+	alice, bob := newTestAccount(t), newTestAccount(t)
+	p, m := newTestPolicy(t, &Config{MaxBundles: 10})
+	later := alice.tx(t, bob.addr, 21_000)
+	_, err := p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{later}, MinTimestamp: 104, MaxTimestamp: 106})
+	require.NoError(t, err)
+	_, err = p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{bob.tx(t, alice.addr, 21_000)}, MaxTimestamp: 102})
+	require.NoError(t, err)
+
+	require.Len(t, p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(100, 30_000_000)), 1)
+	require.Empty(t, p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(103, 30_000_000)))
+	require.Equal(t, 1, m.count(PolicyBundle, DecisionExpired))
+	require.Equal(t, []eth.Data{eth.Data(later)},
+		p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(104, 30_000_000)))
+	require.Empty(t, p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(107, 30_000_000)))
+	require.Equal(t, 2, m.count(PolicyBundle, DecisionExpired))
+}
+
+func TestPolicyGasBudget(t *testing.T) {
+	alice, bob := newTestAccount(t), newTestAccount(t)
+	p, m := newTestPolicy(t, &Config{MaxBundles: 10})
+	large := alice.tx(t, bob.addr, 1_000_000)
+	small := bob.tx(t, alice.addr, 21_000)
+	_, err := p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{large}})
+	require.NoError(t, err)
+	_, err = p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{small}})
+	require.NoError(t, err)
+
+	// the bundle that does not fit is deferred, the smaller bundle after it is still selected
+	require.Equal(t, []eth.Data{eth.Data(small)},
+		p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(100, 500_000)))
+	require.Equal(t, 1, m.count(PolicyBundle, DecisionDeferred))
+	require.Equal(t, []eth.Data{eth.Data(large), eth.Data(small)},
+		p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(102, 30_000_000)))
+}
+
+func TestPolicySenderRateLimit(t *testing.T) {
+	alice, bob := newTestAccount(t), newTestAccount(t)
+	p, m := newTestPolicy(t, &Config{SenderRateLimit: 0.5, SenderBurst: 1, MaxBundles: 10})
+	first := alice.tx(t, bob.addr, 21_000)
+	second := alice.tx(t, bob.addr, 21_000)
+	_, err := p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{first}})
+	require.NoError(t, err)
+	_, err = p.SubmitBundle(Bundle{Txs: []hexutil.Bytes{second}})
+	require.NoError(t, err)
+
+	require.Equal(t, []eth.Data{eth.Data(first)},
+		p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(100, 30_000_000)))
+	require.Equal(t, 1, m.count(PolicyRateLimit, DecisionDeferred))
+	p.OnIncluded(&eth.ExecutionPayload{Transactions: []eth.Data{eth.Data(first)}})
+
+	// one tx per 2 seconds of block time
+	require.Empty(t, p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(101, 30_000_000)))
+	require.Equal(t, []eth.Data{eth.Data(second)},
+		p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(102, 30_000_000)))
+}
+
+func TestPolicyRejected(t *testing.T) {
+	alice, bob := newTestAccount(t), newTestAccount(t)
+	p, m := newTestPolicy(t, &Config{MaxBundles: 10})
+	bundle := []hexutil.Bytes{alice.tx(t, bob.addr, 21_000), alice.tx(t, bob.addr, 21_000)}
+	_, err := p.SubmitBundle(Bundle{Txs: bundle})
+	require.NoError(t, err)
+
+	selected := p.SelectTransactions(context.Background(), eth.L2BlockRef{}, testAttrs(100, 30_000_000))
+	require.Len(t, selected, 2)
+	p.OnRejected(selected, errors.New("nonce too high"))
+	require.Equal(t, 1, m.count(PolicyBundle, DecisionRejected))
+	_, bundles := p.Pending()
+	require.Zero(t, bundles)
+}
+
+func TestPolicyPartiallyIncludedBundle(t *testing.T) {
+	alice, bob := newTestAccount(t), newTestAccount(t)
+	p, m := newTestPolicy(t, &Config{MaxBundles: 10})
+	bundle := []hexutil.Bytes{alice.tx(t, bob.addr, 21_000), alice.tx(t, bob.addr, 21_000)}
+	_, err := p.SubmitBundle(Bundle{Txs: bundle})
+	require.NoError(t, err)
+
+	p.OnIncluded(&eth.ExecutionPayload{Transactions: []eth.Data{eth.Data(bundle[0])}})
+	require.Equal(t, 1, m.count(PolicyBundle, DecisionInvalid))
+	_, bundles := p.Pending()
+	require.Zero(t, bundles)
+}
+
+func TestConfigCheck(t *testing.T) {
+	require.NoError(t, (&Config{}).Check())
+	require.False(t, (&Config{}).Enabled())
+	require.Error(t, (&Config{SenderRateLimit: 1}).Check(), "requires burst")
+	require.Error(t, (&Config{RPCAddr: "127.0.0.1", MaxBundles: 10}).Check(), "requires JWT")
+	require.Error(t, (&Config{RPCAddr: "127.0.0.1", RPCJwtSecretPath: "jwt.txt"}).Check(), "requires max bundles")
+	require.NoError(t, (&Config{RPCAddr: "127.0.0.1", RPCJwtSecretPath: "jwt.txt", MaxBundles: 10}).Check())
+}
@@ -0,0 +1,79 @@
+package preconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing/inclusion"
+	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
+)
+
+// maxAwaitTime is the maximum time to wait for the preconfirmation of a submitted transaction.
+const maxAwaitTime = 30 * time.Second
+
+var ErrNotFound = errors.New("preconfirmation not found")
+
+// API is the preconfirmation RPC, served in the "preconf" namespace.
+type API struct {
+	issuer  *Issuer  // nil if this node does not issue preconfirmations
+	monitor *Monitor // nil if this node does not monitor preconfirmations
+}
+
+func NewAPI(issuer *Issuer, monitor *Monitor) *API {
+	return &API{issuer: issuer, monitor: monitor}
+}
+
+// GetPreconfirmation returns the preconfirmation of the transaction, and its status if it is monitored.
+func (api *API) GetPreconfirmation(ctx context.Context, txHash common.Hash) (*Status, error) {
+	if api.monitor != nil {
+		if status, ok := api.monitor.Get(txHash); ok {
+			return &status, nil
+		}
+	}
+	if api.issuer != nil {
+		if p, ok := api.issuer.Get(txHash); ok {
+			return &Status{Preconfirmation: p, Status: StatusIssued}, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+type Submitter interface {
+	SubmitBundle(bundle inclusion.Bundle) (common.Hash, error)
+}
+
+// SequencerAPI is the preconfirmation RPC of the sequencer, served in the "preconf" namespace
+// of the authenticated bundle RPC server.
+type SequencerAPI struct {
+	issuer    *Issuer
+	submitter Submitter
+}
+
+func NewSequencerAPI(issuer *Issuer, submitter Submitter) *SequencerAPI {
+	return &SequencerAPI{issuer: issuer, submitter: submitter}
+}
+
+// SendRawTransaction submits the transaction for inclusion, and returns its preconfirmation
+// once the sequencer starts building a block with it.
+func (api *SequencerAPI) SendRawTransaction(ctx context.Context, tx hexutil.Bytes) (*opsigner.SignedPreconfirmation, error) {
+	txHash := crypto.Keccak256Hash(tx)
+	ch, done := api.issuer.Await(txHash)
+	defer done()
+	if _, err := api.submitter.SubmitBundle(inclusion.Bundle{Txs: []hexutil.Bytes{tx}}); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, maxAwaitTime)
+	defer cancel()
+	select {
+	case p := <-ch:
+		return p, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("transaction %s was not preconfirmed: %w", txHash, ctx.Err())
+	}
+}
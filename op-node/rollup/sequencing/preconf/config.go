@@ -0,0 +1,9 @@
+package preconf
+
+type Config struct {
+	// Enabled issues preconfirmations for the transactions of the blocks that the sequencer builds.
+	Enabled bool
+	// Monitor checks the preconfirmations of the sequencer, received from gossip or issued by this node,
+	// against the canonical chain.
+	Monitor bool
+}
@@ -0,0 +1,238 @@
+// Package preconf implements preconfirmations: commitments, signed by the sequencer,
+// to include transactions in the block that is being built.
+package preconf
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/engine"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/event"
+	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
+)
+
+// Statuses, as labels of the preconfirmation metrics
+const (
+	// StatusIssued is a preconfirmation that was signed and published by the sequencer.
+	StatusIssued = "issued"
+	// StatusFailed is a preconfirmation that the sequencer failed to sign.
+	StatusFailed = "failed"
+	// StatusPending is a preconfirmation of a block that is not canonical yet.
+	StatusPending = "pending"
+	// StatusKept is a preconfirmation of a transaction that was included in the preconfirmed block.
+	StatusKept = "kept"
+	// StatusBroken is a preconfirmation of a transaction that was not included in the preconfirmed block.
+	StatusBroken = "broken"
+)
+
+// signTimeout is the time to sign and publish the preconfirmations of a block.
+const signTimeout = 2 * time.Second
+
+// issuedSize is the number of issued preconfirmations that are kept for the API.
+const issuedSize = 10_000
+
+type Metrics interface {
+	RecordPreconfirmation(status string)
+}
+
+// Network signs preconfirmations with the sequencer key, and publishes them.
+// The signed preconfirmation is returned even if publishing it failed.
+type Network interface {
+	SignAndPublishPreconfirmation(ctx context.Context, p opsigner.Preconfirmation) (*opsigner.SignedPreconfirmation, error)
+}
+
+// Issuer issues preconfirmations for the transactions that the sequencer puts in the block it builds.
+// Transactions of the payload attributes, e.g. selected by the inclusion policy, are preconfirmed when the
+// block starts building. Transactions from the tx pool of the engine are only known once the block is sealed,
+// and are preconfirmed then, before the block is inserted in the chain.
+type Issuer struct {
+	log     log.Logger
+	metrics Metrics
+	network Network
+	monitor *Monitor // nil if the issued preconfirmations are not monitored
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// attrs of the block the sequencer started to build, until the engine confirms the build started.
+	attrs *derive.AttributesWithParent
+	// started are the transactions preconfirmed when the block being built was started,
+	// not preconfirmed again when it is sealed.
+	started map[common.Hash]struct{}
+
+	mu      sync.Mutex
+	issued  *simplelru.LRU[common.Hash, *opsigner.SignedPreconfirmation]
+	waiters map[common.Hash][]chan *opsigner.SignedPreconfirmation
+}
+
+var _ event.Deriver = (*Issuer)(nil)
+
+func NewIssuer(log log.Logger, m Metrics, network Network, monitor *Monitor) *Issuer {
+	issued, _ := simplelru.NewLRU[common.Hash, *opsigner.SignedPreconfirmation](issuedSize, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Issuer{
+		log:     log,
+		metrics: m,
+		network: network,
+		monitor: monitor,
+		ctx:     ctx,
+		cancel:  cancel,
+		issued:  issued,
+		waiters: make(map[common.Hash][]chan *opsigner.SignedPreconfirmation),
+	}
+}
+
+func (i *Issuer) OnEvent(ctx context.Context, ev event.Event) bool {
+	switch x := ev.(type) {
+	case engine.BuildStartEvent:
+		// only blocks of the sequencer, not derived from L1, are preconfirmed
+		if x.Attributes.DerivedFrom != (eth.L1BlockRef{}) {
+			return false
+		}
+		i.attrs = x.Attributes
+	case engine.BuildStartedEvent:
+		if x.DerivedFrom != (eth.L1BlockRef{}) || i.attrs == nil || i.attrs.Parent != x.Parent {
+			return false
+		}
+		attrs := i.attrs
+		i.attrs = nil
+		i.onBuildStarted(attrs.Attributes, x.Parent.Number+1)
+	case engine.BuildSealedEvent:
+		if x.DerivedFrom != (eth.L1BlockRef{}) {
+			return false
+		}
+		i.onBuildSealed(x.Envelope.ExecutionPayload, x.Ref.Number)
+	default:
+		return false
+	}
+	return true
+}
+
+func (i *Issuer) onBuildStarted(attrs *eth.PayloadAttributes, blockNumber uint64) {
+	i.started = make(map[common.Hash]struct{})
+	var txs []common.Hash
+	for _, tx := range attrs.Transactions {
+		if len(tx) > 0 && tx[0] != types.DepositTxType {
+			txHash := crypto.Keccak256Hash(tx)
+			i.started[txHash] = struct{}{}
+			txs = append(txs, txHash)
+		}
+	}
+	i.issueAll(txs, blockNumber)
+}
+
+// onBuildSealed preconfirms the transactions of the sealed block that the engine added from its tx pool.
+func (i *Issuer) onBuildSealed(payload *eth.ExecutionPayload, blockNumber uint64) {
+	started := i.started
+	i.started = nil
+	var txs []common.Hash
+	for _, tx := range payload.Transactions {
+		if len(tx) == 0 || tx[0] == types.DepositTxType {
+			continue
+		}
+		txHash := crypto.Keccak256Hash(tx)
+		if _, ok := started[txHash]; !ok {
+			txs = append(txs, txHash)
+		}
+	}
+	i.issueAll(txs, blockNumber)
+}
+
+func (i *Issuer) issueAll(txs []common.Hash, blockNumber uint64) {
+	if len(txs) == 0 {
+		return
+	}
+	// Signing may be remote, the block building must not wait for it.
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		ctx, cancel := context.WithTimeout(i.ctx, signTimeout)
+		defer cancel()
+		for _, txHash := range txs {
+			i.issue(ctx, opsigner.Preconfirmation{
+				TxHash:      txHash,
+				BlockNumber: eth.Uint64Quantity(blockNumber),
+			})
+		}
+	}()
+}
+
+func (i *Issuer) issue(ctx context.Context, p opsigner.Preconfirmation) {
+	signed, err := i.network.SignAndPublishPreconfirmation(ctx, p)
+	if signed == nil {
+		i.log.Warn("Failed to sign preconfirmation", "preconf", &p, "err", err)
+		i.metrics.RecordPreconfirmation(StatusFailed)
+		return
+	}
+	if err != nil {
+		// the preconfirmation is still returned to waiting API users
+		i.log.Warn("Failed to publish preconfirmation", "preconf", &p, "err", err)
+	}
+	i.log.Debug("Issued preconfirmation", "preconf", &p)
+	i.metrics.RecordPreconfirmation(StatusIssued)
+	if i.monitor != nil {
+		i.monitor.Add(signed)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.issued.Add(p.TxHash, signed)
+	for _, ch := range i.waiters[p.TxHash] {
+		ch <- signed
+	}
+	delete(i.waiters, p.TxHash)
+}
+
+// Get returns the preconfirmation issued for the transaction, if any.
+func (i *Issuer) Get(txHash common.Hash) (*opsigner.SignedPreconfirmation, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.issued.Get(txHash)
+}
+
+// Await returns a channel that receives the preconfirmation of the transaction once it is issued,
+// and a function to stop waiting, that must be called when done.
+func (i *Issuer) Await(txHash common.Hash) (<-chan *opsigner.SignedPreconfirmation, func()) {
+	ch := make(chan *opsigner.SignedPreconfirmation, 1)
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if p, ok := i.issued.Get(txHash); ok {
+		ch <- p
+		return ch, func() {}
+	}
+	i.waiters[txHash] = append(i.waiters[txHash], ch)
+	return ch, func() {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		waiters := i.waiters[txHash]
+		for j, w := range waiters {
+			if w == ch {
+				waiters = append(waiters[:j], waiters[j+1:]...)
+				break
+			}
+		}
+		if len(waiters) == 0 {
+			delete(i.waiters, txHash)
+		} else {
+			i.waiters[txHash] = waiters
+		}
+	}
+}
+
+// Close stops issuing preconfirmations, and waits for the preconfirmations that are being signed.
+func (i *Issuer) Close() error {
+	i.cancel()
+	i.wg.Wait()
+	return nil
+}
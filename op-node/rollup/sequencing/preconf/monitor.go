@@ -0,0 +1,225 @@
+package preconf
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/engine"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/event"
+	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
+)
+
+// maxBlocksAhead is how far ahead of the unsafe head preconfirmations are accepted for monitoring.
+const maxBlocksAhead = 64
+
+// monitoredSize is the number of preconfirmations, and their status, that are kept.
+const monitoredSize = 10_000
+
+// checkTimeout is the time to fetch and check a block.
+const checkTimeout = 10 * time.Second
+
+type L2Source interface {
+	InfoAndTxsByNumber(ctx context.Context, number uint64) (eth.BlockInfo, types.Transactions, error)
+}
+
+// Status is the status of a monitored preconfirmation.
+type Status struct {
+	Preconfirmation *opsigner.SignedPreconfirmation `json:"preconfirmation"`
+	// Status is pending, kept or broken.
+	Status string `json:"status"`
+	// BlockHash is the hash of the preconfirmed block, once the block is canonical.
+	BlockHash common.Hash `json:"blockHash"`
+}
+
+// Monitor checks preconfirmations of the sequencer against the canonical chain, once the unsafe head
+// reaches the preconfirmed block, and reports the broken preconfirmations.
+type Monitor struct {
+	log     log.Logger
+	metrics Metrics
+	src     L2Source
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	signal chan struct{}
+
+	mu sync.Mutex
+	// head is the unsafe head number.
+	head uint64
+	// pending holds the preconfirmations to check, by block number.
+	pending map[uint64][]*Status
+	// byTx holds the preconfirmations, pending or checked, by transaction hash.
+	byTx *simplelru.LRU[common.Hash, *Status]
+}
+
+var _ event.Deriver = (*Monitor)(nil)
+
+func NewMonitor(log log.Logger, m Metrics, src L2Source) *Monitor {
+	byTx, _ := simplelru.NewLRU[common.Hash, *Status](monitoredSize, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Monitor{
+		log:     log,
+		metrics: m,
+		src:     src,
+		ctx:     ctx,
+		cancel:  cancel,
+		signal:  make(chan struct{}, 1),
+		pending: make(map[uint64][]*Status),
+		byTx:    byTx,
+	}
+}
+
+// Start starts checking preconfirmations in the background.
+func (m *Monitor) Start() {
+	m.wg.Add(1)
+	go m.loop()
+}
+
+// Close stops checking preconfirmations.
+func (m *Monitor) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return nil
+}
+
+func (m *Monitor) OnEvent(ctx context.Context, ev event.Event) bool {
+	switch x := ev.(type) {
+	case engine.ForkchoiceUpdateEvent:
+		m.mu.Lock()
+		m.head = x.UnsafeL2Head.Number
+		m.mu.Unlock()
+		m.trigger()
+	default:
+		return false
+	}
+	return true
+}
+
+// Add adds a preconfirmation to check. The signature must have been verified.
+// Preconfirmations of transactions that are already monitored are ignored.
+func (m *Monitor) Add(p *opsigner.SignedPreconfirmation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.byTx.Contains(p.TxHash) {
+		return
+	}
+	number := uint64(p.BlockNumber)
+	if number > m.head+maxBlocksAhead {
+		m.log.Warn("Ignoring preconfirmation too far ahead of the unsafe head", "preconf", &p.Preconfirmation, "head", m.head)
+		return
+	}
+	status := &Status{Preconfirmation: p, Status: StatusPending}
+	m.byTx.Add(p.TxHash, status)
+	m.pending[number] = append(m.pending[number], status)
+	if number <= m.head {
+		m.trigger()
+	}
+}
+
+// Get returns the status of the preconfirmation of the transaction, if it is monitored.
+func (m *Monitor) Get(txHash common.Hash) (Status, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, ok := m.byTx.Get(txHash)
+	if !ok {
+		return Status{}, false
+	}
+	return *status, true
+}
+
+func (m *Monitor) trigger() {
+	select {
+	case m.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Monitor) loop() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-m.signal:
+			m.checkPending()
+		}
+	}
+}
+
+func (m *Monitor) checkPending() {
+	m.mu.Lock()
+	var numbers []uint64
+	for number := range m.pending {
+		if number <= m.head {
+			numbers = append(numbers, number)
+		}
+	}
+	m.mu.Unlock()
+	slices.Sort(numbers)
+
+	for _, number := range numbers {
+		if err := m.checkBlock(number); err != nil {
+			// retried once the head changes
+			m.log.Warn("Failed to check preconfirmations", "block", number, "err", err)
+			return
+		}
+	}
+}
+
+func (m *Monitor) checkBlock(number uint64) error {
+	ctx, cancel := context.WithTimeout(m.ctx, checkTimeout)
+	defer cancel()
+	info, txs, err := m.src.InfoAndTxsByNumber(ctx, number)
+	if err != nil {
+		return fmt.Errorf("failed to fetch block %d: %w", number, err)
+	}
+	included := make(map[common.Hash]struct{}, len(txs))
+	for _, tx := range txs {
+		included[tx.Hash()] = struct{}{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, status := range m.pending[number] {
+		status.BlockHash = info.Hash()
+		if _, ok := included[status.Preconfirmation.TxHash]; ok {
+			status.Status = StatusKept
+			m.log.Debug("Preconfirmation kept", "preconf", &status.Preconfirmation.Preconfirmation, "block", info.Hash())
+		} else {
+			status.Status = StatusBroken
+			m.log.Error("Preconfirmation broken", "preconf", &status.Preconfirmation.Preconfirmation, "block", info.Hash())
+		}
+		m.metrics.RecordPreconfirmation(status.Status)
+	}
+	delete(m.pending, number)
+	return nil
+}
+
+// Check checks the signature of the preconfirmation, and whether the preconfirmed transaction
+// is included in the preconfirmed block of the canonical chain of src.
+// It returns false, without error, if the preconfirmation was broken.
+func Check(ctx context.Context, src L2Source, p *opsigner.SignedPreconfirmation, auth *opsigner.OPStackP2PBlockAuthV1) (bool, error) {
+	if err := p.VerifySignature(auth); err != nil {
+		return false, fmt.Errorf("invalid preconfirmation: %w", err)
+	}
+	_, txs, err := src.InfoAndTxsByNumber(ctx, uint64(p.BlockNumber))
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch block %d: %w", uint64(p.BlockNumber), err)
+	}
+	for _, tx := range txs {
+		if tx.Hash() == p.TxHash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
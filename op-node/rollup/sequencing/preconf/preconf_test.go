@@ -0,0 +1,320 @@
+package preconf
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/derive"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/engine"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing/inclusion"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testutils"
+)
+
+var testChainID = eth.ChainIDFromUInt64(901)
+
+type testMetrics struct {
+	mu       sync.Mutex
+	statuses map[string]int
+}
+
+func (m *testMetrics) RecordPreconfirmation(status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.statuses == nil {
+		m.statuses = make(map[string]int)
+	}
+	m.statuses[status]++
+}
+
+func (m *testMetrics) count(status string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statuses[status]
+}
+
+type testNetwork struct {
+	signer opsigner.PreconfirmationSigner
+	err    error
+}
+
+func (n *testNetwork) SignAndPublishPreconfirmation(ctx context.Context, p opsigner.Preconfirmation) (*opsigner.SignedPreconfirmation, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+	return opsigner.SignPreconfirmation(ctx, n.signer, testChainID, p)
+}
+
+type testL2Source struct {
+	mu     sync.Mutex
+	blocks map[uint64]types.Transactions
+}
+
+func (s *testL2Source) InfoAndTxsByNumber(ctx context.Context, number uint64) (eth.BlockInfo, types.Transactions, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txs, ok := s.blocks[number]
+	if !ok {
+		return nil, nil, errBlockNotFound
+	}
+	return &testutils.MockBlockInfo{InfoNum: number, InfoHash: common.Hash{byte(number)}}, txs, nil
+}
+
+var errBlockNotFound = errors.New("block not found")
+
+func testTx(t *testing.T, nonce uint64) *types.Transaction {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(901)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(901),
+		Nonce:     nonce,
+		GasFeeCap: big.NewInt(1),
+		Gas:       21_000,
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func encodeTx(t *testing.T, tx *types.Transaction) hexutil.Bytes {
+	data, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return data
+}
+
+// buildBlock emits the events of the sequencer starting to build a block on parent with txs.
+func buildBlock(i *Issuer, parent eth.L2BlockRef, txs ...eth.Data) {
+	attrs := &derive.AttributesWithParent{
+		Attributes: &eth.PayloadAttributes{Transactions: txs},
+		Parent:     parent,
+	}
+	i.OnEvent(context.Background(), engine.BuildStartEvent{Attributes: attrs})
+	i.OnEvent(context.Background(), engine.BuildStartedEvent{Parent: parent})
+}
+
+func TestIssuer(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	auth := &opsigner.OPStackP2PBlockAuthV1{Allowed: crypto.PubkeyToAddress(key.PublicKey), Chain: testChainID}
+	parent := eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+
+	t.Run("issue", func(t *testing.T) {
+		m := &testMetrics{}
+		i := NewIssuer(testlog.Logger(t, log.LevelDebug), m, &testNetwork{signer: opsigner.NewLocalSigner(key)}, nil)
+		tx := testTx(t, 0)
+		deposit := eth.Data{types.DepositTxType, 0x01}
+		buildBlock(i, parent, deposit, encodeTx(t, tx))
+		require.NoError(t, i.Close())
+
+		p, ok := i.Get(tx.Hash())
+		require.True(t, ok)
+		require.Equal(t, eth.Uint64Quantity(11), p.BlockNumber)
+		require.NoError(t, p.VerifySignature(auth))
+		require.Equal(t, 1, m.count(StatusIssued))
+	})
+
+	t.Run("derived blocks", func(t *testing.T) {
+		m := &testMetrics{}
+		i := NewIssuer(testlog.Logger(t, log.LevelDebug), m, &testNetwork{signer: opsigner.NewLocalSigner(key)}, nil)
+		tx := testTx(t, 0)
+		attrs := &derive.AttributesWithParent{
+			Attributes:  &eth.PayloadAttributes{Transactions: []eth.Data{encodeTx(t, tx)}},
+			Parent:      parent,
+			DerivedFrom: eth.L1BlockRef{Number: 1},
+		}
+		require.False(t, i.OnEvent(context.Background(), engine.BuildStartEvent{Attributes: attrs}))
+		require.False(t, i.OnEvent(context.Background(), engine.BuildStartedEvent{Parent: parent, DerivedFrom: attrs.DerivedFrom}))
+		require.NoError(t, i.Close())
+		_, ok := i.Get(tx.Hash())
+		require.False(t, ok)
+	})
+
+	t.Run("tx pool", func(t *testing.T) {
+		m := &testMetrics{}
+		i := NewIssuer(testlog.Logger(t, log.LevelDebug), m, &testNetwork{signer: opsigner.NewLocalSigner(key)}, nil)
+		forced, pooled := testTx(t, 0), testTx(t, 0)
+		buildBlock(i, parent, encodeTx(t, forced))
+		deposit := eth.Data{types.DepositTxType, 0x01}
+		envelope := &eth.ExecutionPayloadEnvelope{ExecutionPayload: &eth.ExecutionPayload{
+			Transactions: []eth.Data{deposit, encodeTx(t, forced), encodeTx(t, pooled)},
+		}}
+		require.True(t, i.OnEvent(context.Background(), engine.BuildSealedEvent{Envelope: envelope, Ref: eth.L2BlockRef{Number: 11}}))
+		require.NoError(t, i.Close())
+
+		p, ok := i.Get(pooled.Hash())
+		require.True(t, ok)
+		require.Equal(t, eth.Uint64Quantity(11), p.BlockNumber)
+		require.NoError(t, p.VerifySignature(auth))
+		// the transaction of the attributes is preconfirmed once, when the block started building
+		require.Equal(t, 2, m.count(StatusIssued))
+	})
+
+	t.Run("other parent", func(t *testing.T) {
+		i := NewIssuer(testlog.Logger(t, log.LevelDebug), &testMetrics{}, &testNetwork{signer: opsigner.NewLocalSigner(key)}, nil)
+		tx := testTx(t, 0)
+		attrs := &derive.AttributesWithParent{
+			Attributes: &eth.PayloadAttributes{Transactions: []eth.Data{encodeTx(t, tx)}},
+			Parent:     parent,
+		}
+		i.OnEvent(context.Background(), engine.BuildStartEvent{Attributes: attrs})
+		require.False(t, i.OnEvent(context.Background(), engine.BuildStartedEvent{Parent: eth.L2BlockRef{Hash: common.Hash{2}, Number: 10}}))
+		require.NoError(t, i.Close())
+		_, ok := i.Get(tx.Hash())
+		require.False(t, ok)
+	})
+
+	t.Run("sign failure", func(t *testing.T) {
+		m := &testMetrics{}
+		i := NewIssuer(testlog.Logger(t, log.LevelCrit), m, &testNetwork{err: errors.New("boom")}, nil)
+		tx := testTx(t, 0)
+		buildBlock(i, parent, encodeTx(t, tx))
+		require.NoError(t, i.Close())
+		_, ok := i.Get(tx.Hash())
+		require.False(t, ok)
+		require.Equal(t, 1, m.count(StatusFailed))
+	})
+}
+
+type testSubmitter struct {
+	bundles  []inclusion.Bundle
+	onSubmit func(bundle inclusion.Bundle)
+}
+
+func (s *testSubmitter) SubmitBundle(bundle inclusion.Bundle) (common.Hash, error) {
+	s.bundles = append(s.bundles, bundle)
+	s.onSubmit(bundle)
+	return common.Hash{}, nil
+}
+
+func TestSendRawTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	i := NewIssuer(testlog.Logger(t, log.LevelDebug), &testMetrics{}, &testNetwork{signer: opsigner.NewLocalSigner(key)}, nil)
+	defer i.Close()
+	parent := eth.L2BlockRef{Hash: common.Hash{1}, Number: 10}
+	submitter := &testSubmitter{onSubmit: func(bundle inclusion.Bundle) {
+		txs := make([]eth.Data, len(bundle.Txs))
+		for j, tx := range bundle.Txs {
+			txs[j] = eth.Data(tx)
+		}
+		buildBlock(i, parent, txs...)
+	}}
+	api := NewSequencerAPI(i, submitter)
+
+	tx := testTx(t, 0)
+	p, err := api.SendRawTransaction(context.Background(), encodeTx(t, tx))
+	require.NoError(t, err)
+	require.Equal(t, tx.Hash(), p.TxHash)
+	require.Equal(t, eth.Uint64Quantity(11), p.BlockNumber)
+	require.Len(t, submitter.bundles, 1)
+	require.Empty(t, i.waiters)
+
+	// not preconfirmed if the transaction is not selected
+	submitter.onSubmit = func(bundle inclusion.Bundle) {}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = api.SendRawTransaction(ctx, encodeTx(t, testTx(t, 1)))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Empty(t, i.waiters)
+}
+
+func TestMonitor(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := opsigner.NewLocalSigner(key)
+	sign := func(tx *types.Transaction, number uint64) *opsigner.SignedPreconfirmation {
+		p, err := opsigner.SignPreconfirmation(context.Background(), signer, testChainID,
+			opsigner.Preconfirmation{TxHash: tx.Hash(), BlockNumber: eth.Uint64Quantity(number)})
+		require.NoError(t, err)
+		return p
+	}
+
+	kept, broken, late := testTx(t, 0), testTx(t, 1), testTx(t, 2)
+	src := &testL2Source{blocks: map[uint64]types.Transactions{
+		11: {kept},
+		12: {late},
+	}}
+	m := &testMetrics{}
+	mon := NewMonitor(testlog.Logger(t, log.LevelCrit), m, src)
+	mon.Start()
+	defer mon.Close()
+
+	mon.Add(sign(kept, 11))
+	mon.Add(sign(broken, 11))
+	mon.Add(sign(late, 11))
+	// far ahead of the head
+	far := testTx(t, 3)
+	mon.Add(sign(far, 11+maxBlocksAhead))
+
+	status, ok := mon.Get(kept.Hash())
+	require.True(t, ok)
+	require.Equal(t, StatusPending, status.Status)
+	_, ok = mon.Get(far.Hash())
+	require.False(t, ok)
+
+	mon.OnEvent(context.Background(), engine.ForkchoiceUpdateEvent{UnsafeL2Head: eth.L2BlockRef{Number: 11}})
+	require.Eventually(t, func() bool {
+		return m.count(StatusKept) == 1 && m.count(StatusBroken) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status, ok = mon.Get(kept.Hash())
+	require.True(t, ok)
+	require.Equal(t, StatusKept, status.Status)
+	require.Equal(t, common.Hash{11}, status.BlockHash)
+	status, ok = mon.Get(broken.Hash())
+	require.True(t, ok)
+	require.Equal(t, StatusBroken, status.Status)
+	status, ok = mon.Get(late.Hash())
+	require.True(t, ok)
+	require.Equal(t, StatusBroken, status.Status)
+
+	api := NewAPI(nil, mon)
+	res, err := api.GetPreconfirmation(context.Background(), broken.Hash())
+	require.NoError(t, err)
+	require.Equal(t, StatusBroken, res.Status)
+	_, err = api.GetPreconfirmation(context.Background(), far.Hash())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCheck(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	auth := &opsigner.OPStackP2PBlockAuthV1{Allowed: crypto.PubkeyToAddress(key.PublicKey), Chain: testChainID}
+	tx := testTx(t, 0)
+	src := &testL2Source{blocks: map[uint64]types.Transactions{11: {tx}, 12: {}}}
+	sign := func(number uint64) *opsigner.SignedPreconfirmation {
+		p, err := opsigner.SignPreconfirmation(context.Background(), opsigner.NewLocalSigner(key), testChainID,
+			opsigner.Preconfirmation{TxHash: tx.Hash(), BlockNumber: eth.Uint64Quantity(number)})
+		require.NoError(t, err)
+		return p
+	}
+
+	ok, err := Check(context.Background(), src, sign(11), auth)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = Check(context.Background(), src, sign(12), auth)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = Check(context.Background(), src, sign(13), auth)
+	require.ErrorIs(t, err, errBlockNotFound)
+
+	modified := sign(11)
+	modified.BlockNumber = 12
+	_, err = Check(context.Background(), src, modified, auth)
+	require.ErrorContains(t, err, "invalid preconfirmation")
+}
@@ -111,6 +111,8 @@ type Sequencer struct {
 
 	metrics Metrics
 
+	inclusion InclusionPolicy
+
 	// timeNow enables sequencer testing to mock the time
 	timeNow func() time.Time
 
@@ -122,6 +124,9 @@ type Sequencer struct {
 	latestSealed eth.L2BlockRef
 	latestHead   eth.L2BlockRef
 
+	// latestSelected are the transactions that the inclusion policy selected for the latest building job
+	latestSelected []eth.Data
+
 	latestHeadSet chan struct{}
 
 	// toBlockRef converts a payload to a block-ref, and is only configurable for test-purposes
@@ -138,6 +143,7 @@ func NewSequencer(driverCtx context.Context, log log.Logger, rollupCfg *rollup.C
 	asyncGossip AsyncGossiper,
 	metrics Metrics,
 	eng attributes.EngineController,
+	inclusion InclusionPolicy,
 ) *Sequencer {
 	return &Sequencer{
 		ctx:              driverCtx,
@@ -151,6 +157,7 @@ func NewSequencer(driverCtx context.Context, log log.Logger, rollupCfg *rollup.C
 		l1OriginSelector: l1OriginSelector,
 		metrics:          metrics,
 		eng:              eng,
+		inclusion:        inclusion,
 		timeNow:          time.Now,
 		toBlockRef:       derive.PayloadToBlockRef,
 	}
@@ -264,6 +271,7 @@ func (d *Sequencer) onInvalidPayloadAttributes(x engine.InvalidPayloadAttributes
 		"attributes_parent", x.Attributes.Parent,
 		"timestamp", x.Attributes.Attributes.Timestamp, "err", x.Err)
 
+	d.rejectSelected(x.Err)
 	d.handleInvalid()
 }
 
@@ -309,9 +317,20 @@ func (d *Sequencer) onPayloadSealInvalid(x engine.PayloadSealInvalidEvent) {
 	}
 	d.log.Error("Sequencer could not seal block",
 		"payloadID", x.Info.ID, "timestamp", x.Info.Timestamp, "err", x.Err)
+	d.rejectSelected(x.Err)
 	d.handleInvalid()
 }
 
+// rejectSelected hands the transactions of the inclusion policy back to it,
+// if they were part of the building job that failed.
+func (d *Sequencer) rejectSelected(err error) {
+	if len(d.latestSelected) == 0 {
+		return
+	}
+	d.inclusion.OnRejected(d.latestSelected, err)
+	d.latestSelected = nil
+}
+
 func (d *Sequencer) onPayloadSealExpiredError(x engine.PayloadSealExpiredErrorEvent) {
 	if d.latest.Info != x.Info {
 		return // not our payload, should be ignored.
@@ -333,6 +352,9 @@ func (d *Sequencer) onPayloadInvalid(x engine.PayloadInvalidEvent) {
 }
 
 func (d *Sequencer) onPayloadSuccess(x engine.PayloadSuccessEvent) {
+	// Any block may include the transactions of the inclusion policy,
+	// also when it was not built by this sequencer.
+	d.inclusion.OnIncluded(x.Envelope.ExecutionPayload)
 	// d.latest as building state may already be empty,
 	// if the forkchoice update (that dropped the stale building job) was received before the payload-success.
 	if d.latest.Ref != (eth.L2BlockRef{}) && d.latest.Ref.Hash != x.Envelope.ExecutionPayload.BlockHash {
@@ -590,9 +612,18 @@ func (d *Sequencer) startBuildingBlock() {
 		d.log.Warn("Sequencing temporarily without user transactions, in recover mode")
 	}
 
+	// The inclusion policy may add transactions to the block, ahead of the tx pool,
+	// but only to blocks that may include sequencer transactions.
+	var selected []eth.Data
+	if !attrs.NoTxPool {
+		selected = d.inclusion.SelectTransactions(ctx, l2Head, attrs)
+		attrs.Transactions = append(attrs.Transactions, selected...)
+	}
+
 	d.log.Debug("prepared attributes for new block",
 		"num", l2Head.Number+1, "time", uint64(attrs.Timestamp),
-		"origin", l1Origin, "origin_time", l1Origin.Time, "noTxPool", attrs.NoTxPool)
+		"origin", l1Origin, "origin_time", l1Origin.Time, "noTxPool", attrs.NoTxPool,
+		"selected", len(selected))
 
 	// Start a payload building process.
 	withParent := &derive.AttributesWithParent{
@@ -608,6 +639,7 @@ func (d *Sequencer) startBuildingBlock() {
 	// Reset building state, and remember what we are building on.
 	// If we get a forkchoice update that conflicts, we will have to abort building.
 	d.latest = BuildingState{Onto: l2Head}
+	d.latestSelected = selected
 
 	d.emitter.Emit(d.ctx, engine.BuildStartEvent{
 		Attributes: withParent,
@@ -3,6 +3,7 @@ package sequencing
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/rand" // nosemgrep
 	"testing"
@@ -161,6 +162,27 @@ func (f *FakeAsyncGossip) Start() {
 
 var _ AsyncGossiper = (*FakeAsyncGossip)(nil)
 
+// FakeInclusionPolicy selects the given txs for every block, and records the included and rejected txs.
+type FakeInclusionPolicy struct {
+	txs      []eth.Data
+	included []*eth.ExecutionPayload
+	rejected []eth.Data
+}
+
+var _ InclusionPolicy = (*FakeInclusionPolicy)(nil)
+
+func (f *FakeInclusionPolicy) SelectTransactions(ctx context.Context, parent eth.L2BlockRef, attrs *eth.PayloadAttributes) []eth.Data {
+	return f.txs
+}
+
+func (f *FakeInclusionPolicy) OnIncluded(payload *eth.ExecutionPayload) {
+	f.included = append(f.included, payload)
+}
+
+func (f *FakeInclusionPolicy) OnRejected(txs []eth.Data, err error) {
+	f.rejected = append(f.rejected, txs...)
+}
+
 type fakeEngController struct{}
 
 func (fakeEngController) RequestForkchoiceUpdate(ctx context.Context) {}
@@ -634,6 +656,64 @@ func TestSequencerBuild(t *testing.T) {
 	require.Equal(t, testClock.Now(), nextTime, "start asap on the next block")
 }
 
+func TestSequencerInclusionPolicy(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelError)
+	seq, deps := createSequencer(logger)
+	testClock := clock.NewSimpleClock()
+	seq.timeNow = testClock.Now
+	testClock.SetTime(30000)
+	emitter := &testutils.MockEmitter{}
+	seq.AttachEmitter(emitter)
+	require.NoError(t, seq.Init(context.Background(), true))
+
+	head := eth.L2BlockRef{
+		Hash:   common.Hash{0x22},
+		Number: 100,
+		L1Origin: eth.BlockID{
+			Hash:   common.Hash{0x11, 0xa},
+			Number: 1000,
+		},
+		Time: uint64(testClock.Now().Unix()),
+	}
+	seq.OnEvent(context.Background(), engine.ForkchoiceUpdateEvent{UnsafeL2Head: head})
+	l1Origin := eth.L1BlockRef{
+		Hash:       common.Hash{0x11, 0xb},
+		ParentHash: common.Hash{0x11, 0xa},
+		Number:     1001,
+		Time:       29998,
+	}
+	deps.l1OriginSelector.l1OriginFn = func(l2Head eth.L2BlockRef) (eth.L1BlockRef, error) {
+		return l1Origin, nil
+	}
+	deps.inclusion.txs = []eth.Data{{0xaa}, {0xbb}}
+
+	var sentAttributes *derive.AttributesWithParent
+	emitter.ExpectOnceRun(func(ev event.Event) {
+		x, ok := ev.(engine.BuildStartEvent)
+		require.True(t, ok)
+		sentAttributes = x.Attributes
+	})
+	seq.OnEvent(context.Background(), SequencerActionEvent{})
+	emitter.AssertExpectations(t)
+	require.Equal(t, []eth.Data{encodeID(l1Origin.ID()), {0xaa}, {0xbb}}, sentAttributes.Attributes.Transactions,
+		"selected txs are included after the deposits")
+
+	// The selected txs are handed back to the policy if the engine rejects the attributes
+	seq.OnEvent(context.Background(), engine.InvalidPayloadAttributesEvent{
+		Attributes: sentAttributes,
+		Err:        errors.New("invalid tx"),
+	})
+	require.Equal(t, deps.inclusion.txs, deps.inclusion.rejected)
+
+	// The policy is told about inserted payloads
+	payload := &eth.ExecutionPayload{BlockHash: common.Hash{0x12, 0x34}, Transactions: sentAttributes.Attributes.Transactions}
+	seq.OnEvent(context.Background(), engine.PayloadSuccessEvent{
+		Envelope: &eth.ExecutionPayloadEnvelope{ExecutionPayload: payload},
+		Ref:      eth.L2BlockRef{Hash: payload.BlockHash},
+	})
+	require.Equal(t, []*eth.ExecutionPayload{payload}, deps.inclusion.included)
+}
+
 func TestSequencerL1TemporaryErrorEvent(t *testing.T) {
 	logger := testlog.Logger(t, log.LevelError)
 	seq, deps := createSequencer(logger)
@@ -691,6 +771,7 @@ type sequencerTestDeps struct {
 	seqState         *BasicSequencerStateListener
 	conductor        *FakeConductor
 	asyncGossip      *FakeAsyncGossip
+	inclusion        *FakeInclusionPolicy
 }
 
 func createSequencer(log log.Logger) (*Sequencer, *sequencerTestDeps) {
@@ -731,10 +812,11 @@ func createSequencer(log log.Logger) (*Sequencer, *sequencerTestDeps) {
 		seqState:    &BasicSequencerStateListener{},
 		conductor:   &FakeConductor{},
 		asyncGossip: &FakeAsyncGossip{},
+		inclusion:   &FakeInclusionPolicy{},
 	}
 	seq := NewSequencer(context.Background(), log, cfg, deps.attribBuilder,
 		deps.l1OriginSelector, deps.seqState, deps.conductor,
-		deps.asyncGossip, metrics.NoopMetrics, fakeEngController{})
+		deps.asyncGossip, metrics.NoopMetrics, fakeEngController{}, deps.inclusion)
 	// We create mock payloads, with the epoch-id as tx[0], rather than proper L1Block-info deposit tx.
 	seq.toBlockRef = func(rollupCfg *rollup.Config, payload *eth.ExecutionPayload) (eth.L2BlockRef, error) {
 		return eth.L2BlockRef{
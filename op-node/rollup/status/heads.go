@@ -0,0 +1,197 @@
+package status
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/engine"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/event"
+)
+
+type HeadKind string
+
+const (
+	// HeadUnsafe is a new unsafe head, e.g. built by the sequencer or received via p2p.
+	HeadUnsafe HeadKind = "unsafe"
+	// HeadSafe is a new local-safe head: derived from L1 by this node.
+	HeadSafe HeadKind = "safe"
+	// HeadCrossSafe is a new cross-safe head: safe, with all cross-chain dependencies safe as well.
+	// Pre-interop every local-safe block is immediately cross-safe.
+	HeadCrossSafe HeadKind = "cross-safe"
+	// HeadFinalized is a new finalized head: derived from finalized L1 data.
+	HeadFinalized HeadKind = "finalized"
+	// HeadReorg is an unsafe head that does not extend the previous unsafe head.
+	HeadReorg HeadKind = "reorg"
+)
+
+// headSubscriptionBuffer is the number of events a subscriber may fall behind,
+// before its subscription is dropped.
+const headSubscriptionBuffer = 256
+
+// headSourcesSize is the number of derivation sources that are remembered,
+// to attribute finalized heads to the L1 block they were derived from.
+const headSourcesSize = 1024
+
+// HeadEvent is a change of one of the L2 heads.
+type HeadEvent struct {
+	Kind HeadKind `json:"kind"`
+	// Head is the new head. The head includes its L1 origin.
+	Head eth.L2BlockRef `json:"head"`
+	// Source is the L1 block the head was derived from.
+	// Zero for unsafe heads and reorgs, and for finalized heads that were derived before this node started.
+	Source eth.L1BlockRef `json:"source"`
+	// Previous is the unsafe head that was reorged out. Only set for reorgs.
+	Previous *eth.L2BlockRef `json:"previous,omitempty"`
+}
+
+// HeadSubscription receives head events, until it is unsubscribed or dropped.
+type HeadSubscription struct {
+	n      *HeadNotifier
+	events chan HeadEvent
+	err    chan struct{}
+	once   sync.Once
+}
+
+// Events returns the head events, in the order they happened.
+func (s *HeadSubscription) Events() <-chan HeadEvent {
+	return s.events
+}
+
+// Err is closed when the subscription is dropped, since the subscriber fell behind, or unsubscribed.
+func (s *HeadSubscription) Err() <-chan struct{} {
+	return s.err
+}
+
+func (s *HeadSubscription) Unsubscribe() {
+	s.n.remove(s)
+}
+
+func (s *HeadSubscription) close() {
+	s.once.Do(func() { close(s.err) })
+}
+
+type derivedFrom struct {
+	last   eth.L2BlockRef
+	source eth.L1BlockRef
+}
+
+// HeadNotifier notifies subscribers of changes of the L2 heads, and of reorgs of the unsafe chain.
+// Subscribers never block the event processing: a subscriber that falls behind is dropped.
+type HeadNotifier struct {
+	log log.Logger
+
+	// unsafe is the last unsafe head, to detect reorgs.
+	unsafe eth.L2BlockRef
+	// sources are the last L2 blocks derived from each L1 block, in order, to find the source of finalized heads.
+	sources []derivedFrom
+
+	mu   sync.Mutex
+	subs map[*HeadSubscription]struct{}
+}
+
+var _ event.Deriver = (*HeadNotifier)(nil)
+
+func NewHeadNotifier(log log.Logger) *HeadNotifier {
+	return &HeadNotifier{
+		log:  log,
+		subs: make(map[*HeadSubscription]struct{}),
+	}
+}
+
+func (n *HeadNotifier) OnEvent(ctx context.Context, ev event.Event) bool {
+	switch x := ev.(type) {
+	case engine.UnsafeUpdateEvent:
+		n.onUnsafe(x.Ref)
+	case engine.LocalSafeUpdateEvent:
+		n.publish(HeadEvent{Kind: HeadSafe, Head: x.Ref, Source: x.Source})
+	case engine.SafeDerivedEvent:
+		n.addSource(x.Safe, x.Source)
+		n.publish(HeadEvent{Kind: HeadCrossSafe, Head: x.Safe, Source: x.Source})
+	case engine.FinalizedUpdateEvent:
+		n.publish(HeadEvent{Kind: HeadFinalized, Head: x.Ref, Source: n.sourceOf(x.Ref)})
+	case engine.EngineResetConfirmedEvent:
+		n.sources = n.sources[:0]
+		n.onUnsafe(x.LocalUnsafe)
+	default:
+		return false
+	}
+	return true
+}
+
+func (n *HeadNotifier) onUnsafe(ref eth.L2BlockRef) {
+	prev := n.unsafe
+	n.unsafe = ref
+	if prev == ref {
+		return
+	}
+	// A gap in the unsafe chain, e.g. after EL sync, can not be told apart from a reorg without fetching
+	// the chain, and is not reported as reorg.
+	if prev != (eth.L2BlockRef{}) && (ref.Number <= prev.Number || (ref.Number == prev.Number+1 && ref.ParentHash != prev.Hash)) {
+		n.log.Info("Unsafe head reorg", "previous", prev, "head", ref)
+		n.publish(HeadEvent{Kind: HeadReorg, Head: ref, Previous: &prev})
+	}
+	n.publish(HeadEvent{Kind: HeadUnsafe, Head: ref})
+}
+
+func (n *HeadNotifier) addSource(safe eth.L2BlockRef, source eth.L1BlockRef) {
+	if len(n.sources) > 0 && n.sources[len(n.sources)-1].source == source {
+		n.sources[len(n.sources)-1].last = safe
+		return
+	}
+	if len(n.sources) >= headSourcesSize {
+		n.sources = append(n.sources[:0], n.sources[1:]...)
+	}
+	n.sources = append(n.sources, derivedFrom{last: safe, source: source})
+}
+
+// sourceOf returns the first L1 block that the L2 chain up to and including ref was derived from.
+func (n *HeadNotifier) sourceOf(ref eth.L2BlockRef) eth.L1BlockRef {
+	for i, d := range n.sources {
+		if d.last.Number < ref.Number {
+			continue
+		}
+		// the block is not derived from the first remembered source if it was derived earlier
+		if i == 0 && d.last.Number > ref.Number {
+			return eth.L1BlockRef{}
+		}
+		return d.source
+	}
+	return eth.L1BlockRef{}
+}
+
+// Subscribe subscribes to all head events. The subscription must be unsubscribed when no longer used.
+func (n *HeadNotifier) Subscribe() *HeadSubscription {
+	sub := &HeadSubscription{
+		n:      n,
+		events: make(chan HeadEvent, headSubscriptionBuffer),
+		err:    make(chan struct{}),
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.subs[sub] = struct{}{}
+	return sub
+}
+
+func (n *HeadNotifier) remove(sub *HeadSubscription) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.subs, sub)
+	sub.close()
+}
+
+func (n *HeadNotifier) publish(ev HeadEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for sub := range n.subs {
+		select {
+		case sub.events <- ev:
+		default:
+			n.log.Warn("Dropping head subscription that fell behind", "kind", ev.Kind, "head", ev.Head)
+			delete(n.subs, sub)
+			sub.close()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package status
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/engine"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+func l2Ref(number uint64, hash byte, parent byte) eth.L2BlockRef {
+	return eth.L2BlockRef{Number: number, Hash: common.Hash{hash}, ParentHash: common.Hash{parent}}
+}
+
+func nextEvent(t *testing.T, sub *HeadSubscription) HeadEvent {
+	select {
+	case ev := <-sub.Events():
+		return ev
+	default:
+		t.Fatal("expected head event")
+		return HeadEvent{}
+	}
+}
+
+func TestHeadNotifier(t *testing.T) {
+	n := NewHeadNotifier(testlog.Logger(t, log.LevelDebug))
+	sub := n.Subscribe()
+	defer sub.Unsubscribe()
+	ctx := context.Background()
+
+	a1, a2, b2 := l2Ref(1, 0xa1, 0xa0), l2Ref(2, 0xa2, 0xa1), l2Ref(2, 0xb2, 0xa1)
+	l1A, l1B := eth.L1BlockRef{Number: 100, Hash: common.Hash{0x10}}, eth.L1BlockRef{Number: 101, Hash: common.Hash{0x11}}
+
+	t.Run("unsafe", func(t *testing.T) {
+		n.OnEvent(ctx, engine.UnsafeUpdateEvent{Ref: a1})
+		require.Equal(t, HeadEvent{Kind: HeadUnsafe, Head: a1}, nextEvent(t, sub))
+		n.OnEvent(ctx, engine.UnsafeUpdateEvent{Ref: a2})
+		require.Equal(t, HeadEvent{Kind: HeadUnsafe, Head: a2}, nextEvent(t, sub))
+	})
+
+	t.Run("reorg", func(t *testing.T) {
+		n.OnEvent(ctx, engine.UnsafeUpdateEvent{Ref: b2})
+		require.Equal(t, HeadEvent{Kind: HeadReorg, Head: b2, Previous: &a2}, nextEvent(t, sub))
+		require.Equal(t, HeadEvent{Kind: HeadUnsafe, Head: b2}, nextEvent(t, sub))
+		// a gap is not a reorg
+		n.OnEvent(ctx, engine.UnsafeUpdateEvent{Ref: l2Ref(10, 0xa9, 0xa8)})
+		require.Equal(t, HeadUnsafe, nextEvent(t, sub).Kind)
+	})
+
+	t.Run("safe and finalized", func(t *testing.T) {
+		n.OnEvent(ctx, engine.LocalSafeUpdateEvent{Ref: a1, Source: l1A})
+		require.Equal(t, HeadEvent{Kind: HeadSafe, Head: a1, Source: l1A}, nextEvent(t, sub))
+		n.OnEvent(ctx, engine.SafeDerivedEvent{Safe: a1, Source: l1A})
+		require.Equal(t, HeadEvent{Kind: HeadCrossSafe, Head: a1, Source: l1A}, nextEvent(t, sub))
+		n.OnEvent(ctx, engine.SafeDerivedEvent{Safe: b2, Source: l1B})
+		require.Equal(t, HeadEvent{Kind: HeadCrossSafe, Head: b2, Source: l1B}, nextEvent(t, sub))
+
+		n.OnEvent(ctx, engine.FinalizedUpdateEvent{Ref: a1})
+		require.Equal(t, HeadEvent{Kind: HeadFinalized, Head: a1, Source: l1A}, nextEvent(t, sub))
+		n.OnEvent(ctx, engine.FinalizedUpdateEvent{Ref: b2})
+		require.Equal(t, HeadEvent{Kind: HeadFinalized, Head: b2, Source: l1B}, nextEvent(t, sub))
+		// unknown source
+		n.OnEvent(ctx, engine.FinalizedUpdateEvent{Ref: l2Ref(0, 0xa0, 0)})
+		require.Equal(t, eth.L1BlockRef{}, nextEvent(t, sub).Source)
+	})
+
+	t.Run("slow subscriber", func(t *testing.T) {
+		slow := n.Subscribe()
+		for i := uint64(0); i <= headSubscriptionBuffer; i++ {
+			n.OnEvent(ctx, engine.UnsafeUpdateEvent{Ref: l2Ref(100+i, byte(i), byte(i-1))})
+		}
+		select {
+		case <-slow.Err():
+		default:
+			t.Fatal("expected slow subscriber to be dropped")
+		}
+		slow.Unsubscribe() // no-op after being dropped
+	})
+}
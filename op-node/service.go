@@ -25,6 +25,8 @@ import (
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/engine"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/finality"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/interop"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing/inclusion"
+	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sequencing/preconf"
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup/sync"
 	"github.com/tokamak-network/tokamak-thanos/op-service/cliiface"
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
@@ -34,6 +36,7 @@ import (
 	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
 	"github.com/tokamak-network/tokamak-thanos/op-service/rpc"
 	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 	"github.com/tokamak-network/tokamak-thanos/op-supervisor/supervisor/backend/depset"
 )
 
@@ -67,6 +70,11 @@ func NewConfig(ctx cliiface.Context, log log.Logger) (*config.Config, error) {
 
 	driverConfig := NewDriverConfig(ctx)
 
+	inclusionConfig, err := NewInclusionConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inclusion policy config: %w", err)
+	}
+
 	p2pSignerSetup, err := p2pcli.LoadSignerSetup(ctx, log)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load p2p signer: %w", err)
@@ -107,17 +115,22 @@ func NewConfig(ctx cliiface.Context, log log.Logger) (*config.Config, error) {
 		Rollup:                      *rollupConfig,
 		DependencySet:               depSet,
 		Driver:                      *driverConfig,
+		Inclusion:                   *inclusionConfig,
+		Preconf:                     NewPreconfConfig(ctx),
 		Beacon:                      NewBeaconEndpointConfig(ctx),
 		InteropConfig:               NewSupervisorEndpointConfig(ctx),
 		RPC:                         rpc.ReadCLIConfig(ctx.(*cli.Context)),
 		Metrics:                     opmetrics.ReadCLIConfig(ctx.(*cli.Context)),
 		Pprof:                       oppprof.ReadCLIConfig(ctx.(*cli.Context)),
+		Tracing:                     tracing.ReadCLIConfig(ctx.(*cli.Context)),
 		P2P:                         p2pConfig,
 		P2PSigner:                   p2pSignerSetup,
 		L1EpochPollInterval:         ctx.Duration(flags.L1EpochPollIntervalFlag.Name),
 		RuntimeConfigReloadInterval: ctx.Duration(flags.RuntimeConfigReloadIntervalFlag.Name),
 		ConfigPersistence:           configPersistence,
 		SafeDBPath:                  ctx.String(flags.SafeDBPath.Name),
+		SafeDBRetention:             ctx.Uint64(flags.SafeDBRetention.Name),
+		JournalPath:                 ctx.String(flags.JournalPath.Name),
 		Sync:                        *syncConfig,
 		L2FollowSource:              NewL2FollowSourceConfig(ctx),
 		RollupHalt:                  haltOption,
@@ -159,6 +172,33 @@ func NewSupervisorEndpointConfig(ctx cliiface.Context) *interop.Config {
 	}
 }
 
+func NewInclusionConfig(ctx cliiface.Context) (*inclusion.Config, error) {
+	var denyList []common.Address
+	for _, addr := range ctx.StringSlice(flags.SequencerDenyListFlag.Name) {
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid deny-list address: %q", addr)
+		}
+		denyList = append(denyList, common.HexToAddress(addr))
+	}
+	return &inclusion.Config{
+		ForcedTxsPath:    ctx.Path(flags.SequencerForcedTxsFlag.Name),
+		DenyList:         denyList,
+		SenderRateLimit:  ctx.Float64(flags.SequencerSenderRateLimitFlag.Name),
+		SenderBurst:      ctx.Int(flags.SequencerSenderBurstFlag.Name),
+		MaxBundles:       ctx.Int(flags.SequencerBundlesMaxFlag.Name),
+		RPCAddr:          ctx.String(flags.SequencerBundlesRPCAddrFlag.Name),
+		RPCPort:          ctx.Int(flags.SequencerBundlesRPCPortFlag.Name),
+		RPCJwtSecretPath: ctx.String(flags.SequencerBundlesJWTSecretFlag.Name),
+	}, nil
+}
+
+func NewPreconfConfig(ctx cliiface.Context) preconf.Config {
+	return preconf.Config{
+		Enabled: ctx.Bool(flags.SequencerPreconfEnabledFlag.Name),
+		Monitor: ctx.Bool(flags.PreconfMonitorFlag.Name),
+	}
+}
+
 func NewBeaconEndpointConfig(ctx cliiface.Context) config.L1BeaconEndpointSetup {
 	return &config.L1BeaconEndpointConfig{
 		BeaconAddr:             ctx.String(flags.BeaconAddr.Name),
@@ -11,6 +11,7 @@ import (
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
 	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
 )
 
@@ -47,6 +48,8 @@ type CLIConfig struct {
 
 	PprofConfig oppprof.CLIConfig
 
+	TracingConfig tracing.CLIConfig
+
 	// DGFAddress is the DisputeGameFactory contract address.
 	DGFAddress string
 
@@ -73,6 +76,9 @@ func (c *CLIConfig) Check() error {
 	if err := c.PprofConfig.Check(); err != nil {
 		return err
 	}
+	if err := c.TracingConfig.Check(); err != nil {
+		return err
+	}
 	if err := c.TxMgrConfig.Check(); err != nil {
 		return err
 	}
@@ -108,6 +114,7 @@ func NewConfig(ctx *cli.Context) *CLIConfig {
 		LogConfig:                    oplog.ReadCLIConfig(ctx),
 		MetricsConfig:                opmetrics.ReadCLIConfig(ctx),
 		PprofConfig:                  oppprof.ReadCLIConfig(ctx),
+		TracingConfig:                tracing.ReadCLIConfig(ctx),
 		DGFAddress:                   ctx.String(flags.DisputeGameFactoryAddressFlag.Name),
 		ProposalInterval:             ctx.Duration(flags.ProposalIntervalFlag.Name),
 		DisputeGameType:              uint32(ctx.Uint(flags.DisputeGameTypeFlag.Name)),
@@ -18,11 +18,13 @@ import (
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
 	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 	"github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 var ErrAlreadyStopped = errors.New("already stopped")
@@ -62,9 +64,10 @@ type ProposerService struct {
 
 	Version string
 
-	pprofService *oppprof.Service
-	metricsSrv   *httputil.HTTPServer
-	rpcServer    *oprpc.Server
+	pprofService   *oppprof.Service
+	metricsSrv     *httputil.HTTPServer
+	rpcServer      *oprpc.Server
+	tracerProvider *sdktrace.TracerProvider
 
 	balanceMetricer io.Closer
 
@@ -87,6 +90,9 @@ func (ps *ProposerService) initFromCLIConfig(ctx context.Context, version string
 	ps.Log = log
 
 	ps.initMetrics(cfg)
+	if err := ps.initTracing(ctx, version, cfg); err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
 
 	ps.PollInterval = cfg.PollInterval
 	ps.NetworkTimeout = cfg.TxMgrConfig.NetworkTimeout
@@ -167,6 +173,19 @@ func (ps *ProposerService) initTxManager(cfg *CLIConfig) error {
 	return nil
 }
 
+func (ps *ProposerService) initTracing(ctx context.Context, version string, cfg *CLIConfig) error {
+	if !cfg.TracingConfig.Enabled {
+		return nil
+	}
+	tp, err := tracing.Start(ctx, cfg.TracingConfig, "op-proposer", version)
+	if err != nil {
+		return err
+	}
+	ps.tracerProvider = tp
+	ps.Log.Info("Started tracing", "endpoint", cfg.TracingConfig.Endpoint)
+	return nil
+}
+
 func (ps *ProposerService) initPProf(cfg *CLIConfig) error {
 	ps.pprofService = oppprof.New(
 		cfg.PprofConfig.ListenEnabled,
@@ -326,6 +345,11 @@ func (ps *ProposerService) Stop(ctx context.Context) error {
 	if ps.RollupProvider != nil {
 		ps.RollupProvider.Close()
 	}
+	if ps.tracerProvider != nil {
+		if err := ps.tracerProvider.Shutdown(ctx); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to shut down tracing: %w", err))
+		}
+	}
 
 	if result == nil {
 		ps.stopped.Store(true)
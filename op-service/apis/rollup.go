@@ -39,6 +39,14 @@ type RollupSafeAtServer interface {
 	SafeHeadAtL1Block(ctx context.Context, blockNum hexutil.Uint64) (*eth.SafeHeadResponse, error)
 }
 
+type RollupSafeRangeClient interface {
+	SafeHeadsInRange(ctx context.Context, start uint64, end uint64) ([]eth.SafeHeadResponse, error)
+}
+
+type RollupSafeRangeServer interface {
+	SafeHeadsInRange(ctx context.Context, start hexutil.Uint64, end hexutil.Uint64) ([]eth.SafeHeadResponse, error)
+}
+
 type SequencerActivity interface {
 	StartSequencer(ctx context.Context, unsafeHead common.Hash) error
 	StopSequencer(ctx context.Context) (common.Hash, error)
@@ -81,6 +89,7 @@ type RollupNodeClient interface {
 	RollupSyncStatus
 	RollupOutputClient
 	RollupSafeAtClient
+	RollupSafeRangeClient
 }
 
 type RollupNodeServer interface {
@@ -90,6 +99,7 @@ type RollupNodeServer interface {
 	RollupSyncStatus
 	RollupOutputServer
 	RollupSafeAtServer
+	RollupSafeRangeServer
 }
 
 type RollupClient interface {
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NewGethRPCClient returns a go-ethereum RPC client that sends its calls to the given RPC, so that the RPC can be used
+// where a *rpc.Client is needed, such as by an ethclient.Client.
+// The client is an HTTP client, so it does not support subscriptions. Closing the client closes the RPC.
+func NewGethRPCClient(ctx context.Context, c RPC) (*rpc.Client, error) {
+	return rpc.DialOptions(ctx, "http://rpc.invalid", rpc.WithHTTPClient(&http.Client{Transport: &rpcTransport{rpc: c}}))
+}
+
+// DialGethRPCClient dials the go-ethereum RPC client of the address, which may list multiple endpoints,
+// see ParseMultiAddr.
+func DialGethRPCClient(ctx context.Context, lgr log.Logger, addr string, opts ...rpc.ClientOption) (*rpc.Client, error) {
+	if !IsMultiAddr(addr) {
+		return rpc.DialOptions(ctx, addr, opts...)
+	}
+	cfg, err := ParseMultiAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	multi, err := NewMultiRPC(ctx, lgr, cfg, WithGethRPCOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+	return NewGethRPCClient(ctx, multi)
+}
+
+type jsonrpcMessage struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Error   *jsonError      `json:"error,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+}
+
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// rpcTransport serves the JSON-RPC requests of a go-ethereum HTTP client with an RPC.
+type rpcTransport struct {
+	rpc RPC
+}
+
+func (t *rpcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+		var msgs []*jsonrpcMessage
+		if err := json.Unmarshal(body, &msgs); err != nil {
+			return nil, fmt.Errorf("invalid batch request: %w", err)
+		}
+		if out, err = t.batch(req.Context(), msgs); err != nil {
+			return nil, err
+		}
+	} else {
+		var msg jsonrpcMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+		if out, err = t.call(req.Context(), &msg); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(resp)),
+		ContentLength: int64(len(resp)),
+		Request:       req,
+	}, nil
+}
+
+// CloseIdleConnections is called when the go-ethereum client is closed.
+func (t *rpcTransport) CloseIdleConnections() {
+	t.rpc.Close()
+}
+
+func callArgs(msg *jsonrpcMessage) ([]any, error) {
+	if len(msg.Params) == 0 {
+		return nil, nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(msg.Params, &raw); err != nil {
+		return nil, fmt.Errorf("invalid params of %s: %w", msg.Method, err)
+	}
+	args := make([]any, len(raw))
+	for i, p := range raw {
+		args[i] = p
+	}
+	return args, nil
+}
+
+func (t *rpcTransport) call(ctx context.Context, msg *jsonrpcMessage) (*jsonrpcMessage, error) {
+	args, err := callArgs(msg)
+	if err != nil {
+		return nil, err
+	}
+	var result json.RawMessage
+	err = t.rpc.CallContext(ctx, &result, msg.Method, args...)
+	return response(msg, result, err)
+}
+
+func (t *rpcTransport) batch(ctx context.Context, msgs []*jsonrpcMessage) ([]*jsonrpcMessage, error) {
+	batch := make([]rpc.BatchElem, len(msgs))
+	for i, msg := range msgs {
+		args, err := callArgs(msg)
+		if err != nil {
+			return nil, err
+		}
+		batch[i] = rpc.BatchElem{Method: msg.Method, Args: args, Result: new(json.RawMessage)}
+	}
+	if err := t.rpc.BatchCallContext(ctx, batch); err != nil {
+		return nil, err
+	}
+	out := make([]*jsonrpcMessage, len(msgs))
+	for i, msg := range msgs {
+		resp, err := response(msg, *batch[i].Result.(*json.RawMessage), batch[i].Error)
+		if err != nil {
+			// A batch element that failed to reach any endpoint fails like a call that returned no response.
+			resp = errorResponse(msg, &jsonError{Code: -32603, Message: err.Error()})
+		}
+		out[i] = resp
+	}
+	return out, nil
+}
+
+// response returns the JSON-RPC response of the call result. Errors that are not error responses of
+// an endpoint are returned as-is, so that they fail the request.
+func response(msg *jsonrpcMessage, result json.RawMessage, err error) (*jsonrpcMessage, error) {
+	if err != nil {
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) {
+			return nil, err
+		}
+		jsonErr := &jsonError{Code: rpcErr.ErrorCode(), Message: rpcErr.Error()}
+		var dataErr rpc.DataError
+		if errors.As(err, &dataErr) {
+			jsonErr.Data = dataErr.ErrorData()
+		}
+		return errorResponse(msg, jsonErr), nil
+	}
+	if len(result) == 0 {
+		result = json.RawMessage("null")
+	}
+	return &jsonrpcMessage{Version: "2.0", ID: msg.ID, Result: result}, nil
+}
+
+func errorResponse(msg *jsonrpcMessage, err *jsonError) *jsonrpcMessage {
+	return &jsonrpcMessage{Version: "2.0", ID: msg.ID, Error: err}
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+)
+
+// ErrReplaySubscription is returned when subscribing through a replay RPC,
+// since subscriptions are not recorded in a journal.
+var ErrReplaySubscription = errors.New("subscriptions are not supported when replaying a journal")
+
+// RecordingRPC records all calls and their responses to a journal.
+// Subscriptions are not recorded.
+type RecordingRPC struct {
+	c      RPC
+	w      *journal.Writer
+	source string
+}
+
+var _ RPC = (*RecordingRPC)(nil)
+
+// NewRecordingRPC wraps the RPC to record its calls to the journal as calls to source.
+func NewRecordingRPC(c RPC, w *journal.Writer, source string) *RecordingRPC {
+	return &RecordingRPC{c: c, w: w, source: source}
+}
+
+func (r *RecordingRPC) Close() {
+	r.c.Close()
+}
+
+func (r *RecordingRPC) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	var raw json.RawMessage
+	err := r.c.CallContext(ctx, &raw, method, args...)
+	r.w.WriteCall(r.source, method, args, raw, err)
+	if err != nil || result == nil {
+		return err
+	}
+	return json.Unmarshal(raw, result)
+}
+
+func (r *RecordingRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	raws := make([]json.RawMessage, len(b))
+	batch := make([]rpc.BatchElem, len(b))
+	for i, elem := range b {
+		batch[i] = rpc.BatchElem{Method: elem.Method, Args: elem.Args, Result: &raws[i]}
+	}
+	if err := r.c.BatchCallContext(ctx, batch); err != nil {
+		return err
+	}
+	for i := range b {
+		r.w.WriteCall(r.source, b[i].Method, b[i].Args, raws[i], batch[i].Error)
+		b[i].Error = batch[i].Error
+		if b[i].Error == nil && b[i].Result != nil {
+			b[i].Error = json.Unmarshal(raws[i], b[i].Result)
+		}
+	}
+	return nil
+}
+
+func (r *RecordingRPC) EthSubscribe(ctx context.Context, channel any, args ...any) (ethereum.Subscription, error) {
+	return r.c.EthSubscribe(ctx, channel, args...)
+}
+
+func (r *RecordingRPC) Subscribe(ctx context.Context, namespace string, channel any, args ...any) (ethereum.Subscription, error) {
+	return r.c.Subscribe(ctx, namespace, channel, args...)
+}
+
+// ReplayRPC serves the responses recorded by a RecordingRPC.
+type ReplayRPC struct {
+	responses *journal.Responses
+	source    string
+}
+
+var _ RPC = (*ReplayRPC)(nil)
+
+// NewReplayRPC serves the recorded responses of calls to source.
+func NewReplayRPC(responses *journal.Responses, source string) *ReplayRPC {
+	return &ReplayRPC{responses: responses, source: source}
+}
+
+func (r *ReplayRPC) Close() {}
+
+func (r *ReplayRPC) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	return r.responses.Call(r.source, method, args, result)
+}
+
+func (r *ReplayRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	for i := range b {
+		b[i].Error = r.responses.Call(r.source, b[i].Method, b[i].Args, b[i].Result)
+	}
+	return nil
+}
+
+func (r *ReplayRPC) EthSubscribe(ctx context.Context, channel any, args ...any) (ethereum.Subscription, error) {
+	return nil, ErrReplaySubscription
+}
+
+func (r *ReplayRPC) Subscribe(ctx context.Context, namespace string, channel any, args ...any) (ethereum.Subscription, error) {
+	return nil, ErrReplaySubscription
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestRecordAndReplayRPC(t *testing.T) {
+	endpoint := &fakeEndpoint{results: map[string]string{
+		"eth_chainId":     `"0x2a"`,
+		"eth_blockNumber": `"0x10"`,
+	}}
+	var buf bytes.Buffer
+	w := journal.NewWriter(nopCloser{&buf})
+	recording := NewRecordingRPC(endpoint, w, "l1")
+
+	ctx := context.Background()
+	var chainID hexutil.Uint64
+	require.NoError(t, recording.CallContext(ctx, &chainID, "eth_chainId"))
+	require.Equal(t, hexutil.Uint64(42), chainID)
+	err := recording.CallContext(ctx, nil, "eth_call", map[string]string{"to": "0x00"}, "latest")
+	require.ErrorIs(t, err, revertError{})
+
+	var num hexutil.Uint64
+	batch := []rpc.BatchElem{
+		{Method: "eth_blockNumber", Result: &num},
+		{Method: "eth_call", Args: []any{"0x01"}, Result: new(string)},
+	}
+	require.NoError(t, recording.BatchCallContext(ctx, batch))
+	require.Equal(t, hexutil.Uint64(16), num)
+	require.ErrorIs(t, batch[1].Error, revertError{})
+	require.NoError(t, w.Close())
+
+	entries, err := journal.Decode(&buf)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	replay := NewReplayRPC(journal.NewResponses(entries), "l1")
+	endpoint.down.Store(true)
+
+	chainID = 0
+	require.NoError(t, replay.CallContext(ctx, &chainID, "eth_chainId"))
+	require.Equal(t, hexutil.Uint64(42), chainID)
+	err = replay.CallContext(ctx, nil, "eth_call", map[string]string{"to": "0x00"}, "latest")
+	var rpcErr rpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	require.Equal(t, 3, rpcErr.ErrorCode())
+
+	num = 0
+	batch = []rpc.BatchElem{
+		{Method: "eth_call", Args: []any{"0x01"}, Result: new(string)},
+		{Method: "eth_blockNumber", Result: &num},
+	}
+	require.NoError(t, replay.BatchCallContext(ctx, batch))
+	require.ErrorAs(t, batch[0].Error, &rpcErr)
+	require.NoError(t, batch[1].Error)
+	require.Equal(t, hexutil.Uint64(16), num)
+
+	require.ErrorIs(t, replay.CallContext(ctx, &num, "eth_blockNumber", "unrecorded"), journal.ErrNotRecorded)
+	_, err = replay.EthSubscribe(ctx, make(chan any), "newHeads")
+	require.ErrorIs(t, err, ErrReplaySubscription)
+}
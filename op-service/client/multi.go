@@ -0,0 +1,645 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// multiAddrSeparator separates the endpoints of an RPC address. It is not a valid character in a URL,
+	// so an address of a single endpoint is never mistaken for multiple endpoints.
+	multiAddrSeparator = "|"
+	quorumPrefix       = "quorum="
+
+	// scoreWeight is the weight of the outcome of the latest call in the health score of an endpoint.
+	scoreWeight = 0.2
+	// minRetryBackoff and maxRetryBackoff bound the time a failing endpoint is passed over,
+	// before calls are sent to it again.
+	minRetryBackoff = time.Second
+	maxRetryBackoff = time.Minute
+)
+
+var ErrNoQuorum = errors.New("no quorum of RPC endpoints agreed on the result")
+
+// DefaultQuorumMethods are the consensus-critical methods that need a quorum of endpoints to agree on
+// their result when quorum mode is enabled: the results these methods return are trusted to
+// be consistent with the block hashes and numbers they are queried by.
+var DefaultQuorumMethods = []string{
+	"eth_getBlockByHash",
+	"eth_getTransactionReceipt",
+	"eth_getBlockReceipts",
+	"debug_getRawReceipts",
+	"alchemy_getTransactionReceipts",
+	"parity_getBlockReceipts",
+	"erigon_getBlockReceiptsByBlockHash",
+	"eth_getLogs",
+}
+
+// MultiRPCConfig configures a MultiRPC.
+type MultiRPCConfig struct {
+	// Endpoints are the addresses of the RPC endpoints, in order of preference.
+	Endpoints []string
+	// Quorum is the number of endpoints that must return the same result for a call of a quorum method.
+	// Quorum mode is disabled if 0.
+	Quorum int
+	// QuorumMethods are the methods that need a quorum. DefaultQuorumMethods if nil.
+	QuorumMethods []string
+	// CallTimeout is the time to wait for an endpoint to respond before failing over to the next endpoint.
+	// There is no timeout per endpoint if 0, only the deadline of the call context.
+	CallTimeout time.Duration
+}
+
+func (c *MultiRPCConfig) Check() error {
+	if len(c.Endpoints) == 0 {
+		return errors.New("no RPC endpoints")
+	}
+	for i, addr := range c.Endpoints {
+		if addr == "" {
+			return fmt.Errorf("RPC endpoint %d is empty", i)
+		}
+	}
+	if c.Quorum < 0 || c.Quorum > len(c.Endpoints) {
+		return fmt.Errorf("quorum %d must be between 0 and the number of RPC endpoints (%d)", c.Quorum, len(c.Endpoints))
+	}
+	return nil
+}
+
+// IsMultiAddr returns whether the RPC address lists multiple endpoints. See ParseMultiAddr.
+func IsMultiAddr(addr string) bool {
+	return strings.Contains(addr, multiAddrSeparator)
+}
+
+// ParseMultiAddr parses an RPC address that lists multiple endpoints, in order of preference and separated by "|",
+// optionally followed by the quorum of endpoints that must agree on the result of consensus-critical calls.
+// E.g. "https://a.example|https://b.example|wss://c.example|quorum=2".
+func ParseMultiAddr(addr string) (MultiRPCConfig, error) {
+	var cfg MultiRPCConfig
+	for _, part := range strings.Split(addr, multiAddrSeparator) {
+		part = strings.TrimSpace(part)
+		if q, ok := strings.CutPrefix(part, quorumPrefix); ok {
+			quorum, err := strconv.Atoi(q)
+			if err != nil {
+				return MultiRPCConfig{}, fmt.Errorf("invalid quorum %q: %w", q, err)
+			}
+			cfg.Quorum = quorum
+			continue
+		}
+		cfg.Endpoints = append(cfg.Endpoints, part)
+	}
+	if err := cfg.Check(); err != nil {
+		return MultiRPCConfig{}, fmt.Errorf("invalid RPC address: %w", err)
+	}
+	return cfg, nil
+}
+
+type dialFunc func(ctx context.Context, addr string) (RPC, error)
+
+// multiEndpoint is an endpoint of a MultiRPC, with its health.
+type multiEndpoint struct {
+	addr  string
+	index int
+
+	mu  sync.Mutex
+	rpc RPC
+	// score is an exponentially weighted moving average of the success of calls to the endpoint,
+	// from 0 (all calls failed) to 1 (all calls succeeded).
+	score        float64
+	failures     int
+	backoffUntil time.Time
+}
+
+func (e *multiEndpoint) client(ctx context.Context, dial dialFunc) (RPC, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rpc != nil {
+		return e.rpc, nil
+	}
+	cl, err := dial(ctx, e.addr)
+	if err != nil {
+		return nil, err
+	}
+	e.rpc = cl
+	return cl, nil
+}
+
+func (e *multiEndpoint) record(success bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if success {
+		e.score = e.score*(1-scoreWeight) + scoreWeight
+		e.failures = 0
+		e.backoffUntil = time.Time{}
+		return
+	}
+	e.score = e.score * (1 - scoreWeight)
+	e.failures++
+	backoff := minRetryBackoff << min(e.failures-1, 6)
+	e.backoffUntil = time.Now().Add(min(backoff, maxRetryBackoff))
+}
+
+func (e *multiEndpoint) health(now time.Time) (available bool, score float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.backoffUntil), e.score
+}
+
+func (e *multiEndpoint) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rpc != nil {
+		e.rpc.Close()
+		e.rpc = nil
+	}
+}
+
+// MultiRPC is an RPC client that spreads calls over multiple endpoints.
+// Calls are sent to the healthiest endpoint, and fail over to the next endpoint when the endpoint cannot be reached.
+// Errors returned by an endpoint, such as a revert, are not failed over.
+// In quorum mode, calls of quorum methods are sent to all endpoints, and only return the result that a quorum
+// of endpoints agree on. Endpoints that disagree with the quorum are flagged and penalized.
+type MultiRPC struct {
+	log           log.Logger
+	dial          dialFunc
+	endpoints     []*multiEndpoint
+	quorum        int
+	quorumMethods map[string]struct{}
+	callTimeout   time.Duration
+}
+
+var _ RPC = (*MultiRPC)(nil)
+
+// NewMultiRPC dials the endpoints of the config with the given options, and returns a MultiRPC over them.
+// Endpoints that cannot be dialed are dialed again when they are first needed, but at least one endpoint
+// must be available.
+func NewMultiRPC(ctx context.Context, lgr log.Logger, cfg MultiRPCConfig, opts ...RPCOption) (*MultiRPC, error) {
+	m, err := newMultiRPC(lgr, cfg, func(ctx context.Context, addr string) (RPC, error) {
+		return NewRPC(ctx, lgr, addr, opts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(m.endpoints))
+	for i, e := range m.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := e.client(ctx, m.dial); err != nil {
+				lgr.Warn("Failed to dial RPC endpoint, will retry when needed", "endpoint", e.index, "err", err)
+				errs[i] = fmt.Errorf("endpoint %d: %w", e.index, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if !slices.Contains(errs, nil) {
+		return nil, fmt.Errorf("failed to dial any RPC endpoint: %w", errors.Join(errs...))
+	}
+	// Later dials are done while serving a call, so should not retry for long.
+	opts = append(slices.Clone(opts), WithDialBackoff(1))
+	m.dial = func(ctx context.Context, addr string) (RPC, error) {
+		return NewRPC(ctx, lgr, addr, opts...)
+	}
+	return m, nil
+}
+
+func newMultiRPC(lgr log.Logger, cfg MultiRPCConfig, dial dialFunc) (*MultiRPC, error) {
+	if err := cfg.Check(); err != nil {
+		return nil, err
+	}
+	methods := cfg.QuorumMethods
+	if methods == nil {
+		methods = DefaultQuorumMethods
+	}
+	m := &MultiRPC{
+		log:           lgr,
+		dial:          dial,
+		quorum:        cfg.Quorum,
+		quorumMethods: make(map[string]struct{}, len(methods)),
+		callTimeout:   cfg.CallTimeout,
+	}
+	for _, method := range methods {
+		m.quorumMethods[method] = struct{}{}
+	}
+	for i, addr := range cfg.Endpoints {
+		m.endpoints = append(m.endpoints, &multiEndpoint{addr: addr, index: i, score: 1})
+	}
+	return m, nil
+}
+
+func (m *MultiRPC) isQuorumMethod(method string) bool {
+	_, ok := m.quorumMethods[method]
+	return ok
+}
+
+// ordered returns the endpoints from healthiest to least healthy: endpoints that are not backing off first,
+// then by health score, then in configured order.
+func (m *MultiRPC) ordered() []*multiEndpoint {
+	type entry struct {
+		e         *multiEndpoint
+		available bool
+		score     float64
+	}
+	now := time.Now()
+	entries := make([]entry, len(m.endpoints))
+	for i, e := range m.endpoints {
+		available, score := e.health(now)
+		entries[i] = entry{e, available, score}
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if a.available != b.available {
+			if a.available {
+				return -1
+			}
+			return 1
+		}
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+	out := make([]*multiEndpoint, len(entries))
+	for i, en := range entries {
+		out[i] = en.e
+	}
+	return out
+}
+
+func (m *MultiRPC) callCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.callTimeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, m.callTimeout)
+}
+
+// shouldFailover returns whether the error is a failure of the endpoint, rather than an error response to the call.
+func shouldFailover(err error) bool {
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// failover calls fn with the endpoints, from healthiest to least healthy, until an endpoint succeeds or
+// returns an error response.
+// If the call should be tried with the next endpoint after a successful response, fn returns errTryNext.
+func (m *MultiRPC) failover(ctx context.Context, fn func(ctx context.Context, cl RPC) error) error {
+	var errs []error
+	for _, e := range m.ordered() {
+		cl, err := e.client(ctx, m.dial)
+		if err == nil {
+			cctx, cancel := m.callCtx(ctx)
+			err = fn(cctx, cl)
+			cancel()
+			if errors.Is(err, errTryNext) {
+				e.record(true)
+				errs = append(errs, fmt.Errorf("endpoint %d: %w", e.index, err))
+				continue
+			}
+			if err == nil || !shouldFailover(err) {
+				e.record(true)
+				return err
+			}
+		}
+		if ctx.Err() != nil { // the caller gave up, not the endpoint
+			return err
+		}
+		e.record(false)
+		m.log.Warn("RPC endpoint failed, failing over", "endpoint", e.index, "err", err)
+		errs = append(errs, fmt.Errorf("endpoint %d: %w", e.index, err))
+	}
+	return fmt.Errorf("all RPC endpoints failed: %w", errors.Join(errs...))
+}
+
+var errTryNext = errors.New("empty result")
+
+func (m *MultiRPC) Close() {
+	for _, e := range m.endpoints {
+		e.close()
+	}
+}
+
+func (m *MultiRPC) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	if !m.isQuorumMethod(method) {
+		return m.failover(ctx, func(ctx context.Context, cl RPC) error {
+			return cl.CallContext(ctx, result, method, args...)
+		})
+	}
+	var raw json.RawMessage
+	var err error
+	if m.quorum == 0 {
+		raw, err = m.callFirstNonNull(ctx, method, args...)
+	} else {
+		raw, err = m.callQuorum(ctx, method, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, result)
+}
+
+// callFirstNonNull calls the endpoints until one returns a non-null result, so that an endpoint that lags behind
+// does not hide the results of the other endpoints. It returns null if all endpoints return null.
+func (m *MultiRPC) callFirstNonNull(ctx context.Context, method string, args ...any) (json.RawMessage, error) {
+	var raw json.RawMessage
+	err := m.failover(ctx, func(ctx context.Context, cl RPC) error {
+		raw = nil
+		if err := cl.CallContext(ctx, &raw, method, args...); err != nil {
+			return err
+		}
+		if isNull(raw) {
+			return errTryNext
+		}
+		return nil
+	})
+	if errors.Is(err, errTryNext) && isNull(raw) {
+		return json.RawMessage("null"), nil
+	}
+	return raw, err
+}
+
+func isNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
+
+// multiResult is the result of an endpoint, for quorum calls.
+type multiResult struct {
+	e   *multiEndpoint
+	raw json.RawMessage
+	err error
+}
+
+// all calls fn with all endpoints concurrently, and returns the results from healthiest to least healthy endpoint.
+// fn is passed the index of its result. The health of the endpoints is updated by whether they could be reached.
+func (m *MultiRPC) all(ctx context.Context, fn func(ctx context.Context, cl RPC, i int) (json.RawMessage, error)) []multiResult {
+	results := make([]multiResult, len(m.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range m.ordered() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := multiResult{e: e}
+			cl, err := e.client(ctx, m.dial)
+			if err == nil {
+				cctx, cancel := m.callCtx(ctx)
+				res.raw, err = fn(cctx, cl, i)
+				cancel()
+			}
+			res.err = err
+			if ctx.Err() == nil {
+				e.record(err == nil || !shouldFailover(err))
+			}
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (m *MultiRPC) callQuorum(ctx context.Context, method string, args ...any) (json.RawMessage, error) {
+	results := m.all(ctx, func(ctx context.Context, cl RPC, _ int) (json.RawMessage, error) {
+		var raw json.RawMessage
+		err := cl.CallContext(ctx, &raw, method, args...)
+		return raw, err
+	})
+	return m.pickQuorum(method, results)
+}
+
+// pickQuorum returns the result that a quorum of endpoints agree on, compared by their quorumKey.
+// Endpoints that returned a different result than the quorum, or than each other, are flagged and penalized.
+// Error responses are not votes, unless no endpoint returned a result.
+func (m *MultiRPC) pickQuorum(method string, results []multiResult) (json.RawMessage, error) {
+	type group struct {
+		raw       json.RawMessage
+		endpoints []*multiEndpoint
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	var errs []error
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("endpoint %d: %w", res.e.index, res.err))
+			continue
+		}
+		key, err := quorumKey(method, res.raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("endpoint %d: invalid result: %w", res.e.index, err))
+			res.e.record(false)
+			continue
+		}
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{raw: res.raw}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.endpoints = append(g.endpoints, res.e)
+	}
+	if len(groups) == 0 {
+		// Return the error response of the healthiest endpoint, if any endpoint could be reached.
+		for _, res := range results {
+			if !shouldFailover(res.err) {
+				return nil, res.err
+			}
+		}
+		return nil, fmt.Errorf("all RPC endpoints failed: %w", errors.Join(errs...))
+	}
+	slices.SortStableFunc(groups, func(a, b *group) int { return len(b.endpoints) - len(a.endpoints) })
+	best := groups[0]
+	if len(groups) > 1 {
+		votes := make([]string, len(groups))
+		for i, g := range groups {
+			idx := make([]string, len(g.endpoints))
+			for j, e := range g.endpoints {
+				idx[j] = strconv.Itoa(e.index)
+			}
+			votes[i] = strings.Join(idx, "+")
+		}
+		m.log.Error("RPC endpoints disagree", "method", method, "endpoints", strings.Join(votes, " vs "))
+		for _, g := range groups[1:] {
+			for _, e := range g.endpoints {
+				e.record(false)
+			}
+		}
+	}
+	if len(best.endpoints) < m.quorum || (len(groups) > 1 && len(groups[1].endpoints) == len(best.endpoints)) {
+		return nil, fmt.Errorf("%w: %s: %d of %d required endpoints agreed", ErrNoQuorum, method, len(best.endpoints), m.quorum)
+	}
+	return best.raw, nil
+}
+
+// quorumBlock is the part of a block that endpoints must agree on: the block hash commits to the rest.
+type quorumBlock struct {
+	Hash common.Hash `json:"hash"`
+}
+
+// quorumLog is the part of a log that endpoints must agree on.
+type quorumLog struct {
+	Address   common.Address `json:"address"`
+	Topics    []common.Hash  `json:"topics"`
+	Data      hexutil.Bytes  `json:"data"`
+	BlockHash common.Hash    `json:"blockHash"`
+	TxHash    common.Hash    `json:"transactionHash"`
+	Index     hexutil.Uint   `json:"logIndex"`
+	Removed   bool           `json:"removed"`
+}
+
+// quorumReceipt is the part of a receipt that endpoints must agree on: the fields that the receipts root of the
+// block commits to, and the transaction and block the receipt is of.
+// Fields that are derived differently by different clients, like the effective gas price or L1 fees, are left out.
+type quorumReceipt struct {
+	Type              hexutil.Uint64 `json:"type"`
+	Status            hexutil.Uint64 `json:"status"`
+	CumulativeGasUsed hexutil.Uint64 `json:"cumulativeGasUsed"`
+	Bloom             types.Bloom    `json:"logsBloom"`
+	Logs              []quorumLog    `json:"logs"`
+	TxHash            common.Hash    `json:"transactionHash"`
+	BlockHash         common.Hash    `json:"blockHash"`
+}
+
+// quorumKey returns the part of the result of the method that endpoints must agree on.
+// Blocks are compared by hash, and receipts and logs by their consensus fields, so that endpoints that add or
+// format other fields differently still agree. Results of other methods, e.g. of eth_call, are compared in full.
+func quorumKey(method string, raw json.RawMessage) (string, error) {
+	var v any
+	switch method {
+	case "eth_getBlockByHash", "eth_getBlockByNumber":
+		v = new(*quorumBlock)
+	case "eth_getTransactionReceipt":
+		v = new(*quorumReceipt)
+	case "eth_getBlockReceipts", "parity_getBlockReceipts", "erigon_getBlockReceiptsByBlockHash":
+		v = new([]*quorumReceipt)
+	case "alchemy_getTransactionReceipts":
+		v = new(*struct {
+			Receipts []*quorumReceipt `json:"receipts"`
+		})
+	case "eth_getLogs":
+		v = new([]quorumLog)
+	default:
+		return canonicalJSON(raw)
+	}
+	if isNull(raw) {
+		return "null", nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// canonicalJSON returns the JSON in canonical form, with object keys sorted and without whitespace,
+// so that results of different endpoints can be compared.
+func canonicalJSON(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "null", nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (m *MultiRPC) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	if m.quorum == 0 || !slices.ContainsFunc(b, func(elem rpc.BatchElem) bool { return m.isQuorumMethod(elem.Method) }) {
+		return m.failover(ctx, func(ctx context.Context, cl RPC) error {
+			return cl.BatchCallContext(ctx, b)
+		})
+	}
+	// Send the batch to all endpoints, and pick the quorum result per element.
+	batches := make([][]rpc.BatchElem, len(m.endpoints))
+	results := m.all(ctx, func(ctx context.Context, cl RPC, i int) (json.RawMessage, error) {
+		batch := make([]rpc.BatchElem, len(b))
+		for j, elem := range b {
+			batch[j] = rpc.BatchElem{Method: elem.Method, Args: elem.Args, Result: new(json.RawMessage)}
+		}
+		batches[i] = batch
+		return nil, cl.BatchCallContext(ctx, batch)
+	})
+	type reachedBatch struct {
+		e     *multiEndpoint
+		batch []rpc.BatchElem
+	}
+	var reached []reachedBatch
+	var errs []error
+	for i, res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("endpoint %d: %w", res.e.index, res.err))
+			continue
+		}
+		reached = append(reached, reachedBatch{e: res.e, batch: batches[i]})
+	}
+	if len(reached) == 0 {
+		return fmt.Errorf("all RPC endpoints failed: %w", errors.Join(errs...))
+	}
+	for i := range b {
+		elemResults := make([]multiResult, len(reached))
+		for j, res := range reached {
+			elem := res.batch[i]
+			elemResults[j] = multiResult{e: res.e, err: elem.Error}
+			if elem.Error == nil {
+				elemResults[j].raw = *elem.Result.(*json.RawMessage)
+			}
+		}
+		if !m.isQuorumMethod(b[i].Method) {
+			// Take the result of the healthiest endpoint.
+			b[i].Error = elemResults[0].err
+			if b[i].Error == nil && b[i].Result != nil {
+				b[i].Error = json.Unmarshal(elemResults[0].raw, b[i].Result)
+			}
+			continue
+		}
+		raw, err := m.pickQuorum(b[i].Method, elemResults)
+		b[i].Error = err
+		if err == nil && b[i].Result != nil {
+			b[i].Error = json.Unmarshal(raw, b[i].Result)
+		}
+	}
+	return nil
+}
+
+func (m *MultiRPC) EthSubscribe(ctx context.Context, channel any, args ...any) (ethereum.Subscription, error) {
+	var sub ethereum.Subscription
+	err := m.failover(ctx, func(ctx context.Context, cl RPC) (err error) {
+		sub, err = cl.EthSubscribe(ctx, channel, args...)
+		return err
+	})
+	return sub, err
+}
+
+func (m *MultiRPC) Subscribe(ctx context.Context, namespace string, channel any, args ...any) (ethereum.Subscription, error) {
+	var sub ethereum.Subscription
+	err := m.failover(ctx, func(ctx context.Context, cl RPC) (err error) {
+		sub, err = cl.Subscribe(ctx, namespace, channel, args...)
+		return err
+	})
+	return sub, err
+}
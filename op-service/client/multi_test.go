@@ -0,0 +1,279 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+var errUnreachable = errors.New("connection refused")
+
+type revertError struct{}
+
+func (revertError) Error() string  { return "execution reverted" }
+func (revertError) ErrorCode() int { return 3 }
+
+// fakeEndpoint serves fixed results per method.
+type fakeEndpoint struct {
+	results map[string]string
+	down    atomic.Bool
+	calls   atomic.Int32
+}
+
+func (f *fakeEndpoint) result(method string) (json.RawMessage, error) {
+	f.calls.Add(1)
+	if f.down.Load() {
+		return nil, errUnreachable
+	}
+	if method == "eth_call" {
+		return nil, revertError{}
+	}
+	res, ok := f.results[method]
+	if !ok {
+		return json.RawMessage("null"), nil
+	}
+	return json.RawMessage(res), nil
+}
+
+func (f *fakeEndpoint) Close() {}
+
+func (f *fakeEndpoint) CallContext(_ context.Context, result any, method string, _ ...any) error {
+	res, err := f.result(method)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(res, result)
+}
+
+func (f *fakeEndpoint) BatchCallContext(_ context.Context, b []rpc.BatchElem) error {
+	if f.down.Load() {
+		return errUnreachable
+	}
+	for i := range b {
+		res, err := f.result(b[i].Method)
+		if err != nil {
+			b[i].Error = err
+			continue
+		}
+		b[i].Error = json.Unmarshal(res, b[i].Result)
+	}
+	return nil
+}
+
+func (f *fakeEndpoint) EthSubscribe(context.Context, any, ...any) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func (f *fakeEndpoint) Subscribe(context.Context, string, any, ...any) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func newTestMultiRPC(t *testing.T, quorum int, endpoints ...*fakeEndpoint) *MultiRPC {
+	cfg := MultiRPCConfig{Quorum: quorum}
+	byAddr := make(map[string]RPC)
+	for i, e := range endpoints {
+		addr := "http://endpoint" + string(rune('a'+i))
+		cfg.Endpoints = append(cfg.Endpoints, addr)
+		byAddr[addr] = e
+	}
+	m, err := newMultiRPC(testlog.Logger(t, log.LevelError), cfg, func(ctx context.Context, addr string) (RPC, error) {
+		return byAddr[addr], nil
+	})
+	require.NoError(t, err)
+	return m
+}
+
+func TestParseMultiAddr(t *testing.T) {
+	require.False(t, IsMultiAddr("http://localhost:8545"))
+	require.False(t, IsMultiAddr("https://rpc.example/?filter=a,b"), "commas are valid in a URL")
+	require.True(t, IsMultiAddr("http://a|http://b"))
+
+	cfg, err := ParseMultiAddr("http://a| ws://b|http://c/?x=1,2|quorum=2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://a", "ws://b", "http://c/?x=1,2"}, cfg.Endpoints)
+	require.Equal(t, 2, cfg.Quorum)
+
+	_, err = ParseMultiAddr("http://a|http://b|quorum=3")
+	require.ErrorContains(t, err, "quorum 3")
+	_, err = ParseMultiAddr("http://a|quorum=x")
+	require.ErrorContains(t, err, "invalid quorum")
+	_, err = ParseMultiAddr("http://a||http://b")
+	require.ErrorContains(t, err, "empty")
+}
+
+func TestMultiRPCFailover(t *testing.T) {
+	ctx := context.Background()
+	a := &fakeEndpoint{results: map[string]string{"eth_chainId": `"0x1"`}}
+	b := &fakeEndpoint{results: map[string]string{"eth_chainId": `"0x1"`}}
+	m := newTestMultiRPC(t, 0, a, b)
+
+	var id hexutil.Uint64
+	require.NoError(t, m.CallContext(ctx, &id, "eth_chainId"))
+	require.Equal(t, hexutil.Uint64(1), id)
+	require.Equal(t, int32(1), a.calls.Load())
+	require.Equal(t, int32(0), b.calls.Load(), "prefers the first endpoint")
+
+	a.down.Store(true)
+	require.NoError(t, m.CallContext(ctx, &id, "eth_chainId"))
+	require.Equal(t, int32(1), b.calls.Load(), "fails over")
+	require.NoError(t, m.CallContext(ctx, &id, "eth_chainId"))
+	require.Equal(t, int32(2), a.calls.Load(), "skips the failed endpoint while backing off")
+	require.Equal(t, int32(2), b.calls.Load())
+
+	var rpcErr rpc.Error
+	require.ErrorAs(t, m.CallContext(ctx, &id, "eth_call"), &rpcErr)
+	require.Equal(t, int32(3), b.calls.Load())
+	require.Equal(t, int32(2), a.calls.Load(), "does not fail over error responses")
+
+	b.down.Store(true)
+	require.ErrorIs(t, m.CallContext(ctx, &id, "eth_chainId"), errUnreachable)
+}
+
+func TestMultiRPCNullResult(t *testing.T) {
+	lagging := &fakeEndpoint{}
+	synced := &fakeEndpoint{results: map[string]string{"eth_getBlockByHash": `{"number":"0x5"}`}}
+	m := newTestMultiRPC(t, 0, lagging, synced)
+
+	var block struct {
+		Number hexutil.Uint64 `json:"number"`
+	}
+	require.NoError(t, m.CallContext(context.Background(), &block, "eth_getBlockByHash"))
+	require.Equal(t, hexutil.Uint64(5), block.Number, "tries the next endpoint for missing data")
+
+	var missing *struct{}
+	require.NoError(t, m.CallContext(context.Background(), &missing, "eth_getTransactionReceipt"))
+	require.Nil(t, missing)
+}
+
+func TestMultiRPCQuorum(t *testing.T) {
+	ctx := context.Background()
+	hashA, hashB := common.Hash{0xaa}.Hex(), common.Hash{0xbb}.Hex()
+	a := &fakeEndpoint{results: map[string]string{"eth_getBlockByHash": `{"number":"0x5","hash":"` + hashA + `"}`, "eth_chainId": `"0x1"`}}
+	b := &fakeEndpoint{results: map[string]string{"eth_getBlockByHash": `{"hash": "` + hashA + `", "number": "0x5"}`, "eth_chainId": `"0x1"`}}
+	c := &fakeEndpoint{results: map[string]string{"eth_getBlockByHash": `{"number":"0x5","hash":"` + hashB + `"}`, "eth_chainId": `"0x1"`}}
+	m := newTestMultiRPC(t, 2, c, a, b)
+
+	var block map[string]string
+	require.NoError(t, m.CallContext(ctx, &block, "eth_getBlockByHash"))
+	require.Equal(t, hashA, block["hash"], "takes the result of the quorum, in any JSON formatting")
+	_, score := m.endpoints[0].health(time.Now())
+	require.Less(t, score, 1.0, "penalizes the disagreeing endpoint")
+
+	calls := func() int32 { return a.calls.Load() + b.calls.Load() + c.calls.Load() }
+	before := calls()
+	var id hexutil.Uint64
+	require.NoError(t, m.CallContext(ctx, &id, "eth_chainId"))
+	require.Equal(t, before+1, calls(), "no quorum for other methods")
+
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBlockByHash", Result: &block},
+		{Method: "eth_chainId", Result: &id},
+	}
+	require.NoError(t, m.BatchCallContext(ctx, batch))
+	require.NoError(t, batch[0].Error)
+	require.NoError(t, batch[1].Error)
+	require.Equal(t, hashA, block["hash"])
+
+	b.down.Store(true)
+	require.ErrorIs(t, m.CallContext(ctx, &block, "eth_getBlockByHash"), ErrNoQuorum)
+}
+
+func TestMultiRPCQuorumKey(t *testing.T) {
+	ctx := context.Background()
+	addr, topic := common.Address{0x01}.Hex(), common.Hash{0x02}.Hex()
+	txHash, blockHash := common.Hash{0x01}.Hex(), common.Hash{0xaa}.Hex()
+	receipt := func(extra string) string {
+		return `{"type":"0x2","status":"0x1","cumulativeGasUsed":"0x5208","logsBloom":"0x` + strings.Repeat("00", 256) + `",` +
+			`"logs":[],"transactionHash":"` + txHash + `","blockHash":"` + blockHash + `"` + extra + `}`
+	}
+	logJSON := func(data, extra string) string {
+		return `{"address":"` + addr + `","topics":["` + topic + `"],"data":"` + data + `","blockHash":"` + blockHash + `",` +
+			`"transactionHash":"` + txHash + `","logIndex":"0x0","removed":false` + extra + `}`
+	}
+	// The endpoints run different clients, that return different non-consensus fields.
+	a := &fakeEndpoint{results: map[string]string{
+		"eth_getBlockByHash":        `{"hash":"` + blockHash + `","size":"0x100"}`,
+		"eth_getTransactionReceipt": receipt(`,"effectiveGasPrice":"0x1","l1Fee":"0x10"`),
+	}}
+	b := &fakeEndpoint{results: map[string]string{
+		"eth_getBlockByHash":        `{"hash":"0x` + strings.ToUpper(blockHash[2:]) + `","size":"0x101"}`,
+		"eth_getTransactionReceipt": receipt(`,"effectiveGasPrice":"0x2"`),
+	}}
+	c := &fakeEndpoint{results: map[string]string{
+		"eth_getBlockByHash":        `{"hash":"` + common.Hash{0xbb}.Hex() + `"}`,
+		"eth_getTransactionReceipt": strings.Replace(receipt(""), `"status":"0x1"`, `"status":"0x0"`, 1),
+	}}
+	m := newTestMultiRPC(t, 2, a, b, c)
+
+	var block map[string]any
+	require.NoError(t, m.CallContext(ctx, &block, "eth_getBlockByHash"), "blocks are compared by hash")
+	var rec map[string]any
+	require.NoError(t, m.CallContext(ctx, &rec, "eth_getTransactionReceipt"), "receipts are compared by consensus fields")
+	require.Equal(t, "0x1", rec["status"])
+	_, score := m.endpoints[2].health(time.Now())
+	require.Less(t, score, 1.0, "penalizes the endpoint with a different status")
+
+	for _, test := range []struct {
+		method string
+		a, b   string
+		equal  bool
+	}{
+		{"eth_getLogs", `[` + logJSON("0x", `,"blockTimestamp":"0x1"`) + `]`, `[` + logJSON("0x", "") + `]`, true},
+		{"eth_getLogs", `[` + logJSON("0x01", "") + `]`, `[` + logJSON("0x02", "") + `]`, false},
+		{"alchemy_getTransactionReceipts", `{"receipts":[` + receipt(`,"gasUsed":"0x1"`) + `]}`, `{"receipts":[` + receipt("") + `]}`, true},
+		{"eth_getBlockReceipts", `[` + receipt("") + `]`, `[]`, false},
+		{"eth_getBlockByHash", `null`, ``, true},
+		{"eth_call", `"0x01"`, ` "0x01"`, true},
+		{"eth_call", `"0x01"`, `"0x02"`, false},
+	} {
+		keyA, err := quorumKey(test.method, json.RawMessage(test.a))
+		require.NoError(t, err)
+		keyB, err := quorumKey(test.method, json.RawMessage(test.b))
+		require.NoError(t, err)
+		require.Equal(t, test.equal, keyA == keyB, "%s: %s vs %s", test.method, test.a, test.b)
+	}
+	_, err := quorumKey("eth_getTransactionReceipt", json.RawMessage(`{"status":"1"}`))
+	require.Error(t, err)
+}
+
+func TestGethRPCClient(t *testing.T) {
+	ctx := context.Background()
+	a := &fakeEndpoint{results: map[string]string{"eth_chainId": `"0x2a"`}}
+	b := &fakeEndpoint{results: map[string]string{"eth_chainId": `"0x2a"`}}
+	a.down.Store(true)
+	cl, err := NewGethRPCClient(ctx, newTestMultiRPC(t, 0, a, b))
+	require.NoError(t, err)
+	defer cl.Close()
+
+	id, err := ethclient.NewClient(cl).ChainID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), id.Uint64())
+
+	var rpcErr rpc.Error
+	require.ErrorAs(t, cl.CallContext(ctx, nil, "eth_call"), &rpcErr)
+	require.Equal(t, 3, rpcErr.ErrorCode())
+
+	var ids [2]hexutil.Uint64
+	batch := []rpc.BatchElem{
+		{Method: "eth_chainId", Result: &ids[0]},
+		{Method: "eth_chainId", Result: &ids[1]},
+	}
+	require.NoError(t, cl.BatchCallContext(ctx, batch))
+	require.NoError(t, batch[0].Error)
+	require.Equal(t, [2]hexutil.Uint64{42, 42}, ids)
+}
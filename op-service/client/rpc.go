@@ -76,7 +76,16 @@ func WithRateLimit(rateLimit float64, burst int) RPCOption {
 }
 
 // NewRPC returns the correct client.RPC instance for a given RPC url.
+// An address that lists multiple endpoints returns a MultiRPC over them, see ParseMultiAddr.
 func NewRPC(ctx context.Context, lgr log.Logger, addr string, opts ...RPCOption) (RPC, error) {
+	if IsMultiAddr(addr) {
+		cfg, err := ParseMultiAddr(addr)
+		if err != nil {
+			return nil, err
+		}
+		return NewMultiRPC(ctx, lgr, cfg, opts...)
+	}
+
 	var cfg rpcConfig
 	for i, opt := range opts {
 		if err := opt(&cfg); err != nil {
@@ -104,7 +113,8 @@ func NewRPC(ctx context.Context, lgr log.Logger, addr string, opts ...RPCOption)
 
 // NewRPCWithClient builds a new polling client with the given underlying RPC client.
 func NewRPCWithClient(ctx context.Context, lgr log.Logger, addr string, underlying RPC, pollInterval time.Duration) (RPC, error) {
-	if httpRegex.MatchString(addr) {
+	// A go-ethereum client of a multi-endpoint address is an HTTP client over the endpoints, see NewGethRPCClient.
+	if httpRegex.MatchString(addr) || IsMultiAddr(addr) {
 		underlying = NewPollingClient(ctx, lgr, underlying, WithPollRate(pollInterval))
 	}
 	return underlying, nil
@@ -64,8 +64,12 @@ func dialRPCClientWithBackoff(ctx context.Context, log log.Logger, addr string)
 	})
 }
 
-// Dials a JSON-RPC endpoint once.
+// Dials a JSON-RPC endpoint once. An address that lists multiple endpoints dials a client that fails over
+// between them, see client.ParseMultiAddr.
 func dialRPCClient(ctx context.Context, log log.Logger, addr string) (*rpc.Client, error) {
+	if client.IsMultiAddr(addr) {
+		return client.DialGethRPCClient(ctx, log, addr)
+	}
 	if !client.IsURLAvailable(ctx, addr) {
 		log.Warn("failed to dial address, but may connect later", "addr", addr)
 		return nil, fmt.Errorf("address unavailable (%s)", addr)
@@ -0,0 +1,57 @@
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// WithdrawalTransaction is a withdrawal initiated on L2, encoded like the Types.WithdrawalTransaction struct
+// that the OptimismPortal takes to prove and finalize a withdrawal.
+type WithdrawalTransaction struct {
+	Nonce  *hexutil.Big   `json:"nonce"`
+	Sender common.Address `json:"sender"`
+	Target common.Address `json:"target"`
+	// Value is denominated in the native token of the chain, which is not ether if the chain uses a custom gas token.
+	Value    *hexutil.Big  `json:"value"`
+	GasLimit *hexutil.Big  `json:"gasLimit"`
+	Data     hexutil.Bytes `json:"data"`
+}
+
+// OutputRootProof is the preimage of a version 0 output root, like the Types.OutputRootProof struct.
+type OutputRootProof struct {
+	Version                  Bytes32     `json:"version"`
+	StateRoot                common.Hash `json:"stateRoot"`
+	MessagePasserStorageRoot common.Hash `json:"messagePasserStorageRoot"`
+	LatestBlockhash          common.Hash `json:"latestBlockhash"`
+}
+
+// NativeToken is the L1 token that is bridged through the OptimismPortal to pay for gas on L2,
+// on chains that use a custom gas token.
+type NativeToken struct {
+	Address  common.Address `json:"address"`
+	Decimals uint8          `json:"decimals"`
+}
+
+// WithdrawalProofResponse holds the arguments of OptimismPortal.proveWithdrawalTransaction:
+// the withdrawal, the index of the output (L2OutputOracle) or dispute game (DisputeGameFactory)
+// that commits to the L2 state, the output root proof and the storage proof of the withdrawal.
+type WithdrawalProofResponse struct {
+	Withdrawal     WithdrawalTransaction `json:"withdrawal"`
+	WithdrawalHash common.Hash           `json:"withdrawalHash"`
+	// TransactionHash is the L2 transaction that initiated the withdrawal.
+	TransactionHash common.Hash `json:"transactionHash"`
+
+	// DisputeGame is true if the portal proves withdrawals against dispute games,
+	// and Index is a dispute game index. Otherwise Index is an L2OutputOracle output index.
+	DisputeGame bool           `json:"disputeGame"`
+	Index       hexutil.Uint64 `json:"index"`
+	// OutputRoot is the output root proposed at Index, for the L2 block OutputBlock.
+	OutputRoot  Bytes32 `json:"outputRoot"`
+	OutputBlock BlockID `json:"outputBlock"`
+
+	OutputRootProof OutputRootProof `json:"outputRootProof"`
+	WithdrawalProof []hexutil.Bytes `json:"withdrawalProof"`
+
+	// NativeToken is set if the chain uses a custom gas token, which the withdrawal value is denominated in.
+	NativeToken *NativeToken `json:"nativeToken,omitempty"`
+}
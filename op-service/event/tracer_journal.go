@@ -0,0 +1,78 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+)
+
+// JournalTracer records event emissions and deriver processing to a journal,
+// so that the event processing can be replayed and compared later.
+//
+// Emitted events are recorded with their JSON encoding, and with the journal input
+// that caused them, if the event was emitted with a context marked by journal.WithInput.
+type JournalTracer struct {
+	w *journal.Writer
+}
+
+var _ Tracer = (*JournalTracer)(nil)
+
+func NewJournalTracer(w *journal.Writer) *JournalTracer {
+	return &JournalTracer{w: w}
+}
+
+func (jt *JournalTracer) OnDeriveStart(name string, ev AnnotatedEvent, derivContext uint64, startTime time.Time) {
+}
+
+func (jt *JournalTracer) OnDeriveEnd(name string, ev AnnotatedEvent, derivContext uint64, startTime time.Time, duration time.Duration, effect bool) {
+	// Derivers without effect are not recorded, they are the majority and do not change state.
+	if !effect {
+		return
+	}
+	jt.w.Write(journal.Entry{
+		Kind:         journal.KindDerive,
+		Time:         startTime.UnixNano(),
+		Name:         name,
+		Event:        ev.Event.String(),
+		EmitContext:  ev.EmitContext,
+		DerivContext: derivContext,
+		Effect:       effect,
+	})
+}
+
+func (jt *JournalTracer) OnRateLimited(name string, derivContext uint64) {
+	jt.w.Write(journal.Entry{
+		Kind:         journal.KindRateLimited,
+		Name:         name,
+		DerivContext: derivContext,
+	})
+}
+
+func (jt *JournalTracer) OnEmit(name string, ev AnnotatedEvent, derivContext uint64, emitTime time.Time) {
+	e := journal.Entry{
+		Kind:         journal.KindEmit,
+		Time:         emitTime.UnixNano(),
+		Name:         name,
+		Event:        ev.Event.String(),
+		EmitContext:  ev.EmitContext,
+		DerivContext: derivContext,
+	}
+	if ev.Ctx != nil {
+		e.Input = journal.InputFromContext(ev.Ctx)
+	}
+	data, err := json.Marshal(ev.Event)
+	if err != nil {
+		e.Err = err.Error()
+	} else {
+		e.Data = data
+	}
+	jt.w.Write(e)
+}
+
+func (jt *JournalTracer) OnAfterProcessed(evtype string) {
+	jt.w.Write(journal.Entry{
+		Kind:  journal.KindProcessed,
+		Event: evtype,
+	})
+}
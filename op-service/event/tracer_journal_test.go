@@ -0,0 +1,75 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/journal"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (b *bufferCloser) Close() error { return nil }
+
+var _ io.WriteCloser = (*bufferCloser)(nil)
+
+func TestJournalTracer(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelError)
+	ex := NewGlobalSynchronous(context.Background())
+	sys := NewSystem(logger, ex)
+
+	var buf bufferCloser
+	w := journal.NewWriter(&buf)
+	sys.AddTracer(NewJournalTracer(w))
+
+	var fooEmitter Emitter
+	foo := DeriverFunc(func(ctx context.Context, ev Event) bool {
+		switch ev.(type) {
+		case TestEvent:
+			fooEmitter.Emit(ctx, FooEvent{})
+			return true
+		}
+		return false
+	})
+	fooEmitter = sys.Register("foo", foo)
+	em := sys.Register("source", nil)
+
+	input := w.WriteInput(context.Background(), "signal", "hello")
+	em.Emit(input, TestEvent{})
+	require.NoError(t, ex.Drain())
+	require.NoError(t, w.Close())
+
+	entries, err := journal.Decode(&buf)
+	require.NoError(t, err)
+
+	var kinds []journal.Kind
+	for _, e := range entries {
+		kinds = append(kinds, e.Kind)
+	}
+	require.Equal(t, []journal.Kind{
+		journal.KindInput,
+		journal.KindEmit,      // TestEvent by source
+		journal.KindEmit,      // FooEvent by foo
+		journal.KindDerive,    // TestEvent by foo
+		journal.KindProcessed, // TestEvent
+		journal.KindProcessed, // FooEvent
+	}, kinds)
+
+	testEmit, fooEmit := entries[1], entries[2]
+	require.Equal(t, "source", testEmit.Name)
+	require.Equal(t, TestEvent{}.String(), testEmit.Event)
+	require.Equal(t, entries[0].Seq, testEmit.Input, "emission is attributed to the input")
+	require.Zero(t, testEmit.DerivContext)
+	require.Equal(t, "foo", fooEmit.Name)
+	require.Equal(t, entries[3].DerivContext, fooEmit.DerivContext, "emission is attributed to the deriver")
+	require.Equal(t, testEmit.EmitContext, entries[3].EmitContext)
+}
@@ -0,0 +1,116 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/simplelru"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OTelTracer turns event emissions and deriver processing into OpenTelemetry spans.
+//
+// Every emission is a span, parented by the span of the deriver that emitted the event,
+// or by the span in the emit context if the event was emitted outside of deriver processing
+// (e.g. by an RPC handler or a gossip handler).
+// Every deriver processing of an event is a span, parented by the emission of the event.
+// This makes the causal chain of events across derivers one trace.
+type OTelTracer struct {
+	tracer trace.Tracer
+
+	mu sync.Mutex
+	// spans of derivers that are processing an event, by deriv context
+	derives map[uint64]trace.Span
+	// span contexts of emitted events, by emit context.
+	// Processed events are not reported to the tracer, so these are evicted by age instead.
+	emits *lru.LRU[uint64, trace.SpanContext]
+}
+
+var _ Tracer = (*OTelTracer)(nil)
+
+// NewOTelTracer creates a tracer that starts spans with the given OpenTelemetry tracer.
+func NewOTelTracer(tracer trace.Tracer) *OTelTracer {
+	// Executors never queue up more than sanityEventLimit events,
+	// so older emissions are not processed anymore.
+	emits, err := lru.NewLRU[uint64, trace.SpanContext](sanityEventLimit, nil)
+	if err != nil {
+		panic(err) // only errors on a non-positive size
+	}
+	return &OTelTracer{
+		tracer:  tracer,
+		derives: make(map[uint64]trace.Span),
+		emits:   emits,
+	}
+}
+
+func (ot *OTelTracer) OnDeriveStart(name string, ev AnnotatedEvent, derivContext uint64, startTime time.Time) {
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	parent, ok := ot.emits.Get(ev.EmitContext)
+	if !ok {
+		parent = spanContextOf(ev.Ctx)
+	}
+	_, span := ot.tracer.Start(trace.ContextWithSpanContext(context.Background(), parent),
+		"derive "+ev.Event.String(),
+		trace.WithTimestamp(startTime),
+		trace.WithAttributes(
+			attribute.String("event.deriver", name),
+			attribute.String("event.name", ev.Event.String()),
+			attribute.Int64("event.emit_context", int64(ev.EmitContext)),
+			attribute.Int64("event.deriv_context", int64(derivContext)),
+		))
+	ot.derives[derivContext] = span
+}
+
+func (ot *OTelTracer) OnDeriveEnd(name string, ev AnnotatedEvent, derivContext uint64, startTime time.Time, duration time.Duration, effect bool) {
+	ot.mu.Lock()
+	span, ok := ot.derives[derivContext]
+	delete(ot.derives, derivContext)
+	ot.mu.Unlock()
+	if !ok {
+		return
+	}
+	span.SetAttributes(attribute.Bool("event.effect", effect))
+	span.End(trace.WithTimestamp(startTime.Add(duration)))
+}
+
+func (ot *OTelTracer) OnRateLimited(name string, derivContext uint64) {
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	if span, ok := ot.derives[derivContext]; ok {
+		span.AddEvent("rate-limited", trace.WithAttributes(attribute.String("event.emitter", name)))
+	}
+}
+
+func (ot *OTelTracer) OnEmit(name string, ev AnnotatedEvent, derivContext uint64, emitTime time.Time) {
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	var parent trace.SpanContext
+	if span, ok := ot.derives[derivContext]; ok {
+		parent = span.SpanContext()
+	} else {
+		parent = spanContextOf(ev.Ctx)
+	}
+	_, span := ot.tracer.Start(trace.ContextWithSpanContext(context.Background(), parent),
+		"emit "+ev.Event.String(),
+		trace.WithTimestamp(emitTime),
+		trace.WithAttributes(
+			attribute.String("event.emitter", name),
+			attribute.String("event.name", ev.Event.String()),
+			attribute.Int64("event.emit_context", int64(ev.EmitContext)),
+			attribute.Int64("event.priority", int64(ev.EmitPriority)),
+		))
+	span.End(trace.WithTimestamp(emitTime))
+	ot.emits.Add(ev.EmitContext, span.SpanContext())
+}
+
+func (ot *OTelTracer) OnAfterProcessed(evtype string) {}
+
+func spanContextOf(ctx context.Context) trace.SpanContext {
+	if ctx == nil {
+		return trace.SpanContext{}
+	}
+	return trace.SpanContextFromContext(ctx)
+}
@@ -0,0 +1,81 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+func TestOTelTracer(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelError)
+	ex := NewGlobalSynchronous(context.Background())
+	sys := NewSystem(logger, ex)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	sys.AddTracer(NewOTelTracer(provider.Tracer("test")))
+
+	var fooEmitter Emitter
+	foo := DeriverFunc(func(ctx context.Context, ev Event) bool {
+		switch ev.(type) {
+		case TestEvent:
+			fooEmitter.Emit(ctx, FooEvent{})
+			return true
+		}
+		return false
+	})
+	fooEmitter = sys.Register("foo", foo)
+	bar := DeriverFunc(func(ctx context.Context, ev Event) bool {
+		_, ok := ev.(FooEvent)
+		return ok
+	})
+	sys.Register("bar", bar)
+
+	// The event is emitted as part of an existing trace, e.g. of an RPC request.
+	ctx, root := provider.Tracer("test").Start(context.Background(), "request")
+	em := sys.Register("source", nil)
+	em.Emit(ctx, TestEvent{})
+	root.End()
+	require.NoError(t, ex.Drain())
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		require.Equal(t, root.SpanContext().TraceID(), span.SpanContext().TraceID(), "all spans are in one trace")
+		key := span.Name()
+		for _, attr := range span.Attributes() {
+			if attr.Key == "event.deriver" {
+				key += " by " + attr.Value.AsString()
+			}
+		}
+		spans[key] = span
+	}
+	parentOf := func(name string) string {
+		span, ok := spans[name]
+		require.True(t, ok, "missing span %q", name)
+		for key, other := range spans {
+			if other.SpanContext().SpanID() == span.Parent().SpanID() {
+				return key
+			}
+		}
+		return ""
+	}
+	require.Equal(t, "request", parentOf("emit TestEvent"))
+	require.Equal(t, "emit TestEvent", parentOf("derive TestEvent by foo"))
+	require.Equal(t, "emit TestEvent", parentOf("derive TestEvent by bar"))
+	require.Equal(t, "derive TestEvent by foo", parentOf("emit FooEvent"))
+	require.Equal(t, "emit FooEvent", parentOf("derive FooEvent by foo"))
+	require.Equal(t, "emit FooEvent", parentOf("derive FooEvent by bar"))
+
+	for _, attr := range spans["derive TestEvent by bar"].Attributes() {
+		if attr.Key == "event.effect" {
+			require.False(t, attr.Value.AsBool(), "bar does not process TestEvent")
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package httputil
 
-import "net/http"
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
 
 type WrappedResponseWriter struct {
 	StatusCode  int
@@ -36,3 +41,13 @@ func (w *WrappedResponseWriter) WriteHeader(statusCode int) {
 	w.StatusCode = statusCode
 	w.w.WriteHeader(statusCode)
 }
+
+// Hijack lets the handler take over the connection, e.g. to serve a websocket.
+func (w *WrappedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	w.StatusCode = http.StatusSwitchingProtocols
+	return hj.Hijack()
+}
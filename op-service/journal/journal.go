@@ -0,0 +1,239 @@
+// Package journal records the events and external inputs of a service as a sequence of entries,
+// so that the processing of the service can be replayed deterministically.
+//
+// A journal is stored as JSON lines, and gzip-compressed if the path ends with ".gz".
+package journal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type Kind string
+
+const (
+	// KindHeader holds the configuration needed to replay the journal. It is the first entry.
+	KindHeader Kind = "header"
+	// KindEmit is the emission of an event.
+	KindEmit Kind = "emit"
+	// KindDerive is the processing of an event by a deriver.
+	KindDerive Kind = "derive"
+	// KindProcessed marks that an event was processed by all derivers.
+	KindProcessed Kind = "processed"
+	// KindRateLimited is a rate-limited emission.
+	KindRateLimited Kind = "rate-limited"
+	// KindCall is a call to an external service, like an RPC request, with its response.
+	KindCall Kind = "call"
+	// KindInput is an external input that is not an event, like a new L1 head signal.
+	KindInput Kind = "input"
+)
+
+// Entry is a single journal entry. Which fields are set depends on the Kind.
+type Entry struct {
+	Seq  uint64 `json:"seq"`
+	Kind Kind   `json:"kind"`
+	// Time in unix nanoseconds
+	Time int64 `json:"time"`
+
+	// Name of the emitter, deriver, called service or input
+	Name string `json:"name,omitempty"`
+
+	// Event name, for emit, derive and processed entries
+	Event        string `json:"event,omitempty"`
+	EmitContext  uint64 `json:"emitContext,omitempty"`
+	DerivContext uint64 `json:"derivContext,omitempty"`
+	// Input is the sequence number of the input entry that caused an emission, if any
+	Input  uint64 `json:"input,omitempty"`
+	Effect bool   `json:"effect,omitempty"`
+
+	// Method of a call
+	Method string `json:"method,omitempty"`
+	// Data is the event, the call parameters, the input or the header
+	Data json.RawMessage `json:"data,omitempty"`
+	// Result of a call
+	Result json.RawMessage `json:"result,omitempty"`
+	// Err is the error of a call, or why Data could not be encoded
+	Err     string `json:"err,omitempty"`
+	ErrCode int    `json:"errCode,omitempty"`
+}
+
+// Writer appends entries to a journal. It is safe for concurrent use.
+//
+// Writing is best-effort: the first write error stops the journal, and is returned by Close,
+// so that a broken journal never affects the service it records.
+type Writer struct {
+	mu  sync.Mutex
+	seq uint64
+	err error
+
+	enc    *json.Encoder
+	buf    *bufio.Writer
+	closer []io.Closer
+}
+
+// Create creates a journal file, gzip-compressed if the path ends with ".gz".
+func Create(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal: %w", err)
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return NewWriter(f), nil
+	}
+	zw := gzip.NewWriter(f)
+	w := NewWriter(zw)
+	w.closer = append(w.closer, f)
+	return w, nil
+}
+
+// NewWriter creates a journal writer that writes to w, and closes it on Close.
+func NewWriter(w io.WriteCloser) *Writer {
+	buf := bufio.NewWriter(w)
+	return &Writer{
+		enc:    json.NewEncoder(buf),
+		buf:    buf,
+		closer: []io.Closer{w},
+	}
+}
+
+// Write assigns the next sequence number and the current time to the entry, and appends it.
+// It returns the sequence number of the entry.
+func (w *Writer) Write(e Entry) uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.seq++
+	e.Seq = w.seq
+	if e.Time == 0 {
+		e.Time = time.Now().UnixNano()
+	}
+	if w.err == nil {
+		w.err = w.enc.Encode(&e)
+	}
+	return e.Seq
+}
+
+// WriteCall appends a call to the external service source, with its parameters, and its result or error.
+func (w *Writer) WriteCall(source string, method string, params any, result any, err error) {
+	e := Entry{Kind: KindCall, Name: source, Method: method}
+	e.Data, e.Err = encode(params)
+	if err != nil {
+		e.Err = err.Error()
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			e.ErrCode = rpcErr.ErrorCode()
+		}
+	} else if raw, ok := result.(json.RawMessage); ok {
+		e.Result = raw
+	} else {
+		e.Result, e.Err = encode(result)
+	}
+	w.Write(e)
+}
+
+// WriteInput appends an external input, and returns a context that marks emissions as caused by the input.
+func (w *Writer) WriteInput(ctx context.Context, name string, input any) context.Context {
+	e := Entry{Kind: KindInput, Name: name}
+	e.Data, e.Err = encode(input)
+	seq := w.Write(e)
+	return WithInput(ctx, seq)
+}
+
+// Flush writes the buffered entries.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = w.buf.Flush()
+	}
+	return w.err
+}
+
+// Close flushes the journal and closes the underlying writer.
+// It returns the first error that stopped the journal, if any.
+func (w *Writer) Close() error {
+	err := w.Flush()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, c := range w.closer {
+		err = errors.Join(err, c.Close())
+	}
+	w.closer = nil
+	return err
+}
+
+// Read reads all entries of a journal file, which is gzip-compressed if the path ends with ".gz".
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open compressed journal: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	return Decode(r)
+}
+
+// Decode decodes all journal entries of r.
+// A journal that ends in a partially written entry, e.g. because the service crashed, is decoded up to that entry.
+func Decode(r io.Reader) ([]Entry, error) {
+	dec := json.NewDecoder(r)
+	var entries []Entry
+	for {
+		var e Entry
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode journal entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+func encode(v any) (json.RawMessage, string) {
+	if v == nil {
+		return nil, ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Sprintf("failed to encode: %v", err)
+	}
+	return data, ""
+}
+
+type inputKeyType struct{}
+
+var inputKey = inputKeyType{}
+
+// WithInput marks the context as used for processing the input with the given sequence number.
+func WithInput(ctx context.Context, seq uint64) context.Context {
+	return context.WithValue(ctx, inputKey, seq)
+}
+
+// InputFromContext returns the sequence number of the input the context is used for, or 0.
+func InputFromContext(ctx context.Context) uint64 {
+	if ctx == nil {
+		return 0
+	}
+	seq, _ := ctx.Value(inputKey).(uint64)
+	return seq
+}
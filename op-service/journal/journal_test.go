@@ -0,0 +1,100 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type testRPCError struct{}
+
+func (testRPCError) Error() string  { return "header not found" }
+func (testRPCError) ErrorCode() int { return -32000 }
+
+var _ rpc.Error = testRPCError{}
+
+func TestJournal(t *testing.T) {
+	for _, name := range []string{"journal.jsonl", "journal.jsonl.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			w, err := Create(path)
+			require.NoError(t, err)
+
+			w.Write(Entry{Kind: KindHeader, Data: []byte(`{"chain":1}`)})
+			ctx := w.WriteInput(context.Background(), "l1-unsafe", map[string]uint64{"number": 10})
+			require.Equal(t, uint64(2), InputFromContext(ctx))
+			w.WriteCall("l1", "eth_getBlockByNumber", []any{"0xa", false}, map[string]string{"hash": "0x01"}, nil)
+			w.WriteCall("l1", "eth_getBlockByNumber", []any{"0xb", false}, nil, testRPCError{})
+			require.NoError(t, w.Close())
+
+			entries, err := Read(path)
+			require.NoError(t, err)
+			require.Len(t, entries, 4)
+			for i, e := range entries {
+				require.Equal(t, uint64(i+1), e.Seq)
+				require.NotZero(t, e.Time)
+			}
+			require.Equal(t, KindHeader, entries[0].Kind)
+			require.Equal(t, KindInput, entries[1].Kind)
+			require.JSONEq(t, `{"number":10}`, string(entries[1].Data))
+			require.JSONEq(t, `["0xa",false]`, string(entries[2].Data))
+			require.JSONEq(t, `{"hash":"0x01"}`, string(entries[2].Result))
+			require.Equal(t, "header not found", entries[3].Err)
+			require.Equal(t, -32000, entries[3].ErrCode)
+		})
+	}
+}
+
+func TestReadTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	w, err := Create(path)
+	require.NoError(t, err)
+	w.Write(Entry{Kind: KindInput, Name: "a"})
+	w.Write(Entry{Kind: KindInput, Name: "b"})
+	require.NoError(t, w.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data[:len(data)-10], 0o644))
+
+	entries, err := Read(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "a", entries[0].Name)
+}
+
+func TestResponses(t *testing.T) {
+	entries := []Entry{
+		{Kind: KindCall, Name: "l1", Method: "eth_blockNumber", Data: []byte(`[]`), Result: []byte(`"0x1"`)},
+		{Kind: KindCall, Name: "l1", Method: "eth_blockNumber", Data: []byte(`[]`), Result: []byte(`"0x2"`)},
+		{Kind: KindCall, Name: "l2", Method: "eth_blockNumber", Data: []byte(`[]`), Result: []byte(`"0x9"`)},
+		{Kind: KindCall, Name: "l1", Method: "eth_getBlockByHash", Data: []byte(`["0xaa",false]`), Err: "not found", ErrCode: -32000},
+		{Kind: KindInput, Name: "l1-unsafe"},
+	}
+	r := NewResponses(entries)
+
+	var num string
+	require.NoError(t, r.Call("l1", "eth_blockNumber", []any{}, &num))
+	require.Equal(t, "0x1", num)
+	require.NoError(t, r.Call("l1", "eth_blockNumber", []any{}, &num))
+	require.Equal(t, "0x2", num)
+	require.NoError(t, r.Call("l1", "eth_blockNumber", []any{}, &num))
+	require.Equal(t, "0x2", num, "the last response repeats")
+	require.NoError(t, r.Call("l2", "eth_blockNumber", []any{}, &num))
+	require.Equal(t, "0x9", num)
+
+	err := r.Call("l1", "eth_getBlockByHash", []any{"0xaa", false}, &num)
+	var rpcErr rpc.Error
+	require.True(t, errors.As(err, &rpcErr))
+	require.Equal(t, -32000, rpcErr.ErrorCode())
+	require.Equal(t, "not found", err.Error())
+
+	err = r.Call("l1", "eth_getBlockByHash", []any{"0xbb", false}, &num)
+	require.ErrorIs(t, err, ErrNotRecorded)
+}
@@ -0,0 +1,94 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotRecorded is returned when replaying a call that was not recorded in the journal.
+var ErrNotRecorded = errors.New("call not recorded in journal")
+
+// Responses serves the recorded responses of calls to external services during a replay.
+//
+// Calls are matched by source, method and parameters. Responses to repeated calls are served
+// in the recorded order, and the last response is served again once they run out, since a
+// replay may poll a little more often than the recording did.
+type Responses struct {
+	mu    sync.Mutex
+	calls map[string]*recordedCalls
+}
+
+type recordedCalls struct {
+	entries []Entry
+	next    int
+}
+
+// NewResponses indexes the call entries of a journal.
+func NewResponses(entries []Entry) *Responses {
+	r := &Responses{calls: make(map[string]*recordedCalls)}
+	for _, e := range entries {
+		if e.Kind != KindCall {
+			continue
+		}
+		key := callKey(e.Name, e.Method, e.Data)
+		c, ok := r.calls[key]
+		if !ok {
+			c = &recordedCalls{}
+			r.calls[key] = c
+		}
+		c.entries = append(c.entries, e)
+	}
+	return r
+}
+
+// Call decodes the recorded response of the call into result, or returns the recorded error.
+func (r *Responses) Call(source string, method string, params any, result any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode params of %s: %w", method, err)
+	}
+	r.mu.Lock()
+	c, ok := r.calls[callKey(source, method, data)]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s %s %s", ErrNotRecorded, source, method, data)
+	}
+	e := c.entries[c.next]
+	if c.next < len(c.entries)-1 {
+		c.next++
+	}
+	r.mu.Unlock()
+	if e.Err != "" {
+		return &CallError{Message: e.Err, Code: e.ErrCode}
+	}
+	if result == nil || len(e.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(e.Result, result); err != nil {
+		return fmt.Errorf("failed to decode recorded result of %s: %w", method, err)
+	}
+	return nil
+}
+
+func callKey(source string, method string, params json.RawMessage) string {
+	if len(params) == 0 {
+		params = json.RawMessage("null")
+	}
+	return source + "/" + method + "/" + string(params)
+}
+
+// CallError is a recorded error of a call. It keeps the JSON-RPC error code, if any.
+type CallError struct {
+	Message string
+	Code    int
+}
+
+func (e *CallError) Error() string {
+	return e.Message
+}
+
+func (e *CallError) ErrorCode() int {
+	return e.Code
+}
@@ -13,13 +13,6 @@ import (
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
 )
 
-// WithWebsocketEnabled enables WebSocket support on the server.
-func WithWebsocketEnabled() ServerOption {
-	return func(b *Server) {
-		// WebSocket support is always enabled in this build
-	}
-}
-
 // WithRPCRecorder sets the RPC recorder for metrics.
 func WithRPCRecorder(_ opmetrics.HTTPRecorder) ServerOption {
 	return func(b *Server) {
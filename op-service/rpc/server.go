@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -17,6 +18,7 @@ import (
 	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
 	optls "github.com/tokamak-network/tokamak-thanos/op-service/tls"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tracing"
 )
 
 var wildcardHosts = []string{"*"}
@@ -37,7 +39,8 @@ type Server struct {
 	log            log.Logger
 	tls            *ServerTLSConfig
 	middlewares    []Middleware
-	Handler       *Handler
+	wsEnabled      bool
+	Handler        *Handler
 }
 
 type ServerTLSConfig struct {
@@ -111,6 +114,14 @@ func WithTLSConfig(tls *ServerTLSConfig) ServerOption {
 	}
 }
 
+// WithWebsocketEnabled serves websocket connections on the RPC path, next to HTTP,
+// to support RPC subscriptions.
+func WithWebsocketEnabled() ServerOption {
+	return func(b *Server) {
+		b.wsEnabled = true
+	}
+}
+
 // WithMiddleware adds an http.Handler to the rpc server handler stack
 // The added middleware is invoked directly before the RPC callback
 func WithMiddleware(middleware func(http.Handler) (hdlr http.Handler)) ServerOption {
@@ -165,12 +176,19 @@ func (b *Server) Start() error {
 		return fmt.Errorf("error registering APIs: %w", err)
 	}
 
-	// rpc middleware
-	var nodeHdlr http.Handler = srv
-	for _, middleware := range b.middlewares {
-		nodeHdlr = middleware(nodeHdlr)
+	// rpc middleware, of both the HTTP and the websocket RPC handlers
+	withMiddlewares := func(hdlr http.Handler) http.Handler {
+		for _, middleware := range b.middlewares {
+			hdlr = middleware(hdlr)
+		}
+		// attach the RPC handlers to the trace of the caller
+		return tracing.NewHTTPMiddleware(hdlr)
+	}
+	nodeHdlr := node.NewHTTPHandlerStack(withMiddlewares(srv), b.corsHosts, b.vHosts, b.jwtSecret)
+	if b.wsEnabled {
+		wsHdlr := node.NewWSHandlerStack(withMiddlewares(srv.WebsocketHandler(b.corsHosts)), b.jwtSecret)
+		nodeHdlr = withWebsocket(wsHdlr, nodeHdlr)
 	}
-	nodeHdlr = node.NewHTTPHandlerStack(nodeHdlr, b.corsHosts, b.vHosts, b.jwtSecret)
 
 	mux := http.NewServeMux()
 	mux.Handle(b.rpcPath, nodeHdlr)
@@ -223,6 +241,18 @@ func (b *Server) Stop() error {
 	return nil
 }
 
+// withWebsocket serves websocket upgrade requests with ws, and any other request with next.
+func withWebsocket(ws http.Handler, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+			strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+			ws.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 type HealthzResponse struct {
 	Version string `json:"version"`
 }
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/rpc"
@@ -60,6 +61,11 @@ func TestBaseServer(t *testing.T) {
 		require.Equal(t, 4, res)
 	})
 
+	t.Run("rejects websocket by default", func(t *testing.T) {
+		_, err := rpc.Dial(fmt.Sprintf("ws://%s", server.endpoint))
+		require.Error(t, err)
+	})
+
 	t.Run("supports 0 port", func(t *testing.T) {
 		endpoint := server.Endpoint()
 		_, portStr, err := net.SplitHostPort(endpoint)
@@ -69,3 +75,47 @@ func TestBaseServer(t *testing.T) {
 		require.Greater(t, port, 0)
 	})
 }
+
+func TestWebsocketServer(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	server := NewServer(
+		"127.0.0.1",
+		0,
+		"test",
+		WithAPIs([]rpc.API{
+			{
+				Namespace: "test",
+				Service:   new(testAPI),
+			},
+		}),
+		WithWebsocketEnabled(),
+		WithMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				seen = append(seen, r.Header.Get("Upgrade"))
+				mu.Unlock()
+				next.ServeHTTP(w, r)
+			})
+		}),
+	)
+	require.NoError(t, server.Start())
+	defer func() {
+		_ = server.Stop()
+	}()
+
+	for _, scheme := range []string{"ws", "http"} {
+		t.Run(scheme, func(t *testing.T) {
+			rpcClient, err := rpc.Dial(fmt.Sprintf("%s://%s", scheme, server.endpoint))
+			require.NoError(t, err)
+			defer rpcClient.Close()
+			var res int
+			require.NoError(t, rpcClient.Call(&res, "test_frobnicate", 2))
+			require.Equal(t, 4, res)
+		})
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	require.Contains(t, seen, "websocket", "middleware must handle the websocket upgrade")
+	require.Contains(t, seen, "", "middleware must handle the http requests")
+}
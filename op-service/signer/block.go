@@ -9,6 +9,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 )
 
@@ -87,8 +89,57 @@ func NewLocalSigner(key *ecdsa.PrivateKey) *LocalBlockSigner {
 	return NewLocalBlockSigner(key)
 }
 
+// BlockPayloadArgs are the arguments of the opsigner_signBlockPayload RPC, that signs a block payload for p2p gossip.
+type BlockPayloadArgs struct {
+	ChainID     eth.ChainID `json:"chainId"`
+	PayloadHash common.Hash `json:"payloadHash"`
+	// SenderAddress is the address the client expects the payload to be signed by, if set.
+	SenderAddress *common.Address `json:"senderAddress,omitempty"`
+}
+
+func NewBlockPayloadArgs(chainID eth.ChainID, payloadHash common.Hash, sender *common.Address) *BlockPayloadArgs {
+	return &BlockPayloadArgs{
+		ChainID:       chainID,
+		PayloadHash:   payloadHash,
+		SenderAddress: sender,
+	}
+}
+
+func (args *BlockPayloadArgs) Check() error {
+	if args.ChainID == (eth.ChainID{}) {
+		return errors.New("chain id not specified")
+	}
+	if args.PayloadHash == (common.Hash{}) {
+		return errors.New("payload hash not specified")
+	}
+	return nil
+}
+
+// Message returns the hash to sign, that SignedP2PBlock.VerifySignature verifies the signature against.
+func (args *BlockPayloadArgs) Message() common.Hash {
+	return blockSigningHash(args.ChainID, args.PayloadHash)
+}
+
+// RemoteSigner signs blocks with a remote signer service.
+type RemoteSigner struct {
+	client *SignerClient
+	sender common.Address
+}
+
+var _ BlockSigner = (*RemoteSigner)(nil)
+
 // NewRemoteSigner creates a remote signer from CLI config.
-// For now this is a stub that returns an error.
-func NewRemoteSigner(log interface{}, cfg CLIConfig) (BlockSigner, error) {
-	return nil, errors.New("remote signer not yet supported in tokamak-thanos")
+func NewRemoteSigner(logger log.Logger, cfg CLIConfig) (*RemoteSigner, error) {
+	client, err := NewSignerClientFromConfig(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(cfg.Address) {
+		return nil, fmt.Errorf("invalid signer address: %q", cfg.Address)
+	}
+	return &RemoteSigner{client: client, sender: common.HexToAddress(cfg.Address)}, nil
+}
+
+func (s *RemoteSigner) SignBlockV1(ctx context.Context, chainID eth.ChainID, payloadHash common.Hash) (eth.Bytes65, error) {
+	return s.client.SignBlockPayload(ctx, NewBlockPayloadArgs(chainID, payloadHash, &s.sender))
 }
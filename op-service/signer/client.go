@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	optls "github.com/tokamak-network/tokamak-thanos/op-service/tls"
 	"github.com/tokamak-network/tokamak-thanos/op-service/tls/certman"
 )
@@ -113,3 +114,36 @@ func (s *SignerClient) SignTransaction(ctx context.Context, chainId *big.Int, fr
 
 	return &signed, nil
 }
+
+// SignBlockPayload signs a block payload for p2p gossip with the opsigner_signBlockPayload RPC.
+func (s *SignerClient) SignBlockPayload(ctx context.Context, args *BlockPayloadArgs) (eth.Bytes65, error) {
+	var result hexutil.Bytes
+	if err := s.client.CallContext(ctx, &result, "opsigner_signBlockPayload", args); err != nil {
+		return eth.Bytes65{}, fmt.Errorf("opsigner_signBlockPayload failed: %w", err)
+	}
+	if len(result) != 65 {
+		return eth.Bytes65{}, fmt.Errorf("invalid signature length: %d", len(result))
+	}
+	var sig eth.Bytes65
+	copy(sig[:], result)
+	return sig, nil
+}
+
+// SignPreconfirmation signs a preconfirmation with the opsigner_signPreconfirmation RPC.
+func (s *SignerClient) SignPreconfirmation(ctx context.Context, args *PreconfirmationArgs) (eth.Bytes65, error) {
+	var result hexutil.Bytes
+	if err := s.client.CallContext(ctx, &result, "opsigner_signPreconfirmation", args); err != nil {
+		return eth.Bytes65{}, fmt.Errorf("opsigner_signPreconfirmation failed: %w", err)
+	}
+	if len(result) != 65 {
+		return eth.Bytes65{}, fmt.Errorf("invalid signature length: %d", len(result))
+	}
+	var sig eth.Bytes65
+	copy(sig[:], result)
+	return sig, nil
+}
+
+// Close closes the connection to the signer.
+func (s *SignerClient) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,173 @@
+package signer
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+// PreconfirmationDomainV1 prefixes the encoding of a preconfirmation.
+var PreconfirmationDomainV1 = crypto.Keccak256Hash([]byte("OPSTACK_PRECONFIRMATION_V1"))
+
+// PreconfirmationSigner signs preconfirmations. Preconfirmations are signed in their own signing domain,
+// so a preconfirmation signature is never a valid block signature, and the other way around.
+type PreconfirmationSigner interface {
+	SignPreconfirmationV1(ctx context.Context, chainID eth.ChainID, preconfHash common.Hash) (sig eth.Bytes65, err error)
+}
+
+// PreconfirmationSize is the size of an encoded preconfirmation.
+const PreconfirmationSize = 32 + 32 + 8
+
+// Preconfirmation is the commitment of the sequencer to include a transaction in a block.
+type Preconfirmation struct {
+	TxHash      common.Hash        `json:"txHash"`
+	BlockNumber eth.Uint64Quantity `json:"blockNumber"`
+}
+
+// MarshalBinary encodes the preconfirmation as domain, tx hash and big-endian block number.
+func (p *Preconfirmation) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, PreconfirmationSize)
+	out = append(out, PreconfirmationDomainV1[:]...)
+	out = append(out, p.TxHash[:]...)
+	out = binary.BigEndian.AppendUint64(out, uint64(p.BlockNumber))
+	return out, nil
+}
+
+func (p *Preconfirmation) UnmarshalBinary(data []byte) error {
+	if len(data) != PreconfirmationSize {
+		return fmt.Errorf("invalid preconfirmation size %d, expected %d", len(data), PreconfirmationSize)
+	}
+	if common.Hash(data[:32]) != PreconfirmationDomainV1 {
+		return fmt.Errorf("unknown preconfirmation domain %x", data[:32])
+	}
+	p.TxHash = common.Hash(data[32:64])
+	p.BlockNumber = eth.Uint64Quantity(binary.BigEndian.Uint64(data[64:]))
+	return nil
+}
+
+func (p *Preconfirmation) String() string {
+	return fmt.Sprintf("preconf(%s@%d)", p.TxHash, uint64(p.BlockNumber))
+}
+
+// SignedPreconfirmation is a preconfirmation with the signature of the sequencer.
+type SignedPreconfirmation struct {
+	Preconfirmation
+	Signature eth.Bytes65 `json:"signature"`
+}
+
+// SignPreconfirmation signs the preconfirmation with the preconfirmation signer of the sequencer.
+func SignPreconfirmation(ctx context.Context, s PreconfirmationSigner, chainID eth.ChainID, p Preconfirmation) (*SignedPreconfirmation, error) {
+	raw, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.SignPreconfirmationV1(ctx, chainID, PayloadHash(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign %s: %w", &p, err)
+	}
+	return &SignedPreconfirmation{Preconfirmation: p, Signature: sig}, nil
+}
+
+// VerifySignature verifies that the preconfirmation was signed by the allowed sequencer.
+func (p *SignedPreconfirmation) VerifySignature(auth *OPStackP2PBlockAuthV1) error {
+	raw, err := p.Preconfirmation.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	msg := preconfirmationSigningHash(auth.Chain, PayloadHash(raw))
+	pubKey, err := crypto.SigToPub(msg[:], p.Signature[:])
+	if err != nil {
+		return fmt.Errorf("failed to recover public key: %w", err)
+	}
+	addr := crypto.PubkeyToAddress(*pubKey)
+	if addr != auth.Allowed {
+		return fmt.Errorf("signer %s is not allowed (expected %s)", addr, auth.Allowed)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the signed preconfirmation as signature followed by the preconfirmation,
+// like a signed block is encoded for p2p gossip.
+func (p *SignedPreconfirmation) MarshalBinary() ([]byte, error) {
+	raw, err := p.Preconfirmation.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(p.Signature[:], raw...), nil
+}
+
+func (p *SignedPreconfirmation) UnmarshalBinary(data []byte) error {
+	if len(data) != 65+PreconfirmationSize {
+		return fmt.Errorf("invalid signed preconfirmation size %d, expected %d", len(data), 65+PreconfirmationSize)
+	}
+	copy(p.Signature[:], data[:65])
+	return p.Preconfirmation.UnmarshalBinary(data[65:])
+}
+
+// preconfirmationSigningHash creates the message to sign for a preconfirmation.
+// It differs from blockSigningHash in the domain tag only.
+func preconfirmationSigningHash(chainID eth.ChainID, preconfHash common.Hash) common.Hash {
+	v, _ := chainID.Uint64()
+	domain := crypto.Keccak256Hash([]byte("OPSTACK_PRECONFIRMATION_SIGNING"), common.BigToHash(new(big.Int).SetUint64(v)).Bytes())
+	return crypto.Keccak256Hash(domain[:], preconfHash[:])
+}
+
+var _ PreconfirmationSigner = (*LocalBlockSigner)(nil)
+
+func (s *LocalBlockSigner) SignPreconfirmationV1(ctx context.Context, chainID eth.ChainID, preconfHash common.Hash) (eth.Bytes65, error) {
+	if s.key == nil {
+		return eth.Bytes65{}, errors.New("no signing key configured")
+	}
+	msg := preconfirmationSigningHash(chainID, preconfHash)
+	sig, err := crypto.Sign(msg[:], s.key)
+	if err != nil {
+		return eth.Bytes65{}, err
+	}
+	var out eth.Bytes65
+	copy(out[:], sig)
+	return out, nil
+}
+
+// PreconfirmationArgs are the arguments of the opsigner_signPreconfirmation RPC, that signs a preconfirmation.
+type PreconfirmationArgs struct {
+	ChainID     eth.ChainID `json:"chainId"`
+	PreconfHash common.Hash `json:"preconfHash"`
+	// SenderAddress is the address the client expects the preconfirmation to be signed by, if set.
+	SenderAddress *common.Address `json:"senderAddress,omitempty"`
+}
+
+func NewPreconfirmationArgs(chainID eth.ChainID, preconfHash common.Hash, sender *common.Address) *PreconfirmationArgs {
+	return &PreconfirmationArgs{
+		ChainID:       chainID,
+		PreconfHash:   preconfHash,
+		SenderAddress: sender,
+	}
+}
+
+func (args *PreconfirmationArgs) Check() error {
+	if args.ChainID == (eth.ChainID{}) {
+		return errors.New("chain id not specified")
+	}
+	if args.PreconfHash == (common.Hash{}) {
+		return errors.New("preconfirmation hash not specified")
+	}
+	return nil
+}
+
+// Message returns the hash to sign, that SignedPreconfirmation.VerifySignature verifies the signature against.
+func (args *PreconfirmationArgs) Message() common.Hash {
+	return preconfirmationSigningHash(args.ChainID, args.PreconfHash)
+}
+
+var _ PreconfirmationSigner = (*RemoteSigner)(nil)
+
+func (s *RemoteSigner) SignPreconfirmationV1(ctx context.Context, chainID eth.ChainID, preconfHash common.Hash) (eth.Bytes65, error) {
+	return s.client.SignPreconfirmation(ctx, NewPreconfirmationArgs(chainID, preconfHash, &s.sender))
+}
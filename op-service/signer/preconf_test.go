@@ -0,0 +1,67 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+func TestSignPreconfirmation(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chainID := eth.ChainIDFromUInt64(901)
+	p := Preconfirmation{TxHash: common.Hash{0xaa}, BlockNumber: 1234}
+
+	signed, err := SignPreconfirmation(context.Background(), NewLocalBlockSigner(key), chainID, p)
+	require.NoError(t, err)
+	auth := &OPStackP2PBlockAuthV1{Allowed: crypto.PubkeyToAddress(key.PublicKey), Chain: chainID}
+	require.NoError(t, signed.VerifySignature(auth))
+
+	data, err := signed.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, data, 65+PreconfirmationSize)
+	var decoded SignedPreconfirmation
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, *signed, decoded)
+
+	t.Run("other signer", func(t *testing.T) {
+		other := &OPStackP2PBlockAuthV1{Allowed: common.Address{0x01}, Chain: chainID}
+		require.Error(t, signed.VerifySignature(other))
+	})
+	t.Run("other chain", func(t *testing.T) {
+		other := &OPStackP2PBlockAuthV1{Allowed: auth.Allowed, Chain: eth.ChainIDFromUInt64(902)}
+		require.Error(t, signed.VerifySignature(other))
+	})
+	t.Run("other block", func(t *testing.T) {
+		changed := *signed
+		changed.BlockNumber++
+		require.Error(t, changed.VerifySignature(auth))
+	})
+	t.Run("not a block signature", func(t *testing.T) {
+		raw, err := p.MarshalBinary()
+		require.NoError(t, err)
+		block := &SignedP2PBlock{Raw: raw, Signature: signed.Signature}
+		require.Error(t, block.VerifySignature(auth), "a preconfirmation signature must not verify as a block signature")
+
+		blockSig, err := NewLocalBlockSigner(key).SignBlockV1(context.Background(), chainID, PayloadHash(raw))
+		require.NoError(t, err)
+		forged := SignedPreconfirmation{Preconfirmation: p, Signature: blockSig}
+		require.Error(t, forged.VerifySignature(auth), "a block signature must not verify as a preconfirmation signature")
+
+		var payload eth.ExecutionPayload
+		require.Error(t, payload.UnmarshalSSZ(eth.BlockV1, uint32(len(raw)), bytes.NewReader(raw)),
+			"a preconfirmation must not decode as a block payload")
+	})
+	t.Run("bad domain", func(t *testing.T) {
+		data := append([]byte{}, data...)
+		data[65] ^= 1
+		require.Error(t, new(SignedPreconfirmation).UnmarshalBinary(data))
+	})
+}
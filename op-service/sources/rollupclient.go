@@ -33,6 +33,23 @@ func (r *RollupClient) SafeHeadAtL1Block(ctx context.Context, blockNum uint64) (
 	return output, err
 }
 
+// SafeHeadsInRange returns the safe head updates recorded at L1 blocks in the inclusive range [start, end].
+// The node limits the number of entries per call, so callers should continue from the L1 block after the
+// last returned entry until the result is empty.
+func (r *RollupClient) SafeHeadsInRange(ctx context.Context, start uint64, end uint64) ([]eth.SafeHeadResponse, error) {
+	var output []eth.SafeHeadResponse
+	err := r.rpc.CallContext(ctx, &output, "optimism_safeHeadsInRange", hexutil.Uint64(start), hexutil.Uint64(end))
+	return output, err
+}
+
+// WithdrawalProof returns the arguments to prove a withdrawal on L1 against the output or dispute game at the given index.
+// The withdrawal is identified by the hash of the L2 transaction that initiated it, or by the withdrawal hash.
+func (r *RollupClient) WithdrawalProof(ctx context.Context, hash common.Hash, index uint64) (*eth.WithdrawalProofResponse, error) {
+	var output *eth.WithdrawalProofResponse
+	err := r.rpc.CallContext(ctx, &output, "optimism_withdrawalProof", hash, hexutil.Uint64(index))
+	return output, err
+}
+
 func (r *RollupClient) SyncStatus(ctx context.Context) (*eth.SyncStatus, error) {
 	var output *eth.SyncStatus
 	err := r.rpc.CallContext(ctx, &output, "optimism_syncStatus")
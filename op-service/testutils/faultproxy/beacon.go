@@ -0,0 +1,105 @@
+package faultproxy
+
+import (
+	"fmt"
+	"net/http"
+	stdhttputil "net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/httputil"
+)
+
+const blobSidecarsPath = "/eth/v1/beacon/blob_sidecars/"
+
+// BeaconProxy is a proxy in front of a beacon API endpoint, that injects the scripted faults.
+// It supports FaultLatency, FaultDrop and FaultBlobsNotFound, that match the URL path by prefix.
+type BeaconProxy struct {
+	log    log.Logger
+	proxy  *stdhttputil.ReverseProxy
+	faults faultSet
+
+	srv *httputil.HTTPServer
+}
+
+func NewBeaconProxy(logger log.Logger, upstream string) (*BeaconProxy, error) {
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("invalid beacon endpoint %q: %w", upstream, err)
+	}
+	return &BeaconProxy{
+		log:   logger,
+		proxy: stdhttputil.NewSingleHostReverseProxy(target),
+	}, nil
+}
+
+func (p *BeaconProxy) Start(addr string) error {
+	srv, err := httputil.StartHTTPServer(addr, p)
+	if err != nil {
+		return fmt.Errorf("failed to start beacon fault proxy: %w", err)
+	}
+	p.srv = srv
+	p.log.Info("Started beacon fault proxy", "endpoint", p.Endpoint())
+	return nil
+}
+
+// Endpoint returns the HTTP endpoint of the started proxy.
+func (p *BeaconProxy) Endpoint() string {
+	return "http://" + p.srv.Addr().String()
+}
+
+// AddFaults adds faults that apply to the following requests.
+func (p *BeaconProxy) AddFaults(faults ...Fault) {
+	p.faults.add(faults...)
+}
+
+// ClearFaults removes all faults.
+func (p *BeaconProxy) ClearFaults() {
+	p.faults.clear()
+}
+
+func (p *BeaconProxy) Close() error {
+	if p.srv == nil {
+		return nil
+	}
+	return p.srv.Close()
+}
+
+func (p *BeaconProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	faults := p.faults.take(func(f Fault) bool {
+		if !strings.HasPrefix(r.URL.Path, f.Match) {
+			return false
+		}
+		switch f.Kind {
+		case FaultLatency, FaultDrop:
+			return true
+		case FaultBlobsNotFound:
+			return strings.HasPrefix(r.URL.Path, blobSidecarsPath)
+		default:
+			return false
+		}
+	})
+	if len(faults) > 0 {
+		p.log.Debug("Applying faults", "path", r.URL.Path, "faults", faults)
+	}
+
+	if d := totalLatency(faults); d > 0 {
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if hasKind(faults, FaultDrop) {
+		// Close the connection without a response.
+		panic(http.ErrAbortHandler)
+	}
+	if hasKind(faults, FaultBlobsNotFound) {
+		http.Error(w, `{"code":404,"message":"NOT_FOUND: beacon block"}`, http.StatusNotFound)
+		return
+	}
+	p.proxy.ServeHTTP(w, r)
+}
@@ -0,0 +1,104 @@
+// Package faultproxy provides proxies that sit in front of an L1, engine or beacon API endpoint, and inject
+// scripted faults into the responses, to test how clients handle flaky infrastructure.
+package faultproxy
+
+import (
+	"sync"
+	"time"
+)
+
+type FaultKind string
+
+const (
+	// FaultLatency delays the response by Fault.Latency.
+	FaultLatency FaultKind = "latency"
+	// FaultDrop drops the response by closing the connection. JSON-RPC requests are still forwarded,
+	// so that their side effects, such as a forkchoice update of an engine, apply.
+	FaultDrop FaultKind = "drop"
+	// FaultStaleHead serves the previously served block of eth_getBlockByNumber queries by label, such as "latest",
+	// so that the head appears inconsistent across calls.
+	FaultStaleHead FaultKind = "stale-head"
+	// FaultMissingReceipts serves no receipts for receipt queries, as if the node did not have them.
+	FaultMissingReceipts FaultKind = "missing-receipts"
+	// FaultBlobsNotFound serves a 404 for beacon API blob sidecar queries.
+	FaultBlobsNotFound FaultKind = "blobs-not-found"
+)
+
+// Fault is a scripted fault of a proxy.
+type Fault struct {
+	Kind FaultKind
+	// Match is the JSON-RPC method, or the URL path prefix of the beacon API, the fault applies to.
+	// The fault applies to all calls of its kind if empty.
+	Match string
+	// Latency is the delay of a FaultLatency.
+	Latency time.Duration
+	// Skip is the number of matching calls to pass before the fault applies.
+	Skip int
+	// Times is the number of matching calls the fault applies to after skipping, or all calls if 0.
+	Times int
+}
+
+type activeFault struct {
+	Fault
+	calls int
+}
+
+// faultSet is the set of faults of a proxy, that tracks the number of calls each fault matched.
+type faultSet struct {
+	mu     sync.Mutex
+	faults []*activeFault
+}
+
+func (s *faultSet) add(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range faults {
+		s.faults = append(s.faults, &activeFault{Fault: f})
+	}
+}
+
+func (s *faultSet) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// take counts the call for the faults that match it, and returns the faults that apply to it.
+// Faults that applied as many times as scripted are removed.
+func (s *faultSet) take(matches func(f Fault) bool) []Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Fault
+	remaining := s.faults[:0]
+	for _, f := range s.faults {
+		if matches(f.Fault) {
+			f.calls++
+			if f.calls > f.Skip {
+				out = append(out, f.Fault)
+			}
+		}
+		if f.Times == 0 || f.calls < f.Skip+f.Times {
+			remaining = append(remaining, f)
+		}
+	}
+	s.faults = remaining
+	return out
+}
+
+func hasKind(faults []Fault, kind FaultKind) bool {
+	for _, f := range faults {
+		if f.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func totalLatency(faults []Fault) (out time.Duration) {
+	for _, f := range faults {
+		if f.Kind == FaultLatency {
+			out += f.Latency
+		}
+	}
+	return out
+}
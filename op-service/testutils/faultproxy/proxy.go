@@ -0,0 +1,280 @@
+package faultproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/client"
+	"github.com/tokamak-network/tokamak-thanos/op-service/httputil"
+)
+
+const (
+	maxRequestSize = 10 * 1024 * 1024
+
+	errCodeParse    = -32700
+	errCodeParams   = -32602
+	errCodeInternal = -32603
+)
+
+// receiptMethods are the methods that FaultMissingReceipts applies to.
+var receiptMethods = map[string]bool{
+	"eth_getTransactionReceipt":          true,
+	"eth_getBlockReceipts":               true,
+	"debug_getRawReceipts":               true,
+	"parity_getBlockReceipts":            true,
+	"erigon_getBlockReceiptsByBlockHash": true,
+}
+
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+type jsonrpcMessage struct {
+	Version string          `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Error   *jsonError      `json:"error,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+}
+
+func (m *jsonrpcMessage) params() ([]any, error) {
+	if len(m.Params) == 0 || string(m.Params) == "null" {
+		return nil, nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(m.Params, &raw); err != nil {
+		return nil, err
+	}
+	out := make([]any, len(raw))
+	for i, p := range raw {
+		out[i] = p
+	}
+	return out, nil
+}
+
+// headLabel returns the block label of an eth_getBlockByNumber query by label, or an empty string otherwise.
+func (m *jsonrpcMessage) headLabel(params []any) string {
+	if m.Method != "eth_getBlockByNumber" || len(params) == 0 {
+		return ""
+	}
+	var label string
+	if err := json.Unmarshal(params[0].(json.RawMessage), &label); err != nil || strings.HasPrefix(label, "0x") {
+		return ""
+	}
+	return label
+}
+
+func errorResponse(id json.RawMessage, code int, err error) *jsonrpcMessage {
+	jerr := &jsonError{Code: code, Message: err.Error()}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		jerr.Code = rpcErr.ErrorCode()
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		jerr.Data = dataErr.ErrorData()
+	}
+	return &jsonrpcMessage{Version: "2.0", ID: id, Error: jerr}
+}
+
+// Proxy is a JSON-RPC proxy over HTTP in front of an L1 or engine endpoint, that injects the scripted faults.
+//
+// Requests are forwarded to the upstream RPC, which holds the credentials of the endpoint, such as the JWT secret
+// of an engine API. The proxy does not authenticate requests itself.
+type Proxy struct {
+	log      log.Logger
+	upstream client.RPC
+	faults   faultSet
+
+	// heads are the last served results of eth_getBlockByNumber queries by label, by their params.
+	headsLock sync.Mutex
+	heads     map[string]json.RawMessage
+
+	srv *httputil.HTTPServer
+}
+
+func NewProxy(logger log.Logger, upstream client.RPC) *Proxy {
+	return &Proxy{
+		log:      logger,
+		upstream: upstream,
+		heads:    make(map[string]json.RawMessage),
+	}
+}
+
+func (p *Proxy) Start(addr string) error {
+	srv, err := httputil.StartHTTPServer(addr, p)
+	if err != nil {
+		return fmt.Errorf("failed to start fault proxy: %w", err)
+	}
+	p.srv = srv
+	p.log.Info("Started fault proxy", "endpoint", p.Endpoint())
+	return nil
+}
+
+// Endpoint returns the HTTP endpoint of the started proxy.
+func (p *Proxy) Endpoint() string {
+	return "http://" + p.srv.Addr().String()
+}
+
+// AddFaults adds faults that apply to the following calls.
+func (p *Proxy) AddFaults(faults ...Fault) {
+	p.faults.add(faults...)
+}
+
+// ClearFaults removes all faults.
+func (p *Proxy) ClearFaults() {
+	p.faults.clear()
+}
+
+// Reorg rewinds the upstream chain by depth blocks with debug_setHead, so that the blocks that are built next
+// replace the rewound blocks. The upstream must serve the debug namespace and keep building blocks, like the L1
+// of the op-e2e system does.
+func (p *Proxy) Reorg(ctx context.Context, depth uint64) error {
+	var head hexutil.Uint64
+	if err := p.upstream.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return fmt.Errorf("failed to get head: %w", err)
+	}
+	if uint64(head) < depth {
+		return fmt.Errorf("cannot reorg %d blocks of chain with head %d", depth, head)
+	}
+	if err := p.upstream.CallContext(ctx, nil, "debug_setHead", head-hexutil.Uint64(depth)); err != nil {
+		return fmt.Errorf("failed to set head: %w", err)
+	}
+	p.log.Info("Reorged upstream chain", "head", uint64(head), "depth", depth)
+	return nil
+}
+
+// Close stops the proxy and closes the upstream RPC.
+func (p *Proxy) Close() error {
+	var err error
+	if p.srv != nil {
+		err = p.srv.Close()
+	}
+	p.upstream.Close()
+	return err
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var msgs []*jsonrpcMessage
+	batch := len(bytes.TrimSpace(body)) > 0 && bytes.TrimSpace(body)[0] == '['
+	if batch {
+		err = json.Unmarshal(body, &msgs)
+	} else {
+		var msg jsonrpcMessage
+		err = json.Unmarshal(body, &msg)
+		msgs = []*jsonrpcMessage{&msg}
+	}
+	if err != nil {
+		writeJSON(w, errorResponse(json.RawMessage("null"), errCodeParse, err))
+		return
+	}
+
+	responses := make([]*jsonrpcMessage, len(msgs))
+	dropped := make([]bool, len(msgs))
+	var wg sync.WaitGroup
+	for i, msg := range msgs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i], dropped[i] = p.call(r.Context(), msg)
+		}()
+	}
+	wg.Wait()
+	for _, drop := range dropped {
+		if drop {
+			// Close the connection without a response.
+			panic(http.ErrAbortHandler)
+		}
+	}
+	if batch {
+		writeJSON(w, responses)
+	} else {
+		writeJSON(w, responses[0])
+	}
+}
+
+// call forwards the call upstream, with the faults that apply to it.
+// It returns the response, and whether the response must be dropped.
+func (p *Proxy) call(ctx context.Context, msg *jsonrpcMessage) (*jsonrpcMessage, bool) {
+	params, err := msg.params()
+	if err != nil {
+		return errorResponse(msg.ID, errCodeParams, fmt.Errorf("invalid params: %w", err)), false
+	}
+	label := msg.headLabel(params)
+	faults := p.faults.take(func(f Fault) bool {
+		if f.Match != "" && f.Match != msg.Method {
+			return false
+		}
+		switch f.Kind {
+		case FaultLatency, FaultDrop:
+			return true
+		case FaultStaleHead:
+			return label != ""
+		case FaultMissingReceipts:
+			return receiptMethods[msg.Method]
+		default:
+			return false
+		}
+	})
+	if len(faults) > 0 {
+		p.log.Debug("Applying faults", "method", msg.Method, "faults", faults)
+	}
+	drop := hasKind(faults, FaultDrop)
+
+	if d := totalLatency(faults); d > 0 {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return errorResponse(msg.ID, errCodeInternal, ctx.Err()), drop
+		}
+	}
+
+	if hasKind(faults, FaultMissingReceipts) {
+		return &jsonrpcMessage{Version: "2.0", ID: msg.ID, Result: json.RawMessage("null")}, drop
+	}
+	if label != "" && hasKind(faults, FaultStaleHead) {
+		p.headsLock.Lock()
+		stale, ok := p.heads[string(msg.Params)]
+		p.headsLock.Unlock()
+		if ok {
+			return &jsonrpcMessage{Version: "2.0", ID: msg.ID, Result: stale}, drop
+		}
+	}
+
+	var result json.RawMessage
+	if err := p.upstream.CallContext(ctx, &result, msg.Method, params...); err != nil {
+		return errorResponse(msg.ID, errCodeInternal, err), drop
+	}
+	if label != "" {
+		p.headsLock.Lock()
+		p.heads[string(msg.Params)] = result
+		p.headsLock.Unlock()
+	}
+	return &jsonrpcMessage{Version: "2.0", ID: msg.ID, Result: result}, drop
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	// An error means the client went away, there is nothing to respond to then.
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,184 @@
+package faultproxy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/client"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+)
+
+type testBlock struct {
+	Number hexutil.Uint64 `json:"number"`
+}
+
+type testReceipt struct {
+	TxHash common.Hash `json:"transactionHash"`
+}
+
+type ethBackend struct {
+	head atomic.Uint64
+}
+
+func (b *ethBackend) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(b.head.Load())
+}
+
+func (b *ethBackend) GetBlockByNumber(label string, fullTxs bool) *testBlock {
+	if label == "latest" {
+		return &testBlock{Number: hexutil.Uint64(b.head.Load())}
+	}
+	var num hexutil.Uint64
+	if err := num.UnmarshalText([]byte(label)); err != nil || uint64(num) > b.head.Load() {
+		return nil
+	}
+	return &testBlock{Number: num}
+}
+
+func (b *ethBackend) GetTransactionReceipt(txHash common.Hash) *testReceipt {
+	return &testReceipt{TxHash: txHash}
+}
+
+func newTestProxy(t *testing.T) (*Proxy, *ethBackend, *rpc.Client) {
+	backend := new(ethBackend)
+	backend.head.Store(5)
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("eth", backend))
+	t.Cleanup(srv.Stop)
+
+	proxy := NewProxy(testlog.Logger(t, log.LevelInfo), client.NewBaseRPCClient(rpc.DialInProc(srv)))
+	require.NoError(t, proxy.Start("127.0.0.1:0"))
+	t.Cleanup(func() {
+		require.NoError(t, proxy.Close())
+	})
+	cl, err := rpc.Dial(proxy.Endpoint())
+	require.NoError(t, err)
+	t.Cleanup(cl.Close)
+	return proxy, backend, cl
+}
+
+func latest(t *testing.T, cl *rpc.Client) uint64 {
+	var block testBlock
+	require.NoError(t, cl.CallContext(context.Background(), &block, "eth_getBlockByNumber", "latest", false))
+	return uint64(block.Number)
+}
+
+func TestProxy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Forward", func(t *testing.T) {
+		_, _, cl := newTestProxy(t)
+		require.Equal(t, uint64(5), latest(t, cl))
+
+		var block *testBlock
+		require.NoError(t, cl.CallContext(ctx, &block, "eth_getBlockByNumber", "0x9", false))
+		require.Nil(t, block)
+
+		batch := []rpc.BatchElem{
+			{Method: "eth_getTransactionReceipt", Args: []any{common.Hash{0x01}}, Result: new(testReceipt)},
+			{Method: "eth_getTransactionReceipt", Args: []any{common.Hash{0x02}}, Result: new(testReceipt)},
+			{Method: "eth_unknown", Result: new(json.RawMessage)},
+		}
+		require.NoError(t, cl.BatchCallContext(ctx, batch))
+		require.NoError(t, batch[0].Error)
+		require.Equal(t, common.Hash{0x01}, batch[0].Result.(*testReceipt).TxHash)
+		require.Equal(t, common.Hash{0x02}, batch[1].Result.(*testReceipt).TxHash)
+		var rpcErr rpc.Error
+		require.ErrorAs(t, batch[2].Error, &rpcErr)
+		require.Equal(t, -32601, rpcErr.ErrorCode(), "method not found")
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		proxy, _, cl := newTestProxy(t)
+		proxy.AddFaults(Fault{Kind: FaultLatency, Match: "eth_getBlockByNumber", Latency: 200 * time.Millisecond, Times: 1})
+		start := time.Now()
+		latest(t, cl)
+		require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		proxy, _, cl := newTestProxy(t)
+		proxy.AddFaults(Fault{Kind: FaultDrop, Match: "eth_blockNumber", Times: 1})
+		var num hexutil.Uint64
+		require.Error(t, cl.CallContext(ctx, &num, "eth_blockNumber"))
+		require.Equal(t, uint64(5), latest(t, cl), "other methods are not affected")
+		require.NoError(t, cl.CallContext(ctx, &num, "eth_blockNumber"), "only drops once")
+		require.Equal(t, hexutil.Uint64(5), num)
+	})
+
+	t.Run("StaleHead", func(t *testing.T) {
+		proxy, backend, cl := newTestProxy(t)
+		require.Equal(t, uint64(5), latest(t, cl))
+		backend.head.Store(6)
+		proxy.AddFaults(Fault{Kind: FaultStaleHead, Times: 1})
+		require.Equal(t, uint64(5), latest(t, cl))
+		require.Equal(t, uint64(6), latest(t, cl))
+	})
+
+	t.Run("MissingReceipts", func(t *testing.T) {
+		proxy, _, cl := newTestProxy(t)
+		proxy.AddFaults(Fault{Kind: FaultMissingReceipts, Skip: 1, Times: 1})
+		for i, missing := range []bool{false, true, false} {
+			var rec *testReceipt
+			require.NoError(t, cl.CallContext(ctx, &rec, "eth_getTransactionReceipt", common.Hash{0x01}))
+			require.Equal(t, missing, rec == nil, "call %d", i)
+		}
+	})
+
+	t.Run("Reorg", func(t *testing.T) {
+		proxy, _, _ := newTestProxy(t)
+		require.ErrorContains(t, proxy.Reorg(ctx, 10), "cannot reorg")
+	})
+}
+
+func TestBeaconProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	t.Cleanup(upstream.Close)
+
+	proxy, err := NewBeaconProxy(testlog.Logger(t, log.LevelInfo), upstream.URL)
+	require.NoError(t, err)
+	require.NoError(t, proxy.Start("127.0.0.1:0"))
+	t.Cleanup(func() {
+		require.NoError(t, proxy.Close())
+	})
+	get := func(path string) (int, string) {
+		resp, err := http.Get(proxy.Endpoint() + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	proxy.AddFaults(Fault{Kind: FaultBlobsNotFound, Times: 1})
+	status, body := get("/eth/v1/beacon/genesis")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "/eth/v1/beacon/genesis", body)
+	status, _ = get("/eth/v1/beacon/blob_sidecars/10")
+	require.Equal(t, http.StatusNotFound, status)
+	status, body = get("/eth/v1/beacon/blob_sidecars/10")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "/eth/v1/beacon/blob_sidecars/10", body)
+
+	proxy.AddFaults(Fault{Kind: FaultDrop, Match: "/eth/v1/config/"})
+	_, err = http.Get(proxy.Endpoint() + "/eth/v1/config/spec")
+	require.Error(t, err)
+	proxy.ClearFaults()
+	status, _ = get("/eth/v1/config/spec")
+	require.Equal(t, http.StatusOK, status)
+}
@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const rpcInstrumentation = "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+
+// methodSniffLimit is the number of bytes of a request body that are read to find the JSON-RPC method.
+// The rest of the body is streamed to the handler, which enforces the body size limit of the server.
+const methodSniffLimit = 4 * 1024
+
+// NewHTTPMiddleware starts a server span for every JSON-RPC request,
+// continuing the trace of the caller if the request carries a W3C traceparent header.
+// The span is attached to the request context, which geth passes on to the RPC handlers.
+func NewHTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := otel.Tracer(rpcInstrumentation).Start(ctx, "rpc",
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("rpc.system", "jsonrpc")))
+		defer span.End()
+
+		// Only pay for reading the method of the request if the span is recorded.
+		if span.IsRecording() && r.Body != nil {
+			prefix, err := io.ReadAll(io.LimitReader(r.Body, methodSniffLimit))
+			if err != nil {
+				span.SetStatus(codes.Error, "failed to read request body")
+				http.Error(w, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(prefix), r.Body), Closer: r.Body}
+			if method := rpcMethod(prefix); method != "" {
+				span.SetName(method)
+				span.SetAttributes(attribute.String("rpc.method", method))
+			}
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		span.SetAttributes(attribute.Int("http.response.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	})
+}
+
+// prefixedBody is a request body whose sniffed prefix is read again before the rest of the body.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// rpcMethod returns the method of a JSON-RPC request, "batch" for a batch request,
+// or an empty string if the method is not found in the (possibly truncated) body.
+func rpcMethod(body []byte) string {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	if len(body) > 0 && body[0] == '[' {
+		return "batch"
+	}
+	// Walk the keys of the request object, so that a method before a truncated value is still found.
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return ""
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		if key == "method" {
+			var method string
+			if err := dec.Decode(&method); err != nil {
+				return ""
+			}
+			return method
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return ""
+		}
+	}
+	return ""
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush keeps streaming responses working through the recorder.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
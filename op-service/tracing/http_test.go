@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useTestProvider installs a global tracer provider that records all spans.
+func useTestProvider(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	recorder := useTestProvider(t)
+
+	var handlerSpan trace.SpanContext
+	var handlerBody string
+	srv := httptest.NewServer(NewHTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		body, _ := io.ReadAll(r.Body)
+		handlerBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	})))
+	defer srv.Close()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	post := func(body string, traceparent string) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		if traceparent != "" {
+			req.Header.Set("traceparent", traceparent)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	t.Run("ContinuesTrace", func(t *testing.T) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"admin_postUnsafePayload","params":[]}`
+		post(body, "00-"+traceID+"-00f067aa0ba902b7-01")
+		require.Equal(t, body, handlerBody, "handler must still read the full body")
+		require.Equal(t, traceID, handlerSpan.TraceID().String())
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		span := spans[0]
+		require.Equal(t, "admin_postUnsafePayload", span.Name())
+		require.Equal(t, trace.SpanKindServer, span.SpanKind())
+		require.Equal(t, traceID, span.Parent().TraceID().String())
+		require.True(t, span.Parent().IsRemote())
+		require.Equal(t, handlerSpan.SpanID(), span.SpanContext().SpanID())
+	})
+
+	t.Run("NewTrace", func(t *testing.T) {
+		post(`[{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}]`, "")
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		span := spans[1]
+		require.Equal(t, "batch", span.Name())
+		require.False(t, span.Parent().IsValid())
+		require.NotEqual(t, traceID, span.SpanContext().TraceID().String())
+	})
+
+	t.Run("LargeBody", func(t *testing.T) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x` +
+			strings.Repeat("ab", methodSniffLimit) + `"]}`
+		post(body, "")
+		require.Equal(t, body, handlerBody, "handler must still read the full body")
+		spans := recorder.Ended()
+		require.Len(t, spans, 3)
+		require.Equal(t, "eth_sendRawTransaction", spans[2].Name())
+	})
+}
+
+func TestRPCMethod(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "Request", body: `{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`, want: "eth_chainId"},
+		{name: "MethodAfterParams", body: `{"params":[{"a":1}],"method":"eth_call","id":1}`, want: "eth_call"},
+		{name: "Batch", body: ` [{"method":"eth_chainId"}]`, want: "batch"},
+		{name: "TruncatedAfterMethod", body: `{"method":"eth_sendRawTransaction","params":["0xabab`, want: "eth_sendRawTransaction"},
+		{name: "TruncatedBeforeMethod", body: `{"params":["0xabab`, want: ""},
+		{name: "NotJSON", body: `hello`, want: ""},
+		{name: "Empty", body: ``, want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.want, rpcMethod([]byte(test.body)))
+		})
+	}
+}
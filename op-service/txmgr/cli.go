@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
+	"github.com/tokamak-network/tokamak-thanos/op-service/client"
 	opcrypto "github.com/tokamak-network/tokamak-thanos/op-service/crypto"
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
@@ -330,10 +331,11 @@ func NewConfig(cfg CLIConfig, l log.Logger) (Config, error) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.NetworkTimeout)
 	defer cancel()
-	l1, err := ethclient.DialContext(ctx, cfg.L1RPCURL)
+	rpcClient, err := client.DialGethRPCClient(ctx, l, cfg.L1RPCURL)
 	if err != nil {
 		return Config{}, fmt.Errorf("could not dial eth client: %w", err)
 	}
+	l1 := ethclient.NewClient(rpcClient)
 
 	ctx, cancel = context.WithTimeout(context.Background(), cfg.NetworkTimeout)
 	defer cancel()
@@ -19,6 +19,10 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/holiman/uint256"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
 	"github.com/tokamak-network/tokamak-thanos/op-service/retry"
@@ -26,6 +30,9 @@ import (
 )
 
 const (
+	// tracerName is the instrumentation name of the spans of the tx manager
+	tracerName = "github.com/tokamak-network/tokamak-thanos/op-service/txmgr"
+
 	// geth requires a minimum fee bump of 10% for regular tx resubmission
 	priceBump int64 = 10
 	// geth requires a minimum fee bump of 100% for blob tx resubmission
@@ -214,9 +221,23 @@ func (m *SimpleTxManager) Send(ctx context.Context, candidate TxCandidate) (*typ
 	defer func() {
 		m.metr.RecordPendingTx(m.pending.Add(-1))
 	}()
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "txmgr.send", trace.WithAttributes(
+		attribute.String("tx.from", m.cfg.From.Hex()),
+		attribute.Int("tx.calldata_size", len(candidate.TxData)),
+		attribute.Int("tx.blobs", len(candidate.Blobs)),
+	))
+	defer span.End()
 	receipt, err := m.send(ctx, candidate)
 	if err != nil {
 		m.resetNonce()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(
+			attribute.String("tx.hash", receipt.TxHash.Hex()),
+			attribute.Int64("tx.block_number", receipt.BlockNumber.Int64()),
+			attribute.Int64("tx.status", int64(receipt.Status)),
+		)
 	}
 	return receipt, err
 }
@@ -600,6 +621,16 @@ func (m *SimpleTxManager) publishTx(ctx context.Context, tx *types.Transaction,
 		err := m.backend.SendTransaction(cCtx, tx)
 		cancel()
 		sendState.ProcessSendError(err)
+		publishAttrs := []attribute.KeyValue{
+			attribute.String("tx.hash", tx.Hash().Hex()),
+			attribute.Int64("tx.nonce", int64(tx.Nonce())),
+			attribute.String("tx.gas_tip_cap", tx.GasTipCap().String()),
+			attribute.String("tx.gas_fee_cap", tx.GasFeeCap().String()),
+		}
+		if err != nil {
+			publishAttrs = append(publishAttrs, attribute.String("error", err.Error()))
+		}
+		trace.SpanFromContext(ctx).AddEvent("publish", trace.WithAttributes(publishAttrs...))
 
 		if err == nil {
 			m.metr.TxPublished("")
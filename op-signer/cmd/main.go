@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
+	"github.com/tokamak-network/tokamak-thanos/op-service/cliapp"
+	"github.com/tokamak-network/tokamak-thanos/op-service/ctxinterrupt"
+	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/config"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/flags"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/service"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/version"
+)
+
+var (
+	GitCommit = ""
+	GitDate   = ""
+)
+
+// VersionWithMeta holds the textual version string including the metadata.
+var VersionWithMeta = opservice.FormatVersion(version.Version, GitCommit, GitDate, version.Meta)
+
+func main() {
+	args := os.Args
+	ctx := ctxinterrupt.WithSignalWaiterMain(context.Background())
+	if err := run(ctx, args, service.Main); err != nil {
+		log.Crit("Application failed", "err", err)
+	}
+}
+
+type ConfiguredLifecycle func(ctx context.Context, log log.Logger, config *config.Config) (cliapp.Lifecycle, error)
+
+func run(ctx context.Context, args []string, action ConfiguredLifecycle) error {
+	oplog.SetupDefaults()
+
+	app := cli.NewApp()
+	app.Version = VersionWithMeta
+	app.Flags = cliapp.ProtectFlags(flags.Flags)
+	app.Name = "op-signer"
+	app.Usage = "Remote transaction and block payload signer"
+	app.Description = "Signs transactions and block payloads for mTLS-authenticated clients, within the policies of the clients."
+	app.Action = cliapp.LifecycleCmd(func(ctx *cli.Context, close context.CancelCauseFunc) (cliapp.Lifecycle, error) {
+		logger, err := setupLogging(ctx)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Starting op-signer", "version", VersionWithMeta)
+
+		cfg, err := flags.NewConfigFromCLI(ctx, VersionWithMeta)
+		if err != nil {
+			return nil, err
+		}
+		return action(ctx.Context, logger, cfg)
+	})
+	return app.RunContext(ctx, args)
+}
+
+func setupLogging(ctx *cli.Context) (log.Logger, error) {
+	logCfg := oplog.ReadCLIConfig(ctx)
+	logger := oplog.NewLogger(oplog.AppOut(ctx), logCfg)
+	oplog.SetGlobalLogHandler(logger.Handler())
+	return logger, nil
+}
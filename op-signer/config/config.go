@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
+	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	optls "github.com/tokamak-network/tokamak-thanos/op-service/tls"
+)
+
+var (
+	ErrMissingConfigFile = errors.New("missing signer config file")
+	ErrMissingTLS        = errors.New("tls must be enabled, clients are authorized by their certificate")
+)
+
+// Config is a well typed config that is parsed from the CLI params.
+// It also contains config options for auxiliary services.
+type Config struct {
+	ConfigFile string // Path of the signer config file, with the keys and the client policies
+	AuditLog   string // Path of the audit log file. Audit records are logged if empty.
+
+	Version string
+
+	TLSConfig     optls.CLIConfig
+	MetricsConfig opmetrics.CLIConfig
+	PprofConfig   oppprof.CLIConfig
+	RPC           oprpc.CLIConfig
+}
+
+func NewConfig(configFile string) Config {
+	return Config{
+		ConfigFile: configFile,
+
+		TLSConfig:     optls.NewCLIConfig(),
+		MetricsConfig: opmetrics.DefaultCLIConfig(),
+		PprofConfig:   oppprof.DefaultCLIConfig(),
+		RPC:           oprpc.DefaultCLIConfig(),
+	}
+}
+
+func (c Config) Check() error {
+	if c.ConfigFile == "" {
+		return ErrMissingConfigFile
+	}
+	if !c.TLSConfig.TLSEnabled() {
+		return ErrMissingTLS
+	}
+	if err := c.TLSConfig.Check(); err != nil {
+		return fmt.Errorf("tls config: %w", err)
+	}
+	if err := c.MetricsConfig.Check(); err != nil {
+		return fmt.Errorf("metrics config: %w", err)
+	}
+	if err := c.PprofConfig.Check(); err != nil {
+		return fmt.Errorf("pprof config: %w", err)
+	}
+	if err := c.RPC.Check(); err != nil {
+		return fmt.Errorf("rpc config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var validConfigFile = "/etc/op-signer/config.yaml"
+
+func validConfig() Config {
+	return NewConfig(validConfigFile)
+}
+
+func TestValidConfigIsValid(t *testing.T) {
+	require.NoError(t, validConfig().Check())
+}
+
+func TestConfigFileRequired(t *testing.T) {
+	config := validConfig()
+	config.ConfigFile = ""
+	require.ErrorIs(t, config.Check(), ErrMissingConfigFile)
+}
+
+func TestTLSRequired(t *testing.T) {
+	config := validConfig()
+	config.TLSConfig.TLSCaCert = ""
+	config.TLSConfig.TLSCert = ""
+	config.TLSConfig.TLSKey = ""
+	require.ErrorIs(t, config.Check(), ErrMissingTLS)
+}
+
+func TestTLSConfigMustBeComplete(t *testing.T) {
+	config := validConfig()
+	config.TLSConfig.TLSKey = ""
+	require.ErrorContains(t, config.Check(), "all tls flags must be set")
+}
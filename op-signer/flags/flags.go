@@ -0,0 +1,88 @@
+package flags
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
+	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
+	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	optls "github.com/tokamak-network/tokamak-thanos/op-service/tls"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/config"
+)
+
+const (
+	envVarPrefix = "OP_SIGNER"
+)
+
+func prefixEnvVars(name string) []string {
+	return opservice.PrefixEnvVar(envVarPrefix, name)
+}
+
+var (
+	// Required Flags
+	ConfigFileFlag = &cli.PathFlag{
+		Name:    "config",
+		Usage:   "Path of the signer config file, with the signing keys and the policies of the clients.",
+		EnvVars: prefixEnvVars("CONFIG"),
+	}
+	// Optional Flags
+	AuditLogFlag = &cli.PathFlag{
+		Name:    "audit-log",
+		Usage:   "Path of the file to append the audit records of the signing requests to, as JSON lines. Audit records are logged if not set.",
+		EnvVars: prefixEnvVars("AUDIT_LOG"),
+	}
+)
+
+// requiredFlags are checked by [CheckRequired]
+var requiredFlags = []cli.Flag{
+	ConfigFileFlag,
+}
+
+// optionalFlags is a list of unchecked cli flags
+var optionalFlags = []cli.Flag{
+	AuditLogFlag,
+}
+
+func init() {
+	optionalFlags = append(optionalFlags, oprpc.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, optls.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, oplog.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, opmetrics.CLIFlags(envVarPrefix)...)
+	optionalFlags = append(optionalFlags, oppprof.CLIFlags(envVarPrefix)...)
+
+	Flags = append(requiredFlags, optionalFlags...)
+}
+
+// Flags contains the list of configuration options available to the binary.
+var Flags []cli.Flag
+
+func CheckRequired(ctx *cli.Context) error {
+	for _, f := range requiredFlags {
+		if !ctx.IsSet(f.Names()[0]) {
+			return fmt.Errorf("flag %s is required", f.Names()[0])
+		}
+	}
+	return nil
+}
+
+// NewConfigFromCLI parses the Config from the provided flags or environment variables.
+func NewConfigFromCLI(ctx *cli.Context, version string) (*config.Config, error) {
+	if err := CheckRequired(ctx); err != nil {
+		return nil, err
+	}
+	return &config.Config{
+		ConfigFile: ctx.Path(ConfigFileFlag.Name),
+		AuditLog:   ctx.Path(AuditLogFlag.Name),
+
+		Version: version,
+
+		TLSConfig:     optls.ReadCLIConfig(ctx),
+		MetricsConfig: opmetrics.ReadCLIConfig(ctx),
+		PprofConfig:   oppprof.ReadCLIConfig(ctx),
+		RPC:           oprpc.ReadCLIConfig(ctx),
+	}, nil
+}
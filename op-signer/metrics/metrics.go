@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+)
+
+const Namespace = "op_signer"
+
+const (
+	OutcomeSigned = "signed"
+	OutcomeDenied = "denied"
+	OutcomeFailed = "failed"
+)
+
+type Metricer interface {
+	RecordInfo(version string)
+	RecordUp()
+
+	RecordRequest(client string, method string, outcome string)
+
+	Document() []opmetrics.DocumentedMetric
+}
+
+type Metrics struct {
+	ns       string
+	registry *prometheus.Registry
+	factory  opmetrics.Factory
+
+	requests *prometheus.CounterVec
+
+	info prometheus.GaugeVec
+	up   prometheus.Gauge
+}
+
+var _ Metricer = (*Metrics)(nil)
+
+// implements the Registry getter, for metrics HTTP server to hook into
+var _ opmetrics.RegistryMetricer = (*Metrics)(nil)
+
+func NewMetrics() *Metrics {
+	registry := opmetrics.NewRegistry()
+	factory := opmetrics.With(registry)
+	ns := Namespace
+
+	return &Metrics{
+		ns:       ns,
+		registry: registry,
+		factory:  factory,
+
+		requests: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns,
+			Name:      "requests_total",
+			Help:      "Number of signing requests, by client, method and outcome",
+		}, []string{
+			"client",
+			"method",
+			"outcome",
+		}),
+
+		info: *factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "info",
+			Help:      "Pseudo-metric tracking version and config info",
+		}, []string{
+			"version",
+		}),
+		up: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns,
+			Name:      "up",
+			Help:      "1 if the op-signer has finished starting up",
+		}),
+	}
+}
+
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) Document() []opmetrics.DocumentedMetric {
+	return m.factory.Document()
+}
+
+// RecordInfo sets a pseudo-metric that contains versioning and config info for the op-signer.
+func (m *Metrics) RecordInfo(version string) {
+	m.info.WithLabelValues(version).Set(1)
+}
+
+// RecordUp sets the up metric to 1.
+func (m *Metrics) RecordUp() {
+	m.up.Set(1)
+}
+
+func (m *Metrics) RecordRequest(client string, method string, outcome string) {
+	m.requests.WithLabelValues(client, method, outcome).Inc()
+}
@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+)
+
+type noopMetrics struct{}
+
+var NoopMetrics Metricer = new(noopMetrics)
+
+func (*noopMetrics) Document() []opmetrics.DocumentedMetric { return nil }
+
+func (*noopMetrics) RecordInfo(version string) {}
+func (*noopMetrics) RecordUp()                 {}
+
+func (*noopMetrics) RecordRequest(_ string, _ string, _ string) {}
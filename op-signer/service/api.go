@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/signer"
+	optls "github.com/tokamak-network/tokamak-thanos/op-service/tls"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/metrics"
+)
+
+const (
+	methodSignTransaction  = "eth_signTransaction"
+	methodSignBlockPayload = "opsigner_signBlockPayload"
+
+	methodSignPreconfirmation = "opsigner_signPreconfirmation"
+)
+
+// ErrUnauthorized is returned when the client certificate does not match any client policy.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Signer signs transactions and block payloads for the clients, within the policies of the clients.
+type Signer struct {
+	log     log.Logger
+	metrics metrics.Metricer
+	config  *SignerConfig
+	keys    map[string]*Key
+	audit   AuditLog
+}
+
+// NewSigner loads the keys of the signer config.
+func NewSigner(logger log.Logger, m metrics.Metricer, config *SignerConfig, audit AuditLog) (*Signer, error) {
+	keys := make(map[string]*Key, len(config.Keys))
+	for name, keyCfg := range config.Keys {
+		key, err := LoadKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key %q: %w", name, err)
+		}
+		logger.Info("Loaded key", "name", name, "address", key.Address())
+		keys[name] = key
+	}
+	return &Signer{
+		log:     logger,
+		metrics: m,
+		config:  config,
+		keys:    keys,
+		audit:   audit,
+	}, nil
+}
+
+// client returns the policy of the client of the request, by its TLS client certificate.
+func (s *Signer) client(ctx context.Context) (*ClientPolicy, *Key, error) {
+	cert := optls.PeerTLSInfoFromContext(ctx).LeafCertificate
+	policy, ok := s.config.Client(cert)
+	if !ok {
+		return nil, nil, ErrUnauthorized
+	}
+	return policy, s.keys[policy.Key], nil
+}
+
+// record audits the request, and records its outcome in the metrics.
+func (s *Signer) record(rec *AuditRecord, err error) {
+	rec.Time = time.Now()
+	rec.Allowed = err == nil
+	outcome := metrics.OutcomeSigned
+	if err != nil {
+		rec.Reason = err.Error()
+		outcome = metrics.OutcomeFailed
+		if errors.Is(err, ErrForbidden) || errors.Is(err, ErrUnauthorized) {
+			outcome = metrics.OutcomeDenied
+		}
+	}
+	s.audit.Record(rec)
+	s.metrics.RecordRequest(rec.Client, rec.Method, outcome)
+}
+
+// authorize looks up the client of the request, and adds it to the audit record.
+func (s *Signer) authorize(ctx context.Context, rec *AuditRecord) (*ClientPolicy, *Key, error) {
+	policy, key, err := s.client(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	rec.Client = policy.Name
+	rec.Signer = key.Address()
+	return policy, key, nil
+}
+
+func (s *Signer) SignTransaction(ctx context.Context, args *signer.TransactionArgs) (hexutil.Bytes, error) {
+	rec := &AuditRecord{
+		Client: "unknown",
+		Method: methodSignTransaction,
+		To:     args.To,
+		Value:  args.Value,
+		Nonce:  args.Nonce,
+		MaxFee: args.MaxFeePerGas,
+	}
+	if args.ChainID != nil {
+		rec.ChainID = args.ChainID.ToInt().String()
+	}
+	result, err := s.signTransaction(ctx, args, rec)
+	s.record(rec, err)
+	return result, err
+}
+
+func (s *Signer) signTransaction(ctx context.Context, args *signer.TransactionArgs, rec *AuditRecord) (hexutil.Bytes, error) {
+	policy, key, err := s.authorize(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Check(); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	if args.From != nil && *args.From != key.Address() {
+		return nil, fmt.Errorf("%w: from address %s is not the signer %s", ErrForbidden, args.From, key.Address())
+	}
+	if err := policy.CheckTransaction(args); err != nil {
+		return nil, err
+	}
+	data, err := args.ToTransactionData()
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	tx, err := key.SignTransaction(args.ChainID.ToInt(), data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	hash := tx.Hash()
+	rec.TxHash = &hash
+	return tx.MarshalBinary()
+}
+
+func (s *Signer) SignBlockPayload(ctx context.Context, args *signer.BlockPayloadArgs) (hexutil.Bytes, error) {
+	rec := &AuditRecord{
+		Client:      "unknown",
+		Method:      methodSignBlockPayload,
+		ChainID:     args.ChainID.String(),
+		PayloadHash: &args.PayloadHash,
+	}
+	result, err := s.signBlockPayload(ctx, args, rec)
+	s.record(rec, err)
+	return result, err
+}
+
+func (s *Signer) signBlockPayload(ctx context.Context, args *signer.BlockPayloadArgs, rec *AuditRecord) (hexutil.Bytes, error) {
+	policy, key, err := s.authorize(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Check(); err != nil {
+		return nil, fmt.Errorf("invalid block payload: %w", err)
+	}
+	if args.SenderAddress != nil && *args.SenderAddress != key.Address() {
+		return nil, fmt.Errorf("%w: sender address %s is not the signer %s", ErrForbidden, args.SenderAddress, key.Address())
+	}
+	if err := policy.CheckBlockPayload(args); err != nil {
+		return nil, err
+	}
+	sig, err := key.SignHash(args.Message())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign block payload: %w", err)
+	}
+	return sig, nil
+}
+
+func (s *Signer) SignPreconfirmation(ctx context.Context, args *signer.PreconfirmationArgs) (hexutil.Bytes, error) {
+	rec := &AuditRecord{
+		Client:      "unknown",
+		Method:      methodSignPreconfirmation,
+		ChainID:     args.ChainID.String(),
+		PayloadHash: &args.PreconfHash,
+	}
+	result, err := s.signPreconfirmation(ctx, args, rec)
+	s.record(rec, err)
+	return result, err
+}
+
+func (s *Signer) signPreconfirmation(ctx context.Context, args *signer.PreconfirmationArgs, rec *AuditRecord) (hexutil.Bytes, error) {
+	policy, key, err := s.authorize(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	if err := args.Check(); err != nil {
+		return nil, fmt.Errorf("invalid preconfirmation: %w", err)
+	}
+	if args.SenderAddress != nil && *args.SenderAddress != key.Address() {
+		return nil, fmt.Errorf("%w: sender address %s is not the signer %s", ErrForbidden, args.SenderAddress, key.Address())
+	}
+	if err := policy.CheckPreconfirmation(args); err != nil {
+		return nil, err
+	}
+	sig, err := key.SignHash(args.Message())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign preconfirmation: %w", err)
+	}
+	return sig, nil
+}
+
+// EthAPI serves the eth_signTransaction method of the signer.
+type EthAPI struct {
+	signer *Signer
+}
+
+func NewEthAPI(s *Signer) *EthAPI {
+	return &EthAPI{signer: s}
+}
+
+func (api *EthAPI) SignTransaction(ctx context.Context, args signer.TransactionArgs) (hexutil.Bytes, error) {
+	return api.signer.SignTransaction(ctx, &args)
+}
+
+// OpSignerAPI serves the opsigner_signBlockPayload and opsigner_signPreconfirmation methods of the signer.
+type OpSignerAPI struct {
+	signer *Signer
+}
+
+func NewOpSignerAPI(s *Signer) *OpSignerAPI {
+	return &OpSignerAPI{signer: s}
+}
+
+func (api *OpSignerAPI) SignBlockPayload(ctx context.Context, args signer.BlockPayloadArgs) (hexutil.Bytes, error) {
+	return api.signer.SignBlockPayload(ctx, &args)
+}
+
+func (api *OpSignerAPI) SignPreconfirmation(ctx context.Context, args signer.PreconfirmationArgs) (hexutil.Bytes, error) {
+	return api.signer.SignPreconfirmation(ctx, &args)
+}
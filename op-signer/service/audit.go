@@ -0,0 +1,87 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// AuditRecord is the record of a signing request, whether it was allowed or not.
+type AuditRecord struct {
+	Time   time.Time      `json:"time"`
+	Client string         `json:"client"`
+	Method string         `json:"method"`
+	Signer common.Address `json:"signer"`
+
+	ChainID     string          `json:"chainId,omitempty"`
+	To          *common.Address `json:"to,omitempty"`
+	Value       *hexutil.Big    `json:"value,omitempty"`
+	Nonce       *hexutil.Uint64 `json:"nonce,omitempty"`
+	MaxFee      *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	TxHash      *common.Hash    `json:"txHash,omitempty"`
+	PayloadHash *common.Hash    `json:"payloadHash,omitempty"`
+
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// AuditLog records the signing requests.
+type AuditLog interface {
+	Record(rec *AuditRecord)
+	Close() error
+}
+
+// FileAuditLog appends the audit records to a file, as JSON lines.
+type FileAuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+	log  log.Logger
+}
+
+func NewFileAuditLog(logger log.Logger, path string) (*FileAuditLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &FileAuditLog{file: file, enc: json.NewEncoder(file), log: logger}, nil
+}
+
+func (a *FileAuditLog) Record(rec *AuditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.enc.Encode(rec); err != nil {
+		a.log.Error("Failed to write audit record", "client", rec.Client, "method", rec.Method, "err", err)
+	}
+}
+
+func (a *FileAuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
+
+// LoggerAuditLog logs the audit records.
+type LoggerAuditLog struct {
+	log log.Logger
+}
+
+func NewLoggerAuditLog(logger log.Logger) *LoggerAuditLog {
+	return &LoggerAuditLog{log: logger}
+}
+
+func (a *LoggerAuditLog) Record(rec *AuditRecord) {
+	a.log.Info("Audit", "client", rec.Client, "method", rec.Method, "signer", rec.Signer, "chain_id", rec.ChainID,
+		"to", rec.To, "value", rec.Value, "nonce", rec.Nonce, "max_fee", rec.MaxFee,
+		"tx", rec.TxHash, "payload", rec.PayloadHash, "allowed", rec.Allowed, "reason", rec.Reason)
+}
+
+func (a *LoggerAuditLog) Close() error {
+	return nil
+}
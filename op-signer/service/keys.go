@@ -0,0 +1,102 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// KeyConfig configures a signing key, either as an encrypted keystore file with a password file,
+// or as a file with the hex-encoded private key.
+type KeyConfig struct {
+	Keystore       string `yaml:"keystore"`
+	PasswordFile   string `yaml:"passwordFile"`
+	PrivateKeyFile string `yaml:"privateKeyFile"`
+}
+
+func (c KeyConfig) Check() error {
+	switch {
+	case c.Keystore != "" && c.PrivateKeyFile != "":
+		return errors.New("both keystore and private key file are set")
+	case c.Keystore != "" && c.PasswordFile == "":
+		return errors.New("keystore requires a password file")
+	case c.Keystore == "" && c.PrivateKeyFile == "":
+		return errors.New("either keystore or private key file must be set")
+	}
+	return nil
+}
+
+func (c KeyConfig) relativeTo(dir string) KeyConfig {
+	abs := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+	return KeyConfig{
+		Keystore:       abs(c.Keystore),
+		PasswordFile:   abs(c.PasswordFile),
+		PrivateKeyFile: abs(c.PrivateKeyFile),
+	}
+}
+
+// Key is a signing key of the signer.
+type Key struct {
+	priv *ecdsa.PrivateKey
+	addr common.Address
+}
+
+func NewKey(priv *ecdsa.PrivateKey) *Key {
+	return &Key{priv: priv, addr: crypto.PubkeyToAddress(priv.PublicKey)}
+}
+
+// LoadKey loads the key of the config.
+func LoadKey(cfg KeyConfig) (*Key, error) {
+	if cfg.PrivateKeyFile != "" {
+		data, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file: %w", err)
+		}
+		priv, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		return NewKey(priv), nil
+	}
+	data, err := os.ReadFile(cfg.Keystore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	password, err := os.ReadFile(cfg.PasswordFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password file: %w", err)
+	}
+	key, err := keystore.DecryptKey(data, strings.TrimRight(string(password), "\r\n"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return NewKey(key.PrivateKey), nil
+}
+
+func (k *Key) Address() common.Address {
+	return k.addr
+}
+
+// SignTransaction signs the transaction data for the chain.
+func (k *Key) SignTransaction(chainID *big.Int, data types.TxData) (*types.Transaction, error) {
+	return types.SignNewTx(k.priv, types.LatestSignerForChainID(chainID), data)
+}
+
+// SignHash signs the hash, and returns the signature in the [R || S || V] format, with V 0 or 1.
+func (k *Key) SignHash(hash common.Hash) ([]byte, error) {
+	return crypto.Sign(hash[:], k.priv)
+}
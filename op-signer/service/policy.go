@@ -0,0 +1,198 @@
+package service
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/signer"
+)
+
+// ErrForbidden is returned when a signing request is denied by the policy of the client.
+var ErrForbidden = errors.New("forbidden")
+
+// SignerConfig is the config file of the signer, with the signing keys and the policies of the clients.
+type SignerConfig struct {
+	Keys    map[string]KeyConfig `yaml:"keys"`
+	Clients []ClientPolicy       `yaml:"clients"`
+}
+
+// ClientPolicy is the policy of a client, that is authenticated by its TLS client certificate.
+type ClientPolicy struct {
+	// Name is matched against the DNS names and the common name of the client certificate.
+	Name string `yaml:"name"`
+	// Key is the name of the key the client signs with.
+	Key string `yaml:"key"`
+	// ChainIDs are the chain IDs the client may sign transactions and block payloads for.
+	ChainIDs []eth.ChainID `yaml:"chainIds"`
+	// ToAddresses are the addresses the client may send transactions to. Any address if empty.
+	// Contract creations are only allowed if empty.
+	ToAddresses []common.Address `yaml:"toAddresses"`
+	// MaxValue is the maximum value of a transaction in wei, as a decimal or 0x-prefixed hex number.
+	// No maximum if empty.
+	MaxValue string `yaml:"maxValue"`
+	// MaxGasPrice is the maximum fee per gas of a transaction in wei, as a decimal or 0x-prefixed hex number.
+	// No maximum if empty.
+	MaxGasPrice string `yaml:"maxGasPrice"`
+	// MaxBlobFee is the maximum fee per blob gas of a blob transaction in wei, as a decimal or 0x-prefixed hex number.
+	// No maximum if empty.
+	MaxBlobFee string `yaml:"maxBlobFee"`
+	// MaxGas is the maximum gas limit of a transaction. No maximum if zero.
+	MaxGas uint64 `yaml:"maxGas"`
+	// BlockPayloads allows the client to sign block payloads for p2p gossip.
+	BlockPayloads bool `yaml:"blockPayloads"`
+	// Preconfirmations allows the client to sign preconfirmations.
+	Preconfirmations bool `yaml:"preconfirmations"`
+
+	maxValue    *big.Int
+	maxGasPrice *big.Int
+	maxBlobFee  *big.Int
+}
+
+// LoadSignerConfig loads the signer config file. Key paths are relative to the directory of the file.
+func LoadSignerConfig(path string) (*SignerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signer config: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var cfg SignerConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode signer config: %w", err)
+	}
+	dir := filepath.Dir(path)
+	for name, key := range cfg.Keys {
+		cfg.Keys[name] = key.relativeTo(dir)
+	}
+	if err := cfg.Check(); err != nil {
+		return nil, fmt.Errorf("invalid signer config: %w", err)
+	}
+	return &cfg, nil
+}
+
+func (c *SignerConfig) Check() error {
+	if len(c.Clients) == 0 {
+		return errors.New("no clients")
+	}
+	names := make(map[string]struct{})
+	for i := range c.Clients {
+		p := &c.Clients[i]
+		if p.Name == "" {
+			return fmt.Errorf("client %d has no name", i)
+		}
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("duplicate client %q", p.Name)
+		}
+		names[p.Name] = struct{}{}
+		if _, ok := c.Keys[p.Key]; !ok {
+			return fmt.Errorf("client %q: unknown key %q", p.Name, p.Key)
+		}
+		if len(p.ChainIDs) == 0 {
+			return fmt.Errorf("client %q: no chain IDs", p.Name)
+		}
+		var err error
+		if p.maxValue, err = parseWei(p.MaxValue); err != nil {
+			return fmt.Errorf("client %q: invalid max value: %w", p.Name, err)
+		}
+		if p.maxGasPrice, err = parseWei(p.MaxGasPrice); err != nil {
+			return fmt.Errorf("client %q: invalid max gas price: %w", p.Name, err)
+		}
+		if p.maxBlobFee, err = parseWei(p.MaxBlobFee); err != nil {
+			return fmt.Errorf("client %q: invalid max blob fee: %w", p.Name, err)
+		}
+	}
+	for name, key := range c.Keys {
+		if err := key.Check(); err != nil {
+			return fmt.Errorf("key %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func parseWei(v string) (*big.Int, error) {
+	if v == "" {
+		return nil, nil
+	}
+	out, ok := new(big.Int).SetString(v, 0)
+	if !ok || out.Sign() < 0 {
+		return nil, fmt.Errorf("not a non-negative number: %q", v)
+	}
+	return out, nil
+}
+
+// Client returns the policy of the client that matches the certificate, if any.
+func (c *SignerConfig) Client(cert *x509.Certificate) (*ClientPolicy, bool) {
+	if cert == nil {
+		return nil, false
+	}
+	for i := range c.Clients {
+		p := &c.Clients[i]
+		if slices.Contains(cert.DNSNames, p.Name) || cert.Subject.CommonName == p.Name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+func (p *ClientPolicy) checkChainID(chainID eth.ChainID) error {
+	if !slices.Contains(p.ChainIDs, chainID) {
+		return fmt.Errorf("%w: chain ID %s not allowed", ErrForbidden, chainID)
+	}
+	return nil
+}
+
+// CheckTransaction checks that the policy allows signing the transaction.
+// The arguments must have been checked with TransactionArgs.Check.
+func (p *ClientPolicy) CheckTransaction(args *signer.TransactionArgs) error {
+	if err := p.checkChainID(eth.ChainIDFromBig(args.ChainID.ToInt())); err != nil {
+		return err
+	}
+	if len(p.ToAddresses) > 0 {
+		if args.To == nil {
+			return fmt.Errorf("%w: contract creation not allowed", ErrForbidden)
+		}
+		if !slices.Contains(p.ToAddresses, *args.To) {
+			return fmt.Errorf("%w: to address %s not allowed", ErrForbidden, args.To)
+		}
+	}
+	if p.maxValue != nil && args.Value.ToInt().Cmp(p.maxValue) > 0 {
+		return fmt.Errorf("%w: value %s exceeds maximum %s", ErrForbidden, args.Value.ToInt(), p.maxValue)
+	}
+	if p.maxGasPrice != nil && args.MaxFeePerGas.ToInt().Cmp(p.maxGasPrice) > 0 {
+		return fmt.Errorf("%w: max fee per gas %s exceeds maximum %s", ErrForbidden, args.MaxFeePerGas.ToInt(), p.maxGasPrice)
+	}
+	if p.maxBlobFee != nil && args.BlobFeeCap != nil && args.BlobFeeCap.ToInt().Cmp(p.maxBlobFee) > 0 {
+		return fmt.Errorf("%w: max fee per blob gas %s exceeds maximum %s", ErrForbidden, args.BlobFeeCap.ToInt(), p.maxBlobFee)
+	}
+	if p.MaxGas != 0 && uint64(*args.Gas) > p.MaxGas {
+		return fmt.Errorf("%w: gas %d exceeds maximum %d", ErrForbidden, uint64(*args.Gas), p.MaxGas)
+	}
+	return nil
+}
+
+// CheckBlockPayload checks that the policy allows signing the block payload.
+func (p *ClientPolicy) CheckBlockPayload(args *signer.BlockPayloadArgs) error {
+	if !p.BlockPayloads {
+		return fmt.Errorf("%w: block payload signing not allowed", ErrForbidden)
+	}
+	return p.checkChainID(args.ChainID)
+}
+
+// CheckPreconfirmation checks that the policy allows signing the preconfirmation.
+func (p *ClientPolicy) CheckPreconfirmation(args *signer.PreconfirmationArgs) error {
+	if !p.Preconfirmations {
+		return fmt.Errorf("%w: preconfirmation signing not allowed", ErrForbidden)
+	}
+	return p.checkChainID(args.ChainID)
+}
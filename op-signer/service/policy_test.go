@@ -0,0 +1,214 @@
+package service
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/signer"
+)
+
+const testSignerConfig = `
+keys:
+  batcher:
+    keystore: keys/batcher.json
+    passwordFile: /secrets/batcher.pass
+  sequencer:
+    privateKeyFile: keys/sequencer.key
+clients:
+  - name: batcher.test
+    key: batcher
+    chainIds: [900, "0x385"]
+    toAddresses: ["0xff00000000000000000000000000000000000901"]
+    maxValue: "0"
+    maxGasPrice: "0x174876e800"
+    maxBlobFee: "1000000000"
+    maxGas: 1000000
+  - name: sequencer.test
+    key: sequencer
+    chainIds: [901]
+    blockPayloads: true
+    preconfirmations: true
+`
+
+func writeSignerConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadSignerConfig(t *testing.T) {
+	path := writeSignerConfig(t, testSignerConfig)
+	cfg, err := LoadSignerConfig(path)
+	require.NoError(t, err)
+
+	dir := filepath.Dir(path)
+	require.Equal(t, KeyConfig{
+		Keystore:     filepath.Join(dir, "keys/batcher.json"),
+		PasswordFile: "/secrets/batcher.pass",
+	}, cfg.Keys["batcher"])
+	require.Equal(t, KeyConfig{PrivateKeyFile: filepath.Join(dir, "keys/sequencer.key")}, cfg.Keys["sequencer"])
+
+	batcher := cfg.Clients[0]
+	require.Equal(t, []eth.ChainID{eth.ChainIDFromUInt64(900), eth.ChainIDFromUInt64(901)}, batcher.ChainIDs)
+	require.Equal(t, []common.Address{common.HexToAddress("0xff00000000000000000000000000000000000901")}, batcher.ToAddresses)
+	require.Equal(t, big.NewInt(0), batcher.maxValue)
+	require.Equal(t, big.NewInt(100_000_000_000), batcher.maxGasPrice)
+	require.Equal(t, big.NewInt(1_000_000_000), batcher.maxBlobFee)
+	require.Equal(t, uint64(1_000_000), batcher.MaxGas)
+	require.Nil(t, cfg.Clients[1].maxValue)
+
+	policy, ok := cfg.Client(&x509.Certificate{DNSNames: []string{"other.test", "sequencer.test"}})
+	require.True(t, ok)
+	require.Equal(t, "sequencer", policy.Key)
+	policy, ok = cfg.Client(&x509.Certificate{Subject: pkix.Name{CommonName: "batcher.test"}})
+	require.True(t, ok)
+	require.Equal(t, "batcher", policy.Key)
+	_, ok = cfg.Client(&x509.Certificate{DNSNames: []string{"other.test"}})
+	require.False(t, ok)
+	_, ok = cfg.Client(nil)
+	require.False(t, ok)
+}
+
+func TestInvalidSignerConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name:     "UnknownField",
+			config:   "clients: []\nfoo: bar\n",
+			expected: "field foo not found",
+		},
+		{
+			name:     "NoClients",
+			config:   "keys: {}\n",
+			expected: "no clients",
+		},
+		{
+			name:     "UnknownKey",
+			config:   "clients:\n  - name: a\n    key: b\n    chainIds: [1]\n",
+			expected: `unknown key "b"`,
+		},
+		{
+			name:     "NoChainIDs",
+			config:   "keys:\n  b:\n    privateKeyFile: key\nclients:\n  - name: a\n    key: b\n",
+			expected: "no chain IDs",
+		},
+		{
+			name:     "DuplicateClient",
+			config:   "keys:\n  b:\n    privateKeyFile: key\nclients:\n  - {name: a, key: b, chainIds: [1]}\n  - {name: a, key: b, chainIds: [1]}\n",
+			expected: `duplicate client "a"`,
+		},
+		{
+			name:     "InvalidMaxValue",
+			config:   "keys:\n  b:\n    privateKeyFile: key\nclients:\n  - {name: a, key: b, chainIds: [1], maxValue: '-1'}\n",
+			expected: "invalid max value",
+		},
+		{
+			name:     "InvalidMaxBlobFee",
+			config:   "keys:\n  b:\n    privateKeyFile: key\nclients:\n  - {name: a, key: b, chainIds: [1], maxBlobFee: 'x'}\n",
+			expected: "invalid max blob fee",
+		},
+		{
+			name:     "KeystoreWithoutPassword",
+			config:   "keys:\n  b:\n    keystore: key.json\nclients:\n  - {name: a, key: b, chainIds: [1]}\n",
+			expected: "keystore requires a password file",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadSignerConfig(writeSignerConfig(t, test.config))
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+func TestClientPolicy(t *testing.T) {
+	cfg, err := LoadSignerConfig(writeSignerConfig(t, testSignerConfig))
+	require.NoError(t, err)
+	batcher, sequencer := &cfg.Clients[0], &cfg.Clients[1]
+
+	inbox := common.HexToAddress("0xff00000000000000000000000000000000000901")
+	txArgs := func(modify func(args *signer.TransactionArgs)) *signer.TransactionArgs {
+		gas := hexutil.Uint64(21_000)
+		args := &signer.TransactionArgs{
+			Gas:          &gas,
+			To:           &inbox,
+			Value:        (*hexutil.Big)(big.NewInt(0)),
+			MaxFeePerGas: (*hexutil.Big)(big.NewInt(1_000_000_000)),
+			ChainID:      (*hexutil.Big)(big.NewInt(900)),
+		}
+		if modify != nil {
+			modify(args)
+		}
+		return args
+	}
+	require.NoError(t, batcher.CheckTransaction(txArgs(nil)))
+	require.NoError(t, batcher.CheckTransaction(txArgs(func(args *signer.TransactionArgs) {
+		args.BlobVersionedHashes = []common.Hash{{0x01}}
+		args.BlobFeeCap = (*hexutil.Big)(big.NewInt(1_000_000_000))
+	})), "blob fee and gas at the maximum are allowed")
+	for name, modify := range map[string]func(args *signer.TransactionArgs){
+		"chain ID 1 not allowed": func(args *signer.TransactionArgs) {
+			args.ChainID = (*hexutil.Big)(big.NewInt(1))
+		},
+		"to address": func(args *signer.TransactionArgs) {
+			args.To = &common.Address{0x01}
+		},
+		"contract creation": func(args *signer.TransactionArgs) {
+			args.To = nil
+		},
+		"value 1 exceeds maximum 0": func(args *signer.TransactionArgs) {
+			args.Value = (*hexutil.Big)(big.NewInt(1))
+		},
+		"max fee per gas": func(args *signer.TransactionArgs) {
+			args.MaxFeePerGas = (*hexutil.Big)(big.NewInt(100_000_000_001))
+		},
+		"max fee per blob gas 1000000001 exceeds maximum 1000000000": func(args *signer.TransactionArgs) {
+			args.BlobVersionedHashes = []common.Hash{{0x01}}
+			args.BlobFeeCap = (*hexutil.Big)(big.NewInt(1_000_000_001))
+		},
+		"gas 1000001 exceeds maximum 1000000": func(args *signer.TransactionArgs) {
+			gas := hexutil.Uint64(1_000_001)
+			args.Gas = &gas
+		},
+	} {
+		err := batcher.CheckTransaction(txArgs(modify))
+		require.ErrorIs(t, err, ErrForbidden, name)
+		require.ErrorContains(t, err, name)
+	}
+	// Without restrictions, any recipient, value, fees and gas are allowed.
+	require.NoError(t, sequencer.CheckTransaction(txArgs(func(args *signer.TransactionArgs) {
+		args.To = nil
+		args.Value = (*hexutil.Big)(big.NewInt(1e18))
+		args.ChainID = (*hexutil.Big)(big.NewInt(901))
+		args.MaxFeePerGas = (*hexutil.Big)(big.NewInt(1e18))
+		args.BlobVersionedHashes = []common.Hash{{0x01}}
+		args.BlobFeeCap = (*hexutil.Big)(big.NewInt(1e18))
+		gas := hexutil.Uint64(30_000_000)
+		args.Gas = &gas
+	})))
+
+	payload := signer.NewBlockPayloadArgs(eth.ChainIDFromUInt64(901), common.Hash{0x01}, nil)
+	require.NoError(t, sequencer.CheckBlockPayload(payload))
+	require.ErrorIs(t, batcher.CheckBlockPayload(payload), ErrForbidden)
+	payload.ChainID = eth.ChainIDFromUInt64(900)
+	require.ErrorIs(t, sequencer.CheckBlockPayload(payload), ErrForbidden)
+
+	preconf := signer.NewPreconfirmationArgs(eth.ChainIDFromUInt64(901), common.Hash{0x01}, nil)
+	require.NoError(t, sequencer.CheckPreconfirmation(preconf))
+	require.ErrorIs(t, batcher.CheckPreconfirmation(preconf), ErrForbidden)
+	preconf.ChainID = eth.ChainIDFromUInt64(900)
+	require.ErrorIs(t, sequencer.CheckPreconfirmation(preconf), ErrForbidden)
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/cliapp"
+	"github.com/tokamak-network/tokamak-thanos/op-service/httputil"
+	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-service/oppprof"
+	oprpc "github.com/tokamak-network/tokamak-thanos/op-service/rpc"
+	"github.com/tokamak-network/tokamak-thanos/op-service/tls/certman"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/config"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/version"
+)
+
+func Main(ctx context.Context, logger log.Logger, cfg *config.Config) (cliapp.Lifecycle, error) {
+	if err := cfg.Check(); err != nil {
+		return nil, err
+	}
+	return NewService(ctx, logger, cfg)
+}
+
+type Service struct {
+	logger  log.Logger
+	metrics metrics.Metricer
+
+	audit  AuditLog
+	signer *Signer
+
+	certMan      *certman.CertMan
+	rpcServer    *oprpc.Server
+	pprofService *oppprof.Service
+	metricsSrv   *httputil.HTTPServer
+
+	stopped atomic.Bool
+}
+
+// NewService creates a new Service.
+func NewService(ctx context.Context, logger log.Logger, cfg *config.Config) (*Service, error) {
+	s := &Service{
+		logger:  logger,
+		metrics: metrics.NewMetrics(),
+	}
+
+	if err := s.initFromConfig(cfg); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to init service: %w", err), s.Stop(ctx))
+	}
+
+	return s, nil
+}
+
+func (s *Service) initFromConfig(cfg *config.Config) error {
+	if err := s.initAuditLog(cfg); err != nil {
+		return fmt.Errorf("failed to init audit log: %w", err)
+	}
+	if err := s.initSigner(cfg); err != nil {
+		return fmt.Errorf("failed to init signer: %w", err)
+	}
+	if err := s.initPProf(&cfg.PprofConfig); err != nil {
+		return fmt.Errorf("failed to init profiling: %w", err)
+	}
+	if err := s.initMetricsServer(&cfg.MetricsConfig); err != nil {
+		return fmt.Errorf("failed to init metrics server: %w", err)
+	}
+	if err := s.initRPCServer(cfg); err != nil {
+		return fmt.Errorf("failed to init rpc server: %w", err)
+	}
+
+	s.metrics.RecordInfo(version.SimpleWithMeta)
+	s.metrics.RecordUp()
+
+	return nil
+}
+
+func (s *Service) initAuditLog(cfg *config.Config) error {
+	if cfg.AuditLog == "" {
+		s.audit = NewLoggerAuditLog(s.logger)
+		return nil
+	}
+	audit, err := NewFileAuditLog(s.logger, cfg.AuditLog)
+	if err != nil {
+		return err
+	}
+	s.audit = audit
+	return nil
+}
+
+func (s *Service) initSigner(cfg *config.Config) error {
+	signerCfg, err := LoadSignerConfig(cfg.ConfigFile)
+	if err != nil {
+		return err
+	}
+	signer, err := NewSigner(s.logger, s.metrics, signerCfg, s.audit)
+	if err != nil {
+		return err
+	}
+	s.signer = signer
+	return nil
+}
+
+func (s *Service) initPProf(cfg *oppprof.CLIConfig) error {
+	s.pprofService = oppprof.New(
+		cfg.ListenEnabled,
+		cfg.ListenAddr,
+		cfg.ListenPort,
+		cfg.ProfileType,
+		cfg.ProfileDir,
+		cfg.ProfileFilename,
+	)
+
+	if err := s.pprofService.Start(); err != nil {
+		return fmt.Errorf("failed to start pprof service: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) initMetricsServer(cfg *opmetrics.CLIConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	s.logger.Debug("starting metrics server", "addr", cfg.ListenAddr, "port", cfg.ListenPort)
+	m, ok := s.metrics.(opmetrics.RegistryMetricer)
+	if !ok {
+		return fmt.Errorf("metrics were enabled, but metricer %T does not expose registry for metrics-server", s.metrics)
+	}
+	metricsSrv, err := opmetrics.StartServer(m.Registry(), cfg.ListenAddr, cfg.ListenPort)
+	if err != nil {
+		return fmt.Errorf("failed to start metrics server: %w", err)
+	}
+	s.logger.Info("started metrics server", "addr", metricsSrv.Addr())
+	s.metricsSrv = metricsSrv
+	return nil
+}
+
+func (s *Service) initRPCServer(cfg *config.Config) error {
+	caCert, err := os.ReadFile(cfg.TLSConfig.TLSCaCert)
+	if err != nil {
+		return fmt.Errorf("failed to read tls ca cert: %w", err)
+	}
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return errors.New("no certificates in tls ca cert")
+	}
+	// certman watches for newer server certificates and automatically reloads them
+	cm, err := certman.New(s.logger, cfg.TLSConfig.TLSCert, cfg.TLSConfig.TLSKey)
+	if err != nil {
+		return fmt.Errorf("failed to read tls cert or key: %w", err)
+	}
+	if err := cm.Watch(); err != nil {
+		return fmt.Errorf("failed to start certman watcher: %w", err)
+	}
+	s.certMan = cm
+
+	server := oprpc.NewServer(
+		cfg.RPC.ListenAddr,
+		cfg.RPC.ListenPort,
+		cfg.Version,
+		oprpc.WithLogger(s.logger),
+		oprpc.WithTLSConfig(&oprpc.ServerTLSConfig{
+			Config: &tls.Config{
+				MinVersion:     tls.VersionTLS13,
+				GetCertificate: cm.GetCertificate,
+				ClientCAs:      caCertPool,
+				// Clients are authorized by their certificate.
+				ClientAuth: tls.RequireAndVerifyClientCert,
+			},
+			CLIConfig: &cfg.TLSConfig,
+		}),
+	)
+	server.AddAPI(rpc.API{
+		Namespace: "eth",
+		Service:   NewEthAPI(s.signer),
+	})
+	server.AddAPI(rpc.API{
+		Namespace: "opsigner",
+		Service:   NewOpSignerAPI(s.signer),
+	})
+	s.rpcServer = server
+	return nil
+}
+
+func (s *Service) Start(_ context.Context) error {
+	s.logger.Info("Starting JSON-RPC server")
+	if err := s.rpcServer.Start(); err != nil {
+		return fmt.Errorf("unable to start RPC server: %w", err)
+	}
+	s.logger.Info("Started JSON-RPC server", "endpoint", s.rpcServer.Endpoint())
+	s.logger.Info("Signer started")
+	return nil
+}
+
+// Endpoint returns the https endpoint of the RPC server. The server must have been started.
+func (s *Service) Endpoint() string {
+	return "https://" + s.rpcServer.Endpoint()
+}
+
+func (s *Service) Stopped() bool {
+	return s.stopped.Load()
+}
+
+func (s *Service) Stop(ctx context.Context) error {
+	s.logger.Info("Stopping signer")
+
+	var result error
+	if s.rpcServer != nil {
+		if err := s.rpcServer.Stop(); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to stop RPC server: %w", err))
+		}
+	}
+	if s.certMan != nil {
+		s.certMan.Stop()
+	}
+	if s.audit != nil {
+		if err := s.audit.Close(); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to close audit log: %w", err))
+		}
+	}
+	if s.pprofService != nil {
+		if err := s.pprofService.Stop(ctx); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to close pprof server: %w", err))
+		}
+	}
+	if s.metricsSrv != nil {
+		if err := s.metricsSrv.Stop(ctx); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to close metrics server: %w", err))
+		}
+	}
+	s.stopped.Store(true)
+	s.logger.Info("Stopped signer", "err", result)
+	return result
+}
+
+var _ cliapp.Lifecycle = (*Service)(nil)
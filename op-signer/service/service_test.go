@@ -0,0 +1,286 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/signer"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	optls "github.com/tokamak-network/tokamak-thanos/op-service/tls"
+	"github.com/tokamak-network/tokamak-thanos/op-signer/config"
+)
+
+// testCA issues the TLS certificates of the signer and its clients.
+type testCA struct {
+	dir  string
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	path string
+}
+
+func newTestCA(t *testing.T, dir string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	ca := &testCA{dir: dir, cert: cert, key: key, path: filepath.Join(dir, "ca.crt")}
+	writePEM(t, ca.path, "CERTIFICATE", der)
+	return ca
+}
+
+// issue issues a certificate for the name, and returns the paths of the certificate and its key.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPath, keyPath := filepath.Join(ca.dir, name+".crt"), filepath.Join(ca.dir, name+".key")
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+	return certPath, keyPath
+}
+
+func (ca *testCA) clientConfig(t *testing.T, name string) optls.CLIConfig {
+	cert, key := ca.issue(t, name, x509.ExtKeyUsageClientAuth)
+	return optls.CLIConfig{TLSCaCert: ca.path, TLSCert: cert, TLSKey: key}
+}
+
+func writePEM(t *testing.T, path string, typ string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	logger := testlog.Logger(t, log.LevelInfo)
+	dir := t.TempDir()
+	keysDir := filepath.Join(dir, "keys")
+	require.NoError(t, os.Mkdir(keysDir, 0o700))
+
+	// The batcher key is in an encrypted keystore, the sequencer key is a plain key.
+	batcherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	batcherAddr := crypto.PubkeyToAddress(batcherKey.PublicKey)
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{Address: batcherAddr, PrivateKey: batcherKey}, "secret",
+		keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(keysDir, "batcher.json"), keyJSON, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(keysDir, "batcher.pass"), []byte("secret\n"), 0o600))
+	sequencerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sequencerAddr := crypto.PubkeyToAddress(sequencerKey.PublicKey)
+	require.NoError(t, os.WriteFile(filepath.Join(keysDir, "sequencer.key"),
+		[]byte(hexutil.Encode(crypto.FromECDSA(sequencerKey))), 0o600))
+
+	inbox := common.HexToAddress("0xff00000000000000000000000000000000000901")
+	signerCfg := fmt.Sprintf(`
+keys:
+  batcher:
+    keystore: keys/batcher.json
+    passwordFile: keys/batcher.pass
+  sequencer:
+    privateKeyFile: keys/sequencer.key
+clients:
+  - name: batcher.test
+    key: batcher
+    chainIds: [900]
+    toAddresses: ["%s"]
+    maxValue: "0"
+  - name: sequencer.test
+    key: sequencer
+    chainIds: [901]
+    blockPayloads: true
+    preconfirmations: true
+`, inbox)
+	configPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(signerCfg), 0o600))
+
+	ca := newTestCA(t, dir)
+	serverCert, serverKey := ca.issue(t, "localhost", x509.ExtKeyUsageServerAuth)
+	cfg := config.NewConfig(configPath)
+	cfg.AuditLog = filepath.Join(dir, "audit.jsonl")
+	cfg.TLSConfig = optls.CLIConfig{TLSCaCert: ca.path, TLSCert: serverCert, TLSKey: serverKey}
+	cfg.RPC.ListenAddr = "127.0.0.1"
+	cfg.RPC.ListenPort = 0
+	cfg.Version = "test"
+	require.NoError(t, cfg.Check())
+
+	svc, err := NewService(ctx, logger, &cfg)
+	require.NoError(t, err)
+	require.NoError(t, svc.Start(ctx))
+	t.Cleanup(func() {
+		require.NoError(t, svc.Stop(ctx))
+	})
+
+	batcher, err := signer.NewSignerClient(logger, svc.Endpoint(), ca.clientConfig(t, "batcher.test"))
+	require.NoError(t, err)
+	t.Cleanup(batcher.Close)
+
+	chainID := big.NewInt(900)
+	newTx := func(to common.Address, value int64) *types.Transaction {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     3,
+			GasTipCap: big.NewInt(1_000_000_000),
+			GasFeeCap: big.NewInt(2_000_000_000),
+			Gas:       21_000,
+			To:        &to,
+			Value:     big.NewInt(value),
+		})
+	}
+
+	t.Run("SignTransaction", func(t *testing.T) {
+		tx := newTx(inbox, 0)
+		signed, err := batcher.SignTransaction(ctx, chainID, batcherAddr, tx)
+		require.NoError(t, err)
+		sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+		require.NoError(t, err)
+		require.Equal(t, batcherAddr, sender)
+		require.Equal(t, tx.Nonce(), signed.Nonce())
+		require.Equal(t, *tx.To(), *signed.To())
+	})
+
+	t.Run("PolicyDenies", func(t *testing.T) {
+		_, err := batcher.SignTransaction(ctx, chainID, batcherAddr, newTx(common.Address{0x01}, 0))
+		require.ErrorContains(t, err, "to address")
+		_, err = batcher.SignTransaction(ctx, chainID, batcherAddr, newTx(inbox, 1))
+		require.ErrorContains(t, err, "exceeds maximum")
+		_, err = batcher.SignTransaction(ctx, chainID, sequencerAddr, newTx(inbox, 0))
+		require.ErrorContains(t, err, "is not the signer")
+		_, err = batcher.SignBlockPayload(ctx, signer.NewBlockPayloadArgs(eth.ChainIDFromUInt64(900), common.Hash{0x01}, nil))
+		require.ErrorContains(t, err, "block payload signing not allowed")
+	})
+
+	t.Run("SignBlockPayload", func(t *testing.T) {
+		seqTLS := ca.clientConfig(t, "sequencer.test")
+		remote, err := signer.NewRemoteSigner(logger, signer.CLIConfig{
+			Endpoint:  svc.Endpoint(),
+			Address:   sequencerAddr.Hex(),
+			TLSConfig: seqTLS,
+		})
+		require.NoError(t, err)
+
+		chain := eth.ChainIDFromUInt64(901)
+		block := &signer.SignedP2PBlock{Raw: []byte("block payload")}
+		block.Signature, err = remote.SignBlockV1(ctx, chain, signer.PayloadHash(block.Raw))
+		require.NoError(t, err)
+		require.NoError(t, block.VerifySignature(&signer.OPStackP2PBlockAuthV1{Allowed: sequencerAddr, Chain: chain}))
+
+		// The sender is checked against the key of the client.
+		other, err := signer.NewRemoteSigner(logger, signer.CLIConfig{
+			Endpoint:  svc.Endpoint(),
+			Address:   batcherAddr.Hex(),
+			TLSConfig: seqTLS,
+		})
+		require.NoError(t, err)
+		_, err = other.SignBlockV1(ctx, chain, signer.PayloadHash(block.Raw))
+		require.ErrorContains(t, err, "sender address")
+	})
+
+	t.Run("SignPreconfirmation", func(t *testing.T) {
+		remote, err := signer.NewRemoteSigner(logger, signer.CLIConfig{
+			Endpoint:  svc.Endpoint(),
+			Address:   sequencerAddr.Hex(),
+			TLSConfig: ca.clientConfig(t, "sequencer.test"),
+		})
+		require.NoError(t, err)
+
+		chain := eth.ChainIDFromUInt64(901)
+		signed, err := signer.SignPreconfirmation(ctx, remote, chain, signer.Preconfirmation{TxHash: common.Hash{0xaa}, BlockNumber: 7})
+		require.NoError(t, err)
+		require.NoError(t, signed.VerifySignature(&signer.OPStackP2PBlockAuthV1{Allowed: sequencerAddr, Chain: chain}))
+
+		_, err = batcher.SignPreconfirmation(ctx, signer.NewPreconfirmationArgs(eth.ChainIDFromUInt64(900), common.Hash{0x01}, nil))
+		require.ErrorContains(t, err, "preconfirmation signing not allowed")
+	})
+
+	t.Run("UnknownClient", func(t *testing.T) {
+		unknown, err := signer.NewSignerClient(logger, svc.Endpoint(), ca.clientConfig(t, "unknown.test"))
+		require.NoError(t, err, "health status does not need authorization")
+		defer unknown.Close()
+		_, err = unknown.SignTransaction(ctx, chainID, batcherAddr, newTx(inbox, 0))
+		require.ErrorContains(t, err, "unauthorized")
+	})
+
+	t.Run("AuditLog", func(t *testing.T) {
+		file, err := os.Open(cfg.AuditLog)
+		require.NoError(t, err)
+		defer file.Close()
+		var records []AuditRecord
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var rec AuditRecord
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+			records = append(records, rec)
+		}
+		require.NoError(t, scanner.Err())
+		require.Len(t, records, 10)
+
+		signed := records[0]
+		require.Equal(t, "batcher.test", signed.Client)
+		require.Equal(t, methodSignTransaction, signed.Method)
+		require.Equal(t, batcherAddr, signed.Signer)
+		require.True(t, signed.Allowed)
+		require.NotNil(t, signed.TxHash)
+
+		denied := records[1]
+		require.False(t, denied.Allowed)
+		require.Contains(t, denied.Reason, "to address")
+
+		require.Equal(t, methodSignBlockPayload, records[5].Method)
+		require.True(t, records[5].Allowed)
+		require.Equal(t, methodSignPreconfirmation, records[7].Method)
+		require.True(t, records[7].Allowed)
+		require.False(t, records[8].Allowed)
+		require.Equal(t, "unknown", records[9].Client)
+		require.False(t, records[9].Allowed)
+	})
+}
@@ -0,0 +1,128 @@
+package adversarialbuilder
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+type L1Source interface {
+	L1BlockRefByLabel(ctx context.Context, label eth.BlockLabel) (eth.L1BlockRef, error)
+	L1BlockRefByNumber(ctx context.Context, num uint64) (eth.L1BlockRef, error)
+}
+
+// Builder wraps another builder, to misbehave on purpose:
+// it may corrupt the sealed payloads, and it may build on an L1 origin that lags behind the L1 head.
+type Builder struct {
+	id  seqtypes.BuilderID
+	log log.Logger
+
+	// inner returns the wrapped builder.
+	// It is resolved lazily, since builders are started in no particular order.
+	inner func() (work.Builder, error)
+
+	corruption Corruption
+	every      uint64
+
+	l1          L1Source
+	l1OriginLag *uint64
+
+	// count is the number of jobs created so far, to determine which blocks to corrupt.
+	count atomic.Uint64
+
+	onClose func() // always non-nil
+
+	registry work.Jobs
+}
+
+var _ work.Builder = (*Builder)(nil)
+
+func NewBuilder(id seqtypes.BuilderID, log log.Logger, inner work.Builder,
+	corruption Corruption, every uint64,
+	l1 L1Source, l1OriginLag *uint64,
+	registry work.Jobs) *Builder {
+	return &Builder{
+		id:          id,
+		log:         log,
+		inner:       func() (work.Builder, error) { return inner, nil },
+		corruption:  corruption,
+		every:       every,
+		l1:          l1,
+		l1OriginLag: l1OriginLag,
+		onClose:     func() {},
+		registry:    registry,
+	}
+}
+
+func (b *Builder) NewJob(ctx context.Context, opts seqtypes.BuildOpts) (work.BuildJob, error) {
+	inner, err := b.inner()
+	if err != nil {
+		return nil, err
+	}
+	if b.l1OriginLag != nil {
+		origin, err := b.laggingOrigin(ctx, *b.l1OriginLag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select lagging L1 origin: %w", err)
+		}
+		b.log.Warn("Building on lagging L1 origin", "origin", origin, "lag", *b.l1OriginLag)
+		opts.L1Origin = &origin.Hash
+	}
+	innerJob, err := inner.NewJob(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	// The inner job is registered by the inner builder, but the sequencer interacts with the outer job.
+	// Unregister the inner job, so the block-building API cannot bypass the adversarial behavior.
+	b.registry.UnregisterJob(innerJob.ID())
+
+	corruption := CorruptionNone
+	if b.every > 0 && (b.count.Add(1)%b.every) == 0 {
+		corruption = b.corruption
+	}
+	id := seqtypes.RandomJobID()
+	job := &Job{
+		id:         id,
+		log:        b.log,
+		inner:      innerJob,
+		corruption: corruption,
+		unregister: func() {
+			b.registry.UnregisterJob(id)
+		},
+	}
+	if err := b.registry.RegisterJob(job); err != nil {
+		innerJob.Close()
+		return nil, err
+	}
+	return job, nil
+}
+
+// laggingOrigin returns the L1 block that is the given number of blocks behind the L1 head.
+func (b *Builder) laggingOrigin(ctx context.Context, lag uint64) (eth.L1BlockRef, error) {
+	head, err := b.l1.L1BlockRefByLabel(ctx, eth.Unsafe)
+	if err != nil {
+		return eth.L1BlockRef{}, fmt.Errorf("failed to retrieve L1 head: %w", err)
+	}
+	if head.Number < lag {
+		return eth.L1BlockRef{}, fmt.Errorf("L1 head %s is not deep enough for origin lag %d", head, lag)
+	}
+	return b.l1.L1BlockRefByNumber(ctx, head.Number-lag)
+}
+
+func (b *Builder) Close() error {
+	b.onClose()
+	return nil
+}
+
+func (b *Builder) String() string {
+	return "adversarial-builder-" + b.id.String()
+}
+
+func (b *Builder) ID() seqtypes.BuilderID {
+	return b.id
+}
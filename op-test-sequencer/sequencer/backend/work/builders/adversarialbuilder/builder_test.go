@@ -0,0 +1,135 @@
+package adversarialbuilder
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testutils"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+type testBuilder struct {
+	registry work.Jobs
+	opts     seqtypes.BuildOpts
+	envelope *eth.ExecutionPayloadEnvelope
+}
+
+func (t *testBuilder) NewJob(ctx context.Context, opts seqtypes.BuildOpts) (work.BuildJob, error) {
+	t.opts = opts
+	job := &testJob{id: seqtypes.RandomJobID(), envelope: t.envelope, registry: t.registry}
+	return job, t.registry.RegisterJob(job)
+}
+
+func (t *testBuilder) String() string         { return "test-builder" }
+func (t *testBuilder) ID() seqtypes.BuilderID { return "test" }
+func (t *testBuilder) Close() error           { return nil }
+
+type testJob struct {
+	id       seqtypes.BuildJobID
+	envelope *eth.ExecutionPayloadEnvelope
+	registry work.Jobs
+}
+
+func (t *testJob) ID() seqtypes.BuildJobID                               { return t.id }
+func (t *testJob) Cancel(ctx context.Context) error                      { return nil }
+func (t *testJob) Open(ctx context.Context) error                        { return nil }
+func (t *testJob) Seal(ctx context.Context) (work.Block, error)          { return t.envelope, nil }
+func (t *testJob) String() string                                        { return "test-job" }
+func (t *testJob) Close()                                                { t.registry.UnregisterJob(t.id) }
+func (t *testJob) IncludeTx(ctx context.Context, tx hexutil.Bytes) error { return nil }
+
+func testEnvelope(rng *rand.Rand) *eth.ExecutionPayloadEnvelope {
+	envelope := &eth.ExecutionPayloadEnvelope{
+		ExecutionPayload: &eth.ExecutionPayload{
+			ParentHash:   testutils.RandomHash(rng),
+			StateRoot:    eth.Bytes32(testutils.RandomHash(rng)),
+			BlockNumber:  100,
+			Timestamp:    1000,
+			Transactions: []eth.Data{{0x01}},
+		},
+	}
+	envelope.ExecutionPayload.BlockHash, _ = envelope.CheckBlockHash()
+	return envelope
+}
+
+func TestCorruption(t *testing.T) {
+	rng := rand.New(rand.NewSource(123))
+	for _, c := range []Corruption{CorruptionStateRoot, CorruptionParentHash, CorruptionTimestamp, CorruptionTransactions} {
+		t.Run(string(c), func(t *testing.T) {
+			original := testEnvelope(rng)
+			originalHash := original.ExecutionPayload.BlockHash
+			corrupted, err := c.Apply(original)
+			require.NoError(t, err)
+			require.NotEqual(t, originalHash, corrupted.ExecutionPayload.BlockHash)
+			_, ok := corrupted.CheckBlockHash()
+			require.True(t, ok, "block hash must match the corrupted contents")
+			require.Equal(t, originalHash, original.ExecutionPayload.BlockHash, "original must not be modified")
+			require.Len(t, original.ExecutionPayload.Transactions, 1)
+		})
+	}
+	t.Run("block-hash", func(t *testing.T) {
+		original := testEnvelope(rng)
+		corrupted, err := CorruptionBlockHash.Apply(original)
+		require.NoError(t, err)
+		_, ok := corrupted.CheckBlockHash()
+		require.False(t, ok, "block hash must not match")
+	})
+	t.Run("none", func(t *testing.T) {
+		original := testEnvelope(rng)
+		out, err := CorruptionNone.Apply(original)
+		require.NoError(t, err)
+		require.Same(t, original, out)
+	})
+	require.Error(t, Corruption("foo").Check())
+}
+
+func TestAdversarialBuilder(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	rng := rand.New(rand.NewSource(1234))
+	reg := work.NewJobRegistry()
+	inner := &testBuilder{registry: reg, envelope: testEnvelope(rng)}
+	l1 := &testutils.MockL1Source{}
+	lag := uint64(5)
+	x := NewBuilder("foo", logger, inner, CorruptionStateRoot, 2, l1, &lag, reg)
+
+	ctx := context.Background()
+	head := eth.L1BlockRef{Number: 20, Hash: common.Hash{20}}
+	origin := eth.L1BlockRef{Number: 15, Hash: common.Hash{15}}
+	for i := 1; i <= 4; i++ {
+		l1.ExpectL1BlockRefByLabel(eth.Unsafe, head, nil)
+		l1.ExpectL1BlockRefByNumber(15, origin, nil)
+		job, err := x.NewJob(ctx, seqtypes.BuildOpts{Parent: common.Hash{1}})
+		require.NoError(t, err)
+		l1.AssertExpectations(t)
+		require.Equal(t, origin.Hash, *inner.opts.L1Origin, "must build on the lagging L1 origin")
+		require.Equal(t, 1, reg.Len(), "only the adversarial job is registered")
+		require.NotNil(t, reg.GetJob(job.ID()))
+
+		block, err := job.Seal(ctx)
+		require.NoError(t, err)
+		if i%2 == 0 {
+			require.NotEqual(t, inner.envelope.ExecutionPayload.ID(), block.ID(), "every 2nd block is corrupted")
+		} else {
+			require.Equal(t, inner.envelope.ExecutionPayload.ID(), block.ID())
+		}
+		job.Close()
+		require.Equal(t, 0, reg.Len())
+	}
+
+	l1.ExpectL1BlockRefByLabel(eth.Unsafe, eth.L1BlockRef{Number: 2}, nil)
+	_, err := x.NewJob(ctx, seqtypes.BuildOpts{})
+	require.ErrorContains(t, err, "not deep enough")
+
+	require.NoError(t, x.Close())
+	require.Equal(t, "adversarial-builder-foo", x.String())
+}
@@ -0,0 +1,80 @@
+package adversarialbuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/client"
+	"github.com/tokamak-network/tokamak-thanos/op-service/endpoint"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+type Config struct {
+	// Builder is the builder to wrap, which builds the honest blocks.
+	Builder seqtypes.BuilderID `yaml:"builder"`
+
+	// Corruption to apply to sealed blocks. No blocks are corrupted if empty.
+	Corruption Corruption `yaml:"corruption,omitempty"`
+	// Every applies the corruption to every N-th block only. Defaults to every block.
+	Every uint64 `yaml:"every,omitempty"`
+
+	// L1OriginLag is the number of blocks behind the L1 head to select the L1 origin of new blocks.
+	// The L1 origin is not changed if nil.
+	// The origin is not checked to be a valid successor of the L1 origin of the parent block.
+	L1OriginLag *uint64 `yaml:"l1OriginLag,omitempty"`
+	// L1EL is the L1 execution-layer RPC endpoint, required if L1OriginLag is set.
+	L1EL endpoint.OptionalRPC `yaml:"l1EL,omitempty"`
+}
+
+func (c *Config) Start(ctx context.Context, id seqtypes.BuilderID, opts *work.ServiceOpts) (work.Builder, error) {
+	if c.Builder == "" {
+		return nil, errors.New("no builder to wrap specified")
+	}
+	if c.Builder == id {
+		return nil, errors.New("adversarial builder cannot wrap itself")
+	}
+	if err := c.Corruption.Check(); err != nil {
+		return nil, err
+	}
+	every := c.Every
+	if every == 0 {
+		every = 1
+	}
+	b := &Builder{
+		id:  id,
+		log: opts.Log,
+		inner: func() (work.Builder, error) {
+			inner := opts.Services.Builder(c.Builder)
+			if inner == nil {
+				return nil, fmt.Errorf("unknown builder %q", c.Builder)
+			}
+			return inner, nil
+		},
+		corruption:  c.Corruption,
+		every:       every,
+		l1OriginLag: c.L1OriginLag,
+		onClose:     func() {},
+		registry:    opts.Jobs,
+	}
+	if c.L1OriginLag != nil {
+		if c.L1EL.Value == nil || c.L1EL.Value.RPC() == "" {
+			return nil, errors.New("l1EL is required to select a lagging L1 origin")
+		}
+		l1RPC, err := client.NewRPC(ctx, opts.Log, c.L1EL.Value.RPC(), client.WithLazyDial())
+		if err != nil {
+			return nil, err
+		}
+		l1Cl, err := sources.NewL1Client(l1RPC, opts.Log, nil,
+			sources.L1ClientSimpleConfig(false, sources.RPCKindStandard, 10))
+		if err != nil {
+			l1RPC.Close()
+			return nil, err
+		}
+		b.l1 = l1Cl
+		b.onClose = l1RPC.Close
+	}
+	return b, nil
+}
@@ -0,0 +1,65 @@
+package adversarialbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/builders/noopbuilder"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+func TestConfig(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelInfo)
+	ensemble := &work.Ensemble{}
+	opts := &work.ServiceOpts{
+		StartOpts: &work.StartOpts{
+			Log:     logger,
+			Metrics: &metrics.NoopMetrics{},
+			Jobs:    work.NewJobRegistry(),
+		},
+		Services: ensemble,
+	}
+	id := seqtypes.BuilderID("test")
+
+	t.Run("missing builder", func(t *testing.T) {
+		_, err := (&Config{}).Start(context.Background(), id, opts)
+		require.ErrorContains(t, err, "no builder")
+	})
+	t.Run("self", func(t *testing.T) {
+		_, err := (&Config{Builder: id}).Start(context.Background(), id, opts)
+		require.ErrorContains(t, err, "itself")
+	})
+	t.Run("unknown corruption", func(t *testing.T) {
+		_, err := (&Config{Builder: "inner", Corruption: "foo"}).Start(context.Background(), id, opts)
+		require.ErrorContains(t, err, "unknown corruption")
+	})
+	t.Run("lag without L1", func(t *testing.T) {
+		lag := uint64(1)
+		_, err := (&Config{Builder: "inner", L1OriginLag: &lag}).Start(context.Background(), id, opts)
+		require.ErrorContains(t, err, "l1EL is required")
+	})
+	t.Run("lazy inner builder", func(t *testing.T) {
+		cfg := &Config{Builder: "inner", Corruption: CorruptionBlockHash}
+		builder, err := cfg.Start(context.Background(), id, opts)
+		require.NoError(t, err)
+		require.Equal(t, id, builder.ID())
+
+		_, err = builder.NewJob(context.Background(), seqtypes.BuildOpts{})
+		require.ErrorContains(t, err, "unknown builder")
+
+		require.NoError(t, ensemble.AddBuilder(noopbuilder.NewBuilder("inner", opts.Jobs)))
+		job, err := builder.NewJob(context.Background(), seqtypes.BuildOpts{})
+		require.NoError(t, err)
+		_, err = job.Seal(context.Background())
+		require.ErrorIs(t, err, noopbuilder.ErrNoBuild)
+		job.Close()
+		require.NoError(t, builder.Close())
+	})
+}
@@ -0,0 +1,81 @@
+package adversarialbuilder
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+)
+
+// Corruption is a way to make a sealed block invalid.
+type Corruption string
+
+const (
+	// CorruptionNone leaves the block as-is.
+	CorruptionNone Corruption = ""
+	// CorruptionBlockHash changes the block hash, without changing the block contents.
+	// The block fails the block-hash check of the P2P gossip validation.
+	CorruptionBlockHash Corruption = "block-hash"
+	// CorruptionStateRoot changes the state root, with a matching block hash.
+	// The block passes the gossip validation, but fails to be processed by the execution engine.
+	CorruptionStateRoot Corruption = "state-root"
+	// CorruptionParentHash builds on top of an unknown parent, with a matching block hash.
+	CorruptionParentHash Corruption = "parent-hash"
+	// CorruptionTimestamp changes the timestamp to be off-by-one from the L2 block time, with a matching block hash.
+	CorruptionTimestamp Corruption = "timestamp"
+	// CorruptionTransactions appends a transaction that cannot be decoded, with a matching block hash.
+	CorruptionTransactions Corruption = "transactions"
+)
+
+func (c Corruption) Check() error {
+	switch c {
+	case CorruptionNone, CorruptionBlockHash, CorruptionStateRoot, CorruptionParentHash,
+		CorruptionTimestamp, CorruptionTransactions:
+		return nil
+	default:
+		return fmt.Errorf("unknown corruption %q", string(c))
+	}
+}
+
+// invalidTx is not a valid typed transaction nor a valid RLP-encoded legacy transaction.
+var invalidTx = hexutil.Bytes{0x7f, 0xde, 0xad}
+
+// Apply returns a corrupted copy of the envelope. The original envelope is not modified.
+func (c Corruption) Apply(envelope *eth.ExecutionPayloadEnvelope) (*eth.ExecutionPayloadEnvelope, error) {
+	if c == CorruptionNone {
+		return envelope, nil
+	}
+	payload := *envelope.ExecutionPayload
+	out := &eth.ExecutionPayloadEnvelope{
+		ParentBeaconBlockRoot: envelope.ParentBeaconBlockRoot,
+		ExecutionPayload:      &payload,
+	}
+	switch c {
+	case CorruptionBlockHash:
+		payload.BlockHash = flip(payload.BlockHash)
+		return out, nil
+	case CorruptionStateRoot:
+		payload.StateRoot = eth.Bytes32(flip(common.Hash(payload.StateRoot)))
+	case CorruptionParentHash:
+		payload.ParentHash = flip(payload.ParentHash)
+	case CorruptionTimestamp:
+		payload.Timestamp += 1
+	case CorruptionTransactions:
+		payload.Transactions = append(append(make([]eth.Data, 0, len(payload.Transactions)+1),
+			payload.Transactions...), eth.Data(invalidTx))
+	default:
+		return nil, fmt.Errorf("unknown corruption %q", string(c))
+	}
+	// Keep the block hash consistent with the corrupted contents,
+	// so the block is not trivially rejected before the corruption is noticed.
+	payload.BlockHash, _ = out.CheckBlockHash()
+	return out, nil
+}
+
+// flip returns the hash with all bits of the last byte inverted.
+func flip(h common.Hash) common.Hash {
+	h[len(h)-1] ^= 0xff
+	return h
+}
@@ -0,0 +1,82 @@
+package adversarialbuilder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+type Job struct {
+	id  seqtypes.BuildJobID
+	log log.Logger
+
+	inner      work.BuildJob
+	corruption Corruption
+
+	result     work.Block
+	unregister func() // always non-nil
+
+	mu sync.Mutex
+}
+
+var _ work.BuildJob = (*Job)(nil)
+
+func (job *Job) ID() seqtypes.BuildJobID {
+	return job.id
+}
+
+func (job *Job) Cancel(ctx context.Context) error {
+	return job.inner.Cancel(ctx)
+}
+
+func (job *Job) Open(ctx context.Context) error {
+	return job.inner.Open(ctx)
+}
+
+func (job *Job) Seal(ctx context.Context) (work.Block, error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.result != nil {
+		return job.result, nil
+	}
+	block, err := job.inner.Seal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if job.corruption == CorruptionNone {
+		job.result = block
+		return block, nil
+	}
+	envelope, ok := block.(*eth.ExecutionPayloadEnvelope)
+	if !ok {
+		return nil, fmt.Errorf("cannot corrupt block of type %T: %w", block, seqtypes.ErrUnknownKind)
+	}
+	corrupted, err := job.corruption.Apply(envelope)
+	if err != nil {
+		return nil, err
+	}
+	job.log.Warn("Corrupted sealed block", "corruption", job.corruption,
+		"original", envelope.ExecutionPayload.ID(), "corrupted", corrupted.ExecutionPayload.ID())
+	job.result = corrupted
+	return corrupted, nil
+}
+
+func (job *Job) String() string {
+	return "adversarial-job-" + job.id.String()
+}
+
+func (job *Job) Close() {
+	job.inner.Close()
+	job.unregister()
+}
+
+func (job *Job) IncludeTx(ctx context.Context, tx hexutil.Bytes) error {
+	return job.inner.IncludeTx(ctx, tx)
+}
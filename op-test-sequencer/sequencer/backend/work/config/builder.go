@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/builders/adversarialbuilder"
 	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/builders/fakepos"
 	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/builders/noopbuilder"
 	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/builders/standardbuilder"
@@ -11,9 +12,10 @@ import (
 )
 
 type BuilderEntry struct {
-	Standard *standardbuilder.Config `yaml:"standard,omitempty"`
-	Noop     *noopbuilder.Config     `yaml:"noop,omitempty"`
-	L1       *fakepos.Config         // L1 is supported only in-process
+	Standard    *standardbuilder.Config    `yaml:"standard,omitempty"`
+	Noop        *noopbuilder.Config        `yaml:"noop,omitempty"`
+	Adversarial *adversarialbuilder.Config `yaml:"adversarial,omitempty"`
+	L1          *fakepos.Config            // L1 is supported only in-process
 }
 
 func (b *BuilderEntry) Start(ctx context.Context, id seqtypes.BuilderID, opts *work.ServiceOpts) (work.Builder, error) {
@@ -22,6 +24,8 @@ func (b *BuilderEntry) Start(ctx context.Context, id seqtypes.BuilderID, opts *w
 		return b.Standard.Start(ctx, id, opts)
 	case b.Noop != nil:
 		return b.Noop.Start(ctx, id, opts)
+	case b.Adversarial != nil:
+		return b.Adversarial.Start(ctx, id, opts)
 	case b.L1 != nil:
 		return fakepos.NewBuilder(ctx, id, opts, b.L1)
 	default:
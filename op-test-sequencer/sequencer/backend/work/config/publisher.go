@@ -4,14 +4,18 @@ import (
 	"context"
 
 	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/publishers/delayedpublisher"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/publishers/equivocatingpublisher"
 	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/publishers/nooppublisher"
 	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/publishers/standardpublisher"
 	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
 )
 
 type PublisherEntry struct {
-	Standard *standardpublisher.Config `yaml:"standard,omitempty"`
-	Noop     *nooppublisher.Config     `yaml:"noop,omitempty"`
+	Standard     *standardpublisher.Config     `yaml:"standard,omitempty"`
+	Noop         *nooppublisher.Config         `yaml:"noop,omitempty"`
+	Delayed      *delayedpublisher.Config      `yaml:"delayed,omitempty"`
+	Equivocating *equivocatingpublisher.Config `yaml:"equivocating,omitempty"`
 }
 
 func (b *PublisherEntry) Start(ctx context.Context, id seqtypes.PublisherID, opts *work.ServiceOpts) (work.Publisher, error) {
@@ -20,6 +24,10 @@ func (b *PublisherEntry) Start(ctx context.Context, id seqtypes.PublisherID, opt
 		return b.Standard.Start(ctx, id, opts)
 	case b.Noop != nil:
 		return b.Noop.Start(ctx, id, opts)
+	case b.Delayed != nil:
+		return b.Delayed.Start(ctx, id, opts)
+	case b.Equivocating != nil:
+		return b.Equivocating.Start(ctx, id, opts)
 	default:
 		return nil, seqtypes.ErrUnknownKind
 	}
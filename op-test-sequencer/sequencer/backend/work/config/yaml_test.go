@@ -5,8 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/builders/adversarialbuilder"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
 )
 
 func TestYamlLoader_Load(t *testing.T) {
@@ -37,3 +41,25 @@ func TestYamlLoader_Invalid(t *testing.T) {
 	_, err := x.Load(context.Background())
 	require.ErrorContains(t, err, "field foobar not found")
 }
+
+func TestYamlLoader_Adversarial(t *testing.T) {
+	x := &YamlLoader{Path: filepath.Join(".", "testdata", "config.yaml")}
+	result, err := x.Load(context.Background())
+	require.NoError(t, err)
+	static := result.(*Ensemble)
+
+	invalid := static.Builders["builder-invalid"].Adversarial
+	require.NotNil(t, invalid)
+	require.Equal(t, seqtypes.BuilderID("builder-a"), invalid.Builder)
+	require.Equal(t, adversarialbuilder.CorruptionStateRoot, invalid.Corruption)
+	require.Equal(t, uint64(3), invalid.Every)
+
+	delayed := static.Publishers["pub-delayed"].Delayed
+	require.NotNil(t, delayed)
+	require.Equal(t, 3*time.Second, delayed.Delay)
+	require.Equal(t, uint64(5), delayed.WithholdEvery)
+
+	equivocating := static.Publishers["pub-equivocating"].Equivocating
+	require.NotNil(t, equivocating)
+	require.Equal(t, seqtypes.SignerID("signer-b"), equivocating.Signer)
+}
@@ -0,0 +1,40 @@
+package delayedpublisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+type Config struct {
+	// Publisher is the publisher to publish the blocks with, after the delay.
+	Publisher seqtypes.PublisherID `yaml:"publisher"`
+	// Delay is how long to wait before publishing a block, e.g. "3s".
+	Delay time.Duration `yaml:"delay,omitempty"`
+	// WithholdEvery withholds every N-th block, and never publishes it.
+	// Set to 1 to withhold all blocks. No blocks are withheld if 0.
+	WithholdEvery uint64 `yaml:"withholdEvery,omitempty"`
+}
+
+func (c *Config) Start(ctx context.Context, id seqtypes.PublisherID, opts *work.ServiceOpts) (work.Publisher, error) {
+	if c.Publisher == "" {
+		return nil, errors.New("no publisher specified")
+	}
+	if c.Publisher == id {
+		return nil, errors.New("delayed publisher cannot publish with itself")
+	}
+	if c.Delay < 0 {
+		return nil, fmt.Errorf("negative delay %s", c.Delay)
+	}
+	return newPublisher(id, opts.Log, func() (work.Publisher, error) {
+		v := opts.Services.Publisher(c.Publisher)
+		if v == nil {
+			return nil, fmt.Errorf("unknown publisher %q", c.Publisher)
+		}
+		return v, nil
+	}, c.Delay, c.WithholdEvery), nil
+}
@@ -0,0 +1,49 @@
+package delayedpublisher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/publishers/nooppublisher"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+func TestConfig(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelInfo)
+	ensemble := &work.Ensemble{}
+	opts := &work.ServiceOpts{
+		StartOpts: &work.StartOpts{
+			Log:     logger,
+			Metrics: &metrics.NoopMetrics{},
+		},
+		Services: ensemble,
+	}
+	id := seqtypes.PublisherID("test")
+
+	_, err := (&Config{}).Start(context.Background(), id, opts)
+	require.ErrorContains(t, err, "no publisher")
+	_, err = (&Config{Publisher: id}).Start(context.Background(), id, opts)
+	require.ErrorContains(t, err, "itself")
+	_, err = (&Config{Publisher: "inner", Delay: -time.Second}).Start(context.Background(), id, opts)
+	require.ErrorContains(t, err, "negative delay")
+
+	cfg := &Config{Publisher: "inner", WithholdEvery: 1}
+	publisher, err := cfg.Start(context.Background(), id, opts)
+	require.NoError(t, err)
+	require.Equal(t, id, publisher.ID())
+
+	err = publisher.Publish(context.Background(), testBlock(1))
+	require.ErrorContains(t, err, "unknown publisher")
+
+	require.NoError(t, ensemble.AddPublisher(nooppublisher.NewPublisher("inner", logger)))
+	require.NoError(t, publisher.Publish(context.Background(), testBlock(1)))
+	require.NoError(t, publisher.Close())
+}
@@ -0,0 +1,101 @@
+package delayedpublisher
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+// Publisher delays or withholds the publishing of blocks,
+// to test how the network handles late or missing gossip from the sequencer.
+type Publisher struct {
+	id  seqtypes.PublisherID
+	log log.Logger
+
+	// publisher is resolved lazily, since publishers are started in no particular order.
+	publisher func() (work.Publisher, error)
+
+	delay         time.Duration
+	withholdEvery uint64
+
+	// count is the number of blocks published so far, to determine which blocks to withhold.
+	count atomic.Uint64
+
+	// ctx is canceled on close, to abort pending delayed publishing.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ work.Publisher = (*Publisher)(nil)
+
+func NewPublisher(id seqtypes.PublisherID, log log.Logger, publisher work.Publisher, delay time.Duration, withholdEvery uint64) *Publisher {
+	return newPublisher(id, log, func() (work.Publisher, error) { return publisher, nil }, delay, withholdEvery)
+}
+
+func newPublisher(id seqtypes.PublisherID, log log.Logger, publisher func() (work.Publisher, error), delay time.Duration, withholdEvery uint64) *Publisher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Publisher{
+		id:            id,
+		log:           log,
+		publisher:     publisher,
+		delay:         delay,
+		withholdEvery: withholdEvery,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Close aborts any pending delayed publishing.
+func (n *Publisher) Close() error {
+	n.cancel()
+	n.wg.Wait()
+	return nil
+}
+
+func (n *Publisher) String() string {
+	return "delayed-publisher-" + n.id.String()
+}
+
+func (n *Publisher) ID() seqtypes.PublisherID {
+	return n.id
+}
+
+// Publish withholds or delays the block. Delayed blocks are published in the background,
+// so the sequencer is not blocked, and publishing errors of delayed blocks are only logged.
+func (n *Publisher) Publish(ctx context.Context, block work.SignedBlock) error {
+	publisher, err := n.publisher()
+	if err != nil {
+		return err
+	}
+	if n.withholdEvery > 0 && n.count.Add(1)%n.withholdEvery == 0 {
+		n.log.Warn("Withholding block", "block", block)
+		return nil
+	}
+	if n.delay == 0 {
+		return publisher.Publish(ctx, block)
+	}
+	n.log.Warn("Delaying block", "block", block, "delay", n.delay)
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		select {
+		case <-time.After(n.delay):
+		case <-n.ctx.Done():
+			n.log.Warn("Dropped delayed block on close", "block", block)
+			return
+		}
+		if err := publisher.Publish(n.ctx, block); err != nil {
+			n.log.Error("Failed to publish delayed block", "block", block, "err", err)
+			return
+		}
+		n.log.Info("Published delayed block", "block", block)
+	}()
+	return nil
+}
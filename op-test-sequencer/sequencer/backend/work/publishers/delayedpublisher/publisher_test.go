@@ -0,0 +1,87 @@
+package delayedpublisher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+type recordingPublisher struct {
+	mu        sync.Mutex
+	published []work.SignedBlock
+}
+
+func (r *recordingPublisher) String() string           { return "recording-publisher" }
+func (r *recordingPublisher) ID() seqtypes.PublisherID { return "recording" }
+func (r *recordingPublisher) Close() error             { return nil }
+func (r *recordingPublisher) Publish(ctx context.Context, block work.SignedBlock) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.published = append(r.published, block)
+	return nil
+}
+
+func (r *recordingPublisher) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.published)
+}
+
+func testBlock(n uint64) *opsigner.SignedExecutionPayloadEnvelope {
+	return &opsigner.SignedExecutionPayloadEnvelope{
+		Envelope: &eth.ExecutionPayloadEnvelope{
+			ExecutionPayload: &eth.ExecutionPayload{
+				BlockHash:   common.Hash{byte(n)},
+				BlockNumber: eth.Uint64Quantity(n),
+			},
+		},
+	}
+}
+
+func TestWithhold(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	inner := &recordingPublisher{}
+	x := NewPublisher("foo", logger, inner, 0, 3)
+	for i := uint64(1); i <= 6; i++ {
+		require.NoError(t, x.Publish(context.Background(), testBlock(i)))
+	}
+	require.Len(t, inner.published, 4, "every 3rd block is withheld")
+	for _, bl := range inner.published {
+		require.NotZero(t, bl.ID().Number%3)
+	}
+	require.NoError(t, x.Close())
+	require.Equal(t, "delayed-publisher-foo", x.String())
+}
+
+func TestDelay(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	inner := &recordingPublisher{}
+	x := NewPublisher("foo", logger, inner, 50*time.Millisecond, 0)
+	require.NoError(t, x.Publish(context.Background(), testBlock(1)))
+	require.Zero(t, inner.Len(), "must not be published immediately")
+	require.Eventually(t, func() bool {
+		return inner.Len() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, x.Close())
+}
+
+func TestDelayDroppedOnClose(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	inner := &recordingPublisher{}
+	x := NewPublisher("foo", logger, inner, time.Hour, 0)
+	require.NoError(t, x.Publish(context.Background(), testBlock(1)))
+	require.NoError(t, x.Close())
+	require.Zero(t, inner.Len())
+}
@@ -0,0 +1,51 @@
+package equivocatingpublisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+type Config struct {
+	// Publisher is the publisher to publish both the original and the conflicting block with.
+	Publisher seqtypes.PublisherID `yaml:"publisher"`
+	// Signer is the signer of the conflicting block.
+	// This is typically a different key than the signer of the original block.
+	Signer seqtypes.SignerID `yaml:"signer"`
+	// ConflictFirst publishes the conflicting block before the original block.
+	ConflictFirst bool `yaml:"conflictFirst,omitempty"`
+}
+
+func (c *Config) Start(ctx context.Context, id seqtypes.PublisherID, opts *work.ServiceOpts) (work.Publisher, error) {
+	if c.Publisher == "" {
+		return nil, errors.New("no publisher specified")
+	}
+	if c.Publisher == id {
+		return nil, errors.New("equivocating publisher cannot publish with itself")
+	}
+	if c.Signer == "" {
+		return nil, errors.New("no signer for the conflicting block specified")
+	}
+	return &Publisher{
+		id:  id,
+		log: opts.Log,
+		publisher: func() (work.Publisher, error) {
+			v := opts.Services.Publisher(c.Publisher)
+			if v == nil {
+				return nil, fmt.Errorf("unknown publisher %q", c.Publisher)
+			}
+			return v, nil
+		},
+		signer: func() (work.Signer, error) {
+			v := opts.Services.Signer(c.Signer)
+			if v == nil {
+				return nil, fmt.Errorf("unknown signer %q", c.Signer)
+			}
+			return v, nil
+		},
+		conflictFirst: c.ConflictFirst,
+	}, nil
+}
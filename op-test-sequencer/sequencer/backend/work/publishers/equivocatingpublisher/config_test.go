@@ -0,0 +1,48 @@
+package equivocatingpublisher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/metrics"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/publishers/nooppublisher"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+func TestConfig(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelInfo)
+	ensemble := &work.Ensemble{}
+	opts := &work.ServiceOpts{
+		StartOpts: &work.StartOpts{
+			Log:     logger,
+			Metrics: &metrics.NoopMetrics{},
+		},
+		Services: ensemble,
+	}
+	id := seqtypes.PublisherID("test")
+
+	_, err := (&Config{Signer: "alt"}).Start(context.Background(), id, opts)
+	require.ErrorContains(t, err, "no publisher")
+	_, err = (&Config{Publisher: id, Signer: "alt"}).Start(context.Background(), id, opts)
+	require.ErrorContains(t, err, "itself")
+	_, err = (&Config{Publisher: "inner"}).Start(context.Background(), id, opts)
+	require.ErrorContains(t, err, "no signer")
+
+	cfg := &Config{Publisher: "inner", Signer: "alt"}
+	publisher, err := cfg.Start(context.Background(), id, opts)
+	require.NoError(t, err)
+	require.Equal(t, id, publisher.ID())
+
+	err = publisher.Publish(context.Background(), testBlock())
+	require.ErrorContains(t, err, "unknown publisher")
+
+	require.NoError(t, ensemble.AddPublisher(nooppublisher.NewPublisher("inner", logger)))
+	err = publisher.Publish(context.Background(), testBlock())
+	require.ErrorContains(t, err, "unknown signer")
+}
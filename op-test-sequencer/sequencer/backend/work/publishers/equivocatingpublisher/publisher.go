@@ -0,0 +1,97 @@
+package equivocatingpublisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+// Publisher publishes every block together with a conflicting block at the same height,
+// signed by a different signer, to test how the network handles an equivocating sequencer.
+type Publisher struct {
+	id  seqtypes.PublisherID
+	log log.Logger
+
+	// publisher and signer are resolved lazily, since services are started in no particular order.
+	publisher func() (work.Publisher, error)
+	signer    func() (work.Signer, error)
+
+	// conflictFirst publishes the conflicting block before the original block.
+	conflictFirst bool
+}
+
+var _ work.Publisher = (*Publisher)(nil)
+
+func NewPublisher(id seqtypes.PublisherID, log log.Logger, publisher work.Publisher, signer work.Signer, conflictFirst bool) *Publisher {
+	return &Publisher{
+		id:            id,
+		log:           log,
+		publisher:     func() (work.Publisher, error) { return publisher, nil },
+		signer:        func() (work.Signer, error) { return signer, nil },
+		conflictFirst: conflictFirst,
+	}
+}
+
+func (n *Publisher) Close() error {
+	return nil
+}
+
+func (n *Publisher) String() string {
+	return "equivocating-publisher-" + n.id.String()
+}
+
+func (n *Publisher) ID() seqtypes.PublisherID {
+	return n.id
+}
+
+func (n *Publisher) Publish(ctx context.Context, block work.SignedBlock) error {
+	publisher, err := n.publisher()
+	if err != nil {
+		return err
+	}
+	signer, err := n.signer()
+	if err != nil {
+		return err
+	}
+	bl, ok := block.(*opsigner.SignedExecutionPayloadEnvelope)
+	if !ok {
+		return fmt.Errorf("cannot equivocate block of type %T: %w", block, seqtypes.ErrUnknownKind)
+	}
+	conflict := Conflicting(bl.Envelope)
+	signedConflict, err := signer.Sign(ctx, conflict)
+	if err != nil {
+		return fmt.Errorf("failed to sign conflicting block: %w", err)
+	}
+	n.log.Warn("Equivocating block", "block", bl.ID(), "conflict", signedConflict.ID(), "signer", signer)
+
+	first, second := block, signedConflict
+	if n.conflictFirst {
+		first, second = second, first
+	}
+	// Attempt to publish both, even if the first one fails, to get the conflicting block out there.
+	return errors.Join(publisher.Publish(ctx, first), publisher.Publish(ctx, second))
+}
+
+// Conflicting returns a copy of the block with different contents and block hash, at the same height.
+// The prev-randao value is changed, so the state transition only differs
+// if transactions in the block read the value.
+func Conflicting(envelope *eth.ExecutionPayloadEnvelope) *eth.ExecutionPayloadEnvelope {
+	payload := *envelope.ExecutionPayload
+	randao := common.Hash(payload.PrevRandao)
+	randao[len(randao)-1] ^= 0xff
+	payload.PrevRandao = eth.Bytes32(randao)
+	out := &eth.ExecutionPayloadEnvelope{
+		ParentBeaconBlockRoot: envelope.ParentBeaconBlockRoot,
+		ExecutionPayload:      &payload,
+	}
+	payload.BlockHash, _ = out.CheckBlockHash()
+	return out
+}
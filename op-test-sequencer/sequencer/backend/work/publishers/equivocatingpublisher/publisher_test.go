@@ -0,0 +1,78 @@
+package equivocatingpublisher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	opsigner "github.com/tokamak-network/tokamak-thanos/op-service/signer"
+	"github.com/tokamak-network/tokamak-thanos/op-service/testlog"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/backend/work/signers/localkey"
+	"github.com/tokamak-network/tokamak-thanos/op-test-sequencer/sequencer/seqtypes"
+)
+
+type recordingPublisher struct {
+	published []work.SignedBlock
+}
+
+func (r *recordingPublisher) String() string           { return "recording-publisher" }
+func (r *recordingPublisher) ID() seqtypes.PublisherID { return "recording" }
+func (r *recordingPublisher) Close() error             { return nil }
+func (r *recordingPublisher) Publish(ctx context.Context, block work.SignedBlock) error {
+	r.published = append(r.published, block)
+	return nil
+}
+
+func testBlock() *opsigner.SignedExecutionPayloadEnvelope {
+	parentBeaconBlockRoot := common.Hash{0x42}
+	envelope := &eth.ExecutionPayloadEnvelope{
+		ParentBeaconBlockRoot: &parentBeaconBlockRoot,
+		ExecutionPayload: &eth.ExecutionPayload{
+			ParentHash:  common.Hash{0x01},
+			BlockNumber: 1234,
+			Timestamp:   5000,
+		},
+	}
+	envelope.ExecutionPayload.BlockHash, _ = envelope.CheckBlockHash()
+	return &opsigner.SignedExecutionPayloadEnvelope{Envelope: envelope, Signature: eth.Bytes65{0x01}}
+}
+
+func TestEquivocatingPublisher(t *testing.T) {
+	logger := testlog.Logger(t, log.LevelDebug)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := localkey.NewSigner("alt", logger, eth.ChainIDFromUInt64(123), key)
+
+	for _, conflictFirst := range []bool{false, true} {
+		inner := &recordingPublisher{}
+		x := NewPublisher("foo", logger, inner, signer, conflictFirst)
+
+		block := testBlock()
+		require.NoError(t, x.Publish(context.Background(), block))
+		require.Len(t, inner.published, 2)
+
+		original, conflict := inner.published[0], inner.published[1]
+		if conflictFirst {
+			original, conflict = conflict, original
+		}
+		require.Same(t, block, original)
+		require.Equal(t, block.ID().Number, conflict.ID().Number, "must be at the same height")
+		require.NotEqual(t, block.ID().Hash, conflict.ID().Hash, "must be a different block")
+
+		signedConflict := conflict.(*opsigner.SignedExecutionPayloadEnvelope)
+		require.NotEqual(t, block.Signature, signedConflict.Signature)
+		require.Equal(t, block.Envelope.ExecutionPayload.ParentHash, signedConflict.Envelope.ExecutionPayload.ParentHash)
+		_, ok := signedConflict.Envelope.CheckBlockHash()
+		require.True(t, ok, "conflicting block must have a valid block hash")
+
+		require.NoError(t, x.Close())
+		require.Equal(t, "equivocating-publisher-foo", x.String())
+	}
+}
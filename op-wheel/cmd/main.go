@@ -32,13 +32,14 @@ func main() {
 		return nil
 	}
 	app.Action = cli.ActionFunc(func(c *cli.Context) error {
-		return errors.New("see 'cheat' and 'engine' subcommands and --help")
+		return errors.New("see 'cheat', 'engine' and 'scenario' subcommands and --help")
 	})
 	app.Writer = os.Stdout
 	app.ErrWriter = os.Stderr
 	app.Commands = []*cli.Command{
 		wheel.CheatCmd,
 		wheel.EngineCmd,
+		wheel.ScenarioCmd,
 	}
 
 	err := app.Run(os.Args)
@@ -25,11 +25,13 @@ import (
 	"github.com/tokamak-network/tokamak-thanos/op-node/rollup"
 	opservice "github.com/tokamak-network/tokamak-thanos/op-service"
 	"github.com/tokamak-network/tokamak-thanos/op-service/client"
+	"github.com/tokamak-network/tokamak-thanos/op-service/jsonutil"
 	oplog "github.com/tokamak-network/tokamak-thanos/op-service/log"
 	opmetrics "github.com/tokamak-network/tokamak-thanos/op-service/metrics"
 	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
 	"github.com/tokamak-network/tokamak-thanos/op-wheel/cheat"
 	"github.com/tokamak-network/tokamak-thanos/op-wheel/engine"
+	"github.com/tokamak-network/tokamak-thanos/op-wheel/scenario"
 )
 
 const envVarPrefix = "OP_WHEEL"
@@ -668,6 +670,68 @@ var (
 	}
 )
 
+var ScenarioCmd = &cli.Command{
+	Name:  "scenario",
+	Usage: "Run a YAML scenario of engine and cheat steps, e.g. to reproduce an incident.",
+	Description: "Runs the steps of the scenario file in order: build blocks, patch state, reorg, set forkchoice, and assert heads and state. " +
+		"The structured result is printed as JSON, and optionally saved to a file, such that reproductions can be saved and rerun. " +
+		"Patch steps modify the geth datadir, and require geth to be stopped: they cannot be mixed with other steps, " +
+		"which require geth to be running. Scenarios of patch steps do not connect to the engine.",
+	ArgsUsage: "<scenario.yaml>",
+	Flags: withEngineFlags(
+		FeeRecipientFlag, RandaoFlag, BlockTimeFlag, BuildingTime, AllowGaps,
+		&cli.StringFlag{
+			Name:      DataDirFlag.Name,
+			Usage:     "Geth data dir location, required by patch steps.",
+			TakesFile: true,
+			EnvVars:   DataDirFlag.EnvVars,
+		},
+		&cli.PathFlag{
+			Name:    "output",
+			Usage:   "Path to write the JSON result of the scenario to",
+			EnvVars: prefixEnvVars("SCENARIO_OUTPUT"),
+		},
+	),
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() != 1 {
+			return fmt.Errorf("expected 1 argument: scenario file path")
+		}
+		sc, err := scenario.Load(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		if sc.Patches() {
+			// geth is stopped, the engine cannot be reached
+			runner := scenario.NewRunner(initLogger(ctx), nil, nil, ctx.String(DataDirFlag.Name), ParseBuildingArgs(ctx))
+			return runScenario(ctx, runner, sc)
+		}
+		return EngineAction(func(ctx *cli.Context, client *sources.EngineAPIClient, lgr log.Logger) error {
+			open, err := initOpenEngineRPC(ctx, lgr)
+			if err != nil {
+				return fmt.Errorf("failed to dial open RPC endpoint: %w", err)
+			}
+			return runScenario(ctx, scenario.NewRunner(lgr, client, open, ctx.String(DataDirFlag.Name), ParseBuildingArgs(ctx)), sc)
+		})(ctx)
+	},
+}
+
+// runScenario runs the scenario, and prints and optionally saves its result.
+func runScenario(ctx *cli.Context, runner *scenario.Runner, sc *scenario.Scenario) error {
+	result := runner.Run(ctx.Context, sc)
+	if err := jsonutil.WriteJSON(ctx.Path("output"), result, 0o644); err != nil {
+		return fmt.Errorf("failed to write scenario result: %w", err)
+	}
+	enc := json.NewEncoder(ctx.App.Writer)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		return err
+	}
+	if !result.Passed {
+		return fmt.Errorf("scenario %q failed", sc.Name)
+	}
+	return nil
+}
+
 var CheatCmd = &cli.Command{
 	Name:  "cheat",
 	Usage: "Cheating commands to modify a Geth database.",
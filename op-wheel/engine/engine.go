@@ -124,6 +124,10 @@ type BlockBuildingSettings struct {
 	Random       common.Hash
 	FeeRecipient common.Address
 	BuildTime    time.Duration
+	// Transactions to force into the block, before any tx-pool transactions.
+	Transactions []eth.Data
+	// NoTxPool disables the inclusion of tx-pool transactions.
+	NoTxPool bool
 }
 
 func BuildBlock(ctx context.Context, client *sources.EngineAPIClient, status *StatusData, settings *BlockBuildingSettings) (*eth.ExecutionPayloadEnvelope, error) {
@@ -135,6 +139,8 @@ func BuildBlock(ctx context.Context, client *sources.EngineAPIClient, status *St
 		}
 	}
 	attrs := newPayloadAttributes(client.EngineVersionProvider(), timestamp, settings.Random, settings.FeeRecipient)
+	attrs.Transactions = settings.Transactions
+	attrs.NoTxPool = settings.NoTxPool
 	pre, err := client.ForkchoiceUpdate(ctx,
 		&eth.ForkchoiceState{
 			HeadBlockHash:      status.Head.Hash,
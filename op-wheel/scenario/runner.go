@@ -0,0 +1,249 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/tokamak-network/tokamak-thanos/op-service/client"
+	"github.com/tokamak-network/tokamak-thanos/op-service/eth"
+	"github.com/tokamak-network/tokamak-thanos/op-service/sources"
+	"github.com/tokamak-network/tokamak-thanos/op-wheel/cheat"
+	"github.com/tokamak-network/tokamak-thanos/op-wheel/engine"
+)
+
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// StepResult is the outcome of a single step.
+type StepResult struct {
+	Index   int    `json:"index"`
+	Name    string `json:"name,omitempty"`
+	Kind    string `json:"kind"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Elapsed string `json:"elapsed,omitempty"`
+	// Blocks lists the blocks that were built by a build step.
+	Blocks []eth.BlockID `json:"blocks,omitempty"`
+	// Heads is the engine forkchoice state after the step, if the engine is reachable.
+	Heads *engine.StatusData `json:"heads,omitempty"`
+}
+
+// Result is the structured outcome of a scenario run, to save and compare incident reproductions.
+type Result struct {
+	Scenario string       `json:"scenario"`
+	Passed   bool         `json:"passed"`
+	Steps    []StepResult `json:"steps"`
+}
+
+// Runner runs scenarios against an execution engine and its geth datadir.
+type Runner struct {
+	log      log.Logger
+	engine   *sources.EngineAPIClient
+	open     client.RPC
+	dataDir  string
+	settings engine.BlockBuildingSettings
+}
+
+// NewRunner creates a scenario runner. The open RPC is used for state assertions and debug_setHead.
+// The dataDir is only required by patch steps. The engine and open RPC are not used by scenarios that patch,
+// since those run while geth is stopped, see Scenario.Patches.
+func NewRunner(lgr log.Logger, engineClient *sources.EngineAPIClient, open client.RPC, dataDir string, settings *engine.BlockBuildingSettings) *Runner {
+	return &Runner{
+		log:      lgr,
+		engine:   engineClient,
+		open:     open,
+		dataDir:  dataDir,
+		settings: *settings,
+	}
+}
+
+// Run runs the steps of the scenario in order. Steps after the first failed step are skipped.
+func (r *Runner) Run(ctx context.Context, sc *Scenario) *Result {
+	res := &Result{Scenario: sc.Name, Passed: true}
+	for i := range sc.Steps {
+		step := &sc.Steps[i]
+		kind, _ := step.Kind()
+		sr := StepResult{Index: i, Name: step.Name, Kind: kind}
+		if !res.Passed {
+			sr.Status = StatusSkipped
+			res.Steps = append(res.Steps, sr)
+			continue
+		}
+		r.log.Info("Running step", "index", i, "kind", kind, "name", step.Name)
+		start := time.Now()
+		err := r.runStep(ctx, step, &sr)
+		sr.Elapsed = time.Since(start).String()
+		if err != nil {
+			r.log.Error("Step failed", "index", i, "kind", kind, "name", step.Name, "err", err)
+			sr.Status = StatusFailed
+			sr.Error = err.Error()
+			res.Passed = false
+		} else {
+			sr.Status = StatusOK
+		}
+		// patch steps run while geth is stopped, the heads are not available then
+		if step.Patch == nil {
+			if heads, err := engine.Status(ctx, r.engine.RPC); err == nil {
+				sr.Heads = heads
+			}
+		}
+		res.Steps = append(res.Steps, sr)
+	}
+	return res
+}
+
+func (r *Runner) runStep(ctx context.Context, step *Step, sr *StepResult) error {
+	switch {
+	case step.Build != nil:
+		return r.build(ctx, step.Build, sr)
+	case step.Patch != nil:
+		return r.patch(step.Patch)
+	case step.Reorg != nil:
+		return engine.Rewind(ctx, r.log, r.engine, r.open, step.Reorg.To, step.Reorg.SetHead)
+	case step.Forkchoice != nil:
+		fc := step.Forkchoice
+		return engine.SetForkchoice(ctx, r.engine, fc.Finalized, fc.Safe, fc.Unsafe)
+	case step.Assert != nil:
+		return r.assert(ctx, step.Assert)
+	default:
+		return errors.New("no step kind specified")
+	}
+}
+
+func (r *Runner) build(ctx context.Context, step *BuildStep, sr *StepResult) error {
+	count := step.Count
+	if count == 0 {
+		count = 1
+	}
+	for i := uint64(0); i < count; i++ {
+		settings := r.settings
+		settings.NoTxPool = step.NoTxPool
+		if i == 0 {
+			for _, tx := range step.Txs {
+				settings.Transactions = append(settings.Transactions, eth.Data(tx))
+			}
+		}
+		status, err := engine.Status(ctx, r.engine.RPC)
+		if err != nil {
+			return fmt.Errorf("failed to get engine status: %w", err)
+		}
+		payloadEnv, err := engine.BuildBlock(ctx, r.engine, status, &settings)
+		if err != nil {
+			return fmt.Errorf("failed to build block %d of %d: %w", i+1, count, err)
+		}
+		payload := payloadEnv.ExecutionPayload
+		if got, want := len(payload.Transactions), len(settings.Transactions); got < want {
+			return fmt.Errorf("block %s includes %d txs, expected at least the %d forced txs", payload.ID(), got, want)
+		}
+		sr.Blocks = append(sr.Blocks, payload.ID())
+	}
+	return nil
+}
+
+func (r *Runner) patch(step *PatchStep) error {
+	if r.dataDir == "" {
+		return errors.New("patch steps require a geth datadir")
+	}
+	ch, err := cheat.OpenGethDB(r.dataDir, false)
+	if err != nil {
+		return fmt.Errorf("failed to open geth db, geth must be stopped to patch its state: %w", err)
+	}
+	return ch.RunAndClose(func(header *types.Header, headState *state.StateDB) error {
+		var fns []cheat.HeadFn
+		for _, v := range step.Storage {
+			fns = append(fns, cheat.StorageSet(v.Address, v.Key, v.Value))
+		}
+		for _, v := range step.Balance {
+			fns = append(fns, cheat.SetBalance(v.Address, v.Value))
+		}
+		for _, v := range step.Nonce {
+			fns = append(fns, cheat.SetNonce(v.Address, v.Value))
+		}
+		for _, v := range step.Code {
+			fns = append(fns, cheat.SetCode(v.Address, v.Code))
+		}
+		for _, fn := range fns {
+			if err := fn(header, headState); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *Runner) assert(ctx context.Context, step *AssertStep) error {
+	var errs []error
+	if step.Head != nil || step.Safe != nil || step.Finalized != nil {
+		status, err := engine.Status(ctx, r.engine.RPC)
+		if err != nil {
+			return fmt.Errorf("failed to get engine status: %w", err)
+		}
+		errs = append(errs,
+			checkBlock("head", step.Head, status.Head),
+			checkBlock("safe", step.Safe, status.Safe),
+			checkBlock("finalized", step.Finalized, status.Finalized))
+	}
+	for _, v := range step.Balance {
+		var got hexutil.Big
+		if err := r.open.CallContext(ctx, &got, "eth_getBalance", v.Address, "latest"); err != nil {
+			return fmt.Errorf("failed to get balance of %s: %w", v.Address, err)
+		}
+		if (*big.Int)(&got).Cmp(v.Value) != 0 {
+			errs = append(errs, fmt.Errorf("balance of %s: expected %s, got %s", v.Address, v.Value, (*big.Int)(&got)))
+		}
+	}
+	for _, v := range step.Storage {
+		var got common.Hash
+		if err := r.open.CallContext(ctx, &got, "eth_getStorageAt", v.Address, v.Key, "latest"); err != nil {
+			return fmt.Errorf("failed to get storage %s of %s: %w", v.Key, v.Address, err)
+		}
+		if got != v.Value {
+			errs = append(errs, fmt.Errorf("storage %s of %s: expected %s, got %s", v.Key, v.Address, v.Value, got))
+		}
+	}
+	for _, v := range step.Nonce {
+		var got hexutil.Uint64
+		if err := r.open.CallContext(ctx, &got, "eth_getTransactionCount", v.Address, "latest"); err != nil {
+			return fmt.Errorf("failed to get nonce of %s: %w", v.Address, err)
+		}
+		if uint64(got) != v.Value {
+			errs = append(errs, fmt.Errorf("nonce of %s: expected %d, got %d", v.Address, v.Value, got))
+		}
+	}
+	for _, v := range step.Code {
+		var got hexutil.Bytes
+		if err := r.open.CallContext(ctx, &got, "eth_getCode", v.Address, "latest"); err != nil {
+			return fmt.Errorf("failed to get code of %s: %w", v.Address, err)
+		}
+		if !bytes.Equal(got, v.Code) {
+			errs = append(errs, fmt.Errorf("code of %s: expected %s, got %s", v.Address, v.Code, got))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkBlock(label string, expected *BlockAssertion, got eth.L1BlockRef) error {
+	if expected == nil {
+		return nil
+	}
+	if expected.Number != nil && *expected.Number != got.Number {
+		return fmt.Errorf("%s block: expected number %d, got %s", label, *expected.Number, got)
+	}
+	if expected.Hash != nil && *expected.Hash != got.Hash {
+		return fmt.Errorf("%s block: expected hash %s, got %s", label, *expected.Hash, got)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package scenario
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Scenario is a sequence of steps to run against an execution engine,
+// e.g. to reproduce an incident in a repeatable way.
+type Scenario struct {
+	Name  string `yaml:"name"`
+	Steps []Step `yaml:"steps"`
+}
+
+// Step is a single scenario step. Exactly one of the step kinds must be set.
+type Step struct {
+	// Name optionally describes the step in the result.
+	Name string `yaml:"name,omitempty"`
+
+	Build      *BuildStep      `yaml:"build,omitempty"`
+	Patch      *PatchStep      `yaml:"patch,omitempty"`
+	Reorg      *ReorgStep      `yaml:"reorg,omitempty"`
+	Forkchoice *ForkchoiceStep `yaml:"forkchoice,omitempty"`
+	Assert     *AssertStep     `yaml:"assert,omitempty"`
+}
+
+// Kind returns the name of the kind of step, or an error if not exactly one kind is set.
+func (s *Step) Kind() (string, error) {
+	var kinds []string
+	if s.Build != nil {
+		kinds = append(kinds, "build")
+	}
+	if s.Patch != nil {
+		kinds = append(kinds, "patch")
+	}
+	if s.Reorg != nil {
+		kinds = append(kinds, "reorg")
+	}
+	if s.Forkchoice != nil {
+		kinds = append(kinds, "forkchoice")
+	}
+	if s.Assert != nil {
+		kinds = append(kinds, "assert")
+	}
+	switch len(kinds) {
+	case 0:
+		return "", errors.New("no step kind specified")
+	case 1:
+		return kinds[0], nil
+	default:
+		return "", fmt.Errorf("multiple step kinds specified: %v", kinds)
+	}
+}
+
+// BuildStep builds blocks on top of the current head with the Engine API.
+type BuildStep struct {
+	// Count is the number of blocks to build, 1 if not set.
+	Count uint64 `yaml:"count,omitempty"`
+	// Txs are signed raw transactions to include in the first block that is built.
+	Txs []hexutil.Bytes `yaml:"txs,omitempty"`
+	// NoTxPool excludes transactions from the tx-pool of the engine.
+	NoTxPool bool `yaml:"no_tx_pool,omitempty"`
+}
+
+// StorageValue is the value of a storage slot of an account.
+type StorageValue struct {
+	Address common.Address `yaml:"address"`
+	Key     common.Hash    `yaml:"key"`
+	Value   common.Hash    `yaml:"value"`
+}
+
+// BalanceValue is the balance of an account.
+type BalanceValue struct {
+	Address common.Address `yaml:"address"`
+	Value   *big.Int       `yaml:"value"`
+}
+
+// NonceValue is the nonce of an account.
+type NonceValue struct {
+	Address common.Address `yaml:"address"`
+	Value   uint64         `yaml:"value"`
+}
+
+// CodeValue is the code of an account.
+type CodeValue struct {
+	Address common.Address `yaml:"address"`
+	Code    hexutil.Bytes  `yaml:"code"`
+}
+
+// PatchStep modifies the head state in the geth datadir, like the cheat commands.
+// The datadir cannot be opened while geth is running, while all other steps need geth to be running:
+// a scenario either only has patch steps, or none.
+type PatchStep struct {
+	Storage []StorageValue `yaml:"storage,omitempty"`
+	Balance []BalanceValue `yaml:"balance,omitempty"`
+	Nonce   []NonceValue   `yaml:"nonce,omitempty"`
+	Code    []CodeValue    `yaml:"code,omitempty"`
+}
+
+// ReorgStep rewinds the chain to the given block number, like the engine rewind command.
+type ReorgStep struct {
+	To      uint64 `yaml:"to"`
+	SetHead bool   `yaml:"set_head,omitempty"`
+}
+
+// ForkchoiceStep sets the unsafe, safe and finalized blocks by number, like the engine set-forkchoice command.
+type ForkchoiceStep struct {
+	Unsafe    uint64 `yaml:"unsafe"`
+	Safe      uint64 `yaml:"safe"`
+	Finalized uint64 `yaml:"finalized"`
+}
+
+// BlockAssertion checks a block label. Unset fields are not checked.
+type BlockAssertion struct {
+	Number *uint64      `yaml:"number,omitempty"`
+	Hash   *common.Hash `yaml:"hash,omitempty"`
+}
+
+// AssertStep checks the chain heads and the latest state of the engine.
+type AssertStep struct {
+	Head      *BlockAssertion `yaml:"head,omitempty"`
+	Safe      *BlockAssertion `yaml:"safe,omitempty"`
+	Finalized *BlockAssertion `yaml:"finalized,omitempty"`
+	Storage   []StorageValue  `yaml:"storage,omitempty"`
+	Balance   []BalanceValue  `yaml:"balance,omitempty"`
+	Nonce     []NonceValue    `yaml:"nonce,omitempty"`
+	Code      []CodeValue     `yaml:"code,omitempty"`
+}
+
+// Patches returns whether the scenario patches the geth datadir. Such a scenario runs while geth is stopped.
+func (s *Scenario) Patches() bool {
+	return len(s.Steps) > 0 && s.Steps[0].Patch != nil
+}
+
+// Check verifies that the scenario is well-formed.
+func (s *Scenario) Check() error {
+	if len(s.Steps) == 0 {
+		return errors.New("scenario has no steps")
+	}
+	patches := s.Patches()
+	for i := range s.Steps {
+		step := &s.Steps[i]
+		if _, err := step.Kind(); err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+		if (step.Patch != nil) != patches {
+			return fmt.Errorf("step %d: patch steps need geth to be stopped, and other steps need it to be running: "+
+				"patch steps cannot be mixed with other steps, split them into separate scenarios", i)
+		}
+		if step.Forkchoice != nil {
+			fc := step.Forkchoice
+			if fc.Unsafe < fc.Safe || fc.Safe < fc.Finalized {
+				return fmt.Errorf("step %d: forkchoice must satisfy unsafe >= safe >= finalized", i)
+			}
+		}
+		if step.Patch != nil {
+			for _, b := range step.Patch.Balance {
+				if b.Value == nil {
+					return fmt.Errorf("step %d: balance patch of %s has no value", i, b.Address)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Load reads a scenario from a YAML file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+	var out Scenario
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true) // typos in a scenario should not silently skip steps or checks
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario YAML: %w", err)
+	}
+	if err := out.Check(); err != nil {
+		return nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	return &out, nil
+}
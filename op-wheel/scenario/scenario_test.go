@@ -0,0 +1,120 @@
+package scenario
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func writeScenario(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadExample(t *testing.T) {
+	sc, err := Load("example.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "reorg-and-rebuild", sc.Name)
+	require.False(t, sc.Patches())
+
+	var kinds []string
+	for i := range sc.Steps {
+		kind, err := sc.Steps[i].Kind()
+		require.NoError(t, err)
+		kinds = append(kinds, kind)
+	}
+	require.Equal(t, []string{"build", "reorg", "assert", "build", "forkchoice", "assert"}, kinds)
+
+	require.Equal(t, &BuildStep{Count: 3, NoTxPool: true}, sc.Steps[0].Build)
+	require.Equal(t, &ReorgStep{To: 1, SetHead: true}, sc.Steps[1].Reorg)
+	require.Equal(t, uint64(1), *sc.Steps[2].Assert.Head.Number)
+	require.Equal(t, []BalanceValue{{Address: common.HexToAddress("0x1111111111111111111111111111111111111111"), Value: big.NewInt(0)}},
+		sc.Steps[2].Assert.Balance)
+	require.Equal(t, &ForkchoiceStep{Unsafe: 3, Safe: 2, Finalized: 1}, sc.Steps[4].Forkchoice)
+}
+
+func TestLoadPatchScenario(t *testing.T) {
+	sc, err := Load(writeScenario(t, `
+name: patch
+steps:
+  - patch:
+      balance:
+        - address: "0x1111111111111111111111111111111111111111"
+          value: "1000000000000000000"
+      storage:
+        - address: "0x2222222222222222222222222222222222222222"
+          key: "0x0000000000000000000000000000000000000000000000000000000000000001"
+          value: "0x0000000000000000000000000000000000000000000000000000000000000002"
+  - patch:
+      nonce:
+        - address: "0x1111111111111111111111111111111111111111"
+          value: 5
+`))
+	require.NoError(t, err)
+	require.True(t, sc.Patches())
+	require.Len(t, sc.Steps, 2)
+	patch := sc.Steps[0].Patch
+	require.Equal(t, big.NewInt(1e18), patch.Balance[0].Value)
+	require.Equal(t, common.Hash{31: 0x02}, patch.Storage[0].Value)
+	require.Equal(t, uint64(5), sc.Steps[1].Patch.Nonce[0].Value)
+}
+
+func TestLoadInvalidScenario(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		scenario string
+		expected string
+	}{
+		{
+			name:     "UnknownField",
+			scenario: "steps:\n  - build: {cont: 2}\n",
+			expected: "field cont not found",
+		},
+		{
+			name:     "NoSteps",
+			scenario: "name: empty\n",
+			expected: "scenario has no steps",
+		},
+		{
+			name:     "NoStepKind",
+			scenario: "steps:\n  - name: nothing\n",
+			expected: "step 0: no step kind specified",
+		},
+		{
+			name:     "MultipleStepKinds",
+			scenario: "steps:\n  - build: {}\n    reorg: {to: 1}\n",
+			expected: "step 0: multiple step kinds specified: [build reorg]",
+		},
+		{
+			name:     "ForkchoiceOrder",
+			scenario: "steps:\n  - forkchoice: {unsafe: 1, safe: 2, finalized: 0}\n",
+			expected: "step 0: forkchoice must satisfy",
+		},
+		{
+			name:     "BalancePatchWithoutValue",
+			scenario: "steps:\n  - patch:\n      balance:\n        - address: \"0x1111111111111111111111111111111111111111\"\n",
+			expected: "step 0: balance patch of 0x1111111111111111111111111111111111111111 has no value",
+		},
+		{
+			name:     "PatchAfterEngineStep",
+			scenario: "steps:\n  - build: {}\n  - patch: {nonce: [{address: \"0x1111111111111111111111111111111111111111\", value: 1}]}\n",
+			expected: "step 1: patch steps need geth to be stopped",
+		},
+		{
+			name:     "EngineStepAfterPatch",
+			scenario: "steps:\n  - patch: {nonce: [{address: \"0x1111111111111111111111111111111111111111\", value: 1}]}\n  - assert: {head: {number: 1}}\n",
+			expected: "step 1: patch steps need geth to be stopped",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Load(writeScenario(t, test.scenario))
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}